@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// item is an item as returned by the JSON API.
+type item struct {
+	ID                int        `json:"id"`
+	Title             string     `json:"title"`
+	Price             string     `json:"price"`
+	Tags              []string   `json:"tags"`
+	Status            string     `json:"status"`
+	PurchaseAllowedAt *time.Time `json:"purchase_allowed_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// itemInput is the body of POST /api/v1/items.
+type itemInput struct {
+	Title           string   `json:"title"`
+	Price           string   `json:"price,omitempty"`
+	Link            string   `json:"link,omitempty"`
+	Note            string   `json:"note,omitempty"`
+	Tags            []string `json:"tags,omitempty"`
+	WaitPreset      string   `json:"wait_preset,omitempty"`
+	WaitCustomHours string   `json:"wait_custom_hours,omitempty"`
+	WaitText        string   `json:"wait_text,omitempty"`
+}
+
+// apiError is the error body of the JSON API.
+type apiError struct {
+	Error  string `json:"error"`
+	Fields []struct {
+		Field   string `json:"field"`
+		Message string `json:"message"`
+	} `json:"fields"`
+}
+
+// client talks to the JSON API as the configured profile.
+type client struct {
+	cfg  config
+	http *http.Client
+}
+
+func newClient(cfg config) *client {
+	return &client{cfg: cfg, http: &http.Client{Timeout: 15 * time.Second}}
+}
+
+// do sends a request and decodes a successful JSON response into out. Error responses become errors
+// that carry the server's message.
+func (c *client) do(method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequest(method, c.cfg.Server+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.cfg.Profile != "" {
+		req.AddCookie(&http.Cookie{Name: "active_profile", Value: c.cfg.Profile})
+	}
+	if c.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.Token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("reach %s: %w", c.cfg.Server, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		var apiErr apiError
+		if json.NewDecoder(resp.Body).Decode(&apiErr) != nil || apiErr.Error == "" {
+			return fmt.Errorf("%s %s: %s", method, path, resp.Status)
+		}
+		messages := []string{apiErr.Error}
+		for _, field := range apiErr.Fields {
+			messages = append(messages, field.Field+": "+field.Message)
+		}
+		return fmt.Errorf("%s", strings.Join(messages, "\n"))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response of %s %s: %w", method, path, err)
+	}
+	return nil
+}
+
+// listItems returns every item matching query, following the API's cursor through all pages.
+func (c *client) listItems(query url.Values) ([]item, error) {
+	var items []item
+	for {
+		var page struct {
+			Items      []item `json:"items"`
+			NextCursor string `json:"next_cursor"`
+		}
+		if err := c.do(http.MethodGet, "/api/v1/items?"+query.Encode(), nil, &page); err != nil {
+			return nil, err
+		}
+		items = append(items, page.Items...)
+		if page.NextCursor == "" {
+			return items, nil
+		}
+		query.Set("cursor", page.NextCursor)
+	}
+}
+
+func (c *client) addItem(input itemInput) (item, error) {
+	var created item
+	err := c.do(http.MethodPost, "/api/v1/items", input, &created)
+	return created, err
+}
+
+// decideItem marks a ready item as "Bought" or "Skipped".
+func (c *client) decideItem(id int, status string) (item, error) {
+	var decided item
+	err := c.do(http.MethodPost, fmt.Sprintf("/api/v1/items/%d/decision", id), map[string]string{"status": status}, &decided)
+	return decided, err
+}
+
+// snoozeItem puts a ready item back to waiting for another day.
+func (c *client) snoozeItem(id int) (item, error) {
+	var snoozed item
+	err := c.do(http.MethodPost, fmt.Sprintf("/api/v1/items/%d/snooze", id), map[string]string{"preset": "24h"}, &snoozed)
+	return snoozed, err
+}
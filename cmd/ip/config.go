@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// config is where the CLI finds the server. The file holds "key = value" lines; "#" starts a comment.
+//
+//	server = https://pause.example.com
+//	profile = Alex
+//	token = …
+type config struct {
+	Server  string
+	Profile string
+	// Token is sent as a bearer token, for servers behind an authenticating proxy.
+	Token string
+}
+
+// defaultConfigPath is ip.conf in the user's config directory, e.g. ~/.config/impulse-pause/ip.conf.
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "impulse-pause", "ip.conf")
+}
+
+// loadConfig reads the config file at path, then applies IP_SERVER, IP_PROFILE and IP_TOKEN. A missing
+// file is fine as long as the environment names the server; an explicitly given path must exist.
+func loadConfig(path string, required bool) (config, error) {
+	cfg := config{Server: "http://localhost:8080"}
+	if path != "" {
+		if err := readConfigFile(path, &cfg); err != nil && (required || !errors.Is(err, fs.ErrNotExist)) {
+			return config{}, err
+		}
+	}
+	if v := os.Getenv("IP_SERVER"); v != "" {
+		cfg.Server = v
+	}
+	if v := os.Getenv("IP_PROFILE"); v != "" {
+		cfg.Profile = v
+	}
+	if v := os.Getenv("IP_TOKEN"); v != "" {
+		cfg.Token = v
+	}
+	cfg.Server = strings.TrimRight(strings.TrimSpace(cfg.Server), "/")
+	if cfg.Server == "" {
+		return config{}, errors.New("no server configured")
+	}
+	return cfg, nil
+}
+
+func readConfigFile(path string, cfg *config) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(text, "=")
+		if !ok {
+			return fmt.Errorf("%s:%d: expected key = value", path, line)
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "server":
+			cfg.Server = value
+		case "profile":
+			cfg.Profile = value
+		case "token":
+			cfg.Token = value
+		default:
+			return fmt.Errorf("%s:%d: unknown key %q", path, line, strings.TrimSpace(key))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+// Command ip adds and lists Impulse Pause items from the terminal through the JSON API.
+//
+//	ip add "Standing desk" --price 400 --wait 30d --tags Home,Office
+//	ip list --ready
+//	ip tui
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const usage = `Usage: ip [-config FILE] <command> [arguments]
+
+Commands:
+  add TITLE [--price 400] [--wait 30d] [--tags A,B] [--link URL] [--note TEXT]
+        park a new item; --wait takes 24h, 7d, 30d, a number of hours such as 48h,
+        or text such as "3 weeks" or "next Friday 18:00" (default: the profile's wait)
+  list [--ready] [--waiting] [--tag TAG] [--search TEXT] [--all]
+        show open items, soonest unlock first; --all includes decided items
+  tui   show waiting and ready items with live countdowns; buy, skip or snooze
+        the selected item with b, s and z
+
+The config file (default %s) holds "server = …", "profile = …" and "token = …" lines.
+IP_SERVER, IP_PROFILE and IP_TOKEN override it.
+`
+
+// customHours matches a --wait given in hours, which the API takes as a custom wait.
+var customHours = regexp.MustCompile(`^([0-9]+)h$`)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, time.Now()); err != nil {
+		fmt.Fprintln(os.Stderr, "ip:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer, now time.Time) error {
+	global := flag.NewFlagSet("ip", flag.ContinueOnError)
+	global.SetOutput(io.Discard)
+	configPath := global.String("config", "", "config file")
+	if err := global.Parse(args); err != nil || global.NArg() == 0 {
+		fmt.Fprintf(stdout, usage, defaultConfigPath())
+		if err != nil && !errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return nil
+	}
+
+	path, required := *configPath, true
+	if path == "" {
+		path, required = defaultConfigPath(), false
+	}
+	cfg, err := loadConfig(path, required)
+	if err != nil {
+		return err
+	}
+	c := newClient(cfg)
+
+	command, rest := global.Arg(0), global.Args()[1:]
+	switch command {
+	case "add":
+		return runAdd(c, rest, stdout)
+	case "list":
+		return runList(c, rest, stdout, now)
+	case "tui":
+		restore, err := rawTerminal()
+		if err != nil {
+			return err
+		}
+		defer restore()
+		return runTUI(c, os.Stdin, stdout, time.Now)
+	case "help":
+		fmt.Fprintf(stdout, usage, defaultConfigPath())
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q, see ip help", command)
+	}
+}
+
+// parseInterspersed parses flags that may come before or after the positional arguments, as in
+// ip add "Standing desk" --price 400.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// waitInput maps a --wait value to the API's wait fields.
+func waitInput(input *itemInput, wait string) {
+	wait = strings.TrimSpace(wait)
+	switch {
+	case wait == "":
+	case wait == "24h" || wait == "7d" || wait == "30d":
+		input.WaitPreset = wait
+	case customHours.MatchString(wait):
+		input.WaitPreset = "custom"
+		input.WaitCustomHours = customHours.FindStringSubmatch(wait)[1]
+	default:
+		input.WaitText = wait
+	}
+}
+
+func runAdd(c *client, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("add", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	price := fs.String("price", "", "price")
+	wait := fs.String("wait", "", "wait")
+	tags := fs.String("tags", "", "comma-separated tags")
+	link := fs.String("link", "", "link")
+	note := fs.String("note", "", "note")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return fmt.Errorf("add: %w", err)
+	}
+	title := strings.TrimSpace(strings.Join(positional, " "))
+	if title == "" {
+		return errors.New("add: a title is required")
+	}
+
+	input := itemInput{Title: title, Price: *price, Link: *link, Note: *note}
+	for _, tag := range strings.Split(*tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			input.Tags = append(input.Tags, tag)
+		}
+	}
+	waitInput(&input, *wait)
+
+	created, err := c.addItem(input)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Added #%d %s", created.ID, created.Title)
+	if created.PurchaseAllowedAt != nil {
+		fmt.Fprintf(stdout, ", ready to buy %s", created.PurchaseAllowedAt.Local().Format("Mon 02.01. 15:04"))
+	}
+	fmt.Fprintln(stdout)
+	return nil
+}
+
+func runList(c *client, args []string, stdout io.Writer, now time.Time) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	ready := fs.Bool("ready", false, "only items ready to buy")
+	waiting := fs.Bool("waiting", false, "only waiting items")
+	all := fs.Bool("all", false, "include decided items")
+	tag := fs.String("tag", "", "tag")
+	search := fs.String("search", "", "search text")
+	if _, err := parseInterspersed(fs, args); err != nil {
+		return fmt.Errorf("list: %w", err)
+	}
+
+	query := url.Values{"sort": {"next_ready"}}
+	var statuses []string
+	if *ready {
+		statuses = append(statuses, "Ready to buy")
+	}
+	if *waiting {
+		statuses = append(statuses, "Waiting")
+	}
+	if len(statuses) == 0 && !*all {
+		statuses = []string{"Ready to buy", "Waiting", "Researching"}
+	}
+	if len(statuses) > 0 {
+		query.Set("status", strings.Join(statuses, ","))
+	}
+	if *tag != "" {
+		query.Set("tag", *tag)
+	}
+	if *search != "" {
+		query.Set("q", *search)
+	}
+
+	items, err := c.listItems(query)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		fmt.Fprintln(stdout, "No items.")
+		return nil
+	}
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSTATUS\tPRICE\tUNLOCKS\tTITLE")
+	for _, it := range items {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", it.ID, it.Status, orDash(it.Price), unlockLabel(it, now), it.Title)
+	}
+	return tw.Flush()
+}
+
+// unlockLabel tells how long a waiting item still waits, e.g. "in 2d 4h".
+func unlockLabel(it item, now time.Time) string {
+	if it.Status != "Waiting" || it.PurchaseAllowedAt == nil {
+		return "-"
+	}
+	return "in " + formatCountdown(it.PurchaseAllowedAt.Sub(now))
+}
+
+// formatCountdown shows a remaining duration in its two largest units.
+func formatCountdown(d time.Duration) string {
+	if d < time.Minute {
+		return "<1m"
+	}
+	days, hours, minutes := int(d/(24*time.Hour)), int(d%(24*time.Hour)/time.Hour), int(d%time.Hour/time.Minute)
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
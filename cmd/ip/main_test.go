@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"mvpapp/internal/web"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	app, err := web.NewAppWithSQLite(filepath.Join(t.TempDir(), "test.sqlite"))
+	if err != nil {
+		t.Fatalf("new sqlite app: %v", err)
+	}
+	t.Cleanup(func() { _ = app.Close() })
+	server := httptest.NewServer(app.Handler())
+	t.Cleanup(server.Close)
+
+	resp, err := http.PostForm(server.URL+"/switch-profile", url.Values{"profile_name": {"Alex"}})
+	if err != nil {
+		t.Fatalf("create profile: %v", err)
+	}
+	resp.Body.Close()
+	return server
+}
+
+func TestAddAndListItemsThroughTheAPI(t *testing.T) {
+	server := newTestServer(t)
+	configPath := filepath.Join(t.TempDir(), "ip.conf")
+	config := "# my server\nserver = " + server.URL + "/\nprofile = \"Alex\"\n"
+	if err := os.WriteFile(configPath, []byte(config), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	now := time.Now()
+	var out bytes.Buffer
+	if err := run([]string{"-config", configPath, "add", "Standing desk", "--price", "400", "--wait", "30d", "--tags", "Home"}, &out, now); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "Added #1 Standing desk, ready to buy ") {
+		t.Fatalf("unexpected add output %q", out.String())
+	}
+	out.Reset()
+	if err := run([]string{"-config", configPath, "add", "--wait", "48h", "Headphones"}, &out, now); err != nil {
+		t.Fatalf("add with custom hours: %v", err)
+	}
+
+	out.Reset()
+	if err := run([]string{"-config", configPath, "list"}, &out, now); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[1], "Headphones") || !strings.Contains(lines[1], "in 2d 0h") || !strings.Contains(lines[2], "400") || !strings.Contains(lines[2], "in 30d 0h") {
+		t.Fatalf("expected both items, soonest first, got:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := run([]string{"-config", configPath, "list", "--ready"}, &out, now); err != nil {
+		t.Fatalf("list ready: %v", err)
+	}
+	if strings.TrimSpace(out.String()) != "No items." {
+		t.Fatalf("expected no ready items, got %q", out.String())
+	}
+
+	err := run([]string{"-config", configPath, "add", "Lamp", "--wait", "whenever"}, &out, now)
+	if err == nil || !strings.Contains(err.Error(), "wait") {
+		t.Fatalf("expected the API's price error, got %v", err)
+	}
+}
+
+func TestConfigComesFromTheFileAndTheEnvironment(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "ip.conf")
+	if err := os.WriteFile(configPath, []byte("server = https://pause.example.com\ntoken = abc\n"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	t.Setenv("IP_PROFILE", "Sam")
+	cfg, err := loadConfig(configPath, true)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if cfg.Server != "https://pause.example.com" || cfg.Profile != "Sam" || cfg.Token != "abc" {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+
+	if _, err := loadConfig(filepath.Join(t.TempDir(), "missing.conf"), true); err == nil {
+		t.Fatal("expected an explicit missing config to fail")
+	}
+	if _, err := loadConfig(filepath.Join(t.TempDir(), "missing.conf"), false); err != nil {
+		t.Fatalf("expected the default config to be optional, got %v", err)
+	}
+	if err := os.WriteFile(configPath, []byte("colour = blue\n"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := loadConfig(configPath, true); err == nil || !strings.Contains(err.Error(), `unknown key "colour"`) {
+		t.Fatalf("expected an unknown key to be reported, got %v", err)
+	}
+}
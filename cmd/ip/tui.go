@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// tuiRefreshInterval is how often the dashboard reloads items, so items changed in the browser show up.
+const tuiRefreshInterval = 30 * time.Second
+
+// Terminal control sequences: the alternate screen keeps the shell's scrollback intact.
+const (
+	enterAltScreen = "\x1b[?1049h\x1b[?25l"
+	leaveAltScreen = "\x1b[?25h\x1b[?1049l"
+	clearScreen    = "\x1b[H\x1b[2J"
+)
+
+const tuiHelp = "↑/k ↓/j move  b buy  s skip  z snooze 24h  r refresh  q quit"
+
+// dashboard is the state of ip tui: the open items, the selected row and the last action's outcome.
+type dashboard struct {
+	c        *client
+	items    []item
+	selected int
+	message  string
+}
+
+// rawTerminal switches the terminal to raw mode, so single key presses arrive unbuffered, and returns a
+// function that restores the previous mode. It uses stty rather than a terminal library.
+func rawTerminal() (func(), error) {
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, errors.New("tui needs an interactive terminal")
+	}
+	if _, err := stty("raw", "-echo"); err != nil {
+		return nil, fmt.Errorf("switch terminal to raw mode: %w", err)
+	}
+	return func() { _, _ = stty(strings.TrimSpace(saved)) }, nil
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return string(out), err
+}
+
+// readKeys sends each key press read from in, with arrow keys as "up" and "down", and closes the channel
+// once in is exhausted.
+func readKeys(in io.Reader, keys chan<- string) {
+	defer close(keys)
+	r := bufio.NewReader(in)
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return
+		}
+		if b != 0x1b {
+			keys <- string(b)
+			continue
+		}
+		seq := make([]byte, 2)
+		if _, err := io.ReadFull(r, seq); err != nil {
+			return
+		}
+		switch string(seq) {
+		case "[A":
+			keys <- "up"
+		case "[B":
+			keys <- "down"
+		}
+	}
+}
+
+// runTUI shows waiting and ready items with live countdowns until q is pressed or in ends.
+func runTUI(c *client, in io.Reader, out io.Writer, clock func() time.Time) error {
+	d := &dashboard{c: c}
+	if err := d.load(); err != nil {
+		return err
+	}
+
+	fmt.Fprint(out, enterAltScreen)
+	defer fmt.Fprint(out, leaveAltScreen)
+
+	keys := make(chan string)
+	go readKeys(in, keys)
+	tick := time.NewTicker(time.Second)
+	defer tick.Stop()
+	lastLoad := clock()
+
+	for {
+		d.render(out, clock())
+		select {
+		case key, ok := <-keys:
+			if !ok || key == "q" || key == "\x03" {
+				return nil
+			}
+			d.handleKey(key)
+		case <-tick.C:
+			if now := clock(); now.Sub(lastLoad) >= tuiRefreshInterval {
+				lastLoad = now
+				if err := d.load(); err != nil {
+					d.message = err.Error()
+				}
+			}
+		}
+	}
+}
+
+// load fetches the open items that wait or are ready, soonest unlock first, and keeps the selection in range.
+func (d *dashboard) load() error {
+	items, err := d.c.listItems(url.Values{"sort": {"next_ready"}, "status": {"Ready to buy,Waiting"}})
+	if err != nil {
+		return err
+	}
+	d.items = items
+	d.selected = min(d.selected, max(len(items)-1, 0))
+	return nil
+}
+
+func (d *dashboard) handleKey(key string) {
+	switch key {
+	case "up", "k":
+		d.selected = max(d.selected-1, 0)
+	case "down", "j":
+		d.selected = min(d.selected+1, max(len(d.items)-1, 0))
+	case "r":
+		d.message = ""
+		if err := d.load(); err != nil {
+			d.message = err.Error()
+		}
+	case "b":
+		d.act("Bought", func(it item) (item, error) { return d.c.decideItem(it.ID, "Bought") })
+	case "s":
+		d.act("Skipped", func(it item) (item, error) { return d.c.decideItem(it.ID, "Skipped") })
+	case "z":
+		d.act("Snoozed for 24 hours", func(it item) (item, error) { return d.c.snoozeItem(it.ID) })
+	}
+}
+
+// act applies an action to the selected item, which must be ready to buy, and reloads the list.
+func (d *dashboard) act(done string, action func(item) (item, error)) {
+	if len(d.items) == 0 {
+		return
+	}
+	it := d.items[d.selected]
+	if it.Status != "Ready to buy" {
+		d.message = fmt.Sprintf("%s is still waiting.", it.Title)
+		return
+	}
+	if _, err := action(it); err != nil {
+		d.message = err.Error()
+		return
+	}
+	d.message = fmt.Sprintf("%s: %s.", done, it.Title)
+	if err := d.load(); err != nil {
+		d.message = err.Error()
+	}
+}
+
+// render draws the whole screen. Raw mode needs explicit carriage returns, so lines end in \r\n.
+func (d *dashboard) render(out io.Writer, now time.Time) {
+	var b strings.Builder
+	b.WriteString(clearScreen)
+	fmt.Fprintf(&b, "Impulse Pause  %s\n\n", now.Format("Mon 02.01. 15:04:05"))
+	if len(d.items) == 0 {
+		b.WriteString("  Nothing is waiting. Add items with ip add.\n")
+	}
+	for i, it := range d.items {
+		cursor := "  "
+		if i == d.selected {
+			cursor = "> "
+		}
+		countdown := "ready"
+		if it.Status == "Waiting" {
+			countdown = unlockLabel(it, now)
+		}
+		fmt.Fprintf(&b, "%s%-10s %10s  %s\n", cursor, countdown, orDash(it.Price), it.Title)
+	}
+	b.WriteString("\n")
+	if d.message != "" {
+		b.WriteString(d.message + "\n")
+	}
+	b.WriteString(tuiHelp + "\n")
+	fmt.Fprint(out, strings.ReplaceAll(b.String(), "\n", "\r\n"))
+}
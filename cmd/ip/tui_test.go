@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTUIShowsCountdownsAndBuysTheSelectedItem(t *testing.T) {
+	server := newTestServer(t)
+	c := newClient(config{Server: server.URL, Profile: "Alex"})
+	// Backdating the item makes its day-long wait already over.
+	ready := map[string]string{"title": "Old lamp", "wait_preset": "24h", "created_at": "2020-01-01"}
+	if err := c.do("POST", "/api/v1/items", ready, nil); err != nil {
+		t.Fatalf("add ready item: %v", err)
+	}
+	if _, err := c.addItem(itemInput{Title: "Standing desk", Price: "400", WaitPreset: "7d"}); err != nil {
+		t.Fatalf("add waiting item: %v", err)
+	}
+
+	now := time.Now()
+	var out bytes.Buffer
+	// Buying the waiting desk is refused, then the lamp above it is bought.
+	keys := "jb\x1b[Ab"
+	if err := runTUI(c, strings.NewReader(keys), &out, func() time.Time { return now }); err != nil {
+		t.Fatalf("tui: %v", err)
+	}
+	screen := out.String()
+	for _, want := range []string{"> ready", "in 6d 23h", "Standing desk is still waiting.", "Bought: Old lamp.", "b buy"} {
+		if !strings.Contains(screen, want) {
+			t.Fatalf("expected %q on screen, got:\n%s", want, screen)
+		}
+	}
+
+	items, err := c.listItems(url.Values{"status": {"Bought"}})
+	if err != nil {
+		t.Fatalf("list bought items: %v", err)
+	}
+	if len(items) != 1 || items[0].Title != "Old lamp" {
+		t.Fatalf("expected the lamp to be bought, got %+v", items)
+	}
+}
@@ -0,0 +1,302 @@
+// Command loadtest seeds a profile with items through the JSON API and measures response times of the
+// dashboard, the insights page and the items API under concurrent requests.
+//
+//	loadtest -server http://localhost:8080 -items 5000 -duration 30s -check
+//
+// With -check it exits with status 1 when an endpoint misses its p95 target.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// endpoint is a page measured by the load test, with the p95 response time it should stay under at the
+// documented capacity (see "Capacity targets" in the README).
+type endpoint struct {
+	Name   string
+	Path   string
+	Target time.Duration
+}
+
+var endpoints = []endpoint{
+	{Name: "dashboard", Path: "/", Target: 500 * time.Millisecond},
+	{Name: "insights", Path: "/insights", Target: 400 * time.Millisecond},
+	{Name: "items API", Path: "/api/v1/items?limit=100", Target: 200 * time.Millisecond},
+}
+
+var (
+	seedTags   = []string{"Tech", "Home", "Clothing", "Hobby", "Sports", "Books", "Gifts"}
+	seedTitles = []string{"Headphones", "Desk lamp", "Running shoes", "Board game", "Jacket", "Camera", "Coffee grinder", "Novel"}
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "loadtest:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("loadtest", flag.ContinueOnError)
+	server := fs.String("server", "http://localhost:8080", "base URL of the instance to test")
+	profile := fs.String("profile", "Load test", "profile to seed and measure; created if missing")
+	items := fs.Int("items", 5000, "items to seed before measuring; 0 measures the profile as it is")
+	concurrency := fs.Int("concurrency", 8, "concurrent clients")
+	duration := fs.Duration("duration", 30*time.Second, "how long to send requests")
+	check := fs.Bool("check", false, "fail when an endpoint misses its p95 target")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *concurrency < 1 {
+		return errors.New("-concurrency must be at least 1")
+	}
+
+	c := &client{baseURL: strings.TrimRight(*server, "/"), profile: *profile, http: &http.Client{Timeout: 30 * time.Second}}
+	if err := c.waitUntilHealthy(30 * time.Second); err != nil {
+		return err
+	}
+	if err := c.enterProfile(); err != nil {
+		return err
+	}
+	if *items > 0 {
+		start := time.Now()
+		if err := seed(c, *items, *concurrency, time.Now()); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "seeded %d items in %s\n", *items, time.Since(start).Round(time.Millisecond))
+	}
+
+	results := measure(c, endpoints, *concurrency, *duration)
+	missed := report(stdout, results)
+	if *check && len(missed) > 0 {
+		return fmt.Errorf("p95 target missed for %s", strings.Join(missed, ", "))
+	}
+	return nil
+}
+
+// seedItem returns the i-th seeded item: about a third are still waiting, the rest were bought or
+// skipped over the last two years, so the insights page has history to chart.
+func seedItem(i int, rng *rand.Rand, now time.Time) map[string]any {
+	item := map[string]any{
+		"title": fmt.Sprintf("%s %d", seedTitles[i%len(seedTitles)], i+1),
+		"price": fmt.Sprintf("%d.%02d", 5+rng.Intn(500), rng.Intn(100)),
+		"tags":  []string{seedTags[rng.Intn(len(seedTags))]},
+	}
+	if i%3 == 0 {
+		item["wait_preset"] = []string{"24h", "7d", "30d"}[rng.Intn(3)]
+		return item
+	}
+	created := now.AddDate(0, 0, -31-rng.Intn(700))
+	item["created_at"] = created.Format("2006-01-02")
+	item["decided_at"] = created.AddDate(0, 0, 1+rng.Intn(30)).Format("2006-01-02")
+	item["decision"] = []string{"Bought", "Skipped"}[rng.Intn(2)]
+	item["wait_preset"] = "24h"
+	return item
+}
+
+func seed(c *client, count, concurrency int, now time.Time) error {
+	jobs := make(chan int)
+	errs := make(chan error, concurrency)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(int64(w)))
+			for i := range jobs {
+				if err := c.createItem(seedItem(i, rng, now)); err != nil {
+					errs <- fmt.Errorf("seed item %d: %w", i+1, err)
+					for range jobs {
+					}
+					return
+				}
+			}
+		}(w)
+	}
+	for i := 0; i < count; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	close(errs)
+	return <-errs
+}
+
+// result holds the response times of one endpoint.
+type result struct {
+	Endpoint  endpoint
+	Durations []time.Duration
+	Errors    int
+}
+
+// measure sends requests to the endpoints in turn from concurrency clients until duration has passed.
+func measure(c *client, endpoints []endpoint, concurrency int, duration time.Duration) []result {
+	results := make([]result, len(endpoints))
+	for i, e := range endpoints {
+		results[i].Endpoint = e
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	deadline := time.Now().Add(duration)
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for n := w; time.Now().Before(deadline); n++ {
+				i := n % len(endpoints)
+				took, err := c.timeGet(endpoints[i].Path)
+				mu.Lock()
+				if err != nil {
+					results[i].Errors++
+				} else {
+					results[i].Durations = append(results[i].Durations, took)
+				}
+				mu.Unlock()
+			}
+		}(w)
+	}
+	wg.Wait()
+	return results
+}
+
+// percentile returns the response time that p percent of the sorted durations stay under.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	index := int(float64(len(sorted))*p/100+0.5) - 1
+	return sorted[max(0, min(index, len(sorted)-1))]
+}
+
+// report prints a table of the results and returns the endpoints that missed their target, including
+// endpoints whose requests failed.
+func report(w io.Writer, results []result) []string {
+	var missed []string
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ENDPOINT\tREQUESTS\tERRORS\tP50\tP95\tP99\tTARGET P95\t")
+	for _, r := range results {
+		sorted := slices.Clone(r.Durations)
+		slices.Sort(sorted)
+		p95 := percentile(sorted, 95)
+		verdict := "ok"
+		if r.Errors > 0 || len(sorted) == 0 || p95 > r.Endpoint.Target {
+			verdict = "MISSED"
+			missed = append(missed, r.Endpoint.Name)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%s\t%s %s\t\n", r.Endpoint.Name, len(sorted), r.Errors,
+			round(percentile(sorted, 50)), round(p95), round(percentile(sorted, 99)), r.Endpoint.Target, verdict)
+	}
+	tw.Flush()
+	return missed
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(100 * time.Microsecond)
+}
+
+// client sends requests as the load test profile, like cmd/ip does.
+type client struct {
+	baseURL string
+	profile string
+	http    *http.Client
+}
+
+func (c *client) waitUntilHealthy(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		resp, err := c.http.Get(c.baseURL + "/healthz")
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%s is not reachable: %v", c.baseURL, err)
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+// enterProfile creates the profile through the profile switch form, which also finishes its setup.
+func (c *client) enterProfile() error {
+	noRedirect := *c.http
+	noRedirect.CheckRedirect = func(req *http.Request, via []*http.Request) error { return http.ErrUseLastResponse }
+	resp, err := noRedirect.PostForm(c.baseURL+"/switch-profile", url.Values{"profile_name": {c.profile}})
+	if err != nil {
+		return fmt.Errorf("enter profile: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther {
+		return fmt.Errorf("enter profile: server answered %s", resp.Status)
+	}
+	return nil
+}
+
+func (c *client) createItem(item map[string]any) error {
+	body, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	req, err := c.newRequest(http.MethodPost, "/api/v1/items", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("server answered %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// timeGet returns how long a GET took until its whole body was read.
+func (c *client) timeGet(path string) (time.Duration, error) {
+	req, err := c.newRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return 0, err
+	}
+	start := time.Now()
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	_, err = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	took := time.Since(start)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("GET %s: %s", path, resp.Status)
+	}
+	return took, nil
+}
+
+func (c *client) newRequest(method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.AddCookie(&http.Cookie{Name: "active_profile", Value: c.profile})
+	return req, nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPercentileUsesTheNearestRank(t *testing.T) {
+	var sorted []time.Duration
+	for i := 1; i <= 20; i++ {
+		sorted = append(sorted, time.Duration(i)*time.Millisecond)
+	}
+	if got := percentile(sorted, 95); got != 19*time.Millisecond {
+		t.Fatalf("expected p95 of 1..20 ms to be 19ms, got %s", got)
+	}
+	if got := percentile(sorted, 50); got != 10*time.Millisecond {
+		t.Fatalf("expected p50 of 1..20 ms to be 10ms, got %s", got)
+	}
+	if got := percentile(sorted[:1], 99); got != time.Millisecond {
+		t.Fatalf("expected a single duration to be every percentile, got %s", got)
+	}
+	if got := percentile(nil, 95); got != 0 {
+		t.Fatalf("expected 0 without durations, got %s", got)
+	}
+}
+
+func TestReportListsEndpointsThatMissTheirTarget(t *testing.T) {
+	fast := endpoint{Name: "fast", Path: "/fast", Target: 100 * time.Millisecond}
+	slow := endpoint{Name: "slow", Path: "/slow", Target: 100 * time.Millisecond}
+	failing := endpoint{Name: "failing", Path: "/failing", Target: 100 * time.Millisecond}
+
+	var out strings.Builder
+	missed := report(&out, []result{
+		{Endpoint: fast, Durations: []time.Duration{40 * time.Millisecond, 20 * time.Millisecond, 90 * time.Millisecond}},
+		{Endpoint: slow, Durations: []time.Duration{20 * time.Millisecond, 300 * time.Millisecond}},
+		{Endpoint: failing, Durations: []time.Duration{10 * time.Millisecond}, Errors: 1},
+	})
+	if strings.Join(missed, ",") != "slow,failing" {
+		t.Fatalf("expected slow and failing to miss their target, got %v", missed)
+	}
+	if !strings.Contains(out.String(), "90ms") || !strings.Contains(out.String(), "MISSED") {
+		t.Fatalf("expected the table to show the p95 and the verdict, got:\n%s", out.String())
+	}
+}
+
+func TestSeedItemsDecideTwoThirdsInThePast(t *testing.T) {
+	now := time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+	rng := rand.New(rand.NewSource(1))
+	waiting := 0
+	for i := 0; i < 300; i++ {
+		item := seedItem(i, rng, now)
+		decided, ok := item["decided_at"].(string)
+		if !ok {
+			waiting++
+			continue
+		}
+		at, err := time.Parse("2006-01-02", decided)
+		if err != nil || !at.Before(now) {
+			t.Fatalf("expected the decision date %q to be in the past", decided)
+		}
+	}
+	if waiting != 100 {
+		t.Fatalf("expected a third of the items to wait, got %d of 300", waiting)
+	}
+}
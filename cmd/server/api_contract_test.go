@@ -0,0 +1,325 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPublicAPIContract checks the JSON API as clients see it: the server binary is built and started on
+// a fresh data directory, or API_CONTRACT_URL points at an instance that is already running (with
+// API_CONTRACT_ADMIN_TOKEN for its admin checks). Each run works in its own new profile.
+func TestPublicAPIContract(t *testing.T) {
+	if os.Getenv("RUN_API_CONTRACT_TESTS") != "1" {
+		t.Skip("set RUN_API_CONTRACT_TESTS=1 to run the API contract tests")
+	}
+
+	baseURL := strings.TrimRight(os.Getenv("API_CONTRACT_URL"), "/")
+	adminToken := os.Getenv("API_CONTRACT_ADMIN_TOKEN")
+	if baseURL == "" {
+		adminToken = "contract-admin"
+		baseURL = startContractServer(t, adminToken)
+	}
+	c := &contractClient{t: t, baseURL: baseURL, profile: fmt.Sprintf("Contract %d", time.Now().UnixNano())}
+
+	t.Run("auth", func(t *testing.T) {
+		c := c.with(t)
+		res := c.do(http.MethodGet, "/api/v1/home-assistant?token=not-a-share-token", nil, nil)
+		res.expectProblem(http.StatusNotFound)
+
+		if adminToken == "" {
+			t.Log("admin checks skipped: API_CONTRACT_ADMIN_TOKEN is not set")
+		} else {
+			res = c.do(http.MethodGet, "/household", nil, nil)
+			res.expectStatus(http.StatusUnauthorized)
+			if got := res.header.Get("WWW-Authenticate"); !strings.HasPrefix(got, "Bearer") {
+				t.Fatalf("expected a Bearer challenge, got %q", got)
+			}
+			c.do(http.MethodGet, "/household", nil, map[string]string{"Authorization": "Bearer " + adminToken}).expectStatus(http.StatusOK)
+		}
+
+		c.createProfile()
+		c.do(http.MethodGet, "/api/v1/items", nil, nil).expectStatus(http.StatusOK)
+	})
+
+	t.Run("items", func(t *testing.T) {
+		c := c.with(t)
+		res := c.do(http.MethodPost, "/api/v1/items", map[string]any{"title": "Contract lamp", "price": "39.90", "tags": []string{"Home"}, "wait_preset": "7d"}, nil)
+		res.expectStatus(http.StatusCreated)
+		var item contractItem
+		res.decode(&item)
+		if item.ID == 0 || item.Title != "Contract lamp" || item.Status != "Waiting" || item.WaitPreset != "7d" || item.PriceCents != 3990 ||
+			len(item.Tags) != 1 || item.Tags[0] != "Home" || item.PurchaseAllowedAt == nil || item.CreatedAt.IsZero() {
+			t.Fatalf("unexpected item %+v", item)
+		}
+
+		c.do(http.MethodPost, fmt.Sprintf("/api/v1/items/%d/decision", item.ID), map[string]any{"status": "Bought"}, nil).expectProblem(http.StatusConflict)
+		c.do(http.MethodPost, "/api/v1/items/999999999/decision", map[string]any{"status": "Bought"}, nil).expectProblem(http.StatusNotFound)
+	})
+
+	t.Run("idempotency", func(t *testing.T) {
+		c := c.with(t)
+		key := map[string]string{"Idempotency-Key": fmt.Sprintf("contract-%d", time.Now().UnixNano())}
+		body := map[string]any{"title": "Contract desk", "wait_preset": "24h"}
+
+		first := c.do(http.MethodPost, "/api/v1/items", body, key)
+		first.expectStatus(http.StatusCreated)
+		replay := c.do(http.MethodPost, "/api/v1/items", body, key)
+		replay.expectStatus(http.StatusCreated)
+		if replay.header.Get("Idempotent-Replayed") != "true" || !bytes.Equal(replay.body, first.body) {
+			t.Fatalf("expected the first response to be replayed, got %s", replay.body)
+		}
+		c.do(http.MethodPost, "/api/v1/items", map[string]any{"title": "Another desk", "wait_preset": "24h"}, key).expectProblem(http.StatusUnprocessableEntity)
+	})
+
+	t.Run("pagination", func(t *testing.T) {
+		c := c.with(t)
+		for i := range 3 {
+			c.do(http.MethodPost, "/api/v1/items", map[string]any{"title": fmt.Sprintf("Contract page item %d", i), "wait_preset": "30d"}, nil).expectStatus(http.StatusCreated)
+		}
+
+		seen := map[int]bool{}
+		path := "/api/v1/items?limit=2&sort=oldest"
+		for pages := 0; path != ""; pages++ {
+			if pages > 10 {
+				t.Fatalf("pagination did not end")
+			}
+			res := c.do(http.MethodGet, path, nil, nil)
+			res.expectStatus(http.StatusOK)
+			var page struct {
+				Items      []contractItem `json:"items"`
+				NextCursor string         `json:"next_cursor"`
+			}
+			res.decode(&page)
+			if len(page.Items) > 2 {
+				t.Fatalf("expected at most 2 items per page, got %d", len(page.Items))
+			}
+			for _, item := range page.Items {
+				if seen[item.ID] {
+					t.Fatalf("item %d was returned on two pages", item.ID)
+				}
+				seen[item.ID] = true
+			}
+			path = ""
+			if page.NextCursor != "" {
+				path = "/api/v1/items?limit=2&sort=oldest&cursor=" + url.QueryEscape(page.NextCursor)
+			}
+		}
+		// The lamp, the desk and the three page items.
+		if len(seen) != 5 {
+			t.Fatalf("expected all 5 items across the pages, got %d", len(seen))
+		}
+
+		res := c.do(http.MethodGet, "/api/v1/items", nil, nil)
+		res.expectStatus(http.StatusOK)
+		etag := res.header.Get("ETag")
+		if etag == "" {
+			t.Fatalf("expected an ETag on the item list")
+		}
+		c.do(http.MethodGet, "/api/v1/items", nil, map[string]string{"If-None-Match": etag}).expectStatus(http.StatusNotModified)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		c := c.with(t)
+		for field, body := range map[string]map[string]any{
+			"title":       {"title": " "},
+			"wait_preset": {"title": "Contract chair", "wait_preset": "forever"},
+		} {
+			problem := c.do(http.MethodPost, "/api/v1/items", body, nil).expectProblem(http.StatusUnprocessableEntity)
+			if len(problem.Fields) != 1 || problem.Fields[0].Field != field || problem.Fields[0].Message == "" {
+				t.Fatalf("expected %s to be rejected with a message, got %+v", field, problem.Fields)
+			}
+		}
+
+		c.do(http.MethodPost, "/api/v1/items", []byte("{not json"), nil).expectProblem(http.StatusBadRequest)
+		c.do(http.MethodGet, "/api/v1/items?sort=sideways", nil, nil).expectProblem(http.StatusBadRequest)
+	})
+}
+
+// startContractServer builds the server binary and runs it on a free port until the test ends.
+func startContractServer(t *testing.T, adminToken string) string {
+	t.Helper()
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "server")
+	build := exec.Command("go", "build", "-o", binary, ".")
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("build server: %v\n%s", err, out)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("find a free port: %v", err)
+	}
+	port := fmt.Sprint(listener.Addr().(*net.TCPAddr).Port)
+	listener.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var logs bytes.Buffer
+	cmd := exec.CommandContext(ctx, binary)
+	cmd.Env = append(os.Environ(), "PORT="+port, "DATA_DIR="+filepath.Join(dir, "data"), "DB_PATH=", "ADMIN_TOKEN="+adminToken, "GRPC_PORT=")
+	cmd.Stdout, cmd.Stderr = &logs, &logs
+	if err := cmd.Start(); err != nil {
+		cancel()
+		t.Fatalf("start server: %v", err)
+	}
+	t.Cleanup(func() {
+		cancel()
+		_ = cmd.Wait()
+		if t.Failed() {
+			t.Logf("server output:\n%s", logs.String())
+		}
+	})
+
+	baseURL := "http://127.0.0.1:" + port
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		resp, err := http.Get(baseURL + "/healthz")
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return baseURL
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server not reachable on %s within timeout", baseURL)
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+type contractItem struct {
+	ID                int        `json:"id"`
+	Title             string     `json:"title"`
+	PriceCents        int64      `json:"price_cents"`
+	Tags              []string   `json:"tags"`
+	Status            string     `json:"status"`
+	WaitPreset        string     `json:"wait_preset"`
+	PurchaseAllowedAt *time.Time `json:"purchase_allowed_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// contractProblem is the RFC 7807 body of every API error.
+type contractProblem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail"`
+	Error  string `json:"error"`
+	Fields []struct {
+		Field   string `json:"field"`
+		Message string `json:"message"`
+	} `json:"fields"`
+}
+
+// contractClient sends requests as its profile, like cmd/ip does.
+type contractClient struct {
+	t       *testing.T
+	baseURL string
+	profile string
+}
+
+func (c *contractClient) with(t *testing.T) *contractClient {
+	clone := *c
+	clone.t = t
+	return &clone
+}
+
+// createProfile creates the client's profile through the profile switch form.
+func (c *contractClient) createProfile() {
+	c.t.Helper()
+	client := &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.PostForm(c.baseURL+"/switch-profile", url.Values{"profile_name": {c.profile}})
+	if err != nil {
+		c.t.Fatalf("create profile: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther {
+		c.t.Fatalf("expected 303 when creating the profile, got %d", resp.StatusCode)
+	}
+}
+
+// do sends body as JSON, or as is when it is a []byte.
+func (c *contractClient) do(method, path string, body any, headers map[string]string) contractResponse {
+	c.t.Helper()
+	var reader io.Reader
+	if body != nil {
+		raw, ok := body.([]byte)
+		if !ok {
+			var err error
+			if raw, err = json.Marshal(body); err != nil {
+				c.t.Fatalf("encode request body: %v", err)
+			}
+		}
+		reader = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		c.t.Fatalf("new request: %v", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.AddCookie(&http.Cookie{Name: "active_profile", Value: c.profile})
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.t.Fatalf("read %s %s: %v", method, path, err)
+	}
+	return contractResponse{t: c.t, request: method + " " + path, status: resp.StatusCode, header: resp.Header, body: raw}
+}
+
+type contractResponse struct {
+	t       *testing.T
+	request string
+	status  int
+	header  http.Header
+	body    []byte
+}
+
+func (r contractResponse) expectStatus(status int) {
+	r.t.Helper()
+	if r.status != status {
+		r.t.Fatalf("%s: expected status %d, got %d: %s", r.request, status, r.status, r.body)
+	}
+}
+
+func (r contractResponse) decode(v any) {
+	r.t.Helper()
+	if err := json.Unmarshal(r.body, v); err != nil {
+		r.t.Fatalf("%s: decode %s: %v", r.request, r.body, err)
+	}
+}
+
+// expectProblem checks the status and that the body is a complete problem document for it.
+func (r contractResponse) expectProblem(status int) contractProblem {
+	r.t.Helper()
+	r.expectStatus(status)
+	if got := r.header.Get("Content-Type"); !strings.HasPrefix(got, "application/problem+json") {
+		r.t.Fatalf("%s: expected application/problem+json, got %q", r.request, got)
+	}
+	var problem contractProblem
+	r.decode(&problem)
+	if problem.Type == "" || problem.Title != http.StatusText(status) || problem.Status != status || problem.Detail == "" || problem.Error != problem.Detail {
+		r.t.Fatalf("%s: incomplete problem %+v", r.request, problem)
+	}
+	return problem
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"mvpapp/internal/web"
+)
+
+// minAdminTokenLength keeps the instance-wide admin token out of guessing range.
+const minAdminTokenLength = 12
+
+// endpointProbeTimeout bounds the startup reachability check of all notification endpoints together.
+const endpointProbeTimeout = 3 * time.Second
+
+// configProblems collects every invalid setting, so a misconfigured deployment is fixed in one round
+// instead of one restart per variable.
+type configProblems []string
+
+func (p *configProblems) add(format string, args ...any) {
+	*p = append(*p, fmt.Sprintf(format, args...))
+}
+
+func (p configProblems) err() error {
+	if len(p) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid configuration:\n  - %s", strings.Join(p, "\n  - "))
+}
+
+// validateEnv checks the environment before anything starts: ports, URLs, tokens, durations, switches,
+// hooks, web push keys, job settings and the data directory. It reports all problems at once.
+func validateEnv() error {
+	var problems configProblems
+
+	for _, name := range []string{"PORT", "GRPC_PORT"} {
+		if raw := os.Getenv(name); raw != "" {
+			if port, err := strconv.Atoi(raw); err != nil || port < 0 || port > 65535 {
+				problems.add("%s %q must be a port number between 0 and 65535", name, raw)
+			}
+		}
+	}
+	if port, grpcPort := os.Getenv("PORT"), os.Getenv("GRPC_PORT"); grpcPort != "" && grpcPort != "0" && grpcPort == port {
+		problems.add("GRPC_PORT %q must differ from PORT", grpcPort)
+	}
+
+	for _, name := range []string{"DASHBOARD_URL", "OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"} {
+		if raw := os.Getenv(name); raw != "" {
+			if u, err := url.Parse(raw); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				problems.add("%s %q must be an absolute http:// or https:// URL", name, raw)
+			}
+		}
+	}
+
+	if token := strings.TrimSpace(os.Getenv("ADMIN_TOKEN")); token != "" {
+		switch {
+		case token == "change-me":
+			problems.add("ADMIN_TOKEN is still the example value, generate one with: openssl rand -hex 16")
+		case len(token) < minAdminTokenLength:
+			problems.add("ADMIN_TOKEN must be at least %d characters, generate one with: openssl rand -hex 16", minAdminTokenLength)
+		}
+	}
+
+	for _, name := range []string{"INVITE_ONLY", "NOTIFICATIONS_DRY_RUN", "PUBLIC_STATS", "DEMO_MODE"} {
+		if raw := os.Getenv(name); raw != "" {
+			if _, err := strconv.ParseBool(raw); err != nil {
+				problems.add("%s %q must be true or false", name, raw)
+			}
+		}
+	}
+	for _, name := range []string{"SLOW_QUERY_THRESHOLD", "REQUEST_SLO", "DEMO_RESET_INTERVAL", "NOTIFY_COALESCE_WINDOW"} {
+		if raw := os.Getenv(name); raw != "" {
+			if d, err := time.ParseDuration(raw); err != nil || d <= 0 {
+				problems.add("%s %q must be a positive duration such as 500ms or 1h", name, raw)
+			}
+		}
+	}
+	if raw := os.Getenv("MAX_ITEMS_PER_PROFILE"); raw != "" {
+		if n, err := strconv.Atoi(raw); err != nil || n < 0 {
+			problems.add("MAX_ITEMS_PER_PROFILE %q must be a whole number, 0 for no limit", raw)
+		}
+	}
+	if raw := os.Getenv("MAX_RECEIPT_MB_PER_PROFILE"); raw != "" {
+		if n, err := strconv.Atoi(raw); err != nil || n < 0 {
+			problems.add("MAX_RECEIPT_MB_PER_PROFILE %q must be a whole number of megabytes, 0 for no limit", raw)
+		}
+	}
+	if _, err := sqliteOptionsFromEnv(); err != nil {
+		problems.add("%v", err)
+	}
+
+	if hook := os.Getenv("ITEM_HOOK_COMMAND"); hook != "" {
+		if _, err := exec.LookPath(hook); err != nil {
+			problems.add("ITEM_HOOK_COMMAND %q is not an executable file", hook)
+		}
+	}
+	if err := web.CheckItemHookArgs(os.Getenv("ITEM_HOOK_ARGS")); err != nil {
+		problems.add("ITEM_HOOK_ARGS: %v", err)
+	}
+	for _, path := range strings.Split(os.Getenv("NOTIFIER_PLUGINS"), ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		if _, err := exec.LookPath(path); err != nil {
+			problems.add("NOTIFIER_PLUGINS entry %q is not an executable file", path)
+		}
+	}
+	if err := web.CheckWebPushKeys(os.Getenv("VAPID_PUBLIC_KEY"), os.Getenv("VAPID_PRIVATE_KEY"), os.Getenv("VAPID_SUBJECT")); err != nil {
+		problems.add("VAPID_PUBLIC_KEY, VAPID_PRIVATE_KEY and VAPID_SUBJECT are not a valid web push configuration: %v", err)
+	}
+	validateJobEnv(&problems)
+
+	if dir := os.Getenv("DATA_DIR"); dir != "" {
+		if err := checkWritableDir(dir); err != nil {
+			problems.add("DATA_DIR %q is not a writable directory: %v", dir, err)
+		}
+	}
+	return problems.err()
+}
+
+// validateJobEnv checks JOBS_DISABLED and every JOB_SCHEDULE_<NAME> and JOB_JITTER_<NAME> variable,
+// so a typo in a job name is reported instead of silently leaving the job on its default schedule.
+func validateJobEnv(problems *configProblems) {
+	suffixes := map[string]bool{}
+	for _, name := range web.KnownJobNames() {
+		suffixes[jobEnvSuffix(name)] = true
+	}
+	for _, name := range strings.Split(os.Getenv("JOBS_DISABLED"), ",") {
+		if name = strings.TrimSpace(name); name != "" && !slices.Contains(web.KnownJobNames(), name) {
+			problems.add("JOBS_DISABLED names unknown job %q, known jobs are %s", name, strings.Join(web.KnownJobNames(), ", "))
+		}
+	}
+
+	var names []string
+	for _, entry := range os.Environ() {
+		name, _, _ := strings.Cut(entry, "=")
+		if strings.HasPrefix(name, "JOB_SCHEDULE_") || strings.HasPrefix(name, "JOB_JITTER_") {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		raw := os.Getenv(name)
+		if raw == "" {
+			continue
+		}
+		suffix, isJitter := strings.CutPrefix(name, "JOB_JITTER_")
+		if !isJitter {
+			suffix = strings.TrimPrefix(name, "JOB_SCHEDULE_")
+		}
+		switch {
+		case !suffixes[suffix]:
+			problems.add("%s names unknown job %q, known jobs are %s", name, suffix, strings.Join(web.KnownJobNames(), ", "))
+		case isJitter && os.Getenv("JOB_SCHEDULE_"+suffix) == "":
+			problems.add("%s needs JOB_SCHEDULE_%s", name, suffix)
+		case isJitter:
+			if jitter, err := time.ParseDuration(raw); err != nil || jitter < 0 {
+				problems.add("%s %q must be a duration such as 10m", name, raw)
+			}
+		default:
+			if err := web.CheckJobSchedule(raw, 0); err != nil {
+				problems.add("%s %q is not a valid schedule: %v", name, raw, err)
+			}
+		}
+	}
+}
+
+// jobEnvSuffix is the NAME in JOB_SCHEDULE_<NAME> and JOB_JITTER_<NAME> for a job: its name in upper
+// case with "_" for "-".
+func jobEnvSuffix(name string) string {
+	return strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// checkWritableDir creates dir if needed and writes a scratch file to it, since a read-only volume
+// would otherwise only show up at the first database write.
+func checkWritableDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
+}
+
+// probeNotificationEndpoints warns about notification endpoints that cannot be reached. Profiles choose
+// these endpoints themselves, so an unreachable one is logged rather than stopping the server.
+func probeNotificationEndpoints(app *web.App) {
+	endpoints, err := app.NotificationEndpoints()
+	if err != nil {
+		log.Printf("could not list notification endpoints: %v", err)
+		return
+	}
+	for _, problem := range probeEndpoints(endpoints, endpointProbeTimeout) {
+		log.Printf("warning: %v", problem)
+	}
+}
+
+// probeEndpoints sends a HEAD request to each endpoint in parallel. Any HTTP answer counts as reachable;
+// only endpoints that fail to connect within timeout are reported.
+func probeEndpoints(endpoints []string, timeout time.Duration) []error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	client := &http.Client{Transport: web.OutboundTransport()}
+	problems := make([]error, len(endpoints))
+	var wg sync.WaitGroup
+	for i, endpoint := range endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, err := http.NewRequestWithContext(ctx, http.MethodHead, endpoint, nil)
+			if err != nil {
+				problems[i] = fmt.Errorf("notification endpoint %s is not a valid URL: %w", endpoint, err)
+				return
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				problems[i] = fmt.Errorf("notification endpoint %s is not reachable: %w", endpoint, err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	var unreachable []error
+	for _, problem := range problems {
+		if problem != nil {
+			unreachable = append(unreachable, problem)
+		}
+	}
+	return unreachable
+}
@@ -1,10 +1,16 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"mvpapp/internal/web"
 )
@@ -16,15 +22,44 @@ func main() {
 }
 
 func run() error {
-	dbPath := os.Getenv("DB_PATH")
-	if dbPath == "" {
-		dbPath = "data/app.db"
+	log.Printf("impulse pause %s", web.CurrentBuild())
+	if err := validateEnv(); err != nil {
+		return err
+	}
+
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "" {
+		serviceName := os.Getenv("OTEL_SERVICE_NAME")
+		if serviceName == "" {
+			serviceName = "impulse-pause"
+		}
+		shutdown, err := web.StartTracing(context.Background(), serviceName)
+		if err != nil {
+			return fmt.Errorf("failed to start tracing: %w", err)
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := shutdown(ctx); err != nil {
+				log.Printf("failed to flush traces: %v", err)
+			}
+		}()
+		log.Printf("exporting traces as %s", serviceName)
+	}
+
+	layout, err := dataLayoutFromEnv()
+	if err != nil {
+		return err
 	}
 
-	app, err := web.NewAppWithSQLite(dbPath)
+	sqliteOptions, err := sqliteOptionsFromEnv()
 	if err != nil {
-		return fmt.Errorf("failed to initialize database at %s: %w", dbPath, err)
+		return err
 	}
+	app, err := web.NewAppWithSQLiteOptions(layout.DBPath, sqliteOptions)
+	if err != nil {
+		return fmt.Errorf("failed to initialize database at %s: %w", layout.DBPath, err)
+	}
+	app.StartBackgroundBackups(layout.Backups, 24*time.Hour)
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -36,6 +71,96 @@ func run() error {
 		baseURL = fmt.Sprintf("http://localhost:%s", port)
 	}
 	app.SetDashboardURL(baseURL)
+	app.SetAdminToken(os.Getenv("ADMIN_TOKEN"))
+	app.SetStarterTags(os.Getenv("DEFAULT_TAGS"))
+	if inviteOnly, _ := strconv.ParseBool(os.Getenv("INVITE_ONLY")); inviteOnly {
+		app.SetInviteOnly(true)
+	}
+	if err := app.SetWebPushKeys(os.Getenv("VAPID_PUBLIC_KEY"), os.Getenv("VAPID_PRIVATE_KEY"), os.Getenv("VAPID_SUBJECT")); err != nil {
+		return fmt.Errorf("invalid web push configuration: %w", err)
+	}
+	if dryRun, _ := strconv.ParseBool(os.Getenv("NOTIFICATIONS_DRY_RUN")); dryRun {
+		app.SetNotificationsDryRun(true)
+		log.Printf("notifications dry run enabled, notifications are logged instead of sent")
+	}
+	if publicStats, _ := strconv.ParseBool(os.Getenv("PUBLIC_STATS")); publicStats {
+		app.SetPublicStats(true)
+	}
+	if err := app.SetItemHook(os.Getenv("ITEM_HOOK_COMMAND"), os.Getenv("ITEM_HOOK_ARGS")); err != nil {
+		return fmt.Errorf("invalid ITEM_HOOK_ARGS: %w", err)
+	}
+	for _, path := range strings.Split(os.Getenv("NOTIFIER_PLUGINS"), ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			web.RegisterNotifier("exec:"+path, web.ExecNotifier(path))
+		}
+	}
+
+	if raw := os.Getenv("MAX_ITEMS_PER_PROFILE"); raw != "" {
+		maxItems, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid MAX_ITEMS_PER_PROFILE %q: %w", raw, err)
+		}
+		app.SetItemQuota(maxItems)
+	}
+	if raw := os.Getenv("MAX_RECEIPT_MB_PER_PROFILE"); raw != "" {
+		maxMB, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid MAX_RECEIPT_MB_PER_PROFILE %q: %w", raw, err)
+		}
+		app.SetAttachmentQuota(int64(maxMB) << 20)
+	}
+	if raw := os.Getenv("SLOW_QUERY_THRESHOLD"); raw != "" {
+		threshold, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid SLOW_QUERY_THRESHOLD %q: %w", raw, err)
+		}
+		app.SetSlowQueryThreshold(threshold)
+	}
+	if raw := os.Getenv("NOTIFY_COALESCE_WINDOW"); raw != "" {
+		window, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid NOTIFY_COALESCE_WINDOW %q: %w", raw, err)
+		}
+		app.SetNotificationCoalesceWindow(window)
+	}
+	if raw := os.Getenv("REQUEST_SLO"); raw != "" {
+		slo, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid REQUEST_SLO %q: %w", raw, err)
+		}
+		app.SetRequestSLO(slo)
+	}
+
+	if demo, _ := strconv.ParseBool(os.Getenv("DEMO_MODE")); demo {
+		interval := time.Hour
+		if raw := os.Getenv("DEMO_RESET_INTERVAL"); raw != "" {
+			if interval, err = time.ParseDuration(raw); err != nil {
+				return fmt.Errorf("invalid DEMO_RESET_INTERVAL %q: %w", raw, err)
+			}
+		}
+		if err := app.EnableDemoMode(interval); err != nil {
+			return fmt.Errorf("failed to enable demo mode: %w", err)
+		}
+		log.Printf("demo mode enabled, demo profile resets every %s", interval)
+	}
+	if err := configureJobs(app); err != nil {
+		return err
+	}
+	app.StartJobs()
+	go probeNotificationEndpoints(app)
+
+	if grpcPort := os.Getenv("GRPC_PORT"); grpcPort != "" {
+		listener, err := net.Listen("tcp", ":"+grpcPort)
+		if err != nil {
+			return fmt.Errorf("failed to listen for grpc on port %s: %w", grpcPort, err)
+		}
+		go func() {
+			log.Printf("starting grpc server on :%s", grpcPort)
+			if err := app.GRPCServer().Serve(listener); err != nil {
+				log.Printf("grpc server stopped: %v", err)
+			}
+		}()
+	}
 
 	addr := ":" + port
 	log.Printf("starting server on %s", addr)
@@ -44,3 +169,96 @@ func run() error {
 	}
 	return nil
 }
+
+// dataLayout is where the server keeps its files. Everything lives under one writable directory, so a
+// single volume mount holds the database and its backups.
+type dataLayout struct {
+	Root    string
+	DBPath  string
+	Backups string
+}
+
+// dataLayoutFromEnv places the database at DATA_DIR/app.db (DATA_DIR defaults to "data") and creates the
+// backups subfolder next to it. DB_PATH still overrides the database file, for deployments configured
+// before DATA_DIR existed; without DATA_DIR their backups go next to that file, on the same volume.
+func dataLayoutFromEnv() (dataLayout, error) {
+	root := strings.TrimSpace(os.Getenv("DATA_DIR"))
+	dbPath := os.Getenv("DB_PATH")
+	switch {
+	case root == "" && dbPath != "":
+		root = filepath.Dir(dbPath)
+	case root == "":
+		root = "data"
+	}
+	layout := dataLayout{
+		Root:    root,
+		DBPath:  filepath.Join(root, "app.db"),
+		Backups: filepath.Join(root, "backups"),
+	}
+	if dbPath != "" {
+		layout.DBPath = dbPath
+	}
+	for _, dir := range []string{layout.Root, layout.Backups} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return dataLayout{}, fmt.Errorf("failed to create data directory %s: %w", dir, err)
+		}
+	}
+	return layout, nil
+}
+
+// sqliteOptionsFromEnv overrides the default SQLite tuning with the SQLITE_* variables that are set.
+func sqliteOptionsFromEnv() (web.SQLiteOptions, error) {
+	opts := web.DefaultSQLiteOptions()
+	if raw := os.Getenv("SQLITE_JOURNAL_MODE"); raw != "" {
+		opts.JournalMode = strings.ToLower(strings.TrimSpace(raw))
+	}
+	if raw := os.Getenv("SQLITE_BUSY_TIMEOUT"); raw != "" {
+		timeout, err := time.ParseDuration(raw)
+		if err != nil {
+			return opts, fmt.Errorf("invalid SQLITE_BUSY_TIMEOUT %q: %w", raw, err)
+		}
+		opts.BusyTimeout = timeout
+	}
+	for name, target := range map[string]*int{"SQLITE_MAX_OPEN_CONNS": &opts.MaxOpenConns, "SQLITE_MAX_IDLE_CONNS": &opts.MaxIdleConns} {
+		if raw := os.Getenv(name); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil {
+				return opts, fmt.Errorf("invalid %s %q: %w", name, raw, err)
+			}
+			*target = n
+		}
+	}
+	if os.Getenv("SQLITE_MAX_IDLE_CONNS") == "" && opts.MaxIdleConns > opts.MaxOpenConns {
+		opts.MaxIdleConns = opts.MaxOpenConns
+	}
+	return opts, nil
+}
+
+// configureJobs applies JOBS_DISABLED, a comma-separated list of job names, and the JOB_SCHEDULE_<NAME>
+// and JOB_JITTER_<NAME> variables of the registered jobs; validateEnv has checked them already. Jobs
+// the app did not register, such as "demo-reset" outside demo mode, keep their settings unused.
+func configureJobs(app *web.App) error {
+	for _, name := range strings.Split(os.Getenv("JOBS_DISABLED"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			app.SetJobEnabled(name, false)
+		}
+	}
+	for _, name := range app.JobNames() {
+		suffix := jobEnvSuffix(name)
+		schedule := os.Getenv("JOB_SCHEDULE_" + suffix)
+		if schedule == "" {
+			continue
+		}
+		var jitter time.Duration
+		if raw := os.Getenv("JOB_JITTER_" + suffix); raw != "" {
+			var err error
+			if jitter, err = time.ParseDuration(raw); err != nil {
+				return fmt.Errorf("invalid JOB_JITTER_%s %q: %w", suffix, raw, err)
+			}
+		}
+		if err := app.SetJobSchedule(name, schedule, jitter); err != nil {
+			return fmt.Errorf("invalid JOB_SCHEDULE_%s %q: %w", suffix, schedule, err)
+		}
+	}
+	return nil
+}
@@ -1,13 +1,18 @@
 package main
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestRunReturnsClearErrorOnDatabaseInitializationFailure(t *testing.T) {
 	t.Setenv("PORT", "0")
+	t.Setenv("DATA_DIR", t.TempDir())
 	t.Setenv("DB_PATH", filepath.Dir(t.TempDir()))
 
 	err := run()
@@ -19,3 +24,110 @@ func TestRunReturnsClearErrorOnDatabaseInitializationFailure(t *testing.T) {
 		t.Fatalf("expected clear startup DB error prefix, got: %v", err)
 	}
 }
+
+func TestDataLayoutFromEnvCreatesSubfoldersUnderDataDir(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "volume")
+	t.Setenv("DATA_DIR", root)
+	t.Setenv("DB_PATH", "")
+
+	layout, err := dataLayoutFromEnv()
+	if err != nil {
+		t.Fatalf("data layout: %v", err)
+	}
+	if layout.DBPath != filepath.Join(root, "app.db") {
+		t.Fatalf("expected the database under DATA_DIR, got %+v", layout)
+	}
+	if info, err := os.Stat(layout.Backups); err != nil || !info.IsDir() || filepath.Dir(layout.Backups) != root {
+		t.Fatalf("expected %s to be created under DATA_DIR: %v", layout.Backups, err)
+	}
+
+	legacy := filepath.Join(t.TempDir(), "legacy.db")
+	t.Setenv("DB_PATH", legacy)
+	if layout, err := dataLayoutFromEnv(); err != nil || layout.DBPath != legacy || filepath.Dir(layout.Backups) != root {
+		t.Fatalf("expected DB_PATH to override the database file, got %+v, %v", layout, err)
+	}
+	t.Setenv("DATA_DIR", "")
+	layout, err = dataLayoutFromEnv()
+	if err != nil || layout.DBPath != legacy || layout.Backups != filepath.Join(filepath.Dir(legacy), "backups") {
+		t.Fatalf("expected backups next to the DB_PATH database without DATA_DIR, got %+v, %v", layout, err)
+	}
+
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	t.Setenv("DATA_DIR", file)
+	if _, err := dataLayoutFromEnv(); err == nil || !strings.Contains(err.Error(), "failed to create data directory") {
+		t.Fatalf("expected an unwritable DATA_DIR to fail clearly, got %v", err)
+	}
+}
+
+func TestSQLiteOptionsFromEnvKeepIdleConnectionsWithinTheLimit(t *testing.T) {
+	t.Setenv("SQLITE_JOURNAL_MODE", "DELETE")
+	t.Setenv("SQLITE_BUSY_TIMEOUT", "10s")
+	t.Setenv("SQLITE_MAX_OPEN_CONNS", "2")
+
+	opts, err := sqliteOptionsFromEnv()
+	if err != nil {
+		t.Fatalf("sqlite options: %v", err)
+	}
+	if opts.JournalMode != "delete" || opts.BusyTimeout.String() != "10s" || opts.MaxOpenConns != 2 || opts.MaxIdleConns != 2 {
+		t.Fatalf("unexpected options %+v", opts)
+	}
+
+	t.Setenv("SQLITE_MAX_IDLE_CONNS", "many")
+	if _, err := sqliteOptionsFromEnv(); err == nil || !strings.Contains(err.Error(), "SQLITE_MAX_IDLE_CONNS") {
+		t.Fatalf("expected the invalid variable to be named, got %v", err)
+	}
+}
+
+func TestValidateEnvListsEveryInvalidSetting(t *testing.T) {
+	t.Setenv("PORT", "80a")
+	t.Setenv("DASHBOARD_URL", "pause.example.com")
+	t.Setenv("ADMIN_TOKEN", "secret")
+	t.Setenv("PUBLIC_STATS", "sure")
+	t.Setenv("REQUEST_SLO", "-1s")
+	t.Setenv("ITEM_HOOK_COMMAND", filepath.Join(t.TempDir(), "missing-hook"))
+	t.Setenv("ITEM_HOOK_ARGS", "{{.Event")
+	t.Setenv("VAPID_PUBLIC_KEY", "not-a-key")
+	t.Setenv("JOBS_DISABLED", "purge,promotions")
+	t.Setenv("JOB_SCHEDULE_MAINTENANCE", "30 3 * *")
+	t.Setenv("JOB_JITTER_PURGE", "10m")
+	t.Setenv("JOB_SCHEDULE_CLEANUP", "@daily")
+
+	err := validateEnv()
+	if err == nil {
+		t.Fatal("expected the invalid settings to be rejected")
+	}
+	for _, name := range []string{"PORT", "DASHBOARD_URL", "ADMIN_TOKEN", "PUBLIC_STATS", "REQUEST_SLO", "ITEM_HOOK_COMMAND", "ITEM_HOOK_ARGS", "VAPID_PUBLIC_KEY", `"promotions"`, "JOB_SCHEDULE_MAINTENANCE", "JOB_JITTER_PURGE", "JOB_SCHEDULE_CLEANUP"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Fatalf("expected %s to be reported, got:\n%v", name, err)
+		}
+	}
+
+	for _, name := range []string{"PORT", "DASHBOARD_URL", "ADMIN_TOKEN", "PUBLIC_STATS", "REQUEST_SLO", "ITEM_HOOK_COMMAND", "VAPID_PUBLIC_KEY", "JOB_SCHEDULE_CLEANUP"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("ITEM_HOOK_ARGS", "{{.Event}} {{.Item.ID}}")
+	t.Setenv("JOBS_DISABLED", "purge, demo-reset")
+	t.Setenv("JOB_SCHEDULE_MAINTENANCE", "30 3 * * 0")
+	t.Setenv("JOB_SCHEDULE_PURGE", "@hourly")
+	t.Setenv("DATA_DIR", t.TempDir())
+	if err := validateEnv(); err != nil {
+		t.Fatalf("expected a valid environment, got %v", err)
+	}
+}
+
+func TestProbeEndpointsReportsOnlyUnreachableOnes(t *testing.T) {
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+	defer up.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	problems := probeEndpoints([]string{up.URL, down.URL}, time.Second)
+	if len(problems) != 1 || !strings.Contains(problems[0].Error(), down.URL) {
+		t.Fatalf("expected only the closed server to be reported, got %v", problems)
+	}
+}
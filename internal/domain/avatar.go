@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"hash/fnv"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// AvatarColors are the colors a profile avatar can take, in the order the settings page offers them.
+var AvatarColors = []string{"blue", "green", "teal", "orange", "red", "pink", "purple", "gray"}
+
+// maxAvatarEmojiRunes leaves room for emoji built from several code points, such as flags, skin tones
+// and ZWJ sequences like 👩‍💻, while keeping the avatar to a single symbol.
+const maxAvatarEmojiRunes = 8
+
+// ParseAvatarEmoji checks the emoji shown in a profile's avatar. Empty means the avatar shows the
+// profile's initial instead.
+func ParseAvatarEmoji(raw string) (string, error) {
+	emoji := strings.TrimSpace(raw)
+	if emoji == "" {
+		return "", nil
+	}
+	runes := []rune(emoji)
+	if len(runes) > maxAvatarEmojiRunes {
+		return "", invalid("avatar_emoji", "Please pick a single emoji for the avatar.")
+	}
+	for _, r := range runes {
+		if r <= unicode.MaxASCII || unicode.IsLetter(r) || unicode.IsSpace(r) {
+			return "", invalid("avatar_emoji", "Please pick a single emoji for the avatar.")
+		}
+	}
+	return emoji, nil
+}
+
+// ParseAvatarColor checks an avatar color against AvatarColors. Empty means DefaultAvatarColor.
+func ParseAvatarColor(raw string) (string, error) {
+	color := strings.ToLower(strings.TrimSpace(raw))
+	if color == "" || slices.Contains(AvatarColors, color) {
+		return color, nil
+	}
+	return "", invalid("avatar_color", "Please pick one of the offered avatar colors.")
+}
+
+// DefaultAvatarColor gives a profile without a chosen color a stable one derived from its name, so
+// profiles still look different before anyone picks a color.
+func DefaultAvatarColor(name string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(strings.ToLower(name)))
+	return AvatarColors[h.Sum32()%uint32(len(AvatarColors))]
+}
+
+// AvatarInitial is the symbol of an avatar without an emoji: the first letter or digit of the name.
+func AvatarInitial(name string) string {
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return string(unicode.ToUpper(r))
+		}
+	}
+	return "?"
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// History backdates an item when old purchases are imported or backfilled, so trends follow
+// the real dates instead of the day the item was entered. Zero times leave the item as it is.
+type History struct {
+	CreatedAt time.Time
+	// DecidedAt records the decision in Decision, StatusBought or StatusSkipped, on that date.
+	DecidedAt time.Time
+	Decision  Status
+}
+
+// parseHistoryDate accepts a date such as "2024-03-01", read in the local time zone, or an RFC 3339 timestamp.
+func parseHistoryDate(raw string) (time.Time, bool) {
+	if parsed, err := time.ParseInLocation("2006-01-02", raw, time.Local); err == nil {
+		return parsed, true
+	}
+	if parsed, err := time.Parse(time.RFC3339, raw); err == nil {
+		return parsed, true
+	}
+	return time.Time{}, false
+}
+
+// ParseHistory validates the backfill fields created_at, decided_at and decision. Dates may not lie in the
+// future, a decision needs a date and the decision may not come before the item was created.
+func ParseHistory(createdRaw, decidedRaw, decisionRaw string, now time.Time) (History, error) {
+	createdRaw, decidedRaw, decisionRaw = strings.TrimSpace(createdRaw), strings.TrimSpace(decidedRaw), strings.TrimSpace(decisionRaw)
+	var h History
+	var v Validation
+
+	if createdRaw != "" {
+		created, ok := parseHistoryDate(createdRaw)
+		switch {
+		case !ok:
+			v.Add("created_at", "Please enter the date the item was added, for example 2024-03-01.")
+		case created.After(now):
+			v.Add("created_at", "The date the item was added cannot be in the future.")
+		default:
+			h.CreatedAt = created
+		}
+	}
+
+	switch Status(decisionRaw) {
+	case "":
+	case StatusBought, StatusSkipped:
+		h.Decision = Status(decisionRaw)
+	default:
+		v.Add("decision", "Please choose Bought or Skipped as the decision.")
+	}
+	if decidedRaw != "" {
+		decided, ok := parseHistoryDate(decidedRaw)
+		switch {
+		case !ok:
+			v.Add("decided_at", "Please enter the decision date, for example 2024-03-08.")
+		case decided.After(now):
+			v.Add("decided_at", "The decision date cannot be in the future.")
+		case !h.CreatedAt.IsZero() && decided.Before(h.CreatedAt):
+			v.Add("decided_at", "The decision date cannot be before the item was added.")
+		default:
+			h.DecidedAt = decided
+		}
+	}
+	if (decidedRaw == "") != (decisionRaw == "") {
+		v.Add("decided_at", "Please enter both the decision and its date.")
+	}
+	return h, v.Err()
+}
+
+// startOfDay returns local midnight of t's day, since history dates carry no time of day.
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.In(time.Local).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
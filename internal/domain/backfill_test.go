@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseHistoryRejectsInconsistentDates(t *testing.T) {
+	h, err := ParseHistory("2026-03-01", "2026-03-08", "Bought", testNow)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if h.CreatedAt.Format("2006-01-02") != "2026-03-01" || h.DecidedAt.Format("2006-01-02") != "2026-03-08" || h.Decision != StatusBought {
+		t.Fatalf("unexpected history %+v", h)
+	}
+
+	tests := []struct {
+		created, decided, decision, field string
+	}{
+		{"2026-13-01", "", "", "created_at"},
+		{"2027-01-01", "", "", "created_at"},
+		{"2026-03-08", "2026-03-01", "Skipped", "decided_at"},
+		{"", "2026-03-01", "", "decided_at"},
+		{"", "2026-03-01", "Waiting", "decision"},
+	}
+	for _, tt := range tests {
+		_, err := ParseHistory(tt.created, tt.decided, tt.decision, testNow)
+		var invalid *ValidationError
+		if !errors.As(err, &invalid) || invalid.Message(tt.field) == "" {
+			t.Errorf("ParseHistory(%q, %q, %q): expected an error for %s, got %v", tt.created, tt.decided, tt.decision, tt.field, err)
+		}
+	}
+}
+
+func TestItemServiceBackfillsCreatedAndDecidedDates(t *testing.T) {
+	service, store := newTestItemService()
+
+	item, err := service.Create(Draft{Item: Item{Title: "Bike", WaitPreset: "7d"}, CreatedAtInput: "2026-02-01", DecidedAtInput: "2026-02-10", DecisionInput: "Bought"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if item.Status != StatusBought || item.CreatedAt.Format("2006-01-02") != "2026-02-01" || item.DecidedAt.Format("2006-01-02") != "2026-02-10" {
+		t.Fatalf("unexpected backfilled item %+v", item)
+	}
+	if !item.PurchaseAllowedAt.Equal(item.CreatedAt.Add(7 * 24 * time.Hour)) {
+		t.Fatalf("expected the wait to count from the backfilled date, got %v", item.PurchaseAllowedAt)
+	}
+
+	waiting, _ := service.Create(Draft{Item: Item{Title: "Lamp", WaitPreset: "7d"}})
+	skipped, err := service.Update(waiting.ID, Draft{Item: Item{Title: "Lamp", WaitPreset: "7d"}, DecidedAtInput: "2026-04-30", DecisionInput: "Skipped"})
+	if err == nil || skipped.Status == StatusSkipped {
+		t.Fatalf("expected a decision before the item was added to be rejected, got %+v", skipped)
+	}
+	skipped, err = service.Update(waiting.ID, Draft{Item: Item{Title: "Lamp", WaitPreset: "7d"}, DecidedAtInput: testNow.Format("2006-01-02"), DecisionInput: "Skipped"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if skipped.Status != StatusSkipped || !skipped.CreatedAt.Equal(testNow) {
+		t.Fatalf("unexpected backfilled decision %+v", skipped)
+	}
+	if store.history[len(store.history)-1] != "skipped" {
+		t.Fatalf("expected the backfilled decision in history, got %q", store.history)
+	}
+}
@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// maxBlackoutDays keeps a blackout from holding back every item indefinitely.
+const maxBlackoutDays = 366
+
+const maxBlackoutLabelLength = 64
+
+// Blackout is a period, such as a no-buy November, during which no item becomes ready to buy.
+// Items whose wait ends inside it unlock when it is over.
+type Blackout struct {
+	// Start is midnight of the first day and End midnight after the last day, in the server's time zone.
+	Start time.Time
+	End   time.Time
+	Label string
+}
+
+// Contains reports whether t falls inside the blackout.
+func (b Blackout) Contains(t time.Time) bool {
+	return !t.Before(b.Start) && t.Before(b.End)
+}
+
+// LastDay returns midnight of the blackout's last day.
+func (b Blackout) LastDay() time.Time {
+	return b.End.AddDate(0, 0, -1)
+}
+
+// Name returns the label, or "Blackout" for unlabelled blackouts.
+func (b Blackout) Name() string {
+	if b.Label == "" {
+		return "Blackout"
+	}
+	return b.Label
+}
+
+// ParseBlackout validates a blackout entered as its first and last day ("2006-01-02") and an optional label.
+// Blackouts that are already over are rejected.
+func ParseBlackout(startRaw, endRaw, label string, now time.Time) (Blackout, error) {
+	var v Validation
+	start, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(startRaw), time.Local)
+	if err != nil {
+		v.Add("blackout_start", "Please enter the first day as a date.")
+	}
+	last, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(endRaw), time.Local)
+	if err != nil {
+		v.Add("blackout_end", "Please enter the last day as a date.")
+	}
+	label = strings.Join(strings.Fields(label), " ")
+	if len([]rune(label)) > maxBlackoutLabelLength {
+		v.Add("blackout_label", "The name must be 64 characters or fewer.")
+	}
+	if err := v.Err(); err != nil {
+		return Blackout{}, err
+	}
+
+	blackout := Blackout{Start: start, End: last.AddDate(0, 0, 1), Label: label}
+	switch {
+	case last.Before(start):
+		v.Add("blackout_end", "The last day cannot be before the first day.")
+	case blackout.End.After(start.AddDate(0, 0, maxBlackoutDays)):
+		v.Add("blackout_end", "A blackout can last at most a year.")
+	case !blackout.End.After(now):
+		v.Add("blackout_end", "That period is already over.")
+	}
+	return blackout, v.Err()
+}
+
+// ParseBlackouts reads blackouts stored by FormatBlackouts, skipping lines that cannot be read.
+func ParseBlackouts(raw string) []Blackout {
+	var blackouts []Blackout
+	for _, line := range strings.Split(raw, "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), " ", 3)
+		if len(fields) < 2 {
+			continue
+		}
+		start, err := time.ParseInLocation("2006-01-02", fields[0], time.Local)
+		if err != nil {
+			continue
+		}
+		last, err := time.ParseInLocation("2006-01-02", fields[1], time.Local)
+		if err != nil || last.Before(start) {
+			continue
+		}
+		blackout := Blackout{Start: start, End: last.AddDate(0, 0, 1)}
+		if len(fields) == 3 {
+			blackout.Label = fields[2]
+		}
+		blackouts = append(blackouts, blackout)
+	}
+	SortBlackouts(blackouts)
+	return blackouts
+}
+
+// FormatBlackouts stores blackouts one per line as first day, last day and label.
+func FormatBlackouts(blackouts []Blackout) string {
+	lines := make([]string, 0, len(blackouts))
+	for _, b := range blackouts {
+		line := b.Start.Format("2006-01-02") + " " + b.LastDay().Format("2006-01-02")
+		if b.Label != "" {
+			line += " " + b.Label
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SortBlackouts orders blackouts by their first day.
+func SortBlackouts(blackouts []Blackout) {
+	slices.SortStableFunc(blackouts, func(a, b Blackout) int {
+		return a.Start.Compare(b.Start)
+	})
+}
+
+// ActiveBlackout returns the blackout now falls in.
+func ActiveBlackout(blackouts []Blackout, now time.Time) (Blackout, bool) {
+	for _, b := range blackouts {
+		if b.Contains(now) {
+			return b, true
+		}
+	}
+	return Blackout{}, false
+}
+
+// DeferForBlackouts moves an unlock time inside a blackout to the blackout's end, following blackouts
+// that overlap or directly follow each other. It reports whether the time was moved.
+func DeferForBlackouts(allowedAt time.Time, blackouts []Blackout) (time.Time, bool) {
+	deferred := false
+	for {
+		b, ok := ActiveBlackout(blackouts, allowedAt)
+		if !ok {
+			return allowedAt, deferred
+		}
+		allowedAt, deferred = b.End, true
+	}
+}
+
+// HeldByBlackout reports whether the item is waiting only because of the blackout now falls in.
+func HeldByBlackout(item Item, blackouts []Blackout, now time.Time) bool {
+	until, active := DeferForBlackouts(now, blackouts)
+	return active && item.Status == StatusWaiting && item.PurchaseAllowedAt.Equal(until)
+}
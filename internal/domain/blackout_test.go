@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// noBuyMay covers 1 to 10 May 2026, around testNow.
+var noBuyMay = Blackout{Start: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2026, 5, 11, 0, 0, 0, 0, time.UTC), Label: "No-buy May"}
+
+func TestParseBlackoutValidatesThePeriod(t *testing.T) {
+	b, err := ParseBlackout("2026-11-01", "2026-11-30", "  No-buy   November ", testNow)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.Label != "No-buy November" || b.LastDay().Format("2006-01-02") != "2026-11-30" || b.End.Format("2006-01-02") != "2026-12-01" {
+		t.Fatalf("unexpected blackout %+v", b)
+	}
+
+	tests := []struct {
+		start, end, field string
+	}{
+		{"", "2026-11-30", "blackout_start"},
+		{"2026-11-01", "30.11.", "blackout_end"},
+		{"2026-11-30", "2026-11-01", "blackout_end"},
+		{"2026-01-01", "2027-06-01", "blackout_end"},
+		{"2026-04-01", "2026-04-20", "blackout_end"},
+	}
+	for _, tt := range tests {
+		_, err := ParseBlackout(tt.start, tt.end, "", testNow)
+		var invalid *ValidationError
+		if !errors.As(err, &invalid) || invalid.Message(tt.field) == "" {
+			t.Errorf("ParseBlackout(%q, %q): expected an error for %s, got %v", tt.start, tt.end, tt.field, err)
+		}
+	}
+}
+
+func TestBlackoutsRoundTripAndChain(t *testing.T) {
+	stored := "2026-05-11 2026-05-12\n2026-05-01 2026-05-10 No-buy May\nbroken"
+	blackouts := ParseBlackouts(stored)
+	if len(blackouts) != 2 || blackouts[0].Label != "No-buy May" {
+		t.Fatalf("unexpected blackouts %+v", blackouts)
+	}
+	if got := FormatBlackouts(blackouts); got != "2026-05-01 2026-05-10 No-buy May\n2026-05-11 2026-05-12" {
+		t.Fatalf("unexpected stored form %q", got)
+	}
+
+	// Back-to-back blackouts defer to the end of the last one.
+	deferred, moved := DeferForBlackouts(time.Date(2026, 5, 3, 9, 0, 0, 0, time.Local), blackouts)
+	if !moved || !deferred.Equal(time.Date(2026, 5, 13, 0, 0, 0, 0, time.Local)) {
+		t.Fatalf("expected the wait to end on 13 May, got %v (moved %v)", deferred, moved)
+	}
+	if _, moved := DeferForBlackouts(time.Date(2026, 6, 1, 0, 0, 0, 0, time.Local), blackouts); moved {
+		t.Fatalf("expected times outside blackouts to be kept")
+	}
+}
+
+func TestItemServiceHoldsItemsBackDuringBlackouts(t *testing.T) {
+	due := Item{ID: 1, Title: "Sneakers", Status: StatusWaiting, PurchaseAllowedAt: testNow.Add(-time.Hour)}
+	service, store := newTestItemService(due)
+	service.Blackouts = []Blackout{noBuyMay}
+
+	promoted, err := service.PromoteReady()
+	if err != nil || len(promoted) != 0 {
+		t.Fatalf("expected nothing to be promoted, got %+v %v", promoted, err)
+	}
+	if held := store.items[0]; held.Status != StatusWaiting || !held.PurchaseAllowedAt.Equal(noBuyMay.End) {
+		t.Fatalf("expected the item to wait until the blackout ends, got %+v", held)
+	}
+
+	created, err := service.Create(Draft{Item: Item{Title: "Jacket", WaitPreset: "24h"}})
+	if err != nil || !created.PurchaseAllowedAt.Equal(noBuyMay.End) {
+		t.Fatalf("expected a wait ending in the blackout to end with it, got %+v %v", created, err)
+	}
+	later, err := service.Create(Draft{Item: Item{Title: "Tent", WaitPreset: "30d"}})
+	if err != nil || !later.PurchaseAllowedAt.Equal(testNow.AddDate(0, 0, 30)) {
+		t.Fatalf("expected a wait ending after the blackout to be kept, got %+v %v", later, err)
+	}
+
+	if _, err := service.OverrideBlackout(later.ID); !errors.Is(err, ErrNotHeldByBlackout) {
+		t.Fatalf("expected only held items to be overridable, got %v", err)
+	}
+	overridden, err := service.OverrideBlackout(1)
+	if err != nil || overridden.Status != StatusReady || !overridden.PurchaseAllowedAt.Equal(testNow) {
+		t.Fatalf("expected the override to make the item ready now, got %+v %v", overridden, err)
+	}
+	if last := store.history[len(store.history)-1]; last != "overrode the blackout" {
+		t.Fatalf("expected the override in the history, got %v", store.history)
+	}
+}
+
+func TestHoldBackForBlackoutsMovesWaitsEndingInside(t *testing.T) {
+	service, store := newTestItemService(
+		Item{ID: 1, Title: "Inside", Status: StatusWaiting, PurchaseAllowedAt: testNow.AddDate(0, 0, 3)},
+		Item{ID: 2, Title: "After", Status: StatusWaiting, PurchaseAllowedAt: testNow.AddDate(0, 0, 20)},
+		Item{ID: 3, Title: "Ready", Status: StatusReady, PurchaseAllowedAt: testNow.AddDate(0, 0, -1)},
+	)
+	service.Blackouts = []Blackout{noBuyMay}
+
+	held, err := service.HoldBackForBlackouts()
+	if err != nil || len(held) != 1 || held[0].ID != 1 {
+		t.Fatalf("expected only the wait ending inside the blackout to move, got %+v %v", held, err)
+	}
+	if !store.items[0].PurchaseAllowedAt.Equal(noBuyMay.End) || store.items[2].Status != StatusReady {
+		t.Fatalf("unexpected items %+v", store.items)
+	}
+}
@@ -0,0 +1,35 @@
+package domain
+
+import "errors"
+
+// Error kinds. Every error below wraps one of them, so callers can map any domain error to a response
+// with errors.Is instead of listing the specific errors. A *ValidationError is an ErrValidation.
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+	ErrValidation = errors.New("validation failed")
+)
+
+var (
+	ErrItemNotFound         = kindError(ErrNotFound, "item not found")
+	ErrInvalidStatus        = kindError(ErrValidation, "invalid status")
+	ErrTransitionNotAllowed = kindError(ErrConflict, "status transition not allowed")
+	ErrApprovalRequired     = kindError(ErrConflict, "approval required before buying")
+	ErrNotHeldByBlackout    = kindError(ErrConflict, "item is not held back by a blackout")
+	ErrItemQuotaReached     = kindError(ErrConflict, "item limit reached")
+	ErrLastProfile          = kindError(ErrConflict, "The last remaining profile cannot be deleted. Please create or switch to another profile first.")
+)
+
+// kindedError keeps its own message and matches its kind.
+type kindedError struct {
+	message string
+	kind    error
+}
+
+func kindError(kind error, message string) error {
+	return &kindedError{message: message, kind: kind}
+}
+
+func (e *kindedError) Error() string { return e.message }
+
+func (e *kindedError) Unwrap() error { return e.kind }
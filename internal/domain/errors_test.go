@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDomainErrorsMatchTheirKind(t *testing.T) {
+	cases := []struct {
+		err  error
+		kind error
+	}{
+		{ErrItemNotFound, ErrNotFound},
+		{ErrInvalidStatus, ErrValidation},
+		{ErrTransitionNotAllowed, ErrConflict},
+		{ErrApprovalRequired, ErrConflict},
+		{ErrItemQuotaReached, ErrConflict},
+		{&ValidationError{Fields: []FieldError{{Field: "title", Message: "Please enter a title."}}}, ErrValidation},
+	}
+	for _, c := range cases {
+		if !errors.Is(c.err, c.kind) {
+			t.Errorf("expected %q to be a %q error", c.err, c.kind)
+		}
+	}
+	if ErrItemNotFound.Error() != "item not found" || errors.Is(ErrItemNotFound, ErrConflict) {
+		t.Fatalf("expected the specific message and only its own kind, got %q", ErrItemNotFound)
+	}
+}
@@ -0,0 +1,48 @@
+package domain
+
+// EventType names something that happened to a profile's items or settings.
+type EventType string
+
+const (
+	EventItemCreated    EventType = "item.created"
+	EventItemPromoted   EventType = "item.promoted"
+	EventItemDecided    EventType = "item.decided"
+	EventProfileUpdated EventType = "profile.updated"
+
+	// EventApprovalRequested is an item's owner asking the approver of their approval rule to allow the purchase.
+	EventApprovalRequested EventType = "item.approval_requested"
+)
+
+// Event is published on a Bus. Item is set for item events; Detail and RemoteAddr describe
+// profile updates, such as which settings page was saved and from where.
+type Event struct {
+	Type       EventType
+	Profile    string
+	Item       Item
+	Detail     string
+	RemoteAddr string
+}
+
+// Bus delivers events synchronously, in subscription order, so subscribers run while the publisher
+// still holds its locks. Subscribe during start-up only; the zero value is ready to use.
+type Bus struct {
+	handlers map[EventType][]func(Event)
+}
+
+// Subscribe calls handle for every published event of type t.
+func (b *Bus) Subscribe(t EventType, handle func(Event)) {
+	if b.handlers == nil {
+		b.handlers = map[EventType][]func(Event){}
+	}
+	b.handlers[t] = append(b.handlers[t], handle)
+}
+
+// Publish hands the event to its subscribers. Publishing on a nil Bus does nothing.
+func (b *Bus) Publish(event Event) {
+	if b == nil {
+		return
+	}
+	for _, handle := range b.handlers[event.Type] {
+		handle(event)
+	}
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBusDeliversEventsInSubscriptionOrder(t *testing.T) {
+	var bus Bus
+	var got []string
+	bus.Subscribe(EventItemCreated, func(e Event) { got = append(got, "first "+e.Item.Title) })
+	bus.Subscribe(EventItemCreated, func(e Event) { got = append(got, "second "+e.Item.Title) })
+	bus.Subscribe(EventItemDecided, func(e Event) { got = append(got, "decided") })
+
+	bus.Publish(Event{Type: EventItemCreated, Item: Item{Title: "Lamp"}})
+
+	if want := []string{"first Lamp", "second Lamp"}; !slices.Equal(got, want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+
+	var nilBus *Bus
+	nilBus.Publish(Event{Type: EventItemCreated})
+}
+
+func TestItemServicePublishesItemEvents(t *testing.T) {
+	service, _ := newTestItemService()
+	service.Events = &Bus{}
+	var got []EventType
+	for _, eventType := range []EventType{EventItemCreated, EventItemPromoted, EventItemDecided} {
+		service.Events.Subscribe(eventType, func(e Event) { got = append(got, e.Type) })
+	}
+
+	item, err := service.Create(Draft{Item: Item{Title: "Headphones", WaitPreset: "24h"}})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	service.Now = func() time.Time { return testNow.AddDate(0, 0, 2) }
+	if _, err := service.PromoteReady(); err != nil {
+		t.Fatalf("promote: %v", err)
+	}
+	if _, err := service.Decide(item.ID, StatusSkipped); err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+
+	if want := []EventType{EventItemCreated, EventItemPromoted, EventItemDecided}; !slices.Equal(got, want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,53 @@
+// Package domain holds the business rules for items and profiles: wait resolution, promotion,
+// status decisions and profile validation. It has no knowledge of HTTP or SQL, so the HTML
+// handlers, a JSON API or a CLI can share one implementation through the services.
+package domain
+
+import "time"
+
+type Item struct {
+	ID         int
+	OwnerID    string
+	SharedWith []string
+	// SplitPercents holds the price share of each profile in SharedWith. The owner carries the rest.
+	SplitPercents     map[string]int
+	Title             string
+	Price             string
+	PriceCents        Money
+	HasPriceValue     bool
+	Link              string
+	Note              string
+	Tags              string
+	Status            Status
+	WaitPreset        string
+	WaitCustomHours   string
+	PurchaseAllowedAt time.Time
+	CreatedAt         time.Time
+	DecidedAt         time.Time
+	NtfyAttempted     bool
+	// NotifiedAt is when the item was last announced as ready; zero if it never was.
+	NotifiedAt    time.Time
+	FireflyPushed bool
+	ApprovalState string
+	UrgeScore     int
+	Satisfaction  string
+	// ReceiptName is the file name of the receipt uploaded after buying; empty without one.
+	ReceiptName string
+	// Private items show a placeholder instead of their details wherever others may be looking, such as
+	// the kiosk link and the household page.
+	Private bool
+}
+
+// Draft is an item as submitted on the add or edit form, before its wait and status are resolved.
+type Draft struct {
+	Item
+	// PurchaseAllowedInput and TimezoneOffsetMinutes are only used by the "date" wait preset.
+	PurchaseAllowedInput  string
+	TimezoneOffsetMinutes string
+	// Researching creates the item without starting its wait.
+	Researching bool
+	// CreatedAtInput, DecidedAtInput and DecisionInput backdate the item; see ParseHistory. Empty keeps the dates.
+	CreatedAtInput string
+	DecidedAtInput string
+	DecisionInput  string
+}
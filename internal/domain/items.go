@@ -0,0 +1,370 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ItemStore persists the items of one profile.
+type ItemStore interface {
+	// Items returns the profile's items, including items shared with it.
+	Items() []Item
+	InsertItem(item *Item) error
+	UpdateItem(item Item) error
+	SaveStatus(item Item) error
+	SavePromoted(item Item) error
+	RecordHistory(itemID int, action, detail string)
+}
+
+// ItemService applies the item rules for one profile.
+type ItemService struct {
+	Store ItemStore
+	// PurchaseBlocked reports whether an approval rule blocks buying the item. Nil never blocks.
+	PurchaseBlocked func(Item) (bool, error)
+	// Now defaults to time.Now.
+	Now func() time.Time
+	// Events receives item.created, item.promoted and item.decided. Nil publishes nothing.
+	Events *Bus
+	// Payday is the profile's day of the month for the "payday" wait preset, or 0 if it has none.
+	Payday int
+	// Blackouts are the profile's periods in which no item becomes ready; waits ending inside one end with it.
+	Blackouts []Blackout
+	// MaxItems caps the items Owner may own. Shared items count for their owner only. 0 is unlimited.
+	MaxItems int
+	Owner    string
+}
+
+func (s ItemService) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+func (s ItemService) find(id int) (Item, error) {
+	for _, item := range s.Store.Items() {
+		if item.ID == id {
+			return item, nil
+		}
+	}
+	return Item{}, ErrItemNotFound
+}
+
+// OwnedItems counts the items that count towards MaxItems. Items without an owner belong to Owner.
+func (s ItemService) OwnedItems() int {
+	owned := 0
+	for _, item := range s.Store.Items() {
+		if item.OwnerID == "" || item.OwnerID == s.Owner {
+			owned++
+		}
+	}
+	return owned
+}
+
+// validateDraft checks every field of the draft and resolves its backfilled history and when the item
+// may be bought. A backdated item's wait counts from the day it was added, and a wait ending in a
+// blackout ends with the blackout.
+func (s ItemService) validateDraft(draft Draft, now time.Time) (time.Time, History, error) {
+	var v Validation
+	if draft.Title == "" {
+		v.Add("title", "Please enter a title.")
+	}
+	history, err := ParseHistory(draft.CreatedAtInput, draft.DecidedAtInput, draft.DecisionInput, now)
+	if err := v.Merge(err); err != nil {
+		return time.Time{}, history, err
+	}
+	waitFrom := now
+	if !history.CreatedAt.IsZero() {
+		waitFrom = history.CreatedAt
+	}
+	purchaseAllowedAt, err := ResolvePurchaseAllowedAt(draft.WaitPreset, draft.WaitCustomHours, draft.PurchaseAllowedInput, draft.TimezoneOffsetMinutes, s.Payday, waitFrom)
+	if err := v.Merge(err); err != nil {
+		return time.Time{}, history, err
+	}
+	purchaseAllowedAt, _ = DeferForBlackouts(purchaseAllowedAt, s.Blackouts)
+	return purchaseAllowedAt, history, v.Err()
+}
+
+// Create validates the draft, starts its wait and stores it. On a validation error the returned
+// item still holds the submitted values so the form can be shown again.
+func (s ItemService) Create(draft Draft) (Item, error) {
+	item := draft.Item
+	if s.MaxItems > 0 && s.OwnedItems() >= s.MaxItems {
+		return item, fmt.Errorf("%w: a profile can keep at most %d items", ErrItemQuotaReached, s.MaxItems)
+	}
+	now := s.now()
+	purchaseAllowedAt, history, err := s.validateDraft(draft, now)
+	if err != nil {
+		return item, err
+	}
+
+	item.WaitPreset = NormalizeWaitPreset(item.WaitPreset)
+	item.CreatedAt = now
+	if !history.CreatedAt.IsZero() {
+		item.CreatedAt = history.CreatedAt
+	}
+	item.PurchaseAllowedAt = purchaseAllowedAt
+	item.Status = ActiveStatus(purchaseAllowedAt, now)
+	switch {
+	case !history.DecidedAt.IsZero():
+		item.Status = history.Decision
+		item.DecidedAt = history.DecidedAt
+	case draft.Researching:
+		item.Status = StatusResearching
+		item.PurchaseAllowedAt = researchingPurchaseAllowedAt(item.WaitPreset, purchaseAllowedAt)
+	}
+
+	if err := s.Store.InsertItem(&item); err != nil {
+		return item, err
+	}
+	s.Store.RecordHistory(item.ID, "created", "")
+	if !history.DecidedAt.IsZero() {
+		s.Store.RecordHistory(item.ID, strings.ToLower(string(item.Status)), "backfilled")
+	}
+	s.Events.Publish(Event{Type: EventItemCreated, Profile: item.OwnerID, Item: item})
+	return item, nil
+}
+
+// Update replaces the editable fields of an item and resolves its wait again. Bought items stay
+// bought, researching items keep researching, and a changed price resets an earlier approval.
+// A backfilled decision replaces the status whatever it was.
+func (s ItemService) Update(id int, draft Draft) (Item, error) {
+	item := draft.Item
+	item.ID = id
+	now := s.now()
+	purchaseAllowedAt, history, err := s.validateDraft(draft, now)
+	if err != nil {
+		return item, err
+	}
+	item.WaitPreset = NormalizeWaitPreset(item.WaitPreset)
+
+	existing, err := s.find(id)
+	if err != nil {
+		return item, err
+	}
+	item.OwnerID = existing.OwnerID
+	item.SharedWith = existing.SharedWith
+	item.CreatedAt = existing.CreatedAt
+	if !history.CreatedAt.IsZero() {
+		item.CreatedAt = history.CreatedAt
+	}
+	if !history.DecidedAt.IsZero() && history.DecidedAt.Before(startOfDay(item.CreatedAt)) {
+		return item, invalid("decided_at", "The decision date cannot be before the item was added.")
+	}
+	item.NtfyAttempted = existing.NtfyAttempted
+	item.NotifiedAt = existing.NotifiedAt
+	item.FireflyPushed = existing.FireflyPushed
+	item.Satisfaction = existing.Satisfaction
+	item.ReceiptName = existing.ReceiptName
+	if item.PriceCents == existing.PriceCents && item.HasPriceValue == existing.HasPriceValue {
+		item.ApprovalState = existing.ApprovalState
+	}
+
+	item.PurchaseAllowedAt = purchaseAllowedAt
+	action := ActionEdit
+	switch {
+	case !history.DecidedAt.IsZero():
+		action = ActionBackfill
+		item.Status = history.Decision
+		item.DecidedAt = history.DecidedAt
+	case existing.Status == StatusBought:
+		item.Status = StatusBought
+		item.DecidedAt = existing.DecidedAt
+	case existing.Status == StatusResearching:
+		item.Status = StatusResearching
+		item.PurchaseAllowedAt = researchingPurchaseAllowedAt(item.WaitPreset, purchaseAllowedAt)
+	default:
+		item.Status = ActiveStatus(purchaseAllowedAt, now)
+		if item.Status == StatusWaiting {
+			item.NtfyAttempted = false
+		}
+	}
+	if err := CheckTransition(existing.Status, action, item.Status); err != nil {
+		return item, err
+	}
+
+	if err := s.Store.UpdateItem(item); err != nil {
+		return item, err
+	}
+	if action == ActionBackfill {
+		s.Store.RecordHistory(item.ID, strings.ToLower(string(item.Status)), "backfilled")
+	}
+	return item, nil
+}
+
+// Decide records the final decision, StatusBought or StatusSkipped, on an item that is ready to buy.
+func (s ItemService) Decide(id int, status Status) (Item, error) {
+	action := ActionSkip
+	switch status {
+	case StatusBought:
+		action = ActionBuy
+	case StatusSkipped:
+	default:
+		return Item{}, ErrInvalidStatus
+	}
+	item, err := s.find(id)
+	if err != nil {
+		return Item{}, err
+	}
+	if err := CheckTransition(item.Status, action, status); err != nil {
+		return item, err
+	}
+	if status == StatusBought && s.PurchaseBlocked != nil {
+		blocked, err := s.PurchaseBlocked(item)
+		if err != nil {
+			return item, fmt.Errorf("check approval rule: %w", err)
+		}
+		if blocked {
+			return item, ErrApprovalRequired
+		}
+	}
+
+	item.Status = status
+	item.DecidedAt = s.now()
+	if err := s.Store.SaveStatus(item); err != nil {
+		return item, err
+	}
+	s.Store.RecordHistory(item.ID, strings.ToLower(string(status)), "")
+	s.Events.Publish(Event{Type: EventItemDecided, Profile: item.OwnerID, Item: item})
+	return item, nil
+}
+
+// RecordPurchase marks an open item as bought outside the app, with the price actually paid and the
+// purchase date. The wait and approval rules are not checked: the purchase has already happened.
+func (s ItemService) RecordPurchase(id int, paid Money, boughtAt time.Time) (Item, error) {
+	item, err := s.find(id)
+	if err != nil {
+		return Item{}, err
+	}
+	if err := CheckTransition(item.Status, ActionRecordPurchase, StatusBought); err != nil {
+		return item, err
+	}
+
+	if paid > 0 {
+		item.Price = paid.String()
+		item.PriceCents = paid
+		item.HasPriceValue = true
+	}
+	item.Status = StatusBought
+	item.DecidedAt = boughtAt
+	if item.DecidedAt.IsZero() {
+		item.DecidedAt = s.now()
+	}
+	if err := s.Store.UpdateItem(item); err != nil {
+		return item, err
+	}
+	s.Store.RecordHistory(item.ID, "bought", "recorded from a transaction")
+	s.Events.Publish(Event{Type: EventItemDecided, Profile: item.OwnerID, Item: item})
+	return item, nil
+}
+
+// Snooze moves a ready item back to waiting for the preset's wait, counted from the later of its unlock time and now.
+func (s ItemService) Snooze(id int, preset string) (Item, error) {
+	d, err := ParseWaitDuration(preset, "")
+	if err != nil {
+		return Item{}, err
+	}
+	item, err := s.find(id)
+	if err != nil {
+		return Item{}, err
+	}
+	if err := CheckTransition(item.Status, ActionSnooze, StatusWaiting); err != nil {
+		return item, err
+	}
+
+	now := s.now()
+	base := item.PurchaseAllowedAt
+	if base.Before(now) {
+		base = now
+	}
+	item.PurchaseAllowedAt, _ = DeferForBlackouts(base.Add(d), s.Blackouts)
+	item.Status = StatusWaiting
+	item.NtfyAttempted = false
+	if err := s.Store.UpdateItem(item); err != nil {
+		return item, err
+	}
+	s.Store.RecordHistory(item.ID, "snoozed", preset)
+	return item, nil
+}
+
+// PromoteReady marks waiting items whose wait has ended as ready to buy and returns them. During a
+// blackout their wait is extended to its end instead. Items that could not be stored are still
+// returned; their errors are joined.
+func (s ItemService) PromoteReady() ([]Item, error) {
+	now := s.now()
+	until, blackedOut := DeferForBlackouts(now, s.Blackouts)
+	var promoted []Item
+	var errs []error
+	for _, item := range s.Store.Items() {
+		if !item.Status.Allows(ActionPromote, StatusReady) || item.PurchaseAllowedAt.After(now) {
+			continue
+		}
+		if blackedOut {
+			item.PurchaseAllowedAt = until
+			if err := s.Store.UpdateItem(item); err != nil {
+				errs = append(errs, fmt.Errorf("defer item %d: %w", item.ID, err))
+				continue
+			}
+			blackout, _ := ActiveBlackout(s.Blackouts, now)
+			s.Store.RecordHistory(item.ID, "held back", blackout.Name())
+			continue
+		}
+		item.Status = StatusReady
+		if err := s.Store.SavePromoted(item); err != nil {
+			errs = append(errs, fmt.Errorf("promote item %d: %w", item.ID, err))
+		}
+		promoted = append(promoted, item)
+		s.Events.Publish(Event{Type: EventItemPromoted, Profile: item.OwnerID, Item: item})
+	}
+	return promoted, errors.Join(errs...)
+}
+
+// OverrideBlackout makes an item that only waits because of the current blackout ready to buy now, for emergencies.
+func (s ItemService) OverrideBlackout(id int) (Item, error) {
+	item, err := s.find(id)
+	if err != nil {
+		return Item{}, err
+	}
+	if err := CheckTransition(item.Status, ActionOverrideBlackout, StatusReady); err != nil {
+		return item, err
+	}
+	now := s.now()
+	if !HeldByBlackout(item, s.Blackouts, now) {
+		return item, ErrNotHeldByBlackout
+	}
+
+	blackout, _ := ActiveBlackout(s.Blackouts, now)
+	item.PurchaseAllowedAt = now
+	item.Status = StatusReady
+	if err := s.Store.UpdateItem(item); err != nil {
+		return item, err
+	}
+	s.Store.RecordHistory(item.ID, "overrode the blackout", blackout.Name())
+	return item, nil
+}
+
+// HoldBackForBlackouts moves the unlock time of waiting items whose wait ends inside a blackout to its end.
+// It returns the items that were moved.
+func (s ItemService) HoldBackForBlackouts() ([]Item, error) {
+	var held []Item
+	var errs []error
+	for _, item := range s.Store.Items() {
+		if item.Status != StatusWaiting {
+			continue
+		}
+		blackout, inside := ActiveBlackout(s.Blackouts, item.PurchaseAllowedAt)
+		if !inside {
+			continue
+		}
+		item.PurchaseAllowedAt, _ = DeferForBlackouts(item.PurchaseAllowedAt, s.Blackouts)
+		if err := s.Store.UpdateItem(item); err != nil {
+			errs = append(errs, fmt.Errorf("defer item %d: %w", item.ID, err))
+			continue
+		}
+		s.Store.RecordHistory(item.ID, "held back", blackout.Name())
+		held = append(held, item)
+	}
+	return held, errors.Join(errs...)
+}
@@ -0,0 +1,201 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeItemStore struct {
+	items   []Item
+	history []string
+}
+
+func (s *fakeItemStore) Items() []Item { return s.items }
+
+func (s *fakeItemStore) InsertItem(item *Item) error {
+	item.ID = len(s.items) + 1
+	s.items = append(s.items, *item)
+	return nil
+}
+
+func (s *fakeItemStore) UpdateItem(item Item) error { return s.replace(item) }
+
+func (s *fakeItemStore) SaveStatus(item Item) error { return s.replace(item) }
+
+func (s *fakeItemStore) SavePromoted(item Item) error { return s.replace(item) }
+
+func (s *fakeItemStore) RecordHistory(itemID int, action, detail string) {
+	s.history = append(s.history, action)
+}
+
+func (s *fakeItemStore) replace(item Item) error {
+	for i := range s.items {
+		if s.items[i].ID == item.ID {
+			s.items[i] = item
+			return nil
+		}
+	}
+	return ErrItemNotFound
+}
+
+var testNow = time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestItemService(items ...Item) (ItemService, *fakeItemStore) {
+	store := &fakeItemStore{items: items}
+	return ItemService{Store: store, Now: func() time.Time { return testNow }}, store
+}
+
+func TestItemServiceCreateStartsWait(t *testing.T) {
+	service, store := newTestItemService()
+
+	item, err := service.Create(Draft{Item: Item{Title: "Headphones", WaitPreset: "7d"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if item.Status != "Waiting" || !item.PurchaseAllowedAt.Equal(testNow.AddDate(0, 0, 7)) || len(store.history) != 1 {
+		t.Fatalf("unexpected created item %+v", item)
+	}
+
+	researching, err := service.Create(Draft{Item: Item{Title: "Camera"}, Researching: true})
+	if err != nil || researching.Status != "Researching" || !researching.PurchaseAllowedAt.IsZero() {
+		t.Fatalf("expected researching item without wait, got %+v %v", researching, err)
+	}
+}
+
+func TestItemServiceCreateRejectsMissingTitle(t *testing.T) {
+	service, store := newTestItemService()
+
+	_, err := service.Create(Draft{Item: Item{Price: "20"}})
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) || invalid.Message("title") == "" || len(store.items) != 0 {
+		t.Fatalf("expected title validation error, got %v", err)
+	}
+
+	_, err = service.Create(Draft{Item: Item{WaitPreset: "custom"}})
+	if !errors.As(err, &invalid) || invalid.Message("title") == "" || invalid.Message("wait_custom_hours") == "" {
+		t.Fatalf("expected title and custom hours errors together, got %v", err)
+	}
+}
+
+func TestItemServiceCreateStopsAtTheItemQuota(t *testing.T) {
+	service, store := newTestItemService(Item{ID: 1, OwnerID: "Alex"}, Item{ID: 2, OwnerID: "Sam", SharedWith: []string{"Alex"}})
+	service.Owner = "Alex"
+	service.MaxItems = 2
+
+	if _, err := service.Create(Draft{Item: Item{Title: "Lamp"}}); err != nil {
+		t.Fatalf("expected the shared item not to count, got %v", err)
+	}
+	_, err := service.Create(Draft{Item: Item{Title: "Desk"}})
+	if !errors.Is(err, ErrItemQuotaReached) || len(store.items) != 3 {
+		t.Fatalf("expected the quota to stop the third own item, got %v", err)
+	}
+}
+
+func TestItemServiceUpdateKeepsBoughtStatus(t *testing.T) {
+	decided := testNow.Add(-time.Hour)
+	service, _ := newTestItemService(Item{ID: 1, Title: "Desk", Status: "Bought", DecidedAt: decided, ApprovalState: "approved", PriceCents: 100, HasPriceValue: true})
+
+	item, err := service.Update(1, Draft{Item: Item{Title: "Standing desk", WaitPreset: "24h", PriceCents: 120, HasPriceValue: true}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if item.Status != "Bought" || !item.DecidedAt.Equal(decided) || item.ApprovalState != "" {
+		t.Fatalf("unexpected updated item %+v", item)
+	}
+
+	if _, err := service.Update(2, Draft{Item: Item{Title: "Lamp"}}); !errors.Is(err, ErrItemNotFound) {
+		t.Fatalf("expected ErrItemNotFound, got %v", err)
+	}
+}
+
+func TestItemServiceDecide(t *testing.T) {
+	service, store := newTestItemService(
+		Item{ID: 1, Status: "Ready to buy"},
+		Item{ID: 2, Status: "Waiting"},
+		Item{ID: 3, Status: "Ready to buy"},
+	)
+	service.PurchaseBlocked = func(item Item) (bool, error) { return item.ID == 3, nil }
+
+	if _, err := service.Decide(1, "Maybe"); !errors.Is(err, ErrInvalidStatus) {
+		t.Fatalf("expected ErrInvalidStatus, got %v", err)
+	}
+	if _, err := service.Decide(2, "Bought"); !errors.Is(err, ErrTransitionNotAllowed) {
+		t.Fatalf("expected ErrTransitionNotAllowed, got %v", err)
+	}
+	if _, err := service.Decide(3, "Bought"); !errors.Is(err, ErrApprovalRequired) {
+		t.Fatalf("expected ErrApprovalRequired, got %v", err)
+	}
+	if _, err := service.Decide(3, "Skipped"); err != nil {
+		t.Fatalf("expected skipping to ignore approvals, got %v", err)
+	}
+
+	item, err := service.Decide(1, "Bought")
+	if err != nil || item.Status != "Bought" || !item.DecidedAt.Equal(testNow) {
+		t.Fatalf("unexpected decision %+v %v", item, err)
+	}
+	if store.items[0].Status != "Bought" || store.history[len(store.history)-1] != "bought" {
+		t.Fatalf("expected decision to be stored, got %+v %q", store.items[0], store.history)
+	}
+}
+
+func TestItemServicePromoteReady(t *testing.T) {
+	service, store := newTestItemService(
+		Item{ID: 1, Status: "Waiting", PurchaseAllowedAt: testNow},
+		Item{ID: 2, Status: "Waiting", PurchaseAllowedAt: testNow.Add(time.Minute)},
+		Item{ID: 3, Status: "Researching"},
+	)
+
+	promoted, err := service.PromoteReady()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(promoted) != 1 || promoted[0].ID != 1 || store.items[0].Status != "Ready to buy" || store.items[1].Status != "Waiting" {
+		t.Fatalf("unexpected promotion %+v, store %+v", promoted, store.items)
+	}
+}
+
+func TestItemServiceSnoozeOnlyReadyItems(t *testing.T) {
+	service, store := newTestItemService(
+		Item{ID: 1, Status: "Ready to buy", PurchaseAllowedAt: testNow.Add(-time.Hour), NtfyAttempted: true},
+		Item{ID: 2, Status: "Skipped"},
+	)
+
+	item, err := service.Snooze(1, "24h")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if item.Status != StatusWaiting || !item.PurchaseAllowedAt.Equal(testNow.Add(24*time.Hour)) || item.NtfyAttempted {
+		t.Fatalf("unexpected snoozed item %+v", item)
+	}
+	if store.history[len(store.history)-1] != "snoozed" {
+		t.Fatalf("expected snooze in history, got %q", store.history)
+	}
+
+	if _, err := service.Snooze(2, "24h"); !errors.Is(err, ErrTransitionNotAllowed) {
+		t.Fatalf("expected skipped items not to be snoozed, got %v", err)
+	}
+}
+
+func TestItemServiceRecordPurchaseStoresPaidPriceAndDate(t *testing.T) {
+	service, store := newTestItemService(
+		Item{ID: 1, Status: "Waiting", Price: "50", PriceCents: 5000, HasPriceValue: true, PurchaseAllowedAt: testNow.Add(48 * time.Hour)},
+		Item{ID: 2, Status: "Bought"},
+	)
+	boughtAt := testNow.Add(-24 * time.Hour)
+
+	item, err := service.RecordPurchase(1, 4599, boughtAt)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if item.Status != StatusBought || item.Price != "45.99" || item.PriceCents != 4599 || !item.DecidedAt.Equal(boughtAt) {
+		t.Fatalf("unexpected recorded item %+v", item)
+	}
+	if store.history[len(store.history)-1] != "bought" {
+		t.Fatalf("expected purchase in history, got %q", store.history)
+	}
+
+	if _, err := service.RecordPurchase(2, 1000, boughtAt); !errors.Is(err, ErrTransitionNotAllowed) {
+		t.Fatalf("expected bought items not to be recorded again, got %v", err)
+	}
+}
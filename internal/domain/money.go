@@ -0,0 +1,182 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount in minor units (cents). Prices and totals are kept as integers so sums stay exact.
+type Money int64
+
+const (
+	minorUnitDecimals = 2
+	minorUnitsPerUnit = 100
+	// maxMoneyDigits keeps parsed amounts far below the int64 limit.
+	maxMoneyDigits = 13
+)
+
+var errInvalidMoney = errors.New("invalid amount")
+
+// ParseMoney parses a positive decimal amount such as "12", "12.5" or ".99".
+// More than two decimals are rounded half up to the nearest cent.
+func ParseMoney(raw string) (Money, error) {
+	whole, fraction, _ := strings.Cut(strings.TrimSpace(raw), ".")
+	if (whole == "" && fraction == "") || len(whole) > maxMoneyDigits || !allDigits(whole) || !allDigits(fraction) {
+		return 0, errInvalidMoney
+	}
+
+	var units int64
+	if whole != "" {
+		parsed, err := strconv.ParseInt(whole, 10, 64)
+		if err != nil {
+			return 0, errInvalidMoney
+		}
+		units = parsed
+	}
+
+	roundUp := len(fraction) > minorUnitDecimals && fraction[minorUnitDecimals] >= '5'
+	if len(fraction) > minorUnitDecimals {
+		fraction = fraction[:minorUnitDecimals]
+	}
+	fraction += strings.Repeat("0", minorUnitDecimals-len(fraction))
+	cents, _ := strconv.ParseInt(fraction, 10, 64)
+
+	amount := Money(units*minorUnitsPerUnit + cents)
+	if roundUp {
+		amount++
+	}
+	if amount <= 0 {
+		return 0, errInvalidMoney
+	}
+	return amount, nil
+}
+
+func allDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// MoneyFromFloat converts a decimal amount, as stored before prices moved to cents, to the nearest cent.
+func MoneyFromFloat(v float64) Money {
+	return Money(math.Round(v * minorUnitsPerUnit))
+}
+
+// Float returns the amount in major units, for ratios and APIs that expect decimals.
+func (m Money) Float() float64 {
+	return float64(m) / minorUnitsPerUnit
+}
+
+// String formats the amount with two decimals, e.g. "12.50".
+func (m Money) String() string {
+	return m.Format(minorUnitDecimals)
+}
+
+// Format formats the amount with the given number of decimals (0 to 2), rounding half away from zero.
+func (m Money) Format(decimals int) string {
+	decimals = max(0, min(decimals, minorUnitDecimals))
+	sign := ""
+	abs := int64(m)
+	if abs < 0 {
+		sign = "-"
+		abs = -abs
+	}
+
+	divisor := int64(math.Pow10(minorUnitDecimals - decimals))
+	abs = (abs + divisor/2) / divisor
+	if decimals == 0 {
+		return sign + strconv.FormatInt(abs, 10)
+	}
+	scale := int64(math.Pow10(decimals))
+	fraction := strconv.FormatInt(abs%scale, 10)
+	return sign + strconv.FormatInt(abs/scale, 10) + "." + strings.Repeat("0", decimals-len(fraction)) + fraction
+}
+
+// NumberFormat is how a profile writes decimal numbers.
+type NumberFormat string
+
+const (
+	// NumberFormatPoint writes 1,234.56.
+	NumberFormatPoint NumberFormat = "point"
+	// NumberFormatComma writes 1.234,56.
+	NumberFormatComma NumberFormat = "comma"
+)
+
+// NormalizeNumberFormat returns a known number format, defaulting to NumberFormatPoint.
+func NormalizeNumberFormat(raw string) NumberFormat {
+	if NumberFormat(strings.TrimSpace(raw)) == NumberFormatComma {
+		return NumberFormatComma
+	}
+	return NumberFormatPoint
+}
+
+// ParsePrice parses a price as people type it, such as "€120", "1.299,99", "1,299.99 USD" or "1'299.90".
+// Currency symbols and codes around the number are ignored. When both separators appear, the last one
+// is the decimal separator. A lone separator is a thousands separator only in groups of three digits
+// and only if the format allows it: "1.299" is 1299 with NumberFormatComma but 1.30 with NumberFormatPoint,
+// while "12,5" is 12.50 in both.
+func ParsePrice(raw string, format NumberFormat) (Money, error) {
+	if strings.Contains(raw, "-") {
+		return 0, errInvalidMoney
+	}
+	number := strings.TrimFunc(strings.TrimSpace(raw), func(r rune) bool {
+		return !(r >= '0' && r <= '9') && r != '.' && r != ','
+	})
+	number = strings.NewReplacer(" ", "", "\u00a0", "", "\u202f", "", "'", "", "\u2019", "").Replace(number)
+	if number == "" {
+		return 0, errInvalidMoney
+	}
+
+	decimal, thousands := ".", ","
+	lastComma, lastDot := strings.LastIndex(number, ","), strings.LastIndex(number, ".")
+	switch {
+	case lastComma >= 0 && lastDot >= 0:
+		if lastComma > lastDot {
+			decimal, thousands = ",", "."
+		}
+	case lastComma >= 0:
+		// A comma is a thousands separator only in well-formed groups like "1,299" or "1,299,000".
+		if NormalizeNumberFormat(string(format)) == NumberFormatComma || !thousandsGrouped(number, ",") {
+			decimal, thousands = ",", "."
+		}
+	case lastDot >= 0:
+		if NormalizeNumberFormat(string(format)) == NumberFormatComma && thousandsGrouped(number, ".") {
+			decimal, thousands = ",", "."
+		}
+	}
+
+	whole, fraction, hasFraction := strings.Cut(number, decimal)
+	if strings.Contains(fraction, decimal) || strings.Contains(fraction, thousands) {
+		return 0, errInvalidMoney
+	}
+	if strings.Contains(whole, thousands) {
+		if !thousandsGrouped(whole, thousands) {
+			return 0, errInvalidMoney
+		}
+		whole = strings.ReplaceAll(whole, thousands, "")
+	}
+	if hasFraction {
+		return ParseMoney(whole + "." + fraction)
+	}
+	return ParseMoney(whole)
+}
+
+// thousandsGrouped reports whether sep splits number into a leading group of one to three digits
+// followed by groups of exactly three.
+func thousandsGrouped(number, sep string) bool {
+	groups := strings.Split(number, sep)
+	if len(groups) < 2 || len(groups[0]) == 0 || len(groups[0]) > 3 {
+		return false
+	}
+	for _, group := range groups[1:] {
+		if len(group) != 3 {
+			return false
+		}
+	}
+	return true
+}
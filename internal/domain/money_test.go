@@ -0,0 +1,106 @@
+package domain
+
+import "testing"
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    Money
+		wantErr bool
+	}{
+		{raw: "12", want: 1200},
+		{raw: " 12.5 ", want: 1250},
+		{raw: ".99", want: 99},
+		{raw: "0.1", want: 10},
+		{raw: "19.995", want: 2000},
+		{raw: "19.994", want: 1999},
+		{raw: "0", wantErr: true},
+		{raw: "0.004", wantErr: true},
+		{raw: "-5", wantErr: true},
+		{raw: "1e3", wantErr: true},
+		{raw: "12,50", wantErr: true},
+		{raw: ".", wantErr: true},
+		{raw: "", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		got, err := ParseMoney(tc.raw)
+		if tc.wantErr {
+			if err == nil {
+				t.Fatalf("ParseMoney(%q): expected error, got %d", tc.raw, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("ParseMoney(%q): unexpected error %v", tc.raw, err)
+		}
+		if got != tc.want {
+			t.Fatalf("ParseMoney(%q) = %d, want %d", tc.raw, got, tc.want)
+		}
+	}
+}
+
+func TestMoneyFormat(t *testing.T) {
+	tests := []struct {
+		amount   Money
+		decimals int
+		want     string
+	}{
+		{amount: 1250, decimals: 2, want: "12.50"},
+		{amount: 5, decimals: 2, want: "0.05"},
+		{amount: 1250, decimals: 1, want: "12.5"},
+		{amount: 1250, decimals: 0, want: "13"},
+		{amount: 1249, decimals: 0, want: "12"},
+		{amount: -1999, decimals: 2, want: "-19.99"},
+	}
+
+	for _, tc := range tests {
+		if got := tc.amount.Format(tc.decimals); got != tc.want {
+			t.Fatalf("Money(%d).Format(%d) = %q, want %q", tc.amount, tc.decimals, got, tc.want)
+		}
+	}
+}
+
+func TestMoneySumsStayExact(t *testing.T) {
+	var total Money
+	for i := 0; i < 10; i++ {
+		total += MoneyFromFloat(0.1)
+	}
+	if total != 100 || total.String() != "1.00" {
+		t.Fatalf("expected ten times 0.10 to be exactly 1.00, got %s", total)
+	}
+}
+
+func TestParsePriceAcceptsLocalFormats(t *testing.T) {
+	tests := []struct {
+		raw    string
+		format NumberFormat
+		want   Money
+	}{
+		{"1.299,99", NumberFormatPoint, 129999},
+		{"1,299.99", NumberFormatComma, 129999},
+		{"€120", NumberFormatPoint, 12000},
+		{"120 €", NumberFormatComma, 12000},
+		{"USD 49.90", NumberFormatPoint, 4990},
+		{"1'299.90", NumberFormatPoint, 129990},
+		{"12,5", NumberFormatPoint, 1250},
+		{"12,5", NumberFormatComma, 1250},
+		{"1,299", NumberFormatPoint, 129900},
+		{"1,299", NumberFormatComma, 130},
+		{"1.299", NumberFormatPoint, 130},
+		{"1.299", NumberFormatComma, 129900},
+		{"1.234.567", NumberFormatComma, 123456700},
+		{"1 299,00", NumberFormatComma, 129900},
+	}
+	for _, tc := range tests {
+		if got, err := ParsePrice(tc.raw, tc.format); err != nil || got != tc.want {
+			t.Errorf("ParsePrice(%q, %s) = %d, %v; want %d", tc.raw, tc.format, got, err, tc.want)
+		}
+	}
+
+	for _, raw := range []string{"", "€", "-5", "1,2,3", "12.34.5", "1.299,99,1", "abc"} {
+		if got, err := ParsePrice(raw, NumberFormatComma); err == nil {
+			t.Errorf("ParsePrice(%q): expected error, got %d", raw, got)
+		}
+	}
+}
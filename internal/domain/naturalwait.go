@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WaitPresetText is the wait preset of a free-text wait such as "3 weeks" or "next Friday 18:00".
+// It is resolved once, when the item is saved, and stored as the "date" preset.
+const WaitPresetText = "text"
+
+// maxNaturalWait keeps typos like "300 years" from hiding an item forever.
+const maxNaturalWait = 5 * 366 * 24 * time.Hour
+
+var (
+	naturalRelative = regexp.MustCompile(`^(\d+(?:\.\d+)?|an?|one|two|three|four|five|six|seven|eight|nine|ten|eleven|twelve)\s*(minutes?|mins?|m|hours?|hrs?|h|days?|d|weeks?|w|months?|years?|y)$`)
+	naturalTime     = regexp.MustCompile(`^(?:at\s+)?(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+	naturalNumbers  = map[string]int{"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12}
+	naturalWeekdays = map[string]time.Weekday{
+		"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+		"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+		"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday, "thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+	}
+)
+
+// ParseNaturalWait resolves a free-text wait, relative to now and in now's time zone, into the time the
+// item may be bought. It understands durations ("3 weeks", "in 2 days", "36h"), days ("tomorrow",
+// "next Friday 18:00", "saturday 9am"), dates ("2026-06-01", "1.6.2026 12:00") and "next week",
+// "next month", "end of month" and "payday" (the profile's next payday, 0 if none is set). Named days and
+// dates without a time start at midnight.
+func ParseNaturalWait(text string, payday int, now time.Time) (time.Time, error) {
+	phrase := strings.Join(strings.Fields(strings.ToLower(text)), " ")
+	for _, prefix := range []string{"wait ", "until ", "till ", "til ", "after ", "in ", "for ", "the "} {
+		phrase = strings.TrimPrefix(phrase, prefix)
+	}
+	if phrase == "" {
+		return time.Time{}, invalid("wait_text", `Please describe the wait, for example "3 weeks" or "next Friday 18:00".`)
+	}
+
+	resolved, ok, err := resolveNaturalWait(phrase, payday, now)
+	switch {
+	case err != nil:
+		return time.Time{}, err
+	case !ok:
+		return time.Time{}, invalid("wait_text", `Sorry, "`+strings.TrimSpace(text)+`" is not a wait we understand. Try "3 weeks", "tomorrow 9:00", "next Friday 18:00" or a date like 2026-06-01.`)
+	case !resolved.After(now):
+		return time.Time{}, invalid("wait_text", "That time has already passed. Please pick a time in the future.")
+	case resolved.Sub(now) > maxNaturalWait:
+		return time.Time{}, invalid("wait_text", "Please pick a wait of at most five years.")
+	}
+	return resolved, nil
+}
+
+func resolveNaturalWait(phrase string, payday int, now time.Time) (time.Time, bool, error) {
+	if phrase == "payday" || phrase == "next payday" {
+		resolved, err := untilPayday("wait_text", payday, now)
+		return resolved, err == nil, err
+	}
+	if m := naturalRelative.FindStringSubmatch(phrase); m != nil {
+		return addNaturalDuration(now, m[1], m[2])
+	}
+
+	day, clock, _ := strings.Cut(phrase, " ")
+	midnight := startOfDayIn(now)
+	var base time.Time
+	switch {
+	case phrase == "next week":
+		return now.AddDate(0, 0, 7), true, nil
+	case phrase == "next month":
+		return now.AddDate(0, 1, 0), true, nil
+	case phrase == "end of month" || phrase == "end of the month":
+		return time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, now.Location()), true, nil
+	case day == "today":
+		base = midnight
+	case day == "tomorrow":
+		base = midnight.AddDate(0, 0, 1)
+	case day == "next" || day == "this" || day == "on":
+		day, clock, _ = strings.Cut(clock, " ")
+		weekday, ok := naturalWeekdays[day]
+		if !ok {
+			return time.Time{}, false, nil
+		}
+		base = nextWeekday(midnight, weekday)
+	default:
+		if weekday, ok := naturalWeekdays[day]; ok {
+			base = nextWeekday(midnight, weekday)
+			break
+		}
+		date, ok := parseNaturalDate(day, now.Location())
+		if !ok {
+			return time.Time{}, false, nil
+		}
+		base = date
+	}
+
+	if clock == "" {
+		return base, true, nil
+	}
+	hour, minute, ok := parseNaturalClock(clock)
+	if !ok {
+		return time.Time{}, false, nil
+	}
+	return time.Date(base.Year(), base.Month(), base.Day(), hour, minute, 0, 0, now.Location()), true, nil
+}
+
+func addNaturalDuration(now time.Time, amountRaw, unit string) (time.Time, bool, error) {
+	amount, known := naturalNumbers[amountRaw]
+	fraction := float64(amount)
+	if !known {
+		parsed, err := strconv.ParseFloat(amountRaw, 64)
+		if err != nil || parsed <= 0 {
+			return time.Time{}, false, nil
+		}
+		fraction = parsed
+	}
+
+	switch strings.TrimSuffix(unit, "s") {
+	case "minute", "min", "m":
+		return now.Add(time.Duration(fraction * float64(time.Minute))), true, nil
+	case "hour", "hr", "h":
+		return now.Add(time.Duration(fraction * float64(time.Hour))), true, nil
+	case "day", "d":
+		return now.Add(time.Duration(fraction * float64(24*time.Hour))), true, nil
+	case "week", "w":
+		return now.Add(time.Duration(fraction * float64(7*24*time.Hour))), true, nil
+	}
+	// Months and years follow the calendar, so they only take whole numbers.
+	if fraction != float64(int(fraction)) || fraction > 100 {
+		return time.Time{}, false, nil
+	}
+	switch strings.TrimSuffix(unit, "s") {
+	case "month":
+		return now.AddDate(0, int(fraction), 0), true, nil
+	default:
+		return now.AddDate(int(fraction), 0, 0), true, nil
+	}
+}
+
+// nextWeekday returns the next day after midnight's day that falls on weekday, a week ahead if it is that day.
+func nextWeekday(midnight time.Time, weekday time.Weekday) time.Time {
+	days := (int(weekday) - int(midnight.Weekday()) + 7) % 7
+	if days == 0 {
+		days = 7
+	}
+	return midnight.AddDate(0, 0, days)
+}
+
+func parseNaturalDate(raw string, location *time.Location) (time.Time, bool) {
+	for _, layout := range []string{"2006-01-02", "2.1.2006", "02.01.2006"} {
+		if parsed, err := time.ParseInLocation(layout, raw, location); err == nil {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseNaturalClock reads "18:00", "at 9", "6pm" or "6:30 pm".
+func parseNaturalClock(raw string) (int, int, bool) {
+	m := naturalTime.FindStringSubmatch(raw)
+	if m == nil {
+		return 0, 0, false
+	}
+	hour, _ := strconv.Atoi(m[1])
+	if m[3] != "" && (hour < 1 || hour > 12) {
+		return 0, 0, false
+	}
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	switch m[3] {
+	case "am":
+		if hour == 12 {
+			hour = 0
+		}
+	case "pm":
+		if hour < 12 {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return 0, 0, false
+	}
+	return hour, minute, true
+}
+
+// startOfDayIn returns midnight of t's day in t's own time zone.
+func startOfDayIn(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseNaturalWaitResolvesPhrases(t *testing.T) {
+	// testNow is Friday, 1 May 2026, 12:00.
+	tests := []struct {
+		text string
+		want time.Time
+	}{
+		{"3 weeks", time.Date(2026, 5, 22, 12, 0, 0, 0, time.UTC)},
+		{"in 2 days", time.Date(2026, 5, 3, 12, 0, 0, 0, time.UTC)},
+		{"36h", time.Date(2026, 5, 3, 0, 0, 0, 0, time.UTC)},
+		{"a month", time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)},
+		{"Tomorrow 9am", time.Date(2026, 5, 2, 9, 0, 0, 0, time.UTC)},
+		{"next Friday 18:00", time.Date(2026, 5, 8, 18, 0, 0, 0, time.UTC)},
+		{"until  monday", time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC)},
+		{"end of month", time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{"1.6.2026 12:00", time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)},
+		{"2026-07-15", time.Date(2026, 7, 15, 0, 0, 0, 0, time.UTC)},
+		{"until payday", time.Date(2026, 5, 25, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ParseNaturalWait(tt.text, 25, testNow)
+		if err != nil {
+			t.Errorf("ParseNaturalWait(%q): unexpected error: %v", tt.text, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseNaturalWait(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParseNaturalWaitRejectsUnclearOrPastWaits(t *testing.T) {
+	tests := []struct {
+		text, message string
+	}{
+		{"", "Please describe the wait"},
+		{"someday", "is not a wait we understand"},
+		{"next funday", "is not a wait we understand"},
+		{"tomorrow 25:00", "is not a wait we understand"},
+		{"today 9:00", "already passed"},
+		{"2026-01-01", "already passed"},
+		{"10 years", "at most five years"},
+		{"after payday", "set your payday"},
+	}
+	for _, tt := range tests {
+		_, err := ParseNaturalWait(tt.text, 0, testNow)
+		var invalid *ValidationError
+		if !errors.As(err, &invalid) || !strings.Contains(invalid.Message("wait_text"), tt.message) {
+			t.Errorf("ParseNaturalWait(%q): expected %q, got %v", tt.text, tt.message, err)
+		}
+	}
+}
+
+func TestResolvePurchaseAllowedAtReadsTextInTheBrowserZone(t *testing.T) {
+	// A browser two hours ahead of UTC reports an offset of -120.
+	got, err := ResolvePurchaseAllowedAt(WaitPresetText, "", "tomorrow 9:00", "-120", 0, testNow)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := time.Date(2026, 5, 2, 7, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if NormalizeWaitPreset(WaitPresetText) != "date" {
+		t.Fatalf("expected text waits to be stored as dates")
+	}
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Re-notification policies decide whether an item that becomes ready again, for example after it was
+// edited back to waiting or snoozed, is announced again.
+const (
+	RenotifyAlways = "always"
+	RenotifyOnce   = "once"
+	RenotifyDays   = "days"
+)
+
+const maxRenotifyDays = 365
+
+// RenotifyPolicy is a profile's re-notification policy. Days is only used by RenotifyDays.
+type RenotifyPolicy struct {
+	Mode string
+	Days int
+}
+
+// NormalizeRenotifyMode maps unknown or empty modes to RenotifyAlways.
+func NormalizeRenotifyMode(raw string) string {
+	switch mode := strings.ToLower(strings.TrimSpace(raw)); mode {
+	case RenotifyOnce, RenotifyDays:
+		return mode
+	default:
+		return RenotifyAlways
+	}
+}
+
+// ParseRenotifyPolicy validates a policy as submitted on the settings page.
+func ParseRenotifyPolicy(mode, days string) (RenotifyPolicy, error) {
+	policy := RenotifyPolicy{Mode: NormalizeRenotifyMode(mode)}
+	if policy.Mode != RenotifyDays {
+		return policy, nil
+	}
+	parsed, err := strconv.Atoi(strings.TrimSpace(days))
+	if err != nil || parsed < 1 || parsed > maxRenotifyDays {
+		return policy, invalid("renotify_days", "Please enter a number of days between 1 and 365.")
+	}
+	policy.Days = parsed
+	return policy, nil
+}
+
+// Allows reports whether an item last announced at lastNotified may be announced at now. Items
+// that were never announced always may.
+func (p RenotifyPolicy) Allows(lastNotified, now time.Time) bool {
+	if lastNotified.IsZero() {
+		return true
+	}
+	switch p.Mode {
+	case RenotifyOnce:
+		return false
+	case RenotifyDays:
+		return !now.Before(lastNotified.AddDate(0, 0, p.Days))
+	default:
+		return true
+	}
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRenotifyPolicyAllows(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		policy       RenotifyPolicy
+		lastNotified time.Time
+		want         bool
+	}{
+		{RenotifyPolicy{Mode: RenotifyOnce}, time.Time{}, true},
+		{RenotifyPolicy{Mode: RenotifyOnce}, now.Add(-30 * 24 * time.Hour), false},
+		{RenotifyPolicy{Mode: RenotifyAlways}, now.Add(-time.Minute), true},
+		{RenotifyPolicy{Mode: RenotifyDays, Days: 3}, now.Add(-72 * time.Hour), true},
+		{RenotifyPolicy{Mode: RenotifyDays, Days: 3}, now.Add(-71 * time.Hour), false},
+	}
+	for _, tt := range tests {
+		if got := tt.policy.Allows(tt.lastNotified, now); got != tt.want {
+			t.Fatalf("%+v after %v: expected %v, got %v", tt.policy, now.Sub(tt.lastNotified), tt.want, got)
+		}
+	}
+}
+
+func TestParseRenotifyPolicy(t *testing.T) {
+	if got, err := ParseRenotifyPolicy("bogus", "abc"); err != nil || got.Mode != RenotifyAlways {
+		t.Fatalf("expected unknown modes to fall back to always, got %+v %v", got, err)
+	}
+	if got, err := ParseRenotifyPolicy("days", " 7 "); err != nil || got != (RenotifyPolicy{Mode: RenotifyDays, Days: 7}) {
+		t.Fatalf("unexpected policy %+v %v", got, err)
+	}
+	_, err := ParseRenotifyPolicy("days", "0")
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) || invalid.Message("renotify_days") == "" {
+		t.Fatalf("expected a renotify_days validation error, got %v", err)
+	}
+}
@@ -0,0 +1,306 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const maxProfileNameLength = 64
+
+// Work hours display modes frame an item's price in terms of the profile's wage.
+const (
+	WorkHoursModeHours  = "hours"
+	WorkHoursModeDays   = "days"
+	WorkHoursModeShifts = "shifts"
+	WorkHoursModeIncome = "income"
+)
+
+// Work hours rounding modes decide which way work costs are rounded to the profile's precision.
+// Rounding up never makes an item look cheaper than it is.
+const (
+	WorkHoursRoundNearest = "nearest"
+	WorkHoursRoundUp      = "up"
+	WorkHoursRoundDown    = "down"
+)
+
+// Landing pages decide where opening the app leads a profile. The dashboard link inside the app
+// always shows the dashboard.
+const (
+	LandingDashboard   = "dashboard"
+	LandingQuickAdd    = "quick-add"
+	LandingLastVisited = "last-visited"
+)
+
+// DefaultWorkHoursPrecision is the number of decimals shown for work costs when a profile has not set one.
+const DefaultWorkHoursPrecision = 1
+
+const maxWorkHoursPrecision = 2
+
+// DefaultShiftHours is the shift length used when a profile has not set one.
+const DefaultShiftHours = 8.0
+
+const maxShiftHours = 24.0
+
+// DefaultWeeklyHours is the working week assumed when a profile has not set one.
+const DefaultWeeklyHours = 40.0
+
+// WeeksPerMonth converts weekly hours to monthly hours.
+const WeeksPerMonth = 52.0 / 12
+
+const maxWeeklyHours = 168.0
+
+// ProfileStore lists and removes profiles.
+type ProfileStore interface {
+	ProfileNames() ([]string, error)
+	DeleteProfile(name string) error
+}
+
+// ProfileService applies the profile rules.
+type ProfileService struct {
+	Store ProfileStore
+}
+
+// ProfileSettings are the general settings of a profile as submitted on the settings page.
+type ProfileSettings struct {
+	Name                   string
+	HourlyWage             string
+	DefaultWaitPreset      string
+	DefaultWaitCustomHours string
+	NtfyEndpoint           string
+	NtfyTopic              string
+	WorkHoursMode          string
+	ShiftHours             string
+	// MonthlyIncome is an optional net monthly salary. When set, HourlyWage is derived from it and WeeklyHours.
+	MonthlyIncome string
+	WeeklyHours   string
+	// RenotifyPolicy and RenotifyDays decide whether items that become ready again are announced again.
+	RenotifyPolicy string
+	RenotifyDays   string
+	// NumberFormat decides how prices entered by the profile are read; see ParsePrice.
+	NumberFormat string
+	// Payday is the day of the month the profile is paid on, used by the "payday" wait preset; see ParsePayday.
+	Payday string
+	// AvatarEmoji and AvatarColor tell profiles apart in the header; see ParseAvatarEmoji and AvatarColors.
+	AvatarEmoji string
+	AvatarColor string
+	// WorkHoursPrecision and WorkHoursRounding decide how work costs are shown; see RoundWorkHours.
+	WorkHoursPrecision string
+	WorkHoursRounding  string
+	// LandingPage is where opening the app leads; see NormalizeLandingPage.
+	LandingPage string
+}
+
+func ParseProfileName(raw string) (string, error) {
+	name := strings.TrimSpace(raw)
+	if name == "" {
+		return "", invalid("profile_name", "Please enter a profile name.")
+	}
+	if len([]rune(name)) > maxProfileNameLength {
+		return "", invalid("profile_name", "Profile name must be 64 characters or fewer.")
+	}
+	return name, nil
+}
+
+func ParseHourlyWage(raw string) (float64, error) {
+	parsed, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	if err != nil || parsed <= 0 {
+		return 0, invalid("hourly_wage", "Please enter a valid hourly wage (> 0).")
+	}
+
+	return parsed, nil
+}
+
+// ParseMonthlyIncome parses a net monthly salary.
+func ParseMonthlyIncome(raw string) (float64, error) {
+	parsed, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	if err != nil || parsed <= 0 {
+		return 0, invalid("monthly_income", "Please enter a valid monthly income (> 0).")
+	}
+	return parsed, nil
+}
+
+// ParseWeeklyHours parses the hours worked per week. An empty value means DefaultWeeklyHours.
+func ParseWeeklyHours(raw string) (float64, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return DefaultWeeklyHours, nil
+	}
+	parsed, err := strconv.ParseFloat(raw, 64)
+	if err != nil || parsed <= 0 || parsed > maxWeeklyHours {
+		return 0, invalid("weekly_hours", "Please enter weekly hours between 0 and 168.")
+	}
+	return parsed, nil
+}
+
+// HourlyWageFromMonthly derives the effective hourly wage of a monthly salary.
+func HourlyWageFromMonthly(monthlyIncome, weeklyHours float64) float64 {
+	return monthlyIncome / (weeklyHours * WeeksPerMonth)
+}
+
+// NormalizeWorkHoursMode maps unknown or empty modes to WorkHoursModeHours.
+func NormalizeWorkHoursMode(raw string) string {
+	switch mode := strings.ToLower(strings.TrimSpace(raw)); mode {
+	case WorkHoursModeDays, WorkHoursModeShifts, WorkHoursModeIncome:
+		return mode
+	default:
+		return WorkHoursModeHours
+	}
+}
+
+// NormalizeWorkHoursRounding maps unknown or empty rounding modes to WorkHoursRoundNearest.
+func NormalizeWorkHoursRounding(raw string) string {
+	switch mode := strings.ToLower(strings.TrimSpace(raw)); mode {
+	case WorkHoursRoundUp, WorkHoursRoundDown:
+		return mode
+	default:
+		return WorkHoursRoundNearest
+	}
+}
+
+// NormalizeLandingPage maps unknown or empty landing pages to LandingDashboard.
+func NormalizeLandingPage(raw string) string {
+	switch page := strings.ToLower(strings.TrimSpace(raw)); page {
+	case LandingQuickAdd, LandingLastVisited:
+		return page
+	default:
+		return LandingDashboard
+	}
+}
+
+// ParseWorkHoursPrecision parses the number of decimals shown for work costs. An empty value means
+// DefaultWorkHoursPrecision.
+func ParseWorkHoursPrecision(raw string) (int, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return DefaultWorkHoursPrecision, nil
+	}
+	parsed, err := strconv.Atoi(raw)
+	if err != nil || parsed < 0 || parsed > maxWorkHoursPrecision {
+		return 0, invalid("work_hours_precision", "Please choose between 0 and 2 decimals.")
+	}
+	return parsed, nil
+}
+
+// RoundWorkHours rounds a work cost to the given number of decimals. Values that are already exact
+// within floating point noise are not pushed to the next step when rounding up or down.
+func RoundWorkHours(value float64, precision int, mode string) float64 {
+	scale := math.Pow(10, float64(precision))
+	scaled := value * scale
+	switch NormalizeWorkHoursRounding(mode) {
+	case WorkHoursRoundUp:
+		return math.Ceil(scaled-1e-9) / scale
+	case WorkHoursRoundDown:
+		return math.Floor(scaled+1e-9) / scale
+	default:
+		return math.Round(scaled) / scale
+	}
+}
+
+// ParseShiftHours parses the length of one shift. An empty value means DefaultShiftHours.
+func ParseShiftHours(raw string) (float64, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return DefaultShiftHours, nil
+	}
+	parsed, err := strconv.ParseFloat(raw, 64)
+	if err != nil || parsed <= 0 || parsed > maxShiftHours {
+		return 0, invalid("shift_hours", "Please enter a shift length between 0 and 24 hours.")
+	}
+	return parsed, nil
+}
+
+// ValidateSettings checks the settings and returns them normalized for storage. On a validation
+// error the returned settings hold the trimmed input so the form can be shown again.
+func (s ProfileService) ValidateSettings(in ProfileSettings) (ProfileSettings, error) {
+	in.Name = strings.TrimSpace(in.Name)
+	in.HourlyWage = strings.TrimSpace(in.HourlyWage)
+	in.DefaultWaitPreset = strings.TrimSpace(in.DefaultWaitPreset)
+	in.DefaultWaitCustomHours = strings.TrimSpace(in.DefaultWaitCustomHours)
+	in.NtfyEndpoint = strings.TrimRight(strings.TrimSpace(in.NtfyEndpoint), "/")
+	in.NtfyTopic = strings.TrimSpace(in.NtfyTopic)
+	in.WorkHoursMode = strings.TrimSpace(in.WorkHoursMode)
+	in.ShiftHours = strings.TrimSpace(in.ShiftHours)
+	in.MonthlyIncome = strings.TrimSpace(in.MonthlyIncome)
+	in.WeeklyHours = strings.TrimSpace(in.WeeklyHours)
+	in.RenotifyDays = strings.TrimSpace(in.RenotifyDays)
+	in.WorkHoursPrecision = strings.TrimSpace(in.WorkHoursPrecision)
+
+	// The parsers only return validation errors, so Merge never hands one back.
+	var v Validation
+	_, err := ParseProfileName(in.Name)
+	_ = v.Merge(err)
+	weeklyHours, err := ParseWeeklyHours(in.WeeklyHours)
+	_ = v.Merge(err)
+	if in.MonthlyIncome != "" {
+		monthlyIncome, err := ParseMonthlyIncome(in.MonthlyIncome)
+		_ = v.Merge(err)
+		if err == nil && weeklyHours > 0 {
+			in.HourlyWage = strconv.FormatFloat(HourlyWageFromMonthly(monthlyIncome, weeklyHours), 'f', 2, 64)
+		}
+	} else {
+		_, err = ParseHourlyWage(in.HourlyWage)
+		_ = v.Merge(err)
+	}
+	if _, err := ParseWaitDuration(in.DefaultWaitPreset, in.DefaultWaitCustomHours); err != nil {
+		// The settings form names its wait inputs default_wait_preset and default_wait_custom_hours.
+		var invalid *ValidationError
+		if errors.As(err, &invalid) {
+			for _, f := range invalid.Fields {
+				v.Add("default_"+f.Field, f.Message)
+			}
+		}
+	}
+	_, err = ParseShiftHours(in.ShiftHours)
+	_ = v.Merge(err)
+	_, err = ParseWorkHoursPrecision(in.WorkHoursPrecision)
+	_ = v.Merge(err)
+	_, err = ParseRenotifyPolicy(in.RenotifyPolicy, in.RenotifyDays)
+	_ = v.Merge(err)
+	payday, err := ParsePayday(in.Payday)
+	_ = v.Merge(err)
+	avatarEmoji, err := ParseAvatarEmoji(in.AvatarEmoji)
+	_ = v.Merge(err)
+	avatarColor, err := ParseAvatarColor(in.AvatarColor)
+	_ = v.Merge(err)
+	if (in.NtfyEndpoint == "") != (in.NtfyTopic == "") {
+		v.Add("ntfy_endpoint", "Please provide both ntfy endpoint and topic, or leave both empty.")
+	}
+	if err := v.Err(); err != nil {
+		return in, err
+	}
+
+	out := in
+	out.DefaultWaitPreset = NormalizeWaitPreset(in.DefaultWaitPreset)
+	if out.DefaultWaitPreset != "custom" {
+		out.DefaultWaitCustomHours = ""
+	}
+	out.WorkHoursMode = NormalizeWorkHoursMode(in.WorkHoursMode)
+	out.WorkHoursRounding = NormalizeWorkHoursRounding(in.WorkHoursRounding)
+	out.LandingPage = NormalizeLandingPage(in.LandingPage)
+	out.NumberFormat = string(NormalizeNumberFormat(in.NumberFormat))
+	out.Payday = ""
+	if payday > 0 {
+		out.Payday = strconv.Itoa(payday)
+	}
+	out.AvatarEmoji = avatarEmoji
+	out.AvatarColor = avatarColor
+	out.RenotifyPolicy = NormalizeRenotifyMode(in.RenotifyPolicy)
+	if out.RenotifyPolicy != RenotifyDays {
+		out.RenotifyDays = ""
+	}
+	return out, nil
+}
+
+// Delete removes a profile unless it is the last one.
+func (s ProfileService) Delete(name string) error {
+	names, err := s.Store.ProfileNames()
+	if err != nil {
+		return err
+	}
+	if len(names) <= 1 {
+		return ErrLastProfile
+	}
+	return s.Store.DeleteProfile(name)
+}
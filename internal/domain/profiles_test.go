@@ -0,0 +1,180 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseHourlyWage(t *testing.T) {
+	tests := []struct {
+		name            string
+		raw             string
+		want            float64
+		wantErrContains string
+	}{
+		{name: "valid", raw: "20", want: 20},
+		{name: "valid decimal", raw: "17.5", want: 17.5},
+		{name: "empty", raw: "", wantErrContains: "valid hourly wage"},
+		{name: "zero", raw: "0", wantErrContains: "valid hourly wage"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseHourlyWage(tt.raw)
+			if tt.wantErrContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrContains) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErrContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+type fakeProfileStore struct {
+	names   []string
+	deleted []string
+}
+
+func (s *fakeProfileStore) ProfileNames() ([]string, error) { return s.names, nil }
+
+func (s *fakeProfileStore) DeleteProfile(name string) error {
+	s.deleted = append(s.deleted, name)
+	return nil
+}
+
+func TestProfileServiceDeleteKeepsLastProfile(t *testing.T) {
+	store := &fakeProfileStore{names: []string{"Alex"}}
+	if err := (ProfileService{Store: store}).Delete("Alex"); !errors.Is(err, ErrLastProfile) {
+		t.Fatalf("expected ErrLastProfile, got %v", err)
+	}
+
+	store.names = []string{"Alex", "Bea"}
+	if err := (ProfileService{Store: store}).Delete("Alex"); err != nil || len(store.deleted) != 1 {
+		t.Fatalf("expected Alex to be deleted, got %v %v", err, store.deleted)
+	}
+}
+
+func TestProfileServiceValidateSettings(t *testing.T) {
+	service := ProfileService{}
+
+	got, err := service.ValidateSettings(ProfileSettings{Name: " Alex ", HourlyWage: "20", DefaultWaitPreset: "7d", DefaultWaitCustomHours: "5", NtfyEndpoint: "https://ntfy.sh/", NtfyTopic: "alex"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Name != "Alex" || got.DefaultWaitCustomHours != "" || got.NtfyEndpoint != "https://ntfy.sh" {
+		t.Fatalf("unexpected normalized settings %+v", got)
+	}
+
+	_, err = service.ValidateSettings(ProfileSettings{Name: "Alex", HourlyWage: "20", NtfyTopic: "alex"})
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) || invalid.Message("ntfy_endpoint") == "" || !strings.Contains(invalid.Error(), "both ntfy endpoint and topic") {
+		t.Fatalf("expected ntfy validation error, got %v", err)
+	}
+}
+
+func TestProfileServiceValidateSettingsWorkHoursMode(t *testing.T) {
+	got, err := ProfileService{}.ValidateSettings(ProfileSettings{Name: "Alex", HourlyWage: "20", WorkHoursMode: "unknown"})
+	if err != nil || got.WorkHoursMode != WorkHoursModeHours {
+		t.Fatalf("expected unknown modes to fall back to hours, got %+v %v", got, err)
+	}
+
+	_, err = ProfileService{}.ValidateSettings(ProfileSettings{Name: "Alex", HourlyWage: "20", WorkHoursMode: WorkHoursModeShifts, ShiftHours: "30"})
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) || invalid.Message("shift_hours") == "" {
+		t.Fatalf("expected shift length validation error, got %v", err)
+	}
+}
+
+func TestRoundWorkHoursHonoursPrecisionAndMode(t *testing.T) {
+	tests := []struct {
+		value     float64
+		precision int
+		mode      string
+		want      float64
+	}{
+		{value: 3.04, precision: 1, mode: WorkHoursRoundNearest, want: 3.0},
+		{value: 3.04, precision: 1, mode: WorkHoursRoundUp, want: 3.1},
+		{value: 3.09, precision: 1, mode: WorkHoursRoundDown, want: 3.0},
+		{value: 2.5, precision: 0, mode: "", want: 3},
+		{value: 2.01, precision: 0, mode: WorkHoursRoundUp, want: 3},
+		{value: 0.3 / 0.1, precision: 0, mode: WorkHoursRoundUp, want: 3},
+		{value: 1.234, precision: 2, mode: WorkHoursRoundUp, want: 1.24},
+	}
+	for _, tt := range tests {
+		if got := RoundWorkHours(tt.value, tt.precision, tt.mode); got != tt.want {
+			t.Fatalf("RoundWorkHours(%v, %d, %q) = %v, want %v", tt.value, tt.precision, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestProfileServiceValidateSettingsWorkHoursRounding(t *testing.T) {
+	got, err := ProfileService{}.ValidateSettings(ProfileSettings{Name: "Alex", HourlyWage: "20", WorkHoursPrecision: " 2 ", WorkHoursRounding: "UP"})
+	if err != nil || got.WorkHoursPrecision != "2" || got.WorkHoursRounding != WorkHoursRoundUp {
+		t.Fatalf("expected precision and rounding to be kept, got %+v %v", got, err)
+	}
+
+	_, err = ProfileService{}.ValidateSettings(ProfileSettings{Name: "Alex", HourlyWage: "20", WorkHoursPrecision: "3"})
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) || invalid.Message("work_hours_precision") == "" {
+		t.Fatalf("expected precision validation error, got %v", err)
+	}
+}
+
+func TestProfileServiceValidateSettingsDerivesHourlyWageFromMonthlyIncome(t *testing.T) {
+	got, err := ProfileService{}.ValidateSettings(ProfileSettings{Name: "Alex", HourlyWage: "99", MonthlyIncome: "3466.67", WeeklyHours: "40"})
+	if err != nil || got.HourlyWage != "20.00" || got.MonthlyIncome != "3466.67" {
+		t.Fatalf("expected hourly wage derived from the monthly income, got %+v %v", got, err)
+	}
+
+	_, err = ProfileService{}.ValidateSettings(ProfileSettings{Name: "Alex", MonthlyIncome: "3000", WeeklyHours: "200"})
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) || invalid.Message("weekly_hours") == "" || invalid.Message("hourly_wage") != "" {
+		t.Fatalf("expected only a weekly hours validation error, got %v", err)
+	}
+}
+
+func TestProfileServiceValidateSettingsReportsEveryField(t *testing.T) {
+	_, err := ProfileService{}.ValidateSettings(ProfileSettings{HourlyWage: "0", DefaultWaitPreset: "custom", NtfyEndpoint: "https://ntfy.sh"})
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	for _, field := range []string{"profile_name", "hourly_wage", "default_wait_custom_hours", "ntfy_endpoint"} {
+		if invalid.Message(field) == "" {
+			t.Fatalf("expected a message for %s, got %+v", field, invalid.Fields)
+		}
+	}
+}
+
+func TestProfileServiceValidateSettingsAvatar(t *testing.T) {
+	got, err := ProfileService{}.ValidateSettings(ProfileSettings{Name: "Alex", HourlyWage: "20", AvatarEmoji: " 👩‍💻 ", AvatarColor: "Teal"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.AvatarEmoji != "👩‍💻" || got.AvatarColor != "teal" {
+		t.Fatalf("expected normalized avatar, got %q %q", got.AvatarEmoji, got.AvatarColor)
+	}
+
+	_, err = ProfileService{}.ValidateSettings(ProfileSettings{Name: "Alex", HourlyWage: "20", AvatarEmoji: "AB", AvatarColor: "gold"})
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) || invalid.Message("avatar_emoji") == "" || invalid.Message("avatar_color") == "" {
+		t.Fatalf("expected avatar validation errors, got %v", err)
+	}
+}
+
+func TestDefaultAvatarColorIsStable(t *testing.T) {
+	if DefaultAvatarColor("Alex") != DefaultAvatarColor("alex") {
+		t.Fatal("expected the default color to ignore case")
+	}
+	if got := AvatarInitial(" sam"); got != "S" {
+		t.Fatalf("expected initial S, got %q", got)
+	}
+}
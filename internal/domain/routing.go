@@ -0,0 +1,159 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Routing rule conditions: which ready items a rule applies to.
+const (
+	RouteWhenAny          = "any"
+	RouteWhenPriceAtLeast = "price_at_least"
+	RouteWhenTag          = "tag"
+	RouteWhenShared       = "shared"
+)
+
+// Routing rule channels: where a matching item's ready notification goes.
+const (
+	// RouteToDefault sends it over ntfy and web push, as without rules.
+	RouteToDefault = "default"
+	RouteToPush    = "push"
+	RouteToNtfy    = "ntfy"
+	// RouteToTopic sends it to another ntfy topic on the profile's endpoint, such as a household topic.
+	RouteToTopic = "topic"
+	// RouteToDigest collects it for the weekly digest instead of announcing it right away.
+	RouteToDigest = "digest"
+)
+
+// RoutingRule sends the ready notifications of matching items to one channel. Rules are evaluated in
+// order and the first match wins; see Route.
+type RoutingRule struct {
+	When    string
+	Value   string
+	Channel string
+	// Topic is only used by RouteToTopic.
+	Topic string
+}
+
+// ParseRoutingRule validates a rule as submitted on the settings page. Minimum prices are stored with
+// two decimals, so rules match the same regardless of the number format they were entered in.
+func ParseRoutingRule(when, value, channel, topic string, format NumberFormat) (RoutingRule, error) {
+	rule := RoutingRule{
+		When:    strings.TrimSpace(when),
+		Value:   strings.TrimSpace(value),
+		Channel: strings.TrimSpace(channel),
+		Topic:   strings.TrimSpace(topic),
+	}
+	switch rule.When {
+	case RouteWhenAny, RouteWhenShared:
+		rule.Value = ""
+	case RouteWhenPriceAtLeast:
+		price, err := ParsePrice(rule.Value, format)
+		if err != nil {
+			return rule, invalid("value", "Please enter the minimum price, such as 200.")
+		}
+		rule.Value = price.String()
+	case RouteWhenTag:
+		if rule.Value == "" {
+			return rule, invalid("value", "Please enter the tag the rule applies to.")
+		}
+	default:
+		return rule, invalid("when", "Please choose which items the rule applies to.")
+	}
+	switch rule.Channel {
+	case RouteToDefault, RouteToPush, RouteToNtfy, RouteToDigest:
+		rule.Topic = ""
+	case RouteToTopic:
+		if rule.Topic == "" || strings.ContainsAny(rule.Topic, "/ ") {
+			return rule, invalid("topic", "Please enter an ntfy topic name without spaces or slashes.")
+		}
+	default:
+		return rule, invalid("channel", "Please choose where the notification goes.")
+	}
+	return rule, nil
+}
+
+// ParseRoutingRules reads rules as stored by FormatRoutingRules, skipping lines that are not valid rules.
+func ParseRoutingRules(raw string) []RoutingRule {
+	var rules []RoutingRule
+	for _, line := range strings.Split(raw, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 4 {
+			continue
+		}
+		rule, err := ParseRoutingRule(fields[0], fields[1], fields[2], fields[3], NumberFormatPoint)
+		if err != nil {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// FormatRoutingRules writes one rule per line as tab-separated condition, value, channel and topic.
+func FormatRoutingRules(rules []RoutingRule) string {
+	lines := make([]string, 0, len(rules))
+	for _, r := range rules {
+		lines = append(lines, strings.Join([]string{r.When, r.Value, r.Channel, r.Topic}, "\t"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Matches reports whether the rule applies to item.
+func (r RoutingRule) Matches(item Item) bool {
+	switch r.When {
+	case RouteWhenAny:
+		return true
+	case RouteWhenPriceAtLeast:
+		minimum, err := ParsePrice(r.Value, NumberFormatPoint)
+		return err == nil && item.HasPriceValue && item.PriceCents >= minimum
+	case RouteWhenTag:
+		for _, tag := range strings.Split(item.Tags, ",") {
+			if strings.EqualFold(strings.TrimSpace(tag), r.Value) {
+				return true
+			}
+		}
+	case RouteWhenShared:
+		return len(item.SharedWith) > 0
+	}
+	return false
+}
+
+// Route returns the first rule matching item, or a RouteToDefault rule when none does.
+func Route(rules []RoutingRule, item Item) RoutingRule {
+	for _, rule := range rules {
+		if rule.Matches(item) {
+			return rule
+		}
+	}
+	return RoutingRule{When: RouteWhenAny, Channel: RouteToDefault}
+}
+
+// Describe is the rule in words, as listed on the settings page.
+func (r RoutingRule) Describe() string {
+	var when string
+	switch r.When {
+	case RouteWhenPriceAtLeast:
+		when = "Items costing at least " + r.Value
+	case RouteWhenTag:
+		when = fmt.Sprintf("Items tagged %q", r.Value)
+	case RouteWhenShared:
+		when = "Items shared with the household"
+	default:
+		when = "All other items"
+	}
+	var to string
+	switch r.Channel {
+	case RouteToPush:
+		to = "web push only"
+	case RouteToNtfy:
+		to = "ntfy only"
+	case RouteToTopic:
+		to = "the ntfy topic " + r.Topic
+	case RouteToDigest:
+		to = "the weekly digest"
+	default:
+		to = "ntfy and web push"
+	}
+	return when + " go to " + to
+}
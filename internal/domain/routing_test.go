@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRouteUsesTheFirstMatchingRule(t *testing.T) {
+	rules := ParseRoutingRules(FormatRoutingRules([]RoutingRule{
+		{When: RouteWhenShared, Channel: RouteToTopic, Topic: "household"},
+		{When: RouteWhenPriceAtLeast, Value: "200.00", Channel: RouteToPush},
+		{When: RouteWhenTag, Value: "books", Channel: RouteToNtfy},
+		{When: RouteWhenAny, Channel: RouteToDigest},
+	}) + "\nbroken line")
+	if len(rules) != 4 {
+		t.Fatalf("expected the four stored rules back, got %+v", rules)
+	}
+	tests := []struct {
+		name string
+		item Item
+		want string
+	}{
+		{"shared wins over price", Item{SharedWith: []string{"Bea"}, PriceCents: 50000, HasPriceValue: true}, RouteToTopic},
+		{"expensive", Item{PriceCents: 20000, HasPriceValue: true}, RouteToPush},
+		{"tag ignores case", Item{Tags: "Home, Books", PriceCents: 1999, HasPriceValue: true}, RouteToNtfy},
+		{"no price is not expensive", Item{}, RouteToDigest},
+	}
+	for _, tt := range tests {
+		if got := Route(rules, tt.item); got.Channel != tt.want {
+			t.Fatalf("%s: expected %s, got %+v", tt.name, tt.want, got)
+		}
+	}
+	if got := Route(nil, Item{}); got.Channel != RouteToDefault {
+		t.Fatalf("expected the default route without rules, got %+v", got)
+	}
+}
+
+func TestParseRoutingRule(t *testing.T) {
+	rule, err := ParseRoutingRule("price_at_least", "1.299,50", "push", "ignored", NumberFormatComma)
+	if err != nil || rule != (RoutingRule{When: RouteWhenPriceAtLeast, Value: "1299.50", Channel: RouteToPush}) {
+		t.Fatalf("unexpected rule %+v %v", rule, err)
+	}
+	if rule.Describe() != "Items costing at least 1299.50 go to web push only" {
+		t.Fatalf("unexpected description %q", rule.Describe())
+	}
+	for _, tt := range []struct{ when, value, channel, topic, field string }{
+		{"price_at_least", "cheap", "push", "", "value"},
+		{"tag", " ", "ntfy", "", "value"},
+		{"weekday", "", "ntfy", "", "when"},
+		{"any", "", "topic", "our home", "topic"},
+		{"any", "", "email", "", "channel"},
+	} {
+		_, err := ParseRoutingRule(tt.when, tt.value, tt.channel, tt.topic, NumberFormatPoint)
+		var invalid *ValidationError
+		if !errors.As(err, &invalid) || invalid.Message(tt.field) == "" {
+			t.Fatalf("expected a %s error for %+v, got %v", tt.field, tt, err)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"slices"
+	"strings"
+)
+
+// SimilarDecisions returns the items of history that were bought or skipped and resemble item: they
+// share a tag with it and, when both have a price, cost between two thirds and one and a half times
+// as much. The most recent decisions come first, at most limit of them.
+func SimilarDecisions(item Item, history []Item, limit int) []Item {
+	tags := lowerTags(item.Tags)
+	if len(tags) == 0 {
+		return nil
+	}
+	var similar []Item
+	for _, past := range history {
+		if past.ID == item.ID || (past.Status != StatusBought && past.Status != StatusSkipped) {
+			continue
+		}
+		if !slices.ContainsFunc(lowerTags(past.Tags), func(tag string) bool { return slices.Contains(tags, tag) }) {
+			continue
+		}
+		if item.HasPriceValue && past.HasPriceValue && (3*past.PriceCents < 2*item.PriceCents || 2*past.PriceCents > 3*item.PriceCents) {
+			continue
+		}
+		similar = append(similar, past)
+	}
+	slices.SortStableFunc(similar, func(a, b Item) int { return b.DecidedAt.Compare(a.DecidedAt) })
+	if len(similar) > limit {
+		similar = similar[:limit]
+	}
+	return similar
+}
+
+func lowerTags(raw string) []string {
+	var tags []string
+	for _, part := range strings.Split(raw, ",") {
+		if tag := strings.ToLower(strings.TrimSpace(part)); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
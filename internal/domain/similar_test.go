@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSimilarDecisions(t *testing.T) {
+	now := time.Now()
+	item := Item{ID: 1, Tags: "Audio, Tech", PriceCents: 10000, HasPriceValue: true, Status: StatusReady}
+	history := []Item{
+		item,
+		{ID: 2, Title: "Old earbuds", Tags: "audio", PriceCents: 8000, HasPriceValue: true, Status: StatusSkipped, DecidedAt: now.Add(-48 * time.Hour)},
+		{ID: 3, Title: "Speaker", Tags: "tech", PriceCents: 14000, HasPriceValue: true, Status: StatusBought, DecidedAt: now.Add(-time.Hour)},
+		{ID: 4, Title: "Amplifier", Tags: "audio", PriceCents: 40000, HasPriceValue: true, Status: StatusBought, DecidedAt: now},
+		{ID: 5, Title: "Cable", Tags: "audio", Status: StatusSkipped, DecidedAt: now.Add(-72 * time.Hour)},
+		{ID: 6, Title: "Turntable", Tags: "audio", PriceCents: 9000, HasPriceValue: true, Status: StatusWaiting},
+		{ID: 7, Title: "Lamp", Tags: "home", PriceCents: 10000, HasPriceValue: true, Status: StatusBought, DecidedAt: now},
+	}
+
+	got := SimilarDecisions(item, history, 5)
+	var titles []string
+	for _, similar := range got {
+		titles = append(titles, similar.Title)
+	}
+	if len(titles) != 3 || titles[0] != "Speaker" || titles[1] != "Old earbuds" || titles[2] != "Cable" {
+		t.Fatalf("expected the decided items with a shared tag and a similar or unknown price, newest first, got %v", titles)
+	}
+	if got := SimilarDecisions(item, history, 1); len(got) != 1 || got[0].ID != 3 {
+		t.Fatalf("expected the limit to keep the most recent decision, got %+v", got)
+	}
+	if got := SimilarDecisions(Item{ID: 8}, history, 5); got != nil {
+		t.Fatalf("expected untagged items to have no similar items, got %+v", got)
+	}
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+)
+
+// HasSplit reports whether the price of a shared item is split between profiles.
+func (i Item) HasSplit() bool {
+	for _, percent := range i.SplitPercents {
+		if percent > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// SharePercent returns the part of the price the profile carries. Without a split every profile
+// carries the full price; with one the owner carries what the shared profiles do not.
+func (i Item) SharePercent(profile string) int {
+	if !i.HasSplit() {
+		return 100
+	}
+	if percent, ok := i.SplitPercents[profile]; ok {
+		return percent
+	}
+	if profile != i.OwnerID && i.OwnerID != "" {
+		return 0
+	}
+	rest := 100
+	for _, percent := range i.SplitPercents {
+		rest -= percent
+	}
+	return rest
+}
+
+// ParseSplitPercents parses the percentages of the profiles an item is shared with, given as
+// parallel lists. Empty values count as zero and the shares may not exceed 100% together.
+func ParseSplitPercents(profiles, percents []string) (map[string]int, error) {
+	if len(profiles) != len(percents) {
+		return nil, invalid("split_percent", "Please enter a share for every profile.")
+	}
+	splits := make(map[string]int, len(profiles))
+	total := 0
+	for idx, profile := range profiles {
+		raw := strings.TrimSpace(percents[idx])
+		if raw == "" {
+			raw = "0"
+		}
+		percent, err := strconv.Atoi(raw)
+		if err != nil || percent < 0 || percent > 100 {
+			return nil, invalid("split_percent", "Please enter whole percentages between 0 and 100.")
+		}
+		splits[strings.TrimSpace(profile)] = percent
+		total += percent
+	}
+	if total > 100 {
+		return nil, invalid("split_percent", "The shares of the other profiles may not add up to more than 100%.")
+	}
+	return splits, nil
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestItemSharePercent(t *testing.T) {
+	item := Item{OwnerID: "Alex", SharedWith: []string{"Sam", "Kim"}}
+	if got := item.SharePercent("Sam"); got != 100 {
+		t.Fatalf("expected unsplit items to be carried in full, got %d", got)
+	}
+
+	item.SplitPercents = map[string]int{"Sam": 30, "Kim": 0}
+	for profile, want := range map[string]int{"Alex": 70, "Sam": 30, "Kim": 0, "Jo": 0} {
+		if got := item.SharePercent(profile); got != want {
+			t.Fatalf("expected %s to carry %d%%, got %d", profile, want, got)
+		}
+	}
+}
+
+func TestParseSplitPercents(t *testing.T) {
+	splits, err := ParseSplitPercents([]string{"Sam", "Kim"}, []string{"40", ""})
+	if err != nil || splits["Sam"] != 40 || splits["Kim"] != 0 {
+		t.Fatalf("unexpected splits %v %v", splits, err)
+	}
+
+	for _, percents := range [][]string{{"60", "50"}, {"-1", "0"}, {"1.5", "0"}} {
+		_, err := ParseSplitPercents([]string{"Sam", "Kim"}, percents)
+		var invalid *ValidationError
+		if !errors.As(err, &invalid) || invalid.Message("split_percent") == "" {
+			t.Fatalf("expected %v to be rejected, got %v", percents, err)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Status is the lifecycle state of an item.
+type Status string
+
+const (
+	StatusResearching Status = "Researching"
+	StatusWaiting     Status = "Waiting"
+	StatusReady       Status = "Ready to buy"
+	StatusBought      Status = "Bought"
+	StatusSkipped     Status = "Skipped"
+)
+
+// Statuses lists every status in lifecycle order.
+var Statuses = []Status{StatusResearching, StatusWaiting, StatusReady, StatusBought, StatusSkipped}
+
+// OpenStatuses are the statuses of items that still await a decision.
+var OpenStatuses = []Status{StatusResearching, StatusWaiting, StatusReady}
+
+// Action is something that changes the status of an item.
+type Action string
+
+const (
+	ActionStartWait Action = "start wait"
+	ActionPromote   Action = "promote"
+	ActionSnooze    Action = "snooze"
+	ActionBuy       Action = "buy"
+	ActionSkip      Action = "skip"
+	ActionEdit      Action = "edit"
+	// ActionRecordPurchase records a purchase made outside the app, whatever the wait says.
+	ActionRecordPurchase Action = "record purchase"
+	// ActionBackfill records a decision made in the past, when old purchases are imported.
+	ActionBackfill Action = "backfill"
+	// ActionOverrideBlackout unlocks an item held back by a blackout, for emergencies.
+	ActionOverrideBlackout Action = "override blackout"
+)
+
+// transitions lists, for each status, the actions allowed on it and the statuses they may lead to.
+// Editing re-resolves the wait, so it reopens skipped items but keeps researching and bought items as they are.
+var transitions = map[Status]map[Action][]Status{
+	StatusResearching: {
+		ActionStartWait:      {StatusWaiting, StatusReady},
+		ActionEdit:           {StatusResearching},
+		ActionRecordPurchase: {StatusBought},
+		ActionBackfill:       {StatusBought, StatusSkipped},
+	},
+	StatusWaiting: {
+		ActionPromote:          {StatusReady},
+		ActionEdit:             {StatusWaiting, StatusReady},
+		ActionRecordPurchase:   {StatusBought},
+		ActionBackfill:         {StatusBought, StatusSkipped},
+		ActionOverrideBlackout: {StatusReady},
+	},
+	StatusReady: {
+		ActionBuy:            {StatusBought},
+		ActionSkip:           {StatusSkipped},
+		ActionSnooze:         {StatusWaiting},
+		ActionEdit:           {StatusWaiting, StatusReady},
+		ActionRecordPurchase: {StatusBought},
+		ActionBackfill:       {StatusBought, StatusSkipped},
+	},
+	StatusSkipped: {
+		ActionEdit:     {StatusWaiting, StatusReady},
+		ActionBackfill: {StatusBought, StatusSkipped},
+	},
+	StatusBought: {
+		ActionEdit:     {StatusBought},
+		ActionBackfill: {StatusBought, StatusSkipped},
+	},
+}
+
+// ParseStatus returns the status with the given name.
+func ParseStatus(raw string) (Status, error) {
+	status := Status(strings.TrimSpace(raw))
+	if !slices.Contains(Statuses, status) {
+		return "", ErrInvalidStatus
+	}
+	return status, nil
+}
+
+func (s Status) String() string {
+	return string(s)
+}
+
+// Decided reports whether the item was bought or skipped.
+func (s Status) Decided() bool {
+	return s == StatusBought || s == StatusSkipped
+}
+
+// CanPerform reports whether the action applies to items in status s at all.
+func (s Status) CanPerform(action Action) bool {
+	return len(transitions[s][action]) > 0
+}
+
+// Allows reports whether the action may move an item in status s to next.
+func (s Status) Allows(action Action, next Status) bool {
+	return slices.Contains(transitions[s][action], next)
+}
+
+// CheckTransition returns an error wrapping ErrTransitionNotAllowed unless the action may move an item from one status to the other.
+func CheckTransition(from Status, action Action, to Status) error {
+	if !from.Allows(action, to) {
+		return fmt.Errorf("%w: cannot %s from %q to %q", ErrTransitionNotAllowed, action, from, to)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseStatus(t *testing.T) {
+	if status, err := ParseStatus(" Ready to buy "); err != nil || status != StatusReady {
+		t.Fatalf("expected StatusReady, got %q %v", status, err)
+	}
+	if _, err := ParseStatus("ready"); !errors.Is(err, ErrInvalidStatus) {
+		t.Fatalf("expected ErrInvalidStatus, got %v", err)
+	}
+}
+
+func TestTransitionTable(t *testing.T) {
+	tests := []struct {
+		from   Status
+		action Action
+		to     Status
+		want   bool
+	}{
+		{StatusResearching, ActionStartWait, StatusWaiting, true},
+		{StatusResearching, ActionStartWait, StatusReady, true},
+		{StatusResearching, ActionPromote, StatusReady, false},
+		{StatusResearching, ActionEdit, StatusResearching, true},
+		{StatusWaiting, ActionPromote, StatusReady, true},
+		{StatusWaiting, ActionBuy, StatusBought, false},
+		{StatusWaiting, ActionSnooze, StatusWaiting, false},
+		{StatusWaiting, ActionStartWait, StatusWaiting, false},
+		{StatusReady, ActionBuy, StatusBought, true},
+		{StatusReady, ActionSkip, StatusSkipped, true},
+		{StatusReady, ActionBuy, StatusSkipped, false},
+		{StatusReady, ActionSnooze, StatusWaiting, true},
+		{StatusReady, ActionEdit, StatusWaiting, true},
+		{StatusSkipped, ActionEdit, StatusWaiting, true},
+		{StatusSkipped, ActionSnooze, StatusWaiting, false},
+		{StatusSkipped, ActionBuy, StatusBought, false},
+		{StatusBought, ActionEdit, StatusBought, true},
+		{StatusBought, ActionEdit, StatusWaiting, false},
+		{StatusBought, ActionSkip, StatusSkipped, false},
+		{StatusWaiting, ActionRecordPurchase, StatusBought, true},
+		{StatusResearching, ActionRecordPurchase, StatusBought, true},
+		{StatusSkipped, ActionRecordPurchase, StatusBought, false},
+	}
+
+	for _, tt := range tests {
+		err := CheckTransition(tt.from, tt.action, tt.to)
+		if got := err == nil; got != tt.want {
+			t.Errorf("%s %s → %s: allowed=%v, want %v", tt.action, tt.from, tt.to, got, tt.want)
+		}
+		if err != nil && !errors.Is(err, ErrTransitionNotAllowed) {
+			t.Errorf("%s %s → %s: expected ErrTransitionNotAllowed, got %v", tt.action, tt.from, tt.to, err)
+		}
+	}
+}
+
+func TestEveryStatusHasTransitions(t *testing.T) {
+	for _, status := range Statuses {
+		if _, ok := transitions[status]; !ok {
+			t.Errorf("status %q is missing from the transition table", status)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+// FieldError is the message for one rejected form or API field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError rejects user input. It carries one message per rejected field, in the order
+// the fields were checked, written for the person who submitted them.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Message)
+	}
+	return strings.Join(messages, " ")
+}
+
+// Unwrap makes every validation error match ErrValidation.
+func (e *ValidationError) Unwrap() error { return ErrValidation }
+
+// Message returns the message for field, or "" if the field was accepted.
+func (e *ValidationError) Message(field string) string {
+	for _, f := range e.Fields {
+		if f.Field == field {
+			return f.Message
+		}
+	}
+	return ""
+}
+
+// FieldMessages returns the messages keyed by field, for rendering next to form inputs.
+func (e *ValidationError) FieldMessages() map[string]string {
+	messages := make(map[string]string, len(e.Fields))
+	for _, f := range e.Fields {
+		messages[f.Field] = f.Message
+	}
+	return messages
+}
+
+// Validation collects field errors so a form reports every problem at once. The zero value is ready to use.
+type Validation struct {
+	fields []FieldError
+}
+
+// Add rejects field with message. Only the first message per field is kept.
+func (v *Validation) Add(field, message string) {
+	for _, f := range v.fields {
+		if f.Field == field {
+			return
+		}
+	}
+	v.fields = append(v.fields, FieldError{Field: field, Message: message})
+}
+
+// Merge adds the fields of a *ValidationError and returns nil; any other non-nil error is returned unchanged.
+func (v *Validation) Merge(err error) error {
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		return err
+	}
+	for _, f := range invalid.Fields {
+		v.Add(f.Field, f.Message)
+	}
+	return nil
+}
+
+// Err returns a *ValidationError holding the collected fields, or nil if there are none.
+func (v *Validation) Err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: append([]FieldError(nil), v.fields...)}
+}
+
+func invalid(field, message string) error {
+	return &ValidationError{Fields: []FieldError{{Field: field, Message: message}}}
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidationCollectsFirstMessagePerField(t *testing.T) {
+	var v Validation
+	if err := v.Err(); err != nil {
+		t.Fatalf("expected empty validation to pass, got %v", err)
+	}
+
+	v.Add("title", "Please enter a title.")
+	v.Add("title", "Title is too long.")
+	if err := v.Merge(invalid("price", "Please enter a valid price.")); err != nil {
+		t.Fatalf("expected validation error to be merged, got %v", err)
+	}
+	other := errors.New("disk full")
+	if err := v.Merge(other); err != other {
+		t.Fatalf("expected other errors to be returned, got %v", err)
+	}
+
+	var invalid *ValidationError
+	if !errors.As(v.Err(), &invalid) || len(invalid.Fields) != 2 {
+		t.Fatalf("expected two field errors, got %v", v.Err())
+	}
+	if invalid.Message("title") != "Please enter a title." || invalid.FieldMessages()["price"] != "Please enter a valid price." {
+		t.Fatalf("unexpected messages %+v", invalid.Fields)
+	}
+	if got := invalid.Error(); got != "Please enter a title. Please enter a valid price." {
+		t.Fatalf("unexpected error text %q", got)
+	}
+}
@@ -0,0 +1,152 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WaitPresetPayday waits until the profile's next payday.
+const WaitPresetPayday = "payday"
+
+// NormalizeWaitPreset returns a known wait preset for storage, defaulting to "24h". A free-text
+// wait is stored as the date it resolved to.
+func NormalizeWaitPreset(raw string) string {
+	switch strings.TrimSpace(raw) {
+	case WaitPresetText:
+		return "date"
+	case "7d", "30d", "custom", "date", WaitPresetPayday:
+		return strings.TrimSpace(raw)
+	default:
+		return "24h"
+	}
+}
+
+// ParseWaitDuration returns the length of a relative wait preset.
+func ParseWaitDuration(waitPreset string, waitCustomHours string) (time.Duration, error) {
+	preset := strings.TrimSpace(waitPreset)
+	if preset == "" {
+		preset = "24h"
+	}
+
+	switch preset {
+	case "24h":
+		return 24 * time.Hour, nil
+	case "7d":
+		return 7 * 24 * time.Hour, nil
+	case "30d":
+		return 30 * 24 * time.Hour, nil
+	case "custom":
+		hours, err := strconv.ParseFloat(strings.TrimSpace(waitCustomHours), 64)
+		if err != nil || hours <= 0 {
+			return 0, invalid("wait_custom_hours", "Please enter a valid number of custom hours (> 0).")
+		}
+		return time.Duration(hours * float64(time.Hour)), nil
+	default:
+		return 0, invalid("wait_preset", "Please select a valid wait time.")
+	}
+}
+
+// browserLocation returns the time zone of a browser's getTimezoneOffset, or the server's zone if it is empty.
+func browserLocation(timezoneOffsetMinutesRaw string) (*time.Location, bool) {
+	if timezoneOffsetMinutesRaw == "" {
+		return time.Local, true
+	}
+	offsetMinutes, err := strconv.Atoi(timezoneOffsetMinutesRaw)
+	if err != nil {
+		return nil, false
+	}
+	return time.FixedZone("browser", -offsetMinutes*60), true
+}
+
+func parsePurchaseAllowedAt(raw string, timezoneOffsetMinutesRaw string) (time.Time, error) {
+	location, ok := browserLocation(timezoneOffsetMinutesRaw)
+	if !ok {
+		return time.Time{}, invalid("purchase_allowed_at", "Please enter a valid buy-after date and time.")
+	}
+
+	parsed, err := time.ParseInLocation("2006-01-02T15:04", strings.TrimSpace(raw), location)
+	if err != nil {
+		return time.Time{}, invalid("purchase_allowed_at", "Please enter a valid buy-after date and time.")
+	}
+	return parsed, nil
+}
+
+// ParsePayday reads the day of the month a profile is paid on. Empty means no payday is set and yields 0.
+func ParsePayday(raw string) (int, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, nil
+	}
+	day, err := strconv.Atoi(raw)
+	if err != nil || day < 1 || day > 31 {
+		return 0, invalid("payday", "Please enter a payday between 1 and 31, or leave it empty.")
+	}
+	return day, nil
+}
+
+// NextPayday returns midnight of the first payday after now, in now's time zone. Paydays past the end
+// of a short month fall on its last day, so a payday of 31 is 30 April.
+func NextPayday(payday int, now time.Time) time.Time {
+	for months := 0; ; months++ {
+		first := time.Date(now.Year(), now.Month()+time.Month(months), 1, 0, 0, 0, 0, now.Location())
+		day := min(payday, first.AddDate(0, 1, -1).Day())
+		if candidate := first.AddDate(0, 0, day-1); candidate.After(now) {
+			return candidate
+		}
+	}
+}
+
+// untilPayday resolves the "payday" preset, failing with a hint to the settings when no payday is set.
+func untilPayday(field string, payday int, now time.Time) (time.Time, error) {
+	if payday < 1 || payday > 31 {
+		return time.Time{}, invalid(field, "Please set your payday in the settings to wait until payday.")
+	}
+	return NextPayday(payday, now), nil
+}
+
+// ResolvePurchaseAllowedAt returns when an item may be bought: the entered date for the "date"
+// preset, the described time for the "text" preset, the next payday for the "payday" preset, otherwise
+// now plus the preset's wait. purchaseAllowedRaw holds the date or the description. The offset is the
+// browser's getTimezoneOffset; payday is the profile's day of the month, or 0 if it has none.
+func ResolvePurchaseAllowedAt(waitPreset string, waitCustomHours string, purchaseAllowedRaw string, timezoneOffsetMinutesRaw string, payday int, now time.Time) (time.Time, error) {
+	switch strings.TrimSpace(waitPreset) {
+	case WaitPresetText, WaitPresetPayday:
+		location, ok := browserLocation(strings.TrimSpace(timezoneOffsetMinutesRaw))
+		if !ok {
+			location = time.Local
+		}
+		if strings.TrimSpace(waitPreset) == WaitPresetPayday {
+			return untilPayday("wait_preset", payday, now.In(location))
+		}
+		return ParseNaturalWait(purchaseAllowedRaw, payday, now.In(location))
+	}
+	if NormalizeWaitPreset(waitPreset) == "date" {
+		if strings.TrimSpace(purchaseAllowedRaw) == "" {
+			return time.Time{}, invalid("purchase_allowed_at", "Please enter a buy-after date and time.")
+		}
+		return parsePurchaseAllowedAt(purchaseAllowedRaw, strings.TrimSpace(timezoneOffsetMinutesRaw))
+	}
+
+	waitDuration, err := ParseWaitDuration(waitPreset, waitCustomHours)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return now.Add(waitDuration), nil
+}
+
+// ActiveStatus is the status of an item whose wait ends at purchaseAllowedAt.
+func ActiveStatus(purchaseAllowedAt, now time.Time) Status {
+	if purchaseAllowedAt.After(now) {
+		return StatusWaiting
+	}
+	return StatusReady
+}
+
+// researchingPurchaseAllowedAt keeps only an explicitly chosen date; relative waits are counted from when the wait starts.
+func researchingPurchaseAllowedAt(waitPreset string, resolved time.Time) time.Time {
+	if waitPreset == "date" {
+		return resolved
+	}
+	return time.Time{}
+}
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParsePurchaseAllowedAtWithTimezoneOffset(t *testing.T) {
+	parsed, err := parsePurchaseAllowedAt("2026-02-12T10:30", "-120")
+	if err != nil {
+		t.Fatalf("expected valid datetime, got %v", err)
+	}
+	if got := parsed.Format(time.RFC3339); got != "2026-02-12T10:30:00+02:00" {
+		t.Fatalf("unexpected parsed datetime %q", got)
+	}
+}
+
+func TestParsePurchaseAllowedAtRejectsInvalidTimezoneOffset(t *testing.T) {
+	if _, err := parsePurchaseAllowedAt("2026-02-12T10:30", "oops"); err == nil {
+		t.Fatalf("expected timezone parse error")
+	}
+}
+
+func TestParseWaitDuration(t *testing.T) {
+	tests := []struct {
+		name            string
+		preset          string
+		customHours     string
+		wantDuration    time.Duration
+		wantErrContains string
+	}{
+		{name: "default", preset: "", wantDuration: 24 * time.Hour},
+		{name: "24h", preset: "24h", wantDuration: 24 * time.Hour},
+		{name: "7d", preset: "7d", wantDuration: 7 * 24 * time.Hour},
+		{name: "30d", preset: "30d", wantDuration: 30 * 24 * time.Hour},
+		{name: "custom", preset: "custom", customHours: "5", wantDuration: 5 * time.Hour},
+		{name: "invalid custom", preset: "custom", customHours: "0", wantErrContains: "valid number"},
+		{name: "invalid preset", preset: "abc", wantErrContains: "valid wait time"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseWaitDuration(tt.preset, tt.customHours)
+			if tt.wantErrContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrContains) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErrContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.wantDuration {
+				t.Fatalf("expected %s, got %s", tt.wantDuration, got)
+			}
+		})
+	}
+}
+
+func TestResolvePurchaseAllowedAtReportsInvalidField(t *testing.T) {
+	_, err := ResolvePurchaseAllowedAt("date", "", "", "", 0, time.Now())
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) || invalid.Message("purchase_allowed_at") == "" {
+		t.Fatalf("expected a purchase_allowed_at validation error, got %v", err)
+	}
+}
+
+func TestNextPaydayRollsOverAndClampsShortMonths(t *testing.T) {
+	tests := []struct {
+		payday int
+		now    time.Time
+		want   time.Time
+	}{
+		{25, time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC), time.Date(2026, 5, 25, 0, 0, 0, 0, time.UTC)},
+		{1, time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC), time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{31, time.Date(2026, 4, 2, 8, 0, 0, 0, time.UTC), time.Date(2026, 4, 30, 0, 0, 0, 0, time.UTC)},
+		{30, time.Date(2026, 1, 31, 8, 0, 0, 0, time.UTC), time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC)},
+		{15, time.Date(2026, 12, 20, 8, 0, 0, 0, time.UTC), time.Date(2027, 1, 15, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := NextPayday(tt.payday, tt.now); !got.Equal(tt.want) {
+			t.Errorf("NextPayday(%d, %v) = %v, want %v", tt.payday, tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestResolvePurchaseAllowedAtWaitsUntilPayday(t *testing.T) {
+	got, err := ResolvePurchaseAllowedAt(WaitPresetPayday, "", "", "", 25, testNow)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := time.Date(2026, 5, 25, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if NormalizeWaitPreset(WaitPresetPayday) != WaitPresetPayday {
+		t.Fatalf("expected the payday preset to be kept")
+	}
+
+	_, err = ResolvePurchaseAllowedAt(WaitPresetPayday, "", "", "", 0, testNow)
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) || !strings.Contains(invalid.Message("wait_preset"), "set your payday") {
+		t.Fatalf("expected a hint to set the payday, got %v", err)
+	}
+	if _, err := ParsePayday("32"); err == nil {
+		t.Fatalf("expected payday 32 to be rejected")
+	}
+}
@@ -0,0 +1,1150 @@
+// gRPC interface for internal integrations. The server runs next to the web app when GRPC_PORT is set
+// and expects the admin token as "authorization: Bearer <token>" metadata.
+//
+// Regenerate the Go code in internal/impulsepausev1 after changing this file:
+//
+//   protoc --go_out=. --go_opt=module=mvpapp --go-grpc_out=. --go-grpc_opt=module=mvpapp \
+//     proto/impulsepause/v1/impulsepause.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: proto/impulsepause/v1/impulsepause.proto
+
+package impulsepausev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ItemStatus int32
+
+const (
+	ItemStatus_ITEM_STATUS_UNSPECIFIED ItemStatus = 0
+	ItemStatus_ITEM_STATUS_RESEARCHING ItemStatus = 1
+	ItemStatus_ITEM_STATUS_WAITING     ItemStatus = 2
+	ItemStatus_ITEM_STATUS_READY       ItemStatus = 3
+	ItemStatus_ITEM_STATUS_BOUGHT      ItemStatus = 4
+	ItemStatus_ITEM_STATUS_SKIPPED     ItemStatus = 5
+)
+
+// Enum value maps for ItemStatus.
+var (
+	ItemStatus_name = map[int32]string{
+		0: "ITEM_STATUS_UNSPECIFIED",
+		1: "ITEM_STATUS_RESEARCHING",
+		2: "ITEM_STATUS_WAITING",
+		3: "ITEM_STATUS_READY",
+		4: "ITEM_STATUS_BOUGHT",
+		5: "ITEM_STATUS_SKIPPED",
+	}
+	ItemStatus_value = map[string]int32{
+		"ITEM_STATUS_UNSPECIFIED": 0,
+		"ITEM_STATUS_RESEARCHING": 1,
+		"ITEM_STATUS_WAITING":     2,
+		"ITEM_STATUS_READY":       3,
+		"ITEM_STATUS_BOUGHT":      4,
+		"ITEM_STATUS_SKIPPED":     5,
+	}
+)
+
+func (x ItemStatus) Enum() *ItemStatus {
+	p := new(ItemStatus)
+	*p = x
+	return p
+}
+
+func (x ItemStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ItemStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_impulsepause_v1_impulsepause_proto_enumTypes[0].Descriptor()
+}
+
+func (ItemStatus) Type() protoreflect.EnumType {
+	return &file_proto_impulsepause_v1_impulsepause_proto_enumTypes[0]
+}
+
+func (x ItemStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ItemStatus.Descriptor instead.
+func (ItemStatus) EnumDescriptor() ([]byte, []int) {
+	return file_proto_impulsepause_v1_impulsepause_proto_rawDescGZIP(), []int{0}
+}
+
+type Item struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id    int64  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title string `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	// price is the price as entered; price_cents is only set when it could be read as an amount.
+	Price             string                 `protobuf:"bytes,3,opt,name=price,proto3" json:"price,omitempty"`
+	PriceCents        *int64                 `protobuf:"varint,4,opt,name=price_cents,json=priceCents,proto3,oneof" json:"price_cents,omitempty"`
+	Link              string                 `protobuf:"bytes,5,opt,name=link,proto3" json:"link,omitempty"`
+	Note              string                 `protobuf:"bytes,6,opt,name=note,proto3" json:"note,omitempty"`
+	Tags              []string               `protobuf:"bytes,7,rep,name=tags,proto3" json:"tags,omitempty"`
+	Status            ItemStatus             `protobuf:"varint,8,opt,name=status,proto3,enum=impulsepause.v1.ItemStatus" json:"status,omitempty"`
+	WaitPreset        string                 `protobuf:"bytes,9,opt,name=wait_preset,json=waitPreset,proto3" json:"wait_preset,omitempty"`
+	PurchaseAllowedAt *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=purchase_allowed_at,json=purchaseAllowedAt,proto3" json:"purchase_allowed_at,omitempty"`
+	CreatedAt         *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	DecidedAt         *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=decided_at,json=decidedAt,proto3" json:"decided_at,omitempty"`
+}
+
+func (x *Item) Reset() {
+	*x = Item{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_impulsepause_v1_impulsepause_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Item) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Item) ProtoMessage() {}
+
+func (x *Item) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_impulsepause_v1_impulsepause_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Item.ProtoReflect.Descriptor instead.
+func (*Item) Descriptor() ([]byte, []int) {
+	return file_proto_impulsepause_v1_impulsepause_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Item) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Item) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Item) GetPrice() string {
+	if x != nil {
+		return x.Price
+	}
+	return ""
+}
+
+func (x *Item) GetPriceCents() int64 {
+	if x != nil && x.PriceCents != nil {
+		return *x.PriceCents
+	}
+	return 0
+}
+
+func (x *Item) GetLink() string {
+	if x != nil {
+		return x.Link
+	}
+	return ""
+}
+
+func (x *Item) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+func (x *Item) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Item) GetStatus() ItemStatus {
+	if x != nil {
+		return x.Status
+	}
+	return ItemStatus_ITEM_STATUS_UNSPECIFIED
+}
+
+func (x *Item) GetWaitPreset() string {
+	if x != nil {
+		return x.WaitPreset
+	}
+	return ""
+}
+
+func (x *Item) GetPurchaseAllowedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PurchaseAllowedAt
+	}
+	return nil
+}
+
+func (x *Item) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Item) GetDecidedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DecidedAt
+	}
+	return nil
+}
+
+type ListItemsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Profile string `protobuf:"bytes,1,opt,name=profile,proto3" json:"profile,omitempty"`
+	// query, statuses, tag and sort match the q, status, tag and sort parameters of GET /api/v1/items.
+	Query    string       `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`
+	Statuses []ItemStatus `protobuf:"varint,3,rep,packed,name=statuses,proto3,enum=impulsepause.v1.ItemStatus" json:"statuses,omitempty"`
+	Tag      string       `protobuf:"bytes,4,opt,name=tag,proto3" json:"tag,omitempty"`
+	Sort     string       `protobuf:"bytes,5,opt,name=sort,proto3" json:"sort,omitempty"`
+	// page_size of 0 returns every remaining item; at most 500.
+	PageSize  int32  `protobuf:"varint,6,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken string `protobuf:"bytes,7,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+}
+
+func (x *ListItemsRequest) Reset() {
+	*x = ListItemsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_impulsepause_v1_impulsepause_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListItemsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListItemsRequest) ProtoMessage() {}
+
+func (x *ListItemsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_impulsepause_v1_impulsepause_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListItemsRequest.ProtoReflect.Descriptor instead.
+func (*ListItemsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_impulsepause_v1_impulsepause_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListItemsRequest) GetProfile() string {
+	if x != nil {
+		return x.Profile
+	}
+	return ""
+}
+
+func (x *ListItemsRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *ListItemsRequest) GetStatuses() []ItemStatus {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+func (x *ListItemsRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *ListItemsRequest) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+func (x *ListItemsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListItemsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListItemsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Items         []*Item `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	NextPageToken string  `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+}
+
+func (x *ListItemsResponse) Reset() {
+	*x = ListItemsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_impulsepause_v1_impulsepause_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListItemsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListItemsResponse) ProtoMessage() {}
+
+func (x *ListItemsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_impulsepause_v1_impulsepause_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListItemsResponse.ProtoReflect.Descriptor instead.
+func (*ListItemsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_impulsepause_v1_impulsepause_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListItemsResponse) GetItems() []*Item {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *ListItemsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type GetItemRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Profile string `protobuf:"bytes,1,opt,name=profile,proto3" json:"profile,omitempty"`
+	Id      int64  `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *GetItemRequest) Reset() {
+	*x = GetItemRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_impulsepause_v1_impulsepause_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetItemRequest) ProtoMessage() {}
+
+func (x *GetItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_impulsepause_v1_impulsepause_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetItemRequest.ProtoReflect.Descriptor instead.
+func (*GetItemRequest) Descriptor() ([]byte, []int) {
+	return file_proto_impulsepause_v1_impulsepause_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetItemRequest) GetProfile() string {
+	if x != nil {
+		return x.Profile
+	}
+	return ""
+}
+
+func (x *GetItemRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type CreateItemRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Profile string   `protobuf:"bytes,1,opt,name=profile,proto3" json:"profile,omitempty"`
+	Title   string   `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Price   string   `protobuf:"bytes,3,opt,name=price,proto3" json:"price,omitempty"`
+	Link    string   `protobuf:"bytes,4,opt,name=link,proto3" json:"link,omitempty"`
+	Note    string   `protobuf:"bytes,5,opt,name=note,proto3" json:"note,omitempty"`
+	Tags    []string `protobuf:"bytes,6,rep,name=tags,proto3" json:"tags,omitempty"`
+	// wait_preset, wait_custom_hours, purchase_allowed_at and wait_text work as in POST /api/v1/items;
+	// omitted waits fall back to the tag and profile defaults.
+	WaitPreset        string                 `protobuf:"bytes,7,opt,name=wait_preset,json=waitPreset,proto3" json:"wait_preset,omitempty"`
+	WaitCustomHours   string                 `protobuf:"bytes,8,opt,name=wait_custom_hours,json=waitCustomHours,proto3" json:"wait_custom_hours,omitempty"`
+	PurchaseAllowedAt *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=purchase_allowed_at,json=purchaseAllowedAt,proto3" json:"purchase_allowed_at,omitempty"`
+	WaitText          string                 `protobuf:"bytes,10,opt,name=wait_text,json=waitText,proto3" json:"wait_text,omitempty"`
+	Researching       bool                   `protobuf:"varint,11,opt,name=researching,proto3" json:"researching,omitempty"`
+}
+
+func (x *CreateItemRequest) Reset() {
+	*x = CreateItemRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_impulsepause_v1_impulsepause_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreateItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateItemRequest) ProtoMessage() {}
+
+func (x *CreateItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_impulsepause_v1_impulsepause_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateItemRequest.ProtoReflect.Descriptor instead.
+func (*CreateItemRequest) Descriptor() ([]byte, []int) {
+	return file_proto_impulsepause_v1_impulsepause_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateItemRequest) GetProfile() string {
+	if x != nil {
+		return x.Profile
+	}
+	return ""
+}
+
+func (x *CreateItemRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *CreateItemRequest) GetPrice() string {
+	if x != nil {
+		return x.Price
+	}
+	return ""
+}
+
+func (x *CreateItemRequest) GetLink() string {
+	if x != nil {
+		return x.Link
+	}
+	return ""
+}
+
+func (x *CreateItemRequest) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+func (x *CreateItemRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *CreateItemRequest) GetWaitPreset() string {
+	if x != nil {
+		return x.WaitPreset
+	}
+	return ""
+}
+
+func (x *CreateItemRequest) GetWaitCustomHours() string {
+	if x != nil {
+		return x.WaitCustomHours
+	}
+	return ""
+}
+
+func (x *CreateItemRequest) GetPurchaseAllowedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PurchaseAllowedAt
+	}
+	return nil
+}
+
+func (x *CreateItemRequest) GetWaitText() string {
+	if x != nil {
+		return x.WaitText
+	}
+	return ""
+}
+
+func (x *CreateItemRequest) GetResearching() bool {
+	if x != nil {
+		return x.Researching
+	}
+	return false
+}
+
+type DecideItemRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Profile string `protobuf:"bytes,1,opt,name=profile,proto3" json:"profile,omitempty"`
+	Id      int64  `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	// decision is ITEM_STATUS_BOUGHT or ITEM_STATUS_SKIPPED.
+	Decision ItemStatus `protobuf:"varint,3,opt,name=decision,proto3,enum=impulsepause.v1.ItemStatus" json:"decision,omitempty"`
+}
+
+func (x *DecideItemRequest) Reset() {
+	*x = DecideItemRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_impulsepause_v1_impulsepause_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DecideItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DecideItemRequest) ProtoMessage() {}
+
+func (x *DecideItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_impulsepause_v1_impulsepause_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DecideItemRequest.ProtoReflect.Descriptor instead.
+func (*DecideItemRequest) Descriptor() ([]byte, []int) {
+	return file_proto_impulsepause_v1_impulsepause_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DecideItemRequest) GetProfile() string {
+	if x != nil {
+		return x.Profile
+	}
+	return ""
+}
+
+func (x *DecideItemRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DecideItemRequest) GetDecision() ItemStatus {
+	if x != nil {
+		return x.Decision
+	}
+	return ItemStatus_ITEM_STATUS_UNSPECIFIED
+}
+
+type ListProfilesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ListProfilesRequest) Reset() {
+	*x = ListProfilesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_impulsepause_v1_impulsepause_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListProfilesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProfilesRequest) ProtoMessage() {}
+
+func (x *ListProfilesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_impulsepause_v1_impulsepause_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProfilesRequest.ProtoReflect.Descriptor instead.
+func (*ListProfilesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_impulsepause_v1_impulsepause_proto_rawDescGZIP(), []int{6}
+}
+
+type ListProfilesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Profiles []string `protobuf:"bytes,1,rep,name=profiles,proto3" json:"profiles,omitempty"`
+}
+
+func (x *ListProfilesResponse) Reset() {
+	*x = ListProfilesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_impulsepause_v1_impulsepause_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListProfilesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProfilesResponse) ProtoMessage() {}
+
+func (x *ListProfilesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_impulsepause_v1_impulsepause_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProfilesResponse.ProtoReflect.Descriptor instead.
+func (*ListProfilesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_impulsepause_v1_impulsepause_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListProfilesResponse) GetProfiles() []string {
+	if x != nil {
+		return x.Profiles
+	}
+	return nil
+}
+
+type DeleteProfileRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Profile string `protobuf:"bytes,1,opt,name=profile,proto3" json:"profile,omitempty"`
+}
+
+func (x *DeleteProfileRequest) Reset() {
+	*x = DeleteProfileRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_impulsepause_v1_impulsepause_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteProfileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteProfileRequest) ProtoMessage() {}
+
+func (x *DeleteProfileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_impulsepause_v1_impulsepause_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteProfileRequest.ProtoReflect.Descriptor instead.
+func (*DeleteProfileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_impulsepause_v1_impulsepause_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DeleteProfileRequest) GetProfile() string {
+	if x != nil {
+		return x.Profile
+	}
+	return ""
+}
+
+type DeleteProfileResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *DeleteProfileResponse) Reset() {
+	*x = DeleteProfileResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_impulsepause_v1_impulsepause_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteProfileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteProfileResponse) ProtoMessage() {}
+
+func (x *DeleteProfileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_impulsepause_v1_impulsepause_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteProfileResponse.ProtoReflect.Descriptor instead.
+func (*DeleteProfileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_impulsepause_v1_impulsepause_proto_rawDescGZIP(), []int{9}
+}
+
+var File_proto_impulsepause_v1_impulsepause_proto protoreflect.FileDescriptor
+
+var file_proto_impulsepause_v1_impulsepause_proto_rawDesc = []byte{
+	0x0a, 0x28, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x69, 0x6d, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x70,
+	0x61, 0x75, 0x73, 0x65, 0x2f, 0x76, 0x31, 0x2f, 0x69, 0x6d, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x70,
+	0x61, 0x75, 0x73, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0f, 0x69, 0x6d, 0x70, 0x75,
+	0x6c, 0x73, 0x65, 0x70, 0x61, 0x75, 0x73, 0x65, 0x2e, 0x76, 0x31, 0x1a, 0x1f, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xcc, 0x03, 0x0a,
+	0x04, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x02, 0x69, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x70,
+	0x72, 0x69, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x72, 0x69, 0x63,
+	0x65, 0x12, 0x24, 0x0a, 0x0b, 0x70, 0x72, 0x69, 0x63, 0x65, 0x5f, 0x63, 0x65, 0x6e, 0x74, 0x73,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x0a, 0x70, 0x72, 0x69, 0x63, 0x65, 0x43,
+	0x65, 0x6e, 0x74, 0x73, 0x88, 0x01, 0x01, 0x12, 0x12, 0x0a, 0x04, 0x6c, 0x69, 0x6e, 0x6b, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6c, 0x69, 0x6e, 0x6b, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0x6f, 0x74, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x6f, 0x74, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x74,
+	0x61, 0x67, 0x73, 0x12, 0x33, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x1b, 0x2e, 0x69, 0x6d, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x70, 0x61, 0x75,
+	0x73, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x74, 0x65, 0x6d, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x77, 0x61, 0x69, 0x74,
+	0x5f, 0x70, 0x72, 0x65, 0x73, 0x65, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x77,
+	0x61, 0x69, 0x74, 0x50, 0x72, 0x65, 0x73, 0x65, 0x74, 0x12, 0x4a, 0x0a, 0x13, 0x70, 0x75, 0x72,
+	0x63, 0x68, 0x61, 0x73, 0x65, 0x5f, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x5f, 0x61, 0x74,
+	0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x52, 0x11, 0x70, 0x75, 0x72, 0x63, 0x68, 0x61, 0x73, 0x65, 0x41, 0x6c, 0x6c, 0x6f,
+	0x77, 0x65, 0x64, 0x41, 0x74, 0x12, 0x39, 0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64,
+	0x5f, 0x61, 0x74, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74,
+	0x12, 0x39, 0x0a, 0x0a, 0x64, 0x65, 0x63, 0x69, 0x64, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x0c,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x52, 0x09, 0x64, 0x65, 0x63, 0x69, 0x64, 0x65, 0x64, 0x41, 0x74, 0x42, 0x0e, 0x0a, 0x0c, 0x5f,
+	0x70, 0x72, 0x69, 0x63, 0x65, 0x5f, 0x63, 0x65, 0x6e, 0x74, 0x73, 0x22, 0xdd, 0x01, 0x0a, 0x10,
+	0x4c, 0x69, 0x73, 0x74, 0x49, 0x74, 0x65, 0x6d, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x18, 0x0a, 0x07, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x71, 0x75,
+	0x65, 0x72, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79,
+	0x12, 0x37, 0x0a, 0x08, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03,
+	0x28, 0x0e, 0x32, 0x1b, 0x2e, 0x69, 0x6d, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x70, 0x61, 0x75, 0x73,
+	0x65, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x74, 0x65, 0x6d, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52,
+	0x08, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x65, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x61, 0x67,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x74, 0x61, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x73,
+	0x6f, 0x72, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6f, 0x72, 0x74, 0x12,
+	0x1b, 0x0a, 0x09, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x08, 0x70, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x1d, 0x0a, 0x0a,
+	0x70, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x09, 0x70, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x68, 0x0a, 0x11, 0x4c,
+	0x69, 0x73, 0x74, 0x49, 0x74, 0x65, 0x6d, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x2b, 0x0a, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x15, 0x2e, 0x69, 0x6d, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x70, 0x61, 0x75, 0x73, 0x65, 0x2e, 0x76,
+	0x31, 0x2e, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x12, 0x26, 0x0a,
+	0x0f, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65,
+	0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x3a, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x49, 0x74, 0x65, 0x6d,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x72, 0x6f, 0x66, 0x69,
+	0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c,
+	0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69,
+	0x64, 0x22, 0xed, 0x02, 0x0a, 0x11, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x49, 0x74, 0x65, 0x6d,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x72, 0x6f, 0x66, 0x69,
+	0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c,
+	0x65, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65, 0x12, 0x12, 0x0a,
+	0x04, 0x6c, 0x69, 0x6e, 0x6b, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6c, 0x69, 0x6e,
+	0x6b, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x6f, 0x74, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x6e, 0x6f, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x06, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x77, 0x61, 0x69,
+	0x74, 0x5f, 0x70, 0x72, 0x65, 0x73, 0x65, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a,
+	0x77, 0x61, 0x69, 0x74, 0x50, 0x72, 0x65, 0x73, 0x65, 0x74, 0x12, 0x2a, 0x0a, 0x11, 0x77, 0x61,
+	0x69, 0x74, 0x5f, 0x63, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x5f, 0x68, 0x6f, 0x75, 0x72, 0x73, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x77, 0x61, 0x69, 0x74, 0x43, 0x75, 0x73, 0x74, 0x6f,
+	0x6d, 0x48, 0x6f, 0x75, 0x72, 0x73, 0x12, 0x4a, 0x0a, 0x13, 0x70, 0x75, 0x72, 0x63, 0x68, 0x61,
+	0x73, 0x65, 0x5f, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x09, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
+	0x11, 0x70, 0x75, 0x72, 0x63, 0x68, 0x61, 0x73, 0x65, 0x41, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64,
+	0x41, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x77, 0x61, 0x69, 0x74, 0x5f, 0x74, 0x65, 0x78, 0x74, 0x18,
+	0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x77, 0x61, 0x69, 0x74, 0x54, 0x65, 0x78, 0x74, 0x12,
+	0x20, 0x0a, 0x0b, 0x72, 0x65, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x69, 0x6e, 0x67, 0x18, 0x0b,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x72, 0x65, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x69, 0x6e,
+	0x67, 0x22, 0x76, 0x0a, 0x11, 0x44, 0x65, 0x63, 0x69, 0x64, 0x65, 0x49, 0x74, 0x65, 0x6d, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65,
+	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64,
+	0x12, 0x37, 0x0a, 0x08, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x1b, 0x2e, 0x69, 0x6d, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x70, 0x61, 0x75, 0x73,
+	0x65, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x74, 0x65, 0x6d, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52,
+	0x08, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x15, 0x0a, 0x13, 0x4c, 0x69, 0x73,
+	0x74, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x22, 0x32, 0x0a, 0x14, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x66,
+	0x69, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x66,
+	0x69, 0x6c, 0x65, 0x73, 0x22, 0x30, 0x0a, 0x14, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x50, 0x72,
+	0x6f, 0x66, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07,
+	0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70,
+	0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x22, 0x17, 0x0a, 0x15, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
+	0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2a,
+	0xa7, 0x01, 0x0a, 0x0a, 0x49, 0x74, 0x65, 0x6d, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1b,
+	0x0a, 0x17, 0x49, 0x54, 0x45, 0x4d, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x55, 0x4e,
+	0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x1b, 0x0a, 0x17, 0x49,
+	0x54, 0x45, 0x4d, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x52, 0x45, 0x53, 0x45, 0x41,
+	0x52, 0x43, 0x48, 0x49, 0x4e, 0x47, 0x10, 0x01, 0x12, 0x17, 0x0a, 0x13, 0x49, 0x54, 0x45, 0x4d,
+	0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x57, 0x41, 0x49, 0x54, 0x49, 0x4e, 0x47, 0x10,
+	0x02, 0x12, 0x15, 0x0a, 0x11, 0x49, 0x54, 0x45, 0x4d, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53,
+	0x5f, 0x52, 0x45, 0x41, 0x44, 0x59, 0x10, 0x03, 0x12, 0x16, 0x0a, 0x12, 0x49, 0x54, 0x45, 0x4d,
+	0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x42, 0x4f, 0x55, 0x47, 0x48, 0x54, 0x10, 0x04,
+	0x12, 0x17, 0x0a, 0x13, 0x49, 0x54, 0x45, 0x4d, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f,
+	0x53, 0x4b, 0x49, 0x50, 0x50, 0x45, 0x44, 0x10, 0x05, 0x32, 0xb6, 0x02, 0x0a, 0x0b, 0x49, 0x74,
+	0x65, 0x6d, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x52, 0x0a, 0x09, 0x4c, 0x69, 0x73,
+	0x74, 0x49, 0x74, 0x65, 0x6d, 0x73, 0x12, 0x21, 0x2e, 0x69, 0x6d, 0x70, 0x75, 0x6c, 0x73, 0x65,
+	0x70, 0x61, 0x75, 0x73, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x49, 0x74, 0x65,
+	0x6d, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x69, 0x6d, 0x70, 0x75,
+	0x6c, 0x73, 0x65, 0x70, 0x61, 0x75, 0x73, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74,
+	0x49, 0x74, 0x65, 0x6d, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x41, 0x0a,
+	0x07, 0x47, 0x65, 0x74, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x1f, 0x2e, 0x69, 0x6d, 0x70, 0x75, 0x6c,
+	0x73, 0x65, 0x70, 0x61, 0x75, 0x73, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x49, 0x74,
+	0x65, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x69, 0x6d, 0x70, 0x75,
+	0x6c, 0x73, 0x65, 0x70, 0x61, 0x75, 0x73, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x74, 0x65, 0x6d,
+	0x12, 0x47, 0x0a, 0x0a, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x22,
+	0x2e, 0x69, 0x6d, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x70, 0x61, 0x75, 0x73, 0x65, 0x2e, 0x76, 0x31,
+	0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x15, 0x2e, 0x69, 0x6d, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x70, 0x61, 0x75, 0x73,
+	0x65, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x47, 0x0a, 0x0a, 0x44, 0x65, 0x63,
+	0x69, 0x64, 0x65, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x22, 0x2e, 0x69, 0x6d, 0x70, 0x75, 0x6c, 0x73,
+	0x65, 0x70, 0x61, 0x75, 0x73, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x63, 0x69, 0x64, 0x65,
+	0x49, 0x74, 0x65, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x69, 0x6d,
+	0x70, 0x75, 0x6c, 0x73, 0x65, 0x70, 0x61, 0x75, 0x73, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x74,
+	0x65, 0x6d, 0x32, 0xcd, 0x01, 0x0a, 0x0e, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x53, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x5b, 0x0a, 0x0c, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x72, 0x6f,
+	0x66, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x24, 0x2e, 0x69, 0x6d, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x70,
+	0x61, 0x75, 0x73, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x72, 0x6f, 0x66,
+	0x69, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x25, 0x2e, 0x69, 0x6d,
+	0x70, 0x75, 0x6c, 0x73, 0x65, 0x70, 0x61, 0x75, 0x73, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69,
+	0x73, 0x74, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x5e, 0x0a, 0x0d, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x50, 0x72, 0x6f, 0x66,
+	0x69, 0x6c, 0x65, 0x12, 0x25, 0x2e, 0x69, 0x6d, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x70, 0x61, 0x75,
+	0x73, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x50, 0x72, 0x6f, 0x66,
+	0x69, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x26, 0x2e, 0x69, 0x6d, 0x70,
+	0x75, 0x6c, 0x73, 0x65, 0x70, 0x61, 0x75, 0x73, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c,
+	0x65, 0x74, 0x65, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x42, 0x2f, 0x5a, 0x2d, 0x6d, 0x76, 0x70, 0x61, 0x70, 0x70, 0x2f, 0x69, 0x6e, 0x74,
+	0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x69, 0x6d, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x70, 0x61, 0x75,
+	0x73, 0x65, 0x76, 0x31, 0x3b, 0x69, 0x6d, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x70, 0x61, 0x75, 0x73,
+	0x65, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_proto_impulsepause_v1_impulsepause_proto_rawDescOnce sync.Once
+	file_proto_impulsepause_v1_impulsepause_proto_rawDescData = file_proto_impulsepause_v1_impulsepause_proto_rawDesc
+)
+
+func file_proto_impulsepause_v1_impulsepause_proto_rawDescGZIP() []byte {
+	file_proto_impulsepause_v1_impulsepause_proto_rawDescOnce.Do(func() {
+		file_proto_impulsepause_v1_impulsepause_proto_rawDescData = protoimpl.X.CompressGZIP(file_proto_impulsepause_v1_impulsepause_proto_rawDescData)
+	})
+	return file_proto_impulsepause_v1_impulsepause_proto_rawDescData
+}
+
+var file_proto_impulsepause_v1_impulsepause_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_proto_impulsepause_v1_impulsepause_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_proto_impulsepause_v1_impulsepause_proto_goTypes = []any{
+	(ItemStatus)(0),               // 0: impulsepause.v1.ItemStatus
+	(*Item)(nil),                  // 1: impulsepause.v1.Item
+	(*ListItemsRequest)(nil),      // 2: impulsepause.v1.ListItemsRequest
+	(*ListItemsResponse)(nil),     // 3: impulsepause.v1.ListItemsResponse
+	(*GetItemRequest)(nil),        // 4: impulsepause.v1.GetItemRequest
+	(*CreateItemRequest)(nil),     // 5: impulsepause.v1.CreateItemRequest
+	(*DecideItemRequest)(nil),     // 6: impulsepause.v1.DecideItemRequest
+	(*ListProfilesRequest)(nil),   // 7: impulsepause.v1.ListProfilesRequest
+	(*ListProfilesResponse)(nil),  // 8: impulsepause.v1.ListProfilesResponse
+	(*DeleteProfileRequest)(nil),  // 9: impulsepause.v1.DeleteProfileRequest
+	(*DeleteProfileResponse)(nil), // 10: impulsepause.v1.DeleteProfileResponse
+	(*timestamppb.Timestamp)(nil), // 11: google.protobuf.Timestamp
+}
+var file_proto_impulsepause_v1_impulsepause_proto_depIdxs = []int32{
+	0,  // 0: impulsepause.v1.Item.status:type_name -> impulsepause.v1.ItemStatus
+	11, // 1: impulsepause.v1.Item.purchase_allowed_at:type_name -> google.protobuf.Timestamp
+	11, // 2: impulsepause.v1.Item.created_at:type_name -> google.protobuf.Timestamp
+	11, // 3: impulsepause.v1.Item.decided_at:type_name -> google.protobuf.Timestamp
+	0,  // 4: impulsepause.v1.ListItemsRequest.statuses:type_name -> impulsepause.v1.ItemStatus
+	1,  // 5: impulsepause.v1.ListItemsResponse.items:type_name -> impulsepause.v1.Item
+	11, // 6: impulsepause.v1.CreateItemRequest.purchase_allowed_at:type_name -> google.protobuf.Timestamp
+	0,  // 7: impulsepause.v1.DecideItemRequest.decision:type_name -> impulsepause.v1.ItemStatus
+	2,  // 8: impulsepause.v1.ItemService.ListItems:input_type -> impulsepause.v1.ListItemsRequest
+	4,  // 9: impulsepause.v1.ItemService.GetItem:input_type -> impulsepause.v1.GetItemRequest
+	5,  // 10: impulsepause.v1.ItemService.CreateItem:input_type -> impulsepause.v1.CreateItemRequest
+	6,  // 11: impulsepause.v1.ItemService.DecideItem:input_type -> impulsepause.v1.DecideItemRequest
+	7,  // 12: impulsepause.v1.ProfileService.ListProfiles:input_type -> impulsepause.v1.ListProfilesRequest
+	9,  // 13: impulsepause.v1.ProfileService.DeleteProfile:input_type -> impulsepause.v1.DeleteProfileRequest
+	3,  // 14: impulsepause.v1.ItemService.ListItems:output_type -> impulsepause.v1.ListItemsResponse
+	1,  // 15: impulsepause.v1.ItemService.GetItem:output_type -> impulsepause.v1.Item
+	1,  // 16: impulsepause.v1.ItemService.CreateItem:output_type -> impulsepause.v1.Item
+	1,  // 17: impulsepause.v1.ItemService.DecideItem:output_type -> impulsepause.v1.Item
+	8,  // 18: impulsepause.v1.ProfileService.ListProfiles:output_type -> impulsepause.v1.ListProfilesResponse
+	10, // 19: impulsepause.v1.ProfileService.DeleteProfile:output_type -> impulsepause.v1.DeleteProfileResponse
+	14, // [14:20] is the sub-list for method output_type
+	8,  // [8:14] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_proto_impulsepause_v1_impulsepause_proto_init() }
+func file_proto_impulsepause_v1_impulsepause_proto_init() {
+	if File_proto_impulsepause_v1_impulsepause_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_proto_impulsepause_v1_impulsepause_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*Item); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_impulsepause_v1_impulsepause_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*ListItemsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_impulsepause_v1_impulsepause_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*ListItemsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_impulsepause_v1_impulsepause_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*GetItemRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_impulsepause_v1_impulsepause_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*CreateItemRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_impulsepause_v1_impulsepause_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*DecideItemRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_impulsepause_v1_impulsepause_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*ListProfilesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_impulsepause_v1_impulsepause_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*ListProfilesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_impulsepause_v1_impulsepause_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*DeleteProfileRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_impulsepause_v1_impulsepause_proto_msgTypes[9].Exporter = func(v any, i int) any {
+			switch v := v.(*DeleteProfileResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_proto_impulsepause_v1_impulsepause_proto_msgTypes[0].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_proto_impulsepause_v1_impulsepause_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   2,
+		},
+		GoTypes:           file_proto_impulsepause_v1_impulsepause_proto_goTypes,
+		DependencyIndexes: file_proto_impulsepause_v1_impulsepause_proto_depIdxs,
+		EnumInfos:         file_proto_impulsepause_v1_impulsepause_proto_enumTypes,
+		MessageInfos:      file_proto_impulsepause_v1_impulsepause_proto_msgTypes,
+	}.Build()
+	File_proto_impulsepause_v1_impulsepause_proto = out.File
+	file_proto_impulsepause_v1_impulsepause_proto_rawDesc = nil
+	file_proto_impulsepause_v1_impulsepause_proto_goTypes = nil
+	file_proto_impulsepause_v1_impulsepause_proto_depIdxs = nil
+}
@@ -0,0 +1,54 @@
+package web_test
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestPagesPassTheAccessibilityAudit(t *testing.T) {
+	now := time.Now()
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}, {Name: "Sam"}},
+		Items: []webtest.Item{
+			{Profile: "Alex", Title: "Headphones", Price: 12999, Tags: "Tech", PurchaseAllowedAt: now.Add(time.Hour)},
+			{Profile: "Alex", Title: "Bike", Tags: "Sports", PurchaseAllowedAt: now.Add(-time.Hour)},
+			{Profile: "Alex", Title: "Lamp", Status: "Researching"},
+			{Profile: "Alex", Title: "Watch", Status: "Skipped", Price: 25000, PurchaseAllowedAt: now, DecidedAt: now},
+			{Profile: "Alex", Title: "Pan", Status: "Bought", Price: 4000, PurchaseAllowedAt: now, DecidedAt: now},
+		},
+	})
+	h.App.SetAdminToken("s3cret")
+	alex := h.As("Alex")
+	alex.PostForm("/settings/share", url.Values{"action": {"generate"}})
+
+	pages := []string{
+		"/", "/?q=bike&status=Waiting", "/items/new", "/insights", "/about", "/switch-profile",
+		"/settings/profile", "/settings/tags", "/settings/data", "/settings/exports", "/settings/home-assistant",
+		"/settings/approvals", "/settings/blackouts", "/settings/rules", "/settings/templates", "/settings/wait-check?price=250&tags=Tech", "/settings/reconcile", "/household?token=s3cret",
+		"/items/" + strconv.Itoa(h.Item("Alex", "Headphones").ID) + "/edit",
+	}
+	audit := func(page, body string) {
+		if problems := webtest.AuditAccessibility(body); len(problems) > 0 {
+			t.Errorf("%s:\n  %s", page, strings.Join(problems, "\n  "))
+		}
+	}
+	for _, page := range pages {
+		audit(page, alex.Get(page).ExpectStatus(http.StatusOK).Body())
+	}
+
+	var token string
+	if err := h.DB.QueryRow(`SELECT share_token FROM profiles WHERE user_id = 'Alex'`).Scan(&token); err != nil || token == "" {
+		t.Fatalf("expected a share token, got %q: %v", token, err)
+	}
+	audit("/kiosk", h.Anonymous().Get("/kiosk?token="+token).ExpectStatus(http.StatusOK).Body())
+
+	newcomer := h.Anonymous()
+	newcomer.PostForm("/switch-profile", url.Values{"profile_name": {"Kim"}}).ExpectRedirect("/onboarding")
+	audit("/onboarding", newcomer.Get("/onboarding").ExpectStatus(http.StatusOK).Body())
+}
@@ -0,0 +1,37 @@
+package web
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+func (a *App) SetAdminToken(raw string) {
+	a.mu.Lock()
+	a.adminToken = strings.TrimSpace(raw)
+	a.mu.Unlock()
+}
+
+// requireAdmin checks the admin token from an "Authorization: Bearer" header or the token query parameter.
+// Admin pages are hidden entirely when no admin token is configured.
+func (a *App) requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	a.mu.RLock()
+	adminToken := a.adminToken
+	a.mu.RUnlock()
+
+	if adminToken == "" {
+		http.NotFound(w, r)
+		return false
+	}
+
+	provided := strings.TrimSpace(r.URL.Query().Get("token"))
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		provided = strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+	}
+	if subtle.ConstantTimeCompare([]byte(provided), []byte(adminToken)) != 1 {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+		http.Error(w, "invalid admin token", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,546 @@
+package web
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+// apiItem is the JSON representation of an item.
+type apiItem struct {
+	ID                int        `json:"id"`
+	Title             string     `json:"title"`
+	Price             string     `json:"price,omitempty"`
+	PriceCents        int64      `json:"price_cents,omitempty"`
+	Link              string     `json:"link,omitempty"`
+	Note              string     `json:"note,omitempty"`
+	Tags              []string   `json:"tags"`
+	Status            string     `json:"status"`
+	WaitPreset        string     `json:"wait_preset"`
+	PurchaseAllowedAt *time.Time `json:"purchase_allowed_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	DecidedAt         *time.Time `json:"decided_at,omitempty"`
+}
+
+// apiItemInput is the body of POST /api/v1/items. Omitted wait fields fall back to the tag and profile defaults.
+type apiItemInput struct {
+	Title           string   `json:"title"`
+	Price           string   `json:"price"`
+	Link            string   `json:"link"`
+	Note            string   `json:"note"`
+	Tags            []string `json:"tags"`
+	WaitPreset      string   `json:"wait_preset"`
+	WaitCustomHours string   `json:"wait_custom_hours"`
+	// PurchaseAllowedAt is an RFC 3339 timestamp. It implies the "date" wait preset when none is given.
+	PurchaseAllowedAt string `json:"purchase_allowed_at"`
+	// WaitText is a free-text wait such as "3 weeks" or "next Friday 18:00", read in the server's time zone.
+	// It implies the "text" wait preset.
+	WaitText    string `json:"wait_text"`
+	Researching bool   `json:"researching"`
+	// CreatedAt, DecidedAt and Decision backfill old purchases; dates are "2006-01-02" or RFC 3339.
+	CreatedAt string `json:"created_at"`
+	DecidedAt string `json:"decided_at"`
+	Decision  string `json:"decision"`
+}
+
+// idempotencyKeyTTL is how long a stored response is replayed for a repeated Idempotency-Key.
+const idempotencyKeyTTL = 24 * time.Hour
+
+// maxIdempotencyKeyLength bounds the Idempotency-Key header so keys stay cheap to store.
+const maxIdempotencyKeyLength = 255
+
+// maxAPIBodyBytes bounds the JSON body of API write requests.
+const maxAPIBodyBytes = 1 << 20
+
+// idempotentResponse is the stored outcome of a request sent with an Idempotency-Key.
+type idempotentResponse struct {
+	RequestHash string
+	Status      int
+	Body        string
+	CreatedAt   time.Time
+}
+
+// apiChanges is the body of GET /api/v1/changes. Full is set when Items is a complete snapshot, in which case
+// clients replace their copy instead of merging. Cursor is passed as since on the next request.
+type apiChanges struct {
+	Cursor  string    `json:"cursor"`
+	Full    bool      `json:"full"`
+	Items   []apiItem `json:"items"`
+	Deleted []int     `json:"deleted"`
+}
+
+// apiError is the body of every API error response, an RFC 7807 problem details object sent as
+// application/problem+json. Error repeats Detail under the name clients read before problem details,
+// and Fields is only set for 422 responses.
+type apiError struct {
+	Type   string              `json:"type"`
+	Title  string              `json:"title"`
+	Status int                 `json:"status"`
+	Detail string              `json:"detail"`
+	Error  string              `json:"error"`
+	Fields []domain.FieldError `json:"fields,omitempty"`
+}
+
+func newAPIItem(item Item) apiItem {
+	out := apiItem{
+		ID:         item.ID,
+		Title:      item.Title,
+		Price:      item.Price,
+		Link:       item.Link,
+		Note:       item.Note,
+		Tags:       splitTags(item.Tags),
+		Status:     item.Status.String(),
+		WaitPreset: item.WaitPreset,
+		CreatedAt:  item.CreatedAt,
+	}
+	if out.Tags == nil {
+		out.Tags = []string{}
+	}
+	if item.HasPriceValue {
+		out.PriceCents = int64(item.PriceCents)
+	}
+	if !item.PurchaseAllowedAt.IsZero() {
+		purchaseAllowedAt := item.PurchaseAllowedAt
+		out.PurchaseAllowedAt = &purchaseAllowedAt
+	}
+	if !item.DecidedAt.IsZero() {
+		decidedAt := item.DecidedAt
+		out.DecidedAt = &decidedAt
+	}
+	return out
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("encode json response: %v", err)
+	}
+}
+
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	writeProblem(w, newAPIError(status, message))
+}
+
+// writeProblem sends body as application/problem+json with its status.
+func writeProblem(w http.ResponseWriter, body apiError) {
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(body.Status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("encode problem response: %v", err)
+	}
+}
+
+func newAPIError(status int, message string) apiError {
+	return apiError{Type: "about:blank", Title: http.StatusText(status), Status: status, Detail: message, Error: message}
+}
+
+// validationErrorBody is the body of 422 responses, with the same per-field messages the HTML forms show.
+func validationErrorBody(invalid *domain.ValidationError) apiError {
+	body := newAPIError(http.StatusUnprocessableEntity, "validation failed")
+	body.Fields = invalid.Fields
+	return body
+}
+
+// writeDomainError answers an API request that failed with err, using the status of the error's kind.
+// Validation errors list their fields; unexpected errors are logged and answered with fallback.
+func writeDomainError(w http.ResponseWriter, err error, fallback string) {
+	var invalid *domain.ValidationError
+	switch status := statusForError(err); {
+	case errors.As(err, &invalid):
+		writeProblem(w, validationErrorBody(invalid))
+	case status == http.StatusInternalServerError:
+		log.Printf("api error: %s: %v", fallback, err)
+		writeAPIError(w, status, fallback)
+	default:
+		writeAPIError(w, status, err.Error())
+	}
+}
+
+// requireAPIProfile activates the profile from the active_profile cookie, like the dashboard does.
+func (a *App) requireAPIProfile(w http.ResponseWriter, r *http.Request) bool {
+	if err := a.activateProfileFromRequest(r); err != nil {
+		log.Printf("db error while activating profile for api: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "could not activate profile")
+		return false
+	}
+	if !a.hasActiveProfile() {
+		writeAPIError(w, http.StatusConflict, "no profile exists yet")
+		return false
+	}
+	return true
+}
+
+// apiItemList is the body of GET /api/v1/items. Items holds apiItem values, or maps with the requested
+// fields only. NextCursor is set when more items follow; it is passed as cursor for the next page.
+type apiItemList struct {
+	Items      []any  `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// apiListItems answers GET /api/v1/items with the dashboard's q, status, tag and sort parameters, a sparse
+// fieldset in fields and cursor pagination with limit and cursor.
+func (a *App) apiListItems(w http.ResponseWriter, r *http.Request) {
+	query, err := parseItemListQuery(r.URL.Query())
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !a.requireAPIProfile(w, r) {
+		return
+	}
+
+	a.mu.LockContext(r.Context())
+	a.promoteReadyItemsLocked(time.Now())
+	etag := ""
+	if a.db != nil {
+		cursor, err := a.itemChangeCursorLocked()
+		if err != nil {
+			a.mu.Unlock()
+			log.Printf("db error while listing items via api: %v", err)
+			writeAPIError(w, http.StatusInternalServerError, "could not load items")
+			return
+		}
+		// The change cursor moves with every item write, and the profile is part of the tag because the
+		// same cursor lists different items for different profiles.
+		profileHash := sha256.Sum256([]byte(a.currentUserIDLocked()))
+		etag = fmt.Sprintf(`"%d-%x"`, cursor, profileHash[:4])
+	}
+	page, nextCursor := query.apply(a.items)
+	a.mu.Unlock()
+
+	list := apiItemList{Items: make([]any, 0, len(page)), NextCursor: nextCursor}
+	for _, item := range page {
+		if len(query.Fields) == 0 {
+			list.Items = append(list.Items, newAPIItem(item))
+			continue
+		}
+		sparse, err := sparseAPIItem(newAPIItem(item), query.Fields)
+		if err != nil {
+			log.Printf("encode sparse api item %d: %v", item.ID, err)
+			writeAPIError(w, http.StatusInternalServerError, "could not load items")
+			return
+		}
+		list.Items = append(list.Items, sparse)
+	}
+
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// apiChanges returns the items that changed since the cursor in ?since=, so clients can sync incrementally.
+// Without a cursor, or when the cursor cannot be used, the response is a full snapshot.
+func (a *App) apiChanges(w http.ResponseWriter, r *http.Request) {
+	var since int64
+	if raw := strings.TrimSpace(r.URL.Query().Get("since")); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed < 0 {
+			writeAPIError(w, http.StatusBadRequest, "invalid since cursor")
+			return
+		}
+		since = parsed
+	}
+	if !a.requireAPIProfile(w, r) {
+		return
+	}
+
+	a.mu.LockContext(r.Context())
+	a.promoteReadyItemsLocked(time.Now())
+	changes, err := a.itemChangesLocked(since)
+	a.mu.Unlock()
+	if err != nil {
+		log.Printf("db error while listing item changes via api: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "could not load changes")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, changes)
+}
+
+func (a *App) itemChangesLocked(since int64) (apiChanges, error) {
+	changes := apiChanges{Items: []apiItem{}, Deleted: []int{}}
+	// Without the SQLite store there is no change log, so every response is a snapshot.
+	var cursor int64
+	if a.db != nil {
+		var err error
+		if cursor, err = a.itemChangeCursorLocked(); err != nil {
+			return apiChanges{}, err
+		}
+		changes.Cursor = strconv.FormatInt(cursor, 10)
+	}
+
+	// A cursor from the future, e.g. after restoring an older backup, cannot be trusted either.
+	if a.db == nil || since == 0 || since > cursor {
+		changes.Full = true
+		for _, item := range a.items {
+			changes.Items = append(changes.Items, newAPIItem(item))
+		}
+		return changes, nil
+	}
+
+	itemIDs, err := a.changedItemIDsLocked(since, cursor)
+	if err != nil {
+		return apiChanges{}, err
+	}
+	current := make(map[int]Item, len(a.items))
+	for _, item := range a.items {
+		current[item.ID] = item
+	}
+	for _, itemID := range itemIDs {
+		if item, ok := current[itemID]; ok {
+			changes.Items = append(changes.Items, newAPIItem(item))
+		} else {
+			changes.Deleted = append(changes.Deleted, itemID)
+		}
+	}
+	return changes, nil
+}
+
+func (a *App) apiCreateItem(w http.ResponseWriter, r *http.Request) {
+	if !a.requireAPIProfile(w, r) {
+		return
+	}
+
+	idempotencyKey := strings.TrimSpace(r.Header.Get("Idempotency-Key"))
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		writeAPIError(w, http.StatusBadRequest, "Idempotency-Key must be at most 255 characters")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxAPIBodyBytes+1))
+	if err != nil || len(body) > maxAPIBodyBytes {
+		writeAPIError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	var input apiItemInput
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+
+	draft := draftFromAPIInput(input)
+
+	// The lookup, the create and storing the response share one lock, so concurrent retries
+	// with the same key cannot both create the item.
+	a.mu.LockContext(r.Context())
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	hash := sha256.Sum256(body)
+	requestHash := hex.EncodeToString(hash[:])
+	if idempotencyKey != "" {
+		stored, ok, err := a.idempotentResponseLocked(idempotencyKey)
+		if err != nil {
+			log.Printf("db error while loading idempotency key: %v", err)
+			writeAPIError(w, http.StatusInternalServerError, "could not save item")
+			return
+		}
+		if ok && now.Sub(stored.CreatedAt) < idempotencyKeyTTL {
+			if stored.RequestHash != requestHash {
+				writeAPIError(w, http.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request body")
+				return
+			}
+			contentType := "application/json"
+			if stored.Status >= http.StatusBadRequest {
+				contentType = problemContentType
+			}
+			w.Header().Set("Content-Type", contentType)
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(stored.Status)
+			io.WriteString(w, stored.Body)
+			return
+		}
+	}
+
+	a.applyWaitDefaultsLocked(&draft.Item, draft.WaitPreset != "")
+	created, err := a.itemServiceLocked().Create(draft)
+
+	status := http.StatusCreated
+	var response any = newAPIItem(created)
+	var invalid *domain.ValidationError
+	if errors.As(err, &invalid) {
+		status = http.StatusUnprocessableEntity
+		response = validationErrorBody(invalid)
+	} else if errors.Is(err, domain.ErrItemQuotaReached) {
+		// Not stored either: the same key may succeed once items were deleted.
+		writeAPIError(w, http.StatusConflict, itemQuotaMessage(a.itemQuota))
+		return
+	} else if err != nil {
+		// Server errors are not stored, so the client can retry with the same key.
+		log.Printf("db error while creating item via api: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "could not save item")
+		return
+	}
+
+	if idempotencyKey != "" {
+		encoded, err := json.Marshal(response)
+		if err == nil {
+			err = a.saveIdempotentResponseLocked(idempotencyKey, idempotentResponse{RequestHash: requestHash, Status: status, Body: string(encoded) + "\n", CreatedAt: now})
+		}
+		if err != nil {
+			log.Printf("db error while saving idempotency key: %v", err)
+		}
+	}
+	if invalid != nil {
+		writeProblem(w, validationErrorBody(invalid))
+		return
+	}
+	writeJSON(w, status, response)
+}
+
+// draftFromAPIInput turns an API create request into a draft for the item service.
+func draftFromAPIInput(input apiItemInput) domain.Draft {
+	item := Item{
+		Title:           strings.TrimSpace(input.Title),
+		Price:           strings.TrimSpace(input.Price),
+		Link:            strings.TrimSpace(input.Link),
+		Note:            strings.TrimSpace(input.Note),
+		Tags:            parseTagsFromForm(input.Tags),
+		WaitPreset:      strings.TrimSpace(input.WaitPreset),
+		WaitCustomHours: strings.TrimSpace(input.WaitCustomHours),
+	}
+	if parsedPrice, ok := parsePrice(item.Price); ok {
+		item.PriceCents = parsedPrice
+		item.HasPriceValue = true
+	}
+
+	draft := domain.Draft{
+		Item:           item,
+		Researching:    input.Researching,
+		CreatedAtInput: input.CreatedAt,
+		DecidedAtInput: input.DecidedAt,
+		DecisionInput:  input.Decision,
+	}
+	if raw := strings.TrimSpace(input.WaitText); raw != "" {
+		draft.WaitPreset = domain.WaitPresetText
+		draft.PurchaseAllowedInput = raw
+	} else if raw := strings.TrimSpace(input.PurchaseAllowedAt); raw != "" {
+		if draft.WaitPreset == "" {
+			draft.WaitPreset = "date"
+		}
+		// An unparsable timestamp is passed on as is, so the service rejects it along with any other field.
+		draft.PurchaseAllowedInput = raw
+		if purchaseAllowedAt, err := time.Parse(time.RFC3339, raw); err == nil {
+			draft.PurchaseAllowedInput = purchaseAllowedAt.UTC().Format("2006-01-02T15:04")
+			draft.TimezoneOffsetMinutes = "0"
+		}
+	}
+	return draft
+}
+
+// apiDecisionInput is the body of POST /api/v1/items/{id}/decision.
+type apiDecisionInput struct {
+	// Status is "Bought" or "Skipped".
+	Status string `json:"status"`
+}
+
+// apiSnoozeInput is the body of POST /api/v1/items/{id}/snooze. Preset defaults to "24h", the only snooze
+// the dashboard offers.
+type apiSnoozeInput struct {
+	Preset string `json:"preset"`
+}
+
+// decodeAPIBody decodes a small JSON request body into v, rejecting unknown fields.
+func decodeAPIBody(r *http.Request, v any) error {
+	decoder := json.NewDecoder(io.LimitReader(r.Body, maxAPIBodyBytes))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+// apiItemID reads the {id} path parameter of item routes.
+func apiItemID(r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	return id, err == nil && id > 0
+}
+
+// apiDecideItem marks a ready item as bought or skipped, like the dashboard's buttons.
+func (a *App) apiDecideItem(w http.ResponseWriter, r *http.Request) {
+	id, ok := apiItemID(r)
+	if !ok {
+		writeAPIError(w, http.StatusBadRequest, "invalid item id")
+		return
+	}
+	var input apiDecisionInput
+	if err := decodeAPIBody(r, &input); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	status, err := domain.ParseStatus(input.Status)
+	if err != nil || !status.Decided() {
+		writeAPIError(w, http.StatusBadRequest, `status must be "Bought" or "Skipped"`)
+		return
+	}
+	if !a.requireAPIProfile(w, r) {
+		return
+	}
+
+	a.mu.LockContext(r.Context())
+	a.promoteReadyItemsLocked(time.Now())
+	decided, err := a.itemServiceLocked().Decide(id, status)
+	a.mu.Unlock()
+
+	if err != nil {
+		writeDomainError(w, err, "could not update item status")
+		return
+	}
+	writeJSON(w, http.StatusOK, newAPIItem(decided))
+}
+
+// apiSnoozeItem puts a ready item back to waiting, like the dashboard's snooze button.
+func (a *App) apiSnoozeItem(w http.ResponseWriter, r *http.Request) {
+	id, ok := apiItemID(r)
+	if !ok {
+		writeAPIError(w, http.StatusBadRequest, "invalid item id")
+		return
+	}
+	var input apiSnoozeInput
+	if err := decodeAPIBody(r, &input); err != nil && !errors.Is(err, io.EOF) {
+		writeAPIError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	preset := strings.TrimSpace(input.Preset)
+	if preset == "" {
+		preset = "24h"
+	}
+	if preset != "24h" {
+		writeAPIError(w, http.StatusBadRequest, `preset must be "24h"`)
+		return
+	}
+	if !a.requireAPIProfile(w, r) {
+		return
+	}
+
+	a.mu.LockContext(r.Context())
+	a.promoteReadyItemsLocked(time.Now())
+	snoozed, err := a.itemServiceLocked().Snooze(id, preset)
+	a.mu.Unlock()
+
+	switch {
+	case errors.Is(err, domain.ErrTransitionNotAllowed):
+		writeAPIError(w, http.StatusConflict, "snooze is only allowed for ready items")
+	case err != nil:
+		writeDomainError(w, err, "could not snooze item")
+	default:
+		writeJSON(w, http.StatusOK, newAPIItem(snoozed))
+	}
+}
@@ -0,0 +1,180 @@
+package web
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+// maxAPIPageSize bounds the limit parameter of GET /api/v1/items.
+const maxAPIPageSize = 500
+
+// apiItemFields are the names accepted by the fields parameter, matching the JSON keys of apiItem.
+var apiItemFields = []string{"id", "title", "price", "price_cents", "link", "note", "tags", "status", "wait_preset", "purchase_allowed_at", "created_at", "decided_at"}
+
+// apiSortOptions are the sort orders of the dashboard, accepted by the sort parameter. The dashboard's
+// "unlocking_soon" is left out: it changes with the clock, so a cursor could skip or repeat items.
+var apiSortOptions = []string{"next_ready", "newest", "oldest", "price_asc", "price_desc"}
+
+// itemListQuery is GET /api/v1/items filtered, sorted and paged like the dashboard.
+type itemListQuery struct {
+	Search   string
+	Statuses []domain.Status
+	Tag      string
+	Sort     string
+	// Fields is the sparse fieldset; empty returns every field.
+	Fields []string
+	// Limit is the page size; 0 returns every remaining item.
+	Limit int
+	After *itemListCursor
+}
+
+// itemListCursor holds the sort keys of the last item on a page. The next page starts after it in the
+// same order, so items added, changed or removed meanwhile do not shift or repeat the following pages.
+type itemListCursor struct {
+	Sort              string        `json:"s"`
+	ID                int           `json:"i"`
+	Status            domain.Status `json:"st"`
+	PurchaseAllowedAt time.Time     `json:"a"`
+	CreatedAt         time.Time     `json:"c"`
+	PriceCents        domain.Money  `json:"p"`
+	HasPriceValue     bool          `json:"hp"`
+}
+
+func newItemListCursor(sortBy string, item Item) itemListCursor {
+	return itemListCursor{
+		Sort:              sortBy,
+		ID:                item.ID,
+		Status:            item.Status,
+		PurchaseAllowedAt: item.PurchaseAllowedAt,
+		CreatedAt:         item.CreatedAt,
+		PriceCents:        item.PriceCents,
+		HasPriceValue:     item.HasPriceValue,
+	}
+}
+
+// item returns a stand-in with the cursor's sort keys, to compare against with compareItems.
+func (c itemListCursor) item() Item {
+	return Item{ID: c.ID, Status: c.Status, PurchaseAllowedAt: c.PurchaseAllowedAt, CreatedAt: c.CreatedAt, PriceCents: c.PriceCents, HasPriceValue: c.HasPriceValue}
+}
+
+func (c itemListCursor) encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeItemListCursor(raw string) (*itemListCursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	var cursor itemListCursor
+	if err := json.Unmarshal(decoded, &cursor); err != nil || cursor.ID <= 0 {
+		return nil, errors.New("invalid cursor")
+	}
+	return &cursor, nil
+}
+
+// parseItemListQuery reads q, status, tag and sort as the dashboard does, plus fields, limit and cursor.
+// Unlike the dashboard it rejects unknown values, and without a status it lists items of every status.
+func parseItemListQuery(values url.Values) (itemListQuery, error) {
+	query := itemListQuery{Search: values.Get("q"), Tag: values.Get("tag"), Sort: "newest"}
+
+	for _, raw := range values["status"] {
+		for _, part := range strings.Split(raw, ",") {
+			status, err := domain.ParseStatus(part)
+			if err != nil {
+				return query, fmt.Errorf("unknown status %q", strings.TrimSpace(part))
+			}
+			if !slices.Contains(query.Statuses, status) {
+				query.Statuses = append(query.Statuses, status)
+			}
+		}
+	}
+
+	if raw := strings.TrimSpace(values.Get("sort")); raw != "" {
+		if !slices.Contains(apiSortOptions, raw) {
+			return query, fmt.Errorf("unknown sort %q", raw)
+		}
+		query.Sort = raw
+	}
+
+	if raw := strings.TrimSpace(values.Get("fields")); raw != "" {
+		for _, field := range strings.Split(raw, ",") {
+			field = strings.TrimSpace(field)
+			if !slices.Contains(apiItemFields, field) {
+				return query, fmt.Errorf("unknown field %q", field)
+			}
+			if !slices.Contains(query.Fields, field) {
+				query.Fields = append(query.Fields, field)
+			}
+		}
+	}
+
+	if raw := strings.TrimSpace(values.Get("limit")); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxAPIPageSize {
+			return query, fmt.Errorf("limit must be between 1 and %d", maxAPIPageSize)
+		}
+		query.Limit = limit
+	}
+
+	if raw := strings.TrimSpace(values.Get("cursor")); raw != "" {
+		cursor, err := decodeItemListCursor(raw)
+		if err != nil {
+			return query, err
+		}
+		if cursor.Sort != query.Sort {
+			return query, errors.New("the cursor belongs to another sort order")
+		}
+		query.After = cursor
+	}
+	return query, nil
+}
+
+// apply filters, sorts and pages the items. It returns the page and the cursor of the next one, or "".
+func (q itemListQuery) apply(items []Item) ([]Item, string) {
+	listed := filterAndSortItems(items, q.Search, q.Statuses, q.Tag, q.Sort)
+	if q.After != nil {
+		compare, after := compareItems(q.Sort), q.After.item()
+		start, _ := slices.BinarySearchFunc(listed, after, func(item, target Item) int {
+			if compare(item, target) <= 0 {
+				return -1
+			}
+			return 1
+		})
+		listed = listed[start:]
+	}
+	if q.Limit == 0 || len(listed) <= q.Limit {
+		return listed, ""
+	}
+	page := listed[:q.Limit]
+	return page, newItemListCursor(q.Sort, page[len(page)-1]).encode()
+}
+
+// sparseAPIItem keeps only the requested fields of the item; the ID is always included.
+func sparseAPIItem(item apiItem, fields []string) (map[string]json.RawMessage, error) {
+	raw, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &all); err != nil {
+		return nil, err
+	}
+	out := map[string]json.RawMessage{"id": all["id"]}
+	for _, field := range fields {
+		if value, ok := all[field]; ok {
+			out[field] = value
+		}
+	}
+	return out, nil
+}
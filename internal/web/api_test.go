@@ -0,0 +1,381 @@
+package web_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"mvpapp/internal/web/webtest"
+)
+
+type apiErrorBody struct {
+	Error  string `json:"error"`
+	Fields []struct {
+		Field   string `json:"field"`
+		Message string `json:"message"`
+	} `json:"fields"`
+}
+
+func TestAPICreateItemStoresItemAndListsIt(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{Profiles: []webtest.Profile{{Name: "Alex"}}})
+	alex := h.As("Alex")
+
+	alex.PostJSON("/api/v1/items", map[string]any{"title": "Desk lamp", "price": "39.90", "tags": []string{"Home"}, "wait_preset": "7d"}).
+		ExpectStatus(http.StatusCreated).
+		ExpectContains(`"title":"Desk lamp"`, `"price_cents":3990`, `"status":"Waiting"`)
+
+	if got := h.Item("Alex", "Desk lamp"); got.Price != "39.90" || got.Tags != "Home" {
+		t.Fatalf("unexpected stored item %+v", got)
+	}
+	alex.Get("/api/v1/items").ExpectStatus(http.StatusOK).ExpectContains(`"tags":["Home"]`, `"wait_preset":"7d"`)
+}
+
+func TestAPICreateItemReportsEveryInvalidField(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{Profiles: []webtest.Profile{{Name: "Alex"}}})
+
+	res := h.As("Alex").PostJSON("/api/v1/items", map[string]any{"title": " ", "wait_preset": "custom", "wait_custom_hours": "-1"}).
+		ExpectStatus(http.StatusUnprocessableEntity)
+
+	var body apiErrorBody
+	if err := json.Unmarshal([]byte(res.Body()), &body); err != nil {
+		t.Fatalf("decode error body: %v", err)
+	}
+	fields := map[string]string{}
+	for _, f := range body.Fields {
+		fields[f.Field] = f.Message
+	}
+	if fields["title"] != "Please enter a title." || fields["wait_custom_hours"] == "" {
+		t.Fatalf("expected title and custom hours errors, got %+v", body)
+	}
+	if items := h.Items("Alex"); len(items) != 0 {
+		t.Fatalf("expected no item to be stored, got %+v", items)
+	}
+}
+
+func TestAPICreateItemRejectsMalformedJSON(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{Profiles: []webtest.Profile{{Name: "Alex"}}})
+
+	h.As("Alex").PostJSON("/api/v1/items", map[string]any{"name": "Desk lamp"}).
+		ExpectStatus(http.StatusBadRequest).
+		ExpectContains(`"error":"invalid JSON body"`)
+}
+
+func TestAPICreateItemReplaysIdempotentRequests(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{Profiles: []webtest.Profile{{Name: "Alex"}, {Name: "Sam"}}})
+	alex := h.As("Alex").WithHeader("Idempotency-Key", "lamp-1")
+	body := map[string]any{"title": "Desk lamp", "price": "39.90", "wait_preset": "7d"}
+
+	first := alex.PostJSON("/api/v1/items", body).ExpectStatus(http.StatusCreated)
+	replay := alex.PostJSON("/api/v1/items", body).ExpectStatus(http.StatusCreated)
+	if replay.Body() != first.Body() || replay.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("expected the original response to be replayed, got %q (first %q)", replay.Body(), first.Body())
+	}
+	if items := h.Items("Alex"); len(items) != 1 {
+		t.Fatalf("expected exactly one item, got %+v", items)
+	}
+
+	alex.PostJSON("/api/v1/items", map[string]any{"title": "Floor lamp", "wait_preset": "7d"}).
+		ExpectStatus(http.StatusUnprocessableEntity).
+		ExpectContains("different request body")
+
+	// Keys are scoped to the profile.
+	h.As("Sam").WithHeader("Idempotency-Key", "lamp-1").PostJSON("/api/v1/items", body).ExpectStatus(http.StatusCreated)
+	if items := h.Items("Sam"); len(items) != 1 {
+		t.Fatalf("expected Sam's request to create an item, got %+v", items)
+	}
+}
+
+func TestAPICreateItemReplaysIdempotentValidationErrors(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{Profiles: []webtest.Profile{{Name: "Alex"}}})
+	alex := h.As("Alex").WithHeader("Idempotency-Key", "empty-title")
+
+	alex.PostJSON("/api/v1/items", map[string]any{"title": " "}).ExpectStatus(http.StatusUnprocessableEntity)
+	res := alex.PostJSON("/api/v1/items", map[string]any{"title": " "}).
+		ExpectStatus(http.StatusUnprocessableEntity).
+		ExpectContains("Please enter a title.")
+	if res.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("expected the validation error to be replayed")
+	}
+}
+
+type apiChangesBody struct {
+	Cursor string `json:"cursor"`
+	Full   bool   `json:"full"`
+	Items  []struct {
+		ID    int    `json:"id"`
+		Title string `json:"title"`
+	} `json:"items"`
+	Deleted []int `json:"deleted"`
+}
+
+func getChanges(t *testing.T, client *webtest.Client, since string) apiChangesBody {
+	t.Helper()
+	res := client.Get("/api/v1/changes?since=" + url.QueryEscape(since)).ExpectStatus(http.StatusOK)
+	var body apiChangesBody
+	if err := json.Unmarshal([]byte(res.Body()), &body); err != nil {
+		t.Fatalf("decode changes: %v", err)
+	}
+	return body
+}
+
+func TestAPIChangesReturnsItemChangesSinceCursor(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}, {Name: "Sam"}},
+		Items:    []webtest.Item{{Profile: "Alex", Title: "Desk lamp"}, {Profile: "Alex", Title: "Headphones"}},
+	})
+	alex := h.As("Alex")
+
+	snapshot := getChanges(t, alex, "")
+	if !snapshot.Full || len(snapshot.Items) != 2 || snapshot.Cursor == "" {
+		t.Fatalf("expected a full snapshot with a cursor, got %+v", snapshot)
+	}
+	if unchanged := getChanges(t, alex, snapshot.Cursor); unchanged.Full || len(unchanged.Items) != 0 || len(unchanged.Deleted) != 0 {
+		t.Fatalf("expected no changes, got %+v", unchanged)
+	}
+
+	lamp := h.Item("Alex", "Desk lamp")
+	alex.PostForm("/items/delete", url.Values{"item_id": {strconv.Itoa(lamp.ID)}})
+	alex.PostJSON("/api/v1/items", map[string]any{"title": "Keyboard", "wait_preset": "7d"}).ExpectStatus(http.StatusCreated)
+	// Another profile's changes are not reported.
+	h.As("Sam").PostJSON("/api/v1/items", map[string]any{"title": "Bike", "wait_preset": "7d"}).ExpectStatus(http.StatusCreated)
+
+	delta := getChanges(t, alex, snapshot.Cursor)
+	if delta.Full || len(delta.Items) != 1 || delta.Items[0].Title != "Keyboard" || len(delta.Deleted) != 1 || delta.Deleted[0] != lamp.ID {
+		t.Fatalf("expected the new item and the deleted id, got %+v", delta)
+	}
+	if delta.Cursor == snapshot.Cursor {
+		t.Fatalf("expected the cursor to move")
+	}
+}
+
+func TestAPIChangesReportsItemsWhoseShareWasRemoved(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}, {Name: "Sam"}},
+		Items:    []webtest.Item{{Profile: "Alex", Title: "Tent"}},
+	})
+	tent := h.Item("Alex", "Tent")
+	share := func(action string) {
+		h.As("Alex").PostForm("/items/share", url.Values{"item_id": {strconv.Itoa(tent.ID)}, "action": {action}, "profile_name": {"Sam"}})
+	}
+
+	share("add")
+	sam := h.As("Sam")
+	cursor := getChanges(t, sam, "").Cursor
+	share("remove")
+
+	if delta := getChanges(t, sam, cursor); len(delta.Deleted) != 1 || delta.Deleted[0] != tent.ID {
+		t.Fatalf("expected the unshared item to be reported as deleted, got %+v", delta)
+	}
+}
+
+func TestAPIChangesRejectsInvalidCursor(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{Profiles: []webtest.Profile{{Name: "Alex"}}})
+
+	h.As("Alex").Get("/api/v1/changes?since=abc").ExpectStatus(http.StatusBadRequest).ExpectContains("invalid since cursor")
+}
+
+func TestAPIListItemsSupportsConditionalRequests(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{Profiles: []webtest.Profile{{Name: "Alex"}}})
+	alex := h.As("Alex")
+
+	etag := alex.Get("/api/v1/items").ExpectStatus(http.StatusOK).Header().Get("ETag")
+	if etag == "" {
+		t.Fatalf("expected an ETag")
+	}
+	alex.WithHeader("If-None-Match", etag).Get("/api/v1/items").ExpectStatus(http.StatusNotModified)
+
+	alex.PostJSON("/api/v1/items", map[string]any{"title": "Desk lamp", "wait_preset": "7d"}).ExpectStatus(http.StatusCreated)
+	alex.WithHeader("If-None-Match", etag).Get("/api/v1/items").ExpectStatus(http.StatusOK).ExpectContains("Desk lamp")
+}
+
+func TestFormsShowMessagesNextToRejectedFields(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{Profiles: []webtest.Profile{{Name: "Alex"}}})
+	alex := h.As("Alex")
+
+	alex.PostForm("/items/new", url.Values{"title": {""}, "wait_preset": {"custom"}, "wait_custom_hours": {"0"}}).
+		ExpectStatus(http.StatusBadRequest).
+		ExpectContains(
+			`<div id="title-error" class="invalid-feedback">Please enter a title.</div>`,
+			`<div id="wait_custom_hours-error" class="invalid-feedback">Please enter a valid number of custom hours (&gt; 0).</div>`,
+			`aria-describedby="title-error"`,
+		)
+
+	alex.PostForm("/settings/profile", url.Values{"profile_name": {"Alex"}, "hourly_wage": {"-3"}, "currency": {"XYZ"}, "ntfy_topic": {"alex"}}).
+		ExpectStatus(http.StatusBadRequest).
+		ExpectContains(
+			`<div id="hourly_wage-error" class="invalid-feedback">`,
+			`<div id="currency-error" class="invalid-feedback">Please choose a supported currency.</div>`,
+			`<div id="ntfy_endpoint-error" class="invalid-feedback">`,
+		).
+		ExpectNotContains(`id="profile_name-error"`)
+}
+
+type apiItemPage struct {
+	Items      []map[string]any `json:"items"`
+	NextCursor string           `json:"next_cursor"`
+}
+
+func getItemPage(t *testing.T, c *webtest.Client, query string) apiItemPage {
+	t.Helper()
+	var page apiItemPage
+	if err := json.Unmarshal([]byte(c.Get("/api/v1/items?"+query).ExpectStatus(http.StatusOK).Body()), &page); err != nil {
+		t.Fatalf("decode item page: %v", err)
+	}
+	return page
+}
+
+func TestAPIListItemsFiltersSortsAndPages(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}},
+		Items: []webtest.Item{
+			{Profile: "Alex", Title: "Desk lamp", Price: 4000, Tags: "Home"},
+			{Profile: "Alex", Title: "Sofa", Price: 90000, Tags: "Home"},
+			{Profile: "Alex", Title: "Rug", Price: 12000, Tags: "Home"},
+			{Profile: "Alex", Title: "Headphones", Price: 20000, Tags: "Tech"},
+			{Profile: "Alex", Title: "Old chair", Price: 6000, Tags: "Home", Status: "Skipped"},
+		},
+	})
+	alex := h.As("Alex")
+
+	var titles []any
+	cursor := ""
+	for pages := 0; ; pages++ {
+		page := getItemPage(t, alex, url.Values{"tag": {"home"}, "status": {"Waiting,Ready to buy"}, "sort": {"price_asc"}, "fields": {"title,price_cents"}, "limit": {"2"}, "cursor": {cursor}}.Encode())
+		for _, item := range page.Items {
+			if _, ok := item["status"]; ok || item["id"] == nil {
+				t.Fatalf("expected only the id and the requested fields, got %v", item)
+			}
+			titles = append(titles, item["title"])
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		if pages == 0 {
+			// An item added between pages does not shift the next page.
+			alex.PostJSON("/api/v1/items", map[string]any{"title": "Vase", "price": "10", "tags": []string{"Home"}}).ExpectStatus(http.StatusCreated)
+		}
+		cursor = page.NextCursor
+	}
+	if got := fmt.Sprint(titles); got != "[Desk lamp Rug Sofa]" {
+		t.Fatalf("unexpected titles across pages: %s", got)
+	}
+
+	if page := getItemPage(t, alex, "q=chair"); len(page.Items) != 1 || page.Items[0]["status"] != "Skipped" {
+		t.Fatalf("expected items of every status without a status filter, got %+v", page.Items)
+	}
+
+	alex.Get("/api/v1/items?sort=cheapest").ExpectStatus(http.StatusBadRequest).ExpectContains(`unknown sort`)
+	alex.Get("/api/v1/items?fields=title,secret").ExpectStatus(http.StatusBadRequest).ExpectContains(`unknown field`)
+	alex.Get("/api/v1/items?limit=0").ExpectStatus(http.StatusBadRequest)
+	alex.Get("/api/v1/items?cursor=nope").ExpectStatus(http.StatusBadRequest).ExpectContains("invalid cursor")
+}
+
+func TestAPIDecidesAndSnoozesReadyItems(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}},
+		Items: []webtest.Item{
+			{Profile: "Alex", Title: "Lamp", Status: "Ready to buy", PurchaseAllowedAt: time.Now().Add(-time.Hour)},
+			{Profile: "Alex", Title: "Tent", Status: "Ready to buy", PurchaseAllowedAt: time.Now().Add(-time.Hour)},
+			{Profile: "Alex", Title: "Desk", Status: "Waiting", PurchaseAllowedAt: time.Now().Add(24 * time.Hour)},
+		},
+	})
+	alex := h.As("Alex")
+	path := func(title, action string) string {
+		return fmt.Sprintf("/api/v1/items/%d/%s", h.Item("Alex", title).ID, action)
+	}
+
+	alex.PostJSON(path("Lamp", "decision"), map[string]any{"status": "Bought"}).
+		ExpectStatus(http.StatusOK).
+		ExpectContains(`"title":"Lamp"`, `"status":"Bought"`)
+	alex.PostJSON(path("Tent", "snooze"), map[string]any{}).
+		ExpectStatus(http.StatusOK).
+		ExpectContains(`"status":"Waiting"`)
+	if got := h.Item("Alex", "Tent"); got.Status != "Waiting" {
+		t.Fatalf("expected the tent to wait again, got %+v", got)
+	}
+
+	alex.PostJSON(path("Desk", "decision"), map[string]any{"status": "Skipped"}).ExpectStatus(http.StatusConflict)
+	alex.PostJSON(path("Desk", "decision"), map[string]any{"status": "Waiting"}).ExpectStatus(http.StatusBadRequest)
+	alex.PostJSON("/api/v1/items/999/snooze", map[string]any{}).ExpectStatus(http.StatusNotFound)
+}
+
+func TestAPIErrorsAreProblemDetails(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{Profiles: []webtest.Profile{{Name: "Alex"}}})
+	alex := h.As("Alex")
+
+	res := alex.PostJSON("/api/v1/items/42/decision", map[string]any{"status": "Bought"}).ExpectStatus(http.StatusNotFound)
+	if got := res.Header().Get("Content-Type"); got != "application/problem+json" {
+		t.Fatalf("expected problem+json, got %q", got)
+	}
+	var problem struct {
+		Type   string `json:"type"`
+		Title  string `json:"title"`
+		Status int    `json:"status"`
+		Detail string `json:"detail"`
+		Error  string `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(res.Body()), &problem); err != nil {
+		t.Fatalf("decode problem: %v", err)
+	}
+	if problem.Type != "about:blank" || problem.Title != "Not Found" || problem.Status != http.StatusNotFound || problem.Detail != "item not found" || problem.Error != problem.Detail {
+		t.Fatalf("unexpected problem %+v", problem)
+	}
+
+	res = alex.PostJSON("/api/v1/items", map[string]any{"title": " "}).ExpectStatus(http.StatusUnprocessableEntity).
+		ExpectContains(`"status":422`, `"field":"title"`)
+	if got := res.Header().Get("Content-Type"); got != "application/problem+json" {
+		t.Fatalf("expected validation errors as problem+json, got %q", got)
+	}
+}
+
+func TestAPIListTagsReturnsStatisticsPerTag(t *testing.T) {
+	now := time.Now()
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}},
+		Items: []webtest.Item{
+			{Profile: "Alex", Title: "Headphones", Price: 10000, Tags: "Audio", Status: "Bought", DecidedAt: now},
+			{Profile: "Alex", Title: "Speaker", Price: 6000, Tags: "audio, Gifts", Status: "Skipped", DecidedAt: now},
+			{Profile: "Alex", Title: "Earbuds", Price: 4000, Tags: "Audio", Status: "Skipped", DecidedAt: now},
+			{Profile: "Alex", Title: "Turntable", Tags: "audio", PurchaseAllowedAt: now.Add(-time.Hour)},
+			{Profile: "Alex", Title: "Vinyl", Tags: "Gifts", PurchaseAllowedAt: now.Add(time.Hour)},
+		},
+	})
+
+	var body struct {
+		Currency string `json:"currency"`
+		Tags     []struct {
+			Tag        string         `json:"tag"`
+			ItemCount  int            `json:"item_count"`
+			Counts     map[string]int `json:"counts"`
+			SpentCents int64          `json:"spent_cents"`
+			SavedCents int64          `json:"saved_cents"`
+			SkipRatio  *float64       `json:"skip_ratio"`
+		} `json:"tags"`
+	}
+	res := h.As("Alex").Get("/api/v1/tags").ExpectStatus(http.StatusOK)
+	if err := json.Unmarshal([]byte(res.Body()), &body); err != nil {
+		t.Fatalf("decode tags: %v", err)
+	}
+	if len(body.Tags) < 2 || body.Currency == "" {
+		t.Fatalf("expected the used tags with the profile currency, got %+v", body)
+	}
+	audio, gifts := body.Tags[0], body.Tags[1]
+	if audio.Tag != "Audio" || audio.ItemCount != 4 || audio.Counts["bought"] != 1 || audio.Counts["skipped"] != 2 || audio.Counts["ready"] != 1 {
+		t.Fatalf("unexpected audio stats %+v", audio)
+	}
+	if audio.SpentCents != 10000 || audio.SavedCents != 10000 || audio.SkipRatio == nil || *audio.SkipRatio < 0.66 || *audio.SkipRatio > 0.67 {
+		t.Fatalf("unexpected audio amounts %+v", audio)
+	}
+	if gifts.Tag != "Gifts" || gifts.ItemCount != 2 || gifts.Counts["waiting"] != 1 || gifts.SavedCents != 6000 {
+		t.Fatalf("unexpected gifts stats %+v", gifts)
+	}
+	for _, tag := range body.Tags[2:] {
+		if tag.ItemCount != 0 || tag.SkipRatio != nil {
+			t.Fatalf("expected unused catalog tags without decisions, got %+v", tag)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"mvpapp/internal/domain"
+)
+
+const (
+	approvalRequested = "requested"
+	approvalApproved  = "approved"
+	approvalDenied    = "denied"
+)
+
+type approvalSettingsViewData struct {
+	Title            string
+	CurrentPath      string
+	ContentTemplate  string
+	ScriptTemplate   string
+	Threshold        string
+	Approver         string
+	ApproverOptions  []string
+	PendingApprovals []Item
+	Currency         string
+	Error            string
+	Feedback         string
+	ActiveProfile    string
+}
+
+// requiresApproval reports whether buying the item needs a second profile's approval under the given rule.
+func requiresApproval(item Item, threshold domain.Money, approver string) bool {
+	return approver != "" && threshold > 0 && item.HasPriceValue && item.PriceCents > threshold
+}
+
+func (a *App) itemRequiresApprovalLocked(item Item) (bool, error) {
+	threshold, approver, err := a.approvalRuleForProfileLocked(item.OwnerID)
+	if err != nil {
+		return false, err
+	}
+	return requiresApproval(item, threshold, approver), nil
+}
+
+func (a *App) purchaseBlockedByApprovalLocked(item Item) (bool, error) {
+	required, err := a.itemRequiresApprovalLocked(item)
+	if err != nil {
+		return false, err
+	}
+	return required && item.ApprovalState != approvalApproved, nil
+}
+
+// approvalNeedsLocked maps item IDs to whether they fall under their owner's approval rule.
+func (a *App) approvalNeedsLocked(items []Item) map[int]bool {
+	needs := make(map[int]bool, len(items))
+	for _, item := range items {
+		required, err := a.itemRequiresApprovalLocked(item)
+		if err != nil {
+			log.Printf("db error while loading approval rule: %v", err)
+			continue
+		}
+		needs[item.ID] = required
+	}
+	return needs
+}
+
+func parseApprovalThreshold(raw string, format domain.NumberFormat) (domain.Money, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, nil
+	}
+	threshold, err := domain.ParsePrice(raw, format)
+	if err != nil {
+		return 0, errors.New("Please enter a valid approval threshold.")
+	}
+	return threshold, nil
+}
+
+func (a *App) itemApproval(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	id, err := strconv.Atoi(strings.TrimSpace(r.FormValue("item_id")))
+	if err != nil || id <= 0 {
+		http.Error(w, "invalid item id", http.StatusBadRequest)
+		return
+	}
+
+	switch action := strings.TrimSpace(r.FormValue("action")); action {
+	case "request":
+		a.requestApproval(w, r, id)
+	case "approve", "deny":
+		a.resolveApproval(w, r, id, action)
+	default:
+		http.Error(w, "invalid action", http.StatusBadRequest)
+	}
+}
+
+func (a *App) requestApproval(w http.ResponseWriter, r *http.Request, id int) {
+	a.mu.LockContext(r.Context())
+	defer a.mu.Unlock()
+
+	i := a.itemIndexLocked(id)
+	if i < 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	item := a.items[i]
+	threshold, approver, err := a.approvalRuleForProfileLocked(item.OwnerID)
+	if err != nil {
+		log.Printf("db error while loading approval rule: %v", err)
+		http.Error(w, "could not request approval", http.StatusInternalServerError)
+		return
+	}
+	if !requiresApproval(item, threshold, approver) {
+		http.Error(w, "item does not need approval", http.StatusConflict)
+		return
+	}
+	if item.ApprovalState != "" {
+		http.Error(w, "approval already "+item.ApprovalState, http.StatusConflict)
+		return
+	}
+
+	requested := item
+	requested.ApprovalState = approvalRequested
+	if a.db != nil {
+		// The approver is notified through the outbox, so a slow ntfy server does not hold up the request.
+		err = a.saveItemWithEventLocked(requested, domain.EventApprovalRequested, a.updateItemWithLocked)
+	} else {
+		a.items[i] = requested
+		err = a.updateItemLocked(requested)
+	}
+	if err != nil {
+		a.items[i] = item
+		log.Printf("db error while requesting approval: %v", err)
+		http.Error(w, "could not request approval", http.StatusInternalServerError)
+		return
+	}
+	a.recordHistoryLocked(id, "approval requested", approver)
+	a.deliverItemEffectsLocked(domain.Event{Type: domain.EventApprovalRequested, Profile: a.currentUserIDLocked(), Item: requested})
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func (a *App) resolveApproval(w http.ResponseWriter, r *http.Request, id int, action string) {
+	state := approvalApproved
+	if action == "deny" {
+		state = approvalDenied
+	}
+
+	a.mu.LockContext(r.Context())
+	defer a.mu.Unlock()
+
+	resolved, err := a.resolveApprovalLocked(id, a.currentUserIDLocked(), state)
+	if err != nil {
+		log.Printf("db error while resolving approval: %v", err)
+		http.Error(w, "could not save approval", http.StatusInternalServerError)
+		return
+	}
+	if !resolved {
+		http.NotFound(w, r)
+		return
+	}
+	if i := a.itemIndexLocked(id); i >= 0 {
+		a.items[i].ApprovalState = state
+	}
+	a.recordHistoryLocked(id, "approval "+state, "")
+
+	http.Redirect(w, r, "/settings/approvals?saved="+action, http.StatusSeeOther)
+}
+
+// notifyApproverLocked asks the approver of the item owner's rule over ntfy to review the item, as the
+// requesting profile, which is active.
+func (a *App) notifyApproverLocked(item Item) error {
+	_, approver, err := a.approvalRuleForProfileLocked(item.OwnerID)
+	if err != nil {
+		return fmt.Errorf("load approval rule: %w", err)
+	}
+	if approver == "" {
+		log.Printf("ntfy skipped for approval of item %d: the approval rule was removed", item.ID)
+		return nil
+	}
+	endpoint, topic, err := a.ntfySettingsForProfileLocked(approver)
+	if err != nil {
+		return fmt.Errorf("load approver ntfy settings: %w", err)
+	}
+	if strings.TrimSpace(endpoint) == "" || strings.TrimSpace(topic) == "" {
+		log.Printf("ntfy skipped for approval of item %d: approver has no endpoint/topic", item.ID)
+		return nil
+	}
+
+	message := fmt.Sprintf("%s asks for approval to buy %s (%s).\nReview: %ssettings/approvals", a.currentUserIDLocked(), item.Title, item.Price, a.dashboardLink())
+	if a.notificationDryRunLocked(effectNtfy, endpoint+"/"+topic, message) {
+		a.recordDeliveryLocked(notificationApproval, effectNtfy, item, 0, nil)
+		return nil
+	}
+	code, err := a.postNtfyMessage(a.mu.Context(), endpoint, topic, "Impulse Pause approval request", message)
+	a.recordDeliveryLocked(notificationApproval, effectNtfy, item, code, err)
+	return err
+}
+
+func (a *App) approvalSettings(w http.ResponseWriter, r *http.Request) {
+	feedback := ""
+	switch r.URL.Query().Get("saved") {
+	case "1":
+		feedback = "Approval rule saved."
+	case "approve":
+		feedback = "Purchase approved."
+	case "deny":
+		feedback = "Purchase denied."
+	}
+	a.renderApprovalSettings(w, approvalSettingsViewData{Feedback: feedback})
+}
+
+func (a *App) saveApprovalSettings(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	thresholdRaw := strings.TrimSpace(r.FormValue("approval_threshold"))
+	approver := strings.TrimSpace(r.FormValue("approver"))
+	formData := approvalSettingsViewData{Threshold: thresholdRaw, Approver: approver}
+
+	a.mu.RLock()
+	format := domain.NormalizeNumberFormat(a.numberFormat)
+	a.mu.RUnlock()
+	threshold, err := parseApprovalThreshold(thresholdRaw, format)
+	if err != nil {
+		formData.Error = err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		a.renderApprovalSettings(w, formData)
+		return
+	}
+
+	if approver != "" {
+		profiles, err := a.listProfileNames()
+		if err != nil {
+			log.Printf("db error while listing approvers: %v", err)
+			http.Error(w, "could not save approval rule", http.StatusInternalServerError)
+			return
+		}
+		if approver == a.activeProfileName() || !slices.Contains(profiles, approver) {
+			formData.Error = "Please choose another profile as approver."
+			w.WriteHeader(http.StatusBadRequest)
+			a.renderApprovalSettings(w, formData)
+			return
+		}
+	}
+
+	a.mu.LockContext(r.Context())
+	a.approvalThreshold = threshold
+	a.approver = approver
+	if err := a.persistProfileLocked(); err != nil {
+		a.mu.Unlock()
+		log.Printf("db error while saving approval rule: %v", err)
+		http.Error(w, "could not save approval rule", http.StatusInternalServerError)
+		return
+	}
+	a.publishProfileUpdatedLocked("approvals", r)
+	a.mu.Unlock()
+
+	http.Redirect(w, r, "/settings/approvals?saved=1", http.StatusSeeOther)
+}
+
+func (a *App) renderApprovalSettings(w http.ResponseWriter, data approvalSettingsViewData) {
+	profiles, err := a.listProfileNames()
+	if err != nil {
+		log.Printf("db error while listing approvers: %v", err)
+		http.Error(w, "could not load approval settings", http.StatusInternalServerError)
+		return
+	}
+
+	a.mu.RLock()
+	active := a.currentUserIDLocked()
+	if data.Threshold == "" && data.Error == "" && a.approvalThreshold > 0 {
+		data.Threshold = a.approvalThreshold.String()
+	}
+	if data.Approver == "" && data.Error == "" {
+		data.Approver = a.approver
+	}
+	pending, err := a.pendingApprovalsLocked(active)
+	data.Currency = profileCurrencyOrDefault(a.currency)
+	a.mu.RUnlock()
+	if err != nil {
+		log.Printf("db error while loading pending approvals: %v", err)
+		http.Error(w, "could not load approval settings", http.StatusInternalServerError)
+		return
+	}
+
+	data.Title = "Approvals"
+	data.CurrentPath = "/settings/approvals"
+	data.ActiveProfile = active
+	data.PendingApprovals = pending
+	data.ApproverOptions = slices.DeleteFunc(profiles, func(name string) bool { return name == active })
+	data.ContentTemplate = "approvals_content"
+	renderTemplate(w, a.templates, "layout", data)
+}
@@ -0,0 +1,90 @@
+package web
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRequiresApproval(t *testing.T) {
+	priced := Item{PriceCents: 25000, HasPriceValue: true}
+	if !requiresApproval(priced, 20000, "Bea") {
+		t.Fatalf("expected item above threshold to require approval")
+	}
+	if requiresApproval(priced, 30000, "Bea") {
+		t.Fatalf("expected item below threshold to skip approval")
+	}
+	if requiresApproval(priced, 20000, "") {
+		t.Fatalf("expected rule without approver to be off")
+	}
+	if requiresApproval(Item{}, 20000, "Bea") {
+		t.Fatalf("expected item without price to skip approval")
+	}
+}
+
+func TestApprovalWorkflowBlocksBoughtUntilApproved(t *testing.T) {
+	app, cleanup := newSQLiteTestApp(t)
+	defer cleanup()
+
+	seedSharingProfiles(t, app)
+
+	app.mu.Lock()
+	item := Item{Title: "Road bike", Price: "900", PriceCents: 90000, HasPriceValue: true, Status: "Ready to buy", WaitPreset: "24h", PurchaseAllowedAt: time.Now().Add(-time.Hour), CreatedAt: time.Now()}
+	if err := app.insertItemLocked(&item); err != nil {
+		app.mu.Unlock()
+		t.Fatalf("insert item: %v", err)
+	}
+	app.items = append(app.items, item)
+	app.mu.Unlock()
+	itemID := strconv.Itoa(item.ID)
+
+	if rr := postSharingForm(app, "Alex", "/settings/approvals", url.Values{"approval_threshold": {"500"}, "approver": {"Alex"}}); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected self-approval to be rejected, got %d", rr.Code)
+	}
+	if rr := postSharingForm(app, "Alex", "/settings/approvals", url.Values{"approval_threshold": {"500"}, "approver": {"Bea"}}); rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected approval rule redirect, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if body := visitDashboard(t, app, "Alex"); !strings.Contains(body, "Request approval") {
+		t.Fatalf("expected request approval action on dashboard")
+	}
+	if rr := postSharingForm(app, "Alex", "/items/status", url.Values{"item_id": {itemID}, "status": {"Bought"}}); rr.Code != http.StatusConflict {
+		t.Fatalf("expected Bought to be blocked without approval, got %d", rr.Code)
+	}
+	if rr := postSharingForm(app, "Alex", "/items/approval", url.Values{"item_id": {itemID}, "action": {"request"}}); rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected request redirect, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := postSharingForm(app, "Alex", "/items/approval", url.Values{"item_id": {itemID}, "action": {"approve"}}); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected owner to be unable to approve their own request, got %d", rr.Code)
+	}
+
+	rr := getSharingPage(app, "Bea", "/settings/approvals")
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "Road bike") {
+		t.Fatalf("expected pending request on approver's page, got %d", rr.Code)
+	}
+	if rr := postSharingForm(app, "Bea", "/items/approval", url.Values{"item_id": {itemID}, "action": {"approve"}}); rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected approve redirect, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if rr := postSharingForm(app, "Alex", "/items/status", url.Values{"item_id": {itemID}, "status": {"Bought"}}); rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected Bought after approval, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	app.mu.RLock()
+	history, err := app.itemHistoryLocked(item.ID)
+	app.mu.RUnlock()
+	if err != nil {
+		t.Fatalf("load history: %v", err)
+	}
+	var actions []string
+	for _, entry := range history {
+		actions = append(actions, entry.Actor+" "+entry.Action)
+	}
+	got := strings.Join(actions, ", ")
+	if got != "Alex bought, Bea approval approved, Alex approval requested" {
+		t.Fatalf("unexpected history: %s", got)
+	}
+}
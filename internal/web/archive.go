@@ -0,0 +1,218 @@
+package web
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// errProfileArchived answers changes to an archived profile.
+var errProfileArchived = errors.New("This profile is archived and read-only. Restore it in the profile settings to make changes.")
+
+// archivedProfile is a profile hidden from the profile switcher, listed on the household page.
+type archivedProfile struct {
+	Name       string
+	ArchivedAt time.Time
+}
+
+// archivedWritablePaths stay usable for an archived profile: leaving it, restoring or deleting it, and
+// the admin and invite pages, which do not act on the active profile. GraphQL only answers queries.
+var archivedWritablePaths = []string{"/switch-profile", "/settings/profile/restore", "/settings/profile/delete", "/graphql"}
+
+func (a *App) setProfileArchivedLocked(name string, archivedAt time.Time) error {
+	value := ""
+	if !archivedAt.IsZero() {
+		value = archivedAt.Format(time.RFC3339Nano)
+	}
+	if _, err := a.db.Exec(`UPDATE profiles SET archived_at = ? WHERE user_id = ?`, value, name); err != nil {
+		return fmt.Errorf("archive profile: %w", err)
+	}
+	return nil
+}
+
+// profileArchived reports whether the named profile is archived. Unknown profiles are not.
+func (a *App) profileArchived(name string) (bool, error) {
+	a.mu.RLock()
+	db := a.db
+	a.mu.RUnlock()
+	if db == nil || name == "" {
+		return false, nil
+	}
+
+	var archivedAt string
+	err := db.QueryRow(`SELECT archived_at FROM profiles WHERE user_id = ?`, name).Scan(&archivedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("load archived flag: %w", err)
+	}
+	return archivedAt != "", nil
+}
+
+// listArchivedProfiles returns the archived profiles, most recently archived first.
+func (a *App) listArchivedProfiles() ([]archivedProfile, error) {
+	a.mu.RLock()
+	db := a.db
+	a.mu.RUnlock()
+	if db == nil {
+		return nil, nil
+	}
+
+	rows, err := db.Query(`SELECT user_id, archived_at FROM profiles WHERE archived_at != '' ORDER BY archived_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("list archived profiles: %w", err)
+	}
+	defer rows.Close()
+
+	var archived []archivedProfile
+	for rows.Next() {
+		var profile archivedProfile
+		var archivedAt string
+		if err := rows.Scan(&profile.Name, &archivedAt); err != nil {
+			return nil, fmt.Errorf("scan archived profile: %w", err)
+		}
+		profile.ArchivedAt, _ = time.Parse(time.RFC3339Nano, archivedAt)
+		archived = append(archived, profile)
+	}
+	return archived, rows.Err()
+}
+
+// withoutArchivedProfiles drops archived profiles from the names offered on the switch-profile page.
+func (a *App) withoutArchivedProfiles(names []string) ([]string, error) {
+	archived, err := a.listArchivedProfiles()
+	if err != nil || len(archived) == 0 {
+		return names, err
+	}
+	return slices.DeleteFunc(slices.Clone(names), func(name string) bool {
+		return slices.ContainsFunc(archived, func(profile archivedProfile) bool { return profile.Name == name })
+	}), nil
+}
+
+// archivedProfileGuard keeps archived profiles read-only. It refuses changes while the active_profile
+// cookie names an archived profile, so every form and API endpoint is covered without its own check.
+func (a *App) archivedProfileGuard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+		if slices.Contains(archivedWritablePaths, r.URL.Path) || strings.HasPrefix(r.URL.Path, "/household") || strings.HasPrefix(r.URL.Path, "/invite/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		cookie, err := r.Cookie("active_profile")
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		archived, err := a.profileArchived(strings.TrimSpace(cookie.Value))
+		if err != nil {
+			log.Printf("db error while checking archived profile: %v", err)
+		}
+		if !archived {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if strings.HasPrefix(r.URL.Path, "/api/") {
+			writeAPIError(w, http.StatusForbidden, errProfileArchived.Error())
+			return
+		}
+		http.Error(w, errProfileArchived.Error(), http.StatusForbidden)
+	})
+}
+
+// archiveProfile hides the active profile from the switcher and stops background jobs from touching it.
+// Unlike deleting, its items and settings are kept until it is restored.
+func (a *App) archiveProfile(w http.ResponseWriter, r *http.Request) {
+	a.mu.LockContext(r.Context())
+	if a.db == nil {
+		a.mu.Unlock()
+		http.Error(w, "archiving needs a database", http.StatusConflict)
+		return
+	}
+	profileName := a.currentUserIDLocked()
+	if profileName == "" {
+		a.mu.Unlock()
+		http.Redirect(w, r, "/switch-profile", http.StatusSeeOther)
+		return
+	}
+	err := a.persistProfileLocked()
+	if err == nil {
+		err = a.setProfileArchivedLocked(profileName, time.Now())
+	}
+	if err != nil {
+		a.mu.Unlock()
+		log.Printf("db error while archiving profile: %v", err)
+		http.Error(w, "could not archive profile", http.StatusInternalServerError)
+		return
+	}
+	a.recordAuditLocked(profileName, auditProfileArchived, "", r)
+	a.resetActiveProfileLocked()
+	a.mu.Unlock()
+
+	http.SetCookie(w, &http.Cookie{Name: "active_profile", Value: "", Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode, MaxAge: -1})
+	http.Redirect(w, r, "/switch-profile", http.StatusSeeOther)
+}
+
+// restoreActiveProfile is the restore button an archived profile sees in its own settings.
+func (a *App) restoreActiveProfile(w http.ResponseWriter, r *http.Request) {
+	if err := a.activateProfileFromRequest(r); err != nil {
+		http.Error(w, "could not activate profile", http.StatusInternalServerError)
+		return
+	}
+	a.mu.LockContext(r.Context())
+	err := a.restoreProfileLocked(a.currentUserIDLocked(), r)
+	a.mu.Unlock()
+	if err != nil {
+		log.Printf("db error while restoring profile: %v", err)
+		http.Error(w, "could not restore profile", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/settings/profile", http.StatusSeeOther)
+}
+
+// restoreArchivedProfile restores a profile from the household page.
+func (a *App) restoreArchivedProfile(w http.ResponseWriter, r *http.Request) {
+	if !a.requireAdmin(w, r) {
+		return
+	}
+	if a.db == nil {
+		http.Error(w, "archiving needs a database", http.StatusConflict)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	a.mu.LockContext(r.Context())
+	err := a.restoreProfileLocked(strings.TrimSpace(r.FormValue("profile")), r)
+	a.mu.Unlock()
+	if err != nil {
+		log.Printf("db error while restoring profile: %v", err)
+		http.Error(w, "could not restore profile", http.StatusInternalServerError)
+		return
+	}
+	householdRedirect(w, r)
+}
+
+func (a *App) restoreProfileLocked(name string, r *http.Request) error {
+	if a.db == nil || name == "" {
+		return nil
+	}
+	if err := a.setProfileArchivedLocked(name, time.Time{}); err != nil {
+		return err
+	}
+	if name == a.currentUserIDLocked() {
+		a.archived = false
+	}
+	a.recordAuditLocked(name, auditProfileRestored, "", r)
+	return nil
+}
@@ -0,0 +1,44 @@
+package web_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestArchivedProfilesAreHiddenReadOnlyAndRestorable(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}, {Name: "Sam"}},
+		Items:    []webtest.Item{{Profile: "Alex", Title: "Tent"}},
+	})
+	h.App.SetAdminToken("s3cret")
+	alex := h.As("Alex")
+
+	alex.PostForm("/settings/profile/archive", url.Values{}).ExpectRedirect("/switch-profile")
+	h.Anonymous().Get("/switch-profile").ExpectContains(`value="Sam"`).ExpectNotContains(`value="Alex"`)
+
+	// Typing the name still opens an archived profile, read-only.
+	alex.PostForm("/switch-profile", url.Values{"profile_name": {"Alex"}}).ExpectRedirect("/")
+	alex.Get("/").ExpectStatus(http.StatusOK).ExpectContains("This profile is archived", "Tent")
+	alex.PostForm("/items/new", url.Values{"title": {"Headphones"}, "wait_preset": {"24h"}}).ExpectStatus(http.StatusForbidden)
+	alex.PostJSON("/api/v1/items", map[string]any{"title": "Headphones"}).
+		ExpectStatus(http.StatusForbidden).
+		ExpectContains(`"error":"This profile is archived and read-only.`)
+	if items := h.Items("Alex"); len(items) != 1 {
+		t.Fatalf("expected the archived profile to keep only its item, got %d", len(items))
+	}
+
+	admin := h.Anonymous()
+	admin.Get("/household?token=s3cret").ExpectStatus(http.StatusOK).ExpectContains("Archived profiles", `name="profile" value="Alex"`)
+	admin.PostForm("/household/profiles/restore?token=s3cret", url.Values{"profile": {"Alex"}}).ExpectRedirect("/household?token=s3cret")
+	h.Anonymous().Get("/switch-profile").ExpectContains(`value="Alex"`)
+	alex.PostJSON("/api/v1/items", map[string]any{"title": "Headphones"}).ExpectStatus(http.StatusCreated)
+
+	alex.PostForm("/settings/profile/archive", url.Values{}).ExpectRedirect("/switch-profile")
+	alex.PostForm("/switch-profile", url.Values{"profile_name": {"Alex"}}).ExpectRedirect("/")
+	alex.Get("/settings/profile").ExpectContains("Restore profile")
+	alex.PostForm("/settings/profile/restore", url.Values{}).ExpectRedirect("/settings/profile")
+	alex.Get("/settings/profile").ExpectContains("Archive profile").ExpectNotContains("Restore profile")
+}
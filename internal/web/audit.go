@@ -0,0 +1,91 @@
+package web
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	auditProfileSwitched  = "profile switched"
+	auditProfileCreated   = "profile created"
+	auditProfileRenamed   = "profile renamed"
+	auditProfileDeleted   = "profile deleted"
+	auditProfileArchived  = "profile archived"
+	auditProfileRestored  = "profile restored"
+	auditSettingsChanged  = "settings changed"
+	auditShareLinkCreated = "share link created"
+	auditShareLinkRevoked = "share link revoked"
+	auditShareLinkExpiry  = "share link expiry changed"
+	auditDataWiped        = "data wiped"
+	auditItemTransferred  = "item moved"
+	auditTokenUsed        = "token used"
+
+	// auditLogPageSize is how many entries the settings page shows.
+	auditLogPageSize = 20
+	// tokenUseAuditInterval limits token-use entries for clients that poll, such as the auto-refreshing kiosk.
+	tokenUseAuditInterval = time.Hour
+)
+
+// auditEntry is a security-relevant action on a profile.
+type auditEntry struct {
+	Event      string
+	Detail     string
+	RemoteAddr string
+	CreatedAt  time.Time
+}
+
+// recordAuditLocked stores an audit entry for the profile. Failures are logged because auditing must not block the action itself.
+func (a *App) recordAuditLocked(userID, event, detail string, r *http.Request) {
+	a.recordAuditFromLocked(userID, event, detail, clientAddr(r))
+}
+
+// recordAuditFromLocked is recordAuditLocked for callers that only kept the client address, such as event subscribers.
+func (a *App) recordAuditFromLocked(userID, event, detail, remoteAddr string) {
+	entry := auditEntry{Event: event, Detail: detail, RemoteAddr: remoteAddr, CreatedAt: time.Now()}
+	if err := a.insertAuditLocked(userID, entry); err != nil {
+		log.Printf("db error while recording audit entry: %v", err)
+	}
+}
+
+// recordTokenUseLocked audits the use of a named token at most once per tokenUseAuditInterval and profile.
+func (a *App) recordTokenUseLocked(userID, tokenName string, r *http.Request) {
+	if !a.tokenUses.due(userID+"\x00"+tokenName, time.Now()) {
+		return
+	}
+	a.recordAuditLocked(userID, auditTokenUsed, tokenName, r)
+}
+
+// tokenUses remembers when token uses were last audited. Every request's app shares it, so it has its
+// own lock.
+type tokenUses struct {
+	mu        sync.Mutex
+	auditedAt map[string]time.Time
+}
+
+// due reports whether the use of key at now is to be audited, and if so remembers it.
+func (u *tokenUses) due(key string, now time.Time) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if last, ok := u.auditedAt[key]; ok && now.Sub(last) < tokenUseAuditInterval {
+		return false
+	}
+	if u.auditedAt == nil {
+		u.auditedAt = map[string]time.Time{}
+	}
+	u.auditedAt[key] = now
+	return true
+}
+
+func clientAddr(r *http.Request) string {
+	if r == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,21 @@
+package web_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestProfileSettingsShowRecentActivity(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{})
+
+	alex := h.Anonymous()
+	alex.PostForm("/switch-profile", url.Values{"profile_name": {"Alex"}}).ExpectStatus(http.StatusSeeOther)
+	alex.PostForm("/settings/data", url.Values{"retention_months": {"6"}}).ExpectStatus(http.StatusSeeOther)
+
+	alex.Get("/settings/profile").
+		ExpectStatus(http.StatusOK).
+		ExpectContains("Recent activity", "settings changed · data retention", "profile created", "192.0.2.1")
+}
@@ -0,0 +1,78 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func auditEvents(t *testing.T, app *App, profile string) []string {
+	t.Helper()
+	app.mu.RLock()
+	entries, err := app.auditLogLocked(profile, auditLogPageSize)
+	app.mu.RUnlock()
+	if err != nil {
+		t.Fatalf("load audit log: %v", err)
+	}
+	events := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		event := entry.Event
+		if entry.Detail != "" {
+			event += ": " + entry.Detail
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestAuditLogRecordsProfileLifecycle(t *testing.T) {
+	app, cleanup := newSQLiteTestApp(t)
+	defer cleanup()
+
+	postSharingForm(app, "", "/switch-profile", url.Values{"profile_name": {"Alex"}})
+	postSharingForm(app, "Alex", "/switch-profile", url.Values{"profile_name": {"Bea"}})
+	postSharingForm(app, "Bea", "/switch-profile", url.Values{"profile_name": {"Alex"}})
+
+	rr := postSharingForm(app, "Alex", "/settings/profile", url.Values{"profile_name": {"Alexa"}, "hourly_wage": {"30"}, "default_wait_preset": {"24h"}, "currency": {"EUR"}})
+	if rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected profile save redirect, got %d", rr.Code)
+	}
+
+	want := []string{"settings changed: profile", "profile renamed: Alex → Alexa", "profile switched: from Bea", "profile created"}
+	if got := auditEvents(t, app, "Alexa"); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected audit log %q", got)
+	}
+
+	rr = postSharingForm(app, "Alexa", "/settings/profile/delete", url.Values{})
+	if rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected delete redirect, got %d", rr.Code)
+	}
+	if got := auditEvents(t, app, "Alexa"); len(got) == 0 || got[0] != "profile deleted" {
+		t.Fatalf("expected deletion to stay in the audit log, got %q", got)
+	}
+}
+
+func TestAuditLogRecordsShareTokenUseOncePerInterval(t *testing.T) {
+	app, cleanup := newSQLiteTestApp(t)
+	defer cleanup()
+
+	postSharingForm(app, "", "/switch-profile", url.Values{"profile_name": {"Alex"}})
+	postSharingForm(app, "Alex", "/settings/share", url.Values{"action": {"generate"}})
+
+	token := storedShareToken(t, app, "Alex")
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/kiosk?token="+token, nil)
+		rr := httptest.NewRecorder()
+		app.Handler().ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected kiosk 200, got %d", rr.Code)
+		}
+	}
+
+	want := []string{"token used: share link", "share link created", "profile created"}
+	if got := auditEvents(t, app, "Alex"); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected audit log %q", got)
+	}
+}
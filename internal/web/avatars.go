@@ -0,0 +1,94 @@
+package web
+
+import (
+	"fmt"
+	"sync"
+
+	"mvpapp/internal/domain"
+)
+
+// profileAvatar is the colored badge shown next to a profile name in the header and on the
+// switch-profile page.
+type profileAvatar struct {
+	// Symbol is the chosen emoji, or the profile's initial without one.
+	Symbol string
+	Color  string
+}
+
+func avatarFor(name, emoji, color string) profileAvatar {
+	avatar := profileAvatar{Symbol: emoji, Color: color}
+	if avatar.Symbol == "" {
+		avatar.Symbol = domain.AvatarInitial(name)
+	}
+	if avatar.Color == "" {
+		avatar.Color = domain.DefaultAvatarColor(name)
+	}
+	return avatar
+}
+
+// avatarCache keeps the avatars of loaded profiles for the templates. The layout renders after the
+// handler released a.mu, so the cache has its own lock.
+type avatarCache struct {
+	mu     sync.RWMutex
+	byName map[string]profileAvatar
+}
+
+func (c *avatarCache) set(name string, avatar profileAvatar) {
+	c.mu.Lock()
+	if c.byName == nil {
+		c.byName = make(map[string]profileAvatar)
+	}
+	c.byName[name] = avatar
+	c.mu.Unlock()
+}
+
+func (c *avatarCache) forget(name string) {
+	c.mu.Lock()
+	delete(c.byName, name)
+	c.mu.Unlock()
+}
+
+// profileAvatar is the "profileAvatar" template function. Profiles that were never loaded get the
+// default avatar for their name.
+func (a *App) profileAvatar(name string) profileAvatar {
+	a.avatars.mu.RLock()
+	avatar, ok := a.avatars.byName[name]
+	a.avatars.mu.RUnlock()
+	if !ok {
+		return avatarFor(name, "", "")
+	}
+	return avatar
+}
+
+// cacheActiveAvatarLocked records the active profile's avatar after it was loaded or changed.
+func (a *App) cacheActiveAvatarLocked() {
+	name := a.currentUserIDLocked()
+	if name == "" {
+		return
+	}
+	a.avatars.set(name, avatarFor(name, a.avatarEmoji, a.avatarColor))
+}
+
+// loadProfileAvatars refreshes the cache from every stored profile, for the switch-profile page.
+func (a *App) loadProfileAvatars() error {
+	a.mu.RLock()
+	db := a.db
+	a.mu.RUnlock()
+	if db == nil {
+		return nil
+	}
+
+	rows, err := db.Query(`SELECT user_id, avatar_emoji, avatar_color FROM profiles`)
+	if err != nil {
+		return fmt.Errorf("list profile avatars: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name, emoji, color string
+		if err := rows.Scan(&name, &emoji, &color); err != nil {
+			return fmt.Errorf("scan profile avatar: %w", err)
+		}
+		a.avatars.set(name, avatarFor(name, emoji, color))
+	}
+	return rows.Err()
+}
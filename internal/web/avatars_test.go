@@ -0,0 +1,35 @@
+package web_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestProfileAvatarShowsInHeaderAndProfileSwitch(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{Profiles: []webtest.Profile{{Name: "Alex"}, {Name: "Sam"}}})
+	alex := h.As("Alex")
+
+	alex.PostForm("/settings/profile", url.Values{"profile_name": {"Alex"}, "hourly_wage": {"25"}, "avatar_emoji": {"🦊"}, "avatar_color": {"gold"}}).
+		ExpectStatus(http.StatusBadRequest).
+		ExpectContains(`id="avatar_color-error"`)
+	alex.PostForm("/settings/profile", url.Values{"profile_name": {"Alex"}, "hourly_wage": {"25"}, "avatar_emoji": {"🦊"}, "avatar_color": {"teal"}}).
+		ExpectRedirect("/settings/profile?saved=1")
+
+	alex.Get("/").ExpectContains(`<span class="profile-badge"><span class="avatar avatar-teal" aria-hidden="true">🦊</span>Alex</span>`)
+	alex.Get("/settings/profile").ExpectContains(`value="🦊"`, `<option value="teal" selected>`)
+	alex.Get("/switch-profile").ExpectContains(
+		`<span class="avatar avatar-teal" aria-hidden="true">🦊</span>Alex</button>`,
+		`aria-hidden="true">S</span>Sam</button>`,
+	)
+
+	var emoji, color string
+	if err := h.DB.QueryRow(`SELECT avatar_emoji, avatar_color FROM profiles WHERE user_id = 'Alex'`).Scan(&emoji, &color); err != nil {
+		t.Fatalf("load avatar: %v", err)
+	}
+	if emoji != "🦊" || color != "teal" {
+		t.Fatalf("expected the avatar to be stored, got %q %q", emoji, color)
+	}
+}
@@ -0,0 +1,79 @@
+package web
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// backupsKept is how many backups the backup job keeps; older ones are deleted after each run.
+	backupsKept = 7
+	// backupNameFormat names backups by the UTC time they were taken, so they sort by age.
+	backupNameFormat = "app-20060102T150405Z.db"
+)
+
+// StartBackgroundBackups registers the "backup" job, which writes a copy of the database to dir every
+// interval, first after one interval, and keeps the newest backupsKept of them. Apps without a database,
+// or without a backup directory, have nothing to back up.
+func (a *App) StartBackgroundBackups(dir string, interval time.Duration) {
+	if a.db == nil || dir == "" {
+		return
+	}
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+
+	a.registerJob("backup", "@every "+interval.String(), 0, false, func(a *App, now time.Time) error {
+		_, err := a.backupDatabase(dir, now)
+		return err
+	})
+}
+
+// backupDatabase writes a consistent copy of the database to dir and deletes all but the newest
+// backupsKept backups there. VACUUM INTO reads from its own snapshot, so requests go on meanwhile;
+// the copy is written under a temporary name first, so a backup that exists is complete.
+func (a *App) backupDatabase(dir string, now time.Time) (string, error) {
+	path := filepath.Join(dir, now.UTC().Format(backupNameFormat))
+	partial := path + ".partial"
+	_ = os.Remove(partial)
+	if _, err := a.db.Exec(`VACUUM INTO ?`, partial); err != nil {
+		_ = os.Remove(partial)
+		return "", fmt.Errorf("back up database: %w", err)
+	}
+	if err := os.Rename(partial, path); err != nil {
+		return "", fmt.Errorf("back up database: %w", err)
+	}
+	return path, pruneBackups(dir)
+}
+
+// pruneBackups deletes all but the newest backupsKept backups in dir. Other files are left alone.
+func pruneBackups(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("list backups: %w", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if _, err := time.Parse(backupNameFormat, entry.Name()); err == nil && !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	if len(names) <= backupsKept {
+		return nil
+	}
+	slices.Sort(names)
+	var failed []string
+	for _, name := range names[:len(names)-backupsKept] {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			failed = append(failed, err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("delete old backups: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
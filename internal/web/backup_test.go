@@ -0,0 +1,58 @@
+package web
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBackupsCopyTheDatabaseAndKeepTheNewest(t *testing.T) {
+	app, cleanup := newSQLiteTestApp(t)
+	defer cleanup()
+	seedProfile(app)
+	app.mu.Lock()
+	item := Item{Title: "Headphones", Status: "Waiting", WaitPreset: "24h", PurchaseAllowedAt: time.Now().Add(time.Hour), CreatedAt: time.Now()}
+	err := app.insertItemLocked(&item)
+	owner := app.currentUserIDLocked()
+	app.mu.Unlock()
+	if err != nil {
+		t.Fatalf("insert item: %v", err)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("kept"), 0o600); err != nil {
+		t.Fatalf("write unrelated file: %v", err)
+	}
+	start := time.Date(2026, time.March, 1, 3, 0, 0, 0, time.UTC)
+	var newest string
+	for day := range backupsKept + 2 {
+		if newest, err = app.backupDatabase(dir, start.AddDate(0, 0, day)); err != nil {
+			t.Fatalf("back up: %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("list backups: %v", err)
+	}
+	if len(entries) != backupsKept+1 {
+		t.Fatalf("expected %d backups and the unrelated file, got %d entries", backupsKept, len(entries))
+	}
+	if _, err := os.Stat(filepath.Join(dir, start.Format(backupNameFormat))); !os.IsNotExist(err) {
+		t.Fatalf("expected the oldest backup to be deleted, got %v", err)
+	}
+	if filepath.Base(newest) != "app-20260309T030000Z.db" {
+		t.Fatalf("unexpected backup name %s", newest)
+	}
+
+	restored, err := NewAppWithSQLite(newest)
+	if err != nil {
+		t.Fatalf("open backup: %v", err)
+	}
+	defer restored.Close()
+	items, err := queryItemsForUser(restored.db, owner)
+	if err != nil || len(items) != 1 || items[0].Title != "Headphones" {
+		t.Fatalf("expected the backup to hold the item, got %+v (%v)", items, err)
+	}
+}
@@ -0,0 +1,173 @@
+package web
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+type blackoutSettingsViewData struct {
+	Title           string
+	CurrentPath     string
+	ContentTemplate string
+	ScriptTemplate  string
+	ActiveProfile   string
+	Blackouts       []domain.Blackout
+	Active          *domain.Blackout
+	StartInput      string
+	EndInput        string
+	LabelInput      string
+	Error           string
+	FieldErrors     map[string]string
+	Feedback        string
+}
+
+// activeBlackoutLocked returns the active profile's blackout that is in effect now, if any.
+func (a *App) activeBlackoutLocked(now time.Time) *domain.Blackout {
+	if blackout, ok := domain.ActiveBlackout(a.blackouts, now); ok {
+		return &blackout
+	}
+	return nil
+}
+
+// heldByBlackoutLocked maps item IDs to whether the current blackout alone keeps them waiting.
+func (a *App) heldByBlackoutLocked(items []Item, now time.Time) map[int]bool {
+	held := map[int]bool{}
+	for _, item := range items {
+		if domain.HeldByBlackout(item, a.blackouts, now) {
+			held[item.ID] = true
+		}
+	}
+	return held
+}
+
+// upcomingBlackouts drops the blackouts that are over; they no longer matter and are not kept when the list is saved.
+func upcomingBlackouts(blackouts []domain.Blackout, now time.Time) []domain.Blackout {
+	var upcoming []domain.Blackout
+	for _, blackout := range blackouts {
+		if blackout.End.After(now) {
+			upcoming = append(upcoming, blackout)
+		}
+	}
+	return upcoming
+}
+
+func (a *App) blackoutSettings(w http.ResponseWriter, r *http.Request) {
+	feedback := ""
+	switch r.URL.Query().Get("saved") {
+	case "added":
+		feedback = "Blackout added. Waits ending inside it now end with it."
+	case "deleted":
+		feedback = "Blackout removed."
+	}
+	a.renderBlackoutSettings(w, blackoutSettingsViewData{Feedback: feedback})
+}
+
+// saveBlackouts adds a blackout, or with action=delete removes the one at the given index.
+// Removing a blackout does not bring back the earlier unlock time of items it held back.
+func (a *App) saveBlackouts(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	a.mu.RLock()
+	blackouts := upcomingBlackouts(a.blackouts, now)
+	a.mu.RUnlock()
+
+	saved := "added"
+	if r.FormValue("action") == "delete" {
+		index, err := strconv.Atoi(r.FormValue("index"))
+		if err != nil || index < 0 || index >= len(blackouts) {
+			http.Error(w, "invalid blackout", http.StatusBadRequest)
+			return
+		}
+		blackouts = slices.Delete(blackouts, index, index+1)
+		saved = "deleted"
+	} else {
+		data := blackoutSettingsViewData{
+			StartInput: strings.TrimSpace(r.FormValue("blackout_start")),
+			EndInput:   strings.TrimSpace(r.FormValue("blackout_end")),
+			LabelInput: strings.TrimSpace(r.FormValue("blackout_label")),
+		}
+		blackout, err := domain.ParseBlackout(data.StartInput, data.EndInput, data.LabelInput, now)
+		var invalid *domain.ValidationError
+		if errors.As(err, &invalid) {
+			data.Error = fieldErrorSummary
+			data.FieldErrors = invalid.FieldMessages()
+			w.WriteHeader(http.StatusBadRequest)
+			a.renderBlackoutSettings(w, data)
+			return
+		}
+		blackouts = append(blackouts, blackout)
+		domain.SortBlackouts(blackouts)
+	}
+
+	a.mu.LockContext(r.Context())
+	previous := a.blackouts
+	a.blackouts = blackouts
+	if err := a.persistProfileLocked(); err != nil {
+		a.blackouts = previous
+		a.mu.Unlock()
+		log.Printf("db error while saving blackouts: %v", err)
+		http.Error(w, "could not save blackouts", http.StatusInternalServerError)
+		return
+	}
+	if _, err := a.itemServiceLocked().HoldBackForBlackouts(); err != nil {
+		log.Printf("db error while holding items back for blackouts: %v", err)
+	}
+	a.publishProfileUpdatedLocked("blackouts", r)
+	a.mu.Unlock()
+
+	http.Redirect(w, r, "/settings/blackouts?saved="+saved, http.StatusSeeOther)
+}
+
+func (a *App) renderBlackoutSettings(w http.ResponseWriter, data blackoutSettingsViewData) {
+	now := time.Now()
+	a.mu.RLock()
+	data.ActiveProfile = a.currentUserIDLocked()
+	data.Active = a.activeBlackoutLocked(now)
+	data.Blackouts = upcomingBlackouts(a.blackouts, now)
+	a.mu.RUnlock()
+
+	data.Title = "Blackout periods"
+	data.CurrentPath = "/settings/blackouts"
+	data.ContentTemplate = "blackouts_content"
+	renderTemplate(w, a.templates, "layout", data)
+}
+
+// overrideBlackout unlocks an item held back by the current blackout. The dashboard asks for
+// confirmation first, since blackouts are meant to be kept.
+func (a *App) overrideBlackout(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	id, err := strconv.Atoi(strings.TrimSpace(r.FormValue("item_id")))
+	if err != nil || id <= 0 {
+		http.Error(w, "invalid item id", http.StatusBadRequest)
+		return
+	}
+
+	a.mu.LockContext(r.Context())
+	a.promoteReadyItemsLocked(time.Now())
+	item, err := a.itemServiceLocked().OverrideBlackout(id)
+	a.mu.Unlock()
+
+	switch {
+	case errors.Is(err, domain.ErrTransitionNotAllowed), errors.Is(err, domain.ErrNotHeldByBlackout):
+		a.renderErrorPage(w, r, http.StatusConflict, "Only items held back by the current blackout can be unlocked.")
+	case err != nil:
+		a.renderDomainError(w, r, err, "could not unlock item")
+	default:
+		itemActionRedirect(w, r, "blackout-overridden", item.Title)
+	}
+}
@@ -0,0 +1,61 @@
+package web_test
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestBlackoutsHoldItemsBackUntilTheyEnd(t *testing.T) {
+	now := time.Now()
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}},
+		Items: []webtest.Item{
+			{Profile: "Alex", Title: "Sneakers", Status: "Waiting", WaitPreset: "24h", PurchaseAllowedAt: now.Add(time.Hour)},
+			{Profile: "Alex", Title: "Tent", Status: "Waiting", WaitPreset: "30d", PurchaseAllowedAt: now.AddDate(0, 0, 30)},
+		},
+	})
+	alex := h.As("Alex")
+	today := now.Format("2006-01-02")
+	lastDay := now.AddDate(0, 0, 9).Format("2006-01-02")
+
+	alex.PostForm("/settings/blackouts", url.Values{"blackout_start": {lastDay}, "blackout_end": {today}}).
+		ExpectStatus(http.StatusBadRequest).ExpectContains(`id="blackout_end-error"`, "cannot be before the first day")
+	alex.PostForm("/settings/blackouts", url.Values{"blackout_start": {today}, "blackout_end": {lastDay}, "blackout_label": {"No-buy month"}}).
+		ExpectRedirect("/settings/blackouts?saved=added")
+	alex.Get("/settings/blackouts").ExpectContains("No-buy month", "is in effect until")
+
+	alex.Get("/").ExpectContains(`id="blackout-banner"`, "No-buy month", "Unlock (emergency)")
+	if got := h.Item("Alex", "Sneakers").Status; got != "Waiting" {
+		t.Fatalf("expected the blackout to hold the item back, got %q", got)
+	}
+
+	alex.PostForm("/items/new", url.Values{"title": {"Jacket"}, "wait_preset": {"24h"}}).ExpectRedirect("/")
+	var allowedRaw string
+	if err := h.DB.QueryRow(`SELECT purchase_allowed_at FROM items WHERE title = 'Jacket'`).Scan(&allowedRaw); err != nil {
+		t.Fatalf("load item: %v", err)
+	}
+	allowed, err := time.Parse(time.RFC3339Nano, allowedRaw)
+	if err != nil {
+		t.Fatalf("parse purchase_allowed_at %q: %v", allowedRaw, err)
+	}
+	if want := time.Date(now.Year(), now.Month(), now.Day()+10, 0, 0, 0, 0, time.Local); !allowed.Equal(want) {
+		t.Fatalf("expected the wait to end with the blackout on %v, got %v", want, allowed)
+	}
+
+	tent := h.Item("Alex", "Tent")
+	alex.PostForm("/items/override-blackout", url.Values{"item_id": {strconv.Itoa(tent.ID)}}).ExpectStatus(http.StatusConflict)
+	sneakers := h.Item("Alex", "Sneakers")
+	alex.PostForm("/items/override-blackout", url.Values{"item_id": {strconv.Itoa(sneakers.ID)}}).
+		ExpectRedirect("/?done=blackout-overridden&item=Sneakers")
+	if got := h.Item("Alex", "Sneakers").Status; got != "Ready to buy" {
+		t.Fatalf("expected the override to unlock the item, got %q", got)
+	}
+
+	alex.PostForm("/settings/blackouts", url.Values{"action": {"delete"}, "index": {"0"}}).ExpectRedirect("/settings/blackouts?saved=deleted")
+	alex.Get("/").ExpectNotContains(`id="blackout-banner"`)
+}
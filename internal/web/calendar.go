@@ -0,0 +1,150 @@
+package web
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+const (
+	calendarUnlocks = "unlocks"
+	calendarBought  = "bought"
+	calendarSkipped = "skipped"
+
+	calendarMonthLayout = "2006-01"
+)
+
+// calendarEntry is an item on a calendar day, either unlocking or decided that day.
+type calendarEntry struct {
+	ItemID int
+	Title  string
+	Kind   string
+}
+
+type calendarDay struct {
+	Date    time.Time
+	InMonth bool
+	Today   bool
+	Entries []calendarEntry
+}
+
+type calendarViewData struct {
+	Title           string
+	CurrentPath     string
+	ContentTemplate string
+	ScriptTemplate  string
+	ActiveProfile   string
+	MonthLabel      string
+	PrevMonth       string
+	NextMonth       string
+	ThisMonth       string
+	Weekdays        []string
+	Weeks           [][]calendarDay
+	EntryCount      int
+	Error           string
+}
+
+// bucketItemsByDay groups items by local day, keyed "2006-01-02": open items on the day their wait ends
+// and decided items on the day they were decided. Researching items without a running wait are left out.
+func bucketItemsByDay(items []Item, loc *time.Location) map[string][]calendarEntry {
+	days := map[string][]calendarEntry{}
+	for _, item := range items {
+		entry := calendarEntry{ItemID: item.ID, Title: item.Title}
+		var at time.Time
+		switch item.Status {
+		case domain.StatusWaiting, domain.StatusReady:
+			entry.Kind, at = calendarUnlocks, item.PurchaseAllowedAt
+		case domain.StatusBought:
+			entry.Kind, at = calendarBought, itemDecisionTime(item)
+		case domain.StatusSkipped:
+			entry.Kind, at = calendarSkipped, itemDecisionTime(item)
+		default:
+			continue
+		}
+		key := at.In(loc).Format(time.DateOnly)
+		days[key] = append(days[key], entry)
+	}
+	for _, entries := range days {
+		slices.SortFunc(entries, func(a, b calendarEntry) int {
+			if a.Kind != b.Kind {
+				return strings.Compare(a.Kind, b.Kind)
+			}
+			return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+		})
+	}
+	return days
+}
+
+// calendarWeeks lays out the month as whole weeks starting on weekStart, padded with days of the
+// neighbouring months.
+func calendarWeeks(month time.Time, weekStart time.Weekday, days map[string][]calendarEntry, today time.Time) [][]calendarDay {
+	first := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
+	next := first.AddDate(0, 1, 0)
+	day := first.AddDate(0, 0, -((int(first.Weekday()) - int(weekStart) + 7) % 7))
+	todayKey := today.In(month.Location()).Format(time.DateOnly)
+
+	var weeks [][]calendarDay
+	for day.Before(next) {
+		week := make([]calendarDay, 7)
+		for i := range week {
+			key := day.Format(time.DateOnly)
+			week[i] = calendarDay{Date: day, InMonth: day.Month() == first.Month(), Today: key == todayKey, Entries: days[key]}
+			day = day.AddDate(0, 0, 1)
+		}
+		weeks = append(weeks, week)
+	}
+	return weeks
+}
+
+func calendarWeekdays(weekStart time.Weekday) []string {
+	names := make([]string, 7)
+	for i := range names {
+		names[i] = time.Weekday((int(weekStart) + i) % 7).String()[:3]
+	}
+	return names
+}
+
+// calendar shows a month of unlock and decision dates. Days follow the profile's insights timezone and
+// week start; ?month=2006-01 picks the month.
+func (a *App) calendar(w http.ResponseWriter, r *http.Request) {
+	a.mu.LockContext(r.Context())
+	now := time.Now()
+	a.promoteReadyItemsLocked(now)
+	periods := a.trendPeriodsLocked(trendGranularityWeek)
+	loc := periods.location()
+	days := bucketItemsByDay(a.items, loc)
+	data := calendarViewData{ActiveProfile: a.currentUserIDLocked()}
+	a.mu.Unlock()
+
+	month := time.Date(now.In(loc).Year(), now.In(loc).Month(), 1, 0, 0, 0, 0, loc)
+	data.ThisMonth = month.Format(calendarMonthLayout)
+	if raw := strings.TrimSpace(r.URL.Query().Get("month")); raw != "" {
+		parsed, err := time.ParseInLocation(calendarMonthLayout, raw, loc)
+		if err != nil {
+			data.Error = "Please pick a month like " + data.ThisMonth + "."
+			w.WriteHeader(http.StatusBadRequest)
+		} else {
+			month = parsed
+		}
+	}
+
+	data.Weeks = calendarWeeks(month, periods.WeekStart, days, now)
+	for _, week := range data.Weeks {
+		for _, day := range week {
+			if day.InMonth {
+				data.EntryCount += len(day.Entries)
+			}
+		}
+	}
+	data.Weekdays = calendarWeekdays(periods.WeekStart)
+	data.MonthLabel = month.Format("January 2006")
+	data.PrevMonth = month.AddDate(0, -1, 0).Format(calendarMonthLayout)
+	data.NextMonth = month.AddDate(0, 1, 0).Format(calendarMonthLayout)
+	data.Title = "Calendar"
+	data.CurrentPath = "/calendar"
+	data.ContentTemplate = "calendar_content"
+	renderTemplate(w, a.templates, "layout", data)
+}
@@ -0,0 +1,83 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBucketItemsByDayPlacesUnlocksAndDecisions(t *testing.T) {
+	at := func(day, hour int) time.Time { return time.Date(2026, time.March, day, hour, 0, 0, 0, time.UTC) }
+	items := []Item{
+		{ID: 1, Title: "Tent", Status: "Waiting", PurchaseAllowedAt: at(12, 23)},
+		{ID: 2, Title: "Boots", Status: "Ready to buy", PurchaseAllowedAt: at(3, 9)},
+		{ID: 3, Title: "Stove", Status: "Bought", PurchaseAllowedAt: at(1, 9), DecidedAt: at(12, 8)},
+		{ID: 4, Title: "Lamp", Status: "Skipped", CreatedAt: at(5, 10)},
+		{ID: 5, Title: "Idea", Status: "Researching"},
+	}
+
+	days := bucketItemsByDay(items, time.UTC)
+	if len(days) != 3 {
+		t.Fatalf("expected three days with entries, got %+v", days)
+	}
+	if got := days["2026-03-12"]; len(got) != 2 || got[0].Title != "Stove" || got[0].Kind != calendarBought || got[1].Kind != calendarUnlocks {
+		t.Fatalf("unexpected entries on the 12th: %+v", got)
+	}
+	if got := days["2026-03-05"]; len(got) != 1 || got[0].Kind != calendarSkipped {
+		t.Fatalf("expected a skipped item without decision date on its creation day, got %+v", got)
+	}
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+	if got := bucketItemsByDay(items[:1], berlin); len(got["2026-03-13"]) != 1 {
+		t.Fatalf("expected the late unlock on the next local day, got %+v", got)
+	}
+}
+
+func TestCalendarWeeksCoverTheMonthFromTheWeekStart(t *testing.T) {
+	month := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC) // a Sunday
+	weeks := calendarWeeks(month, time.Monday, nil, month)
+	if len(weeks) != 6 {
+		t.Fatalf("expected six weeks, got %d", len(weeks))
+	}
+	if first := weeks[0][0]; first.Date.Format(time.DateOnly) != "2026-02-23" || first.InMonth {
+		t.Fatalf("expected the grid to start on Monday 23 February, got %+v", first)
+	}
+	if !weeks[0][6].InMonth || !weeks[0][6].Today {
+		t.Fatalf("expected 1 March to be in the month and today, got %+v", weeks[0][6])
+	}
+	if got := calendarWeekdays(time.Sunday); got[0] != "Sun" || got[6] != "Sat" {
+		t.Fatalf("unexpected weekday labels %v", got)
+	}
+}
+
+func TestCalendarPageShowsTheRequestedMonth(t *testing.T) {
+	app := NewApp()
+	seedProfile(app)
+	app.mu.Lock()
+	app.items = []Item{{ID: 7, Title: "Road bike", Status: "Bought", DecidedAt: time.Date(2026, time.March, 14, 12, 0, 0, 0, time.Local)}}
+	app.mu.Unlock()
+
+	req := httptest.NewRequest(http.MethodGet, "/calendar?month=2026-03", nil)
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	for _, want := range []string{"March 2026", `href="/calendar?month=2026-02"`, `href="/calendar?month=2026-04"`, `<time class="calendar-date" datetime="2026-03-14">14</time>`, `<a href="/items/7/edit">Road bike</a>`} {
+		if !strings.Contains(rr.Body.String(), want) {
+			t.Fatalf("expected %q in the calendar", want)
+		}
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/calendar?month=March", nil)
+	rr = httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "Please pick a month like") {
+		t.Fatalf("expected an invalid month to be rejected, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,65 @@
+package web
+
+// cardGlyphs is a 5×7 bitmap font for the share card images, so cards render without font files.
+// It covers digits, upper-case letters and common punctuation; lower-case text is drawn in upper case
+// and any other character as "?".
+var cardGlyphs = map[rune][7]string{
+	' ':  {"     ", "     ", "     ", "     ", "     ", "     ", "     "},
+	'0':  {" ### ", "#   #", "#  ##", "# # #", "##  #", "#   #", " ### "},
+	'1':  {"  #  ", " ##  ", "  #  ", "  #  ", "  #  ", "  #  ", " ### "},
+	'2':  {" ### ", "#   #", "    #", "   # ", "  #  ", " #   ", "#####"},
+	'3':  {"#####", "   # ", "  #  ", "   # ", "    #", "#   #", " ### "},
+	'4':  {"   # ", "  ## ", " # # ", "#  # ", "#####", "   # ", "   # "},
+	'5':  {"#####", "#    ", "#### ", "    #", "    #", "#   #", " ### "},
+	'6':  {"  ## ", " #   ", "#    ", "#### ", "#   #", "#   #", " ### "},
+	'7':  {"#####", "    #", "   # ", "  #  ", " #   ", " #   ", " #   "},
+	'8':  {" ### ", "#   #", "#   #", " ### ", "#   #", "#   #", " ### "},
+	'9':  {" ### ", "#   #", "#   #", " ####", "    #", "   # ", " ##  "},
+	'A':  {" ### ", "#   #", "#   #", "#####", "#   #", "#   #", "#   #"},
+	'B':  {"#### ", "#   #", "#   #", "#### ", "#   #", "#   #", "#### "},
+	'C':  {" ### ", "#   #", "#    ", "#    ", "#    ", "#   #", " ### "},
+	'D':  {"###  ", "#  # ", "#   #", "#   #", "#   #", "#  # ", "###  "},
+	'E':  {"#####", "#    ", "#    ", "#### ", "#    ", "#    ", "#####"},
+	'F':  {"#####", "#    ", "#    ", "#### ", "#    ", "#    ", "#    "},
+	'G':  {" ### ", "#   #", "#    ", "# ###", "#   #", "#   #", " ####"},
+	'H':  {"#   #", "#   #", "#   #", "#####", "#   #", "#   #", "#   #"},
+	'I':  {" ### ", "  #  ", "  #  ", "  #  ", "  #  ", "  #  ", " ### "},
+	'J':  {"  ###", "   # ", "   # ", "   # ", "   # ", "#  # ", " ##  "},
+	'K':  {"#   #", "#  # ", "# #  ", "##   ", "# #  ", "#  # ", "#   #"},
+	'L':  {"#    ", "#    ", "#    ", "#    ", "#    ", "#    ", "#####"},
+	'M':  {"#   #", "## ##", "# # #", "# # #", "#   #", "#   #", "#   #"},
+	'N':  {"#   #", "#   #", "##  #", "# # #", "#  ##", "#   #", "#   #"},
+	'O':  {" ### ", "#   #", "#   #", "#   #", "#   #", "#   #", " ### "},
+	'P':  {"#### ", "#   #", "#   #", "#### ", "#    ", "#    ", "#    "},
+	'Q':  {" ### ", "#   #", "#   #", "#   #", "# # #", "#  # ", " ## #"},
+	'R':  {"#### ", "#   #", "#   #", "#### ", "# #  ", "#  # ", "#   #"},
+	'S':  {" ####", "#    ", "#    ", " ### ", "    #", "    #", "#### "},
+	'T':  {"#####", "  #  ", "  #  ", "  #  ", "  #  ", "  #  ", "  #  "},
+	'U':  {"#   #", "#   #", "#   #", "#   #", "#   #", "#   #", " ### "},
+	'V':  {"#   #", "#   #", "#   #", "#   #", "#   #", " # # ", "  #  "},
+	'W':  {"#   #", "#   #", "#   #", "# # #", "# # #", "# # #", " # # "},
+	'X':  {"#   #", "#   #", " # # ", "  #  ", " # # ", "#   #", "#   #"},
+	'Y':  {"#   #", "#   #", " # # ", "  #  ", "  #  ", "  #  ", "  #  "},
+	'Z':  {"#####", "    #", "   # ", "  #  ", " #   ", "#    ", "#####"},
+	'.':  {"     ", "     ", "     ", "     ", "     ", " ##  ", " ##  "},
+	',':  {"     ", "     ", "     ", "     ", " ##  ", "  #  ", " #   "},
+	':':  {"     ", " ##  ", " ##  ", "     ", " ##  ", " ##  ", "     "},
+	';':  {"     ", " ##  ", " ##  ", "     ", " ##  ", "  #  ", " #   "},
+	'-':  {"     ", "     ", "     ", "#####", "     ", "     ", "     "},
+	'+':  {"     ", "  #  ", "  #  ", "#####", "  #  ", "  #  ", "     "},
+	'/':  {"     ", "    #", "   # ", "  #  ", " #   ", "#    ", "     "},
+	'!':  {"  #  ", "  #  ", "  #  ", "  #  ", "  #  ", "     ", "  #  "},
+	'?':  {" ### ", "#   #", "    #", "   # ", "  #  ", "     ", "  #  "},
+	'\'': {"  #  ", "  #  ", " #   ", "     ", "     ", "     ", "     "},
+	'"':  {" # # ", " # # ", " # # ", "     ", "     ", "     ", "     "},
+	'(':  {"   # ", "  #  ", " #   ", " #   ", " #   ", "  #  ", "   # "},
+	')':  {" #   ", "  #  ", "   # ", "   # ", "   # ", "  #  ", " #   "},
+	'%':  {"##   ", "##  #", "   # ", "  #  ", " #   ", "#  ##", "   ##"},
+	'#':  {" # # ", " # # ", "#####", " # # ", "#####", " # # ", " # # "},
+	'&':  {" ##  ", "#  # ", "# #  ", " #   ", "# # #", "#  # ", " ## #"},
+	'$':  {"  #  ", " ####", "# #  ", " ### ", "  # #", "#### ", "  #  "},
+	'*':  {"     ", "  #  ", "# # #", " ### ", "# # #", "  #  ", "     "},
+	'=':  {"     ", "     ", "#####", "     ", "#####", "     ", "     "},
+	'_':  {"     ", "     ", "     ", "     ", "     ", "     ", "#####"},
+	'@':  {" ### ", "#   #", "    #", " ## #", "# # #", "# # #", " ### "},
+}
@@ -0,0 +1,102 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+// maxCoalescedTitles bounds the titles listed in a combined message; the rest are counted.
+const maxCoalescedTitles = 5
+
+// SetNotificationCoalesceWindow holds ready notifications back for window, so items that become ready
+// within it are announced in one message. Items promoted in the same run are combined regardless;
+// zero or less keeps that as the only coalescing.
+func (a *App) SetNotificationCoalesceWindow(window time.Duration) {
+	if window < 0 {
+		window = 0
+	}
+	a.mu.Lock()
+	a.coalesceWindow = window
+	a.mu.Unlock()
+}
+
+// coalescible reports whether an effect announces ready items to a person, so several of them read
+// better as one message. Home Assistant, the item hook and notifier plugins get one structured event
+// per item.
+func coalescible(effect string, e domain.Event) bool {
+	return e.Type == domain.EventItemPromoted && (effect == effectNtfy || effect == effectWebPush || strings.HasPrefix(effect, effectNtfyTopicPrefix))
+}
+
+// readyMessage announces one or more items that became ready to buy.
+func readyMessage(items []Item) string {
+	if len(items) == 1 {
+		return fmt.Sprintf("%s is now ready to buy.", items[0].Title)
+	}
+	titles := make([]string, 0, maxCoalescedTitles+1)
+	for i, item := range items {
+		if i == maxCoalescedTitles {
+			titles = append(titles, fmt.Sprintf("and %d more", len(items)-i))
+			break
+		}
+		titles = append(titles, item.Title)
+	}
+	return fmt.Sprintf("%d items are ready: %s.", len(items), strings.Join(titles, ", "))
+}
+
+// outboxBatch is a run of outbox entries delivered together: one entry, or coalescible entries of the
+// same profile and effect.
+type outboxBatch []outboxEntry
+
+// batchOutboxEntries groups coalescible entries by profile and effect, keeping the order of first appearance.
+func batchOutboxEntries(entries []outboxEntry) []outboxBatch {
+	var batches []outboxBatch
+	open := map[string]int{}
+	for _, entry := range entries {
+		if !coalescible(entry.Effect, entry.Event) {
+			batches = append(batches, outboxBatch{entry})
+			continue
+		}
+		key := entry.UserID + "\x00" + entry.Effect
+		if i, ok := open[key]; ok {
+			batches[i] = append(batches[i], entry)
+			continue
+		}
+		open[key] = len(batches)
+		batches = append(batches, outboxBatch{entry})
+	}
+	return batches
+}
+
+// deliverBatchLocked performs the effect of a batch for the active profile, as one message for several
+// ready items. Each item gets its own delivery log entry.
+func (a *App) deliverBatchLocked(batch outboxBatch) error {
+	if len(batch) == 1 {
+		return a.deliverEffectLocked(batch[0].Effect, batch[0].Event, batch[0].ID)
+	}
+	items := make([]Item, len(batch))
+	for i, entry := range batch {
+		items[i] = entry.Event.Item
+	}
+	effect := batch[0].Effect
+	var code int
+	var err error
+	switch effect {
+	case effectNtfy:
+		code, err = a.sendReadyNtfyLocked(items...)
+	case effectWebPush:
+		code, err = a.sendWebPushLocked(items...)
+	default:
+		code, err = a.sendTopicNtfyLocked(strings.TrimPrefix(effect, effectNtfyTopicPrefix), items...)
+	}
+	if errors.Is(err, errChannelNotConfigured) {
+		return nil
+	}
+	for _, item := range items {
+		a.recordDeliveryLocked(notificationItemReady, effect, item, code, err)
+	}
+	return err
+}
@@ -0,0 +1,105 @@
+package web
+
+import (
+	"errors"
+	"strings"
+
+	"mvpapp/internal/domain"
+)
+
+// defaultCurrencyCode is used for new profiles and for stored values that cannot be mapped.
+const defaultCurrencyCode = "EUR"
+
+// currencyInfo describes a supported ISO 4217 currency and how amounts in it are displayed.
+type currencyInfo struct {
+	Code     string
+	Symbol   string
+	Name     string
+	Decimals int
+}
+
+// supportedCurrencies is the curated list offered in profile settings. When several
+// currencies share a symbol, the first entry wins for symbol lookups.
+var supportedCurrencies = []currencyInfo{
+	{Code: "EUR", Symbol: "€", Name: "Euro", Decimals: 2},
+	{Code: "USD", Symbol: "$", Name: "US dollar", Decimals: 2},
+	{Code: "GBP", Symbol: "£", Name: "British pound", Decimals: 2},
+	{Code: "CHF", Symbol: "CHF", Name: "Swiss franc", Decimals: 2},
+	{Code: "JPY", Symbol: "¥", Name: "Japanese yen", Decimals: 0},
+	{Code: "SEK", Symbol: "kr", Name: "Swedish krona", Decimals: 2},
+	{Code: "NOK", Symbol: "kr", Name: "Norwegian krone", Decimals: 2},
+	{Code: "DKK", Symbol: "kr", Name: "Danish krone", Decimals: 2},
+	{Code: "PLN", Symbol: "zł", Name: "Polish złoty", Decimals: 2},
+	{Code: "CZK", Symbol: "Kč", Name: "Czech koruna", Decimals: 2},
+	{Code: "HUF", Symbol: "Ft", Name: "Hungarian forint", Decimals: 2},
+	{Code: "CAD", Symbol: "CA$", Name: "Canadian dollar", Decimals: 2},
+	{Code: "AUD", Symbol: "A$", Name: "Australian dollar", Decimals: 2},
+	{Code: "INR", Symbol: "₹", Name: "Indian rupee", Decimals: 2},
+}
+
+// currencyAliases maps legacy free-text spellings that are neither a code nor a symbol.
+var currencyAliases = map[string]string{
+	"fr.": "CHF",
+	"sfr": "CHF",
+}
+
+// lookupCurrency resolves an ISO code, display symbol or known alias, ignoring case and surrounding spaces.
+func lookupCurrency(raw string) (currencyInfo, bool) {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return currencyInfo{}, false
+	}
+	for _, info := range supportedCurrencies {
+		if strings.EqualFold(info.Code, trimmed) {
+			return info, true
+		}
+	}
+	for _, info := range supportedCurrencies {
+		if strings.EqualFold(info.Symbol, trimmed) {
+			return info, true
+		}
+	}
+	if code, ok := currencyAliases[strings.ToLower(trimmed)]; ok {
+		return lookupCurrency(code)
+	}
+	return currencyInfo{}, false
+}
+
+// parseCurrency validates profile input and returns its ISO code. Empty input selects the default.
+func parseCurrency(raw string) (string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return defaultCurrencyCode, nil
+	}
+	info, ok := lookupCurrency(raw)
+	if !ok {
+		return "", errors.New("Please choose a supported currency.")
+	}
+	return info.Code, nil
+}
+
+// normalizeCurrency maps any stored or submitted currency to its ISO code, falling back to the default.
+func normalizeCurrency(raw string) string {
+	if info, ok := lookupCurrency(raw); ok {
+		return info.Code
+	}
+	return defaultCurrencyCode
+}
+
+// profileCurrencyOrDefault returns the display symbol for a profile currency.
+func profileCurrencyOrDefault(raw string) string {
+	info, _ := lookupCurrency(normalizeCurrency(raw))
+	return info.Symbol
+}
+
+// currencyCode maps a profile currency to its ISO 4217 code, or "" when it cannot be determined.
+func currencyCode(raw string) string {
+	if info, ok := lookupCurrency(raw); ok {
+		return info.Code
+	}
+	return ""
+}
+
+func formatMoney(amount domain.Money, currency string) string {
+	info, _ := lookupCurrency(normalizeCurrency(currency))
+	return info.Symbol + " " + amount.Format(info.Decimals)
+}
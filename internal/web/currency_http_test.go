@@ -0,0 +1,35 @@
+package web_test
+
+import (
+	"net/url"
+	"testing"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestPricesAreReadInTheProfileNumberFormat(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{Profiles: []webtest.Profile{{Name: "Alex", HourlyWage: "25"}}})
+	alex := h.As("Alex")
+
+	alex.PostForm("/items/new", url.Values{"title": {"Camera"}, "price": {"€ 1.299,99"}, "wait_preset": {"24h"}}).ExpectRedirect("/")
+	alex.PostForm("/items/new", url.Values{"title": {"Lens"}, "price": {"1.299"}, "wait_preset": {"24h"}}).ExpectRedirect("/")
+
+	alex.PostForm("/settings/profile", url.Values{"profile_name": {"Alex"}, "hourly_wage": {"25"}, "number_format": {"comma"}}).
+		ExpectRedirect("/settings/profile?saved=1")
+	alex.Get("/settings/profile").ExpectContains(`<option value="comma" selected>`)
+	alex.PostForm("/items/new", url.Values{"title": {"Tripod"}, "price": {"1.299"}, "wait_preset": {"24h"}}).ExpectRedirect("/")
+
+	for title, want := range map[string]int64{"Camera": 129999, "Lens": 130, "Tripod": 129900} {
+		var cents int64
+		if err := h.DB.QueryRow(`SELECT price_cents FROM items WHERE title = ?`, title).Scan(&cents); err != nil {
+			t.Fatalf("load %s: %v", title, err)
+		}
+		if cents != want {
+			t.Errorf("%s: expected %d cents, got %d", title, want, cents)
+		}
+	}
+	if got := h.Item("Alex", "Camera").Price; got != "€ 1.299,99" {
+		t.Fatalf("expected the price to be kept as entered, got %q", got)
+	}
+	alex.Get("/").ExpectContains("€ 1299.99").ExpectNotContains("€ € 1.299,99")
+}
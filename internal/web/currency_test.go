@@ -0,0 +1,122 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeCurrencyMapsSymbolsAndCaseToISOCode(t *testing.T) {
+	tests := map[string]string{"€": "EUR", "EUR": "EUR", "eur": "EUR", " £ ": "GBP", "Fr.": "CHF", "kr": "SEK", "": "EUR", "euros": "EUR"}
+	for input, want := range tests {
+		if got := normalizeCurrency(input); got != want {
+			t.Fatalf("normalizeCurrency(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestParseCurrencyRejectsUnknownValues(t *testing.T) {
+	if code, err := parseCurrency("usd"); err != nil || code != "USD" {
+		t.Fatalf("expected usd to parse as USD, got %q, %v", code, err)
+	}
+	if _, err := parseCurrency("doubloons"); err == nil {
+		t.Fatalf("expected unknown currency to be rejected")
+	}
+}
+
+func TestFormatMoneyUsesSymbolAndCurrencyDecimals(t *testing.T) {
+	if got := formatMoney(1250, "eur"); got != "€ 12.50" {
+		t.Fatalf("unexpected EUR formatting %q", got)
+	}
+	if got := formatMoney(125000, "JPY"); got != "¥ 1250" {
+		t.Fatalf("unexpected JPY formatting %q", got)
+	}
+}
+
+func TestProfileSaveStoresISOCodeAndRejectsUnknownCurrency(t *testing.T) {
+	app := NewApp()
+
+	form := url.Values{}
+	form.Set("hourly_wage", "25")
+	form.Set("default_wait_preset", "24h")
+	form.Set("currency", "€")
+	req := httptest.NewRequest(http.MethodPost, "/settings/profile", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected redirect, got %d", rr.Code)
+	}
+	app.mu.RLock()
+	stored := app.currency
+	app.mu.RUnlock()
+	if stored != "EUR" {
+		t.Fatalf("expected symbol to be stored as EUR, got %q", stored)
+	}
+
+	form.Set("currency", "doubloons")
+	req = httptest.NewRequest(http.MethodPost, "/settings/profile", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr = httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), "Please choose a supported currency.") {
+		t.Fatalf("expected currency validation error")
+	}
+}
+
+func TestInitSchemaMigratesFreeTextCurrencies(t *testing.T) {
+	app, cleanup := newSQLiteTestApp(t)
+	defer cleanup()
+
+	for userID, currency := range map[string]string{"Alex": "€", "Sam": "chf", "Kim": "euros"} {
+		if _, err := app.db.Exec(`INSERT INTO profiles(user_id, hourly_wage, currency, updated_at) VALUES (?, '25', ?, '')`, userID, currency); err != nil {
+			t.Fatalf("insert profile: %v", err)
+		}
+	}
+	if err := initSchema(app.db.DB); err != nil {
+		t.Fatalf("rerun schema: %v", err)
+	}
+
+	for userID, want := range map[string]string{"Alex": "EUR", "Sam": "CHF", "Kim": "EUR"} {
+		var got string
+		if err := app.db.QueryRow(`SELECT currency FROM profiles WHERE user_id = ?`, userID).Scan(&got); err != nil {
+			t.Fatalf("load currency: %v", err)
+		}
+		if got != want {
+			t.Fatalf("expected %s currency %q, got %q", userID, want, got)
+		}
+	}
+}
+
+func TestInitSchemaMigratesDecimalPricesToCents(t *testing.T) {
+	app, cleanup := newSQLiteTestApp(t)
+	defer cleanup()
+
+	if _, err := app.db.Exec(`INSERT INTO profiles(user_id, hourly_wage, currency, approval_threshold, updated_at) VALUES ('Alex', '25', 'EUR', 149.99, '')`); err != nil {
+		t.Fatalf("insert profile: %v", err)
+	}
+	if _, err := app.db.Exec(`INSERT INTO items(user_id, title, price, price_value, has_price_value, status, wait_preset, purchase_allowed_at, created_at) VALUES ('Alex', 'Lamp', '19.99', 19.99, 1, 'Waiting', '24h', '', '')`); err != nil {
+		t.Fatalf("insert item: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := initSchema(app.db.DB); err != nil {
+			t.Fatalf("rerun schema: %v", err)
+		}
+	}
+
+	var priceCents, thresholdCents int64
+	if err := app.db.QueryRow(`SELECT price_cents FROM items WHERE title = 'Lamp'`).Scan(&priceCents); err != nil {
+		t.Fatalf("load price: %v", err)
+	}
+	if err := app.db.QueryRow(`SELECT approval_threshold_cents FROM profiles WHERE user_id = 'Alex'`).Scan(&thresholdCents); err != nil {
+		t.Fatalf("load threshold: %v", err)
+	}
+	if priceCents != 1999 || thresholdCents != 14999 {
+		t.Fatalf("expected 1999 and 14999 cents, got %d and %d", priceCents, thresholdCents)
+	}
+}
@@ -0,0 +1,138 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// The notifications in the delivery log.
+const (
+	notificationItemReady   = "ready to buy"
+	notificationApproval    = "approval request"
+	notificationMilestone   = "hours goal milestone"
+	notificationLeaderboard = "leaderboard digest"
+	notificationReadyDigest = "weekly ready digest"
+
+	// deliveryLogPageSize is how many attempts the delivery log page shows.
+	deliveryLogPageSize = 50
+	// deliveryLogRetention is how long attempts are kept; maintenance purges older ones.
+	deliveryLogRetention = 90 * 24 * time.Hour
+)
+
+// errChannelNotConfigured means a notification channel is not set up for the profile, so nothing was
+// attempted and nothing is logged.
+var errChannelNotConfigured = errors.New("notification channel not configured")
+
+// deliveryAttempt is one try to send a notification, successful or not.
+type deliveryAttempt struct {
+	Notification string
+	Channel      string
+	ItemID       int
+	ItemTitle    string
+	AttemptedAt  time.Time
+	// StatusCode is the HTTP status of the receiving service, 0 when it did not answer or is not HTTP.
+	StatusCode int
+	Error      string
+	DryRun     bool
+}
+
+type deliveryLogViewData struct {
+	Title           string
+	CurrentPath     string
+	ContentTemplate string
+	ScriptTemplate  string
+	ActiveProfile   string
+	Attempts        []deliveryAttempt
+	// Unavailable is set for apps without a database, which keep no log.
+	Unavailable bool
+	Error       string
+}
+
+// recordDeliveryLocked logs an attempt of the active profile to send a notification about item on
+// channel. Failures are logged because the log must not hold back the notification itself.
+func (a *App) recordDeliveryLocked(notification, channel string, item Item, statusCode int, deliveryErr error) {
+	a.recordDeliveryForLocked(a.currentUserIDLocked(), notification, channel, item, statusCode, deliveryErr)
+}
+
+// recordDeliveryForLocked logs an attempt to send userID a notification, for notifications that go to
+// other profiles than the active one.
+func (a *App) recordDeliveryForLocked(userID, notification, channel string, item Item, statusCode int, deliveryErr error) {
+	if a.db == nil {
+		return
+	}
+	item = maskPrivate(item)
+	attempt := deliveryAttempt{
+		Notification: notification,
+		Channel:      channel,
+		ItemID:       item.ID,
+		ItemTitle:    item.Title,
+		AttemptedAt:  time.Now(),
+		StatusCode:   statusCode,
+		DryRun:       a.notifyDryRun || a.notificationsDryRun,
+	}
+	if deliveryErr != nil {
+		attempt.Error = deliveryErr.Error()
+	}
+	_, err := a.db.Exec(`INSERT INTO notification_log(user_id, notification, channel, item_id, item_title, attempted_at, status_code, error, dry_run) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		userID, attempt.Notification, attempt.Channel, attempt.ItemID, attempt.ItemTitle, attempt.AttemptedAt.Format(time.RFC3339Nano), attempt.StatusCode, attempt.Error, boolToInt(attempt.DryRun))
+	if err != nil {
+		log.Printf("db error while recording notification delivery: %v", err)
+	}
+}
+
+// deliveryLogLocked returns the most recent delivery attempts of a profile, newest first.
+func (a *App) deliveryLogLocked(userID string, limit int) ([]deliveryAttempt, error) {
+	rows, err := a.db.Query(`SELECT notification, channel, item_id, item_title, attempted_at, status_code, error, dry_run FROM notification_log WHERE user_id = ? ORDER BY id DESC LIMIT ?`, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("load delivery log: %w", err)
+	}
+	defer rows.Close()
+
+	var attempts []deliveryAttempt
+	for rows.Next() {
+		var attempt deliveryAttempt
+		var attemptedAt string
+		if err := rows.Scan(&attempt.Notification, &attempt.Channel, &attempt.ItemID, &attempt.ItemTitle, &attemptedAt, &attempt.StatusCode, &attempt.Error, &attempt.DryRun); err != nil {
+			return nil, fmt.Errorf("scan delivery attempt: %w", err)
+		}
+		attempt.AttemptedAt, _ = time.Parse(time.RFC3339Nano, attemptedAt)
+		attempts = append(attempts, attempt)
+	}
+	return attempts, rows.Err()
+}
+
+// purgeDeliveryLogLocked deletes attempts older than deliveryLogRetention.
+func (a *App) purgeDeliveryLogLocked(now time.Time) (int64, error) {
+	res, err := a.db.Exec(`DELETE FROM notification_log WHERE attempted_at < ?`, now.Add(-deliveryLogRetention).Format(time.RFC3339Nano))
+	if err != nil {
+		return 0, fmt.Errorf("purge delivery log: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+func (a *App) deliveryLog(w http.ResponseWriter, r *http.Request) {
+	data := deliveryLogViewData{
+		Title:           "Notification log",
+		CurrentPath:     "/settings/notification-log",
+		ContentTemplate: "delivery_log_content",
+	}
+
+	a.mu.LockContext(r.Context())
+	data.ActiveProfile = a.currentUserIDLocked()
+	var err error
+	if a.db == nil {
+		data.Unavailable = true
+	} else {
+		data.Attempts, err = a.deliveryLogLocked(data.ActiveProfile, deliveryLogPageSize)
+	}
+	a.mu.Unlock()
+	if err != nil {
+		log.Printf("db error while loading delivery log: %v", err)
+		data.Error = "Could not load the notification log."
+	}
+
+	renderTemplate(w, a.templates, "layout", data)
+}
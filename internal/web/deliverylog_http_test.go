@@ -0,0 +1,70 @@
+package web_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestDeliveryLogRecordsEveryNotificationAttempt(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}},
+		Items: []webtest.Item{
+			{Profile: "Alex", Title: "Headphones", PurchaseAllowedAt: time.Now().Add(-time.Minute), CreatedAt: time.Now().Add(-24 * time.Hour)},
+		},
+	})
+	ntfyStatus := http.StatusServiceUnavailable
+	ntfy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(ntfyStatus)
+	}))
+	defer ntfy.Close()
+	if _, err := h.DB.Exec(`UPDATE profiles SET ntfy_endpoint = ?, ntfy_topic = 'alex'`, ntfy.URL); err != nil {
+		t.Fatalf("set ntfy settings: %v", err)
+	}
+	alex := h.As("Alex")
+
+	alex.Get("/").ExpectStatus(http.StatusOK)
+	h.App.StartBackgroundOutbox(time.Hour)
+	h.App.RunJob("outbox")
+
+	// The failed attempt is retried by the outbox, this time successfully.
+	ntfyStatus = http.StatusOK
+	if _, err := h.DB.Exec(`UPDATE outbox SET next_attempt_at = ''`); err != nil {
+		t.Fatalf("make the retry due: %v", err)
+	}
+	h.App.RunJob("outbox")
+
+	type attempt struct {
+		Notification, Channel, ItemTitle, Error string
+		ItemID, StatusCode                      int
+	}
+	rows, err := h.DB.Query(`SELECT notification, channel, item_id, item_title, status_code, error FROM notification_log WHERE user_id = 'Alex' ORDER BY id`)
+	if err != nil {
+		t.Fatalf("load delivery log: %v", err)
+	}
+	defer rows.Close()
+	var attempts []attempt
+	for rows.Next() {
+		var a attempt
+		if err := rows.Scan(&a.Notification, &a.Channel, &a.ItemID, &a.ItemTitle, &a.StatusCode, &a.Error); err != nil {
+			t.Fatalf("scan delivery log: %v", err)
+		}
+		attempts = append(attempts, a)
+	}
+	if len(attempts) != 2 {
+		t.Fatalf("expected two ntfy attempts, got %+v", attempts)
+	}
+	if got := attempts[0]; got.Channel != "ntfy" || got.ItemID != h.Item("Alex", "Headphones").ID || got.StatusCode != http.StatusServiceUnavailable || !strings.Contains(got.Error, "ntfy returned 503") {
+		t.Fatalf("unexpected failed attempt %+v", got)
+	}
+	if got := attempts[1]; got.StatusCode != http.StatusOK || got.Error != "" || got.Notification != "ready to buy" || got.ItemTitle != "Headphones" {
+		t.Fatalf("unexpected successful attempt %+v", got)
+	}
+
+	alex.Get("/settings/notification-log").ExpectStatus(http.StatusOK).
+		ExpectContains("ready to buy: Headphones", "Failed: ntfy returned 503", "Sent", "<td>200</td>")
+}
@@ -0,0 +1,35 @@
+package web
+
+import (
+	"path/filepath"
+	"testing"
+
+	"mvpapp/internal/domain"
+)
+
+func TestDeliveryLogSkipsChannelsThatAreNotSetUp(t *testing.T) {
+	app, err := NewAppWithSQLite(filepath.Join(t.TempDir(), "test.sqlite"))
+	if err != nil {
+		t.Fatalf("new sqlite app: %v", err)
+	}
+	defer app.Close()
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	app.activeUserID = "Bea"
+	item := Item{ID: 3, Title: "Lamp", Status: "Ready to buy"}
+	if err := app.deliverEffectLocked(effectNtfy, domain.Event{Type: domain.EventItemPromoted, Profile: "Bea", Item: item}, 0); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	app.notifyDryRun, app.haWebhookURL = true, "http://ha.invalid/api/webhook/x"
+	if err := app.deliverEffectLocked(effectHomeAssistant, domain.Event{Type: domain.EventItemPromoted, Profile: "Bea", Item: item}, 0); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+
+	attempts, err := app.deliveryLogLocked("Bea", deliveryLogPageSize)
+	if err != nil {
+		t.Fatalf("load delivery log: %v", err)
+	}
+	if len(attempts) != 1 || attempts[0].Channel != effectHomeAssistant || !attempts[0].DryRun {
+		t.Fatalf("expected only the dry-run Home Assistant attempt, got %+v", attempts)
+	}
+}
@@ -0,0 +1,184 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+// demoProfileName is the throwaway profile that demo mode seeds and resets.
+const demoProfileName = "Demo"
+
+// demoItem is one seeded item. Offsets are relative to the time of the reset so the demo always looks current.
+type demoItem struct {
+	Title        string
+	Price        string
+	Tags         string
+	Note         string
+	Status       domain.Status
+	WaitPreset   string
+	CreatedAgo   time.Duration
+	AllowedIn    time.Duration
+	DecidedAgo   time.Duration
+	UrgeScore    int
+	Satisfaction string
+}
+
+var demoItems = []demoItem{
+	{Title: "Noise-cancelling headphones", Price: "249.00", Tags: "Tech", Status: domain.StatusWaiting, WaitPreset: "7d", CreatedAgo: 2 * 24 * time.Hour, AllowedIn: 5 * 24 * time.Hour, UrgeScore: 4},
+	{Title: "Espresso machine", Price: "389.90", Tags: "Home", Note: "Compare with the model at the store downtown.", Status: domain.StatusWaiting, WaitPreset: "30d", CreatedAgo: 9 * 24 * time.Hour, AllowedIn: 21 * 24 * time.Hour, UrgeScore: 3},
+	{Title: "Trail running shoes", Price: "129.95", Tags: "Sports", Status: domain.StatusReady, WaitPreset: "24h", CreatedAgo: 3 * 24 * time.Hour, AllowedIn: -2 * 24 * time.Hour, UrgeScore: 2},
+	{Title: "Mechanical keyboard", Price: "159.00", Tags: "Tech", Status: domain.StatusResearching, WaitPreset: "7d", CreatedAgo: 24 * time.Hour},
+	{Title: "Smartwatch", Price: "299.00", Tags: "Tech", Status: domain.StatusSkipped, WaitPreset: "30d", CreatedAgo: 75 * 24 * time.Hour, DecidedAgo: 44 * 24 * time.Hour, UrgeScore: 5},
+	{Title: "Designer jacket", Price: "210.00", Tags: "Fashion", Status: domain.StatusSkipped, WaitPreset: "7d", CreatedAgo: 40 * 24 * time.Hour, DecidedAgo: 33 * 24 * time.Hour, UrgeScore: 4},
+	{Title: "Board game", Price: "49.99", Tags: "Gaming", Status: domain.StatusSkipped, WaitPreset: "24h", CreatedAgo: 12 * 24 * time.Hour, DecidedAgo: 11 * 24 * time.Hour, UrgeScore: 2},
+	{Title: "Cast iron pan", Price: "59.90", Tags: "Home", Status: domain.StatusBought, WaitPreset: "7d", CreatedAgo: 60 * 24 * time.Hour, DecidedAgo: 52 * 24 * time.Hour, UrgeScore: 3, Satisfaction: satisfactionWorthIt},
+	{Title: "Fitness tracker", Price: "89.00", Tags: "Sports, Tech", Status: domain.StatusBought, WaitPreset: "24h", CreatedAgo: 20 * 24 * time.Hour, DecidedAgo: 19 * 24 * time.Hour, UrgeScore: 5, Satisfaction: satisfactionRegret},
+	{Title: "Bookshelf", Price: "135.00", Tags: "Home", Status: domain.StatusBought, WaitPreset: "30d", CreatedAgo: 45 * 24 * time.Hour, DecidedAgo: 14 * 24 * time.Hour, UrgeScore: 2},
+}
+
+// EnableDemoMode seeds the demo profile and resets it every interval, discarding whatever visitors changed.
+// The demo is reachable at /demo. Demo mode needs the SQLite store.
+func (a *App) EnableDemoMode(interval time.Duration) error {
+	if a.db == nil {
+		return errors.New("demo mode needs a database")
+	}
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	a.mu.Lock()
+	a.demoResetInterval = interval
+	err := a.resetDemoProfileLocked(time.Now())
+	a.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	a.registerJob("demo-reset", "@every "+interval.String(), 0, false, func(a *App, now time.Time) error {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		return a.resetDemoProfileLocked(now)
+	})
+	return nil
+}
+
+func (a *App) demoModeLocked() bool {
+	return a.demoResetInterval > 0
+}
+
+func (a *App) demoAvailable() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.demoModeLocked()
+}
+
+// resetDemoProfileLocked replaces the demo profile with freshly seeded data and then reloads the profile
+// that was active before, so a reset does not switch anyone away from their own data.
+func (a *App) resetDemoProfileLocked(now time.Time) error {
+	previousProfileName := a.activeUserID
+	if err := a.deleteProfileLocked(demoProfileName); err != nil {
+		return err
+	}
+
+	a.activeUserID = demoProfileName
+	err := a.seedDemoProfileLocked(now)
+	if previousProfileName != demoProfileName {
+		a.activeUserID = previousProfileName
+		if previousProfileName == "" {
+			a.resetActiveProfileLocked()
+		} else if loadErr := a.loadStateFromDB(previousProfileName); err == nil {
+			err = loadErr
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("seed demo profile: %w", err)
+	}
+	return nil
+}
+
+func (a *App) seedDemoProfileLocked(now time.Time) error {
+	if err := a.loadStateFromDB(demoProfileName); err != nil {
+		return err
+	}
+	a.hourlyWage = "28"
+	a.currency = "EUR"
+	a.defaultWaitPreset = "7d"
+	// The seeded items use the built-in tags, whatever starter tags are configured.
+	a.tagCatalog = append([]string(nil), defaultTagOptions...)
+	if err := a.persistProfileLocked(); err != nil {
+		return err
+	}
+
+	for _, seed := range demoItems {
+		item := Item{
+			Title:        seed.Title,
+			Price:        seed.Price,
+			Note:         seed.Note,
+			Tags:         seed.Tags,
+			Status:       seed.Status,
+			WaitPreset:   seed.WaitPreset,
+			CreatedAt:    now.Add(-seed.CreatedAgo),
+			UrgeScore:    seed.UrgeScore,
+			Satisfaction: seed.Satisfaction,
+		}
+		if price, ok := parsePrice(item.Price); ok {
+			item.PriceCents = price
+			item.HasPriceValue = true
+		}
+		if seed.Status != domain.StatusResearching {
+			item.PurchaseAllowedAt = now.Add(seed.AllowedIn)
+		}
+		if seed.DecidedAgo > 0 {
+			item.DecidedAt = now.Add(-seed.DecidedAgo)
+			item.PurchaseAllowedAt = item.DecidedAt
+		}
+		// Items that are already decided or ready never notify, like items that were promoted while the server ran.
+		item.NtfyAttempted = seed.Status != domain.StatusWaiting && seed.Status != domain.StatusResearching
+
+		if err := a.insertItemLocked(&item); err != nil {
+			return err
+		}
+		a.items = append([]Item{item}, a.items...)
+
+		if err := a.insertHistoryLocked(item.ID, "created", "", item.CreatedAt); err != nil {
+			return err
+		}
+		if !item.DecidedAt.IsZero() {
+			if err := a.insertHistoryLocked(item.ID, strings.ToLower(string(item.Status)), "", item.DecidedAt); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// demoIntervalLabel describes the reset interval for the demo banner, e.g. "hour" or "30 minutes".
+func demoIntervalLabel(interval time.Duration) string {
+	switch {
+	case interval == time.Hour:
+		return "hour"
+	case interval%time.Hour == 0:
+		return fmt.Sprintf("%d hours", interval/time.Hour)
+	case interval == time.Minute:
+		return "minute"
+	case interval%time.Minute == 0:
+		return fmt.Sprintf("%d minutes", interval/time.Minute)
+	}
+	return interval.String()
+}
+
+// openDemo switches the visitor to the demo profile.
+func (a *App) openDemo(w http.ResponseWriter, r *http.Request) {
+	if !a.demoAvailable() {
+		http.NotFound(w, r)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{Name: "active_profile", Value: demoProfileName, Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode})
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
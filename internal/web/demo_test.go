@@ -0,0 +1,105 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDemoModeSeedsAndResetsTheDemoProfile(t *testing.T) {
+	app, cleanup := newSQLiteTestApp(t)
+	defer cleanup()
+
+	app.mu.Lock()
+	app.activeUserID = "Alex"
+	app.hourlyWage = "40"
+	if err := app.persistProfileLocked(); err != nil {
+		app.mu.Unlock()
+		t.Fatalf("persist profile: %v", err)
+	}
+	app.mu.Unlock()
+
+	if err := app.EnableDemoMode(time.Hour); err != nil {
+		t.Fatalf("enable demo mode: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/demo", nil)
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/" {
+		t.Fatalf("expected /demo to redirect to the dashboard, got %d %q", rr.Code, rr.Header().Get("Location"))
+	}
+
+	homeReq := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, c := range rr.Result().Cookies() {
+		homeReq.AddCookie(c)
+	}
+	homeRR := httptest.NewRecorder()
+	app.Handler().ServeHTTP(homeRR, homeReq)
+	body := homeRR.Body.String()
+	if !strings.Contains(body, "Noise-cancelling headphones") || !strings.Contains(body, "reset every hour") {
+		t.Fatalf("expected seeded demo items and the reset banner, got %s", body)
+	}
+
+	app.mu.Lock()
+	if err := app.activateProfileLocked(demoProfileName); err != nil {
+		app.mu.Unlock()
+		t.Fatalf("load demo profile: %v", err)
+	}
+	if len(app.items) != len(demoItems) {
+		app.mu.Unlock()
+		t.Fatalf("expected %d demo items, got %d", len(demoItems), len(app.items))
+	}
+	var readyID, boughtID int
+	for _, item := range app.items {
+		switch item.Status {
+		case "Ready to buy":
+			readyID = item.ID
+		case "Bought":
+			boughtID = item.ID
+		}
+	}
+	if _, err := app.itemServiceLocked().Decide(readyID, "Skipped"); err != nil {
+		app.mu.Unlock()
+		t.Fatalf("decide demo item: %v", err)
+	}
+	if err := app.deleteItemLocked(boughtID); err != nil {
+		app.mu.Unlock()
+		t.Fatalf("delete demo item: %v", err)
+	}
+
+	// A reset while someone else is active keeps their profile loaded.
+	app.activeUserID = "Alex"
+	if err := app.loadStateFromDB("Alex"); err != nil {
+		app.mu.Unlock()
+		t.Fatalf("load Alex: %v", err)
+	}
+	if err := app.resetDemoProfileLocked(time.Now()); err != nil {
+		app.mu.Unlock()
+		t.Fatalf("reset demo profile: %v", err)
+	}
+	if app.activeUserID != "Alex" || app.hourlyWage != "40" {
+		app.mu.Unlock()
+		t.Fatalf("expected Alex to stay active after the reset, got %q with wage %q", app.activeUserID, app.hourlyWage)
+	}
+	app.mu.Unlock()
+
+	var count, skipped int
+	if err := app.db.QueryRow(`SELECT COUNT(*), COALESCE(SUM(status = 'Skipped'), 0) FROM items WHERE user_id = ?`, demoProfileName).Scan(&count, &skipped); err != nil {
+		t.Fatalf("count demo items: %v", err)
+	}
+	if count != len(demoItems) || skipped != 3 {
+		t.Fatalf("expected the reset to restore %d items with 3 skipped, got %d with %d skipped", len(demoItems), count, skipped)
+	}
+}
+
+func TestDemoRouteIsHiddenWithoutDemoMode(t *testing.T) {
+	app := NewApp()
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/demo", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 without demo mode, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,29 @@
+package web
+
+import (
+	"net/http"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+// subscribeEventHandlers wires the side effects of item and profile changes to the event bus, so
+// handlers only change state and publish. Events are published while a.mu is held for writing,
+// so subscribers follow the *Locked conventions.
+func (a *App) subscribeEventHandlers() {
+	a.events.Subscribe(domain.EventItemPromoted, a.deliverItemEffectsLocked)
+	a.events.Subscribe(domain.EventItemDecided, a.deliverItemEffectsLocked)
+	a.events.Subscribe(domain.EventItemDecided, func(e domain.Event) {
+		if e.Item.Status == domain.StatusSkipped {
+			a.celebrateHoursGoalLocked(time.Now())
+		}
+	})
+	a.events.Subscribe(domain.EventProfileUpdated, func(e domain.Event) {
+		a.recordAuditFromLocked(e.Profile, auditSettingsChanged, e.Detail, e.RemoteAddr)
+	})
+}
+
+// publishProfileUpdatedLocked announces that the active profile saved the named settings.
+func (a *App) publishProfileUpdatedLocked(detail string, r *http.Request) {
+	a.events.Publish(domain.Event{Type: domain.EventProfileUpdated, Profile: a.currentUserIDLocked(), Detail: detail, RemoteAddr: clientAddr(r)})
+}
@@ -0,0 +1,37 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"mvpapp/internal/domain"
+)
+
+func TestHandlersPublishItemAndProfileEvents(t *testing.T) {
+	app := NewApp()
+	seedProfile(app)
+	var got []string
+	for _, eventType := range []domain.EventType{domain.EventItemCreated, domain.EventProfileUpdated} {
+		app.events.Subscribe(eventType, func(e domain.Event) { got = append(got, string(e.Type)+" "+e.Item.Title+e.Detail) })
+	}
+
+	post := func(path string, form url.Values) {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		app.Handler().ServeHTTP(rr, req)
+		if rr.Code != http.StatusSeeOther {
+			t.Fatalf("POST %s: expected 303, got %d", path, rr.Code)
+		}
+	}
+	post("/items/new", url.Values{"title": {"Headphones"}})
+	post("/settings/data", url.Values{"retention_months": {"6"}})
+
+	if want := []string{"item.created Headphones", "profile.updated data retention"}; !slices.Equal(got, want) {
+		t.Fatalf("expected events %q, got %q", want, got)
+	}
+}
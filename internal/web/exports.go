@@ -0,0 +1,475 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+type exportSettingsViewData struct {
+	Title           string
+	CurrentPath     string
+	ContentTemplate string
+	ScriptTemplate  string
+	BoughtCount     int
+	UnpushedCount   int
+	FireflyURL      string
+	FireflyAccount  string
+	HasFireflyToken bool
+	Error           string
+	Feedback        string
+	ActiveProfile   string
+}
+
+// ledgerEntry is a Bought decision in the shape budgeting tools expect.
+type ledgerEntry struct {
+	ItemID       int
+	Date         time.Time
+	Payee        string
+	Amount       domain.Money
+	CurrencyCode string
+	Category     string
+	Tags         []string
+	Memo         string
+}
+
+// ledgerEntries converts priced Bought items to ledger entries, using the first tag as category. The memo
+// names the receipt, if one was uploaded, so the transaction can be matched to it later.
+func ledgerEntries(items []Item, currency string) []ledgerEntry {
+	code := currencyCode(currency)
+	entries := make([]ledgerEntry, 0, len(items))
+	for _, item := range items {
+		if item.Status != domain.StatusBought || !item.HasPriceValue {
+			continue
+		}
+		tags := splitTags(item.Tags)
+		category := ""
+		if len(tags) > 0 {
+			category = tags[0]
+		}
+		memo := item.Note
+		if item.ReceiptName != "" {
+			memo = strings.TrimSpace(memo + " (receipt: " + item.ReceiptName + ")")
+		}
+		entries = append(entries, ledgerEntry{
+			ItemID:       item.ID,
+			Date:         itemDecisionTime(item),
+			Payee:        item.Title,
+			Amount:       item.PriceCents,
+			CurrencyCode: code,
+			Category:     category,
+			Tags:         tags,
+			Memo:         memo,
+		})
+	}
+	return entries
+}
+
+func splitTags(rawTags string) []string {
+	var tags []string
+	for _, part := range strings.Split(rawTags, ",") {
+		if tag := strings.TrimSpace(part); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func writeYNABCSV(w io.Writer, entries []ledgerEntry) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"Date", "Payee", "Category", "Memo", "Outflow", "Inflow"}); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := cw.Write([]string{
+			entry.Date.Format("2006-01-02"),
+			entry.Payee,
+			entry.Category,
+			entry.Memo,
+			entry.Amount.String(),
+			"",
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeFireflyCSV(w io.Writer, entries []ledgerEntry) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"date", "description", "amount", "currency_code", "category", "tags", "notes"}); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := cw.Write([]string{
+			entry.Date.Format("2006-01-02"),
+			entry.Payee,
+			(-entry.Amount).String(),
+			entry.CurrencyCode,
+			entry.Category,
+			strings.Join(entry.Tags, ","),
+			entry.Memo,
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (a *App) exportYNAB(w http.ResponseWriter, r *http.Request) {
+	a.serveLedgerCSV(w, r, "impulse-pause-ynab.csv", writeYNABCSV)
+}
+
+func (a *App) exportFireflyCSV(w http.ResponseWriter, r *http.Request) {
+	a.serveLedgerCSV(w, r, "impulse-pause-firefly.csv", writeFireflyCSV)
+}
+
+func (a *App) serveLedgerCSV(w http.ResponseWriter, r *http.Request, filename string, write func(io.Writer, []ledgerEntry) error) {
+	a.mu.RLock()
+	entries := ledgerEntries(a.items, a.currency)
+	a.mu.RUnlock()
+
+	var buf bytes.Buffer
+	if err := write(&buf, entries); err != nil {
+		log.Printf("csv export error: %v", err)
+		http.Error(w, "could not export", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	_, _ = w.Write(buf.Bytes())
+}
+
+// itemsCSVHeader are the columns of the items export. Prices use a point as decimal separator.
+var itemsCSVHeader = []string{"id", "title", "price", "currency", "tags", "status", "wait_preset", "created_at", "purchase_allowed_at", "decided_at", "link", "note"}
+
+// exportItems streams all items on the active profile's list as CSV for spreadsheets, oldest first.
+// format defaults to csv, the only format so far.
+func (a *App) exportItems(w http.ResponseWriter, r *http.Request) {
+	if format := r.URL.Query().Get("format"); format != "" && format != "csv" {
+		http.Error(w, "unsupported export format", http.StatusBadRequest)
+		return
+	}
+
+	a.mu.RLock()
+	items := slices.Clone(a.items)
+	code := currencyCode(a.currency)
+	a.mu.RUnlock()
+	slices.SortStableFunc(items, func(x, y Item) int {
+		if c := x.CreatedAt.Compare(y.CreatedAt); c != 0 {
+			return c
+		}
+		return x.ID - y.ID
+	})
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "impulse-pause-items.csv"))
+	if err := writeItemsCSV(w, items, code); err != nil {
+		// The header is sent already, so the download just ends early.
+		log.Printf("items csv export error: %v", err)
+	}
+}
+
+func writeItemsCSV(w io.Writer, items []Item, currencyCode string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(itemsCSVHeader); err != nil {
+		return err
+	}
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format("2006-01-02 15:04")
+	}
+	for i, item := range items {
+		price := strings.TrimSpace(item.Price)
+		if item.HasPriceValue {
+			price = item.PriceCents.String()
+		}
+		note := item.Note
+		if isSealedNote(note) {
+			// Notes of a locked profile stay encrypted and are left out.
+			note = ""
+		}
+		if err := cw.Write([]string{
+			strconv.Itoa(item.ID),
+			item.Title,
+			price,
+			currencyCode,
+			strings.Join(splitTags(item.Tags), ","),
+			item.Status.String(),
+			item.WaitPreset,
+			formatTime(item.CreatedAt),
+			formatTime(item.PurchaseAllowedAt),
+			formatTime(item.DecidedAt),
+			item.Link,
+			note,
+		}); err != nil {
+			return err
+		}
+		// Flush every few hundred rows so long lists stream instead of building up in memory.
+		if i%250 == 249 {
+			cw.Flush()
+			if err := cw.Error(); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+type fireflyTransactionRequest struct {
+	ErrorIfDuplicateHash bool                   `json:"error_if_duplicate_hash"`
+	Transactions         []fireflyTransactionV1 `json:"transactions"`
+}
+
+type fireflyTransactionV1 struct {
+	Type         string   `json:"type"`
+	Date         string   `json:"date"`
+	Amount       string   `json:"amount"`
+	Description  string   `json:"description"`
+	CurrencyCode string   `json:"currency_code,omitempty"`
+	CategoryName string   `json:"category_name,omitempty"`
+	SourceName   string   `json:"source_name"`
+	Tags         []string `json:"tags,omitempty"`
+	Notes        string   `json:"notes,omitempty"`
+}
+
+// pushFireflyTransaction creates a withdrawal via the Firefly III API. Duplicates are rejected by Firefly's hash check.
+func pushFireflyTransaction(ctx context.Context, client *http.Client, baseURL, token, account string, entry ledgerEntry) error {
+	payload := fireflyTransactionRequest{
+		ErrorIfDuplicateHash: true,
+		Transactions: []fireflyTransactionV1{{
+			Type:         "withdrawal",
+			Date:         entry.Date.Format("2006-01-02"),
+			Amount:       entry.Amount.String(),
+			Description:  entry.Payee,
+			CurrencyCode: entry.CurrencyCode,
+			CategoryName: entry.Category,
+			SourceName:   account,
+			Tags:         entry.Tags,
+			Notes:        entry.Memo,
+		}},
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode firefly transaction: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/api/v1/transactions", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create firefly request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("firefly request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("firefly returned %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+// pushFirefly queues the bought items not yet in Firefly III for the outbox, which pushes them one by
+// one and retries those Firefly could not take. Without a database they are pushed right away.
+func (a *App) pushFirefly(w http.ResponseWriter, r *http.Request) {
+	a.mu.LockContext(r.Context())
+	defer a.mu.Unlock()
+
+	if a.fireflyURL == "" || a.fireflyToken == "" || a.fireflyAccount == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		a.renderExportSettingsLocked(w, exportSettingsViewData{Error: "Please configure the Firefly III URL, token and source account first."})
+		return
+	}
+
+	a.recordAuditLocked(a.currentUserIDLocked(), auditTokenUsed, "Firefly III token", r)
+	var items []Item
+	for _, entry := range ledgerEntries(a.items, a.currency) {
+		if idx := a.itemIndexLocked(entry.ItemID); idx >= 0 && !a.items[idx].FireflyPushed {
+			items = append(items, a.items[idx])
+		}
+	}
+
+	if a.db == nil {
+		for pushed, item := range items {
+			if err := a.pushItemToFireflyLocked(item.ID); err != nil {
+				log.Printf("firefly push failed for item %d: %v", item.ID, err)
+				w.WriteHeader(http.StatusBadGateway)
+				a.renderExportSettingsLocked(w, exportSettingsViewData{Error: fmt.Sprintf("Firefly III push stopped after %d transaction(s): %v", pushed, err)})
+				return
+			}
+		}
+		http.Redirect(w, r, "/settings/exports?pushed="+strconv.Itoa(len(items)), http.StatusSeeOther)
+		return
+	}
+
+	if err := a.enqueueFireflyPushesLocked(items); err != nil {
+		log.Printf("db error while queueing firefly pushes: %v", err)
+		http.Error(w, "could not queue the Firefly III push", http.StatusInternalServerError)
+		return
+	}
+	a.triggerJob("outbox")
+	http.Redirect(w, r, "/settings/exports?queued="+strconv.Itoa(len(items)), http.StatusSeeOther)
+}
+
+func (a *App) enqueueFireflyPushesLocked(items []Item) error {
+	tx, err := a.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin firefly tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	now := time.Now()
+	for _, item := range items {
+		if err := a.enqueueEffectsLocked(tx, domain.Event{Profile: a.currentUserIDLocked(), Item: item}, []string{effectFirefly}, now); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit firefly tx: %w", err)
+	}
+	return nil
+}
+
+// pushItemToFireflyLocked pushes a bought item of the active profile to Firefly III and marks it pushed.
+// Items pushed in the meantime, or no longer bought, are skipped, so a push queued twice is sent once.
+func (a *App) pushItemToFireflyLocked(itemID int) error {
+	if a.fireflyURL == "" || a.fireflyToken == "" || a.fireflyAccount == "" {
+		log.Printf("firefly push skipped for item %d: Firefly III is no longer configured", itemID)
+		return nil
+	}
+	idx := a.itemIndexLocked(itemID)
+	if idx < 0 || a.items[idx].FireflyPushed {
+		return nil
+	}
+	entries := ledgerEntries(a.items[idx:idx+1], a.currency)
+	if len(entries) == 0 {
+		return nil
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second, Transport: outboundTransport}
+	if err := pushFireflyTransaction(a.mu.Context(), client, a.fireflyURL, a.fireflyToken, a.fireflyAccount, entries[0]); err != nil {
+		return err
+	}
+	a.items[idx].FireflyPushed = true
+	if err := a.markFireflyPushedLocked(itemID); err != nil {
+		log.Printf("db error while marking firefly push for item %d: %v", itemID, err)
+	}
+	return nil
+}
+
+func (a *App) itemIndexLocked(itemID int) int {
+	for i := range a.items {
+		if a.items[i].ID == itemID {
+			return i
+		}
+	}
+	return -1
+}
+
+func (a *App) exportSettings(w http.ResponseWriter, r *http.Request) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	a.renderExportSettingsLocked(w, exportSettingsViewData{Feedback: exportFeedbackFromQuery(r)})
+}
+
+func exportFeedbackFromQuery(r *http.Request) string {
+	query := r.URL.Query()
+	if query.Get("saved") == "1" {
+		return "Firefly III settings saved."
+	}
+	if pushed := query.Get("pushed"); pushed != "" {
+		return fmt.Sprintf("Pushed %s transaction(s) to Firefly III.", pushed)
+	}
+	if queued := query.Get("queued"); queued != "" {
+		return fmt.Sprintf("Queued %s transaction(s) for Firefly III; they are pushed in the background.", queued)
+	}
+	return ""
+}
+
+func parseFireflyURL(raw string) (string, error) {
+	trimmed := strings.TrimRight(strings.TrimSpace(raw), "/")
+	if trimmed == "" {
+		return "", nil
+	}
+	parsed, err := url.Parse(trimmed)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", errors.New("Please enter a valid Firefly III URL (http or https).")
+	}
+	return trimmed, nil
+}
+
+func (a *App) saveExportSettings(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	a.mu.LockContext(r.Context())
+	defer a.mu.Unlock()
+
+	fireflyURL, err := parseFireflyURL(r.FormValue("firefly_url"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		a.renderExportSettingsLocked(w, exportSettingsViewData{Error: err.Error()})
+		return
+	}
+
+	a.fireflyURL = fireflyURL
+	a.fireflyAccount = strings.TrimSpace(r.FormValue("firefly_account"))
+	if token := strings.TrimSpace(r.FormValue("firefly_token")); token != "" {
+		a.fireflyToken = token
+	}
+	if r.FormValue("firefly_clear_token") == "1" || fireflyURL == "" {
+		a.fireflyToken = ""
+	}
+	if err := a.persistProfileLocked(); err != nil {
+		log.Printf("db error while saving export settings: %v", err)
+		http.Error(w, "could not save export settings", http.StatusInternalServerError)
+		return
+	}
+	a.publishProfileUpdatedLocked("exports", r)
+
+	http.Redirect(w, r, "/settings/exports?saved=1", http.StatusSeeOther)
+}
+
+func (a *App) renderExportSettingsLocked(w http.ResponseWriter, data exportSettingsViewData) {
+	for _, entry := range ledgerEntries(a.items, a.currency) {
+		data.BoughtCount++
+		if idx := a.itemIndexLocked(entry.ItemID); idx >= 0 && !a.items[idx].FireflyPushed {
+			data.UnpushedCount++
+		}
+	}
+	data.FireflyURL = a.fireflyURL
+	data.FireflyAccount = a.fireflyAccount
+	data.HasFireflyToken = a.fireflyToken != ""
+	data.ActiveProfile = a.currentUserIDLocked()
+	data.Title = "Exports"
+	data.CurrentPath = "/settings/exports"
+	data.ContentTemplate = "exports_content"
+	renderTemplate(w, a.templates, "layout", data)
+}
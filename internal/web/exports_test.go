@@ -0,0 +1,194 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func seedExportItems(app *App) {
+	decided := time.Date(2026, 2, 3, 10, 0, 0, 0, time.UTC)
+	app.mu.Lock()
+	app.currency = "€"
+	app.items = []Item{
+		{ID: 1, Title: "Headphones", Price: "199.90", PriceCents: 19990, HasPriceValue: true, Tags: "Audio, Tech", Note: "Noise cancelling", Status: "Bought", DecidedAt: decided},
+		{ID: 2, Title: "Skipped watch", Price: "300", PriceCents: 30000, HasPriceValue: true, Status: "Skipped", DecidedAt: decided},
+		{ID: 3, Title: "Unpriced gift", Status: "Bought", DecidedAt: decided},
+		{ID: 4, Title: "Waiting bike", Price: "900", PriceCents: 90000, HasPriceValue: true, Status: "Waiting"},
+	}
+	app.mu.Unlock()
+}
+
+func TestCurrencyCode(t *testing.T) {
+	tests := map[string]string{"€": "EUR", " $ ": "USD", "chf": "CHF", "EUR": "EUR", "Fr.": "CHF", "euros": "", "": ""}
+	for input, want := range tests {
+		if got := currencyCode(input); got != want {
+			t.Fatalf("currencyCode(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestExportYNABIncludesOnlyPricedBoughtItems(t *testing.T) {
+	app := NewApp()
+	seedProfile(app)
+	seedExportItems(app)
+
+	req := httptest.NewRequest(http.MethodGet, "/exports/ynab.csv", nil)
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	if got := rr.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/csv") {
+		t.Fatalf("expected csv content type, got %q", got)
+	}
+	want := "Date,Payee,Category,Memo,Outflow,Inflow\n2026-02-03,Headphones,Audio,Noise cancelling,199.90,\n"
+	if got := rr.Body.String(); got != want {
+		t.Fatalf("unexpected YNAB export:\n%s", got)
+	}
+}
+
+func TestExportItemsStreamsEveryItemAsCSV(t *testing.T) {
+	app := NewApp()
+	seedProfile(app)
+	seedExportItems(app)
+	created := time.Date(2026, 1, 5, 9, 30, 0, 0, time.UTC)
+	app.mu.Lock()
+	app.items[3].CreatedAt, app.items[3].PurchaseAllowedAt, app.items[3].WaitPreset = created, created.AddDate(0, 0, 30), "30d"
+	app.items = append(app.items, Item{ID: 5, Title: "Lamp, brass", Price: "about 50", Status: "Researching", Note: sealedNotePrefix + "c2VhbGVk", Link: "https://shop.example.com/lamp", CreatedAt: created.AddDate(0, 0, 1)})
+	app.mu.Unlock()
+
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/items/export?format=csv", nil))
+	if rr.Code != http.StatusOK || !strings.HasPrefix(rr.Header().Get("Content-Type"), "text/csv") {
+		t.Fatalf("expected a csv download, got %d %q", rr.Code, rr.Header().Get("Content-Type"))
+	}
+	if got := rr.Header().Get("Content-Disposition"); got != `attachment; filename="impulse-pause-items.csv"` {
+		t.Fatalf("unexpected content disposition %q", got)
+	}
+	want := "id,title,price,currency,tags,status,wait_preset,created_at,purchase_allowed_at,decided_at,link,note\n" +
+		"1,Headphones,199.90,EUR,\"Audio,Tech\",Bought,,,,2026-02-03 10:00,,Noise cancelling\n" +
+		"2,Skipped watch,300.00,EUR,,Skipped,,,,2026-02-03 10:00,,\n" +
+		"3,Unpriced gift,,EUR,,Bought,,,,2026-02-03 10:00,,\n" +
+		"4,Waiting bike,900.00,EUR,,Waiting,30d,2026-01-05 09:30,2026-02-04 09:30,,,\n" +
+		"5,\"Lamp, brass\",about 50,EUR,,Researching,,2026-01-06 09:30,,,https://shop.example.com/lamp,\n"
+	if got := rr.Body.String(); got != want {
+		t.Fatalf("unexpected items export:\n%s", got)
+	}
+
+	rr = httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/items/export?format=xlsx", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unsupported format, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	if !strings.Contains(rr.Body.String(), `href="/items/export?format=csv"`) {
+		t.Fatal("expected a download link on the dashboard")
+	}
+}
+
+func TestExportFireflyCSVUsesNegativeAmountsAndCurrencyCode(t *testing.T) {
+	app := NewApp()
+	seedProfile(app)
+	seedExportItems(app)
+
+	req := httptest.NewRequest(http.MethodGet, "/exports/firefly.csv", nil)
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+
+	want := "date,description,amount,currency_code,category,tags,notes\n2026-02-03,Headphones,-199.90,EUR,Audio,\"Audio,Tech\",Noise cancelling\n"
+	if got := rr.Body.String(); got != want {
+		t.Fatalf("unexpected Firefly export:\n%s", got)
+	}
+}
+
+func TestFireflyPushSendsEachBoughtItemOnce(t *testing.T) {
+	app := NewApp()
+	seedProfile(app)
+	seedExportItems(app)
+
+	var received []fireflyTransactionRequest
+	firefly := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/transactions" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer pat-123" {
+			t.Errorf("unexpected auth header %q", got)
+		}
+		var payload fireflyTransactionRequest
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		received = append(received, payload)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer firefly.Close()
+
+	form := url.Values{}
+	form.Set("firefly_url", firefly.URL+"/")
+	form.Set("firefly_token", "pat-123")
+	form.Set("firefly_account", "Checking")
+	saveReq := httptest.NewRequest(http.MethodPost, "/settings/exports", strings.NewReader(form.Encode()))
+	saveReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	saveRR := httptest.NewRecorder()
+	app.Handler().ServeHTTP(saveRR, saveReq)
+	if saveRR.Code != http.StatusSeeOther {
+		t.Fatalf("expected settings redirect, got %d", saveRR.Code)
+	}
+
+	for i := 0; i < 2; i++ {
+		pushReq := httptest.NewRequest(http.MethodPost, "/exports/firefly/push", nil)
+		pushRR := httptest.NewRecorder()
+		app.Handler().ServeHTTP(pushRR, pushReq)
+		if pushRR.Code != http.StatusSeeOther {
+			t.Fatalf("expected push redirect, got %d", pushRR.Code)
+		}
+	}
+
+	if len(received) != 1 {
+		t.Fatalf("expected exactly one pushed transaction, got %d", len(received))
+	}
+	tx := received[0].Transactions[0]
+	if tx.Type != "withdrawal" || tx.Amount != "199.90" || tx.CurrencyCode != "EUR" || tx.CategoryName != "Audio" || tx.SourceName != "Checking" || tx.Date != "2026-02-03" {
+		t.Fatalf("unexpected transaction payload: %+v", tx)
+	}
+}
+
+func TestFireflyPushRequiresConfiguration(t *testing.T) {
+	app := NewApp()
+	seedProfile(app)
+
+	req := httptest.NewRequest(http.MethodPost, "/exports/firefly/push", nil)
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), "configure the Firefly III URL") {
+		t.Fatalf("expected configuration hint")
+	}
+}
+
+func TestExportSettingsRejectsInvalidFireflyURL(t *testing.T) {
+	app := NewApp()
+	seedProfile(app)
+
+	form := url.Values{}
+	form.Set("firefly_url", "ftp://firefly")
+	req := httptest.NewRequest(http.MethodPost, "/settings/exports", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,290 @@
+package web
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"mvpapp/internal/domain"
+)
+
+// dashboardFilterParams are the query parameters that make up the dashboard filters.
+var dashboardFilterParams = []string{"q", "status", "tag", "within", "sort", "min_price", "max_price", "work"}
+
+// defaultFiltersURL shows the dashboard without filters even when the profile remembers some.
+const defaultFiltersURL = "/?sort=next_ready"
+
+// dashboardFilters is the parsed filter state of the dashboard URL.
+type dashboardFilters struct {
+	Search string
+	// Statuses is empty unless statuses were chosen explicitly; the dashboard then shows the open ones.
+	Statuses []domain.Status
+	Tag      string
+	Within   string
+	Sort     string
+	// MinPrice and MaxPrice bound item prices when HasMinPrice and HasMaxPrice are set.
+	MinPrice    domain.Money
+	HasMinPrice bool
+	MaxPrice    domain.Money
+	HasMaxPrice bool
+	// Work is the key of a workHoursFilter, or empty.
+	Work string
+}
+
+// workHoursFilter is a dashboard quick filter by work cost. It becomes price bounds from the profile's
+// hourly wage when applied, so it follows wage changes. Bounds in shifts use the profile's shift length.
+type workHoursFilter struct {
+	Key       string
+	Label     string
+	MaxHours  float64
+	MaxShifts float64
+	MinShifts float64
+}
+
+var workHoursFilters = []workHoursFilter{
+	{Key: "under_1h", Label: "Under 1 work hour", MaxHours: 1},
+	{Key: "under_1d", Label: "Under a day of work", MaxShifts: 1},
+	{Key: "over_1d", Label: "Over a full day of work", MinShifts: 1},
+}
+
+func findWorkHoursFilter(key string) (workHoursFilter, bool) {
+	for _, filter := range workHoursFilters {
+		if filter.Key == key {
+			return filter, true
+		}
+	}
+	return workHoursFilter{}, false
+}
+
+// workFilterLink is a quick filter on the dashboard. URL toggles it while keeping the other filters.
+type workFilterLink struct {
+	Label  string
+	URL    string
+	Active bool
+}
+
+// filterChip is an applied dashboard filter. RemoveURL is the dashboard URL without it.
+type filterChip struct {
+	Label     string
+	RemoveURL string
+}
+
+func parseDashboardFilters(query url.Values) dashboardFilters {
+	filters := dashboardFilters{
+		Search: strings.TrimSpace(query.Get("q")),
+		Tag:    strings.TrimSpace(query.Get("tag")),
+		Within: normalizeWithin(query.Get("within")),
+		Sort:   normalizeSortBy(query.Get("sort")),
+	}
+	if statuses, explicit := parseStatusFilter(query["status"]); explicit {
+		filters.Statuses = statuses
+	}
+	filters.MinPrice, filters.HasMinPrice = parsePrice(strings.TrimSpace(query.Get("min_price")))
+	filters.MaxPrice, filters.HasMaxPrice = parsePrice(strings.TrimSpace(query.Get("max_price")))
+	if filter, ok := findWorkHoursFilter(strings.TrimSpace(query.Get("work"))); ok {
+		filters.Work = filter.Key
+	}
+	return filters
+}
+
+// active reports whether the filters differ from the default dashboard.
+func (f dashboardFilters) active() bool {
+	return f.Search != "" || len(f.Statuses) > 0 || f.Tag != "" || f.Within != "" || f.Sort != "next_ready" || f.HasMinPrice || f.HasMaxPrice || f.Work != ""
+}
+
+// statuses returns the statuses to show, defaulting to the open ones.
+func (f dashboardFilters) statuses() []domain.Status {
+	if len(f.Statuses) == 0 {
+		return slices.Clone(domain.OpenStatuses)
+	}
+	return f.Statuses
+}
+
+// url encodes the filters as a dashboard link, leaving out defaults. Without filters it links to
+// defaultFiltersURL so the remembered filters are not applied.
+func (f dashboardFilters) url() string {
+	if encoded := f.encode(); encoded != "" {
+		return "/?" + encoded
+	}
+	return defaultFiltersURL
+}
+
+// encode returns the filters as a query string, leaving out defaults.
+func (f dashboardFilters) encode() string {
+	query := url.Values{}
+	if f.Search != "" {
+		query.Set("q", f.Search)
+	}
+	for _, status := range f.Statuses {
+		query.Add("status", string(status))
+	}
+	if f.Tag != "" {
+		query.Set("tag", f.Tag)
+	}
+	if f.Within != "" {
+		query.Set("within", f.Within)
+	}
+	if f.Sort != "next_ready" {
+		query.Set("sort", f.Sort)
+	}
+	if f.HasMinPrice {
+		query.Set("min_price", f.MinPrice.String())
+	}
+	if f.HasMaxPrice {
+		query.Set("max_price", f.MaxPrice.String())
+	}
+	if f.Work != "" {
+		query.Set("work", f.Work)
+	}
+	return query.Encode()
+}
+
+// chips lists the applied filters in the order of the filter form. The sort order is kept when a
+// chip is removed but is not a chip itself.
+func (f dashboardFilters) chips(currency string) []filterChip {
+	var chips []filterChip
+	if f.Search != "" {
+		without := f
+		without.Search = ""
+		chips = append(chips, filterChip{Label: "Search: " + f.Search, RemoveURL: without.url()})
+	}
+	for _, status := range f.Statuses {
+		without := f
+		without.Statuses = slices.DeleteFunc(slices.Clone(f.Statuses), func(s domain.Status) bool { return s == status })
+		chips = append(chips, filterChip{Label: "Status: " + string(status), RemoveURL: without.url()})
+	}
+	if f.Tag != "" {
+		without := f
+		without.Tag = ""
+		chips = append(chips, filterChip{Label: "Tag: " + f.Tag, RemoveURL: without.url()})
+	}
+	if f.HasMinPrice {
+		without := f
+		without.HasMinPrice = false
+		chips = append(chips, filterChip{Label: "From " + formatMoney(f.MinPrice, currency), RemoveURL: without.url()})
+	}
+	if f.HasMaxPrice {
+		without := f
+		without.HasMaxPrice = false
+		chips = append(chips, filterChip{Label: "Up to " + formatMoney(f.MaxPrice, currency), RemoveURL: without.url()})
+	}
+	if filter, ok := findWorkHoursFilter(f.Work); ok {
+		without := f
+		without.Work = ""
+		chips = append(chips, filterChip{Label: filter.Label, RemoveURL: without.url()})
+	}
+	if f.Within != "" {
+		without := f
+		without.Within = ""
+		chips = append(chips, filterChip{Label: "This " + f.Within, RemoveURL: without.url()})
+	}
+	return chips
+}
+
+// workLinks returns the quick filters, each linking to these filters with it turned on, or off when it
+// already is.
+func (f dashboardFilters) workLinks() []workFilterLink {
+	links := make([]workFilterLink, 0, len(workHoursFilters))
+	for _, filter := range workHoursFilters {
+		toggled := f
+		toggled.Work = filter.Key
+		if f.Work == filter.Key {
+			toggled.Work = ""
+		}
+		links = append(links, workFilterLink{Label: filter.Label, URL: toggled.url(), Active: f.Work == filter.Key})
+	}
+	return links
+}
+
+// withWorkBounds returns the filters with the work filter turned into price bounds for the hourly wage.
+// Where the filters already bound the price, the narrower bound wins.
+func (f dashboardFilters) withWorkBounds(hourlyWage, shiftHours float64) dashboardFilters {
+	filter, ok := findWorkHoursFilter(f.Work)
+	if !ok || hourlyWage <= 0 {
+		return f
+	}
+	if hours := filter.MaxHours + filter.MaxShifts*shiftHours; hours > 0 {
+		bound := domain.MoneyFromFloat(hours * hourlyWage)
+		if !f.HasMaxPrice || bound < f.MaxPrice {
+			f.MaxPrice, f.HasMaxPrice = bound, true
+		}
+	}
+	if hours := filter.MinShifts * shiftHours; hours > 0 {
+		bound := domain.MoneyFromFloat(hours * hourlyWage)
+		if !f.HasMinPrice || bound > f.MinPrice {
+			f.MinPrice, f.HasMinPrice = bound, true
+		}
+	}
+	return f
+}
+
+// filterPriceRange keeps the items whose price lies within the filters' bounds. Items without a price
+// are dropped while a bound is set.
+func filterPriceRange(items []Item, f dashboardFilters) []Item {
+	if !f.HasMinPrice && !f.HasMaxPrice {
+		return items
+	}
+	return slices.DeleteFunc(items, func(item Item) bool {
+		price, ok := itemPrice(item)
+		return !ok || (f.HasMinPrice && price < f.MinPrice) || (f.HasMaxPrice && price > f.MaxPrice)
+	})
+}
+
+// hasDashboardFilterParams reports whether query sets any of the dashboard filters.
+func hasDashboardFilterParams(query url.Values) bool {
+	for _, param := range dashboardFilterParams {
+		if query.Has(param) {
+			return true
+		}
+	}
+	return false
+}
+
+// dashboardFiltersLocked returns the filters for a dashboard request. Filters in the URL are
+// remembered for the active profile; a URL without any applies the remembered ones.
+func (a *App) dashboardFiltersLocked(r *http.Request) dashboardFilters {
+	query := r.URL.Query()
+	if !hasDashboardFilterParams(query) {
+		remembered, _ := url.ParseQuery(a.dashboardFilters)
+		return parseDashboardFilters(remembered)
+	}
+	filters := parseDashboardFilters(query)
+	if err := a.rememberDashboardFiltersLocked(filters.encode()); err != nil {
+		log.Printf("db error while saving dashboard filters: %v", err)
+	}
+	return filters
+}
+
+// rememberDashboardFiltersLocked stores encoded as the active profile's dashboard filters.
+func (a *App) rememberDashboardFiltersLocked(encoded string) error {
+	if !a.profileExists || a.dashboardFilters == encoded {
+		return nil
+	}
+	a.dashboardFilters = encoded
+	if a.db == nil {
+		return nil
+	}
+	if _, err := a.db.Exec(`UPDATE profiles SET dashboard_filters = ? WHERE user_id = ?`, encoded, a.currentUserIDLocked()); err != nil {
+		return fmt.Errorf("save dashboard filters: %w", err)
+	}
+	return nil
+}
+
+// resetDashboardFilters forgets the active profile's dashboard filters so / shows the default list again.
+func (a *App) resetDashboardFilters(w http.ResponseWriter, r *http.Request) {
+	if err := a.activateProfileFromRequest(r); err != nil {
+		http.Error(w, "could not activate profile", http.StatusInternalServerError)
+		return
+	}
+	a.mu.LockContext(r.Context())
+	err := a.rememberDashboardFiltersLocked("")
+	a.mu.Unlock()
+	if err != nil {
+		http.Error(w, "could not reset filters", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
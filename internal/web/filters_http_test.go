@@ -0,0 +1,60 @@
+package web_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestHomeFiltersByPriceAndShowsChips(t *testing.T) {
+	later := time.Now().Add(time.Hour)
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}},
+		Items: []webtest.Item{
+			{Profile: "Alex", Title: "Lamp", Price: 3000, PurchaseAllowedAt: later},
+			{Profile: "Alex", Title: "Chair", Price: 25000, PurchaseAllowedAt: later},
+			{Profile: "Alex", Title: "Gift idea", PurchaseAllowedAt: later},
+		},
+	})
+
+	h.As("Alex").Get("/?min_price=20&max_price=100&q=l").ExpectStatus(http.StatusOK).
+		ExpectContains("Lamp", `href="/?max_price=100.00&amp;q=l"`, `href="/?max_price=100.00&amp;min_price=20.00"`).
+		ExpectNotContains("Chair", "Gift idea")
+}
+
+func TestDashboardRemembersFiltersUntilReset(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{Profiles: []webtest.Profile{{Name: "Alex"}}})
+	alex := h.As("Alex")
+	alex.PostForm("/items/new", url.Values{"title": {"Lamp"}, "tags": {"home"}, "wait_preset": {"24h"}}).ExpectStatus(http.StatusSeeOther)
+	alex.PostForm("/items/new", url.Values{"title": {"Shoes"}, "tags": {"sport"}, "wait_preset": {"24h"}}).ExpectStatus(http.StatusSeeOther)
+	showsOnlyLamp := func(target string) bool {
+		body := alex.Get(target).Body()
+		return strings.Contains(body, "Lamp") && !strings.Contains(body, "Shoes")
+	}
+
+	if !showsOnlyLamp("/?tag=home&sort=newest") {
+		t.Fatal("expected the tag filter to apply")
+	}
+	var stored string
+	if err := h.DB.QueryRow(`SELECT dashboard_filters FROM profiles WHERE user_id = 'Alex'`).Scan(&stored); err != nil || stored != "sort=newest&tag=home" {
+		t.Fatalf("expected the filters to be stored, got %q (%v)", stored, err)
+	}
+	if !showsOnlyLamp("/") {
+		t.Fatal("expected the remembered filters on a bare dashboard")
+	}
+	if !showsOnlyLamp("/?done=bought") {
+		t.Fatal("expected an item action confirmation to keep the remembered filters")
+	}
+	if showsOnlyLamp("/?sort=next_ready") {
+		t.Fatal("expected the default list when the filters are removed")
+	}
+	alex.Get("/?tag=home")
+	alex.PostForm("/dashboard/filters/reset", nil).ExpectRedirect("/")
+	if showsOnlyLamp("/") {
+		t.Fatal("expected the default list after a reset")
+	}
+}
@@ -0,0 +1,115 @@
+package web
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFilterChipsRemoveOneFilterEach(t *testing.T) {
+	query, _ := url.ParseQuery("q=desk&status=Waiting&status=Bought&tag=home&sort=price_asc&min_price=10&max_price=99.5")
+	chips := parseDashboardFilters(query).chips("EUR")
+
+	want := []filterChip{
+		{"Search: desk", "/?max_price=99.50&min_price=10.00&sort=price_asc&status=Waiting&status=Bought&tag=home"},
+		{"Status: Waiting", "/?max_price=99.50&min_price=10.00&q=desk&sort=price_asc&status=Bought&tag=home"},
+		{"Status: Bought", "/?max_price=99.50&min_price=10.00&q=desk&sort=price_asc&status=Waiting&tag=home"},
+		{"Tag: home", "/?max_price=99.50&min_price=10.00&q=desk&sort=price_asc&status=Waiting&status=Bought"},
+		{"From " + formatMoney(1000, "EUR"), "/?max_price=99.50&q=desk&sort=price_asc&status=Waiting&status=Bought&tag=home"},
+		{"Up to " + formatMoney(9950, "EUR"), "/?min_price=10.00&q=desk&sort=price_asc&status=Waiting&status=Bought&tag=home"},
+	}
+	if len(chips) != len(want) {
+		t.Fatalf("expected %d chips, got %+v", len(want), chips)
+	}
+	for i := range want {
+		if chips[i] != want[i] {
+			t.Errorf("chip %d: got %+v, want %+v", i, chips[i], want[i])
+		}
+	}
+
+	if chips := parseDashboardFilters(url.Values{"sort": {"newest"}}).chips("EUR"); len(chips) != 0 {
+		t.Fatalf("expected no chips for a sort order alone, got %+v", chips)
+	}
+}
+
+func TestWorkFiltersBecomePriceBoundsFromTheWage(t *testing.T) {
+	bounds := func(query string, shiftHours float64) string {
+		values, _ := url.ParseQuery(query)
+		f := parseDashboardFilters(values).withWorkBounds(25, shiftHours)
+		return fmt.Sprintf("%v %s / %v %s", f.HasMinPrice, f.MinPrice, f.HasMaxPrice, f.MaxPrice)
+	}
+	for query, want := range map[string]string{
+		"work=under_1h":               "false 0.00 / true 25.00",
+		"work=under_1d":               "false 0.00 / true 200.00",
+		"work=over_1d":                "true 200.00 / false 0.00",
+		"work=under_1d&max_price=150": "false 0.00 / true 150.00",
+		"work=under_1d&max_price=300": "false 0.00 / true 200.00",
+		"work=over_1d&min_price=500":  "true 500.00 / false 0.00",
+		"work=someday":                "false 0.00 / false 0.00",
+	} {
+		if got := bounds(query, 8); got != want {
+			t.Errorf("%s: got %s, want %s", query, got, want)
+		}
+	}
+	if got := bounds("work=under_1d", 6); got != "false 0.00 / true 150.00" {
+		t.Errorf("expected a day to follow the shift length, got %s", got)
+	}
+}
+
+func TestHomeQuickFiltersByWorkHours(t *testing.T) {
+	app := NewApp()
+	seedProfile(app)
+
+	now := time.Now()
+	app.mu.Lock()
+	app.items = append(app.items,
+		Item{ID: 1, Title: "Coffee beans", Price: "20", PriceCents: 2000, HasPriceValue: true, Status: "Waiting", CreatedAt: now, PurchaseAllowedAt: now.Add(time.Hour)},
+		Item{ID: 2, Title: "Running shoes", Price: "120", PriceCents: 12000, HasPriceValue: true, Status: "Waiting", CreatedAt: now, PurchaseAllowedAt: now.Add(time.Hour)},
+		Item{ID: 3, Title: "Road bike", Price: "600", PriceCents: 60000, HasPriceValue: true, Status: "Waiting", CreatedAt: now, PurchaseAllowedAt: now.Add(time.Hour)},
+		Item{ID: 4, Title: "Gift idea", Status: "Waiting", CreatedAt: now, PurchaseAllowedAt: now.Add(time.Hour)},
+	)
+	app.mu.Unlock()
+
+	shows := func(target string) []string {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		app.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", target, rr.Code)
+		}
+		var titles []string
+		for _, title := range []string{"Coffee beans", "Running shoes", "Road bike", "Gift idea"} {
+			if strings.Contains(rr.Body.String(), title) {
+				titles = append(titles, title)
+			}
+		}
+		return titles
+	}
+
+	for target, want := range map[string]string{
+		"/?work=under_1h":              "Coffee beans",
+		"/?work=under_1d":              "Coffee beans, Running shoes",
+		"/?work=over_1d":               "Road bike",
+		"/?work=under_1d&min_price=50": "Running shoes",
+		defaultFiltersURL:              "Coffee beans, Running shoes, Road bike, Gift idea",
+	} {
+		if got := strings.Join(shows(target), ", "); got != want {
+			t.Errorf("%s: got %s, want %s", target, got, want)
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/?work=over_1d&tag=bikes", nil))
+	body := html.UnescapeString(rr.Body.String())
+	if !strings.Contains(body, `<a href="/?tag=bikes" class="btn btn-sm btn-secondary" aria-current="true">Over a full day of work</a>`) {
+		t.Fatal("expected the active quick filter to link to the dashboard without it")
+	}
+	if !strings.Contains(body, `href="/?tag=bikes&work=under_1h"`) || !strings.Contains(body, `aria-label="Remove filter Over a full day of work"`) {
+		t.Fatal("expected the other quick filters to keep the tag and a chip for the applied one")
+	}
+}
@@ -0,0 +1,264 @@
+package web
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+// followedShare is another profile's share link the active profile follows on its dashboard.
+type followedShare struct {
+	ID      int
+	Token   string
+	AddedAt time.Time
+}
+
+// followedList is a followed share link as shown on the Following tab. ProfileName is empty when the
+// link was revoked or has expired.
+type followedList struct {
+	ID          int
+	ProfileName string
+	Currency    string
+	// Pending holds the items still waiting or ready to decide, most expensive first.
+	Pending      []Item
+	PendingTotal domain.Money
+}
+
+type followingViewData struct {
+	Title           string
+	CurrentPath     string
+	ContentTemplate string
+	ScriptTemplate  string
+	ActiveProfile   string
+	Lists           []followedList
+	// Unavailable is set for apps without a database, which cannot keep follows.
+	Unavailable bool
+	ShareLink   string
+	Error       string
+	Feedback    string
+}
+
+// shareTokenFromLink accepts a share link as copied from settings, or just its token.
+func shareTokenFromLink(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "token=") {
+		return raw
+	}
+	if parsed, err := url.Parse(raw); err == nil {
+		return strings.TrimSpace(parsed.Query().Get("token"))
+	}
+	return ""
+}
+
+// followingBoard returns the items of a followed profile that are still pending, most expensive first,
+// and their total price.
+func followingBoard(items []Item, now time.Time) ([]Item, domain.Money) {
+	var pending []Item
+	var total domain.Money
+	for _, item := range items {
+		item.Status = effectiveStatus(item, now)
+		if item.Status != domain.StatusWaiting && item.Status != domain.StatusReady {
+			continue
+		}
+		pending = append(pending, item)
+		if item.HasPriceValue {
+			total += item.PriceCents
+		}
+	}
+	slices.SortStableFunc(pending, func(a, b Item) int {
+		if a.PriceCents != b.PriceCents {
+			return int(b.PriceCents - a.PriceCents)
+		}
+		return a.PurchaseAllowedAt.Compare(b.PurchaseAllowedAt)
+	})
+	return pending, total
+}
+
+func (a *App) followedSharesLocked() ([]followedShare, error) {
+	rows, err := a.db.Query(`SELECT id, token, added_at FROM followed_shares WHERE user_id = ? ORDER BY id`, a.currentUserIDLocked())
+	if err != nil {
+		return nil, fmt.Errorf("load followed shares: %w", err)
+	}
+	defer rows.Close()
+
+	var follows []followedShare
+	for rows.Next() {
+		var follow followedShare
+		var addedAt string
+		if err := rows.Scan(&follow.ID, &follow.Token, &addedAt); err != nil {
+			return nil, fmt.Errorf("scan followed share: %w", err)
+		}
+		follow.AddedAt, _ = time.Parse(time.RFC3339Nano, addedAt)
+		follows = append(follows, follow)
+	}
+	return follows, rows.Err()
+}
+
+// followShareLocked adds a share link to the active profile's Following tab. Following the same link
+// twice keeps the first follow.
+func (a *App) followShareLocked(token string, now time.Time) error {
+	_, err := a.db.Exec(`INSERT INTO followed_shares(user_id, token, added_at) VALUES (?, ?, ?) ON CONFLICT(user_id, token) DO NOTHING`,
+		a.currentUserIDLocked(), token, now.Format(time.RFC3339Nano))
+	if err != nil {
+		return fmt.Errorf("follow share: %w", err)
+	}
+	return nil
+}
+
+func (a *App) unfollowShareLocked(followID int) error {
+	res, err := a.db.Exec(`DELETE FROM followed_shares WHERE id = ? AND user_id = ?`, followID, a.currentUserIDLocked())
+	if err != nil {
+		return fmt.Errorf("unfollow share: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// followedListsLocked resolves every followed share link again, so revoked links stop showing items
+// and new items of the followed profile appear on the next load.
+func (a *App) followedListsLocked(r *http.Request, now time.Time) ([]followedList, error) {
+	follows, err := a.followedSharesLocked()
+	if err != nil {
+		return nil, err
+	}
+	lists := make([]followedList, 0, len(follows))
+	for _, follow := range follows {
+		list := followedList{ID: follow.ID}
+		profileName, err := a.profileNameByShareTokenLocked(follow.Token)
+		if err != nil {
+			return nil, err
+		}
+		if profileName != "" {
+			a.recordTokenUseLocked(profileName, "share link", r)
+			items, err := a.itemsForProfileLocked(profileName)
+			if err != nil {
+				return nil, err
+			}
+			if list.Currency, err = a.currencyForProfileLocked(profileName); err != nil {
+				return nil, err
+			}
+			list.ProfileName = profileName
+			list.Pending, list.PendingTotal = followingBoard(maskPrivateItems(items), now)
+		}
+		lists = append(lists, list)
+	}
+	return lists, nil
+}
+
+func (a *App) following(w http.ResponseWriter, r *http.Request) {
+	data := followingViewData{}
+	switch r.URL.Query().Get("saved") {
+	case "follow":
+		data.Feedback = "List followed."
+	case "unfollow":
+		data.Feedback = "List unfollowed."
+	}
+	a.renderFollowing(w, r, data)
+}
+
+func (a *App) saveFollowing(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	switch strings.TrimSpace(r.FormValue("action")) {
+	case "follow":
+		link := strings.TrimSpace(r.FormValue("share_link"))
+		token := shareTokenFromLink(link)
+
+		a.mu.LockContext(r.Context())
+		if a.db == nil {
+			a.mu.Unlock()
+			http.Error(w, "following needs the SQLite store", http.StatusBadRequest)
+			return
+		}
+		profileName, err := a.profileNameByShareTokenLocked(token)
+		if err != nil {
+			a.mu.Unlock()
+			log.Printf("db error while resolving share token: %v", err)
+			http.Error(w, "could not follow list", http.StatusInternalServerError)
+			return
+		}
+		var formErr string
+		switch {
+		case profileName == "":
+			formErr = "Please paste a share link that is still active."
+		case profileName == a.currentUserIDLocked():
+			formErr = "This is your own share link."
+		}
+		if formErr != "" {
+			a.mu.Unlock()
+			w.WriteHeader(http.StatusBadRequest)
+			a.renderFollowing(w, r, followingViewData{ShareLink: link, Error: formErr})
+			return
+		}
+		if err := a.followShareLocked(token, time.Now()); err != nil {
+			a.mu.Unlock()
+			log.Printf("db error while following share: %v", err)
+			http.Error(w, "could not follow list", http.StatusInternalServerError)
+			return
+		}
+		a.mu.Unlock()
+		http.Redirect(w, r, "/following?saved=follow", http.StatusSeeOther)
+	case "unfollow":
+		followID, err := strconv.Atoi(strings.TrimSpace(r.FormValue("follow_id")))
+		if err != nil || followID <= 0 {
+			http.Error(w, "invalid follow id", http.StatusBadRequest)
+			return
+		}
+
+		a.mu.LockContext(r.Context())
+		if a.db == nil {
+			a.mu.Unlock()
+			http.NotFound(w, r)
+			return
+		}
+		err = a.unfollowShareLocked(followID)
+		a.mu.Unlock()
+		if errors.Is(err, sql.ErrNoRows) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			log.Printf("db error while unfollowing share: %v", err)
+			http.Error(w, "could not unfollow list", http.StatusInternalServerError)
+			return
+		}
+		http.Redirect(w, r, "/following?saved=unfollow", http.StatusSeeOther)
+	default:
+		http.Error(w, "invalid action", http.StatusBadRequest)
+	}
+}
+
+func (a *App) renderFollowing(w http.ResponseWriter, r *http.Request, data followingViewData) {
+	a.mu.LockContext(r.Context())
+	data.ActiveProfile = a.currentUserIDLocked()
+	var err error
+	if a.db == nil {
+		data.Unavailable = true
+	} else {
+		data.Lists, err = a.followedListsLocked(r, time.Now())
+	}
+	a.mu.Unlock()
+	if err != nil {
+		log.Printf("db error while loading followed lists: %v", err)
+		data.Error = "Could not load the lists you follow."
+	}
+
+	data.Title = "Following"
+	data.CurrentPath = "/following"
+	data.ContentTemplate = "following_content"
+	renderTemplate(w, a.templates, "layout", data)
+}
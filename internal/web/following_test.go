@@ -0,0 +1,60 @@
+package web_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestFollowingShowsAnotherProfilesPendingItemsUntilTheLinkIsRevoked(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}, {Name: "Sam"}},
+		Items: []webtest.Item{
+			{Profile: "Sam", Title: "Road bike", Price: 120000},
+			{Profile: "Sam", Title: "Espresso machine", Price: 45000, Status: "Ready to buy", PurchaseAllowedAt: time.Now().Add(-time.Hour)},
+			{Profile: "Sam", Title: "Desk lamp", Price: 4000, Status: "Bought", DecidedAt: time.Now()},
+		},
+	})
+	if _, err := h.DB.Exec(`UPDATE profiles SET share_token = 'sam-token' WHERE user_id = 'Sam'`); err != nil {
+		t.Fatalf("set share token: %v", err)
+	}
+	alex := h.As("Alex")
+
+	alex.PostForm("/following", url.Values{"action": {"follow"}, "share_link": {"https://example.com/kiosk?token=nope"}}).
+		ExpectStatus(http.StatusBadRequest).
+		ExpectContains("Please paste a share link that is still active.")
+	alex.PostForm("/following", url.Values{"action": {"follow"}, "share_link": {"https://example.com/kiosk?token=sam-token"}}).
+		ExpectRedirect("/following?saved=follow")
+	// Following the same link again keeps a single entry.
+	alex.PostForm("/following", url.Values{"action": {"follow"}, "share_link": {"sam-token"}}).ExpectRedirect("/following?saved=follow")
+
+	page := alex.Get("/following").ExpectStatus(http.StatusOK).
+		ExpectContains(`<h2 class="h5 mb-0">Sam's waitlist</h2>`, "2 pending · € 1650.00", "Road bike", "Espresso machine").
+		ExpectNotContains("Desk lamp", "Share link no longer works")
+	if count := strings.Count(page.Body(), `name="follow_id"`); count != 1 {
+		t.Fatalf("expected one followed list, got %d", count)
+	}
+	alex.Get("/").ExpectContains(`href="/following"`)
+
+	sam := h.As("Sam")
+	sam.PostForm("/following", url.Values{"action": {"follow"}, "share_link": {"sam-token"}}).
+		ExpectStatus(http.StatusBadRequest).
+		ExpectContains("This is your own share link.")
+
+	if _, err := h.DB.Exec(`UPDATE profiles SET share_token = '' WHERE user_id = 'Sam'`); err != nil {
+		t.Fatalf("revoke share token: %v", err)
+	}
+	alex.Get("/following").ExpectContains("Share link no longer works").ExpectNotContains("Road bike")
+
+	var followID string
+	if err := h.DB.QueryRow(`SELECT id FROM followed_shares WHERE user_id = 'Alex'`).Scan(&followID); err != nil {
+		t.Fatalf("load follow: %v", err)
+	}
+	sam.PostForm("/following", url.Values{"action": {"unfollow"}, "follow_id": {followID}}).ExpectStatus(http.StatusNotFound)
+	alex.PostForm("/following", url.Values{"action": {"unfollow"}, "follow_id": {followID}}).ExpectRedirect("/following?saved=unfollow")
+	alex.Get("/following").ExpectNotContains("Share link no longer works")
+}
@@ -0,0 +1,237 @@
+package web
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+// updateGolden rewrites the golden files instead of comparing against them:
+//
+//	go test ./internal/web -run TestTemplatesMatchGoldenFiles -update
+var updateGolden = flag.Bool("update", false, "rewrite the golden files of the template tests")
+
+// goldenTime is "now" in the golden view data, so the files only change when a template does.
+var goldenTime = time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+
+// goldenCase renders one template with representative view data into testdata/golden/<Name>.html.
+type goldenCase struct {
+	Name     string
+	Template string
+	Data     any
+}
+
+func goldenItems() []Item {
+	return []Item{
+		{ID: 1, Title: "Noise-cancelling headphones", Price: "249.00", PriceCents: 24900, HasPriceValue: true, Link: "https://example.com/headphones", Note: "Compare with last year's model", Tags: "Audio, Tech", Status: domain.StatusReady, WaitPreset: "7d", PurchaseAllowedAt: goldenTime.Add(-2 * time.Hour), CreatedAt: goldenTime.AddDate(0, 0, -7)},
+		{ID: 2, Title: "Standing desk", Price: "480.00", PriceCents: 48000, HasPriceValue: true, Tags: "Office", Status: domain.StatusWaiting, WaitPreset: "30d", PurchaseAllowedAt: goldenTime.Add(50 * time.Hour), CreatedAt: goldenTime.AddDate(0, 0, -28), SharedWith: []string{"Sam"}, SplitPercents: map[string]int{"Sam": 50}},
+		{ID: 3, Title: "Running shoes", Price: "120.00", PriceCents: 12000, HasPriceValue: true, Tags: "Sports", Status: domain.StatusBought, WaitPreset: "24h", PurchaseAllowedAt: goldenTime.AddDate(0, 0, -9), CreatedAt: goldenTime.AddDate(0, 0, -10), DecidedAt: goldenTime.AddDate(0, 0, -8), Satisfaction: satisfactionWorthIt},
+		{ID: 4, Title: "Espresso machine", Price: "399.00", PriceCents: 39900, HasPriceValue: true, Tags: "Home", Status: domain.StatusSkipped, WaitPreset: "7d", PurchaseAllowedAt: goldenTime.AddDate(0, 0, -3), CreatedAt: goldenTime.AddDate(0, 0, -10), DecidedAt: goldenTime.AddDate(0, 0, -2)},
+		{ID: 5, Title: "Camera lens", Tags: "Tech", Status: domain.StatusResearching, WaitPreset: "30d", CreatedAt: goldenTime.AddDate(0, 0, -1)},
+		{ID: 6, Title: "Gift for Sam", Price: "60.00", PriceCents: 6000, HasPriceValue: true, Status: domain.StatusWaiting, WaitPreset: "7d", PurchaseAllowedAt: goldenTime.Add(5 * 24 * time.Hour), CreatedAt: goldenTime.AddDate(0, 0, -2), Private: true},
+	}
+}
+
+func goldenCases() []goldenCase {
+	items := goldenItems()
+	framing := workEffortFraming{Mode: domain.WorkHoursModeHours, Precision: 1, Rounding: domain.WorkHoursRoundNearest}
+	home := homeViewData{
+		Title: "Dashboard", CurrentPath: "/", ContentTemplate: "index_content", ScriptTemplate: "index_script",
+		Items: items, SelectedStatus: map[string]bool{"Waiting": true, "Ready to buy": true}, TagFilter: "Tech",
+		TagOptions: defaultTagOptions, SortBy: "next_ready", MinPrice: "50.00", HasActiveFilter: true,
+		FilterChips: []filterChip{{Label: "Tag: Tech", RemoveURL: "/?min_price=50.00&work=over_1d"}, {Label: "From € 50.00", RemoveURL: "/?tag=Tech&work=over_1d"}, {Label: "Over a full day of work", RemoveURL: "/?min_price=50.00&tag=Tech"}},
+		WorkFilters: dashboardFilters{Tag: "Tech", Sort: "next_ready", MinPrice: 5000, HasMinPrice: true, Work: "over_1d"}.workLinks(),
+		TotalItems:  len(items), HourlyWage: 25, HasHourlyWage: true, WorkEffort: framing, SplitWages: map[string]float64{"Sam": 30},
+		Currency: "EUR", ActiveProfile: "Alex", NeedsApproval: map[int]bool{2: true},
+		SimilarNotes: map[int]string{1: "Similar before: Earbuds (€ 89.00, skipped)"},
+		Summary:      homeSummary{ReadyCount: 1, UnlockingCount: 1, SavedThisMonth: 39900},
+	}
+	blackout := domain.Blackout{Start: goldenTime.AddDate(0, 0, -1), End: goldenTime.AddDate(0, 0, 16), Label: "No-buy spring"}
+	blackoutHome := home
+	blackoutHome.Blackout, blackoutHome.HeldByBlackout = &blackout, map[int]bool{2: true}
+	blackoutHome.Items, blackoutHome.HasActiveFilter, blackoutHome.FilterChips = items[:2], false, nil
+
+	return []goldenCase{
+		{Name: "layout", Template: "layout", Data: pageData{Title: "About", CurrentPath: "/about", ContentTemplate: "about_content", ActiveProfile: "Alex"}},
+		{Name: "index", Template: "index_content", Data: home},
+		{Name: "index_blackout", Template: "index_content", Data: blackoutHome},
+		{Name: "index_empty", Template: "index_content", Data: homeViewData{Title: "Dashboard", CurrentPath: "/", ContentTemplate: "index_content", SelectedStatus: map[string]bool{}, TagOptions: defaultTagOptions, SortBy: "newest", Currency: "EUR", ActiveProfile: "Alex", SetupPending: true}},
+		{Name: "items_new", Template: "items_new_content", Data: itemFormViewData{
+			Title: "Add item", CurrentPath: "/items/new", ContentTemplate: "items_new_content", FormAction: "/items/new", SubmitLabel: "Save item", CancelHref: "/",
+			FormValues: Item{Title: "Mechanical keyboard", Price: "129,99", WaitPreset: "custom", WaitCustomHours: "36"}, TagOptions: defaultTagOptions, SelectedTags: map[string]bool{"Tech": true},
+			Error: fieldErrorSummary, FieldErrors: map[string]string{"price": "Please enter a valid price, such as 19.99."}, Currency: "EUR", ActiveProfile: "Alex",
+			ItemTemplates: []itemTemplate{{ID: 1, Name: "Book", Title: "Book: {date}", Price: "20", Tags: "Education", WaitPreset: "7d"}}, Payday: 25,
+		}},
+		{Name: "items_edit", Template: "items_new_content", Data: itemFormViewData{
+			Title: "Edit item", CurrentPath: "/items/{id}/edit", ContentTemplate: "items_new_content", ItemID: 3, FormAction: "/items/3/edit", SubmitLabel: "Save changes", CancelHref: "/",
+			FormValues: items[2], TagOptions: defaultTagOptions, SelectedTags: map[string]bool{"Sports": true}, Currency: "EUR", ActiveProfile: "Alex", IsOwner: true,
+			ShareCandidates: []string{"Sam"}, MoveCandidates: []string{"Sam"}, History: []historyEntry{}, WaitPresetExplicit: true,
+		}},
+		{Name: "profile", Template: "profile_content", Data: profileViewData{
+			Title: "Settings", CurrentPath: "/settings/profile", ContentTemplate: "profile_content", ProfileName: "Alex", ProfileHourly: "25", DefaultWaitPreset: "7d",
+			NtfyEndpoint: "https://ntfy.sh", NtfyTopic: "alex-ready", WorkHoursMode: domain.WorkHoursModeHours, WorkHoursPrecision: "1", WorkHoursRounding: domain.WorkHoursRoundNearest,
+			LandingPage: "dashboard", RenotifyPolicy: "days", RenotifyDays: "7", NumberFormat: "comma", Payday: "25", AvatarColor: "teal", AvatarColors: domain.AvatarColors,
+			IncomeSummary: "About € 4,000 a month at 40 h a week", Currency: "EUR", CurrencyOptions: supportedCurrencies[:3],
+			AuditLog: []auditEntry{{Event: "settings_changed", Detail: "ntfy", RemoteAddr: "192.0.2.10", CreatedAt: goldenTime.Add(-time.Hour)}},
+			ShareURL: "http://localhost:8080/kiosk?token=golden", QuickAddURL: "http://localhost:8080/quick-add?profile=Alex", ShareExpiresOn: "2026-04-01",
+			ShareViews: 3, ShareViewedAt: goldenTime.Add(-24 * time.Hour), ActiveProfile: "Alex", CanArchive: true,
+		}},
+		{Name: "insights", Template: "insights_content", Data: insightsViewData{
+			Title: "Insights", CurrentPath: "/insights", ContentTemplate: "insights_content", ItemCount: 6, SkippedCount: 1, ResearchingCount: 1,
+			RegretCategories: []categoryRegretRate{{Name: "sports", RegretCount: 1, ResponseCount: 4, Ratio: 0.25, AverageUrge: 3.5, HasUrge: true}},
+			SavedAmount:      39900, TopCategories: []categoryCount{{Name: "tech", Count: 2}, {Name: "audio", Count: 1}}, TrendGranularity: trendGranularityMonth,
+			DecisionTrend:  []decisionTrendPeriod{{Period: "2026-02", BoughtCount: 2, SkippedCount: 1}, {Period: "2026-03", BoughtCount: 1, SkippedCount: 1}},
+			SavedTrend:     []savedAmountPeriod{{Period: "2026-02", Amount: 8900}, {Period: "2026-03", Amount: 39900}},
+			CategoryRatios: []categorySkipRatio{{Name: "home", SkippedCount: 1, DecisionCount: 1, Ratio: 1}},
+			TrendSettings:  trendSettingsForm{Timezone: "Europe/Berlin", WeekStart: "monday", MonthStartDay: "1"}, WeekStartOptions: []string{"monday", "sunday"},
+			Projection:         &savingsProjection{RatePercent: 5, Years: 10, Saved: 48800, Grown: 79490, Average: 24400, Continued: 400000, Period: trendGranularityMonth},
+			ProjectionSettings: projectionSettingsForm{Rate: "5", Years: "10"}, Currency: "EUR", ActiveProfile: "Alex",
+			HoursGoal: &hoursGoalProgress{Year: 2026, GoalHours: 100, Reclaimed: 15.96, Hours: "16.0", Percent: 15, Next: 25}, HoursGoalInput: "100",
+		}},
+		{Name: "calendar", Template: "calendar_content", Data: calendarViewData{
+			Title: "Calendar", CurrentPath: "/calendar", ContentTemplate: "calendar_content", ActiveProfile: "Alex", MonthLabel: "March 2026",
+			PrevMonth: "2026-02", NextMonth: "2026-04", ThisMonth: "2026-03", Weekdays: calendarWeekdays(time.Monday),
+			Weeks: calendarWeeks(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), time.Monday, bucketItemsByDay(items, time.UTC), goldenTime), EntryCount: 5,
+		}},
+		{Name: "timeline", Template: "timeline_content", Data: timelineViewData{
+			Title: "Timeline", CurrentPath: "/timeline", ContentTemplate: "timeline_content", ActiveProfile: "Alex", Currency: "EUR", TagOptions: defaultTagOptions,
+			Month: "2026-03", Days: groupTimelineByDay(buildTimeline(items, goldenTime), time.UTC), EventCount: 9,
+		}},
+		{Name: "about", Template: "about_content", Data: pageData{Title: "About", CurrentPath: "/about", ContentTemplate: "about_content", ActiveProfile: "Alex"}},
+		{Name: "switch_profile", Template: "switch_profile_content", Data: profileSwitchViewData{Title: "Choose profile", CurrentPath: "/switch-profile", ContentTemplate: "switch_profile_content", Names: []string{"Alex", "Sam"}, Error: "Please enter a profile name.", ActiveProfile: "Alex", DemoAvailable: true}},
+		{Name: "tags", Template: "tags_content", Data: tagSettingsViewData{Title: "Tags", CurrentPath: "/settings/tags", ContentTemplate: "tags_content", TagOptions: []string{"Audio", "Tech"}, TagWaitDefaults: map[string]string{"Tech": "30d"}, WaitOptions: tagWaitPresetOptions, StarterTags: defaultTagOptions, Feedback: "Tag added.", ActiveProfile: "Alex"}},
+		{Name: "data_settings", Template: "data_settings_content", Data: dataSettingsViewData{Title: "Data & retention", CurrentPath: "/settings/data", ContentTemplate: "data_settings_content", RetentionMonths: 12, RetentionOptions: retentionMonthOptions, ExpiredCount: 2, UpcomingCount: 1, ItemCount: 6, OwnedItems: 5, ItemQuota: 100, AttachmentBytes: 3 << 20, AttachmentQuota: 50 << 20, NotesEncrypted: true, ActiveProfile: "Alex"}},
+		{Name: "exports", Template: "exports_content", Data: exportSettingsViewData{Title: "Exports", CurrentPath: "/settings/exports", ContentTemplate: "exports_content", BoughtCount: 4, UnpushedCount: 1, FireflyURL: "https://firefly.example.com", FireflyAccount: "1", HasFireflyToken: true, ActiveProfile: "Alex"}},
+		{Name: "household", Template: "household_content", Data: householdViewData{
+			Title: "Household", CurrentPath: "/household", ContentTemplate: "household_content", Month: "2026-03", TotalWaiting: 3, TotalReady: 1, TotalSaved: 39900, SharedCurrency: "€",
+			Members:    []householdMember{{Name: "Alex", Waiting: 2, Ready: 1, SavedMonth: 39900, Currency: "€"}, {Name: "Sam", Waiting: 1, Currency: "€"}},
+			Database:   &databaseSettings{JournalMode: "wal", ConfiguredJournal: "wal", BusyTimeout: 5 * time.Second, MaxOpenConns: 4, MaxIdleConns: 4, OpenConns: 2, InUse: 1, LastMaintenance: &maintenanceRun{StartedAt: goldenTime.Add(-6 * time.Hour), Duration: 1500 * time.Millisecond, Purged: 12, SizeBefore: 4 << 20, SizeAfter: 3 << 20}},
+			AdminQuery: "?token=s3cret", Invites: []invite{{Token: "inv", ProfileName: "Kim", CreatedAt: goldenTime.Add(-time.Hour), ExpiresAt: goldenTime.AddDate(0, 0, 7), Link: "http://localhost:8080/invite/inv"}},
+			InviteOptions: inviteValidityOptions, ArchivedProfiles: []archivedProfile{{Name: "Old", ArchivedAt: goldenTime.AddDate(0, -1, 0)}},
+			Jobs:          []backgroundJob{{Name: "promotion", Schedule: "@every 5s", Enabled: true, LastStarted: goldenTime.Add(-5 * time.Second), LastFinished: goldenTime.Add(-5 * time.Second), Runs: 12}},
+			NtfyEndpoints: []ntfyBreaker{{Endpoint: "https://ntfy.example.com", Failures: 3, OpenUntil: goldenTime.Add(4 * time.Minute), LastError: "ntfy returned 502: bad gateway", LastFail: goldenTime.Add(-time.Minute), State: "open"}},
+		}},
+		{Name: "approvals", Template: "approvals_content", Data: approvalSettingsViewData{Title: "Approvals", CurrentPath: "/settings/approvals", ContentTemplate: "approvals_content", Threshold: "300", Approver: "Sam", ApproverOptions: []string{"Sam"}, PendingApprovals: items[1:2], Currency: "EUR", ActiveProfile: "Alex"}},
+		{Name: "blackouts", Template: "blackouts_content", Data: blackoutSettingsViewData{Title: "Blackout periods", CurrentPath: "/settings/blackouts", ContentTemplate: "blackouts_content", ActiveProfile: "Alex", Blackouts: []domain.Blackout{blackout}, Active: &blackout, Feedback: "Blackout added. Waits ending inside it now end with it."}},
+		{Name: "routing", Template: "routing_content", Data: routingSettingsViewData{
+			Title: "Notification routing", CurrentPath: "/settings/routing", ContentTemplate: "routing_content", ActiveProfile: "Alex", NtfyConfigured: true,
+			Rules:     []domain.RoutingRule{{When: domain.RouteWhenPriceAtLeast, Value: "200.00", Channel: domain.RouteToPush}, {When: domain.RouteWhenAny, Channel: domain.RouteToDigest}},
+			WhenInput: domain.RouteWhenTag, ChannelInput: domain.RouteToTopic, TopicInput: "our house", Error: fieldErrorSummary,
+			FieldErrors: map[string]string{"value": "Please enter the tag the rule applies to.", "topic": "Please enter an ntfy topic name without spaces or slashes."},
+		}},
+		{Name: "rules", Template: "rules_content", Data: rulesSettingsViewData{
+			Title: "Rules import/export", CurrentPath: "/settings/rules", ContentTemplate: "rules_content", ActiveProfile: "Alex",
+			TagWaitCount: 2, HasApproval: true, RoutingCount: 1, Input: "version: 1\nrouting:\n  - when: soon\n",
+			Error: "The rules were not imported. Please fix the file and try again:", Problems: []string{"Routing rule 1: Please choose which items the rule applies to."},
+		}},
+		{Name: "item_templates", Template: "templates_content", Data: templateSettingsViewData{Title: "Item templates", CurrentPath: "/settings/templates", ContentTemplate: "templates_content", ItemTemplates: []itemTemplate{{ID: 1, Name: "Book", Title: "Book: {date}", Price: "20", Tags: "Education", WaitPreset: "7d"}}, TagOptions: defaultTagOptions, SelectedTags: map[string]bool{}, Currency: "EUR", ActiveProfile: "Alex"}},
+		{Name: "home_assistant", Template: "home_assistant_content", Data: homeAssistantViewData{Title: "Home Assistant", CurrentPath: "/settings/home-assistant", ContentTemplate: "home_assistant_content", WebhookURL: "http://homeassistant.local:8123/api/webhook/impulse", SensorURL: "http://localhost:8080/api/v1/home-assistant?token=golden", Config: "rest:\n  - resource: http://localhost:8080/api/v1/home-assistant?token=golden\n", ActiveProfile: "Alex"}},
+		{Name: "onboarding", Template: "onboarding_content", Data: onboardingViewData{
+			Title: "Set up profile", CurrentPath: "/onboarding", ContentTemplate: "onboarding_content", Steps: onboardingSteps, Step: onboardingSteps[1], StepNumber: 2,
+			Progress:        []onboardingProgressItem{{Number: 1, Key: onboardingSteps[0].Key, Title: onboardingSteps[0].Title, Reachable: true}, {Number: 2, Key: onboardingSteps[1].Key, Title: onboardingSteps[1].Title, Current: true, Reachable: true}},
+			ProgressPercent: 2 * 100 / len(onboardingSteps), ProfileName: "Alex", HourlyWage: "25", Currency: "EUR", CurrencyOptions: supportedCurrencies[:3], DefaultWaitPreset: "24h", ActiveProfile: "Alex",
+		}},
+		{Name: "wait_check", Template: "wait_check_content", Data: waitSimulationViewData{
+			Title: "Wait rule check", CurrentPath: "/settings/wait-check", ContentTemplate: "wait_check_content", ActiveProfile: "Alex", Currency: "EUR", Price: "250",
+			TagOptions: defaultTagOptions, SelectedTags: map[string]bool{"Tech": true},
+			Result: &waitSimulation{WaitPreset: "30d", WaitLabel: "30 days", Source: "tag default of Tech", PurchaseAllowedAt: goldenTime.AddDate(0, 0, 30), TagRules: []tagWaitRule{{Tag: "Tech", Preset: "30d", Applied: true}}, NeedsApproval: true, Approver: "Sam", ApprovalThreshold: 20000, WorkEffort: "10.0 h"},
+		}},
+		{Name: "reconcile", Template: "reconcile_content", Data: reconcileViewData{
+			Title: "Reconcile purchases", CurrentPath: "/settings/reconcile", ContentTemplate: "reconcile_content", ActiveProfile: "Alex", Currency: "EUR",
+			Transactions: []transaction{{Date: goldenTime.AddDate(0, 0, -1), Description: "HEADPHONE STORE", Amount: 24900, SuggestedID: 1}}, OpenItems: items[:2], Skipped: []string{"line 3: missing amount"},
+		}},
+		{Name: "invite", Template: "invite_content", Data: inviteViewData{Title: "Accept invite", CurrentPath: "/invite/{token}", ContentTemplate: "invite_content", Invite: invite{Token: "inv", ProfileName: "Kim", CreatedAt: goldenTime.Add(-time.Hour), ExpiresAt: goldenTime.AddDate(0, 0, 7)}, ProfileName: "Kim"}},
+		{Name: "delivery_log", Template: "delivery_log_content", Data: deliveryLogViewData{Title: "Notification log", CurrentPath: "/settings/notification-log", ContentTemplate: "delivery_log_content", ActiveProfile: "Alex", Attempts: []deliveryAttempt{
+			{Notification: notificationItemReady, Channel: effectNtfy, ItemID: 1, ItemTitle: "Noise-cancelling headphones", AttemptedAt: goldenTime.Add(-2 * time.Hour), StatusCode: 200},
+			{Notification: notificationItemReady, Channel: effectWebPush, ItemID: 1, ItemTitle: "Noise-cancelling headphones", AttemptedAt: goldenTime.Add(-2 * time.Hour), Error: "push service returned 410", StatusCode: 410},
+			{Notification: notificationReadyDigest, Channel: effectNtfy, ItemID: 4, ItemTitle: "Espresso machine", AttemptedAt: goldenTime.Add(-time.Hour), DryRun: true},
+		}}},
+		{Name: "following", Template: "following_content", Data: followingViewData{Title: "Following", CurrentPath: "/following", ContentTemplate: "following_content", ActiveProfile: "Alex", Lists: []followedList{{ID: 1, ProfileName: "Sam", Currency: "€", Pending: items[1:2], PendingTotal: 48000}}}},
+		{Name: "leaderboard", Template: "leaderboard_content", Data: leaderboardViewData{
+			Title: "Leaderboard", CurrentPath: "/leaderboard", ContentTemplate: "leaderboard_content", ActiveProfile: "Alex", Month: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), PrevMonth: "2026-01", NextMonth: "2026-03",
+			Board: leaderboard{
+				MostSaved:    []leaderboardRanking{{Currency: "EUR", Entries: []leaderboardEntry{{Name: "Alex", Currency: "EUR", Saved: 39900, Skipped: 1, Bought: 1}, {Name: "Sam", Currency: "EUR", Saved: 8900, Skipped: 1, Bought: 3}}}},
+				BestSkipRate: []leaderboardEntry{{Name: "Alex", Currency: "EUR", Saved: 39900, Skipped: 1, Bought: 1}, {Name: "Sam", Currency: "EUR", Saved: 8900, Skipped: 1, Bought: 3}},
+			},
+			Joined: true, Digest: true,
+		}},
+		{Name: "error", Template: "error_content", Data: errorPageData{Title: "Conflict", ContentTemplate: "error_content", ActiveProfile: "Alex", Status: 409, Message: "Only ready items can be marked as bought or skipped."}},
+		{Name: "kiosk", Template: "kiosk", Data: kioskViewData{
+			Title: "Alex's waitlist", ProfileName: "Alex", ReadyItems: items[:1], UpcomingItems: maskPrivateItems(items[1:2]), Currency: "EUR", RefreshSeconds: 60, GeneratedAt: goldenTime,
+			Preview: sharePreview{Title: "Alex's waitlist", Description: "1 ready, 1 waiting", ImageURL: "http://localhost:8080/kiosk/card.png?token=golden"},
+		}},
+		{Name: "public_stats", Template: "public_stats", Data: publicStatsViewData{Title: "Impulse Pause stats", Stats: publicStats{Profiles: 2, Skipped: 3, Bought: 5, Saved: []publicStatsAmount{{Currency: "EUR", Amount: 48800}}}, GeneratedAt: goldenTime}},
+	}
+}
+
+// TestTemplatesMatchGoldenFiles renders every page template with fixed view data and compares the
+// HTML with testdata/golden, so changes to a template show up as a diff to review. After an
+// intended change, rerun with -update and commit the rewritten files.
+func TestTemplatesMatchGoldenFiles(t *testing.T) {
+	app := NewApp()
+	cases := goldenCases()
+
+	covered := map[string]bool{}
+	for _, tc := range cases {
+		covered[tc.Template] = true
+	}
+	for _, tmpl := range app.templates.Templates() {
+		name := tmpl.Name()
+		if (strings.HasSuffix(name, "_content") || name == "layout" || name == "kiosk" || name == "public_stats") && !covered[name] {
+			t.Errorf("template %q has no golden case; add one to goldenCases", name)
+		}
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := app.templates.ExecuteTemplate(&buf, tc.Template, tc.Data); err != nil {
+				t.Fatalf("render %s: %v", tc.Template, err)
+			}
+			assertGolden(t, tc.Name, buf.Bytes())
+		})
+	}
+}
+
+// assertGolden compares got with testdata/golden/<name>.html, or writes it there with -update. The build
+// in the page footer differs between machines and is replaced with a placeholder.
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	got = bytes.ReplaceAll(got, []byte(CurrentBuild().String()), []byte("BUILD"))
+	path := filepath.Join("testdata", "golden", name+".html")
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("create golden directory: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("write golden file: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file (run with -update to create it): %v", err)
+	}
+	if bytes.Equal(got, want) {
+		return
+	}
+	gotLines, wantLines := strings.Split(string(got), "\n"), strings.Split(string(want), "\n")
+	for i := range max(len(gotLines), len(wantLines)) {
+		var g, w string
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if g != w {
+			t.Fatalf("%s differs from %s at line %d:\n  want: %q\n   got: %q\nrun with -update if the change is intended", name, path, i+1, w, g)
+		}
+	}
+}
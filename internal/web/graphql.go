@@ -0,0 +1,562 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// This file holds a small GraphQL executor for read-only queries: fields with aliases, arguments and
+// variables, no fragments, directives, mutations or introspection beyond __typename. The schema lives
+// in graphql_schema.go.
+
+// maxGraphQLDepth bounds the nesting of selection sets.
+const maxGraphQLDepth = 8
+
+// gqlObject is a value with fields. Resolve returns a scalar, a gqlObject, a []gqlObject or nil, and
+// an error for unknown fields or invalid arguments.
+type gqlObject struct {
+	Type    string
+	Resolve func(field string, args map[string]any) (any, error)
+}
+
+// gqlField is one selected field of a query.
+type gqlField struct {
+	Alias     string
+	Name      string
+	Arguments map[string]gqlValue
+	Selection []gqlField
+}
+
+// gqlValue is an argument value: a literal, or a variable resolved when the query runs.
+type gqlValue struct {
+	Variable string
+	Literal  any
+}
+
+// gqlDocument is a parsed query operation.
+type gqlDocument struct {
+	Selection []gqlField
+	// Defaults are the default values of the declared variables; nil for variables without one.
+	Defaults map[string]any
+}
+
+// gqlError is a GraphQL error entry.
+type gqlError struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path,omitempty"`
+}
+
+func (e *gqlError) Error() string {
+	return e.Message
+}
+
+// gqlResult keeps the response fields in the order they were selected, as GraphQL requires.
+type gqlResult []gqlEntry
+
+type gqlEntry struct {
+	Key   string
+	Value any
+}
+
+func (r gqlResult) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, entry := range r {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(entry.Key)
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := json.Marshal(entry.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// executeGraphQL resolves the document's selection against root.
+func executeGraphQL(doc gqlDocument, variables map[string]any, root gqlObject) (gqlResult, error) {
+	vars := make(map[string]any, len(doc.Defaults))
+	for name, value := range doc.Defaults {
+		vars[name] = value
+	}
+	for name, value := range variables {
+		if _, declared := doc.Defaults[name]; !declared {
+			return nil, &gqlError{Message: fmt.Sprintf("variable $%s is not declared", name)}
+		}
+		vars[name] = value
+	}
+	return executeSelection(root, doc.Selection, vars, nil)
+}
+
+func executeSelection(object gqlObject, selection []gqlField, vars map[string]any, path []any) (gqlResult, error) {
+	result := make(gqlResult, 0, len(selection))
+	for _, field := range selection {
+		fieldPath := append(append([]any(nil), path...), field.Alias)
+		if field.Name == "__typename" {
+			result = append(result, gqlEntry{Key: field.Alias, Value: object.Type})
+			continue
+		}
+
+		args := make(map[string]any, len(field.Arguments))
+		for name, value := range field.Arguments {
+			resolved, err := resolveGraphQLValue(value, vars)
+			if err != nil {
+				return nil, &gqlError{Message: err.Error(), Path: fieldPath}
+			}
+			args[name] = resolved
+		}
+		value, err := object.Resolve(field.Name, args)
+		if err != nil {
+			return nil, &gqlError{Message: err.Error(), Path: fieldPath}
+		}
+		completed, err := completeGraphQLValue(field, value, vars, fieldPath)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, gqlEntry{Key: field.Alias, Value: completed})
+	}
+	return result, nil
+}
+
+func completeGraphQLValue(field gqlField, value any, vars map[string]any, path []any) (any, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case gqlObject:
+		if len(field.Selection) == 0 {
+			return nil, &gqlError{Message: fmt.Sprintf("field %q of type %s needs a selection of subfields", field.Name, v.Type), Path: path}
+		}
+		return executeSelection(v, field.Selection, vars, path)
+	case []gqlObject:
+		if len(field.Selection) == 0 {
+			return nil, &gqlError{Message: fmt.Sprintf("field %q needs a selection of subfields", field.Name), Path: path}
+		}
+		list := make([]gqlResult, 0, len(v))
+		for i, object := range v {
+			completed, err := executeSelection(object, field.Selection, vars, append(append([]any(nil), path...), i))
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, completed)
+		}
+		return list, nil
+	default:
+		if len(field.Selection) > 0 {
+			return nil, &gqlError{Message: fmt.Sprintf("field %q has no subfields", field.Name), Path: path}
+		}
+		return v, nil
+	}
+}
+
+func resolveGraphQLValue(value gqlValue, vars map[string]any) (any, error) {
+	if value.Variable == "" {
+		if list, ok := value.Literal.([]gqlValue); ok {
+			resolved := make([]any, 0, len(list))
+			for _, item := range list {
+				v, err := resolveGraphQLValue(item, vars)
+				if err != nil {
+					return nil, err
+				}
+				resolved = append(resolved, v)
+			}
+			return resolved, nil
+		}
+		return value.Literal, nil
+	}
+	v, ok := vars[value.Variable]
+	if !ok {
+		return nil, fmt.Errorf("variable $%s is not declared", value.Variable)
+	}
+	return v, nil
+}
+
+// parseGraphQL parses a document with a single query operation, either "{ … }" or "query Name($v: T = x) { … }".
+func parseGraphQL(source string) (gqlDocument, error) {
+	p := &gqlParser{lexer: gqlLexer{src: source}}
+	if err := p.advance(); err != nil {
+		return gqlDocument{}, err
+	}
+
+	doc := gqlDocument{Defaults: map[string]any{}}
+	if p.tok.kind == gqlName {
+		switch p.tok.text {
+		case "query":
+		case "mutation", "subscription":
+			return doc, p.errorf("%ss are not supported; the GraphQL endpoint is read-only", p.tok.text)
+		case "fragment":
+			return doc, p.errorf("fragments are not supported")
+		default:
+			return doc, p.errorf("unexpected %q", p.tok.text)
+		}
+		if err := p.advance(); err != nil {
+			return doc, err
+		}
+		if p.tok.kind == gqlName {
+			if err := p.advance(); err != nil {
+				return doc, err
+			}
+		}
+		if p.isPunct("(") {
+			if err := p.parseVariableDefinitions(doc.Defaults); err != nil {
+				return doc, err
+			}
+		}
+	}
+	if p.isPunct("@") {
+		return doc, p.errorf("directives are not supported")
+	}
+
+	selection, err := p.parseSelectionSet(1)
+	if err != nil {
+		return doc, err
+	}
+	if p.tok.kind != gqlEOF {
+		return doc, p.errorf("only one operation per document is supported")
+	}
+	doc.Selection = selection
+	return doc, nil
+}
+
+type gqlTokenKind int
+
+const (
+	gqlEOF gqlTokenKind = iota
+	gqlPunct
+	gqlName
+	gqlInt
+	gqlFloat
+	gqlString
+)
+
+type gqlToken struct {
+	kind gqlTokenKind
+	text string
+	pos  int
+}
+
+type gqlLexer struct {
+	src string
+	pos int
+}
+
+func (l *gqlLexer) next() (gqlToken, error) {
+	// Whitespace, commas and comments carry no meaning in GraphQL.
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',' {
+			l.pos++
+			continue
+		}
+		if c == '#' {
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+			continue
+		}
+		break
+	}
+	start := l.pos
+	if l.pos >= len(l.src) {
+		return gqlToken{kind: gqlEOF, pos: start}, nil
+	}
+
+	c := l.src[l.pos]
+	switch {
+	case strings.ContainsRune("{}()[]:!$=@", rune(c)):
+		l.pos++
+		return gqlToken{kind: gqlPunct, text: string(c), pos: start}, nil
+	case strings.HasPrefix(l.src[l.pos:], "..."):
+		return gqlToken{}, fmt.Errorf("fragments are not supported (at %d)", start)
+	case c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+		for l.pos < len(l.src) && isGraphQLNameChar(l.src[l.pos]) {
+			l.pos++
+		}
+		return gqlToken{kind: gqlName, text: l.src[start:l.pos], pos: start}, nil
+	case c == '-' || (c >= '0' && c <= '9'):
+		l.pos++
+		kind := gqlInt
+		for l.pos < len(l.src) {
+			d := l.src[l.pos]
+			if d == '.' || d == 'e' || d == 'E' || ((d == '+' || d == '-') && kind == gqlFloat) {
+				kind = gqlFloat
+			} else if d < '0' || d > '9' {
+				break
+			}
+			l.pos++
+		}
+		return gqlToken{kind: kind, text: l.src[start:l.pos], pos: start}, nil
+	case c == '"':
+		return l.string(start)
+	}
+	r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+	return gqlToken{}, fmt.Errorf("unexpected character %q at %d", r, start)
+}
+
+func (l *gqlLexer) string(start int) (gqlToken, error) {
+	if strings.HasPrefix(l.src[l.pos:], `"""`) {
+		end := strings.Index(l.src[l.pos+3:], `"""`)
+		if end < 0 {
+			return gqlToken{}, fmt.Errorf("unterminated string at %d", start)
+		}
+		text := l.src[l.pos+3 : l.pos+3+end]
+		l.pos += end + 6
+		return gqlToken{kind: gqlString, text: text, pos: start}, nil
+	}
+	l.pos++
+	for l.pos < len(l.src) {
+		switch l.src[l.pos] {
+		case '\\':
+			l.pos += 2
+			continue
+		case '\n':
+			return gqlToken{}, fmt.Errorf("unterminated string at %d", start)
+		case '"':
+			l.pos++
+			// GraphQL string escapes are a subset of JSON's.
+			var text string
+			if err := json.Unmarshal([]byte(l.src[start:l.pos]), &text); err != nil {
+				return gqlToken{}, fmt.Errorf("invalid string at %d", start)
+			}
+			return gqlToken{kind: gqlString, text: text, pos: start}, nil
+		}
+		l.pos++
+	}
+	return gqlToken{}, fmt.Errorf("unterminated string at %d", start)
+}
+
+func isGraphQLNameChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+type gqlParser struct {
+	lexer gqlLexer
+	tok   gqlToken
+}
+
+func (p *gqlParser) advance() error {
+	tok, err := p.lexer.next()
+	if err != nil {
+		return &gqlError{Message: "Syntax error: " + err.Error()}
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *gqlParser) errorf(format string, args ...any) error {
+	return &gqlError{Message: fmt.Sprintf("Syntax error at %d: ", p.tok.pos) + fmt.Sprintf(format, args...)}
+}
+
+func (p *gqlParser) isPunct(text string) bool {
+	return p.tok.kind == gqlPunct && p.tok.text == text
+}
+
+func (p *gqlParser) expect(text string) error {
+	if !p.isPunct(text) {
+		return p.errorf("expected %q", text)
+	}
+	return p.advance()
+}
+
+func (p *gqlParser) name() (string, error) {
+	if p.tok.kind != gqlName {
+		return "", p.errorf("expected a name")
+	}
+	name := p.tok.text
+	return name, p.advance()
+}
+
+func (p *gqlParser) parseVariableDefinitions(defaults map[string]any) error {
+	if err := p.expect("("); err != nil {
+		return err
+	}
+	for !p.isPunct(")") {
+		if err := p.expect("$"); err != nil {
+			return err
+		}
+		name, err := p.name()
+		if err != nil {
+			return err
+		}
+		if err := p.expect(":"); err != nil {
+			return err
+		}
+		// Types are not checked; resolvers validate the values they receive.
+		if err := p.skipType(); err != nil {
+			return err
+		}
+		defaults[name] = nil
+		if p.isPunct("=") {
+			if err := p.advance(); err != nil {
+				return err
+			}
+			value, err := p.parseValue(true)
+			if err != nil {
+				return err
+			}
+			defaults[name], _ = resolveGraphQLValue(value, nil)
+		}
+	}
+	return p.advance()
+}
+
+func (p *gqlParser) skipType() error {
+	if p.isPunct("[") {
+		if err := p.advance(); err != nil {
+			return err
+		}
+		if err := p.skipType(); err != nil {
+			return err
+		}
+		if err := p.expect("]"); err != nil {
+			return err
+		}
+	} else if _, err := p.name(); err != nil {
+		return err
+	}
+	if p.isPunct("!") {
+		return p.advance()
+	}
+	return nil
+}
+
+func (p *gqlParser) parseSelectionSet(depth int) ([]gqlField, error) {
+	if depth > maxGraphQLDepth {
+		return nil, p.errorf("the query is nested too deeply")
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var fields []gqlField
+	for !p.isPunct("}") {
+		if p.tok.kind == gqlEOF {
+			return nil, p.errorf("expected \"}\"")
+		}
+		field, err := p.parseField(depth)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		return nil, p.errorf("a selection set cannot be empty")
+	}
+	return fields, p.advance()
+}
+
+func (p *gqlParser) parseField(depth int) (gqlField, error) {
+	name, err := p.name()
+	if err != nil {
+		return gqlField{}, err
+	}
+	field := gqlField{Alias: name, Name: name}
+	if p.isPunct(":") {
+		if err := p.advance(); err != nil {
+			return field, err
+		}
+		if field.Name, err = p.name(); err != nil {
+			return field, err
+		}
+	}
+	if p.isPunct("(") {
+		if err := p.advance(); err != nil {
+			return field, err
+		}
+		field.Arguments = map[string]gqlValue{}
+		for !p.isPunct(")") {
+			arg, err := p.name()
+			if err != nil {
+				return field, err
+			}
+			if err := p.expect(":"); err != nil {
+				return field, err
+			}
+			if field.Arguments[arg], err = p.parseValue(false); err != nil {
+				return field, err
+			}
+		}
+		if err := p.advance(); err != nil {
+			return field, err
+		}
+	}
+	if p.isPunct("@") {
+		return field, p.errorf("directives are not supported")
+	}
+	if p.isPunct("{") {
+		if field.Selection, err = p.parseSelectionSet(depth + 1); err != nil {
+			return field, err
+		}
+	}
+	return field, nil
+}
+
+// parseValue reads a literal or, unless constant is set, a variable. Enum values are read as strings.
+func (p *gqlParser) parseValue(constant bool) (gqlValue, error) {
+	tok := p.tok
+	switch {
+	case p.isPunct("$") && !constant:
+		if err := p.advance(); err != nil {
+			return gqlValue{}, err
+		}
+		name, err := p.name()
+		return gqlValue{Variable: name}, err
+	case p.isPunct("["):
+		if err := p.advance(); err != nil {
+			return gqlValue{}, err
+		}
+		list := []gqlValue{}
+		for !p.isPunct("]") {
+			if p.tok.kind == gqlEOF {
+				return gqlValue{}, p.errorf("expected \"]\"")
+			}
+			item, err := p.parseValue(constant)
+			if err != nil {
+				return gqlValue{}, err
+			}
+			list = append(list, item)
+		}
+		return gqlValue{Literal: list}, p.advance()
+	case p.isPunct("{"):
+		return gqlValue{}, p.errorf("input objects are not supported")
+	}
+
+	var literal any
+	switch tok.kind {
+	case gqlInt:
+		n, err := strconv.Atoi(tok.text)
+		if err != nil {
+			return gqlValue{}, p.errorf("invalid integer %q", tok.text)
+		}
+		literal = n
+	case gqlFloat:
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return gqlValue{}, p.errorf("invalid number %q", tok.text)
+		}
+		literal = f
+	case gqlString:
+		literal = tok.text
+	case gqlName:
+		switch tok.text {
+		case "true":
+			literal = true
+		case "false":
+			literal = false
+		case "null":
+			literal = nil
+		default:
+			literal = tok.text
+		}
+	default:
+		return gqlValue{}, p.errorf("expected a value")
+	}
+	return gqlValue{Literal: literal}, p.advance()
+}
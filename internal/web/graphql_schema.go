@@ -0,0 +1,364 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+// graphQLRequest is the body of POST /graphql.
+type graphQLRequest struct {
+	Query         string         `json:"query"`
+	Variables     map[string]any `json:"variables"`
+	OperationName string         `json:"operationName"`
+}
+
+// graphQLResponse is the body of every /graphql response.
+type graphQLResponse struct {
+	Data   gqlResult   `json:"data,omitempty"`
+	Errors []*gqlError `json:"errors,omitempty"`
+}
+
+// graphQL answers read-only queries over the active profile's items, the profile list and the insights
+// aggregations, for integrators who prefer one query to several REST calls. Queries come as the JSON body
+// of a POST or in the query parameter of a GET; variables are only read from the POST body.
+func (a *App) graphQL(w http.ResponseWriter, r *http.Request) {
+	var req graphQLRequest
+	if r.Method == http.MethodGet {
+		req.Query = r.URL.Query().Get("query")
+	} else {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxAPIBodyBytes))
+		if err != nil {
+			writeGraphQLError(w, http.StatusRequestEntityTooLarge, "request body too large")
+			return
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			writeGraphQLError(w, http.StatusBadRequest, "invalid JSON body")
+			return
+		}
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		writeGraphQLError(w, http.StatusBadRequest, "query is required")
+		return
+	}
+
+	doc, err := parseGraphQL(req.Query)
+	if err != nil {
+		writeGraphQLError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !a.requireAPIProfile(w, r) {
+		return
+	}
+	profiles, err := a.listProfileNames()
+	if err != nil {
+		log.Printf("db error while listing profiles for graphql: %v", err)
+		writeGraphQLError(w, http.StatusInternalServerError, "could not load profiles")
+		return
+	}
+
+	a.mu.LockContext(r.Context())
+	a.promoteReadyItemsLocked(time.Now())
+	data, err := executeGraphQL(doc, req.Variables, a.graphQLQueryLocked(profiles))
+	a.mu.Unlock()
+
+	var gqlErr *gqlError
+	if errors.As(err, &gqlErr) {
+		writeJSON(w, http.StatusBadRequest, graphQLResponse{Errors: []*gqlError{gqlErr}})
+		return
+	}
+	writeJSON(w, http.StatusOK, graphQLResponse{Data: data})
+}
+
+func writeGraphQLError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, graphQLResponse{Errors: []*gqlError{{Message: message}}})
+}
+
+// graphQLQueryLocked is the root Query object. Its resolvers read the app state, so the query runs under a.mu.
+func (a *App) graphQLQueryLocked(profiles []string) gqlObject {
+	return gqlObject{Type: "Query", Resolve: func(field string, args map[string]any) (any, error) {
+		switch field {
+		case "items":
+			if err := checkGraphQLArgs(field, args, "q", "status", "tag", "sort", "first"); err != nil {
+				return nil, err
+			}
+			return a.graphQLItemsLocked(args)
+		case "item":
+			if err := checkGraphQLArgs(field, args, "id"); err != nil {
+				return nil, err
+			}
+			id, ok, err := graphQLIntArg(args, "id")
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return nil, errors.New(`argument "id" is required`)
+			}
+			for _, item := range a.items {
+				if item.ID == id {
+					return graphQLItem(item), nil
+				}
+			}
+			return nil, nil
+		case "profiles":
+			if err := checkGraphQLArgs(field, args); err != nil {
+				return nil, err
+			}
+			active := a.currentUserIDLocked()
+			list := make([]gqlObject, 0, len(profiles))
+			for _, name := range profiles {
+				list = append(list, graphQLProfile(name, name == active, ""))
+			}
+			return list, nil
+		case "profile":
+			if err := checkGraphQLArgs(field, args); err != nil {
+				return nil, err
+			}
+			return graphQLProfile(a.currentUserIDLocked(), true, profileCurrencyOrDefault(a.currency)), nil
+		case "insights":
+			if err := checkGraphQLArgs(field, args, "period"); err != nil {
+				return nil, err
+			}
+			period, _, err := graphQLStringArg(args, "period")
+			if err != nil {
+				return nil, err
+			}
+			if period != "" && period != trendGranularityMonth && period != trendGranularityWeek {
+				return nil, fmt.Errorf("unknown period %q, expected month or week", period)
+			}
+			return a.graphQLInsightsLocked(normalizeTrendGranularity(period)), nil
+		}
+		return nil, unknownGraphQLField("Query", field)
+	}}
+}
+
+// graphQLItemsLocked filters and sorts like GET /api/v1/items; first limits the number of items returned.
+func (a *App) graphQLItemsLocked(args map[string]any) ([]gqlObject, error) {
+	search, _, err := graphQLStringArg(args, "q")
+	if err != nil {
+		return nil, err
+	}
+	tag, _, err := graphQLStringArg(args, "tag")
+	if err != nil {
+		return nil, err
+	}
+	sortBy, ok, err := graphQLStringArg(args, "sort")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		sortBy = "newest"
+	} else if !slices.Contains(apiSortOptions, sortBy) {
+		return nil, fmt.Errorf("unknown sort %q", sortBy)
+	}
+	rawStatuses, err := graphQLStringListArg(args, "status")
+	if err != nil {
+		return nil, err
+	}
+	var statuses []domain.Status
+	for _, raw := range rawStatuses {
+		status, err := domain.ParseStatus(raw)
+		if err != nil {
+			return nil, fmt.Errorf("unknown status %q", raw)
+		}
+		statuses = append(statuses, status)
+	}
+	first, limited, err := graphQLIntArg(args, "first")
+	if err != nil {
+		return nil, err
+	}
+	if limited && (first < 1 || first > maxAPIPageSize) {
+		return nil, fmt.Errorf("first must be between 1 and %d", maxAPIPageSize)
+	}
+
+	listed := filterAndSortItems(a.items, search, statuses, tag, sortBy)
+	if limited && len(listed) > first {
+		listed = listed[:first]
+	}
+	objects := make([]gqlObject, 0, len(listed))
+	for _, item := range listed {
+		objects = append(objects, graphQLItem(item))
+	}
+	return objects, nil
+}
+
+// graphQLInsightsLocked carries the figures of the insights page, computed the same way.
+func (a *App) graphQLInsightsLocked(granularity string) gqlObject {
+	carried := itemsCarriedBy(a.items, a.currentUserIDLocked())
+	skippedCount, savedAmount, topCategories := buildDashboardStats(carried)
+	itemCount := len(a.items)
+	researchingCount := countItemsWithStatus(a.items, domain.StatusResearching)
+	periods := a.trendPeriodsLocked(granularity)
+	decisionTrend := buildDecisionTrend(a.items, periods)
+	savedTrend := buildSavedTrend(carried, periods)
+	currency := profileCurrencyOrDefault(a.currency)
+
+	return gqlObject{Type: "Insights", Resolve: func(field string, args map[string]any) (any, error) {
+		if err := checkGraphQLArgs(field, args); err != nil {
+			return nil, err
+		}
+		switch field {
+		case "period":
+			return granularity, nil
+		case "itemCount":
+			return itemCount, nil
+		case "skippedCount":
+			return skippedCount, nil
+		case "researchingCount":
+			return researchingCount, nil
+		case "savedCents":
+			return int64(savedAmount), nil
+		case "currency":
+			return currency, nil
+		case "topCategories":
+			list := make([]gqlObject, 0, len(topCategories))
+			for _, category := range topCategories {
+				list = append(list, graphQLScalars("CategoryCount", map[string]any{"name": category.Name, "count": category.Count}))
+			}
+			return list, nil
+		case "decisionTrend":
+			list := make([]gqlObject, 0, len(decisionTrend))
+			for _, period := range decisionTrend {
+				list = append(list, graphQLScalars("DecisionTrendPeriod", map[string]any{"period": period.Period, "bought": period.BoughtCount, "skipped": period.SkippedCount}))
+			}
+			return list, nil
+		case "savedTrend":
+			list := make([]gqlObject, 0, len(savedTrend))
+			for _, period := range savedTrend {
+				list = append(list, graphQLScalars("SavedTrendPeriod", map[string]any{"period": period.Period, "savedCents": int64(period.Amount)}))
+			}
+			return list, nil
+		}
+		return nil, unknownGraphQLField("Insights", field)
+	}}
+}
+
+// graphQLItem exposes the fields of apiItem in camelCase. Times are RFC 3339 strings.
+func graphQLItem(item Item) gqlObject {
+	out := newAPIItem(item)
+	fields := map[string]any{
+		"id":                out.ID,
+		"title":             out.Title,
+		"price":             nilIfEmpty(out.Price),
+		"priceCents":        nil,
+		"link":              nilIfEmpty(out.Link),
+		"note":              nilIfEmpty(out.Note),
+		"tags":              out.Tags,
+		"status":            out.Status,
+		"waitPreset":        out.WaitPreset,
+		"purchaseAllowedAt": nil,
+		"createdAt":         out.CreatedAt.Format(time.RFC3339),
+		"decidedAt":         nil,
+	}
+	if item.HasPriceValue {
+		fields["priceCents"] = out.PriceCents
+	}
+	if out.PurchaseAllowedAt != nil {
+		fields["purchaseAllowedAt"] = out.PurchaseAllowedAt.Format(time.RFC3339)
+	}
+	if out.DecidedAt != nil {
+		fields["decidedAt"] = out.DecidedAt.Format(time.RFC3339)
+	}
+	return graphQLScalars("Item", fields)
+}
+
+// graphQLProfile lists a profile; the currency is only known for the active one.
+func graphQLProfile(name string, active bool, currency string) gqlObject {
+	return graphQLScalars("Profile", map[string]any{"name": name, "active": active, "currency": nilIfEmpty(currency)})
+}
+
+// graphQLScalars is an object whose fields are the given scalar values and take no arguments.
+func graphQLScalars(typeName string, fields map[string]any) gqlObject {
+	return gqlObject{Type: typeName, Resolve: func(field string, args map[string]any) (any, error) {
+		value, ok := fields[field]
+		if !ok {
+			return nil, unknownGraphQLField(typeName, field)
+		}
+		if err := checkGraphQLArgs(field, args); err != nil {
+			return nil, err
+		}
+		return value, nil
+	}}
+}
+
+func nilIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func unknownGraphQLField(typeName, field string) error {
+	return fmt.Errorf("cannot query field %q on type %s", field, typeName)
+}
+
+func checkGraphQLArgs(field string, args map[string]any, allowed ...string) error {
+	for name := range args {
+		if !slices.Contains(allowed, name) {
+			return fmt.Errorf("unknown argument %q on field %q", name, field)
+		}
+	}
+	return nil
+}
+
+// graphQLStringArg returns a string argument; ok is false when it is absent or null.
+func graphQLStringArg(args map[string]any, name string) (value string, ok bool, err error) {
+	raw, present := args[name]
+	if !present || raw == nil {
+		return "", false, nil
+	}
+	s, isString := raw.(string)
+	if !isString {
+		return "", false, fmt.Errorf("argument %q must be a string", name)
+	}
+	return strings.TrimSpace(s), true, nil
+}
+
+// graphQLIntArg returns an integer argument. Variables decoded from JSON arrive as float64.
+func graphQLIntArg(args map[string]any, name string) (value int, ok bool, err error) {
+	raw, present := args[name]
+	if !present || raw == nil {
+		return 0, false, nil
+	}
+	switch n := raw.(type) {
+	case int:
+		return n, true, nil
+	case float64:
+		if n == float64(int(n)) {
+			return int(n), true, nil
+		}
+	}
+	return 0, false, fmt.Errorf("argument %q must be an integer", name)
+}
+
+// graphQLStringListArg accepts a list of strings or, as GraphQL coerces single values into lists, one string.
+func graphQLStringListArg(args map[string]any, name string) ([]string, error) {
+	raw, present := args[name]
+	if !present || raw == nil {
+		return nil, nil
+	}
+	if s, ok := raw.(string); ok {
+		return []string{strings.TrimSpace(s)}, nil
+	}
+	list, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("argument %q must be a list of strings", name)
+	}
+	values := make([]string, 0, len(list))
+	for _, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("argument %q must be a list of strings", name)
+		}
+		values = append(values, strings.TrimSpace(s))
+	}
+	return values, nil
+}
@@ -0,0 +1,107 @@
+package web_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestGraphQLQueriesItemsProfilesAndInsights(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}, {Name: "Sam"}},
+		Items: []webtest.Item{
+			{Profile: "Alex", Title: "Desk lamp", Price: 3990, Tags: "Home", Status: "Waiting"},
+			{Profile: "Alex", Title: "Sneakers", Price: 12000, Tags: "Clothing", Status: "Skipped"},
+			{Profile: "Alex", Title: "Kettle", Price: 2500, Tags: "Home", Status: "Bought"},
+			{Profile: "Sam", Title: "Tent", Price: 20000, Status: "Waiting"},
+		},
+	})
+	alex := h.As("Alex")
+
+	query := `query Overview($statuses: [String!] = ["Waiting", "Skipped"]) {
+		home: items(tag: "Home", sort: price_asc) { title priceCents }
+		undecided: items(status: $statuses, first: 1, sort: "newest") { __typename title status }
+		profiles { name active }
+		profile { name }
+		insights { skippedCount savedCents topCategories { name count } }
+	}`
+	res := alex.PostJSON("/graphql", map[string]any{"query": query}).ExpectStatus(http.StatusOK)
+
+	var body struct {
+		Data struct {
+			Home []struct {
+				Title      string `json:"title"`
+				PriceCents int    `json:"priceCents"`
+			} `json:"home"`
+			Undecided []map[string]string `json:"undecided"`
+			Profiles  []struct {
+				Name   string `json:"name"`
+				Active bool   `json:"active"`
+			} `json:"profiles"`
+			Profile struct {
+				Name string `json:"name"`
+			} `json:"profile"`
+			Insights struct {
+				SkippedCount  int `json:"skippedCount"`
+				SavedCents    int `json:"savedCents"`
+				TopCategories []struct {
+					Name  string `json:"name"`
+					Count int    `json:"count"`
+				} `json:"topCategories"`
+			} `json:"insights"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(res.Body()), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	data := body.Data
+	if len(data.Home) != 2 || data.Home[0].Title != "Kettle" || data.Home[1].PriceCents != 3990 {
+		t.Fatalf("expected the Home items by price, got %+v", data.Home)
+	}
+	if len(data.Undecided) != 1 || data.Undecided[0]["__typename"] != "Item" || data.Undecided[0]["status"] == "Bought" {
+		t.Fatalf("expected one waiting or skipped item, got %+v", data.Undecided)
+	}
+	if len(data.Profiles) != 2 || !data.Profiles[0].Active || data.Profiles[1].Active || data.Profile.Name != "Alex" {
+		t.Fatalf("unexpected profiles %+v / %+v", data.Profiles, data.Profile)
+	}
+	if data.Insights.SkippedCount != 1 || data.Insights.SavedCents != 12000 || len(data.Insights.TopCategories) == 0 || data.Insights.TopCategories[0].Name != "home" {
+		t.Fatalf("unexpected insights %+v", data.Insights)
+	}
+
+	// Keys follow the order of the selection.
+	alex.Get("/graphql?query=" + url.QueryEscape(`{ profile { name currency } }`)).
+		ExpectStatus(http.StatusOK).
+		ExpectContains(`{"data":{"profile":{"name":"Alex","currency":`)
+}
+
+func TestGraphQLRejectsInvalidQueries(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}},
+		Items:    []webtest.Item{{Profile: "Alex", Title: "Desk lamp"}},
+	})
+	alex := h.As("Alex")
+
+	tests := []struct {
+		query, message string
+	}{
+		{`{ items { title `, `expected \"}\"`},
+		{`mutation { items { title } }`, "read-only"},
+		{`{ items { ...ItemFields } }`, "fragments are not supported"},
+		{`{ items { owner } }`, `cannot query field \"owner\" on type Item`},
+		{`{ items(status: "Lost") { title } }`, `unknown status \"Lost\"`},
+		{`{ items }`, "needs a selection of subfields"},
+		{`{ profile { name { first } } }`, "has no subfields"},
+	}
+	for _, tt := range tests {
+		alex.PostJSON("/graphql", map[string]any{"query": tt.query}).
+			ExpectStatus(http.StatusBadRequest).
+			ExpectContains(`"errors":[{"message":`, tt.message)
+	}
+
+	alex.PostJSON("/graphql", map[string]any{"query": `{ items { title } }`, "variables": map[string]any{"limit": 5}}).
+		ExpectStatus(http.StatusBadRequest).
+		ExpectContains("variable $limit is not declared")
+}
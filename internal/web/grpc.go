@@ -0,0 +1,306 @@
+package web
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"mvpapp/internal/domain"
+	pb "mvpapp/internal/impulsepausev1"
+)
+
+// grpcStatuses maps item statuses to their protobuf enum values.
+var grpcStatuses = map[domain.Status]pb.ItemStatus{
+	domain.StatusResearching: pb.ItemStatus_ITEM_STATUS_RESEARCHING,
+	domain.StatusWaiting:     pb.ItemStatus_ITEM_STATUS_WAITING,
+	domain.StatusReady:       pb.ItemStatus_ITEM_STATUS_READY,
+	domain.StatusBought:      pb.ItemStatus_ITEM_STATUS_BOUGHT,
+	domain.StatusSkipped:     pb.ItemStatus_ITEM_STATUS_SKIPPED,
+}
+
+// GRPCServer returns a gRPC server with the impulsepause.v1 ItemService and ProfileService, defined in
+// proto/impulsepause/v1. Every call must carry the admin token as "authorization: Bearer" metadata;
+// without a configured admin token all calls are rejected. Calls are traced like HTTP requests.
+func (a *App) GRPCServer() *grpc.Server {
+	server := grpc.NewServer(grpc.StatsHandler(otelgrpc.NewServerHandler()), grpc.UnaryInterceptor(a.requireGRPCAdmin))
+	pb.RegisterItemServiceServer(server, grpcItemService{a: a})
+	pb.RegisterProfileServiceServer(server, grpcProfileService{a: a})
+	return server
+}
+
+func (a *App) requireGRPCAdmin(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	a.mu.RLock()
+	adminToken := a.adminToken
+	a.mu.RUnlock()
+
+	provided := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 && strings.HasPrefix(values[0], "Bearer ") {
+			provided = strings.TrimSpace(strings.TrimPrefix(values[0], "Bearer "))
+		}
+	}
+	if adminToken == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(adminToken)) != 1 {
+		return nil, status.Error(codes.Unauthenticated, "invalid admin token")
+	}
+	return handler(ctx, req)
+}
+
+// lockGRPCProfile locks a.mu for the call in ctx and activates the requested profile, or the first one
+// when the name is empty. Calls run on an app of their own, see profileView, like HTTP requests. On
+// success the caller unlocks a.mu.
+func (a *App) lockGRPCProfile(ctx context.Context, name string) error {
+	name = strings.TrimSpace(name)
+	if name != "" {
+		names, err := a.listProfileNames()
+		if err != nil {
+			log.Printf("db error while listing profiles for grpc: %v", err)
+			return status.Error(codes.Internal, "could not load profiles")
+		}
+		if !slices.Contains(names, name) {
+			return status.Errorf(codes.NotFound, "profile %q not found", name)
+		}
+	}
+
+	a.mu.LockContext(ctx)
+	if err := a.activateProfileLocked(name); err != nil {
+		a.mu.Unlock()
+		log.Printf("db error while activating profile for grpc: %v", err)
+		return status.Error(codes.Internal, "could not activate profile")
+	}
+	if strings.TrimSpace(a.activeUserID) == "" {
+		a.mu.Unlock()
+		return status.Error(codes.FailedPrecondition, "no profile exists yet")
+	}
+	return nil
+}
+
+// grpcError maps domain errors to gRPC status codes. Validation errors carry a BadRequest detail with
+// the same per-field messages the forms show.
+func grpcError(err error, action string) error {
+	var invalid *domain.ValidationError
+	switch {
+	case errors.As(err, &invalid):
+		detail := &errdetails.BadRequest{}
+		for _, field := range invalid.Fields {
+			detail.FieldViolations = append(detail.FieldViolations, &errdetails.BadRequest_FieldViolation{Field: field.Field, Description: field.Message})
+		}
+		st, detailErr := status.New(codes.InvalidArgument, "validation failed").WithDetails(detail)
+		if detailErr != nil {
+			return status.Error(codes.InvalidArgument, invalid.Error())
+		}
+		return st.Err()
+	case errors.Is(err, domain.ErrItemQuotaReached):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, domain.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, domain.ErrValidation):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrConflict):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	log.Printf("db error while %s via grpc: %v", action, err)
+	return status.Errorf(codes.Internal, "could not %s", action)
+}
+
+func newGRPCItem(item Item) *pb.Item {
+	out := &pb.Item{
+		Id:         int64(item.ID),
+		Title:      item.Title,
+		Price:      item.Price,
+		Link:       item.Link,
+		Note:       item.Note,
+		Tags:       splitTags(item.Tags),
+		Status:     grpcStatuses[item.Status],
+		WaitPreset: item.WaitPreset,
+		CreatedAt:  timestamppb.New(item.CreatedAt),
+	}
+	if item.HasPriceValue {
+		cents := int64(item.PriceCents)
+		out.PriceCents = &cents
+	}
+	if !item.PurchaseAllowedAt.IsZero() {
+		out.PurchaseAllowedAt = timestamppb.New(item.PurchaseAllowedAt)
+	}
+	if !item.DecidedAt.IsZero() {
+		out.DecidedAt = timestamppb.New(item.DecidedAt)
+	}
+	return out
+}
+
+// grpcItemService implements pb.ItemServiceServer on top of the same item rules as the web handlers.
+type grpcItemService struct {
+	pb.UnimplementedItemServiceServer
+	a *App
+}
+
+func (s grpcItemService) ListItems(ctx context.Context, req *pb.ListItemsRequest) (*pb.ListItemsResponse, error) {
+	values := url.Values{"q": {req.GetQuery()}, "tag": {req.GetTag()}, "sort": {req.GetSort()}, "cursor": {req.GetPageToken()}}
+	for _, wanted := range req.GetStatuses() {
+		found := false
+		for itemStatus, value := range grpcStatuses {
+			if value == wanted {
+				values.Add("status", itemStatus.String())
+				found = true
+			}
+		}
+		if !found {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown status %v", wanted)
+		}
+	}
+	if req.GetPageSize() != 0 {
+		values.Set("limit", strconv.Itoa(int(req.GetPageSize())))
+	}
+	query, err := parseItemListQuery(values)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	a := s.a.profileView()
+	if err := a.lockGRPCProfile(ctx, req.GetProfile()); err != nil {
+		return nil, err
+	}
+	defer a.mu.Unlock()
+	a.promoteReadyItemsLocked(time.Now())
+	page, nextCursor := query.apply(a.items)
+
+	resp := &pb.ListItemsResponse{Items: make([]*pb.Item, 0, len(page)), NextPageToken: nextCursor}
+	for _, item := range page {
+		resp.Items = append(resp.Items, newGRPCItem(item))
+	}
+	return resp, nil
+}
+
+func (s grpcItemService) GetItem(ctx context.Context, req *pb.GetItemRequest) (*pb.Item, error) {
+	a := s.a.profileView()
+	if err := a.lockGRPCProfile(ctx, req.GetProfile()); err != nil {
+		return nil, err
+	}
+	defer a.mu.Unlock()
+	a.promoteReadyItemsLocked(time.Now())
+	for _, item := range a.items {
+		if int64(item.ID) == req.GetId() {
+			return newGRPCItem(item), nil
+		}
+	}
+	return nil, grpcError(domain.ErrItemNotFound, "load item")
+}
+
+func (s grpcItemService) CreateItem(ctx context.Context, req *pb.CreateItemRequest) (*pb.Item, error) {
+	input := apiItemInput{
+		Title:           req.GetTitle(),
+		Price:           req.GetPrice(),
+		Link:            req.GetLink(),
+		Note:            req.GetNote(),
+		Tags:            req.GetTags(),
+		WaitPreset:      req.GetWaitPreset(),
+		WaitCustomHours: req.GetWaitCustomHours(),
+		WaitText:        req.GetWaitText(),
+		Researching:     req.GetResearching(),
+	}
+	if req.GetPurchaseAllowedAt() != nil {
+		input.PurchaseAllowedAt = req.GetPurchaseAllowedAt().AsTime().Format(time.RFC3339)
+	}
+	draft := draftFromAPIInput(input)
+
+	a := s.a.profileView()
+	if err := a.lockGRPCProfile(ctx, req.GetProfile()); err != nil {
+		return nil, err
+	}
+	defer a.mu.Unlock()
+	if a.archived {
+		return nil, status.Error(codes.FailedPrecondition, errProfileArchived.Error())
+	}
+	a.applyWaitDefaultsLocked(&draft.Item, draft.WaitPreset != "")
+	created, err := a.itemServiceLocked().Create(draft)
+	if err != nil {
+		return nil, grpcError(err, "save item")
+	}
+	return newGRPCItem(created), nil
+}
+
+func (s grpcItemService) DecideItem(ctx context.Context, req *pb.DecideItemRequest) (*pb.Item, error) {
+	var decision domain.Status
+	switch req.GetDecision() {
+	case pb.ItemStatus_ITEM_STATUS_BOUGHT:
+		decision = domain.StatusBought
+	case pb.ItemStatus_ITEM_STATUS_SKIPPED:
+		decision = domain.StatusSkipped
+	default:
+		return nil, status.Error(codes.InvalidArgument, "decision must be ITEM_STATUS_BOUGHT or ITEM_STATUS_SKIPPED")
+	}
+
+	a := s.a.profileView()
+	if err := a.lockGRPCProfile(ctx, req.GetProfile()); err != nil {
+		return nil, err
+	}
+	defer a.mu.Unlock()
+	if a.archived {
+		return nil, status.Error(codes.FailedPrecondition, errProfileArchived.Error())
+	}
+	a.promoteReadyItemsLocked(time.Now())
+	item, err := a.itemServiceLocked().Decide(int(req.GetId()), decision)
+	if err != nil {
+		return nil, grpcError(err, "update item")
+	}
+	return newGRPCItem(item), nil
+}
+
+// grpcProfileService implements pb.ProfileServiceServer with the domain profile rules.
+type grpcProfileService struct {
+	pb.UnimplementedProfileServiceServer
+	a *App
+}
+
+func (s grpcProfileService) ListProfiles(ctx context.Context, req *pb.ListProfilesRequest) (*pb.ListProfilesResponse, error) {
+	names, err := s.a.listProfileNames()
+	if err != nil {
+		return nil, grpcError(err, "load profiles")
+	}
+	return &pb.ListProfilesResponse{Profiles: names}, nil
+}
+
+func (s grpcProfileService) DeleteProfile(ctx context.Context, req *pb.DeleteProfileRequest) (*pb.DeleteProfileResponse, error) {
+	a := s.a.profileView()
+	name := strings.TrimSpace(req.GetProfile())
+	names, err := a.listProfileNames()
+	if err != nil {
+		return nil, grpcError(err, "load profiles")
+	}
+	if !slices.Contains(names, name) {
+		return nil, status.Errorf(codes.NotFound, "profile %q not found", name)
+	}
+	if err := a.profileService().Delete(name); err != nil {
+		return nil, grpcError(err, "delete profile")
+	}
+
+	remoteAddr := ""
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		remoteAddr = p.Addr.String()
+		if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+			remoteAddr = host
+		}
+	}
+	a.mu.LockContext(ctx)
+	a.recordAuditFromLocked(name, auditProfileDeleted, "grpc", remoteAddr)
+	if a.activeUserID == name {
+		a.resetActiveProfileLocked()
+	}
+	a.mu.Unlock()
+	return &pb.DeleteProfileResponse{}, nil
+}
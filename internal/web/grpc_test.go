@@ -0,0 +1,105 @@
+package web_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "mvpapp/internal/impulsepausev1"
+	"mvpapp/internal/web/webtest"
+)
+
+// dialGRPC serves the app's gRPC services in memory and returns a client connection.
+func dialGRPC(t *testing.T, h *webtest.Harness) *grpc.ClientConn {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := h.App.GRPCServer()
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial grpc: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestGRPCItemServiceSharesTheItemRules(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}, {Name: "Sam"}},
+		Items: []webtest.Item{
+			{Profile: "Alex", Title: "Desk lamp", Price: 3990, Status: "Ready to buy"},
+			{Profile: "Sam", Title: "Tent", Price: 20000},
+		},
+	})
+	h.App.SetAdminToken("s3cret")
+	items := pb.NewItemServiceClient(dialGRPC(t, h))
+
+	if _, err := items.ListItems(context.Background(), &pb.ListItemsRequest{Profile: "Alex"}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected calls without the admin token to be rejected, got %v", err)
+	}
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer s3cret")
+
+	list, err := items.ListItems(ctx, &pb.ListItemsRequest{Profile: "Sam"})
+	if err != nil || len(list.Items) != 1 || list.Items[0].Title != "Tent" || list.Items[0].GetPriceCents() != 20000 {
+		t.Fatalf("expected Sam's tent, got %+v %v", list, err)
+	}
+	if _, err := items.ListItems(ctx, &pb.ListItemsRequest{Profile: "Nobody"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected unknown profiles to be reported, got %v", err)
+	}
+
+	created, err := items.CreateItem(ctx, &pb.CreateItemRequest{Profile: "Alex", Title: "Headphones", Price: "89", WaitPreset: "7d"})
+	if err != nil || created.Status != pb.ItemStatus_ITEM_STATUS_WAITING || created.PurchaseAllowedAt == nil {
+		t.Fatalf("expected a waiting item, got %+v %v", created, err)
+	}
+	if got := h.Item("Alex", "Headphones"); got.Price != "89" {
+		t.Fatalf("unexpected stored item %+v", got)
+	}
+
+	_, err = items.CreateItem(ctx, &pb.CreateItemRequest{Profile: "Alex", Title: " "})
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument || len(st.Details()) != 1 {
+		t.Fatalf("expected a validation error with details, got %v", err)
+	}
+	if detail, ok := st.Details()[0].(*errdetails.BadRequest); !ok || detail.FieldViolations[0].Field != "title" {
+		t.Fatalf("expected the title to be reported, got %+v", st.Details())
+	}
+
+	lamp := h.Item("Alex", "Desk lamp")
+	decided, err := items.DecideItem(ctx, &pb.DecideItemRequest{Profile: "Alex", Id: int64(lamp.ID), Decision: pb.ItemStatus_ITEM_STATUS_SKIPPED})
+	if err != nil || decided.Status != pb.ItemStatus_ITEM_STATUS_SKIPPED || decided.DecidedAt == nil {
+		t.Fatalf("expected the lamp to be skipped, got %+v %v", decided, err)
+	}
+	if _, err := items.DecideItem(ctx, &pb.DecideItemRequest{Profile: "Alex", Id: int64(lamp.ID), Decision: pb.ItemStatus_ITEM_STATUS_BOUGHT}); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected deciding twice to be refused, got %v", err)
+	}
+}
+
+func TestGRPCProfileServiceKeepsTheLastProfile(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{Profiles: []webtest.Profile{{Name: "Alex"}, {Name: "Sam"}}})
+	h.App.SetAdminToken("s3cret")
+	profiles := pb.NewProfileServiceClient(dialGRPC(t, h))
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer s3cret")
+
+	if _, err := profiles.DeleteProfile(ctx, &pb.DeleteProfileRequest{Profile: "Sam"}); err != nil {
+		t.Fatalf("delete profile: %v", err)
+	}
+	list, err := profiles.ListProfiles(ctx, &pb.ListProfilesRequest{})
+	if err != nil || len(list.Profiles) != 1 || list.Profiles[0] != "Alex" {
+		t.Fatalf("expected only Alex to remain, got %+v %v", list, err)
+	}
+	if _, err := profiles.DeleteProfile(ctx, &pb.DeleteProfileRequest{Profile: "Alex"}); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected the last profile to be kept, got %v", err)
+	}
+}
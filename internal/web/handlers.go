@@ -1,6 +1,7 @@
 package web
 
 import (
+	"context"
 	"database/sql"
 	"embed"
 	"errors"
@@ -8,34 +9,21 @@ import (
 	"html/template"
 	"io"
 	"log"
-	"math"
 	"net/http"
+	"net/url"
 	"slices"
 	"strconv"
 	"strings"
-	"sync"
+	"sync/atomic"
 	"time"
+
+	"mvpapp/internal/domain"
 )
 
 //go:embed templates/*.html assets/*.css
 var embeddedFiles embed.FS
 
-type Item struct {
-	ID                int
-	Title             string
-	Price             string
-	PriceValue        float64
-	HasPriceValue     bool
-	Link              string
-	Note              string
-	Tags              string
-	Status            string
-	WaitPreset        string
-	WaitCustomHours   string
-	PurchaseAllowedAt time.Time
-	CreatedAt         time.Time
-	NtfyAttempted     bool
-}
+type Item = domain.Item
 
 type homeViewData struct {
 	Title           string
@@ -48,28 +36,67 @@ type homeViewData struct {
 	TagFilter       string
 	TagOptions      []string
 	SortBy          string
+	// Within limits the list to the current week or month; see itemInPeriod.
+	Within string
+	// MinPrice and MaxPrice are the price range filter, empty when unbounded.
+	MinPrice        string
+	MaxPrice        string
 	HasActiveFilter bool
-	TotalItems      int
-	HourlyWage      float64
-	HasHourlyWage   bool
-	Currency        string
-	ActiveProfile   string
+	// FilterChips lists the applied filters, each with a link that removes it.
+	FilterChips []filterChip
+	// WorkFilters are the quick filters by work cost, shown when the profile has an hourly wage.
+	WorkFilters   []workFilterLink
+	TotalItems    int
+	HourlyWage    float64
+	HasHourlyWage bool
+	WorkEffort    workEffortFraming
+	// SplitWages holds the hourly wages of the profiles that split an item with the active one.
+	SplitWages    map[string]float64
+	Currency      string
+	ActiveProfile string
+	NeedsApproval map[int]bool
+	// Blackout is the blackout in effect, if any; HeldByBlackout marks the items it alone keeps waiting.
+	Blackout       *domain.Blackout
+	HeldByBlackout map[int]bool
+	// SimilarNotes sums up how similar past items were decided, for the ready items that have any.
+	SimilarNotes   map[int]string
+	SetupPending   bool
+	Archived       bool
+	DemoResetEvery string
+	// Confirmation announces the outcome of the item action that redirected here.
+	Confirmation string
+	Summary      homeSummary
+	// UnlockingSoonCount is the number of leading items shown under "Unlocking soon"; see unlocksSoon.
+	UnlockingSoonCount int
 }
 
 type insightsViewData struct {
-	Title           string
-	CurrentPath     string
-	ContentTemplate string
-	ScriptTemplate  string
-	ItemCount       int
-	SkippedCount    int
-	SavedAmount     float64
-	TopCategories   []categoryCount
-	DecisionTrend   []monthlyDecisionTrend
-	SavedTrend      []monthlySavedAmount
-	CategoryRatios  []categorySkipRatio
-	Currency        string
-	ActiveProfile   string
+	Title            string
+	CurrentPath      string
+	ContentTemplate  string
+	ScriptTemplate   string
+	ItemCount        int
+	SkippedCount     int
+	ResearchingCount int
+	RegretCategories []categoryRegretRate
+	SavedAmount      domain.Money
+	TopCategories    []categoryCount
+	TrendGranularity string
+	DecisionTrend    []decisionTrendPeriod
+	SavedTrend       []savedAmountPeriod
+	CategoryRatios   []categorySkipRatio
+	TrendSettings    trendSettingsForm
+	WeekStartOptions []string
+	// Projection is nil until something was saved.
+	Projection         *savingsProjection
+	ProjectionSettings projectionSettingsForm
+	Currency           string
+	Error              string
+	Feedback           string
+	ActiveProfile      string
+	// HoursGoal is nil while no yearly work-hours goal is set.
+	HoursGoal      *hoursGoalProgress
+	HoursGoalInput string
 }
 
 type categoryCount struct {
@@ -77,15 +104,15 @@ type categoryCount struct {
 	Count int
 }
 
-type monthlyDecisionTrend struct {
-	Month        string
+type decisionTrendPeriod struct {
+	Period       string
 	BoughtCount  int
 	SkippedCount int
 }
 
-type monthlySavedAmount struct {
-	Month  string
-	Amount float64
+type savedAmountPeriod struct {
+	Period string
+	Amount domain.Money
 }
 
 type categorySkipRatio struct {
@@ -109,11 +136,29 @@ type itemFormViewData struct {
 	TagOptions           []string
 	SelectedTags         map[string]bool
 	PurchaseAllowedInput string
+	WaitText             string
+	CreatedAtInput       string
+	DecidedAtInput       string
+	DecisionInput        string
 	Error                string
+	FieldErrors          map[string]string
 	Currency             string
 	ActiveProfile        string
+	IsOwner              bool
+	ShareCandidates      []string
+	MoveCandidates       []string
+	History              []historyEntry
+	ItemTemplates        []itemTemplate
+	SelectedTemplate     int
+	WaitPresetExplicit   bool
+	// Payday is the profile's day of the month; the payday wait is only offered once it is set.
+	Payday int
 }
 
+// fieldErrorSummary heads a form whose rejected inputs carry their own messages.
+const fieldErrorSummary = "Please correct the highlighted fields."
+
+// defaultTagOptions are the built-in starter tags, used unless SetStarterTags configures others.
 var defaultTagOptions = []string{"Tech", "Audio", "Gaming", "Home", "Fashion", "Sports", "Office", "Travel", "Health", "Education"}
 
 type profileViewData struct {
@@ -127,10 +172,41 @@ type profileViewData struct {
 	DefaultWaitCustomHours string
 	NtfyEndpoint           string
 	NtfyTopic              string
-	Currency               string
-	ProfileError           string
-	ProfileFeedback        string
-	ActiveProfile          string
+	WorkHoursMode          string
+	ShiftHours             string
+	WorkHoursPrecision     string
+	WorkHoursRounding      string
+	LandingPage            string
+	MonthlyIncome          string
+	WeeklyHours            string
+	RenotifyPolicy         string
+	RenotifyDays           string
+	NotifyDryRun           bool
+	GlobalNotifyDryRun     bool
+	NumberFormat           string
+	Payday                 string
+	AvatarEmoji            string
+	AvatarColor            string
+	AvatarColors           []string
+	// IncomeSummary shows the wage in the representation the profile did not enter.
+	IncomeSummary   string
+	Currency        string
+	CurrencyOptions []currencyInfo
+	AuditLog        []auditEntry
+	ProfileError    string
+	FieldErrors     map[string]string
+	ProfileFeedback string
+	ShareURL        string
+	QuickAddURL     string
+	ShareExpiresOn  string
+	ShareExpired    bool
+	ShareViews      int
+	ShareViewedAt   time.Time
+	ActiveProfile   string
+	// Archived shows the restore button instead of the archive button.
+	Archived bool
+	// CanArchive hides the archive button for apps without a database.
+	CanArchive bool
 }
 
 type pageData struct {
@@ -147,6 +223,9 @@ type tagSettingsViewData struct {
 	ContentTemplate string
 	ScriptTemplate  string
 	TagOptions      []string
+	TagWaitDefaults map[string]string
+	WaitOptions     []string
+	StarterTags     []string
 	NewTag          string
 	Error           string
 	Feedback        string
@@ -162,13 +241,15 @@ type profileSwitchViewData struct {
 	Names           []string
 	Error           string
 	ActiveProfile   string
+	DemoAvailable   bool
+	InviteOnly      bool
 }
 
 type App struct {
 	templates              *template.Template
 	mux                    *http.ServeMux
-	db                     *sql.DB
-	mu                     sync.RWMutex
+	db                     *scopedDB
+	mu                     stateLock
 	items                  []Item
 	hourlyWage             string
 	defaultWaitPreset      string
@@ -179,12 +260,78 @@ type App struct {
 	dashboardURL           string
 	nextID                 int
 	activeUserID           string
+	loadedRevision         storedRevision
 	profileExists          bool
 	tagCatalog             []string
+	starterTags            []string
+	onboardingStep         string
+	metricsOptIn           bool
+	haWebhookURL           string
+	workHoursMode          string
+	shiftHours             string
+	monthlyIncome          string
+	weeklyHours            string
+	projectionRate         string
+	projectionYears        int
+	renotifyPolicy         string
+	renotifyDays           int
+	notifyDryRun           bool
+	numberFormat           string
+	payday                 int
+	blackouts              []domain.Blackout
+	routingRules           []domain.RoutingRule
+	avatarEmoji            string
+	avatarColor            string
+	hoursGoal              int
+	hoursGoalCelebrated    string
+	workHoursPrecision     string
+	workHoursRounding      string
+	noteKeySalt            string
+	noteKeyCheck           string
+	landingPage            string
+	lastVisited            string
+	dashboardFilters       string
+	avatars                *avatarCache
+	archived               bool
+	shareToken             string
+	shareExpiresAt         time.Time
+	retentionMonths        int
+	fireflyURL             string
+	fireflyToken           string
+	fireflyAccount         string
+	approvalThreshold      domain.Money
+	approver               string
+	itemTemplates          []itemTemplate
+	tagWaitDefaults        map[string]string
+	trendTimezone          string
+	weekStart              string
+	monthStartDay          int
+	tokenUses              *tokenUses
+	noteKeys               *noteKeyring
+	adminToken             string
+	notificationsDryRun    bool
+	publicStats            bool
+	demoResetInterval      time.Duration
+	jobs                   *jobScheduler
+	ntfyBreakers           *ntfyBreakers
+	events                 domain.Bus
+	idempotencyKeys        map[string]idempotentResponse
+	webPush                *webPushKeys
+	itemHook               *itemHook
+	requestSLO             time.Duration
+	coalesceWindow         time.Duration
+	sqliteOptions          SQLiteOptions
+	lastMaintenance        *atomic.Pointer[maintenanceRun]
+	itemQuota              int
+	attachmentQuota        int64
+	inviteOnly             bool
+	// promoting defers the delivery of ready notifications until a promotion run is complete, so its
+	// items are announced together.
+	promoting bool
 }
 
 func NewApp() *App {
-	app, err := newAppWithDB(nil)
+	app, err := newAppWithDB(nil, nil)
 	if err != nil {
 		panic(err)
 	}
@@ -192,86 +339,277 @@ func NewApp() *App {
 }
 
 func NewAppWithSQLite(dbPath string) (*App, error) {
-	db, err := openSQLite(dbPath)
+	return NewAppWithSQLiteOptions(dbPath, DefaultSQLiteOptions())
+}
+
+// NewAppWithSQLiteOptions is NewAppWithSQLite with tuned connection settings.
+func NewAppWithSQLiteOptions(dbPath string, opts SQLiteOptions) (*App, error) {
+	scope := &traceScope{}
+	db, err := openSQLite(dbPath, opts, scope)
 	if err != nil {
 		return nil, err
 	}
 
-	app, err := newAppWithDB(db)
+	app, err := newAppWithDB(db, scope)
 	if err != nil {
 		_ = db.Close()
 		return nil, err
 	}
+	app.sqliteOptions = opts
 	return app, nil
 }
 
-func newAppWithDB(db *sql.DB) (*App, error) {
-	tpls := template.Must(template.New("").Funcs(template.FuncMap{
+// newAppWithDB creates the app on db. scope is shared with db's traced driver, or nil when untraced.
+func newAppWithDB(db *sql.DB, scope *traceScope) (*App, error) {
+	mux := http.NewServeMux()
+
+	activeUserID := defaultUserID
+	if db != nil {
+		activeUserID = ""
+	}
+	app := &App{mux: mux, mu: stateLock{scope: scope}, nextID: 1, activeUserID: activeUserID, starterTags: defaultTagOptions,
+		jobs: &jobScheduler{}, ntfyBreakers: &ntfyBreakers{}, avatars: &avatarCache{}, noteKeys: &noteKeyring{}, tokenUses: &tokenUses{},
+		lastMaintenance: &atomic.Pointer[maintenanceRun]{}}
+	if db != nil {
+		app.db = &scopedDB{DB: db, lock: &app.mu}
+	}
+	app.templates = template.Must(template.New("").Funcs(template.FuncMap{
 		"statusBadgeClass":   statusBadgeClass,
 		"workHoursAvailable": workHoursAvailable,
 		"formatWorkHours":    formatWorkHours,
+		"formatWorkEffort":   formatWorkEffort,
+		"splitShares":        splitShares,
 		"formatMoney":        formatMoney,
 		"mul100":             mul100,
+		"join":               strings.Join,
+		"navLinks":           navLinks,
+		"navSection":         navSection,
+		"breadcrumbs":        breadcrumbs,
+		"childPages":         childPages,
+		"formatBytes":        formatBytes,
+		"profileAvatar":      app.profileAvatar,
+		"isSealedNote":       isSealedNote,
+		"buildInfo":          CurrentBuild,
 	}).ParseFS(embeddedFiles, "templates/*.html"))
-	mux := http.NewServeMux()
-
-	activeUserID := defaultUserID
-	if db != nil {
-		activeUserID = ""
+	app.tagCatalog = app.starterTagsLocked()
+	app.subscribeEventHandlers()
+	if err := app.quarantineBadRows(); err != nil {
+		return nil, err
 	}
-	app := &App{templates: tpls, mux: mux, db: db, nextID: 1, activeUserID: activeUserID, tagCatalog: append([]string(nil), defaultTagOptions...)}
 	if err := app.loadStateFromDB(app.activeUserID); err != nil {
 		return nil, err
 	}
 	app.routes()
 	app.StartBackgroundPromotion(5 * time.Second)
+	app.StartBackgroundPurge(time.Hour)
+	app.StartBackgroundMaintenance(24 * time.Hour)
+	app.StartBackgroundOutbox(30 * time.Second)
+	app.StartLeaderboardDigest()
+	app.StartReadyDigest()
 
 	return app, nil
 }
 
+// profileView returns an app for one request, gRPC call or job run to load its profile into. It shares
+// a's database, settings and background jobs, but has its own profile state and lock, so requests for
+// different profiles neither wait for each other nor switch the profile under each other. Apps without a
+// database keep all profiles' items in memory and return themselves.
+func (a *App) profileView() *App {
+	if a.db == nil {
+		return a
+	}
+	view := &App{templates: a.templates, mux: a.mux, mu: stateLock{scope: &traceScope{}}, nextID: 1, defaultWaitPreset: domain.NormalizeWaitPreset(""),
+		jobs: a.jobs, ntfyBreakers: a.ntfyBreakers, avatars: a.avatars, noteKeys: a.noteKeys, tokenUses: a.tokenUses, lastMaintenance: a.lastMaintenance}
+	view.db = &scopedDB{DB: a.db.DB, lock: &view.mu}
+	a.mu.RLock()
+	view.dashboardURL, view.starterTags, view.adminToken = a.dashboardURL, a.starterTags, a.adminToken
+	view.notificationsDryRun, view.publicStats, view.demoResetInterval = a.notificationsDryRun, a.publicStats, a.demoResetInterval
+	view.webPush, view.itemHook, view.requestSLO, view.coalesceWindow = a.webPush, a.itemHook, a.requestSLO, a.coalesceWindow
+	view.sqliteOptions, view.itemQuota, view.attachmentQuota, view.inviteOnly = a.sqliteOptions, a.itemQuota, a.attachmentQuota, a.inviteOnly
+	a.mu.RUnlock()
+	view.tagCatalog = view.starterTagsLocked()
+	view.subscribeEventHandlers()
+	return view
+}
+
+type requestAppKey struct{}
+
+// requestApp returns the app that loadProfileMiddleware loaded r's profile into, or a for requests that
+// do not act on a profile.
+func (a *App) requestApp(r *http.Request) *App {
+	if view, ok := r.Context().Value(requestAppKey{}).(*App); ok {
+		return view
+	}
+	return a
+}
+
+// handle registers the handler method h for pattern, called on the app that holds the request's profile.
+func (a *App) handle(pattern string, h func(*App, http.ResponseWriter, *http.Request)) {
+	a.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		h(a.requestApp(r), w, r)
+	})
+}
+
+// routes registers method-aware patterns. GET patterns also serve HEAD, and the mux answers
+// requests with a known path but another method with 405 Method Not Allowed.
 func (a *App) routes() {
-	a.mux.HandleFunc("/", a.home)
-	a.mux.HandleFunc("/switch-profile", a.switchProfile)
-	a.mux.HandleFunc("/items/new", a.itemForm)
-	a.mux.HandleFunc("/items/edit", a.editItemForm)
-	a.mux.HandleFunc("/items/delete", a.deleteItem)
-	a.mux.HandleFunc("/items/snooze", a.snoozeItem)
-	a.mux.HandleFunc("/insights", a.insights)
-	a.mux.HandleFunc("/settings/profile", a.profileSettings)
-	a.mux.HandleFunc("/settings/tags", a.tagSettings)
-	a.mux.HandleFunc("/settings/profile/delete", a.deleteProfile)
-	a.mux.HandleFunc("/profile", a.legacyProfile)
-	a.mux.HandleFunc("/items/status", a.updateItemStatus)
-	a.mux.HandleFunc("/healthz", a.health)
-	a.mux.HandleFunc("/about", a.about)
-	a.mux.Handle("/assets/", http.FileServer(http.FS(embeddedFiles)))
+	a.handle("GET /{$}", (*App).home)
+	a.handle("POST /dashboard/filters/reset", (*App).resetDashboardFilters)
+	a.handle("GET /switch-profile", (*App).chooseProfile)
+	a.handle("POST /switch-profile", (*App).switchProfile)
+
+	a.handle("GET /demo", (*App).openDemo)
+	a.handle("GET /onboarding", (*App).onboarding)
+	a.handle("POST /onboarding", (*App).saveOnboardingStep)
+	a.handle("GET /items/new", (*App).itemForm)
+	a.handle("GET /quick-add", (*App).quickAdd)
+	a.handle("POST /items/new", (*App).createItem)
+	a.handle("GET /items/export", (*App).exportItems)
+	a.handle("GET /items/{id}/edit", (*App).editItemForm)
+	a.handle("POST /items/{id}/edit", (*App).updateItem)
+	// Query-string form of the edit page, kept for bookmarks from before path parameters.
+	a.handle("GET /items/edit", (*App).editItemForm)
+	a.handle("POST /items/edit", (*App).updateItem)
+	a.handle("POST /items/delete", (*App).deleteItem)
+	a.handle("POST /items/snooze", (*App).snoozeItem)
+	a.handle("POST /items/start-wait", (*App).startWait)
+	a.handle("POST /items/override-blackout", (*App).overrideBlackout)
+	a.handle("POST /items/satisfaction", (*App).rateSatisfaction)
+	a.handle("POST /items/receipt", (*App).saveReceipt)
+	a.handle("GET /items/{id}/receipt", (*App).serveReceipt)
+	a.handle("POST /items/share", (*App).shareItem)
+	a.handle("POST /items/split", (*App).splitItem)
+	a.handle("POST /items/move", (*App).transferItem)
+	a.handle("POST /items/approval", (*App).itemApproval)
+	a.handle("POST /items/status", (*App).updateItemStatus)
+
+	a.handle("GET /insights", (*App).insights)
+	a.handle("GET /calendar", (*App).calendar)
+	a.handle("GET /timeline", (*App).timeline)
+	a.handle("POST /insights", (*App).saveTrendSettings)
+	a.handle("POST /insights/projection", (*App).saveProjectionSettings)
+	a.handle("POST /insights/goal", (*App).saveHoursGoal)
+	a.handle("GET /about", (*App).about)
+	a.handle("GET /healthz", (*App).health)
+	a.handle("GET /version", (*App).version)
+	a.handle("GET /metrics", (*App).metrics)
+	a.handle("GET /api/v1/items", (*App).apiListItems)
+	a.handle("POST /api/v1/items", (*App).apiCreateItem)
+	a.handle("POST /api/v1/items/{id}/decision", (*App).apiDecideItem)
+	a.handle("POST /api/v1/items/{id}/snooze", (*App).apiSnoozeItem)
+	a.handle("GET /api/v1/changes", (*App).apiChanges)
+	a.handle("GET /api/v1/wait-simulation", (*App).apiSimulateWait)
+	a.handle("GET /api/v1/wait-preview", (*App).apiPreviewWait)
+	a.handle("GET /api/v1/tags", (*App).apiListTags)
+	a.handle("GET /api/v1/push/public-key", (*App).apiPushPublicKey)
+	a.handle("POST /api/v1/push/subscriptions", (*App).apiRegisterPushSubscription)
+	a.handle("DELETE /api/v1/push/subscriptions", (*App).apiUnregisterPushSubscription)
+	a.handle("GET /api/v1/home-assistant", (*App).homeAssistantState)
+	a.handle("GET /api/schemas/{$}", (*App).apiSchemas)
+	a.handle("GET /api/schemas/{name}", (*App).apiSchema)
+	a.handle("GET /graphql", (*App).graphQL)
+	a.handle("POST /graphql", (*App).graphQL)
+	a.handle("GET /kiosk", (*App).kiosk)
+	a.handle("GET /kiosk/card.png", (*App).kioskCard)
+	a.handle("GET /following", (*App).following)
+	a.handle("POST /following", (*App).saveFollowing)
+	a.handle("GET /leaderboard", (*App).leaderboardPage)
+	a.handle("POST /leaderboard", (*App).saveLeaderboard)
+	a.mux.HandleFunc("GET /robots.txt", robots)
+	a.handle("GET /stats", (*App).publicStatsPage)
+	a.handle("GET /household", (*App).household)
+	a.handle("POST /household/maintenance", (*App).runMaintenance)
+	a.handle("POST /household/jobs/{name}/run", (*App).runJobNow)
+	a.handle("POST /household/invites", (*App).createInvite)
+	a.handle("POST /household/invites/revoke", (*App).revokeInvite)
+	a.handle("POST /household/profiles/restore", (*App).restoreArchivedProfile)
+	a.handle("GET /invite/{token}", (*App).showInvite)
+	a.handle("POST /invite/{token}", (*App).acceptInvite)
+
+	a.handle("GET /settings/profile", (*App).profileSettings)
+	a.handle("GET /settings/qr.png", (*App).settingsQRCode)
+	a.handle("POST /settings/profile", (*App).saveProfile)
+	a.handle("POST /settings/profile/delete", (*App).deleteProfile)
+	a.handle("POST /settings/profile/archive", (*App).archiveProfile)
+	a.handle("POST /settings/profile/restore", (*App).restoreActiveProfile)
+	a.handle("GET /profile", (*App).legacyProfile)
+	a.handle("POST /profile", (*App).saveProfile)
+	a.handle("GET /settings/tags", (*App).tagSettings)
+	a.handle("POST /settings/tags", (*App).saveTagSettings)
+	a.handle("GET /settings/approvals", (*App).approvalSettings)
+	a.handle("GET /settings/wait-check", (*App).waitSimulationPage)
+	a.handle("GET /settings/notification-log", (*App).deliveryLog)
+	a.handle("POST /settings/approvals", (*App).saveApprovalSettings)
+	a.handle("GET /settings/blackouts", (*App).blackoutSettings)
+	a.handle("POST /settings/blackouts", (*App).saveBlackouts)
+	a.handle("GET /settings/routing", (*App).routingSettings)
+	a.handle("POST /settings/routing", (*App).saveRoutingRules)
+	a.handle("GET /settings/rules", (*App).rulesSettings)
+	a.handle("POST /settings/rules", (*App).importRules)
+	a.handle("GET /settings/rules/export", (*App).exportRules)
+	a.handle("GET /settings/templates", (*App).templateSettings)
+	a.handle("POST /settings/templates", (*App).saveTemplateSettings)
+	a.handle("POST /settings/share", (*App).shareSettings)
+	a.handle("GET /settings/data", (*App).dataSettings)
+	a.handle("POST /settings/data", (*App).saveDataSettings)
+	a.handle("POST /settings/data/wipe", (*App).wipeProfileData)
+	a.handle("POST /settings/data/metrics", (*App).saveMetricsOptIn)
+	a.handle("POST /settings/data/notes", (*App).saveNoteEncryption)
+	a.handle("GET /settings/reconcile", (*App).reconcileSettings)
+	a.handle("POST /settings/reconcile", (*App).saveReconcile)
+	a.handle("GET /settings/exports", (*App).exportSettings)
+	a.handle("POST /settings/exports", (*App).saveExportSettings)
+	a.handle("GET /settings/home-assistant", (*App).homeAssistantSettings)
+	a.handle("POST /settings/home-assistant", (*App).saveHomeAssistantSettings)
+
+	a.handle("GET /exports/ynab.csv", (*App).exportYNAB)
+	a.handle("GET /exports/firefly.csv", (*App).exportFireflyCSV)
+	a.handle("POST /exports/firefly/push", (*App).pushFirefly)
+	a.mux.Handle("GET /assets/", http.FileServer(http.FS(embeddedFiles)))
+}
+
+// itemIDFromRequest reads the item ID from the {id} path parameter, or from the id query parameter on legacy URLs.
+func itemIDFromRequest(r *http.Request) (int, error) {
+	raw := r.PathValue("id")
+	if raw == "" {
+		raw = r.URL.Query().Get("id")
+	}
+	id, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil || id <= 0 {
+		return 0, errors.New("invalid item id")
+	}
+	return id, nil
+}
+
+func itemEditPath(id int) string {
+	return "/items/" + strconv.Itoa(id) + "/edit"
 }
 
 func (a *App) Handler() http.Handler {
-	return loggingMiddleware(a.mux)
+	return tracingMiddleware(a.mux, a.loggingMiddleware(a.archivedProfileGuard(a.loadProfileMiddleware(a.rememberVisitMiddleware(a.mux)))))
 }
 
+// Close releases the database. In-memory apps have nothing to release.
+func (a *App) Close() error {
+	if a.db == nil {
+		return nil
+	}
+	return a.db.Close()
+}
+
+// StartBackgroundPromotion registers the "promotion" job, which moves items whose waiting time is over to
+// ready every interval, starting right away.
 func (a *App) StartBackgroundPromotion(interval time.Duration) {
 	if interval <= 0 {
 		interval = 5 * time.Second
 	}
 
-	go func() {
-		promote := func() {
-			a.mu.Lock()
-			a.promoteReadyItemsLocked(time.Now())
-			a.mu.Unlock()
-		}
-
-		promote()
-
-		ticker := time.NewTicker(interval)
-		defer ticker.Stop()
-
-		for range ticker.C {
-			promote()
-		}
-	}()
+	a.registerJob("promotion", "@every "+interval.String(), 0, true, func(a *App, now time.Time) error {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		a.promoteReadyItemsLocked(now)
+		return nil
+	})
 }
 
 func (a *App) SetDashboardURL(raw string) {
@@ -280,7 +618,81 @@ func (a *App) SetDashboardURL(raw string) {
 	a.mu.Unlock()
 }
 
+// SetStarterTags replaces the built-in starter tags with a comma-separated list. New profiles, and
+// profiles that reset their tags, start from this list. An empty list keeps the built-in tags.
+func (a *App) SetStarterTags(raw string) {
+	tags := parseTagCatalog(raw)
+	if len(tags) == 0 {
+		return
+	}
+	a.mu.Lock()
+	a.starterTags = tags
+	if !a.profileExists {
+		a.tagCatalog = a.starterTagsLocked()
+	}
+	a.mu.Unlock()
+}
+
+func (a *App) starterTagsLocked() []string {
+	return append([]string(nil), a.starterTags...)
+}
+
+// profileFreePaths never act on a profile, so requests for them do not load one.
+var profileFreePaths = []string{"/healthz", "/version", "/metrics", "/robots.txt"}
+
+// profileFreeRequest reports whether r never acts on the active profile. Running a job by hand is one:
+// the job loads its profiles into an app of its own.
+func profileFreeRequest(r *http.Request) bool {
+	return slices.Contains(profileFreePaths, r.URL.Path) || strings.HasPrefix(r.URL.Path, "/assets/") ||
+		strings.HasPrefix(r.URL.Path, "/api/schemas/") || strings.HasPrefix(r.URL.Path, "/household/jobs/")
+}
+
+type profileLoadedKey struct{}
+
+// loadProfileMiddleware loads the profile named by the active_profile cookie, or the first profile without
+// one, from storage before every request, so a request sees the same state whichever instance behind a
+// load balancer serves it, and whichever profile that instance served last. The profile is loaded into
+// the request's own app, see profileView, which its handler then runs on.
+func (a *App) loadProfileMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if profileFreeRequest(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		view := a.profileView()
+		r = r.WithContext(context.WithValue(r.Context(), requestAppKey{}, view))
+		if err := view.activateProfileFromRequest(r); err != nil {
+			log.Printf("db error while loading profile: %v", err)
+			http.Error(w, "could not activate profile", http.StatusInternalServerError)
+			return
+		}
+		if view.uninvitedProfileRequest(r) {
+			http.SetCookie(w, &http.Cookie{Name: "active_profile", Value: "", Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode, MaxAge: -1})
+			http.Redirect(w, r, "/switch-profile", http.StatusSeeOther)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), profileLoadedKey{}, true)))
+	})
+}
+
+// uninvitedProfileRequest reports whether r names a profile that does not exist while the instance is
+// invite-only. Such a cookie was not set by the app, and serving it would create the profile on its first
+// write; only choosing a profile and accepting an invite may go ahead.
+func (a *App) uninvitedProfileRequest(r *http.Request) bool {
+	if r.URL.Path == "/switch-profile" || strings.HasPrefix(r.URL.Path, "/invite/") {
+		return false
+	}
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.inviteOnly && a.db != nil && a.activeUserID != "" && !a.profileExists
+}
+
+// activateProfileFromRequest loads the profile named by the active_profile cookie, or the first profile
+// without one. Requests whose profile the middleware already loaded keep it.
 func (a *App) activateProfileFromRequest(r *http.Request) error {
+	if loaded, _ := r.Context().Value(profileLoadedKey{}).(bool); loaded {
+		return nil
+	}
 	cookie, err := r.Cookie("active_profile")
 	if err != nil {
 		if errors.Is(err, http.ErrNoCookie) {
@@ -295,28 +707,57 @@ func (a *App) activateProfileFromRequest(r *http.Request) error {
 		name = strings.TrimSpace(cookie.Value)
 	}
 
-	a.mu.Lock()
+	a.mu.LockContext(r.Context())
 	defer a.mu.Unlock()
+	return a.activateProfileLocked(name)
+}
 
+// activateProfileLocked loads the named profile's state, or the first profile's when name is empty. With a
+// database the loaded state is kept only while nothing was written since, as another instance may share it.
+func (a *App) activateProfileLocked(name string) error {
 	if name == "" {
 		if a.db == nil {
 			return nil
 		}
-		name, err = a.firstProfileNameByIDLocked()
+		first, err := a.firstProfileNameByIDLocked()
 		if err != nil {
 			return err
 		}
-		if name == "" {
+		if first == "" {
 			a.activeUserID = ""
 			return nil
 		}
+		name = first
 	}
 
-	if a.activeUserID == name {
+	if a.db == nil {
+		if a.activeUserID == name {
+			return nil
+		}
+		a.activeUserID = name
+		return a.loadStateFromDB(name)
+	}
+
+	revision, err := a.storedRevisionLocked()
+	if err != nil {
+		return err
+	}
+	if a.activeUserID == name && a.loadedRevision == revision {
 		return nil
 	}
 	a.activeUserID = name
-	return a.loadStateFromDB(name)
+	if err := a.loadStateFromDB(name); err != nil {
+		return err
+	}
+	a.loadedRevision = revision
+	return nil
+}
+
+// storedRevision is read before a profile is loaded, so the load is repeated whenever the stored state
+// moved on since, whichever instance wrote it.
+type storedRevision struct {
+	Items    int64
+	Profiles int64
 }
 
 func (a *App) firstProfileNameByIDLocked() (string, error) {
@@ -325,7 +766,7 @@ func (a *App) firstProfileNameByIDLocked() (string, error) {
 	}
 
 	var name string
-	err := a.db.QueryRow(`SELECT user_id FROM profiles ORDER BY rowid ASC LIMIT 1`).Scan(&name)
+	err := a.db.QueryRow(`SELECT user_id FROM profiles WHERE archived_at = '' ORDER BY rowid ASC LIMIT 1`).Scan(&name)
 	if errors.Is(err, sql.ErrNoRows) {
 		err = a.db.QueryRow(`SELECT user_id FROM items GROUP BY user_id ORDER BY MIN(id) ASC LIMIT 1`).Scan(&name)
 	}
@@ -340,68 +781,62 @@ func (a *App) firstProfileNameByIDLocked() (string, error) {
 }
 
 func (a *App) home(w http.ResponseWriter, r *http.Request) {
-	if r.URL.Path != "/" {
-		http.NotFound(w, r)
+	if err := a.activateProfileFromRequest(r); err != nil {
+		http.Error(w, "could not activate profile", http.StatusInternalServerError)
 		return
 	}
-
-	switch r.Method {
-	case http.MethodGet, http.MethodHead:
-		if err := a.activateProfileFromRequest(r); err != nil {
-			http.Error(w, "could not activate profile", http.StatusInternalServerError)
-			return
-		}
-		if !a.hasActiveProfile() {
-			http.Redirect(w, r, "/switch-profile", http.StatusSeeOther)
-			return
-		}
+	req := homeRequest{HasActiveProfile: a.hasActiveProfile(), HasProfile: a.hasProfile(), WithinApp: requestWithinApp(r)}
+	a.mu.RLock()
+	req.LandingPage, req.LastVisited = a.landingPage, a.lastVisited
+	a.mu.RUnlock()
+	if req.HasActiveProfile {
 		if _, err := r.Cookie("active_profile"); errors.Is(err, http.ErrNoCookie) {
 			http.SetCookie(w, &http.Cookie{Name: "active_profile", Value: a.activeProfileName(), Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode})
 		}
-		if !a.hasProfile() {
-			http.Redirect(w, r, "/settings/profile", http.StatusSeeOther)
-			return
-		}
-		a.renderHome(w, r, homeViewData{Title: "Impulse Pause", CurrentPath: "/"})
-	default:
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 	}
-}
-
-func (a *App) insights(w http.ResponseWriter, r *http.Request) {
-	if r.URL.Path != "/insights" {
-		http.NotFound(w, r)
+	if target := homeRoute(req); target != "" {
+		http.Redirect(w, r, target, http.StatusSeeOther)
 		return
 	}
+	a.renderHome(w, r, homeViewData{Title: "Impulse Pause", CurrentPath: "/"})
+}
 
-	switch r.Method {
-	case http.MethodGet, http.MethodHead:
-		a.renderInsights(w, insightsViewData{Title: "Insights", CurrentPath: "/insights"})
-	default:
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+func (a *App) insights(w http.ResponseWriter, r *http.Request) {
+	data := insightsViewData{Title: "Insights", CurrentPath: "/insights", TrendGranularity: normalizeTrendGranularity(r.URL.Query().Get("period"))}
+	switch r.URL.Query().Get("saved") {
+	case "trends":
+		data.Feedback = "Trend periods saved."
+	case "projection":
+		data.Feedback = "Savings projection saved."
+	case "goal":
+		data.Feedback = "Work-hours goal saved."
 	}
+	a.renderInsights(w, data)
 }
 
 func (a *App) itemForm(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet, http.MethodHead:
-		a.renderItemForm(w, itemFormViewData{Title: "Add item", CurrentPath: "/items/new"})
-	case http.MethodPost:
-		a.createItem(w, r)
-	default:
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	data := itemFormViewData{Title: "Add item", CurrentPath: "/items/new"}
+	if raw := strings.TrimSpace(r.URL.Query().Get("template")); raw != "" {
+		templateID, err := strconv.Atoi(raw)
+		if err != nil {
+			http.Error(w, "invalid template id", http.StatusBadRequest)
+			return
+		}
+		a.mu.RLock()
+		tpl, ok := a.itemTemplateLocked(templateID)
+		a.mu.RUnlock()
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		data.FormValues = tpl.item(time.Now())
+		data.SelectedTemplate = tpl.ID
 	}
+	a.renderItemForm(w, data)
 }
 
 func (a *App) editItemForm(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet, http.MethodHead:
-		a.renderEditItemForm(w, r, itemFormViewData{Title: "Edit item", CurrentPath: "/"})
-	case http.MethodPost:
-		a.updateItem(w, r)
-	default:
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-	}
+	a.renderEditItemForm(w, r, itemFormViewData{Title: "Edit item", CurrentPath: "/items/{id}/edit"})
 }
 
 func (a *App) createItem(w http.ResponseWriter, r *http.Request) {
@@ -418,92 +853,157 @@ func (a *App) createItem(w http.ResponseWriter, r *http.Request) {
 		Tags:            parseTagsFromForm(r.Form["tags"]),
 		WaitPreset:      strings.TrimSpace(r.FormValue("wait_preset")),
 		WaitCustomHours: strings.TrimSpace(r.FormValue("wait_custom_hours")),
+		Private:         r.FormValue("private") == "1",
 	}
 
-	if item.WaitPreset == "" {
-		a.mu.RLock()
-		item.WaitPreset = defaultWaitPreset(a.defaultWaitPreset)
-		if item.WaitPreset == "custom" {
-			item.WaitCustomHours = a.defaultWaitCustomHours
-		}
-		a.mu.RUnlock()
+	urgeScore, err := parseUrgeScore(r.FormValue("urge_score"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
+	item.UrgeScore = urgeScore
 
-	if parsedPrice, ok := parsePrice(item.Price); ok {
-		item.PriceValue = parsedPrice
-		item.HasPriceValue = true
+	researching := r.FormValue("researching") == "1"
+	if researching {
+		item.Status = domain.StatusResearching
 	}
 
-	if item.Title == "" {
+	// The add form marks the preselected wait time as automatic until the user changes it.
+	explicitPreset := item.WaitPreset != "" && r.FormValue("wait_preset_auto") != "1"
+	a.mu.RLock()
+	a.applyWaitDefaultsLocked(&item, explicitPreset)
+	item.PriceCents, item.HasPriceValue = a.parsePriceLocked(item.Price)
+	a.mu.RUnlock()
+
+	draft := domain.Draft{
+		Item:                  item,
+		PurchaseAllowedInput:  purchaseAllowedInput(r),
+		TimezoneOffsetMinutes: strings.TrimSpace(r.FormValue("timezone_offset_minutes")),
+		Researching:           researching,
+		CreatedAtInput:        strings.TrimSpace(r.FormValue("created_at")),
+		DecidedAtInput:        strings.TrimSpace(r.FormValue("decided_at")),
+		DecisionInput:         strings.TrimSpace(r.FormValue("decision")),
+	}
+
+	a.mu.LockContext(r.Context())
+	_, err = a.itemServiceLocked().Create(draft)
+	quota := a.itemQuota
+	a.mu.Unlock()
+
+	var invalid *domain.ValidationError
+	if errors.As(err, &invalid) {
 		w.WriteHeader(http.StatusBadRequest)
 		a.renderItemForm(w, itemFormViewData{
-			Title:       "Add item",
-			CurrentPath: "/items/new",
-			FormValues:  item,
-			Error:       "Please enter a title.",
+			Title:                "Add item",
+			CurrentPath:          "/items/new",
+			FormValues:           item,
+			PurchaseAllowedInput: strings.TrimSpace(r.FormValue("purchase_allowed_at")),
+			WaitText:             strings.TrimSpace(r.FormValue("wait_text")),
+			CreatedAtInput:       draft.CreatedAtInput,
+			DecidedAtInput:       draft.DecidedAtInput,
+			DecisionInput:        draft.DecisionInput,
+			Error:                fieldErrorSummary,
+			FieldErrors:          invalid.FieldMessages(),
+			WaitPresetExplicit:   explicitPreset,
 		})
 		return
 	}
-
-	now := time.Now()
-	purchaseAllowedInput := strings.TrimSpace(r.FormValue("purchase_allowed_at"))
-	timezoneOffsetMinutes := strings.TrimSpace(r.FormValue("timezone_offset_minutes"))
-	purchaseAllowedAt, err := resolvePurchaseAllowedAt(item.WaitPreset, item.WaitCustomHours, purchaseAllowedInput, timezoneOffsetMinutes, now)
-	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+	if errors.Is(err, domain.ErrItemQuotaReached) {
+		w.WriteHeader(http.StatusConflict)
 		a.renderItemForm(w, itemFormViewData{
 			Title:                "Add item",
 			CurrentPath:          "/items/new",
 			FormValues:           item,
-			PurchaseAllowedInput: purchaseAllowedInput,
-			Error:                err.Error(),
+			PurchaseAllowedInput: strings.TrimSpace(r.FormValue("purchase_allowed_at")),
+			WaitText:             strings.TrimSpace(r.FormValue("wait_text")),
+			CreatedAtInput:       draft.CreatedAtInput,
+			DecidedAtInput:       draft.DecidedAtInput,
+			DecisionInput:        draft.DecisionInput,
+			Error:                itemQuotaMessage(quota),
+			WaitPresetExplicit:   explicitPreset,
 		})
 		return
 	}
-
-	item.Status = activeStatusForPurchaseAllowedAt(purchaseAllowedAt, now)
-	item.WaitPreset = normalizeItemWaitPreset(item.WaitPreset)
-	item.CreatedAt = now
-	item.PurchaseAllowedAt = purchaseAllowedAt
-
-	a.mu.Lock()
-	if err := a.insertItemLocked(&item); err != nil {
-		a.mu.Unlock()
+	if err != nil {
 		log.Printf("db error while creating item: %v", err)
 		http.Error(w, "could not save item", http.StatusInternalServerError)
 		return
 	}
-	a.items = append([]Item{item}, a.items...)
-	a.mu.Unlock()
 
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
 
+// applyWaitDefaultsLocked picks the wait of a new item whose preset was not chosen explicitly:
+// the longest wait default of its tags, otherwise the profile's default wait.
+func (a *App) applyWaitDefaultsLocked(item *Item, explicitPreset bool) {
+	if !explicitPreset {
+		if preset, ok := tagWaitDefault(item.Tags, a.tagWaitDefaults); ok {
+			item.WaitPreset = preset
+			item.WaitCustomHours = ""
+		}
+	}
+	if item.WaitPreset == "" {
+		item.WaitPreset = domain.NormalizeWaitPreset(a.defaultWaitPreset)
+		if item.WaitPreset == "custom" {
+			item.WaitCustomHours = a.defaultWaitCustomHours
+		}
+	}
+}
+
 func (a *App) renderEditItemForm(w http.ResponseWriter, r *http.Request, data itemFormViewData) {
-	id, err := strconv.Atoi(strings.TrimSpace(r.URL.Query().Get("id")))
-	if err != nil || id <= 0 {
+	id, err := itemIDFromRequest(r)
+	if err != nil {
 		http.Error(w, "invalid item id", http.StatusBadRequest)
 		return
 	}
 
+	profiles, err := a.listProfileNames()
+	var archived []archivedProfile
+	if err == nil {
+		archived, err = a.listArchivedProfiles()
+	}
+	if err != nil {
+		log.Printf("db error while listing profiles for sharing: %v", err)
+		http.Error(w, "could not load item", http.StatusInternalServerError)
+		return
+	}
+	archivedNames := make([]string, 0, len(archived))
+	for _, profile := range archived {
+		archivedNames = append(archivedNames, profile.Name)
+	}
+
 	a.mu.RLock()
+	i := a.itemIndexLocked(id)
+	var existing Item
+	if i >= 0 {
+		existing = a.items[i]
+	}
 	if data.FormValues.ID == 0 {
-		for i := range a.items {
-			if a.items[i].ID == id {
-				data.FormValues = a.items[i]
-				break
-			}
-		}
+		data.FormValues = existing
+	}
+	data.IsOwner = a.isOwnedByLocked(existing)
+	if a.db != nil {
+		data.ShareCandidates = shareCandidates(profiles, a.currentUserIDLocked(), existing.SharedWith)
+		data.MoveCandidates = transferCandidates(profiles, archivedNames, a.currentUserIDLocked())
 	}
+	history, err := a.itemHistoryLocked(id)
 	a.mu.RUnlock()
 
-	if data.FormValues.ID == 0 {
+	if i < 0 {
 		http.NotFound(w, r)
 		return
 	}
+	if err != nil {
+		log.Printf("db error while loading item history: %v", err)
+		http.Error(w, "could not load item", http.StatusInternalServerError)
+		return
+	}
+	data.FormValues.OwnerID = existing.OwnerID
+	data.FormValues.SharedWith = existing.SharedWith
+	data.History = history
 
 	data.ItemID = id
-	data.FormAction = "/items/edit?id=" + strconv.Itoa(id)
+	data.FormAction = itemEditPath(id)
 	data.SubmitLabel = "Save changes"
 	data.CancelHref = "/"
 	a.renderItemForm(w, data)
@@ -515,8 +1015,8 @@ func (a *App) updateItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id, err := strconv.Atoi(strings.TrimSpace(r.URL.Query().Get("id")))
-	if err != nil || id <= 0 {
+	id, err := itemIDFromRequest(r)
+	if err != nil {
 		http.Error(w, "invalid item id", http.StatusBadRequest)
 		return
 	}
@@ -530,106 +1030,70 @@ func (a *App) updateItem(w http.ResponseWriter, r *http.Request) {
 		Tags:            parseTagsFromForm(r.Form["tags"]),
 		WaitPreset:      strings.TrimSpace(r.FormValue("wait_preset")),
 		WaitCustomHours: strings.TrimSpace(r.FormValue("wait_custom_hours")),
+		Private:         r.FormValue("private") == "1",
 	}
 
-	if parsedPrice, ok := parsePrice(item.Price); ok {
-		item.PriceValue = parsedPrice
-		item.HasPriceValue = true
+	urgeScore, err := parseUrgeScore(r.FormValue("urge_score"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
+	item.UrgeScore = urgeScore
 
-	if item.Title == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		a.renderEditItemForm(w, r, itemFormViewData{
-			Title:       "Edit item",
-			CurrentPath: "/",
-			FormValues:  item,
-			Error:       "Please enter a title.",
-		})
-		return
+	a.mu.RLock()
+	item.PriceCents, item.HasPriceValue = a.parsePriceLocked(item.Price)
+	a.mu.RUnlock()
+
+	draft := domain.Draft{
+		Item:                  item,
+		PurchaseAllowedInput:  purchaseAllowedInput(r),
+		TimezoneOffsetMinutes: strings.TrimSpace(r.FormValue("timezone_offset_minutes")),
+		CreatedAtInput:        strings.TrimSpace(r.FormValue("created_at")),
+		DecidedAtInput:        strings.TrimSpace(r.FormValue("decided_at")),
+		DecisionInput:         strings.TrimSpace(r.FormValue("decision")),
 	}
 
-	now := time.Now()
-	purchaseAllowedInput := strings.TrimSpace(r.FormValue("purchase_allowed_at"))
-	timezoneOffsetMinutes := strings.TrimSpace(r.FormValue("timezone_offset_minutes"))
-	purchaseAllowedAt, err := resolvePurchaseAllowedAt(item.WaitPreset, item.WaitCustomHours, purchaseAllowedInput, timezoneOffsetMinutes, now)
-	if err != nil {
+	a.mu.LockContext(r.Context())
+	_, err = a.itemServiceLocked().Update(id, draft)
+	a.mu.Unlock()
+
+	var invalid *domain.ValidationError
+	switch {
+	case errors.As(err, &invalid):
 		w.WriteHeader(http.StatusBadRequest)
 		a.renderEditItemForm(w, r, itemFormViewData{
 			Title:                "Edit item",
 			CurrentPath:          "/",
 			FormValues:           item,
-			PurchaseAllowedInput: purchaseAllowedInput,
-			Error:                err.Error(),
+			PurchaseAllowedInput: strings.TrimSpace(r.FormValue("purchase_allowed_at")),
+			WaitText:             strings.TrimSpace(r.FormValue("wait_text")),
+			CreatedAtInput:       draft.CreatedAtInput,
+			DecidedAtInput:       draft.DecidedAtInput,
+			DecisionInput:        draft.DecisionInput,
+			Error:                fieldErrorSummary,
+			FieldErrors:          invalid.FieldMessages(),
 		})
-		return
-	}
-
-	item.WaitPreset = normalizeItemWaitPreset(item.WaitPreset)
-
-	a.mu.Lock()
-	defer a.mu.Unlock()
-
-	for i := range a.items {
-		if a.items[i].ID != id {
-			continue
-		}
-
-		existing := a.items[i]
-		item.CreatedAt = existing.CreatedAt
-		item.NtfyAttempted = existing.NtfyAttempted
-
-		item.PurchaseAllowedAt = purchaseAllowedAt
-		if existing.Status == "Bought" {
-			item.Status = "Bought"
-		} else {
-			item.Status = activeStatusForPurchaseAllowedAt(purchaseAllowedAt, now)
-			if item.Status == "Waiting" {
-				item.NtfyAttempted = false
-			}
-		}
-
-		a.items[i] = item
-		if err := a.updateItemLocked(item); err != nil {
-			log.Printf("db error while updating item: %v", err)
-			http.Error(w, "could not update item", http.StatusInternalServerError)
-			return
-		}
-
+	case err != nil:
+		a.renderDomainError(w, r, err, "could not update item")
+	default:
 		http.Redirect(w, r, "/", http.StatusSeeOther)
-		return
 	}
-
-	http.NotFound(w, r)
 }
 
 func (a *App) profileSettings(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet, http.MethodHead:
-		a.renderProfile(w, profileViewData{
-			Title:           "Profile settings",
-			CurrentPath:     "/settings/profile",
-			ProfileFeedback: feedbackFromQuery(r),
-		})
-	case http.MethodPost:
-		a.saveProfile(w, r)
-	default:
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-	}
+	a.renderProfile(w, profileViewData{
+		Title:           "Profile settings",
+		CurrentPath:     "/settings/profile",
+		ProfileFeedback: feedbackFromQuery(r),
+	})
 }
 
 func (a *App) tagSettings(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet, http.MethodHead:
-		a.renderTagSettings(w, tagSettingsViewData{
-			Title:       "Tag settings",
-			CurrentPath: "/settings/tags",
-			Feedback:    tagFeedbackFromQuery(r),
-		})
-	case http.MethodPost:
-		a.saveTagSettings(w, r)
-	default:
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-	}
+	a.renderTagSettings(w, tagSettingsViewData{
+		Title:       "Tag settings",
+		CurrentPath: "/settings/tags",
+		Feedback:    tagFeedbackFromQuery(r),
+	})
 }
 
 func tagFeedbackFromQuery(r *http.Request) string {
@@ -638,6 +1102,10 @@ func tagFeedbackFromQuery(r *http.Request) string {
 		return "Tag added."
 	case "deleted":
 		return "Tag deleted."
+	case "wait":
+		return "Default wait time saved."
+	case "reset":
+		return "Starter tags restored."
 	default:
 		return ""
 	}
@@ -657,7 +1125,7 @@ func (a *App) saveTagSettings(w http.ResponseWriter, r *http.Request) {
 			a.renderTagSettings(w, tagSettingsViewData{Title: "Tag settings", CurrentPath: "/settings/tags", Error: "Please enter a tag name."})
 			return
 		}
-		a.mu.Lock()
+		a.mu.LockContext(r.Context())
 		a.tagCatalog = appendTagOption(a.tagCatalog, tag)
 		if err := a.persistProfileLocked(); err != nil {
 			a.mu.Unlock()
@@ -674,8 +1142,9 @@ func (a *App) saveTagSettings(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "invalid tag", http.StatusBadRequest)
 			return
 		}
-		a.mu.Lock()
+		a.mu.LockContext(r.Context())
 		a.tagCatalog = removeTagOption(a.tagCatalog, tag)
+		a.tagWaitDefaults = setTagWaitDefault(a.tagWaitDefaults, tag, "")
 		for i := range a.items {
 			a.items[i].Tags = removeTagFromCSV(a.items[i].Tags, tag)
 			if err := a.updateItemLocked(a.items[i]); err != nil {
@@ -695,68 +1164,167 @@ func (a *App) saveTagSettings(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, "/settings/tags?saved=deleted", http.StatusSeeOther)
 		return
 	}
-
-	http.Error(w, "invalid action", http.StatusBadRequest)
-}
-
-func (a *App) deleteProfile(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	names, err := a.listProfileNames()
-	if err != nil {
-		http.Error(w, "could not load profiles", http.StatusInternalServerError)
+	if action == "reset" {
+		a.mu.LockContext(r.Context())
+		a.tagCatalog = a.starterTagsLocked()
+		if err := a.persistProfileLocked(); err != nil {
+			a.mu.Unlock()
+			log.Printf("db error while resetting tag settings: %v", err)
+			http.Error(w, "could not save tag settings", http.StatusInternalServerError)
+			return
+		}
+		a.mu.Unlock()
+		http.Redirect(w, r, "/settings/tags?saved=reset", http.StatusSeeOther)
 		return
 	}
-	if len(names) <= 1 {
-		w.WriteHeader(http.StatusConflict)
-		a.renderProfile(w, profileViewData{
-			Title:        "Profile settings",
-			CurrentPath:  "/settings/profile",
-			ProfileError: "The last remaining profile cannot be deleted. Please create or switch to another profile first.",
-		})
+	if action == "wait" {
+		preset := strings.TrimSpace(r.FormValue("wait_preset"))
+		if tag == "" || (preset != "" && !slices.Contains(tagWaitPresetOptions, preset)) {
+			http.Error(w, "invalid tag wait default", http.StatusBadRequest)
+			return
+		}
+		a.mu.LockContext(r.Context())
+		a.tagWaitDefaults = setTagWaitDefault(a.tagWaitDefaults, tag, preset)
+		if err := a.persistProfileLocked(); err != nil {
+			a.mu.Unlock()
+			log.Printf("db error while saving tag wait defaults: %v", err)
+			http.Error(w, "could not save tag settings", http.StatusInternalServerError)
+			return
+		}
+		a.mu.Unlock()
+		http.Redirect(w, r, "/settings/tags?saved=wait", http.StatusSeeOther)
 		return
 	}
 
-	a.mu.Lock()
-	profileName := a.currentUserIDLocked()
-	if err := a.deleteProfileLocked(profileName); err != nil {
-		a.mu.Unlock()
+	http.Error(w, "invalid action", http.StatusBadRequest)
+}
+
+func (a *App) deleteProfile(w http.ResponseWriter, r *http.Request) {
+	profileName := a.activeProfileName()
+	if err := a.profileService().Delete(profileName); err != nil {
+		if errors.Is(err, domain.ErrLastProfile) {
+			w.WriteHeader(http.StatusConflict)
+			a.renderProfile(w, profileViewData{
+				Title:        "Profile settings",
+				CurrentPath:  "/settings/profile",
+				ProfileError: err.Error(),
+			})
+			return
+		}
 		log.Printf("db error while deleting profile: %v", err)
 		http.Error(w, "could not delete profile", http.StatusInternalServerError)
 		return
 	}
+
+	a.mu.LockContext(r.Context())
+	a.recordAuditLocked(profileName, auditProfileDeleted, "", r)
+	a.resetActiveProfileLocked()
+	a.mu.Unlock()
+	a.avatars.forget(profileName)
+
+	http.SetCookie(w, &http.Cookie{Name: "active_profile", Value: "", Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode, MaxAge: -1})
+	http.Redirect(w, r, "/switch-profile", http.StatusSeeOther)
+}
+
+func (a *App) resetActiveProfileLocked() {
 	a.activeUserID = ""
 	a.items = nil
 	a.hourlyWage = ""
-	a.defaultWaitPreset = defaultWaitPreset("")
+	a.defaultWaitPreset = domain.NormalizeWaitPreset("")
 	a.defaultWaitCustomHours = ""
 	a.ntfyURL = ""
 	a.ntfyTopic = ""
 	a.currency = ""
+	a.shareToken = ""
+	a.shareExpiresAt = time.Time{}
+	a.retentionMonths = 0
+	a.fireflyURL = ""
+	a.fireflyToken = ""
+	a.fireflyAccount = ""
+	a.approvalThreshold = 0
+	a.approver = ""
+	a.itemTemplates = nil
+	a.tagWaitDefaults = nil
+	a.trendTimezone = ""
+	a.weekStart = ""
+	a.monthStartDay = 0
+	a.metricsOptIn = false
+	a.notifyDryRun = false
+	a.haWebhookURL = ""
+	a.workHoursMode = ""
+	a.shiftHours = ""
+	a.monthlyIncome = ""
+	a.weeklyHours = ""
+	a.projectionRate = ""
+	a.projectionYears = 0
+	a.renotifyPolicy = ""
+	a.renotifyDays = 0
+	a.numberFormat = ""
+	a.payday = 0
+	a.blackouts = nil
+	a.routingRules = nil
+	a.avatarEmoji = ""
+	a.avatarColor = ""
+	a.hoursGoal = 0
+	a.hoursGoalCelebrated = ""
+	a.noteKeySalt = ""
+	a.noteKeyCheck = ""
+	a.landingPage = ""
+	a.lastVisited = ""
+	a.dashboardFilters = ""
+	a.workHoursPrecision = ""
+	a.workHoursRounding = ""
+	a.archived = false
 	a.profileExists = false
 	a.nextID = 1
-	a.mu.Unlock()
-
-	http.SetCookie(w, &http.Cookie{Name: "active_profile", Value: "", Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode, MaxAge: -1})
-	http.Redirect(w, r, "/switch-profile", http.StatusSeeOther)
 }
 
 func (a *App) legacyProfile(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Redirect(w, r, "/settings/profile", http.StatusSeeOther)
-		return
+	http.Redirect(w, r, "/settings/profile", http.StatusSeeOther)
+}
+
+// itemActionConfirmations are the dashboard confirmations after an item action, keyed by the done parameter.
+// %q is the item title.
+var itemActionConfirmations = map[string]string{
+	"bought":              "%q marked as bought.",
+	"skipped":             "%q marked as skipped.",
+	"snoozed":             "%q snoozed for 24 hours.",
+	"deleted":             "%q deleted.",
+	"wait-started":        "Wait started for %q.",
+	"rated":               "Rating saved for %q.",
+	"blackout-overridden": "%q unlocked despite the blackout.",
+	"moved":               "%q moved to another profile.",
+	"receipt":             "Receipt saved for %q.",
+}
+
+// itemActionRedirect sends the browser back to the dashboard, which confirms the action in a live region so
+// screen reader users hear that it worked.
+func itemActionRedirect(w http.ResponseWriter, r *http.Request, done, title string) {
+	http.Redirect(w, r, "/?"+url.Values{"done": {done}, "item": {title}}.Encode(), http.StatusSeeOther)
+}
+
+func itemActionConfirmationFromQuery(r *http.Request) string {
+	message, ok := itemActionConfirmations[r.URL.Query().Get("done")]
+	title := strings.TrimSpace(r.URL.Query().Get("item"))
+	if !ok || title == "" {
+		return ""
 	}
-	a.saveProfile(w, r)
+	return fmt.Sprintf(message, title)
 }
 
 func feedbackFromQuery(r *http.Request) string {
-	if r.URL.Query().Get("saved") == "1" {
+	switch r.URL.Query().Get("saved") {
+	case "1":
 		return "Profile saved."
+	case "share":
+		return "Share link created."
+	case "unshare":
+		return "Share link revoked."
+	case "share-expiry":
+		return "Share link expiry saved."
+	default:
+		return ""
 	}
-	return ""
 }
 
 func (a *App) saveProfile(w http.ResponseWriter, r *http.Request) {
@@ -769,83 +1337,69 @@ func (a *App) saveProfile(w http.ResponseWriter, r *http.Request) {
 	if profileNameRaw == "" {
 		profileNameRaw = a.activeProfileName()
 	}
-	profileName, err := parseProfileName(profileNameRaw)
+	settings, err := a.profileService().ValidateSettings(domain.ProfileSettings{
+		Name:                   profileNameRaw,
+		HourlyWage:             r.FormValue("hourly_wage"),
+		DefaultWaitPreset:      r.FormValue("default_wait_preset"),
+		DefaultWaitCustomHours: r.FormValue("default_wait_custom_hours"),
+		NtfyEndpoint:           r.FormValue("ntfy_endpoint"),
+		NtfyTopic:              r.FormValue("ntfy_topic"),
+		WorkHoursMode:          r.FormValue("work_hours_mode"),
+		ShiftHours:             r.FormValue("shift_hours"),
+		WorkHoursPrecision:     r.FormValue("work_hours_precision"),
+		WorkHoursRounding:      r.FormValue("work_hours_rounding"),
+		LandingPage:            r.FormValue("landing_page"),
+		MonthlyIncome:          r.FormValue("monthly_income"),
+		WeeklyHours:            r.FormValue("weekly_hours"),
+		RenotifyPolicy:         r.FormValue("renotify_policy"),
+		RenotifyDays:           r.FormValue("renotify_days"),
+		NumberFormat:           r.FormValue("number_format"),
+		Payday:                 r.FormValue("payday"),
+		AvatarEmoji:            r.FormValue("avatar_emoji"),
+		AvatarColor:            r.FormValue("avatar_color"),
+	})
+	// ValidateSettings only rejects input, so Merge never hands back an error.
+	var validation domain.Validation
+	_ = validation.Merge(err)
+	currency, err := parseCurrency(r.FormValue("currency"))
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		a.renderProfile(w, profileViewData{
-			Title:                  "Profile settings",
-			CurrentPath:            "/settings/profile",
-			ProfileName:            strings.TrimSpace(profileNameRaw),
-			ProfileHourly:          strings.TrimSpace(r.FormValue("hourly_wage")),
-			DefaultWaitPreset:      strings.TrimSpace(r.FormValue("default_wait_preset")),
-			DefaultWaitCustomHours: strings.TrimSpace(r.FormValue("default_wait_custom_hours")),
-			NtfyEndpoint:           strings.TrimRight(strings.TrimSpace(r.FormValue("ntfy_endpoint")), "/"),
-			NtfyTopic:              strings.TrimSpace(r.FormValue("ntfy_topic")),
-			Currency:               normalizeCurrency(r.FormValue("currency")),
-			ProfileError:           err.Error(),
-		})
-		return
+		validation.Add("currency", err.Error())
 	}
-
-	hourlyWage := strings.TrimSpace(r.FormValue("hourly_wage"))
-	defaultPreset := strings.TrimSpace(r.FormValue("default_wait_preset"))
-	defaultCustomHours := strings.TrimSpace(r.FormValue("default_wait_custom_hours"))
-	ntfyURL := strings.TrimRight(strings.TrimSpace(r.FormValue("ntfy_endpoint")), "/")
-	ntfyTopic := strings.TrimSpace(r.FormValue("ntfy_topic"))
-	currency := normalizeCurrency(r.FormValue("currency"))
-
-	if _, err := parseHourlyWage(hourlyWage); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		a.renderProfile(w, profileViewData{
-			Title:                  "Profile settings",
-			CurrentPath:            "/settings/profile",
-			ProfileName:            profileName,
-			ProfileHourly:          hourlyWage,
-			DefaultWaitPreset:      defaultPreset,
-			DefaultWaitCustomHours: defaultCustomHours,
-			NtfyEndpoint:           ntfyURL,
-			NtfyTopic:              ntfyTopic,
-			Currency:               currency,
-			ProfileError:           err.Error(),
-		})
-		return
-	}
-
-	if _, err := parseWaitDuration(defaultPreset, defaultCustomHours); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		a.renderProfile(w, profileViewData{
-			Title:                  "Profile settings",
-			CurrentPath:            "/settings/profile",
-			ProfileName:            profileName,
-			ProfileHourly:          hourlyWage,
-			DefaultWaitPreset:      defaultPreset,
-			DefaultWaitCustomHours: defaultCustomHours,
-			NtfyEndpoint:           ntfyURL,
-			NtfyTopic:              ntfyTopic,
-			Currency:               currency,
-			ProfileError:           err.Error(),
-		})
-		return
-	}
-
-	if (ntfyURL == "" && ntfyTopic != "") || (ntfyURL != "" && ntfyTopic == "") {
+	var invalid *domain.ValidationError
+	if errors.As(validation.Err(), &invalid) {
 		w.WriteHeader(http.StatusBadRequest)
 		a.renderProfile(w, profileViewData{
 			Title:                  "Profile settings",
 			CurrentPath:            "/settings/profile",
-			ProfileName:            profileName,
-			ProfileHourly:          hourlyWage,
-			DefaultWaitPreset:      defaultPreset,
-			DefaultWaitCustomHours: defaultCustomHours,
-			NtfyEndpoint:           ntfyURL,
-			NtfyTopic:              ntfyTopic,
-			Currency:               currency,
-			ProfileError:           "Please provide both ntfy endpoint and topic, or leave both empty.",
+			ProfileName:            settings.Name,
+			ProfileHourly:          settings.HourlyWage,
+			DefaultWaitPreset:      settings.DefaultWaitPreset,
+			DefaultWaitCustomHours: settings.DefaultWaitCustomHours,
+			NtfyEndpoint:           settings.NtfyEndpoint,
+			NtfyTopic:              settings.NtfyTopic,
+			WorkHoursMode:          settings.WorkHoursMode,
+			ShiftHours:             settings.ShiftHours,
+			WorkHoursPrecision:     settings.WorkHoursPrecision,
+			WorkHoursRounding:      settings.WorkHoursRounding,
+			LandingPage:            settings.LandingPage,
+			MonthlyIncome:          settings.MonthlyIncome,
+			WeeklyHours:            settings.WeeklyHours,
+			RenotifyPolicy:         settings.RenotifyPolicy,
+			RenotifyDays:           settings.RenotifyDays,
+			NotifyDryRun:           r.FormValue("notify_dry_run") == "1",
+			NumberFormat:           settings.NumberFormat,
+			Payday:                 settings.Payday,
+			AvatarEmoji:            settings.AvatarEmoji,
+			AvatarColor:            settings.AvatarColor,
+			Currency:               normalizeCurrency(r.FormValue("currency")),
+			ProfileError:           fieldErrorSummary,
+			FieldErrors:            invalid.FieldMessages(),
 		})
 		return
 	}
+	profileName := settings.Name
 
-	a.mu.Lock()
+	a.mu.LockContext(r.Context())
 	previousProfileName := a.currentUserIDLocked()
 	if profileName != previousProfileName {
 		if err := a.renameProfileLocked(previousProfileName, profileName); err != nil {
@@ -855,16 +1409,28 @@ func (a *App) saveProfile(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		a.activeUserID = profileName
-	}
-	a.hourlyWage = hourlyWage
-	a.defaultWaitPreset = defaultWaitPreset(defaultPreset)
-	if a.defaultWaitPreset == "custom" {
-		a.defaultWaitCustomHours = defaultCustomHours
-	} else {
-		a.defaultWaitCustomHours = ""
-	}
-	a.ntfyURL = ntfyURL
-	a.ntfyTopic = ntfyTopic
+		a.recordAuditLocked(profileName, auditProfileRenamed, previousProfileName+" → "+profileName, r)
+	}
+	a.hourlyWage = settings.HourlyWage
+	a.defaultWaitPreset = settings.DefaultWaitPreset
+	a.defaultWaitCustomHours = settings.DefaultWaitCustomHours
+	a.ntfyURL = settings.NtfyEndpoint
+	a.ntfyTopic = settings.NtfyTopic
+	a.workHoursMode = settings.WorkHoursMode
+	a.shiftHours = settings.ShiftHours
+	a.workHoursPrecision = settings.WorkHoursPrecision
+	a.workHoursRounding = settings.WorkHoursRounding
+	a.landingPage = settings.LandingPage
+	a.monthlyIncome = settings.MonthlyIncome
+	a.weeklyHours = settings.WeeklyHours
+	renotify, _ := domain.ParseRenotifyPolicy(settings.RenotifyPolicy, settings.RenotifyDays)
+	a.renotifyPolicy = renotify.Mode
+	a.renotifyDays = renotify.Days
+	a.notifyDryRun = r.FormValue("notify_dry_run") == "1"
+	a.numberFormat = settings.NumberFormat
+	a.payday, _ = domain.ParsePayday(settings.Payday)
+	a.avatarEmoji = settings.AvatarEmoji
+	a.avatarColor = settings.AvatarColor
 	a.currency = currency
 	if err := a.persistProfileLocked(); err != nil {
 		a.mu.Unlock()
@@ -872,6 +1438,11 @@ func (a *App) saveProfile(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "could not save profile", http.StatusInternalServerError)
 		return
 	}
+	if profileName != previousProfileName {
+		a.avatars.forget(previousProfileName)
+	}
+	a.cacheActiveAvatarLocked()
+	a.publishProfileUpdatedLocked("profile", r)
 	a.mu.Unlock()
 	http.SetCookie(w, &http.Cookie{Name: "active_profile", Value: profileName, Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode})
 
@@ -879,11 +1450,6 @@ func (a *App) saveProfile(w http.ResponseWriter, r *http.Request) {
 }
 
 func (a *App) updateItemStatus(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	if err := r.ParseForm(); err != nil {
 		http.Error(w, "invalid form data", http.StatusBadRequest)
 		return
@@ -895,46 +1461,25 @@ func (a *App) updateItemStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	newStatus := strings.TrimSpace(r.FormValue("status"))
-	if !slices.Contains([]string{"Bought", "Skipped"}, newStatus) {
+	newStatus, err := domain.ParseStatus(r.FormValue("status"))
+	if err != nil {
 		http.Error(w, "invalid status", http.StatusBadRequest)
 		return
 	}
 
-	a.mu.Lock()
-	defer a.mu.Unlock()
-
+	a.mu.LockContext(r.Context())
 	a.promoteReadyItemsLocked(time.Now())
+	decided, err := a.itemServiceLocked().Decide(id, newStatus)
+	a.mu.Unlock()
 
-	for i := range a.items {
-		if a.items[i].ID != id {
-			continue
-		}
-
-		if a.items[i].Status != "Ready to buy" {
-			http.Error(w, "status transition not allowed", http.StatusConflict)
-			return
-		}
-
-		a.items[i].Status = newStatus
-		if err := a.updateItemStatusLocked(id, newStatus); err != nil {
-			log.Printf("db error while updating item status: %v", err)
-			http.Error(w, "could not update item status", http.StatusInternalServerError)
-			return
-		}
-		http.Redirect(w, r, "/", http.StatusSeeOther)
+	if err != nil {
+		a.renderDomainError(w, r, err, "could not update item status")
 		return
 	}
-
-	http.NotFound(w, r)
+	itemActionRedirect(w, r, strings.ToLower(string(decided.Status)), decided.Title)
 }
 
 func (a *App) deleteItem(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	if err := r.ParseForm(); err != nil {
 		http.Error(w, "invalid form data", http.StatusBadRequest)
 		return
@@ -946,167 +1491,72 @@ func (a *App) deleteItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	a.mu.Lock()
+	a.mu.LockContext(r.Context())
 	defer a.mu.Unlock()
 
-	for i := range a.items {
-		if a.items[i].ID != id {
-			continue
-		}
-
-		a.items = append(a.items[:i], a.items[i+1:]...)
-		if err := a.deleteItemLocked(id); err != nil {
-			log.Printf("db error while deleting item: %v", err)
-			http.Error(w, "could not delete item", http.StatusInternalServerError)
-			return
-		}
-
-		http.Redirect(w, r, "/", http.StatusSeeOther)
-		return
-	}
-
-	http.NotFound(w, r)
-}
-
-func (a *App) snoozeItem(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	if err := r.ParseForm(); err != nil {
-		http.Error(w, "invalid form data", http.StatusBadRequest)
-		return
-	}
-
-	id, err := strconv.Atoi(strings.TrimSpace(r.FormValue("item_id")))
-	if err != nil || id <= 0 {
-		http.Error(w, "invalid item id", http.StatusBadRequest)
-		return
-	}
-
-	snoozePreset := strings.TrimSpace(r.FormValue("snooze_preset"))
-	if snoozePreset != "24h" {
-		http.Error(w, "invalid snooze preset", http.StatusBadRequest)
-		return
-	}
-
-	duration := 24 * time.Hour
-
-	a.mu.Lock()
-	defer a.mu.Unlock()
-
-	now := time.Now()
-	a.promoteReadyItemsLocked(now)
-
-	for i := range a.items {
-		if a.items[i].ID != id {
-			continue
-		}
-
-		if a.items[i].Status != "Ready to buy" {
-			http.Error(w, "snooze is only allowed for ready items", http.StatusConflict)
-			return
-		}
-
-		base := a.items[i].PurchaseAllowedAt
-		if base.Before(now) {
-			base = now
+	for i := range a.items {
+		if a.items[i].ID != id {
+			continue
 		}
 
-		a.items[i].PurchaseAllowedAt = base.Add(duration)
-		a.items[i].Status = "Waiting"
-		a.items[i].NtfyAttempted = false
-
-		if err := a.updateItemLocked(a.items[i]); err != nil {
-			log.Printf("db error while snoozing item: %v", err)
-			http.Error(w, "could not snooze item", http.StatusInternalServerError)
-			return
+		if a.isOwnedByLocked(a.items[i]) {
+			if err := a.deleteItemLocked(id); err != nil {
+				log.Printf("db error while deleting item: %v", err)
+				http.Error(w, "could not delete item", http.StatusInternalServerError)
+				return
+			}
+		} else {
+			// Removing a shared item only takes it off this profile's list.
+			if err := a.removeItemShareLocked(id, a.currentUserIDLocked()); err != nil {
+				log.Printf("db error while leaving shared item: %v", err)
+				http.Error(w, "could not delete item", http.StatusInternalServerError)
+				return
+			}
+			a.recordHistoryLocked(id, "unshared", a.currentUserIDLocked())
 		}
+		title := a.items[i].Title
+		a.items = append(a.items[:i], a.items[i+1:]...)
 
-		http.Redirect(w, r, "/", http.StatusSeeOther)
+		itemActionRedirect(w, r, "deleted", title)
 		return
 	}
 
 	http.NotFound(w, r)
 }
 
-func parsePurchaseAllowedAt(raw string, timezoneOffsetMinutesRaw string) (time.Time, error) {
-	location := time.Local
-	if timezoneOffsetMinutesRaw != "" {
-		offsetMinutes, err := strconv.Atoi(timezoneOffsetMinutesRaw)
-		if err != nil {
-			return time.Time{}, errors.New("Please enter a valid buy-after date and time.")
-		}
-		location = time.FixedZone("browser", -offsetMinutes*60)
-	}
-
-	parsed, err := time.ParseInLocation("2006-01-02T15:04", strings.TrimSpace(raw), location)
-	if err != nil {
-		return time.Time{}, errors.New("Please enter a valid buy-after date and time.")
-	}
-	return parsed, nil
-}
-
-func resolvePurchaseAllowedAt(waitPreset string, waitCustomHours string, purchaseAllowedRaw string, timezoneOffsetMinutesRaw string, now time.Time) (time.Time, error) {
-	if normalizeItemWaitPreset(waitPreset) == "date" {
-		if strings.TrimSpace(purchaseAllowedRaw) == "" {
-			return time.Time{}, errors.New("Please enter a buy-after date and time.")
-		}
-		return parsePurchaseAllowedAt(purchaseAllowedRaw, strings.TrimSpace(timezoneOffsetMinutesRaw))
-	}
-
-	waitDuration, err := parseWaitDuration(waitPreset, waitCustomHours)
-	if err != nil {
-		return time.Time{}, err
+func (a *App) snoozeItem(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
 	}
-	return now.Add(waitDuration), nil
-}
 
-func activeStatusForPurchaseAllowedAt(purchaseAllowedAt, now time.Time) string {
-	if purchaseAllowedAt.After(now) {
-		return "Waiting"
+	id, err := strconv.Atoi(strings.TrimSpace(r.FormValue("item_id")))
+	if err != nil || id <= 0 {
+		http.Error(w, "invalid item id", http.StatusBadRequest)
+		return
 	}
-	return "Ready to buy"
-}
 
-func parseWaitDuration(waitPreset string, waitCustomHours string) (time.Duration, error) {
-	preset := strings.TrimSpace(waitPreset)
-	if preset == "" {
-		preset = "24h"
+	snoozePreset := strings.TrimSpace(r.FormValue("snooze_preset"))
+	if snoozePreset != "24h" {
+		http.Error(w, "invalid snooze preset", http.StatusBadRequest)
+		return
 	}
 
-	switch preset {
-	case "24h":
-		return 24 * time.Hour, nil
-	case "7d":
-		return 7 * 24 * time.Hour, nil
-	case "30d":
-		return 30 * 24 * time.Hour, nil
-	case "custom":
-		hours, err := strconv.ParseFloat(strings.TrimSpace(waitCustomHours), 64)
-		if err != nil || hours <= 0 {
-			return 0, errors.New("Please enter a valid number of custom hours (> 0).")
-		}
-		return time.Duration(hours * float64(time.Hour)), nil
-	default:
-		return 0, errors.New("Please select a valid wait time.")
-	}
-}
+	a.mu.LockContext(r.Context())
+	a.promoteReadyItemsLocked(time.Now())
+	snoozed, err := a.itemServiceLocked().Snooze(id, snoozePreset)
+	a.mu.Unlock()
 
-func normalizeItemWaitPreset(raw string) string {
-	switch strings.TrimSpace(raw) {
-	case "7d", "30d", "custom", "date":
-		return strings.TrimSpace(raw)
+	switch {
+	case errors.Is(err, domain.ErrTransitionNotAllowed):
+		a.renderErrorPage(w, r, http.StatusConflict, "Only items that are ready to buy can be snoozed.")
+	case err != nil:
+		a.renderDomainError(w, r, err, "could not snooze item")
 	default:
-		return "24h"
+		itemActionRedirect(w, r, "snoozed", snoozed.Title)
 	}
 }
 
-func defaultWaitPreset(raw string) string {
-	return normalizeItemWaitPreset(raw)
-}
-
 func (a *App) hasProfile() bool {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
@@ -1118,7 +1568,7 @@ func (a *App) hasProfile() bool {
 
 func normalizeSortBy(raw string) string {
 	switch strings.TrimSpace(raw) {
-	case "newest", "oldest", "price_asc", "price_desc":
+	case "newest", "oldest", "price_asc", "price_desc", "unlocking_soon":
 		return strings.TrimSpace(raw)
 	default:
 		return "next_ready"
@@ -1127,41 +1577,34 @@ func normalizeSortBy(raw string) string {
 
 const defaultProfileHourlyWage = "25"
 
-var allStatuses = []string{"Waiting", "Ready to buy", "Bought", "Skipped"}
-
-func parseStatusFilter(raw []string) ([]string, bool) {
-	if len(raw) == 0 {
-		return []string{"Waiting", "Ready to buy"}, false
-	}
-
-	selected := make([]string, 0, len(allStatuses))
-	seen := make(map[string]bool, len(allStatuses))
+// parseStatusFilter returns the statuses selected in the dashboard filter and whether the selection was explicit.
+func parseStatusFilter(raw []string) ([]domain.Status, bool) {
+	selected := make([]domain.Status, 0, len(domain.Statuses))
 	for _, candidate := range raw {
 		for _, part := range strings.Split(candidate, ",") {
-			trimmed := strings.TrimSpace(part)
-			if !slices.Contains(allStatuses, trimmed) || seen[trimmed] {
+			status, err := domain.ParseStatus(part)
+			if err != nil || slices.Contains(selected, status) {
 				continue
 			}
-			seen[trimmed] = true
-			selected = append(selected, trimmed)
+			selected = append(selected, status)
 		}
 	}
 
 	if len(selected) == 0 {
-		return []string{"Waiting", "Ready to buy"}, false
+		return slices.Clone(domain.OpenStatuses), false
 	}
 
 	return selected, true
 }
 
-func filterAndSortItems(items []Item, searchQuery string, statuses []string, tagFilter string, sortBy string) []Item {
+func filterAndSortItems(items []Item, searchQuery string, statuses []domain.Status, tagFilter string, sortBy string) []Item {
 	trimmedSearch := strings.ToLower(strings.TrimSpace(searchQuery))
 	trimmedTag := strings.ToLower(strings.TrimSpace(tagFilter))
-	statusFilter := make(map[string]bool, len(statuses))
+	statusFilter := make(map[domain.Status]bool, len(statuses))
 	for _, status := range statuses {
 		statusFilter[status] = true
 	}
-	hasStatusFilter := len(statusFilter) > 0 && len(statusFilter) < len(allStatuses)
+	hasStatusFilter := len(statusFilter) > 0 && len(statusFilter) < len(domain.Statuses)
 
 	filtered := make([]Item, 0, len(items))
 	for _, item := range items {
@@ -1183,7 +1626,39 @@ func filterAndSortItems(items []Item, searchQuery string, statuses []string, tag
 		filtered = append(filtered, item)
 	}
 
-	slices.SortStableFunc(filtered, func(a, b Item) int {
+	slices.SortStableFunc(filtered, compareItems(sortBy))
+	if sortBy == "unlocking_soon" {
+		now := time.Now()
+		slices.SortStableFunc(filtered, func(a, b Item) int {
+			return boolToInt(!unlocksSoon(a, now)) - boolToInt(!unlocksSoon(b, now))
+		})
+	}
+	return filtered
+}
+
+// unlockingSoonWindow is how far ahead the "unlocking soon" sort looks, so decisions can be thought
+// through before the items become ready.
+const unlockingSoonWindow = 48 * time.Hour
+
+// unlocksSoon reports whether a waiting item becomes ready within unlockingSoonWindow. The
+// "unlocking_soon" sort puts these items first, soonest first, and keeps the default order for the rest.
+func unlocksSoon(item Item, now time.Time) bool {
+	return item.Status == domain.StatusWaiting && item.PurchaseAllowedAt.After(now) && !item.PurchaseAllowedAt.After(now.Add(unlockingSoonWindow))
+}
+
+// countUnlockingSoon counts the leading items the "unlocking_soon" sort placed in its own section.
+func countUnlockingSoon(items []Item, now time.Time) int {
+	count := 0
+	for count < len(items) && unlocksSoon(items[count], now) {
+		count++
+	}
+	return count
+}
+
+// compareItems orders items as the dashboard sorts them. Ties are broken by creation time and ID, so the
+// order is total and the API can page through it with a cursor.
+func compareItems(sortBy string) func(a, b Item) int {
+	return func(a, b Item) int {
 		switch sortBy {
 		case "newest":
 			if cmp := b.CreatedAt.Compare(a.CreatedAt); cmp != 0 {
@@ -1202,14 +1677,14 @@ func filterAndSortItems(items []Item, searchQuery string, statuses []string, tag
 			}
 			if a.HasPriceValue && b.HasPriceValue {
 				if sortBy == "price_asc" {
-					if cmp := a.PriceValue - b.PriceValue; cmp != 0 {
+					if cmp := a.PriceCents - b.PriceCents; cmp != 0 {
 						if cmp < 0 {
 							return -1
 						}
 						return 1
 					}
 				} else {
-					if cmp := b.PriceValue - a.PriceValue; cmp != 0 {
+					if cmp := b.PriceCents - a.PriceCents; cmp != 0 {
 						if cmp < 0 {
 							return -1
 						}
@@ -1218,14 +1693,16 @@ func filterAndSortItems(items []Item, searchQuery string, statuses []string, tag
 				}
 			}
 		default:
-			statusRank := func(status string) int {
+			statusRank := func(status domain.Status) int {
 				switch status {
-				case "Ready to buy":
+				case domain.StatusReady:
 					return 0
-				case "Waiting":
+				case domain.StatusWaiting:
 					return 1
-				default:
+				case domain.StatusResearching:
 					return 2
+				default:
+					return 3
 				}
 			}
 
@@ -1236,7 +1713,7 @@ func filterAndSortItems(items []Item, searchQuery string, statuses []string, tag
 				return 1
 			}
 
-			if a.Status == "Ready to buy" || a.Status == "Waiting" {
+			if a.Status == domain.StatusReady || a.Status == domain.StatusWaiting {
 				if cmp := a.PurchaseAllowedAt.Compare(b.PurchaseAllowedAt); cmp != 0 {
 					return cmp
 				}
@@ -1256,33 +1733,65 @@ func filterAndSortItems(items []Item, searchQuery string, statuses []string, tag
 			return cmp
 		}
 		return b.ID - a.ID
-	})
-
-	return filtered
+	}
 }
 
 func (a *App) renderHome(w http.ResponseWriter, r *http.Request, data homeViewData) {
-	a.mu.Lock()
-	a.promoteReadyItemsLocked(time.Now())
+	a.mu.LockContext(r.Context())
+	now := time.Now()
+	a.promoteReadyItemsLocked(now)
 	allItems := append([]Item(nil), a.items...)
 	data.TotalItems = len(allItems)
 	data.Currency = profileCurrencyOrDefault(a.currency)
 	data.ActiveProfile = a.currentUserIDLocked()
-	if parsedWage, err := parseHourlyWage(a.hourlyWage); err == nil {
+	data.SetupPending = a.onboardingStep != ""
+	data.Archived = a.archived
+	data.Confirmation = itemActionConfirmationFromQuery(r)
+	if a.demoModeLocked() && data.ActiveProfile == demoProfileName {
+		data.DemoResetEvery = demoIntervalLabel(a.demoResetInterval)
+	}
+	if parsedWage, err := domain.ParseHourlyWage(a.hourlyWage); err == nil {
 		data.HourlyWage = parsedWage
 		data.HasHourlyWage = true
 	}
-	data.SearchQuery = strings.TrimSpace(r.URL.Query().Get("q"))
-	selectedStatuses, explicitStatusSelection := parseStatusFilter(r.URL.Query()["status"])
+	data.WorkEffort = a.workEffortFramingLocked()
+	data.SplitWages = a.splitWagesLocked(allItems)
+	filters := a.dashboardFiltersLocked(r)
+	data.SearchQuery = filters.Search
+	selectedStatuses := filters.statuses()
 	data.SelectedStatus = make(map[string]bool, len(selectedStatuses))
 	for _, status := range selectedStatuses {
-		data.SelectedStatus[status] = true
+		data.SelectedStatus[string(status)] = true
 	}
-	data.TagFilter = strings.TrimSpace(r.URL.Query().Get("tag"))
+	data.TagFilter = filters.Tag
 	data.TagOptions = availableTagOptions(allItems, a.tagCatalog)
-	data.SortBy = normalizeSortBy(r.URL.Query().Get("sort"))
-	data.HasActiveFilter = data.SearchQuery != "" || data.TagFilter != "" || data.SortBy != "next_ready" || explicitStatusSelection
-	data.Items = filterAndSortItems(allItems, data.SearchQuery, selectedStatuses, data.TagFilter, data.SortBy)
+	data.SortBy = filters.Sort
+	data.Within = filters.Within
+	if filters.HasMinPrice {
+		data.MinPrice = filters.MinPrice.String()
+	}
+	if filters.HasMaxPrice {
+		data.MaxPrice = filters.MaxPrice.String()
+	}
+	if !data.HasHourlyWage {
+		filters.Work = ""
+	} else {
+		data.WorkFilters = filters.workLinks()
+	}
+	data.HasActiveFilter = filters.active()
+	data.FilterChips = filters.chips(data.Currency)
+	data.Items = filterPriceRange(filterAndSortItems(allItems, data.SearchQuery, selectedStatuses, data.TagFilter, data.SortBy), filters.withWorkBounds(data.HourlyWage, data.WorkEffort.ShiftHours))
+	if data.Within != "" {
+		data.Items = filterWithinPeriod(data.Items, a.trendPeriodsLocked(data.Within), now)
+	}
+	if data.SortBy == "unlocking_soon" {
+		data.UnlockingSoonCount = countUnlockingSoon(data.Items, now)
+	}
+	data.Summary = buildHomeSummary(itemsCarriedBy(allItems, data.ActiveProfile), a.trendPeriodsLocked(trendGranularityWeek), a.trendPeriodsLocked(trendGranularityMonth), now)
+	data.NeedsApproval = a.approvalNeedsLocked(data.Items)
+	data.Blackout = a.activeBlackoutLocked(now)
+	data.HeldByBlackout = a.heldByBlackoutLocked(data.Items, now)
+	data.SimilarNotes = a.similarNotesLocked(data.Items, now)
 	data.ContentTemplate = "index_content"
 	data.ScriptTemplate = "index_script"
 	a.mu.Unlock()
@@ -1294,9 +1803,29 @@ func (a *App) renderInsights(w http.ResponseWriter, data insightsViewData) {
 	a.mu.Lock()
 	a.promoteReadyItemsLocked(time.Now())
 	data.ItemCount = len(a.items)
-	data.SkippedCount, data.SavedAmount, data.TopCategories = buildDashboardStats(a.items)
-	data.DecisionTrend = buildMonthlyDecisionTrend(a.items)
-	data.SavedTrend = buildMonthlySavedTrend(a.items)
+	carried := itemsCarriedBy(a.items, a.currentUserIDLocked())
+	data.SkippedCount, data.SavedAmount, data.TopCategories = buildDashboardStats(carried)
+	data.ResearchingCount = countItemsWithStatus(a.items, domain.StatusResearching)
+	data.RegretCategories = buildCategoryRegretRates(a.items)
+	if data.TrendGranularity == "" {
+		data.TrendGranularity = trendGranularityMonth
+	}
+	periods := a.trendPeriodsLocked(data.TrendGranularity)
+	data.DecisionTrend = buildDecisionTrend(a.items, periods)
+	data.SavedTrend = buildSavedTrend(carried, periods)
+	if data.TrendSettings == (trendSettingsForm{}) {
+		data.TrendSettings = a.trendSettingsFormLocked()
+	}
+	if data.ProjectionSettings == (projectionSettingsForm{}) {
+		data.ProjectionSettings = a.projectionSettingsFormLocked()
+	}
+	rate, years := a.projectionSettingsLocked()
+	data.Projection = buildSavingsProjection(data.SavedTrend, data.TrendGranularity, rate, years)
+	data.HoursGoal = a.hoursGoalProgressLocked(time.Now())
+	if data.HoursGoalInput == "" && a.hoursGoal > 0 {
+		data.HoursGoalInput = strconv.Itoa(a.hoursGoal)
+	}
+	data.WeekStartOptions = weekStartOptions
 	data.CategoryRatios = buildCategorySkipRatios(a.items)
 	data.Currency = profileCurrencyOrDefault(a.currency)
 	data.ActiveProfile = a.currentUserIDLocked()
@@ -1312,6 +1841,8 @@ func (a *App) renderItemForm(w http.ResponseWriter, data itemFormViewData) {
 	data.Items = append([]Item(nil), a.items...)
 	data.Currency = profileCurrencyOrDefault(a.currency)
 	data.ActiveProfile = a.currentUserIDLocked()
+	data.ItemTemplates = append([]itemTemplate(nil), a.itemTemplates...)
+	data.Payday = a.payday
 	a.mu.Unlock()
 
 	data.TagOptions = availableTagOptions(data.Items, a.tagCatalog)
@@ -1319,7 +1850,7 @@ func (a *App) renderItemForm(w http.ResponseWriter, data itemFormViewData) {
 
 	if data.FormValues.WaitPreset == "" {
 		a.mu.RLock()
-		data.FormValues.WaitPreset = defaultWaitPreset(a.defaultWaitPreset)
+		data.FormValues.WaitPreset = domain.NormalizeWaitPreset(a.defaultWaitPreset)
 		if data.FormValues.WaitPreset == "custom" {
 			data.FormValues.WaitCustomHours = a.defaultWaitCustomHours
 		}
@@ -1359,50 +1890,127 @@ func (a *App) renderProfile(w http.ResponseWriter, data profileViewData) {
 	if data.NtfyTopic == "" {
 		data.NtfyTopic = a.ntfyTopic
 	}
+	if data.WorkHoursMode == "" {
+		data.WorkHoursMode = domain.NormalizeWorkHoursMode(a.workHoursMode)
+	}
+	if data.ShiftHours == "" {
+		data.ShiftHours = a.shiftHours
+	}
+	if data.WorkHoursPrecision == "" {
+		data.WorkHoursPrecision = a.workHoursPrecision
+	}
+	if data.WorkHoursRounding == "" {
+		data.WorkHoursRounding = domain.NormalizeWorkHoursRounding(a.workHoursRounding)
+	}
+	if data.LandingPage == "" {
+		data.LandingPage = domain.NormalizeLandingPage(a.landingPage)
+	}
+	if data.MonthlyIncome == "" {
+		data.MonthlyIncome = a.monthlyIncome
+	}
+	if data.WeeklyHours == "" {
+		data.WeeklyHours = a.weeklyHours
+	}
+	data.IncomeSummary = a.incomeSummaryLocked()
+	if data.RenotifyPolicy == "" {
+		data.RenotifyPolicy = domain.NormalizeRenotifyMode(a.renotifyPolicy)
+	}
+	if data.RenotifyDays == "" && a.renotifyDays > 0 {
+		data.RenotifyDays = strconv.Itoa(a.renotifyDays)
+	}
+	// A rejected form keeps the checkbox as submitted.
+	if data.FieldErrors == nil {
+		data.NotifyDryRun = a.notifyDryRun
+	}
+	data.GlobalNotifyDryRun = a.notificationsDryRun
+	if data.NumberFormat == "" {
+		data.NumberFormat = string(domain.NormalizeNumberFormat(a.numberFormat))
+	}
+	if data.Payday == "" && a.payday > 0 {
+		data.Payday = strconv.Itoa(a.payday)
+	}
+	if data.AvatarEmoji == "" {
+		data.AvatarEmoji = a.avatarEmoji
+	}
+	if data.AvatarColor == "" {
+		data.AvatarColor = a.avatarColor
+	}
+	data.AvatarColors = domain.AvatarColors
 	if data.Currency == "" {
-		data.Currency = profileCurrencyOrDefault(a.currency)
+		data.Currency = normalizeCurrency(a.currency)
 	}
+	data.CurrencyOptions = supportedCurrencies
 	if data.ActiveProfile == "" {
 		data.ActiveProfile = a.currentUserIDLocked()
 	}
 	if data.DefaultWaitPreset == "" {
-		data.DefaultWaitPreset = defaultWaitPreset(a.defaultWaitPreset)
+		data.DefaultWaitPreset = domain.NormalizeWaitPreset(a.defaultWaitPreset)
 	}
 	if data.DefaultWaitCustomHours == "" {
 		data.DefaultWaitCustomHours = a.defaultWaitCustomHours
 	}
+	data.ShareURL = a.shareURLLocked()
+	data.QuickAddURL = a.quickAddURLLocked()
+	if data.ShareURL != "" {
+		data.ShareExpiresOn = formatShareExpiry(a.shareExpiresAt)
+		data.ShareExpired = shareLinkExpired(a.shareExpiresAt, time.Now())
+		data.ShareViews, data.ShareViewedAt = a.shareViewsLocked()
+	}
+	data.Archived = a.archived
+	data.CanArchive = a.db != nil
+	auditLog, err := a.auditLogLocked(a.currentUserIDLocked(), auditLogPageSize)
 	a.mu.RUnlock()
+	if err != nil {
+		log.Printf("db error while loading audit log: %v", err)
+	}
+	data.AuditLog = auditLog
 
 	data.ContentTemplate = "profile_content"
 	data.ScriptTemplate = "profile_script"
 	renderTemplate(w, a.templates, "layout", data)
 }
 
+// incomeSummaryLocked describes the saved wage in the other representation: the effective hourly wage
+// of a monthly income, or the monthly income an hourly wage adds up to.
+func (a *App) incomeSummaryLocked() string {
+	hourlyWage, err := domain.ParseHourlyWage(a.hourlyWage)
+	if err != nil {
+		return ""
+	}
+	weeklyHours, err := domain.ParseWeeklyHours(a.weeklyHours)
+	if err != nil {
+		return ""
+	}
+	hours := strconv.FormatFloat(weeklyHours, 'f', -1, 64)
+	if a.monthlyIncome != "" {
+		return fmt.Sprintf("Effective hourly wage: %s at %s h per week.", formatMoney(domain.MoneyFromFloat(hourlyWage), a.currency), hours)
+	}
+	return fmt.Sprintf("About %s per month at %s h per week.", formatMoney(domain.MoneyFromFloat(hourlyWage*weeklyHours*domain.WeeksPerMonth), a.currency), hours)
+}
+
 func (a *App) renderTagSettings(w http.ResponseWriter, data tagSettingsViewData) {
 	a.mu.RLock()
 	items := append([]Item(nil), a.items...)
 	tagCatalog := append([]string(nil), a.tagCatalog...)
+	tagWaitDefaults := a.tagWaitDefaults
+	data.StarterTags = a.starterTagsLocked()
 	if data.ActiveProfile == "" {
 		data.ActiveProfile = a.currentUserIDLocked()
 	}
 	a.mu.RUnlock()
 
 	data.TagOptions = availableTagOptions(items, tagCatalog)
+	data.TagWaitDefaults = make(map[string]string, len(data.TagOptions))
+	for _, tag := range data.TagOptions {
+		if preset, ok := lookupTagWaitDefault(tagWaitDefaults, tag); ok {
+			data.TagWaitDefaults[tag] = preset
+		}
+	}
+	data.WaitOptions = tagWaitPresetOptions
 	data.ContentTemplate = "tags_content"
 	renderTemplate(w, a.templates, "layout", data)
 }
 
-func parseProfileName(raw string) (string, error) {
-	name := strings.TrimSpace(raw)
-	if name == "" {
-		return "", errors.New("Please enter a profile name.")
-	}
-	if len([]rune(name)) > 64 {
-		return "", errors.New("Profile name must be 64 characters or fewer.")
-	}
-	return name, nil
-}
-
 func (a *App) hasActiveProfile() bool {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
@@ -1457,137 +2065,210 @@ func (a *App) listProfileNames() ([]string, error) {
 	return names, nil
 }
 
+func (a *App) chooseProfile(w http.ResponseWriter, r *http.Request) {
+	names, err := a.listProfileNames()
+	if err != nil {
+		http.Error(w, "could not load profiles", http.StatusInternalServerError)
+		return
+	}
+	a.renderProfileSwitch(w, names, "")
+}
+
+func (a *App) renderProfileSwitch(w http.ResponseWriter, names []string, errMessage string) {
+	names, err := a.withoutArchivedProfiles(names)
+	if err != nil {
+		log.Printf("db error while loading archived profiles: %v", err)
+	}
+	if err := a.loadProfileAvatars(); err != nil {
+		log.Printf("db error while loading profile avatars: %v", err)
+	}
+	a.mu.RLock()
+	inviteOnly := a.inviteOnly
+	a.mu.RUnlock()
+	renderTemplate(w, a.templates, "layout", profileSwitchViewData{Title: "Choose profile", CurrentPath: "/switch-profile", ContentTemplate: "switch_profile_content", Names: names, SelectedName: "", Error: errMessage, ActiveProfile: a.activeProfileName(), DemoAvailable: a.demoAvailable(), InviteOnly: inviteOnly})
+}
+
 func (a *App) switchProfile(w http.ResponseWriter, r *http.Request) {
-	if r.URL.Path != "/switch-profile" {
-		http.NotFound(w, r)
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+	name, err := domain.ParseProfileName(r.FormValue("profile_name"))
+	if err != nil {
+		names, _ := a.listProfileNames()
+		a.renderProfileSwitch(w, names, err.Error())
 		return
 	}
 
-	switch r.Method {
-	case http.MethodGet, http.MethodHead:
+	a.mu.RLock()
+	inviteOnly := a.inviteOnly
+	a.mu.RUnlock()
+	if inviteOnly {
 		names, err := a.listProfileNames()
 		if err != nil {
 			http.Error(w, "could not load profiles", http.StatusInternalServerError)
 			return
 		}
-		renderTemplate(w, a.templates, "layout", profileSwitchViewData{Title: "Choose profile", CurrentPath: "/switch-profile", ContentTemplate: "switch_profile_content", Names: names, SelectedName: "", ActiveProfile: a.activeProfileName()})
-	case http.MethodPost:
-		if err := r.ParseForm(); err != nil {
-			http.Error(w, "invalid form data", http.StatusBadRequest)
-			return
-		}
-		name, err := parseProfileName(r.FormValue("profile_name"))
-		if err != nil {
-			names, _ := a.listProfileNames()
-			renderTemplate(w, a.templates, "layout", profileSwitchViewData{Title: "Choose profile", CurrentPath: "/switch-profile", ContentTemplate: "switch_profile_content", Names: names, SelectedName: "", Error: err.Error(), ActiveProfile: a.activeProfileName()})
+		if !slices.Contains(names, name) {
+			w.WriteHeader(http.StatusForbidden)
+			a.renderProfileSwitch(w, names, "New profiles need an invite link from the admin of this instance.")
 			return
 		}
+	}
 
-		a.mu.Lock()
-		a.activeUserID = name
-		if err := a.loadStateFromDB(name); err != nil {
-			a.mu.Unlock()
-			http.Error(w, "could not switch profile", http.StatusInternalServerError)
-			return
-		}
-		isNewProfile := !a.profileExists
-		if strings.TrimSpace(a.hourlyWage) == "" {
-			a.hourlyWage = defaultProfileHourlyWage
-		}
-		if strings.TrimSpace(a.currency) == "" {
-			a.currency = normalizeCurrency("")
-		}
-		if err := a.persistProfileLocked(); err != nil {
-			a.mu.Unlock()
-			http.Error(w, "could not initialize profile", http.StatusInternalServerError)
-			return
-		}
-		needsProfileSetup := isNewProfile
-		a.mu.Unlock()
-		http.SetCookie(w, &http.Cookie{Name: "active_profile", Value: name, Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode})
-		if needsProfileSetup {
-			http.Redirect(w, r, "/settings/profile", http.StatusSeeOther)
-			return
-		}
-		http.Redirect(w, r, "/", http.StatusSeeOther)
-	default:
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	a.mu.LockContext(r.Context())
+	isNewProfile, err := a.enterProfileLocked(name, "", r)
+	a.mu.Unlock()
+	if err != nil {
+		log.Printf("db error while switching profile: %v", err)
+		http.Error(w, "could not switch profile", http.StatusInternalServerError)
+		return
+	}
+	redirectIntoProfile(w, r, name, isNewProfile)
+}
+
+// enterProfileLocked makes name the active profile, creating it with default settings if it does not
+// exist yet, and records the switch or creation in the audit log together with note.
+func (a *App) enterProfileLocked(name, note string, r *http.Request) (isNewProfile bool, err error) {
+	previousProfileName := a.activeUserID
+	a.activeUserID = name
+	if err := a.loadStateFromDB(name); err != nil {
+		return false, err
+	}
+	isNewProfile = !a.profileExists
+	if isNewProfile {
+		a.onboardingStep = onboardingSteps[0].Key
+	}
+	if strings.TrimSpace(a.hourlyWage) == "" {
+		a.hourlyWage = defaultProfileHourlyWage
 	}
+	if strings.TrimSpace(a.currency) == "" {
+		a.currency = normalizeCurrency("")
+	}
+	if err := a.persistProfileLocked(); err != nil {
+		return false, err
+	}
+	event, detail := auditProfileSwitched, note
+	if isNewProfile {
+		event = auditProfileCreated
+	}
+	if previousProfileName != "" && previousProfileName != name {
+		detail = strings.TrimSpace("from " + previousProfileName + " " + note)
+	}
+	a.recordAuditLocked(name, event, detail, r)
+	return isNewProfile, nil
 }
 
-func parseHourlyWage(raw string) (float64, error) {
-	parsed, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
-	if err != nil || parsed <= 0 {
-		return 0, errors.New("Please enter a valid hourly wage (> 0).")
+// redirectIntoProfile remembers the profile in the browser and continues with its setup if it is new.
+func redirectIntoProfile(w http.ResponseWriter, r *http.Request, name string, isNewProfile bool) {
+	http.SetCookie(w, &http.Cookie{Name: "active_profile", Value: name, Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode})
+	if isNewProfile {
+		http.Redirect(w, r, "/onboarding", http.StatusSeeOther)
+		return
 	}
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
 
-	return parsed, nil
+// purchaseAllowedInput returns the form field the chosen wait preset reads: the free-text wait for the
+// "text" preset, otherwise the buy-after date.
+func purchaseAllowedInput(r *http.Request) string {
+	if strings.TrimSpace(r.FormValue("wait_preset")) == domain.WaitPresetText {
+		return strings.TrimSpace(r.FormValue("wait_text"))
+	}
+	return strings.TrimSpace(r.FormValue("purchase_allowed_at"))
 }
 
-func parsePrice(raw string) (float64, bool) {
-	parsed, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
-	if err != nil || parsed <= 0 {
+// parsePrice reads a price written with a decimal point, as in seeded data and older stored items.
+func parsePrice(raw string) (domain.Money, bool) {
+	parsed, err := domain.ParsePrice(raw, domain.NumberFormatPoint)
+	if err != nil {
 		return 0, false
 	}
 
 	return parsed, true
 }
 
-func (a *App) promoteReadyItemsLocked(now time.Time) {
-	for i := range a.items {
-		if a.items[i].Status != "Waiting" {
-			continue
-		}
-		if !a.items[i].PurchaseAllowedAt.After(now) {
-			a.items[i].Status = "Ready to buy"
-			if err := a.updatePromotedItemLocked(a.items[i]); err != nil {
-				log.Printf("db error while promoting item %d: %v", a.items[i].ID, err)
-			}
-			a.sendNtfyNotificationLocked(a.items[i])
-		}
+// parsePriceLocked reads a price entered by the active profile in its number format.
+func (a *App) parsePriceLocked(raw string) (domain.Money, bool) {
+	parsed, err := domain.ParsePrice(raw, domain.NormalizeNumberFormat(a.numberFormat))
+	if err != nil {
+		return 0, false
 	}
+
+	return parsed, true
 }
 
-func (a *App) sendNtfyNotificationLocked(item Item) {
-	if item.NtfyAttempted {
-		return
+// itemPrice returns the stored price of an item. Only items without one, such as test fixtures, have their text parsed.
+func itemPrice(item Item) (domain.Money, bool) {
+	if item.HasPriceValue {
+		return item.PriceCents, true
 	}
+	return parsePrice(item.Price)
+}
 
-	for i := range a.items {
-		if a.items[i].ID == item.ID {
-			a.items[i].NtfyAttempted = true
-			if err := a.markNtfyAttemptedLocked(item.ID); err != nil {
-				log.Printf("db error while marking ntfy attempt for item %d: %v", item.ID, err)
-			}
-			break
-		}
+func (a *App) promoteReadyItemsLocked(now time.Time) {
+	if a.archived {
+		return
+	}
+	service := a.itemServiceLocked()
+	service.Now = func() time.Time { return now }
+	a.promoting = true
+	promoted, err := service.PromoteReady()
+	a.promoting = false
+	if err != nil {
+		log.Printf("db error while promoting items: %v", err)
+	}
+	if len(promoted) > 0 && a.db != nil {
+		a.triggerJob("outbox")
 	}
+}
+
+// renotifyPolicyLocked returns the active profile's re-notification policy.
+func (a *App) renotifyPolicyLocked() domain.RenotifyPolicy {
+	return domain.RenotifyPolicy{Mode: domain.NormalizeRenotifyMode(a.renotifyPolicy), Days: a.renotifyDays}
+}
 
+// sendReadyNtfyLocked announces newly ready items in one message on the active profile's ntfy topic, if
+// one is set, and returns ntfy's status code.
+func (a *App) sendReadyNtfyLocked(items ...Item) (int, error) {
 	if strings.TrimSpace(a.ntfyURL) == "" || strings.TrimSpace(a.ntfyTopic) == "" {
-		log.Printf("ntfy skipped for item %d: endpoint/topic not configured", item.ID)
-		return
+		log.Printf("ntfy skipped for item %d: endpoint/topic not configured", items[0].ID)
+		return 0, errChannelNotConfigured
+	}
+
+	message := readyMessage(items)
+	if len(items) == 1 {
+		if note := similarItemsNote(items[0], a.items, profileCurrencyOrDefault(a.currency)); note != "" {
+			message += "\n" + note
+		}
+	}
+	message = fmt.Sprintf("%s\nDashboard: %s", message, a.dashboardLink())
+	if a.notificationDryRunLocked(effectNtfy, a.ntfyURL+"/"+a.ntfyTopic, message) {
+		return 0, nil
 	}
+	return a.postNtfyMessage(a.mu.Context(), a.ntfyURL, a.ntfyTopic, "Impulse Pause reminder", message)
+}
 
-	message := fmt.Sprintf("%s is now ready to buy.\nDashboard: %s", item.Title, a.dashboardLink())
-	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/%s", a.ntfyURL, a.ntfyTopic), strings.NewReader(message))
+// postNtfyMessage publishes a message on an ntfy topic and returns the response status, 0 when there was none.
+func postNtfyMessage(ctx context.Context, endpoint, topic, title, message string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/%s", endpoint, topic), strings.NewReader(message))
 	if err != nil {
-		log.Printf("ntfy request creation failed for item %d: %v", item.ID, err)
-		return
+		return 0, fmt.Errorf("create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
-	req.Header.Set("Title", "Impulse Pause reminder")
+	req.Header.Set("Title", title)
 
-	client := &http.Client{Timeout: 2 * time.Second}
+	client := &http.Client{Timeout: 2 * time.Second, Transport: outboundTransport}
 	resp, err := client.Do(req)
 	if err != nil {
-		log.Printf("ntfy request failed for item %d: %v", item.ID, err)
-		return
+		return 0, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode >= http.StatusInternalServerError {
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
-		log.Printf("ntfy request returned %d for item %d: %s", resp.StatusCode, item.ID, strings.TrimSpace(string(body)))
+		return resp.StatusCode, fmt.Errorf("ntfy returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
 	}
+	return resp.StatusCode, nil
 }
 
 func (a *App) dashboardLink() string {
@@ -1602,45 +2283,94 @@ func workHoursAvailable(item Item, hourlyWage float64, hasHourlyWage bool) bool
 		return false
 	}
 
-	_, ok := parsePrice(item.Price)
+	_, ok := itemPrice(item)
 	return ok
 }
 
-func formatWorkHours(item Item, hourlyWage float64) string {
-	price, ok := parsePrice(item.Price)
+func formatWorkHours(item Item, hourlyWage float64, framing workEffortFraming) string {
+	price, ok := itemPrice(item)
 	if !ok || hourlyWage <= 0 {
 		return ""
 	}
 
-	hours := price / hourlyWage
-	roundedHours := math.Round(hours*10) / 10
-	return fmt.Sprintf("%.1f", roundedHours)
+	return framing.format(price.Float() / hourlyWage)
 }
 
-func normalizeCurrency(raw string) string {
-	trimmed := strings.TrimSpace(raw)
-	if trimmed == "" {
-		return "€"
-	}
-	return trimmed
+const workHoursPerDay = 8.0
+
+// workEffortFraming is how the dashboard expresses an item's price in terms of the profile's wage.
+type workEffortFraming struct {
+	Mode        string
+	ShiftHours  float64
+	WeeklyHours float64
+	// Precision is the number of decimals shown and Rounding the domain.WorkHoursRound* mode.
+	Precision int
+	Rounding  string
 }
 
-func profileCurrencyOrDefault(raw string) string {
-	return normalizeCurrency(raw)
+// defaultWorkEffortFraming shows work hours to one decimal, rounded to the nearest.
+var defaultWorkEffortFraming = workEffortFraming{Mode: domain.WorkHoursModeHours, ShiftHours: domain.DefaultShiftHours, WeeklyHours: domain.DefaultWeeklyHours, Precision: domain.DefaultWorkHoursPrecision, Rounding: domain.WorkHoursRoundNearest}
+
+// format rounds a work cost the way the profile asked for and prints exactly Precision decimals.
+func (f workEffortFraming) format(value float64) string {
+	return strconv.FormatFloat(domain.RoundWorkHours(value, f.Precision, f.Rounding), 'f', f.Precision, 64)
+}
+
+func (a *App) workEffortFramingLocked() workEffortFraming {
+	shiftHours, err := domain.ParseShiftHours(a.shiftHours)
+	if err != nil {
+		shiftHours = domain.DefaultShiftHours
+	}
+	weeklyHours, err := domain.ParseWeeklyHours(a.weeklyHours)
+	if err != nil {
+		weeklyHours = domain.DefaultWeeklyHours
+	}
+	precision, err := domain.ParseWorkHoursPrecision(a.workHoursPrecision)
+	if err != nil {
+		precision = domain.DefaultWorkHoursPrecision
+	}
+	return workEffortFraming{Mode: domain.NormalizeWorkHoursMode(a.workHoursMode), ShiftHours: shiftHours, WeeklyHours: weeklyHours, Precision: precision, Rounding: domain.NormalizeWorkHoursRounding(a.workHoursRounding)}
 }
 
-func formatMoney(amount float64, currency string) string {
-	return fmt.Sprintf("%s %.2f", profileCurrencyOrDefault(currency), amount)
+// formatWorkEffort renders the labelled work cost of an item, for example "Work hours: 4.0 h" or
+// "Monthly income: 2.3%", rounded to the profile's precision. Monthly income assumes a 40 hour week unless
+// the profile sets its weekly hours.
+func formatWorkEffort(item Item, hourlyWage float64, framing workEffortFraming) string {
+	price, ok := itemPrice(item)
+	if !ok || hourlyWage <= 0 {
+		return ""
+	}
+
+	hours := price.Float() / hourlyWage
+	switch framing.Mode {
+	case domain.WorkHoursModeDays:
+		return "Work days: " + framing.format(hours/workHoursPerDay)
+	case domain.WorkHoursModeShifts:
+		shiftHours := framing.ShiftHours
+		if shiftHours <= 0 {
+			shiftHours = domain.DefaultShiftHours
+		}
+		return fmt.Sprintf("Shifts: %s × %s h", framing.format(hours/shiftHours), strconv.FormatFloat(shiftHours, 'f', -1, 64))
+	case domain.WorkHoursModeIncome:
+		weeklyHours := framing.WeeklyHours
+		if weeklyHours <= 0 {
+			weeklyHours = domain.DefaultWeeklyHours
+		}
+		monthlyHours := weeklyHours * domain.WeeksPerMonth
+		return "Monthly income: " + framing.format(hours/monthlyHours*100) + "%"
+	default:
+		return "Work hours: " + formatWorkHours(item, hourlyWage, framing) + " h"
+	}
 }
 
-func buildDashboardStats(items []Item) (skippedCount int, savedAmount float64, topCategories []categoryCount) {
+func buildDashboardStats(items []Item) (skippedCount int, savedAmount domain.Money, topCategories []categoryCount) {
 	categoryTotals := map[string]int{}
 
 	for _, item := range items {
-		if item.Status == "Skipped" {
+		if item.Status == domain.StatusSkipped {
 			skippedCount++
 			if item.HasPriceValue {
-				savedAmount += item.PriceValue
+				savedAmount += item.PriceCents
 			}
 		}
 
@@ -1667,77 +2397,18 @@ func buildDashboardStats(items []Item) (skippedCount int, savedAmount float64, t
 	return skippedCount, savedAmount, topCategories
 }
 
-func buildMonthlyDecisionTrend(items []Item) []monthlyDecisionTrend {
-	monthly := map[string]*monthlyDecisionTrend{}
-	for _, item := range items {
-		if item.Status != "Bought" && item.Status != "Skipped" {
-			continue
-		}
-		month := item.CreatedAt.Format("2006-01")
-		bucket, exists := monthly[month]
-		if !exists {
-			bucket = &monthlyDecisionTrend{Month: month}
-			monthly[month] = bucket
-		}
-		if item.Status == "Bought" {
-			bucket.BoughtCount++
-		} else {
-			bucket.SkippedCount++
-		}
-	}
-
-	if len(monthly) == 0 {
-		return nil
-	}
-
-	months := mapKeys(monthly)
-	slices.Sort(months)
-
-	trends := make([]monthlyDecisionTrend, 0, len(months))
-	for _, month := range months {
-		trends = append(trends, *monthly[month])
-	}
-
-	return trends
-}
-
-func buildMonthlySavedTrend(items []Item) []monthlySavedAmount {
-	monthly := map[string]float64{}
-	for _, item := range items {
-		if item.Status != "Skipped" || !item.HasPriceValue {
-			continue
-		}
-		month := item.CreatedAt.Format("2006-01")
-		monthly[month] += item.PriceValue
-	}
-
-	if len(monthly) == 0 {
-		return nil
-	}
-
-	months := mapKeys(monthly)
-	slices.Sort(months)
-
-	trend := make([]monthlySavedAmount, 0, len(months))
-	for _, month := range months {
-		trend = append(trend, monthlySavedAmount{Month: month, Amount: monthly[month]})
-	}
-
-	return trend
-}
-
 func buildCategorySkipRatios(items []Item) []categorySkipRatio {
 	decisions := map[string]int{}
 	skips := map[string]int{}
 
 	for _, item := range items {
-		if item.Status != "Bought" && item.Status != "Skipped" {
+		if !item.Status.Decided() {
 			continue
 		}
 
 		for _, category := range categoriesFromTags(item.Tags) {
 			decisions[category]++
-			if item.Status == "Skipped" {
+			if item.Status == domain.StatusSkipped {
 				skips[category]++
 			}
 		}
@@ -1813,13 +2484,9 @@ func categoriesFromTags(rawTags string) []string {
 
 func availableTagOptions(items []Item, catalog []string) []string {
 	options := make([]string, 0, len(catalog)+len(items))
-	if len(catalog) == 0 {
-		options = append(options, defaultTagOptions...)
-	} else {
-		for _, tag := range catalog {
-			if trimmed := strings.TrimSpace(tag); trimmed != "" {
-				options = append(options, trimmed)
-			}
+	for _, tag := range catalog {
+		if trimmed := strings.TrimSpace(tag); trimmed != "" {
+			options = append(options, trimmed)
 		}
 	}
 
@@ -1951,14 +2618,16 @@ func removeTagFromCSV(rawTags string, toRemove string) string {
 func mul100(v float64) float64 {
 	return v * 100
 }
-func statusBadgeClass(status string) string {
+func statusBadgeClass(status domain.Status) string {
 	switch status {
-	case "Ready to buy":
+	case domain.StatusReady:
 		return "text-bg-success"
-	case "Bought":
+	case domain.StatusBought:
 		return "text-bg-primary"
-	case "Skipped":
+	case domain.StatusSkipped:
 		return "text-bg-secondary"
+	case domain.StatusResearching:
+		return "text-bg-info"
 	default:
 		return "text-bg-warning"
 	}
@@ -1981,10 +2650,3 @@ func renderTemplate(w http.ResponseWriter, tpls *template.Template, name string,
 		http.Error(w, "internal server error", http.StatusInternalServerError)
 	}
 }
-
-func loggingMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		log.Printf("%s %s", r.Method, r.URL.Path)
-		next.ServeHTTP(w, r)
-	})
-}
@@ -1,6 +1,7 @@
 package web
 
 import (
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -10,6 +11,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"mvpapp/internal/domain"
 )
 
 func seedProfile(app *App) {
@@ -68,6 +71,46 @@ func TestHomeRouteRejectsPost(t *testing.T) {
 	}
 }
 
+func TestMethodMismatchListsAllowedMethods(t *testing.T) {
+	app := NewApp()
+	req := httptest.NewRequest(http.MethodPut, "/settings/profile", nil)
+	rr := httptest.NewRecorder()
+
+	app.Handler().ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", rr.Code)
+	}
+	if allow := rr.Header().Get("Allow"); !strings.Contains(allow, http.MethodGet) || !strings.Contains(allow, http.MethodPost) {
+		t.Fatalf("expected Allow header with GET and POST, got %q", allow)
+	}
+}
+
+func TestEditItemRouteUsesPathParameter(t *testing.T) {
+	app := NewApp()
+	seedProfile(app)
+	app.mu.Lock()
+	app.items = []Item{{ID: 7, Title: "Desk lamp", Status: "Waiting", WaitPreset: "24h", PurchaseAllowedAt: time.Now().Add(time.Hour)}}
+	app.mu.Unlock()
+
+	req := httptest.NewRequest(http.MethodGet, "/items/7/edit", nil)
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	if body := rr.Body.String(); !strings.Contains(body, `action="/items/7/edit"`) || !strings.Contains(body, "Desk lamp") {
+		t.Fatalf("expected edit form posting to the path-parameter route")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/items/abc/edit", nil)
+	rr = httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a non-numeric id, got %d", rr.Code)
+	}
+}
+
 func TestInsightsRouteGet(t *testing.T) {
 	app := NewApp()
 	req := httptest.NewRequest(http.MethodGet, "/insights", nil)
@@ -494,8 +537,8 @@ func TestHomeSortsByPriceAscending(t *testing.T) {
 	now := time.Now()
 	app.mu.Lock()
 	app.items = append(app.items,
-		Item{ID: 1, Title: "High", Price: "100", PriceValue: 100, HasPriceValue: true, Status: "Waiting", CreatedAt: now.Add(-2 * time.Hour), PurchaseAllowedAt: now.Add(24 * time.Hour)},
-		Item{ID: 2, Title: "Low", Price: "10", PriceValue: 10, HasPriceValue: true, Status: "Waiting", CreatedAt: now.Add(-1 * time.Hour), PurchaseAllowedAt: now.Add(24 * time.Hour)},
+		Item{ID: 1, Title: "High", Price: "100", PriceCents: 10000, HasPriceValue: true, Status: "Waiting", CreatedAt: now.Add(-2 * time.Hour), PurchaseAllowedAt: now.Add(24 * time.Hour)},
+		Item{ID: 2, Title: "Low", Price: "10", PriceCents: 1000, HasPriceValue: true, Status: "Waiting", CreatedAt: now.Add(-1 * time.Hour), PurchaseAllowedAt: now.Add(24 * time.Hour)},
 	)
 	app.mu.Unlock()
 
@@ -620,22 +663,6 @@ func TestStatusUpdateFromWaitingReturnsConflict(t *testing.T) {
 	}
 }
 
-func TestParsePurchaseAllowedAtWithTimezoneOffset(t *testing.T) {
-	parsed, err := parsePurchaseAllowedAt("2026-02-12T10:30", "-120")
-	if err != nil {
-		t.Fatalf("expected valid datetime, got %v", err)
-	}
-	if got := parsed.Format(time.RFC3339); got != "2026-02-12T10:30:00+02:00" {
-		t.Fatalf("unexpected parsed datetime %q", got)
-	}
-}
-
-func TestParsePurchaseAllowedAtRejectsInvalidTimezoneOffset(t *testing.T) {
-	if _, err := parsePurchaseAllowedAt("2026-02-12T10:30", "oops"); err == nil {
-		t.Fatalf("expected timezone parse error")
-	}
-}
-
 func TestCreateItemWithSpecificDateWaitPreset(t *testing.T) {
 	app := NewApp()
 	seedProfile(app)
@@ -1103,6 +1130,59 @@ func TestReadyToBuySendsSingleNtfyNotification(t *testing.T) {
 	}
 }
 
+func TestReadyNotificationsFollowTheRenotifyPolicy(t *testing.T) {
+	requestCount := 0
+	ntfyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ntfyServer.Close()
+
+	tests := []struct {
+		name         string
+		policy       string
+		days         int
+		lastNotified time.Duration
+		want         int
+	}{
+		{name: "always", policy: "always", lastNotified: time.Hour, want: 1},
+		{name: "once", policy: "once", lastNotified: 30 * 24 * time.Hour, want: 0},
+		{name: "within cooldown", policy: "days", days: 3, lastNotified: 2 * 24 * time.Hour, want: 0},
+		{name: "after cooldown", policy: "days", days: 3, lastNotified: 4 * 24 * time.Hour, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := NewApp()
+			seedProfile(app)
+			requestCount = 0
+
+			app.mu.Lock()
+			app.ntfyURL = ntfyServer.URL
+			app.ntfyTopic = "impulse-pause"
+			app.renotifyPolicy = tt.policy
+			app.renotifyDays = tt.days
+			// The item was announced before and then edited back to waiting, which resets NtfyAttempted.
+			notifiedAt := time.Now().Add(-tt.lastNotified)
+			app.items = append(app.items, Item{ID: 9, Title: "Laptop stand", Status: "Waiting", PurchaseAllowedAt: time.Now().Add(-time.Minute), NotifiedAt: notifiedAt})
+			app.mu.Unlock()
+
+			app.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if requestCount != tt.want {
+				t.Fatalf("expected %d ntfy requests, got %d", tt.want, requestCount)
+			}
+			app.mu.RLock()
+			defer app.mu.RUnlock()
+			if app.items[0].Status != "Ready to buy" {
+				t.Fatalf("expected the item to be promoted regardless of the policy, got %q", app.items[0].Status)
+			}
+			if notified := !app.items[0].NotifiedAt.Equal(notifiedAt); notified != (tt.want == 1) {
+				t.Fatalf("expected NotifiedAt to change only when notifying, got %v", app.items[0].NotifiedAt)
+			}
+		})
+	}
+}
+
 func TestReadyToBuyWithoutNtfyConfigStillPromotesItem(t *testing.T) {
 	app := NewApp()
 	seedProfile(app)
@@ -1220,6 +1300,7 @@ func TestBackgroundPromotionPromotesWithoutHTTPRequest(t *testing.T) {
 	app.mu.Unlock()
 
 	app.StartBackgroundPromotion(10 * time.Millisecond)
+	app.StartJobs()
 	time.Sleep(60 * time.Millisecond)
 
 	app.mu.RLock()
@@ -1229,42 +1310,6 @@ func TestBackgroundPromotionPromotesWithoutHTTPRequest(t *testing.T) {
 	}
 }
 
-func TestParseWaitDuration(t *testing.T) {
-	tests := []struct {
-		name            string
-		preset          string
-		customHours     string
-		wantDuration    time.Duration
-		wantErrContains string
-	}{
-		{name: "default", preset: "", wantDuration: 24 * time.Hour},
-		{name: "24h", preset: "24h", wantDuration: 24 * time.Hour},
-		{name: "7d", preset: "7d", wantDuration: 7 * 24 * time.Hour},
-		{name: "30d", preset: "30d", wantDuration: 30 * 24 * time.Hour},
-		{name: "custom", preset: "custom", customHours: "5", wantDuration: 5 * time.Hour},
-		{name: "invalid custom", preset: "custom", customHours: "0", wantErrContains: "valid number"},
-		{name: "invalid preset", preset: "abc", wantErrContains: "valid wait time"},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got, err := parseWaitDuration(tt.preset, tt.customHours)
-			if tt.wantErrContains != "" {
-				if err == nil || !strings.Contains(err.Error(), tt.wantErrContains) {
-					t.Fatalf("expected error containing %q, got %v", tt.wantErrContains, err)
-				}
-				return
-			}
-			if err != nil {
-				t.Fatalf("unexpected error: %v", err)
-			}
-			if got != tt.wantDuration {
-				t.Fatalf("expected %s, got %s", tt.wantDuration, got)
-			}
-		})
-	}
-}
-
 func TestFormatWorkHoursRoundingBoundaries(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -1279,7 +1324,7 @@ func TestFormatWorkHoursRoundingBoundaries(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := formatWorkHours(Item{Price: tt.price}, tt.hourlyWage)
+			got := formatWorkHours(Item{Price: tt.price}, tt.hourlyWage, defaultWorkEffortFraming)
 			if got != tt.want {
 				t.Fatalf("expected %q, got %q", tt.want, got)
 			}
@@ -1287,46 +1332,14 @@ func TestFormatWorkHoursRoundingBoundaries(t *testing.T) {
 	}
 }
 
-func TestParseHourlyWage(t *testing.T) {
-	tests := []struct {
-		name            string
-		raw             string
-		want            float64
-		wantErrContains string
-	}{
-		{name: "valid", raw: "20", want: 20},
-		{name: "valid decimal", raw: "17.5", want: 17.5},
-		{name: "empty", raw: "", wantErrContains: "valid hourly wage"},
-		{name: "zero", raw: "0", wantErrContains: "valid hourly wage"},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got, err := parseHourlyWage(tt.raw)
-			if tt.wantErrContains != "" {
-				if err == nil || !strings.Contains(err.Error(), tt.wantErrContains) {
-					t.Fatalf("expected error containing %q, got %v", tt.wantErrContains, err)
-				}
-				return
-			}
-			if err != nil {
-				t.Fatalf("unexpected error: %v", err)
-			}
-			if got != tt.want {
-				t.Fatalf("expected %v, got %v", tt.want, got)
-			}
-		})
-	}
-}
-
 func TestInsightsPageShowsDashboardInsights(t *testing.T) {
 	app := NewApp()
 
 	app.mu.Lock()
 	app.items = append(app.items,
-		Item{ID: 1, Title: "Keyboard", Price: "99.99", PriceValue: 99.99, HasPriceValue: true, Tags: "Tech, Desk", Status: "Skipped", PurchaseAllowedAt: time.Now().Add(-time.Hour)},
-		Item{ID: 2, Title: "Mouse", Price: "50", PriceValue: 50, HasPriceValue: true, Tags: "tech", Status: "Skipped", PurchaseAllowedAt: time.Now().Add(-time.Hour)},
-		Item{ID: 3, Title: "Shoes", Price: "120", PriceValue: 120, HasPriceValue: true, Tags: "Fashion", Status: "Bought", PurchaseAllowedAt: time.Now().Add(-time.Hour)},
+		Item{ID: 1, Title: "Keyboard", Price: "99.99", PriceCents: 9999, HasPriceValue: true, Tags: "Tech, Desk", Status: "Skipped", PurchaseAllowedAt: time.Now().Add(-time.Hour)},
+		Item{ID: 2, Title: "Mouse", Price: "50", PriceCents: 5000, HasPriceValue: true, Tags: "tech", Status: "Skipped", PurchaseAllowedAt: time.Now().Add(-time.Hour)},
+		Item{ID: 3, Title: "Shoes", Price: "120", PriceCents: 12000, HasPriceValue: true, Tags: "Fashion", Status: "Bought", PurchaseAllowedAt: time.Now().Add(-time.Hour)},
 	)
 	app.mu.Unlock()
 
@@ -1372,7 +1385,7 @@ func TestInsightsMetricsUpdateAfterStatusChange(t *testing.T) {
 		ID:                77,
 		Title:             "Noise-cancelling headphones",
 		Price:             "199",
-		PriceValue:        199,
+		PriceCents:        19900,
 		HasPriceValue:     true,
 		Tags:              "Tech",
 		Status:            "Ready to buy",
@@ -1465,14 +1478,14 @@ func TestBuildMonthlyDecisionTrend(t *testing.T) {
 		{Status: "Waiting", CreatedAt: time.Date(2026, 2, 3, 12, 0, 0, 0, now.Location())},
 	}
 
-	trend := buildMonthlyDecisionTrend(items)
+	trend := buildDecisionTrend(items, trendPeriods{})
 	if len(trend) != 2 {
 		t.Fatalf("expected 2 months, got %d", len(trend))
 	}
-	if trend[0].Month != "2026-01" || trend[0].BoughtCount != 1 || trend[0].SkippedCount != 1 {
+	if trend[0].Period != "2026-01" || trend[0].BoughtCount != 1 || trend[0].SkippedCount != 1 {
 		t.Fatalf("unexpected first month: %+v", trend[0])
 	}
-	if trend[1].Month != "2026-02" || trend[1].BoughtCount != 0 || trend[1].SkippedCount != 1 {
+	if trend[1].Period != "2026-02" || trend[1].BoughtCount != 0 || trend[1].SkippedCount != 1 {
 		t.Fatalf("unexpected second month: %+v", trend[1])
 	}
 }
@@ -1480,17 +1493,17 @@ func TestBuildMonthlyDecisionTrend(t *testing.T) {
 func TestBuildMonthlySavedTrend(t *testing.T) {
 	now := time.Now()
 	items := []Item{
-		{Status: "Skipped", HasPriceValue: true, PriceValue: 40.5, CreatedAt: time.Date(2026, 1, 4, 12, 0, 0, 0, now.Location())},
-		{Status: "Skipped", HasPriceValue: true, PriceValue: 9.5, CreatedAt: time.Date(2026, 1, 14, 12, 0, 0, 0, now.Location())},
+		{Status: "Skipped", HasPriceValue: true, PriceCents: 4050, CreatedAt: time.Date(2026, 1, 4, 12, 0, 0, 0, now.Location())},
+		{Status: "Skipped", HasPriceValue: true, PriceCents: 950, CreatedAt: time.Date(2026, 1, 14, 12, 0, 0, 0, now.Location())},
 		{Status: "Skipped", HasPriceValue: false, CreatedAt: time.Date(2026, 2, 2, 12, 0, 0, 0, now.Location())},
-		{Status: "Bought", HasPriceValue: true, PriceValue: 100, CreatedAt: time.Date(2026, 2, 3, 12, 0, 0, 0, now.Location())},
+		{Status: "Bought", HasPriceValue: true, PriceCents: 10000, CreatedAt: time.Date(2026, 2, 3, 12, 0, 0, 0, now.Location())},
 	}
 
-	trend := buildMonthlySavedTrend(items)
+	trend := buildSavedTrend(items, trendPeriods{})
 	if len(trend) != 1 {
 		t.Fatalf("expected 1 month, got %d", len(trend))
 	}
-	if trend[0].Month != "2026-01" || trend[0].Amount != 50 {
+	if trend[0].Period != "2026-01" || trend[0].Amount != 5000 {
 		t.Fatalf("unexpected saved trend: %+v", trend[0])
 	}
 }
@@ -1550,8 +1563,8 @@ func TestInsightsPageShowsTrendSections(t *testing.T) {
 
 	app.mu.Lock()
 	app.items = append(app.items,
-		Item{ID: 1, Title: "Keyboard", Price: "99.99", PriceValue: 99.99, HasPriceValue: true, Tags: "Tech", Status: "Skipped", CreatedAt: time.Date(2026, 1, 11, 12, 0, 0, 0, time.Local), PurchaseAllowedAt: time.Now().Add(-time.Hour)},
-		Item{ID: 2, Title: "Shoes", Price: "120", PriceValue: 120, HasPriceValue: true, Tags: "Fashion", Status: "Bought", CreatedAt: time.Date(2026, 1, 14, 12, 0, 0, 0, time.Local), PurchaseAllowedAt: time.Now().Add(-time.Hour)},
+		Item{ID: 1, Title: "Keyboard", Price: "99.99", PriceCents: 9999, HasPriceValue: true, Tags: "Tech", Status: "Skipped", CreatedAt: time.Date(2026, 1, 11, 12, 0, 0, 0, time.Local), PurchaseAllowedAt: time.Now().Add(-time.Hour)},
+		Item{ID: 2, Title: "Shoes", Price: "120", PriceCents: 12000, HasPriceValue: true, Tags: "Fashion", Status: "Bought", CreatedAt: time.Date(2026, 1, 14, 12, 0, 0, 0, time.Local), PurchaseAllowedAt: time.Now().Add(-time.Hour)},
 	)
 	app.mu.Unlock()
 
@@ -1588,6 +1601,28 @@ func TestHealthRoute(t *testing.T) {
 	}
 }
 
+func TestVersionRouteAndFooterShowTheBuild(t *testing.T) {
+	defer func(version, commit, date string) { Version, Commit, BuildDate = version, commit, date }(Version, Commit, BuildDate)
+	Version, Commit, BuildDate = "1.4.0", "3f2a9c1", "2026-10-16T08:00:00Z"
+	app := NewApp()
+
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/version", nil))
+	var info BuildInfo
+	if err := json.Unmarshal(rr.Body.Bytes(), &info); err != nil {
+		t.Fatalf("decode version: %v", err)
+	}
+	if rr.Code != http.StatusOK || info.Version != "1.4.0" || info.Commit != "3f2a9c1" || info.BuildDate != "2026-10-16T08:00:00Z" || info.GoVersion == "" {
+		t.Fatalf("unexpected version response %d %+v", rr.Code, info)
+	}
+
+	rr = httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/about", nil))
+	if !strings.Contains(rr.Body.String(), "Impulse Pause 1.4.0 (3f2a9c1, 2026-10-16T08:00:00Z)") {
+		t.Fatalf("expected the build in the footer, got %s", rr.Body.String())
+	}
+}
+
 func TestUnknownRoute(t *testing.T) {
 	app := NewApp()
 	req := httptest.NewRequest(http.MethodGet, "/missing", nil)
@@ -1672,8 +1707,8 @@ func TestDeleteItemRemovesItFromHomeAndInsights(t *testing.T) {
 	seedProfile(app)
 	app.mu.Lock()
 	app.items = append(app.items,
-		Item{ID: 1, Title: "Keep", Status: "Skipped", Price: "12.50", HasPriceValue: true, PriceValue: 12.5, Tags: "Office", PurchaseAllowedAt: time.Now().Add(-time.Hour), CreatedAt: time.Now().Add(-48 * time.Hour)},
-		Item{ID: 2, Title: "Delete me", Status: "Skipped", Price: "100.00", HasPriceValue: true, PriceValue: 100, Tags: "Tech", PurchaseAllowedAt: time.Now().Add(-time.Hour), CreatedAt: time.Now().Add(-24 * time.Hour)},
+		Item{ID: 1, Title: "Keep", Status: "Skipped", Price: "12.50", HasPriceValue: true, PriceCents: 1250, Tags: "Office", PurchaseAllowedAt: time.Now().Add(-time.Hour), CreatedAt: time.Now().Add(-48 * time.Hour)},
+		Item{ID: 2, Title: "Delete me", Status: "Skipped", Price: "100.00", HasPriceValue: true, PriceCents: 10000, Tags: "Tech", PurchaseAllowedAt: time.Now().Add(-time.Hour), CreatedAt: time.Now().Add(-24 * time.Hour)},
 	)
 	app.mu.Unlock()
 
@@ -1874,7 +1909,7 @@ func TestProfileCurrencyDefaultsToEuro(t *testing.T) {
 	if rr.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d", rr.Code)
 	}
-	if body := rr.Body.String(); !strings.Contains(body, "id=\"currency\"") || !strings.Contains(body, "value=\"€\"") {
+	if body := rr.Body.String(); !strings.Contains(body, "id=\"currency\"") || !strings.Contains(body, "<option value=\"EUR\" selected>") {
 		t.Fatalf("expected default euro currency in profile form")
 	}
 }
@@ -1938,7 +1973,7 @@ func TestProfileCurrencyPersistsAndRendersAcrossViews(t *testing.T) {
 	app.mu.Lock()
 	app.items[0].Status = "Skipped"
 	app.items[0].HasPriceValue = true
-	app.items[0].PriceValue = 199.9
+	app.items[0].PriceCents = 19990
 	app.mu.Unlock()
 
 	insightsReq := httptest.NewRequest(http.MethodGet, "/insights", nil)
@@ -1973,7 +2008,7 @@ func TestProfileCurrencyFallsBackToEuroWhenEmpty(t *testing.T) {
 	if profileRR.Code != http.StatusOK {
 		t.Fatalf("expected profile 200, got %d", profileRR.Code)
 	}
-	if body := profileRR.Body.String(); !strings.Contains(body, "value=\"€\"") {
+	if body := profileRR.Body.String(); !strings.Contains(body, "<option value=\"EUR\" selected>") {
 		t.Fatalf("expected empty currency to fallback to euro")
 	}
 }
@@ -2049,7 +2084,7 @@ func TestHomeNextReadySortAcrossStatusesWhenAllSelected(t *testing.T) {
 
 func TestFilterAndSortItemsMonkeyishNextReadyOrdering(t *testing.T) {
 	now := time.Now()
-	statuses := []string{"Waiting", "Ready to buy", "Bought", "Skipped"}
+	statuses := []domain.Status{"Waiting", "Ready to buy", "Bought", "Skipped"}
 	items := make([]Item, 0, 60)
 	for i := 0; i < 60; i++ {
 		status := statuses[i%len(statuses)]
@@ -2067,7 +2102,7 @@ func TestFilterAndSortItemsMonkeyishNextReadyOrdering(t *testing.T) {
 		t.Fatalf("expected %d items, got %d", len(items), len(sorted))
 	}
 
-	rank := func(status string) int {
+	rank := func(status domain.Status) int {
 		switch status {
 		case "Ready to buy":
 			return 0
@@ -2100,6 +2135,56 @@ func TestFilterAndSortItemsMonkeyishNextReadyOrdering(t *testing.T) {
 	}
 }
 
+func TestFilterAndSortItemsPutsItemsUnlockingSoonFirst(t *testing.T) {
+	now := time.Now()
+	items := []Item{
+		{ID: 1, Title: "Ready", Status: "Ready to buy", PurchaseAllowedAt: now.Add(-time.Hour)},
+		{ID: 2, Title: "Next week", Status: "Waiting", PurchaseAllowedAt: now.Add(7 * 24 * time.Hour)},
+		{ID: 3, Title: "Tomorrow", Status: "Waiting", PurchaseAllowedAt: now.Add(30 * time.Hour)},
+		{ID: 4, Title: "Tonight", Status: "Waiting", PurchaseAllowedAt: now.Add(3 * time.Hour)},
+		{ID: 5, Title: "In three days", Status: "Waiting", PurchaseAllowedAt: now.Add(72 * time.Hour)},
+	}
+
+	sorted := filterAndSortItems(items, "", domain.OpenStatuses, "", "unlocking_soon")
+	var titles []string
+	for _, item := range sorted {
+		titles = append(titles, item.Title)
+	}
+	if got, want := strings.Join(titles, ", "), "Tonight, Tomorrow, Ready, In three days, Next week"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	if got := countUnlockingSoon(sorted, now); got != 2 {
+		t.Fatalf("expected two items in the unlocking soon section, got %d", got)
+	}
+}
+
+func TestHomeShowsUnlockingSoonSection(t *testing.T) {
+	app := NewApp()
+	seedProfile(app)
+	now := time.Now()
+	app.items = []Item{
+		{ID: 1, Title: "Camping stove", Status: "Waiting", WaitPreset: "7d", PurchaseAllowedAt: now.Add(5 * 24 * time.Hour), CreatedAt: now},
+		{ID: 2, Title: "Rain jacket", Status: "Waiting", WaitPreset: "24h", PurchaseAllowedAt: now.Add(20 * time.Hour), CreatedAt: now},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/?sort=unlocking_soon", nil)
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+
+	body := rr.Body.String()
+	prev := -1
+	for _, label := range []string{"Unlocking soon", "Rain jacket", "Everything else", "Camping stove"} {
+		idx := strings.Index(body, label)
+		if idx <= prev {
+			t.Fatalf("expected %q after the previous label in the dashboard", label)
+		}
+		prev = idx
+	}
+	if !strings.Contains(body, `<option value="unlocking_soon" selected>`) {
+		t.Fatal("expected the unlocking soon sort to be selected")
+	}
+}
+
 func TestSwitchProfilePageRendersExistingProfiles(t *testing.T) {
 	app, cleanup := newSQLiteTestApp(t)
 	defer cleanup()
@@ -2187,8 +2272,8 @@ func TestSwitchProfileNewProfileRedirectsToSettingsAndResetsProfileDefaults(t *t
 	if rr.Code != http.StatusSeeOther {
 		t.Fatalf("expected redirect, got %d", rr.Code)
 	}
-	if got := rr.Header().Get("Location"); got != "/settings/profile" {
-		t.Fatalf("expected redirect to /settings/profile for new profile, got %q", got)
+	if got := rr.Header().Get("Location"); got != "/onboarding" {
+		t.Fatalf("expected redirect to /onboarding for new profile, got %q", got)
 	}
 
 	settingsReq := httptest.NewRequest(http.MethodGet, "/settings/profile", nil)
@@ -2204,7 +2289,7 @@ func TestSwitchProfileNewProfileRedirectsToSettingsAndResetsProfileDefaults(t *t
 	if strings.Contains(body, "value=\"55\"") {
 		t.Fatalf("expected hourly wage to be reset for brand new profile")
 	}
-	if strings.Contains(body, "value=\"CHF\"") {
+	if strings.Contains(body, "<option value=\"CHF\" selected>") {
 		t.Fatalf("expected currency to be reset for brand new profile")
 	}
 	if !strings.Contains(body, "<option value=\"24h\" selected>") {
@@ -2245,6 +2330,7 @@ func TestSwitchProfileChangesContextAndSetsCookie(t *testing.T) {
 	form := url.Values{"profile_name": {"Alice"}}
 	req := httptest.NewRequest(http.MethodPost, "/switch-profile", strings.NewReader(form.Encode()))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(&http.Cookie{Name: "active_profile", Value: "Bob"})
 	rr := httptest.NewRecorder()
 	app.Handler().ServeHTTP(rr, req)
 
@@ -2255,13 +2341,14 @@ func TestSwitchProfileChangesContextAndSetsCookie(t *testing.T) {
 		t.Fatalf("expected active_profile cookie, got %q", got)
 	}
 
-	app.mu.RLock()
-	defer app.mu.RUnlock()
-	if app.activeUserID != "Alice" {
-		t.Fatalf("expected active user Alice, got %q", app.activeUserID)
+	homeReq := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, c := range rr.Result().Cookies() {
+		homeReq.AddCookie(c)
 	}
-	if len(app.items) != 1 || app.items[0].Title != "alice item" {
-		t.Fatalf("expected Alice items loaded after switch")
+	homeRR := httptest.NewRecorder()
+	app.Handler().ServeHTTP(homeRR, homeReq)
+	if body := homeRR.Body.String(); !strings.Contains(body, "alice item") {
+		t.Fatalf("expected Alice items after switch")
 	}
 }
 
@@ -2278,8 +2365,8 @@ func TestAboutShowsActiveProfileInHeader(t *testing.T) {
 	if rr.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d", rr.Code)
 	}
-	if body := rr.Body.String(); !strings.Contains(body, `<span class="profile-badge">Test</span>`) {
-		t.Fatalf("expected active profile in about header")
+	if body := rr.Body.String(); !strings.Contains(body, `<span class="profile-badge"><span class="avatar avatar-`) || !strings.Contains(body, `aria-hidden="true">T</span>Test</span>`) {
+		t.Fatalf("expected active profile with its avatar in about header")
 	}
 }
 
@@ -2308,6 +2395,7 @@ func TestProfileSettingsCanRenameActiveProfile(t *testing.T) {
 	form.Set("currency", "EUR")
 	req := httptest.NewRequest(http.MethodPost, "/settings/profile", strings.NewReader(form.Encode()))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(&http.Cookie{Name: "active_profile", Value: "OldName"})
 	rr := httptest.NewRecorder()
 	app.Handler().ServeHTTP(rr, req)
 
@@ -2318,15 +2406,9 @@ func TestProfileSettingsCanRenameActiveProfile(t *testing.T) {
 		t.Fatalf("expected active_profile cookie for renamed profile, got %q", got)
 	}
 
-	app.mu.RLock()
-	if app.activeUserID != "NewName" {
-		app.mu.RUnlock()
-		t.Fatalf("expected active profile renamed to NewName, got %q", app.activeUserID)
-	}
-	app.mu.RUnlock()
-
 	switchReq := httptest.NewRequest(http.MethodPost, "/switch-profile", strings.NewReader(url.Values{"profile_name": {"NewName"}}.Encode()))
 	switchReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	switchReq.AddCookie(&http.Cookie{Name: "active_profile", Value: "NewName"})
 	switchRR := httptest.NewRecorder()
 	app.Handler().ServeHTTP(switchRR, switchReq)
 	if switchRR.Code != http.StatusSeeOther {
@@ -2334,6 +2416,7 @@ func TestProfileSettingsCanRenameActiveProfile(t *testing.T) {
 	}
 
 	homeReq := httptest.NewRequest(http.MethodGet, "/", nil)
+	homeReq.AddCookie(&http.Cookie{Name: "active_profile", Value: "NewName"})
 	homeRR := httptest.NewRecorder()
 	app.Handler().ServeHTTP(homeRR, homeReq)
 	if homeRR.Code != http.StatusOK {
@@ -2372,9 +2455,6 @@ func TestHomeWithoutCookieSelectsSmallestProfileID(t *testing.T) {
 	if rr.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d", rr.Code)
 	}
-	if got := app.activeProfileName(); got != "Zed" {
-		t.Fatalf("expected smallest id profile Zed to be selected, got %q", got)
-	}
 	if got := rr.Header().Get("Set-Cookie"); !strings.Contains(got, "active_profile=Zed") {
 		t.Fatalf("expected active_profile cookie for selected profile, got %q", got)
 	}
@@ -2438,6 +2518,7 @@ func TestDeleteProfileRemovesActiveProfileAndRedirectsToSwitch(t *testing.T) {
 	app.mu.Unlock()
 
 	req := httptest.NewRequest(http.MethodPost, "/settings/profile/delete", nil)
+	req.AddCookie(&http.Cookie{Name: "active_profile", Value: "DeleteMe"})
 	rr := httptest.NewRecorder()
 	app.Handler().ServeHTTP(rr, req)
 
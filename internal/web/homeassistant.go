@@ -0,0 +1,291 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+// homeAssistantWebhookID is the webhook id the suggested automation listens on.
+const homeAssistantWebhookID = "impulse_pause"
+
+type homeAssistantViewData struct {
+	Title           string
+	CurrentPath     string
+	ContentTemplate string
+	ScriptTemplate  string
+	WebhookURL      string
+	SensorURL       string
+	Config          string
+	Error           string
+	Feedback        string
+	ActiveProfile   string
+}
+
+// homeAssistantState is the body of GET /api/v1/home-assistant, shaped for Home Assistant's RESTful sensor.
+type homeAssistantState struct {
+	Profile    string                   `json:"profile"`
+	Currency   string                   `json:"currency"`
+	Waiting    int                      `json:"waiting"`
+	Ready      int                      `json:"ready"`
+	SavedMonth float64                  `json:"saved_month"`
+	NextUnlock *time.Time               `json:"next_unlock"`
+	ReadyItems []homeAssistantReadyItem `json:"ready_items"`
+}
+
+type homeAssistantReadyItem struct {
+	ID         int       `json:"id"`
+	Title      string    `json:"title"`
+	Price      float64   `json:"price,omitempty"`
+	ReadySince time.Time `json:"ready_since"`
+}
+
+// homeAssistantEvent is posted to the profile's webhook when an item becomes ready to buy.
+type homeAssistantEvent struct {
+	Event        string  `json:"event"`
+	Profile      string  `json:"profile"`
+	ItemID       int     `json:"item_id"`
+	Title        string  `json:"title"`
+	Price        float64 `json:"price,omitempty"`
+	Currency     string  `json:"currency"`
+	Message      string  `json:"message"`
+	DashboardURL string  `json:"dashboard_url"`
+	DeliveryID   int64   `json:"delivery_id,omitempty"`
+}
+
+func buildHomeAssistantState(profileName, currency string, items []Item, now time.Time) homeAssistantState {
+	member := buildHouseholdMembers(map[string][]Item{profileName: items}, map[string]string{profileName: currency}, now)[0]
+	state := homeAssistantState{
+		Profile:    profileName,
+		Currency:   normalizeCurrency(currency),
+		Waiting:    member.Waiting,
+		Ready:      member.Ready,
+		SavedMonth: member.SavedMonth.Float(),
+		ReadyItems: []homeAssistantReadyItem{},
+	}
+
+	ready, _ := kioskBoard(items, now)
+	for _, item := range ready {
+		entry := homeAssistantReadyItem{ID: item.ID, Title: item.Title, ReadySince: item.PurchaseAllowedAt}
+		if item.HasPriceValue {
+			entry.Price = item.PriceCents.Float()
+		}
+		state.ReadyItems = append(state.ReadyItems, entry)
+	}
+	for _, item := range items {
+		if effectiveStatus(item, now) != domain.StatusWaiting {
+			continue
+		}
+		if state.NextUnlock == nil || item.PurchaseAllowedAt.Before(*state.NextUnlock) {
+			nextUnlock := item.PurchaseAllowedAt
+			state.NextUnlock = &nextUnlock
+		}
+	}
+	return state
+}
+
+// homeAssistantState serves the profile's sensor values. It authenticates with the profile's share link token.
+func (a *App) homeAssistantState(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimSpace(r.URL.Query().Get("token"))
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		token = strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+	}
+
+	a.mu.LockContext(r.Context())
+	a.promoteReadyItemsLocked(time.Now())
+	profileName, err := a.profileNameByShareTokenLocked(token)
+	if err != nil {
+		a.mu.Unlock()
+		log.Printf("db error while resolving share token: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "could not load state")
+		return
+	}
+	if profileName == "" {
+		a.mu.Unlock()
+		writeAPIError(w, http.StatusNotFound, "unknown token")
+		return
+	}
+	a.recordTokenUseLocked(profileName, "home assistant", r)
+	items, err := a.itemsForProfileLocked(profileName)
+	var currency string
+	if err == nil {
+		currency, err = a.currencyForProfileLocked(profileName)
+	}
+	a.mu.Unlock()
+	if err != nil {
+		log.Printf("db error while loading home assistant state: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "could not load state")
+		return
+	}
+
+	setShareHeaders(w)
+	writeJSON(w, http.StatusOK, buildHomeAssistantState(profileName, currency, maskPrivateItems(items), time.Now()))
+}
+
+// sendHomeAssistantEventLocked tells Home Assistant that an item of the active profile is ready to buy
+// and returns the webhook's status code. deliveryID is the outbox entry the event is sent for, or 0.
+func (a *App) sendHomeAssistantEventLocked(item Item, deliveryID int64) (int, error) {
+	if a.haWebhookURL == "" {
+		return 0, errChannelNotConfigured
+	}
+	// Home Assistant often announces on shared speakers and screens.
+	item = maskPrivate(item)
+
+	event := homeAssistantEvent{
+		Event:        "item_ready",
+		Profile:      a.currentUserIDLocked(),
+		ItemID:       item.ID,
+		Title:        item.Title,
+		Currency:     normalizeCurrency(a.currency),
+		Message:      fmt.Sprintf("%s is ready to buy.", item.Title),
+		DashboardURL: a.dashboardLink(),
+		DeliveryID:   deliveryID,
+	}
+	if item.HasPriceValue {
+		event.Price = item.PriceCents.Float()
+	}
+	if body, err := json.Marshal(event); err == nil && a.notificationDryRunLocked(effectHomeAssistant, a.haWebhookURL, string(body)) {
+		return 0, nil
+	}
+	return postHomeAssistantEvent(a.mu.Context(), a.haWebhookURL, event)
+}
+
+func postHomeAssistantEvent(ctx context.Context, webhookURL string, event homeAssistantEvent) (int, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return 0, fmt.Errorf("encode event: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 2 * time.Second, Transport: outboundTransport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return resp.StatusCode, fmt.Errorf("home assistant returned %d: %s", resp.StatusCode, strings.TrimSpace(string(text)))
+	}
+	return resp.StatusCode, nil
+}
+
+func parseHomeAssistantWebhookURL(raw string) (string, error) {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return "", nil
+	}
+	parsed, err := url.Parse(trimmed)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", errors.New("Please enter a valid Home Assistant webhook URL (http or https).")
+	}
+	return trimmed, nil
+}
+
+// homeAssistantConfig returns a configuration.yaml snippet with REST sensors for the profile and an
+// automation that announces ready items sent to the webhook.
+func homeAssistantConfig(profileName, sensorURL, currency string) string {
+	// Entity ids only allow lowercase letters, digits and underscores.
+	id := "impulse_pause_" + strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, strings.ToLower(profileName))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "rest:\n")
+	fmt.Fprintf(&b, "  - resource: %q\n", sensorURL)
+	fmt.Fprintf(&b, "    scan_interval: 300\n")
+	fmt.Fprintf(&b, "    sensor:\n")
+	fmt.Fprintf(&b, "      - name: \"Impulse Pause %s ready items\"\n", profileName)
+	fmt.Fprintf(&b, "        unique_id: %s_ready\n", id)
+	fmt.Fprintf(&b, "        value_template: \"{{ value_json.ready }}\"\n")
+	fmt.Fprintf(&b, "        json_attributes: [ready_items, next_unlock]\n")
+	fmt.Fprintf(&b, "      - name: \"Impulse Pause %s waiting items\"\n", profileName)
+	fmt.Fprintf(&b, "        unique_id: %s_waiting\n", id)
+	fmt.Fprintf(&b, "        value_template: \"{{ value_json.waiting }}\"\n")
+	fmt.Fprintf(&b, "      - name: \"Impulse Pause %s saved this month\"\n", profileName)
+	fmt.Fprintf(&b, "        unique_id: %s_saved_month\n", id)
+	fmt.Fprintf(&b, "        value_template: \"{{ value_json.saved_month }}\"\n")
+	fmt.Fprintf(&b, "        device_class: monetary\n")
+	fmt.Fprintf(&b, "        unit_of_measurement: %s\n", normalizeCurrency(currency))
+	fmt.Fprintf(&b, "\nautomation:\n")
+	fmt.Fprintf(&b, "  - alias: \"Announce Impulse Pause items that are ready to buy\"\n")
+	fmt.Fprintf(&b, "    trigger:\n")
+	fmt.Fprintf(&b, "      - platform: webhook\n")
+	fmt.Fprintf(&b, "        webhook_id: %s\n", homeAssistantWebhookID)
+	fmt.Fprintf(&b, "        allowed_methods: [POST]\n")
+	fmt.Fprintf(&b, "    action:\n")
+	fmt.Fprintf(&b, "      - service: notify.notify\n")
+	fmt.Fprintf(&b, "        data:\n")
+	fmt.Fprintf(&b, "          message: \"{{ trigger.json.message }}\"\n")
+	return b.String()
+}
+
+func (a *App) homeAssistantSettings(w http.ResponseWriter, r *http.Request) {
+	feedback := ""
+	if r.URL.Query().Get("saved") == "1" {
+		feedback = "Home Assistant settings saved."
+	}
+	a.renderHomeAssistantSettings(w, homeAssistantViewData{Feedback: feedback})
+}
+
+func (a *App) saveHomeAssistantSettings(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	webhookURL, err := parseHomeAssistantWebhookURL(r.FormValue("ha_webhook_url"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		a.renderHomeAssistantSettings(w, homeAssistantViewData{WebhookURL: r.FormValue("ha_webhook_url"), Error: err.Error()})
+		return
+	}
+
+	a.mu.LockContext(r.Context())
+	a.haWebhookURL = webhookURL
+	if err := a.persistProfileLocked(); err != nil {
+		a.mu.Unlock()
+		log.Printf("db error while saving home assistant settings: %v", err)
+		http.Error(w, "could not save home assistant settings", http.StatusInternalServerError)
+		return
+	}
+	a.publishProfileUpdatedLocked("home assistant", r)
+	a.mu.Unlock()
+
+	http.Redirect(w, r, "/settings/home-assistant?saved=1", http.StatusSeeOther)
+}
+
+func (a *App) renderHomeAssistantSettings(w http.ResponseWriter, data homeAssistantViewData) {
+	a.mu.RLock()
+	if data.WebhookURL == "" {
+		data.WebhookURL = a.haWebhookURL
+	}
+	if a.shareToken != "" {
+		data.SensorURL = a.dashboardLink() + "api/v1/home-assistant?token=" + url.QueryEscape(a.shareToken)
+		data.Config = homeAssistantConfig(a.currentUserIDLocked(), data.SensorURL, a.currency)
+	}
+	data.ActiveProfile = a.currentUserIDLocked()
+	a.mu.RUnlock()
+
+	data.Title = "Home Assistant"
+	data.CurrentPath = "/settings/home-assistant"
+	data.ContentTemplate = "home_assistant_content"
+	renderTemplate(w, a.templates, "layout", data)
+}
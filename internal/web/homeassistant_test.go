@@ -0,0 +1,95 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHomeAssistantWebhookReceivesReadyItemOnce(t *testing.T) {
+	app := NewApp()
+	seedProfile(app)
+	var events []homeAssistantEvent
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event homeAssistantEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Fatalf("decode webhook body: %v", err)
+		}
+		events = append(events, event)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer webhook.Close()
+
+	app.mu.Lock()
+	app.haWebhookURL = webhook.URL + "/api/webhook/impulse_pause"
+	app.items = append(app.items, Item{ID: 9, Title: "headphones", Status: "Waiting", PriceCents: 12999, HasPriceValue: true, PurchaseAllowedAt: time.Now().Add(-time.Minute)})
+	app.mu.Unlock()
+
+	for range 2 {
+		rr := httptest.NewRecorder()
+		app.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", rr.Code)
+		}
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("expected exactly one webhook event, got %d", len(events))
+	}
+	if got := events[0]; got.Event != "item_ready" || got.ItemID != 9 || got.Price != 129.99 || got.Message != "headphones is ready to buy." {
+		t.Fatalf("unexpected webhook event %+v", got)
+	}
+}
+
+func TestHomeAssistantStateSummarizesTheWaitlist(t *testing.T) {
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	items := []Item{
+		{ID: 1, Title: "Headphones", Status: "Waiting", PurchaseAllowedAt: now.Add(-time.Hour), PriceCents: 12999, HasPriceValue: true},
+		{ID: 2, Title: "Bike", Status: "Waiting", PurchaseAllowedAt: now.Add(48 * time.Hour)},
+		{ID: 3, Title: "Lamp", Status: "Waiting", PurchaseAllowedAt: now.Add(3 * time.Hour)},
+		{ID: 4, Title: "Watch", Status: "Skipped", PriceCents: 25000, HasPriceValue: true, DecidedAt: now.Add(-24 * time.Hour)},
+	}
+
+	state := buildHomeAssistantState("Alex", "EUR", items, now)
+
+	if state.Waiting != 2 || state.Ready != 1 || state.SavedMonth != 250 || state.Currency != "EUR" {
+		t.Fatalf("unexpected summary %+v", state)
+	}
+	if len(state.ReadyItems) != 1 || state.ReadyItems[0].Title != "Headphones" || state.ReadyItems[0].Price != 129.99 {
+		t.Fatalf("expected headphones to be listed as ready, got %+v", state.ReadyItems)
+	}
+	if state.NextUnlock == nil || !state.NextUnlock.Equal(now.Add(3*time.Hour)) {
+		t.Fatalf("expected the lamp to unlock next, got %v", state.NextUnlock)
+	}
+}
+
+func TestHomeAssistantStateRequiresShareToken(t *testing.T) {
+	app := NewApp()
+	seedProfile(app)
+
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/home-assistant?token=nope", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown token, got %d", rr.Code)
+	}
+
+	app.mu.Lock()
+	app.shareToken = "abc123"
+	app.mu.Unlock()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/home-assistant", nil)
+	req.Header.Set("Authorization", "Bearer abc123")
+	rr = httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"ready_items":[]`) {
+		t.Fatalf("expected sensor state for the share token, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	settings := httptest.NewRecorder()
+	app.Handler().ServeHTTP(settings, httptest.NewRequest(http.MethodGet, "/settings/home-assistant", nil))
+	if !strings.Contains(settings.Body.String(), "api/v1/home-assistant?token=abc123") || !strings.Contains(settings.Body.String(), "webhook_id: impulse_pause") {
+		t.Fatalf("expected configuration snippet with the sensor URL, got %s", settings.Body.String())
+	}
+}
@@ -0,0 +1,177 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+const maxHoursGoal = 10000
+
+// hoursGoalMilestones are the percentages of the yearly goal that are celebrated with a notification.
+var hoursGoalMilestones = []int{25, 50, 75, 100}
+
+// hoursGoalProgress counts the work-hours not spent this year against the profile's goal.
+type hoursGoalProgress struct {
+	Year      int
+	GoalHours int
+	// Reclaimed is the price of the items skipped this year in hours of the profile's wage, and Hours
+	// the same rounded for display.
+	Reclaimed float64
+	Hours     string
+	Percent   int
+	// Milestone is the highest milestone reached, 0 before the first; Next is the one after it, 0 once
+	// the goal is reached.
+	Milestone int
+	Next      int
+}
+
+// BarPercent caps Percent for the progress bar once the goal is exceeded.
+func (p hoursGoalProgress) BarPercent() int {
+	return min(p.Percent, 100)
+}
+
+// buildHoursGoalProgress sums the skipped items decided in now's year, counted in loc. It returns nil
+// without a goal or a usable wage.
+func buildHoursGoalProgress(carried []Item, hourlyWage float64, goalHours int, now time.Time, loc *time.Location) *hoursGoalProgress {
+	if goalHours <= 0 || hourlyWage <= 0 {
+		return nil
+	}
+
+	progress := &hoursGoalProgress{Year: now.In(loc).Year(), GoalHours: goalHours}
+	var saved domain.Money
+	for _, item := range carried {
+		if item.Status != domain.StatusSkipped || itemDecisionTime(item).In(loc).Year() != progress.Year {
+			continue
+		}
+		if price, ok := itemPrice(item); ok {
+			saved += price
+		}
+	}
+	progress.Reclaimed = saved.Float() / hourlyWage
+	progress.Percent = int(progress.Reclaimed * 100 / float64(goalHours))
+	for _, milestone := range hoursGoalMilestones {
+		if progress.Percent >= milestone {
+			progress.Milestone = milestone
+		} else if progress.Next == 0 {
+			progress.Next = milestone
+		}
+	}
+	return progress
+}
+
+func parseHoursGoal(raw string) (int, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, nil
+	}
+	goal, err := strconv.Atoi(raw)
+	if err != nil || goal < 1 || goal > maxHoursGoal {
+		return 0, errors.New("Please enter a goal between 1 and 10000 hours, or leave it empty to turn it off.")
+	}
+	return goal, nil
+}
+
+// hoursGoalMarker records the year and the last milestone that was celebrated, like "2026:50".
+func hoursGoalMarker(progress *hoursGoalProgress) string {
+	if progress == nil || progress.Milestone == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d:%d", progress.Year, progress.Milestone)
+}
+
+// hoursGoalCelebrated reports whether the marker already covers the progress' milestone.
+func hoursGoalCelebrated(marker string, progress *hoursGoalProgress) bool {
+	yearRaw, milestoneRaw, ok := strings.Cut(marker, ":")
+	if !ok {
+		return false
+	}
+	year, err := strconv.Atoi(yearRaw)
+	if err != nil || year != progress.Year {
+		return false
+	}
+	milestone, err := strconv.Atoi(milestoneRaw)
+	return err == nil && milestone >= progress.Milestone
+}
+
+func (a *App) hoursGoalProgressLocked(now time.Time) *hoursGoalProgress {
+	wage, err := domain.ParseHourlyWage(a.hourlyWage)
+	if err != nil {
+		return nil
+	}
+	loc := a.trendPeriodsLocked(trendGranularityMonth).location()
+	progress := buildHoursGoalProgress(itemsCarriedBy(a.items, a.currentUserIDLocked()), wage, a.hoursGoal, now, loc)
+	if progress != nil {
+		progress.Hours = a.workEffortFramingLocked().format(progress.Reclaimed)
+	}
+	return progress
+}
+
+// celebrateHoursGoalLocked sends an ntfy message the first time a milestone of the yearly goal is
+// reached. Each milestone is celebrated once a year, even if an undone skip drops below it again.
+func (a *App) celebrateHoursGoalLocked(now time.Time) {
+	progress := a.hoursGoalProgressLocked(now)
+	if progress == nil || progress.Milestone == 0 || hoursGoalCelebrated(a.hoursGoalCelebrated, progress) {
+		return
+	}
+
+	a.hoursGoalCelebrated = hoursGoalMarker(progress)
+	if err := a.persistProfileLocked(); err != nil {
+		log.Printf("db error while saving the hours goal milestone: %v", err)
+	}
+	if strings.TrimSpace(a.ntfyURL) == "" || strings.TrimSpace(a.ntfyTopic) == "" {
+		log.Printf("ntfy skipped for the %d%% hours goal milestone: endpoint/topic not configured", progress.Milestone)
+		return
+	}
+
+	message := fmt.Sprintf("You reclaimed %s of your %d work-hours for %d, %d%% of the way.", progress.Hours, progress.GoalHours, progress.Year, progress.Milestone)
+	if progress.Next == 0 {
+		message = fmt.Sprintf("Goal reached! You reclaimed %s work-hours in %d by skipping what you did not need.", progress.Hours, progress.Year)
+	}
+	message += "\nInsights: " + a.dashboardLink() + "insights"
+	if a.notificationDryRunLocked(effectNtfy, a.ntfyURL+"/"+a.ntfyTopic, message) {
+		a.recordDeliveryLocked(notificationMilestone, effectNtfy, Item{}, 0, nil)
+		return
+	}
+	code, err := a.postNtfyMessage(a.mu.Context(), a.ntfyURL, a.ntfyTopic, "Impulse Pause milestone", message)
+	if err != nil {
+		log.Printf("ntfy request failed for the hours goal milestone: %v", err)
+	}
+	a.recordDeliveryLocked(notificationMilestone, effectNtfy, Item{}, code, err)
+}
+
+func (a *App) saveHoursGoal(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	raw := strings.TrimSpace(r.FormValue("hours_goal"))
+	goal, err := parseHoursGoal(raw)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		a.renderInsights(w, insightsViewData{Title: "Insights", CurrentPath: "/insights", HoursGoalInput: raw, Error: err.Error()})
+		return
+	}
+
+	a.mu.LockContext(r.Context())
+	a.hoursGoal = goal
+	// Milestones already passed under the new goal are not announced after the fact.
+	a.hoursGoalCelebrated = hoursGoalMarker(a.hoursGoalProgressLocked(time.Now()))
+	if err := a.persistProfileLocked(); err != nil {
+		a.mu.Unlock()
+		log.Printf("db error while saving the hours goal: %v", err)
+		http.Error(w, "could not save the hours goal", http.StatusInternalServerError)
+		return
+	}
+	a.publishProfileUpdatedLocked("hours goal", r)
+	a.mu.Unlock()
+
+	http.Redirect(w, r, "/insights?saved=goal", http.StatusSeeOther)
+}
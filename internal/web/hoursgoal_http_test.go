@@ -0,0 +1,58 @@
+package web_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestSkippingPastAMilestoneCelebratesOnce(t *testing.T) {
+	now := time.Now()
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}},
+		Items: []webtest.Item{
+			{Profile: "Alex", Title: "Drone", Price: 50000, Status: "Ready to buy", PurchaseAllowedAt: now.Add(-time.Hour)},
+			{Profile: "Alex", Title: "Watch", Price: 10000, Status: "Ready to buy", PurchaseAllowedAt: now.Add(-time.Hour)},
+		},
+	})
+	var messages []string
+	ntfy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		messages = append(messages, r.Header.Get("Title")+": "+string(body))
+	}))
+	defer ntfy.Close()
+	if _, err := h.DB.Exec(`UPDATE profiles SET ntfy_endpoint = ?, ntfy_topic = 'impulse-pause'`, ntfy.URL); err != nil {
+		t.Fatalf("set ntfy settings: %v", err)
+	}
+	alex := h.As("Alex")
+
+	alex.PostForm("/insights/goal", url.Values{"hours_goal": {"0"}}).ExpectStatus(http.StatusBadRequest).ExpectContains("between 1 and 10000 hours")
+	alex.PostForm("/insights/goal", url.Values{"hours_goal": {"40"}}).ExpectRedirect("/insights?saved=goal")
+
+	alex.PostForm("/items/status", url.Values{"item_id": {strconv.Itoa(h.Item("Alex", "Drone").ID)}, "status": {"Skipped"}})
+	if len(messages) != 1 || !strings.Contains(messages[0], "Impulse Pause milestone: You reclaimed 20.0 of your 40 work-hours") || !strings.Contains(messages[0], "50% of the way") {
+		t.Fatalf("expected the 50%% milestone to be announced, got %q", messages)
+	}
+	alex.PostForm("/items/status", url.Values{"item_id": {strconv.Itoa(h.Item("Alex", "Watch").ID)}, "status": {"Skipped"}})
+	if len(messages) != 1 {
+		t.Fatalf("expected no message without a new milestone, got %q", messages)
+	}
+
+	var goal int
+	var marker string
+	if err := h.DB.QueryRow(`SELECT hours_goal, hours_goal_celebrated FROM profiles WHERE user_id = 'Alex'`).Scan(&goal, &marker); err != nil {
+		t.Fatalf("load goal: %v", err)
+	}
+	if goal != 40 || marker != strconv.Itoa(now.Year())+":50" {
+		t.Fatalf("unexpected persisted goal %d and marker %q", goal, marker)
+	}
+
+	alex.Get("/insights").ExpectStatus(http.StatusOK).ExpectContains("24.0 h</span>", "of your 40 h goal", "notification at 75%")
+}
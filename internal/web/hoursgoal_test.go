@@ -0,0 +1,34 @@
+package web
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildHoursGoalProgressCountsThisYearsSkips(t *testing.T) {
+	now := time.Date(2026, time.June, 1, 12, 0, 0, 0, time.UTC)
+	items := []Item{
+		{ID: 1, Status: "Skipped", PriceCents: 50000, HasPriceValue: true, DecidedAt: time.Date(2026, time.March, 3, 9, 0, 0, 0, time.UTC)},
+		{ID: 2, Status: "Skipped", PriceCents: 25000, HasPriceValue: true, DecidedAt: time.Date(2026, time.May, 3, 9, 0, 0, 0, time.UTC)},
+		{ID: 3, Status: "Skipped", PriceCents: 90000, HasPriceValue: true, DecidedAt: time.Date(2025, time.December, 30, 9, 0, 0, 0, time.UTC)},
+		{ID: 4, Status: "Bought", PriceCents: 90000, HasPriceValue: true, DecidedAt: time.Date(2026, time.May, 3, 9, 0, 0, 0, time.UTC)},
+		{ID: 5, Status: "Skipped", DecidedAt: time.Date(2026, time.May, 3, 9, 0, 0, 0, time.UTC)},
+	}
+
+	got := buildHoursGoalProgress(items, 25, 50, now, time.UTC)
+	if got == nil || got.Year != 2026 || got.Reclaimed != 30 || got.Percent != 60 || got.Milestone != 50 || got.Next != 75 {
+		t.Fatalf("unexpected progress %+v", got)
+	}
+
+	got = buildHoursGoalProgress(items, 25, 20, now, time.UTC)
+	if got.Percent != 150 || got.BarPercent() != 100 || got.Milestone != 100 || got.Next != 0 {
+		t.Fatalf("expected an exceeded goal to be capped for the bar, got %+v", got)
+	}
+
+	if got := buildHoursGoalProgress(items, 25, 0, now, time.UTC); got != nil {
+		t.Fatalf("expected no progress without a goal, got %+v", got)
+	}
+	if _, err := parseHoursGoal("10001"); err == nil {
+		t.Fatal("expected a goal above 10000 hours to be rejected")
+	}
+}
@@ -0,0 +1,188 @@
+package web
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+type householdViewData struct {
+	Title           string
+	CurrentPath     string
+	ContentTemplate string
+	ScriptTemplate  string
+	Month           string
+	Members         []householdMember
+	TotalWaiting    int
+	TotalReady      int
+	TotalSaved      domain.Money
+	SharedCurrency  string
+	ActiveProfile   string
+	Database        *databaseSettings
+	// AdminQuery carries the admin token given in the URL on to the page's forms.
+	AdminQuery    string
+	Invites       []invite
+	InviteOnly    bool
+	InviteOptions []int
+	// ArchivedProfiles can be restored from the household page.
+	ArchivedProfiles []archivedProfile
+	Jobs             []backgroundJob
+	// NtfyEndpoints lists the ntfy endpoints that failed since they last worked, with their circuit state.
+	NtfyEndpoints []ntfyBreaker
+}
+
+// databaseSettings shows the SQLite tuning and pool usage, to tell whether "database is locked" errors
+// or slow pages come from waiting for connections.
+type databaseSettings struct {
+	JournalMode       string
+	ConfiguredJournal string
+	BusyTimeout       time.Duration
+	MaxOpenConns      int
+	MaxIdleConns      int
+	OpenConns         int
+	InUse             int
+	WaitCount         int64
+	WaitDuration      time.Duration
+	LastMaintenance   *maintenanceRun
+	// Quarantined counts the rows set aside at startup because they could not be loaded.
+	Quarantined int
+}
+
+type householdMember struct {
+	Name       string
+	Waiting    int
+	Ready      int
+	SavedMonth domain.Money
+	Currency   string
+}
+
+// buildHouseholdMembers aggregates open items and this month's savings per profile, sorted by name.
+func buildHouseholdMembers(itemsByProfile map[string][]Item, currencies map[string]string, now time.Time) []householdMember {
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	members := make([]householdMember, 0, len(itemsByProfile))
+	for name, items := range itemsByProfile {
+		member := householdMember{Name: name, Currency: profileCurrencyOrDefault(currencies[name])}
+		for _, item := range items {
+			switch effectiveStatus(item, now) {
+			case domain.StatusWaiting:
+				member.Waiting++
+			case domain.StatusReady:
+				member.Ready++
+			case domain.StatusSkipped:
+				if item.HasPriceValue && !itemDecisionTime(item).Before(monthStart) {
+					member.SavedMonth += item.PriceCents
+				}
+			}
+		}
+		members = append(members, member)
+	}
+
+	slices.SortFunc(members, func(a, b householdMember) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	return members
+}
+
+func (a *App) household(w http.ResponseWriter, r *http.Request) {
+	if !a.requireAdmin(w, r) {
+		return
+	}
+
+	a.mu.LockContext(r.Context())
+	a.promoteReadyItemsLocked(time.Now())
+	itemsByProfile, err := a.itemsByProfileLocked()
+	currencies := map[string]string{}
+	if err == nil {
+		for name, items := range itemsByProfile {
+			itemsByProfile[name] = maskPrivateItems(items)
+			if currencies[name], err = a.currencyForProfileLocked(name); err != nil {
+				break
+			}
+		}
+	}
+	lastMaintenance := a.lastMaintenance.Load()
+	var invites []invite
+	if err == nil && a.db != nil {
+		invites, err = a.openInvitesLocked(time.Now())
+	}
+	inviteOnly, dashboardURL := a.inviteOnly, a.dashboardURL
+	a.mu.Unlock()
+	var archived []archivedProfile
+	if err == nil {
+		archived, err = a.listArchivedProfiles()
+	}
+	if err != nil {
+		log.Printf("db error while loading household overview: %v", err)
+		http.Error(w, "could not load household overview", http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	data := householdViewData{
+		Title:            "Household",
+		CurrentPath:      "/household",
+		ContentTemplate:  "household_content",
+		Month:            now.Format("2006-01"),
+		Members:          buildHouseholdMembers(itemsByProfile, currencies, now),
+		ArchivedProfiles: archived,
+		Jobs:             a.jobStatuses(),
+		NtfyEndpoints:    a.ntfyBreakers.statuses(now),
+	}
+	for i, member := range data.Members {
+		data.TotalWaiting += member.Waiting
+		data.TotalReady += member.Ready
+		data.TotalSaved += member.SavedMonth
+		if i == 0 {
+			data.SharedCurrency = member.Currency
+		} else if data.SharedCurrency != member.Currency {
+			data.SharedCurrency = ""
+		}
+	}
+
+	if token := r.URL.Query().Get("token"); token != "" {
+		data.AdminQuery = "?" + url.Values{"token": {token}}.Encode()
+	}
+	if a.db != nil {
+		data.Database = a.databaseSettings()
+		data.Database.LastMaintenance = lastMaintenance
+		data.InviteOnly = inviteOnly
+		data.InviteOptions = inviteValidityOptions
+		for _, inv := range invites {
+			inv.Link = dashboardURL + "/invite/" + inv.Token
+			data.Invites = append(data.Invites, inv)
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	renderTemplate(w, a.templates, "layout", data)
+}
+
+// databaseSettings reads the journal mode in effect, which differs from the configured one when SQLite
+// cannot use WAL, for example on some network file systems.
+func (a *App) databaseSettings() *databaseSettings {
+	opts := a.sqliteOptions
+	stats := a.db.Stats()
+	settings := &databaseSettings{
+		ConfiguredJournal: opts.JournalMode,
+		BusyTimeout:       opts.BusyTimeout,
+		MaxOpenConns:      opts.MaxOpenConns,
+		MaxIdleConns:      opts.MaxIdleConns,
+		OpenConns:         stats.OpenConnections,
+		InUse:             stats.InUse,
+		WaitCount:         stats.WaitCount,
+		WaitDuration:      stats.WaitDuration.Round(time.Millisecond),
+	}
+	if err := a.db.QueryRow(`PRAGMA journal_mode`).Scan(&settings.JournalMode); err != nil {
+		log.Printf("db error while reading journal mode: %v", err)
+		settings.JournalMode = "unknown"
+	}
+	if err := a.db.QueryRow(`SELECT COUNT(*) FROM quarantined_rows`).Scan(&settings.Quarantined); err != nil {
+		log.Printf("db error while counting quarantined rows: %v", err)
+	}
+	return settings
+}
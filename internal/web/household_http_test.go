@@ -0,0 +1,26 @@
+package web_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestHouseholdAggregatesAllProfilesWithBearerToken(t *testing.T) {
+	now := time.Now()
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}, {Name: "Sam"}},
+		Items: []webtest.Item{
+			{Profile: "Alex", Title: "a1", PurchaseAllowedAt: now.Add(time.Hour)},
+			{Profile: "Alex", Title: "a2", PurchaseAllowedAt: now.Add(2 * time.Hour)},
+			{Profile: "Sam", Title: "s1", Status: "Skipped", Price: 2500, PurchaseAllowedAt: now, DecidedAt: now},
+		},
+	})
+	h.App.SetAdminToken("s3cret")
+
+	h.Anonymous().WithHeader("Authorization", "Bearer s3cret").Get("/household").
+		ExpectStatus(http.StatusOK).
+		ExpectContains("<td>Alex</td>", "<td>Sam</td>", "€ 25.00", "<td>2</td>")
+}
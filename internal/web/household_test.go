@@ -0,0 +1,145 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHouseholdIsHiddenWithoutAdminToken(t *testing.T) {
+	app := NewApp()
+
+	req := httptest.NewRequest(http.MethodGet, "/household", nil)
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 when no admin token is configured, got %d", rr.Code)
+	}
+}
+
+func TestHouseholdRejectsWrongAdminToken(t *testing.T) {
+	app := NewApp()
+	app.SetAdminToken("s3cret")
+
+	req := httptest.NewRequest(http.MethodGet, "/household?token=nope", nil)
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rr.Code)
+	}
+}
+
+func TestBuildHouseholdMembers(t *testing.T) {
+	now := time.Date(2026, 5, 20, 12, 0, 0, 0, time.UTC)
+	members := buildHouseholdMembers(map[string][]Item{
+		"zoe": {
+			{Status: "Waiting", PurchaseAllowedAt: now.Add(time.Hour)},
+			{Status: "Waiting", PurchaseAllowedAt: now.Add(-time.Hour)},
+			{Status: "Skipped", PriceCents: 4000, HasPriceValue: true, DecidedAt: now.AddDate(0, 0, -3)},
+			{Status: "Skipped", PriceCents: 9900, HasPriceValue: true, DecidedAt: now.AddDate(0, -1, 0)},
+		},
+		"Alex": {
+			{Status: "Ready to buy"},
+			{Status: "Bought", PriceCents: 1000, HasPriceValue: true, DecidedAt: now},
+		},
+	}, map[string]string{"Alex": "CHF"}, now)
+
+	if len(members) != 2 || members[0].Name != "Alex" || members[1].Name != "zoe" {
+		t.Fatalf("expected members sorted by name, got %+v", members)
+	}
+	if got := members[1]; got.Waiting != 1 || got.Ready != 1 || got.SavedMonth != 4000 || got.Currency != "€" {
+		t.Fatalf("unexpected stats for zoe: %+v", got)
+	}
+	if got := members[0]; got.Ready != 1 || got.SavedMonth != 0 || got.Currency != "CHF" {
+		t.Fatalf("unexpected stats for Alex: %+v", got)
+	}
+}
+
+func TestHouseholdShowsDatabaseSettings(t *testing.T) {
+	opts := SQLiteOptions{JournalMode: "wal", BusyTimeout: 2 * time.Second, MaxOpenConns: 3, MaxIdleConns: 1}
+	app, err := NewAppWithSQLiteOptions(filepath.Join(t.TempDir(), "test.sqlite"), opts)
+	if err != nil {
+		t.Fatalf("new sqlite app: %v", err)
+	}
+	defer app.Close()
+	app.SetAdminToken("s3cret")
+
+	var busyTimeout int
+	if err := app.db.QueryRow(`PRAGMA busy_timeout`).Scan(&busyTimeout); err != nil || busyTimeout != 2000 {
+		t.Fatalf("expected the busy timeout on pooled connections, got %d %v", busyTimeout, err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/household?token=s3cret", nil)
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+	body := rr.Body.String()
+	for _, want := range []string{"Journal mode</dt>\n      <dd class=\"col-sm-7\">wal</dd>", "2s", "at most 3 (1 kept idle)"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q on the household page, got:\n%s", want, body)
+		}
+	}
+}
+
+func TestSQLiteOptionsAreValidated(t *testing.T) {
+	for _, opts := range []SQLiteOptions{
+		{JournalMode: "memory", MaxOpenConns: 1},
+		{JournalMode: "wal", BusyTimeout: -time.Second, MaxOpenConns: 1},
+		{JournalMode: "wal", MaxOpenConns: 0},
+		{JournalMode: "wal", MaxOpenConns: 2, MaxIdleConns: 3},
+	} {
+		if _, err := NewAppWithSQLiteOptions(filepath.Join(t.TempDir(), "test.sqlite"), opts); err == nil {
+			t.Fatalf("expected %+v to be rejected", opts)
+		}
+	}
+}
+
+func TestMaintenancePurgesExpiredRowsAndReportsOnHousehold(t *testing.T) {
+	app, cleanup := newSQLiteTestApp(t)
+	defer cleanup()
+	app.SetAdminToken("s3cret")
+
+	now := time.Now()
+	seed := []struct {
+		query string
+		args  []any
+	}{
+		{`INSERT INTO api_idempotency_keys(user_id, idempotency_key, request_hash, status, body, created_at) VALUES ('Alex', 'old', '', 201, '', ?)`, []any{now.Add(-2 * idempotencyKeyTTL).Format(time.RFC3339Nano)}},
+		{`INSERT INTO api_idempotency_keys(user_id, idempotency_key, request_hash, status, body, created_at) VALUES ('Alex', 'fresh', '', 201, '', ?)`, []any{now.Format(time.RFC3339Nano)}},
+		{`INSERT INTO push_subscriptions(endpoint, user_id, p256dh, auth, expires_at, created_at) VALUES ('https://push.example/gone', 'Alex', '', '', ?, ?)`, []any{now.Add(-time.Hour).Format(time.RFC3339Nano), now.Format(time.RFC3339Nano)}},
+		{`INSERT INTO push_subscriptions(endpoint, user_id, p256dh, auth, expires_at, created_at) VALUES ('https://push.example/kept', 'Alex', '', '', '', ?)`, []any{now.Format(time.RFC3339Nano)}},
+		{`INSERT INTO item_changes(item_id, user_id) VALUES (7, 'Alex'), (7, 'Alex'), (7, 'Alex'), (7, 'Sam')`, nil},
+	}
+	for _, row := range seed {
+		if _, err := app.db.Exec(row.query, row.args...); err != nil {
+			t.Fatalf("seed %q: %v", row.query, err)
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/household/maintenance?token=s3cret", nil))
+	if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/household?token=s3cret" {
+		t.Fatalf("expected a redirect back to the household page, got %d %q", rr.Code, rr.Header().Get("Location"))
+	}
+
+	var keys, subs, changes int
+	_ = app.db.QueryRow(`SELECT COUNT(*) FROM api_idempotency_keys`).Scan(&keys)
+	_ = app.db.QueryRow(`SELECT COUNT(*) FROM push_subscriptions`).Scan(&subs)
+	_ = app.db.QueryRow(`SELECT COUNT(*) FROM item_changes`).Scan(&changes)
+	if keys != 1 || subs != 1 || changes != 2 {
+		t.Fatalf("expected only fresh rows and the latest changes to remain, got %d keys, %d subscriptions, %d changes", keys, subs, changes)
+	}
+
+	rr = httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/household?token=s3cret", nil))
+	body := rr.Body.String()
+	for _, want := range []string{"(run manually)", "2 expired rows purged, 2 change log entries compacted", `action="/household/maintenance?token=s3cret"`} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q on the household page, got:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package web
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+// inviteValidityOptions are the days an invite link can stay valid.
+var inviteValidityOptions = []int{1, 7, 30}
+
+// invite is a link the admin hands out so someone can create a profile while /switch-profile only
+// accepts existing names.
+type invite struct {
+	Token       string
+	ProfileName string
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+	UsedBy      string
+	UsedAt      time.Time
+	// Link is the full invite URL, filled in for the household page.
+	Link string
+}
+
+type inviteViewData struct {
+	Title           string
+	CurrentPath     string
+	ContentTemplate string
+	ScriptTemplate  string
+	Invite          invite
+	ProfileName     string
+	Error           string
+	ActiveProfile   string
+}
+
+// SetInviteOnly stops /switch-profile from creating profiles; new profiles then need an invite link
+// from the household page. Existing profiles can still be chosen.
+func (a *App) SetInviteOnly(inviteOnly bool) {
+	a.mu.Lock()
+	a.inviteOnly = inviteOnly
+	a.mu.Unlock()
+}
+
+func (a *App) createInviteLocked(profileName string, validFor time.Duration, now time.Time) (invite, error) {
+	token, err := newShareToken()
+	if err != nil {
+		return invite{}, err
+	}
+	created := invite{Token: token, ProfileName: profileName, CreatedAt: now, ExpiresAt: now.Add(validFor)}
+	_, err = a.db.Exec(`INSERT INTO invites(token, profile_name, created_at, expires_at) VALUES (?, ?, ?, ?)`,
+		created.Token, created.ProfileName, created.CreatedAt.Format(time.RFC3339Nano), created.ExpiresAt.Format(time.RFC3339Nano))
+	if err != nil {
+		return invite{}, fmt.Errorf("save invite: %w", err)
+	}
+	return created, nil
+}
+
+// openInvitesLocked lists the invites that can still be accepted, newest first.
+func (a *App) openInvitesLocked(now time.Time) ([]invite, error) {
+	rows, err := a.db.Query(`SELECT token, profile_name, created_at, expires_at FROM invites WHERE used_by = '' ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("list invites: %w", err)
+	}
+	defer rows.Close()
+
+	var invites []invite
+	for rows.Next() {
+		var inv invite
+		var createdAt, expiresAt string
+		if err := rows.Scan(&inv.Token, &inv.ProfileName, &createdAt, &expiresAt); err != nil {
+			return nil, fmt.Errorf("scan invite: %w", err)
+		}
+		inv.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+		inv.ExpiresAt, _ = time.Parse(time.RFC3339Nano, expiresAt)
+		if inv.ExpiresAt.After(now) {
+			invites = append(invites, inv)
+		}
+	}
+	return invites, rows.Err()
+}
+
+// openInviteLocked returns the invite for token if it is neither used nor expired.
+func (a *App) openInviteLocked(token string, now time.Time) (invite, bool, error) {
+	if a.db == nil || token == "" {
+		return invite{}, false, nil
+	}
+	var inv invite
+	var createdAt, expiresAt string
+	err := a.db.QueryRow(`SELECT token, profile_name, created_at, expires_at FROM invites WHERE token = ? AND used_by = ''`, token).
+		Scan(&inv.Token, &inv.ProfileName, &createdAt, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return invite{}, false, nil
+	}
+	if err != nil {
+		return invite{}, false, fmt.Errorf("load invite: %w", err)
+	}
+	inv.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+	inv.ExpiresAt, _ = time.Parse(time.RFC3339Nano, expiresAt)
+	return inv, inv.ExpiresAt.After(now), nil
+}
+
+func (a *App) markInviteUsedLocked(token, profileName string, now time.Time) error {
+	if _, err := a.db.Exec(`UPDATE invites SET used_by = ?, used_at = ? WHERE token = ?`, profileName, now.Format(time.RFC3339Nano), token); err != nil {
+		return fmt.Errorf("mark invite used: %w", err)
+	}
+	return nil
+}
+
+func (a *App) deleteInviteLocked(token string) error {
+	if _, err := a.db.Exec(`DELETE FROM invites WHERE token = ? AND used_by = ''`, token); err != nil {
+		return fmt.Errorf("delete invite: %w", err)
+	}
+	return nil
+}
+
+// purgeStaleInvitesLocked deletes invites that were accepted or expired more than a month ago. They are
+// kept that long so the admin can still see why a link stopped working.
+func (a *App) purgeStaleInvitesLocked(now time.Time) (int64, error) {
+	cutoff := now.AddDate(0, -1, 0)
+	rows, err := a.db.Query(`SELECT token, expires_at, used_at FROM invites`)
+	if err != nil {
+		return 0, fmt.Errorf("list invites: %w", err)
+	}
+	var stale []string
+	for rows.Next() {
+		var token, expiresAt, usedAt string
+		if err := rows.Scan(&token, &expiresAt, &usedAt); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scan invite: %w", err)
+		}
+		end := expiresAt
+		if usedAt != "" {
+			end = usedAt
+		}
+		if at, err := time.Parse(time.RFC3339Nano, end); err == nil && at.Before(cutoff) {
+			stale = append(stale, token)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("list invites: %w", err)
+	}
+
+	for _, token := range stale {
+		if _, err := a.db.Exec(`DELETE FROM invites WHERE token = ?`, token); err != nil {
+			return 0, fmt.Errorf("delete invite: %w", err)
+		}
+	}
+	return int64(len(stale)), nil
+}
+
+// householdRedirect returns to the household page, keeping an admin token given in the URL.
+func householdRedirect(w http.ResponseWriter, r *http.Request) {
+	target := "/household"
+	if token := r.URL.Query().Get("token"); token != "" {
+		target += "?" + url.Values{"token": {token}}.Encode()
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
+
+// createInvite adds an invite link on the household page, optionally for a fixed profile name.
+func (a *App) createInvite(w http.ResponseWriter, r *http.Request) {
+	if !a.requireAdmin(w, r) {
+		return
+	}
+	if a.db == nil {
+		http.Error(w, "invites need a database", http.StatusConflict)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+	days, err := strconv.Atoi(strings.TrimSpace(r.FormValue("valid_days")))
+	if err != nil || !slices.Contains(inviteValidityOptions, days) {
+		http.Error(w, "invalid validity", http.StatusBadRequest)
+		return
+	}
+	profileName := ""
+	if raw := strings.TrimSpace(r.FormValue("profile_name")); raw != "" {
+		if profileName, err = domain.ParseProfileName(raw); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	a.mu.LockContext(r.Context())
+	_, err = a.createInviteLocked(profileName, time.Duration(days)*24*time.Hour, time.Now())
+	a.mu.Unlock()
+	if err != nil {
+		log.Printf("db error while creating invite: %v", err)
+		http.Error(w, "could not create invite", http.StatusInternalServerError)
+		return
+	}
+	householdRedirect(w, r)
+}
+
+func (a *App) revokeInvite(w http.ResponseWriter, r *http.Request) {
+	if !a.requireAdmin(w, r) {
+		return
+	}
+	if a.db == nil {
+		http.Error(w, "invites need a database", http.StatusConflict)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	a.mu.LockContext(r.Context())
+	err := a.deleteInviteLocked(strings.TrimSpace(r.FormValue("invite")))
+	a.mu.Unlock()
+	if err != nil {
+		log.Printf("db error while revoking invite: %v", err)
+		http.Error(w, "could not revoke invite", http.StatusInternalServerError)
+		return
+	}
+	householdRedirect(w, r)
+}
+
+// showInvite lets the invitee pick a profile name, unless the admin fixed one.
+func (a *App) showInvite(w http.ResponseWriter, r *http.Request) {
+	a.mu.RLock()
+	inv, ok, err := a.openInviteLocked(r.PathValue("token"), time.Now())
+	a.mu.RUnlock()
+	if err != nil {
+		log.Printf("db error while loading invite: %v", err)
+		http.Error(w, "could not load invite", http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		http.Error(w, "This invite link is invalid, was already used or has expired.", http.StatusNotFound)
+		return
+	}
+	a.renderInvite(w, inviteViewData{Invite: inv, ProfileName: inv.ProfileName})
+}
+
+// acceptInvite creates the profile and uses up the invite. Names of existing profiles are refused, so
+// an invite cannot be used to enter someone else's profile.
+func (a *App) acceptInvite(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+	names, err := a.listProfileNames()
+	if err != nil {
+		http.Error(w, "could not load profiles", http.StatusInternalServerError)
+		return
+	}
+
+	a.mu.LockContext(r.Context())
+	now := time.Now()
+	inv, ok, err := a.openInviteLocked(r.PathValue("token"), now)
+	if err != nil || !ok {
+		a.mu.Unlock()
+		if err != nil {
+			log.Printf("db error while loading invite: %v", err)
+			http.Error(w, "could not load invite", http.StatusInternalServerError)
+			return
+		}
+		http.Error(w, "This invite link is invalid, was already used or has expired.", http.StatusNotFound)
+		return
+	}
+
+	requested := r.FormValue("profile_name")
+	if inv.ProfileName != "" {
+		requested = inv.ProfileName
+	}
+	name, err := domain.ParseProfileName(requested)
+	if err == nil && slices.Contains(names, name) {
+		err = errors.New("A profile with this name already exists. Please choose another name.")
+	}
+	if err != nil {
+		a.mu.Unlock()
+		w.WriteHeader(http.StatusBadRequest)
+		a.renderInvite(w, inviteViewData{Invite: inv, ProfileName: strings.TrimSpace(requested), Error: err.Error()})
+		return
+	}
+
+	isNewProfile, err := a.enterProfileLocked(name, "via invite", r)
+	if err == nil {
+		err = a.markInviteUsedLocked(inv.Token, name, now)
+	}
+	a.mu.Unlock()
+	if err != nil {
+		log.Printf("db error while accepting invite: %v", err)
+		http.Error(w, "could not create profile", http.StatusInternalServerError)
+		return
+	}
+	redirectIntoProfile(w, r, name, isNewProfile)
+}
+
+func (a *App) renderInvite(w http.ResponseWriter, data inviteViewData) {
+	data.Title = "Accept invite"
+	data.CurrentPath = "/invite/{token}"
+	data.ContentTemplate = "invite_content"
+	data.ActiveProfile = a.activeProfileName()
+	w.Header().Set("Cache-Control", "no-store")
+	renderTemplate(w, a.templates, "layout", data)
+}
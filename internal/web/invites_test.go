@@ -0,0 +1,89 @@
+package web_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"mvpapp/internal/web/webtest"
+)
+
+// openInvite returns the token of the newest unused invite.
+func openInvite(t *testing.T, h *webtest.Harness) string {
+	t.Helper()
+	var token string
+	if err := h.DB.QueryRow(`SELECT token FROM invites WHERE used_by = '' ORDER BY created_at DESC LIMIT 1`).Scan(&token); err != nil {
+		t.Fatalf("load invite: %v", err)
+	}
+	return token
+}
+
+func TestInviteOnlyProfilesNeedAnInviteLink(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{Profiles: []webtest.Profile{{Name: "Alex"}}})
+	h.App.SetAdminToken("s3cret")
+	h.App.SetInviteOnly(true)
+	visitor := h.Anonymous()
+
+	visitor.Get("/switch-profile").ExpectStatus(http.StatusOK).
+		ExpectContains("New profiles need an invite link").
+		ExpectNotContains(">Create</button>")
+	visitor.PostForm("/switch-profile", url.Values{"profile_name": {"Sam"}}).
+		ExpectStatus(http.StatusForbidden).
+		ExpectContains("New profiles need an invite link")
+	visitor.PostForm("/switch-profile", url.Values{"profile_name": {"Alex"}}).ExpectRedirect("/")
+
+	admin := h.Anonymous()
+	admin.PostForm("/household/invites?token=s3cret", url.Values{"valid_days": {"7"}}).ExpectRedirect("/household?token=s3cret")
+	token := openInvite(t, h)
+	admin.Get("/household?token=s3cret").ExpectStatus(http.StatusOK).ExpectContains("/invite/" + token)
+
+	visitor.Get("/invite/"+token).ExpectStatus(http.StatusOK).ExpectContains("You're invited", `name="profile_name"`)
+	visitor.PostForm("/invite/"+token, url.Values{"profile_name": {"Alex"}}).
+		ExpectStatus(http.StatusBadRequest).
+		ExpectContains("A profile with this name already exists.")
+	visitor.PostForm("/invite/"+token, url.Values{"profile_name": {"Sam"}}).ExpectRedirect("/onboarding")
+	visitor.PostForm("/invite/"+token, url.Values{"profile_name": {"Kim"}}).ExpectStatus(http.StatusNotFound)
+	visitor.Get("/invite/" + token).ExpectStatus(http.StatusNotFound)
+
+	admin.PostForm("/household/invites?token=s3cret", url.Values{"valid_days": {"1"}, "profile_name": {"Kim"}}).ExpectStatus(http.StatusSeeOther)
+	fixed := openInvite(t, h)
+	visitor.PostForm("/invite/"+fixed, url.Values{"profile_name": {"Someone else"}}).ExpectRedirect("/onboarding")
+
+	var sam, kim int
+	_ = h.DB.QueryRow(`SELECT COUNT(*) FROM profiles WHERE user_id = 'Sam'`).Scan(&sam)
+	_ = h.DB.QueryRow(`SELECT COUNT(*) FROM profiles WHERE user_id = 'Kim'`).Scan(&kim)
+	if sam != 1 || kim != 1 {
+		t.Fatalf("expected Sam and Kim to be created through their invites, got %d and %d", sam, kim)
+	}
+}
+
+func TestRevokedInvitesStopWorking(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{Profiles: []webtest.Profile{{Name: "Alex"}}})
+	h.App.SetAdminToken("s3cret")
+	admin := h.Anonymous().WithHeader("Authorization", "Bearer s3cret")
+
+	admin.PostForm("/household/invites", url.Values{"valid_days": {"30"}}).ExpectRedirect("/household")
+	token := openInvite(t, h)
+	admin.PostForm("/household/invites/revoke", url.Values{"invite": {token}}).ExpectRedirect("/household")
+	h.Anonymous().Get("/invite/" + token).ExpectStatus(http.StatusNotFound)
+
+	h.Anonymous().PostForm("/household/invites", url.Values{"valid_days": {"30"}}).ExpectStatus(http.StatusUnauthorized)
+}
+
+func TestInviteOnlyRejectsCookiesForUnknownProfiles(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{Profiles: []webtest.Profile{{Name: "Alex"}}})
+	h.App.SetInviteOnly(true)
+
+	mallory := h.As("Mallory")
+	mallory.PostForm("/items/new", url.Values{"title": {"Drone"}, "wait_preset": {"24h"}}).ExpectRedirect("/switch-profile")
+	h.As("Mallory").Get("/settings/profile").ExpectRedirect("/switch-profile")
+
+	var profiles int
+	if err := h.DB.QueryRow(`SELECT COUNT(*) FROM profiles WHERE user_id = 'Mallory'`).Scan(&profiles); err != nil {
+		t.Fatalf("count profiles: %v", err)
+	}
+	if profiles != 0 || len(h.Items("Mallory")) != 0 {
+		t.Fatalf("expected no profile or items for the forged cookie, got %d profiles and %v", profiles, h.Items("Mallory"))
+	}
+	h.As("Alex").Get("/").ExpectStatus(http.StatusOK)
+}
@@ -0,0 +1,268 @@
+package web
+
+import (
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+// historyEntry records who did what to an item, so decisions on shared items stay attributable.
+type historyEntry struct {
+	ItemID    int
+	Actor     string
+	Action    string
+	Detail    string
+	CreatedAt time.Time
+}
+
+// isOwnedByLocked reports whether the active profile owns the item. Items without an owner predate sharing.
+func (a *App) isOwnedByLocked(item Item) bool {
+	return item.OwnerID == "" || item.OwnerID == a.currentUserIDLocked()
+}
+
+// recordHistoryLocked stores a history entry. Failures are logged because history must not block the action itself.
+func (a *App) recordHistoryLocked(itemID int, action, detail string) {
+	if err := a.insertHistoryLocked(itemID, action, detail, time.Now()); err != nil {
+		log.Printf("db error while recording item history: %v", err)
+	}
+}
+
+func (a *App) shareItem(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	id, err := strconv.Atoi(strings.TrimSpace(r.FormValue("item_id")))
+	if err != nil || id <= 0 {
+		http.Error(w, "invalid item id", http.StatusBadRequest)
+		return
+	}
+
+	action := strings.TrimSpace(r.FormValue("action"))
+	if action != "add" && action != "remove" {
+		http.Error(w, "invalid action", http.StatusBadRequest)
+		return
+	}
+
+	target, err := domain.ParseProfileName(r.FormValue("profile_name"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	profiles, err := a.listProfileNames()
+	if err != nil {
+		log.Printf("db error while listing profiles for sharing: %v", err)
+		http.Error(w, "could not share item", http.StatusInternalServerError)
+		return
+	}
+
+	a.mu.LockContext(r.Context())
+	defer a.mu.Unlock()
+
+	if a.db == nil {
+		http.Error(w, "sharing requires persistent storage", http.StatusConflict)
+		return
+	}
+
+	i := a.itemIndexLocked(id)
+	if i < 0 {
+		http.NotFound(w, r)
+		return
+	}
+	if !a.isOwnedByLocked(a.items[i]) {
+		http.Error(w, "only the owner can change sharing", http.StatusForbidden)
+		return
+	}
+	if target == a.currentUserIDLocked() {
+		http.Error(w, "items cannot be shared with their owner", http.StatusBadRequest)
+		return
+	}
+
+	switch action {
+	case "add":
+		if !slices.Contains(profiles, target) {
+			http.Error(w, "unknown profile", http.StatusBadRequest)
+			return
+		}
+		if err := a.addItemShareLocked(id, target); err != nil {
+			log.Printf("db error while sharing item: %v", err)
+			http.Error(w, "could not share item", http.StatusInternalServerError)
+			return
+		}
+		if !slices.Contains(a.items[i].SharedWith, target) {
+			a.items[i].SharedWith = append(a.items[i].SharedWith, target)
+		}
+		a.recordHistoryLocked(id, "shared", target)
+	case "remove":
+		if err := a.removeItemShareLocked(id, target); err != nil {
+			log.Printf("db error while unsharing item: %v", err)
+			http.Error(w, "could not unshare item", http.StatusInternalServerError)
+			return
+		}
+		a.items[i].SharedWith = slices.DeleteFunc(a.items[i].SharedWith, func(name string) bool { return name == target })
+		a.recordHistoryLocked(id, "unshared", target)
+	}
+
+	http.Redirect(w, r, itemEditPath(id), http.StatusSeeOther)
+}
+
+// splitItem sets how much of a shared item's price each profile carries. The owner carries the rest.
+func (a *App) splitItem(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	id, err := strconv.Atoi(strings.TrimSpace(r.FormValue("item_id")))
+	if err != nil || id <= 0 {
+		http.Error(w, "invalid item id", http.StatusBadRequest)
+		return
+	}
+
+	splits, err := domain.ParseSplitPercents(r.Form["profile_name"], r.Form["split_percent"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	a.mu.LockContext(r.Context())
+	defer a.mu.Unlock()
+
+	if a.db == nil {
+		http.Error(w, "sharing requires persistent storage", http.StatusConflict)
+		return
+	}
+
+	i := a.itemIndexLocked(id)
+	if i < 0 {
+		http.NotFound(w, r)
+		return
+	}
+	if !a.isOwnedByLocked(a.items[i]) {
+		http.Error(w, "only the owner can change sharing", http.StatusForbidden)
+		return
+	}
+	for profile := range splits {
+		if !slices.Contains(a.items[i].SharedWith, profile) {
+			http.Error(w, "item is not shared with "+profile, http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := a.setItemSplitLocked(id, splits); err != nil {
+		log.Printf("db error while splitting item: %v", err)
+		http.Error(w, "could not split item", http.StatusInternalServerError)
+		return
+	}
+	a.items[i].SplitPercents = nil
+	for profile, percent := range splits {
+		if percent > 0 {
+			if a.items[i].SplitPercents == nil {
+				a.items[i].SplitPercents = map[string]int{}
+			}
+			a.items[i].SplitPercents[profile] = percent
+		}
+	}
+	a.recordHistoryLocked(id, "split", formatSplit(a.items[i], a.currentUserIDLocked()))
+
+	http.Redirect(w, r, itemEditPath(id), http.StatusSeeOther)
+}
+
+// splitShare is one profile's part of a split item.
+type splitShare struct {
+	Profile string
+	Percent int
+	// WorkHours is empty when the profile has no valid hourly wage.
+	WorkHours string
+}
+
+// splitShares lists the owner and every profile the item is shared with together with their part of
+// the price, expressed in each profile's own work hours and rounded as the viewer's framing asks. It
+// returns nil for items without a split.
+func splitShares(item Item, owner string, hourlyWages map[string]float64, framing workEffortFraming) []splitShare {
+	if !item.HasSplit() {
+		return nil
+	}
+	if item.OwnerID != "" {
+		owner = item.OwnerID
+	}
+	shares := make([]splitShare, 0, len(item.SharedWith)+1)
+	for _, profile := range append([]string{owner}, item.SharedWith...) {
+		share := splitShare{Profile: profile, Percent: item.SharePercent(profile)}
+		if wage, ok := hourlyWages[profile]; ok {
+			part := item
+			part.PriceCents = domain.MoneyFromFloat(item.PriceCents.Float() * float64(share.Percent) / 100)
+			part.Price = part.PriceCents.String()
+			share.WorkHours = formatWorkHours(part, wage, framing)
+		}
+		shares = append(shares, share)
+	}
+	return shares
+}
+
+// splitWagesLocked loads the hourly wages of everyone involved in a split item. Errors are logged
+// because the split is shown without work hours rather than failing the page.
+func (a *App) splitWagesLocked(items []Item) map[string]float64 {
+	var profiles []string
+	for _, item := range items {
+		if !item.HasSplit() {
+			continue
+		}
+		for _, profile := range append([]string{item.OwnerID}, item.SharedWith...) {
+			if profile != "" && !slices.Contains(profiles, profile) {
+				profiles = append(profiles, profile)
+			}
+		}
+	}
+	if len(profiles) == 0 {
+		return nil
+	}
+	wages, err := a.hourlyWagesLocked(profiles)
+	if err != nil {
+		log.Printf("db error while loading wages for split items: %v", err)
+	}
+	return wages
+}
+
+// itemsCarriedBy returns the items with the price of split items reduced to the part the profile carries,
+// so insights count shared purchases proportionally.
+func itemsCarriedBy(items []Item, profile string) []Item {
+	carried := make([]Item, len(items))
+	for i, item := range items {
+		if item.HasSplit() {
+			item.PriceCents = item.PriceCents * domain.Money(item.SharePercent(profile)) / 100
+		}
+		carried[i] = item
+	}
+	return carried
+}
+
+func formatSplit(item Item, owner string) string {
+	parts := make([]string, 0, len(item.SharedWith)+1)
+	for _, share := range splitShares(item, owner, nil, defaultWorkEffortFraming) {
+		parts = append(parts, share.Profile+" "+strconv.Itoa(share.Percent)+"%")
+	}
+	if len(parts) == 0 {
+		return "removed"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// shareCandidates lists the profiles an item can still be shared with.
+func shareCandidates(profiles []string, owner string, sharedWith []string) []string {
+	candidates := make([]string, 0, len(profiles))
+	for _, name := range profiles {
+		if name == owner || slices.Contains(sharedWith, name) {
+			continue
+		}
+		candidates = append(candidates, name)
+	}
+	return candidates
+}
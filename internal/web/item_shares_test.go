@@ -0,0 +1,189 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// visitDashboard loads profile's dashboard.
+func visitDashboard(t *testing.T, app *App, profile string) string {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "active_profile", Value: profile})
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected %s's dashboard, got %d", profile, rr.Code)
+	}
+	return rr.Body.String()
+}
+
+// getSharingPage loads path as profile.
+func getSharingPage(app *App, profile, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.AddCookie(&http.Cookie{Name: "active_profile", Value: profile})
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+	return rr
+}
+
+// postSharingForm posts form to path as profile, or without a profile cookie when profile is empty.
+func postSharingForm(app *App, profile, path string, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if profile != "" {
+		req.AddCookie(&http.Cookie{Name: "active_profile", Value: profile})
+	}
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+	return rr
+}
+
+func seedSharingProfiles(t *testing.T, app *App) Item {
+	t.Helper()
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	app.activeUserID = "Bea"
+	app.hourlyWage = "20"
+	if err := app.persistProfileLocked(); err != nil {
+		t.Fatalf("persist Bea: %v", err)
+	}
+
+	app.activeUserID = "Alex"
+	if err := app.loadStateFromDB("Alex"); err != nil {
+		t.Fatalf("load Alex: %v", err)
+	}
+	app.hourlyWage = "25"
+	if err := app.persistProfileLocked(); err != nil {
+		t.Fatalf("persist Alex: %v", err)
+	}
+	item := Item{Title: "Espresso machine", Status: "Ready to buy", WaitPreset: "24h", PurchaseAllowedAt: time.Now().Add(-time.Hour), CreatedAt: time.Now()}
+	if err := app.insertItemLocked(&item); err != nil {
+		t.Fatalf("insert item: %v", err)
+	}
+	app.items = append(app.items, item)
+	return item
+}
+
+func TestSharedItemCanBeDecidedByEitherProfileWithAttribution(t *testing.T) {
+	app, cleanup := newSQLiteTestApp(t)
+	defer cleanup()
+
+	item := seedSharingProfiles(t, app)
+	itemID := strconv.Itoa(item.ID)
+
+	rr := postSharingForm(app, "Alex", "/items/share", url.Values{"item_id": {itemID}, "profile_name": {"Bea"}, "action": {"add"}})
+	if rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected share redirect, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if body := visitDashboard(t, app, "Bea"); !strings.Contains(body, "Espresso machine") || !strings.Contains(body, "Shared by Alex") {
+		t.Fatalf("expected shared item on Bea's dashboard")
+	}
+
+	rr = postSharingForm(app, "Bea", "/items/status", url.Values{"item_id": {itemID}, "status": {"Skipped"}})
+	if rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected status redirect, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	edit := getSharingPage(app, "Alex", "/items/edit?id="+itemID)
+	if edit.Code != http.StatusOK {
+		t.Fatalf("expected edit page, got %d", edit.Code)
+	}
+	body := edit.Body.String()
+	if !strings.Contains(body, "Bea skipped") || !strings.Contains(body, "Alex shared (Bea)") {
+		t.Fatalf("expected attributed history on owner's edit page, got %s", body)
+	}
+
+	var status string
+	if err := app.db.QueryRow(`SELECT status FROM items WHERE id = ?`, item.ID).Scan(&status); err != nil {
+		t.Fatalf("load status: %v", err)
+	}
+	if status != "Skipped" {
+		t.Fatalf("expected owner to see Bea's decision, got %q", status)
+	}
+}
+
+func TestSharedItemRecipientCannotReshareAndDeleteOnlyLeaves(t *testing.T) {
+	app, cleanup := newSQLiteTestApp(t)
+	defer cleanup()
+
+	item := seedSharingProfiles(t, app)
+	itemID := strconv.Itoa(item.ID)
+
+	if rr := postSharingForm(app, "Alex", "/items/share", url.Values{"item_id": {itemID}, "profile_name": {"Nobody"}, "action": {"add"}}); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected unknown profile to be rejected, got %d", rr.Code)
+	}
+	if rr := postSharingForm(app, "Alex", "/items/share", url.Values{"item_id": {itemID}, "profile_name": {"Bea"}, "action": {"add"}}); rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected share redirect, got %d", rr.Code)
+	}
+
+	if rr := postSharingForm(app, "Bea", "/items/share", url.Values{"item_id": {itemID}, "profile_name": {"Alex"}, "action": {"remove"}}); rr.Code != http.StatusForbidden {
+		t.Fatalf("expected recipient to be forbidden from changing sharing, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if rr := postSharingForm(app, "Bea", "/items/delete", url.Values{"item_id": {itemID}}); rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected delete redirect, got %d", rr.Code)
+	}
+	if body := visitDashboard(t, app, "Bea"); strings.Contains(body, "Espresso machine") {
+		t.Fatalf("expected item to be gone from Bea's list")
+	}
+	if body := visitDashboard(t, app, "Alex"); !strings.Contains(body, "Espresso machine") {
+		t.Fatalf("expected owner to keep the item after recipient left")
+	}
+}
+
+func TestSplitItemShowsEachShareAndCountsItInInsights(t *testing.T) {
+	app, cleanup := newSQLiteTestApp(t)
+	defer cleanup()
+
+	item := seedSharingProfiles(t, app)
+	itemID := strconv.Itoa(item.ID)
+	app.mu.Lock()
+	app.items[0].Price, app.items[0].PriceCents, app.items[0].HasPriceValue = "100", 10000, true
+	if err := app.updateItemLocked(app.items[0]); err != nil {
+		app.mu.Unlock()
+		t.Fatalf("update item: %v", err)
+	}
+	app.mu.Unlock()
+
+	if rr := postSharingForm(app, "Alex", "/items/split", url.Values{"item_id": {itemID}, "profile_name": {"Bea"}, "split_percent": {"40"}}); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected splitting with a profile the item is not shared with to fail, got %d", rr.Code)
+	}
+	postSharingForm(app, "Alex", "/items/share", url.Values{"item_id": {itemID}, "profile_name": {"Bea"}, "action": {"add"}})
+	if rr := postSharingForm(app, "Alex", "/items/split", url.Values{"item_id": {itemID}, "profile_name": {"Bea"}, "split_percent": {"120"}}); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected an invalid percentage to be rejected, got %d", rr.Code)
+	}
+	if rr := postSharingForm(app, "Alex", "/items/split", url.Values{"item_id": {itemID}, "profile_name": {"Bea"}, "split_percent": {"40"}}); rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected split redirect, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if body := visitDashboard(t, app, "Alex"); !strings.Contains(body, "Split: Alex 60% (2.4 h) · Bea 40% (2.0 h)") {
+		t.Fatalf("expected each profile's share in work hours on the card")
+	}
+	rr := getSharingPage(app, "Alex", itemEditPath(item.ID))
+	if body := rr.Body.String(); !strings.Contains(body, `name="split_percent" type="number" min="0" max="100" step="1" class="form-control" value="40"`) || !strings.Contains(body, "You carry the rest (60% now)") {
+		t.Fatalf("expected the split form to show the current shares, got %d", rr.Code)
+	}
+	if body := visitDashboard(t, app, "Bea"); !strings.Contains(body, "Split: Alex 60% (2.4 h) · Bea 40% (2.0 h)") {
+		t.Fatalf("expected the split on Bea's card as well")
+	}
+
+	postSharingForm(app, "Bea", "/items/status", url.Values{"item_id": {itemID}, "status": {"Skipped"}})
+	insights := func(profile string) string {
+		return getSharingPage(app, profile, "/insights").Body.String()
+	}
+	if body := insights("Bea"); !strings.Contains(body, "€ 40.00") {
+		t.Fatalf("expected Bea's insights to count her 40%% share")
+	}
+	if body := insights("Alex"); !strings.Contains(body, "€ 60.00") {
+		t.Fatalf("expected Alex's insights to count the remaining 60%%")
+	}
+}
@@ -0,0 +1,172 @@
+package web
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+// titleDatePlaceholder is replaced with the current date when a template title is applied.
+const titleDatePlaceholder = "{date}"
+
+// itemTemplate pre-fills the add-item form for purchases that come up repeatedly.
+type itemTemplate struct {
+	ID              int
+	Name            string
+	Title           string
+	Price           string
+	Tags            string
+	WaitPreset      string
+	WaitCustomHours string
+}
+
+type templateSettingsViewData struct {
+	Title           string
+	CurrentPath     string
+	ContentTemplate string
+	ScriptTemplate  string
+	ItemTemplates   []itemTemplate
+	FormValues      itemTemplate
+	TagOptions      []string
+	SelectedTags    map[string]bool
+	Currency        string
+	Error           string
+	Feedback        string
+	ActiveProfile   string
+}
+
+// item returns the form values for a new item created from the template.
+func (t itemTemplate) item(now time.Time) Item {
+	item := Item{
+		Title:           strings.ReplaceAll(t.Title, titleDatePlaceholder, now.Format("2006-01-02")),
+		Price:           t.Price,
+		Tags:            t.Tags,
+		WaitPreset:      t.WaitPreset,
+		WaitCustomHours: t.WaitCustomHours,
+	}
+	if parsedPrice, ok := parsePrice(item.Price); ok {
+		item.PriceCents = parsedPrice
+		item.HasPriceValue = true
+	}
+	return item
+}
+
+func (a *App) itemTemplateLocked(templateID int) (itemTemplate, bool) {
+	for _, tpl := range a.itemTemplates {
+		if tpl.ID == templateID {
+			return tpl, true
+		}
+	}
+	return itemTemplate{}, false
+}
+
+func validateItemTemplate(tpl itemTemplate) error {
+	if tpl.Name == "" {
+		return errors.New("Please enter a template name.")
+	}
+	if tpl.Price != "" {
+		if _, ok := parsePrice(tpl.Price); !ok {
+			return errors.New("Please enter a valid price or leave it empty.")
+		}
+	}
+	if _, err := domain.ParseWaitDuration(tpl.WaitPreset, tpl.WaitCustomHours); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (a *App) templateSettings(w http.ResponseWriter, r *http.Request) {
+	feedback := ""
+	switch r.URL.Query().Get("saved") {
+	case "1":
+		feedback = "Template saved."
+	case "deleted":
+		feedback = "Template deleted."
+	}
+	a.renderTemplateSettings(w, templateSettingsViewData{Feedback: feedback})
+}
+
+func (a *App) saveTemplateSettings(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	switch strings.TrimSpace(r.FormValue("action")) {
+	case "add":
+		tpl := itemTemplate{
+			Name:            strings.TrimSpace(r.FormValue("name")),
+			Title:           strings.TrimSpace(r.FormValue("title")),
+			Price:           strings.TrimSpace(r.FormValue("price")),
+			Tags:            parseTagsFromForm(r.Form["tags"]),
+			WaitPreset:      strings.TrimSpace(r.FormValue("wait_preset")),
+			WaitCustomHours: strings.TrimSpace(r.FormValue("wait_custom_hours")),
+		}
+		if err := validateItemTemplate(tpl); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			a.renderTemplateSettings(w, templateSettingsViewData{FormValues: tpl, Error: err.Error()})
+			return
+		}
+		tpl.WaitPreset = domain.NormalizeWaitPreset(tpl.WaitPreset)
+		if tpl.WaitPreset != "custom" {
+			tpl.WaitCustomHours = ""
+		}
+
+		a.mu.LockContext(r.Context())
+		if err := a.insertItemTemplateLocked(&tpl); err != nil {
+			a.mu.Unlock()
+			log.Printf("db error while saving item template: %v", err)
+			http.Error(w, "could not save template", http.StatusInternalServerError)
+			return
+		}
+		a.itemTemplates = append(a.itemTemplates, tpl)
+		slices.SortStableFunc(a.itemTemplates, func(x, y itemTemplate) int {
+			return strings.Compare(strings.ToLower(x.Name), strings.ToLower(y.Name))
+		})
+		a.mu.Unlock()
+		http.Redirect(w, r, "/settings/templates?saved=1", http.StatusSeeOther)
+	case "delete":
+		templateID, err := strconv.Atoi(strings.TrimSpace(r.FormValue("template_id")))
+		if err != nil || templateID <= 0 {
+			http.Error(w, "invalid template id", http.StatusBadRequest)
+			return
+		}
+
+		a.mu.LockContext(r.Context())
+		if err := a.deleteItemTemplateLocked(templateID); err != nil {
+			a.mu.Unlock()
+			log.Printf("db error while deleting item template: %v", err)
+			http.Error(w, "could not delete template", http.StatusInternalServerError)
+			return
+		}
+		a.itemTemplates = slices.DeleteFunc(a.itemTemplates, func(tpl itemTemplate) bool { return tpl.ID == templateID })
+		a.mu.Unlock()
+		http.Redirect(w, r, "/settings/templates?saved=deleted", http.StatusSeeOther)
+	default:
+		http.Error(w, "invalid action", http.StatusBadRequest)
+	}
+}
+
+func (a *App) renderTemplateSettings(w http.ResponseWriter, data templateSettingsViewData) {
+	a.mu.RLock()
+	data.ItemTemplates = append([]itemTemplate(nil), a.itemTemplates...)
+	data.TagOptions = availableTagOptions(a.items, a.tagCatalog)
+	data.Currency = profileCurrencyOrDefault(a.currency)
+	data.ActiveProfile = a.currentUserIDLocked()
+	a.mu.RUnlock()
+
+	if data.FormValues.WaitPreset == "" {
+		data.FormValues.WaitPreset = "24h"
+	}
+	data.SelectedTags = selectedTagsMap(data.FormValues.Tags)
+	data.Title = "Item templates"
+	data.CurrentPath = "/settings/templates"
+	data.ContentTemplate = "templates_content"
+	renderTemplate(w, a.templates, "layout", data)
+}
@@ -0,0 +1,91 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestItemTemplateExpandsDatePlaceholder(t *testing.T) {
+	tpl := itemTemplate{Title: "Groceries splurge {date}", Price: "12.50", Tags: "Home", WaitPreset: "7d"}
+	item := tpl.item(time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC))
+
+	if item.Title != "Groceries splurge 2026-03-14" {
+		t.Fatalf("unexpected title %q", item.Title)
+	}
+	if !item.HasPriceValue || item.PriceCents != 1250 {
+		t.Fatalf("expected parsed price, got %+v", item)
+	}
+	if item.WaitPreset != "7d" || item.Tags != "Home" {
+		t.Fatalf("expected wait preset and tags to carry over, got %+v", item)
+	}
+}
+
+func TestTemplateSettingsRejectInvalidTemplate(t *testing.T) {
+	app := NewApp()
+	seedProfile(app)
+
+	form := url.Values{"action": {"add"}, "name": {""}, "wait_preset": {"24h"}}
+	req := httptest.NewRequest(http.MethodPost, "/settings/templates", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "Please enter a template name.") {
+		t.Fatalf("expected validation error, got %d", rr.Code)
+	}
+}
+
+func TestItemTemplatePersistsAndPrefillsNewItemForm(t *testing.T) {
+	app, cleanup := newSQLiteTestApp(t)
+	defer cleanup()
+
+	app.mu.Lock()
+	app.activeUserID = "Alex"
+	app.hourlyWage = "25"
+	if err := app.persistProfileLocked(); err != nil {
+		app.mu.Unlock()
+		t.Fatalf("persist profile: %v", err)
+	}
+	app.mu.Unlock()
+
+	form := url.Values{"action": {"add"}, "name": {"Game"}, "title": {"New game"}, "price": {"69.99"}, "tags": {"Gaming"}, "wait_preset": {"30d"}}
+	req := httptest.NewRequest(http.MethodPost, "/settings/templates", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected redirect, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	app.mu.Lock()
+	if err := app.loadStateFromDB("Alex"); err != nil {
+		app.mu.Unlock()
+		t.Fatalf("reload profile: %v", err)
+	}
+	templates := append([]itemTemplate(nil), app.itemTemplates...)
+	app.mu.Unlock()
+	if len(templates) != 1 || templates[0].Name != "Game" || templates[0].WaitPreset != "30d" {
+		t.Fatalf("expected persisted template, got %+v", templates)
+	}
+
+	formRR := httptest.NewRecorder()
+	app.Handler().ServeHTTP(formRR, httptest.NewRequest(http.MethodGet, "/items/new?template="+strconv.Itoa(templates[0].ID), nil))
+	if formRR.Code != http.StatusOK {
+		t.Fatalf("expected form, got %d", formRR.Code)
+	}
+	body := formRR.Body.String()
+	if !strings.Contains(body, `value="New game"`) || !strings.Contains(body, `value="69.99"`) || !strings.Contains(body, `<option value="30d" selected>`) {
+		t.Fatalf("expected template values in new item form")
+	}
+
+	missingRR := httptest.NewRecorder()
+	app.Handler().ServeHTTP(missingRR, httptest.NewRequest(http.MethodGet, "/items/new?template=999", nil))
+	if missingRR.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown template, got %d", missingRR.Code)
+	}
+}
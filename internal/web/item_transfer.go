@@ -0,0 +1,145 @@
+package web
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"mvpapp/internal/domain"
+)
+
+// transferCandidates lists the profiles an item can be moved to: every other profile that is not archived.
+func transferCandidates(profiles, archived []string, owner string) []string {
+	candidates := make([]string, 0, len(profiles))
+	for _, name := range profiles {
+		if name == owner || slices.Contains(archived, name) {
+			continue
+		}
+		candidates = append(candidates, name)
+	}
+	return candidates
+}
+
+// transferItemLocked hands the item to another profile. History and the item ID stay, so links and the
+// history list keep working. A share with the new owner is dropped because owners cannot be shared with,
+// and the old owner gets a change entry so its sync clients drop the item.
+func (a *App) transferItemLocked(itemID int, from, to string) error {
+	tx, err := a.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin item transfer tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.Exec(`UPDATE items SET user_id = ? WHERE id = ? AND user_id = ?`, to, itemID, from); err != nil {
+		return fmt.Errorf("move item: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM item_shares WHERE item_id = ? AND user_id = ?`, itemID, to); err != nil {
+		return fmt.Errorf("drop share with new owner: %w", err)
+	}
+	if _, err := tx.Exec(`INSERT INTO item_changes(item_id, user_id) VALUES (?, ?)`, itemID, from); err != nil {
+		return fmt.Errorf("record item change: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit item transfer tx: %w", err)
+	}
+	return nil
+}
+
+func (a *App) ownedItemCountLocked(profile string) (int, error) {
+	var count int
+	if err := a.db.QueryRow(`SELECT COUNT(*) FROM items WHERE user_id = ?`, profile).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count items: %w", err)
+	}
+	return count, nil
+}
+
+// transferItem moves an item created under the wrong profile to the right one. Only the owner can move
+// it, and both profiles get an audit entry.
+func (a *App) transferItem(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	id, err := strconv.Atoi(strings.TrimSpace(r.FormValue("item_id")))
+	if err != nil || id <= 0 {
+		http.Error(w, "invalid item id", http.StatusBadRequest)
+		return
+	}
+	target, err := domain.ParseProfileName(r.FormValue("profile_name"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	profiles, err := a.listProfileNames()
+	if err == nil {
+		var archived bool
+		if archived, err = a.profileArchived(target); err == nil && archived {
+			http.Error(w, "items cannot be moved to an archived profile", http.StatusBadRequest)
+			return
+		}
+	}
+	if err != nil {
+		log.Printf("db error while listing profiles for item transfer: %v", err)
+		http.Error(w, "could not move item", http.StatusInternalServerError)
+		return
+	}
+	if !slices.Contains(profiles, target) {
+		http.Error(w, "unknown profile", http.StatusBadRequest)
+		return
+	}
+
+	a.mu.LockContext(r.Context())
+	defer a.mu.Unlock()
+
+	if a.db == nil {
+		http.Error(w, "moving items requires persistent storage", http.StatusConflict)
+		return
+	}
+
+	i := a.itemIndexLocked(id)
+	if i < 0 {
+		http.NotFound(w, r)
+		return
+	}
+	item := a.items[i]
+	owner := a.currentUserIDLocked()
+	if !a.isOwnedByLocked(item) {
+		http.Error(w, "only the owner can move an item", http.StatusForbidden)
+		return
+	}
+	if target == owner {
+		http.Error(w, "the item already belongs to this profile", http.StatusBadRequest)
+		return
+	}
+	if a.itemQuota > 0 {
+		count, err := a.ownedItemCountLocked(target)
+		if err != nil {
+			log.Printf("db error while moving item: %v", err)
+			http.Error(w, "could not move item", http.StatusInternalServerError)
+			return
+		}
+		if count >= a.itemQuota {
+			http.Error(w, fmt.Sprintf("%s has reached the limit of %d items.", target, a.itemQuota), http.StatusConflict)
+			return
+		}
+	}
+
+	if err := a.transferItemLocked(id, owner, target); err != nil {
+		log.Printf("db error while moving item: %v", err)
+		http.Error(w, "could not move item", http.StatusInternalServerError)
+		return
+	}
+	a.items = slices.Delete(a.items, i, i+1)
+	a.recordHistoryLocked(id, "moved", owner+" → "+target)
+	a.recordAuditLocked(owner, auditItemTransferred, fmt.Sprintf("%q to %s", item.Title, target), r)
+	a.recordAuditLocked(target, auditItemTransferred, fmt.Sprintf("%q from %s", item.Title, owner), r)
+
+	itemActionRedirect(w, r, "moved", item.Title)
+}
@@ -0,0 +1,44 @@
+package web_test
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestItemMovesToAnotherProfileWithItsHistory(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}, {Name: "Sam"}},
+		Items:    []webtest.Item{{Profile: "Alex", Title: "Tent"}},
+	})
+	alex, sam := h.As("Alex"), h.As("Sam")
+	itemID := strconv.Itoa(h.Item("Alex", "Tent").ID)
+
+	alex.PostForm("/items/share", url.Values{"item_id": {itemID}, "profile_name": {"Sam"}, "action": {"add"}}).ExpectStatus(http.StatusSeeOther)
+	alex.Get("/items/"+itemID+"/edit").ExpectContains(`action="/items/move"`, `<option value="Sam">Sam</option>`)
+
+	sam.PostForm("/items/move", url.Values{"item_id": {itemID}, "profile_name": {"Sam"}}).ExpectStatus(http.StatusForbidden)
+	alex.PostForm("/items/move", url.Values{"item_id": {itemID}, "profile_name": {"Nobody"}}).ExpectStatus(http.StatusBadRequest)
+	alex.PostForm("/items/move", url.Values{"item_id": {itemID}, "profile_name": {"Sam"}}).
+		ExpectRedirect("/?done=moved&item=Tent")
+
+	if items := h.Items("Alex"); len(items) != 0 {
+		t.Fatalf("expected Alex to have no items left, got %d", len(items))
+	}
+	h.Item("Sam", "Tent")
+	alex.Get("/?done=moved&item=Tent").ExpectContains(`&#34;Tent&#34; moved to another profile.`)
+	sam.Get("/items/"+itemID+"/edit").ExpectStatus(http.StatusOK).
+		ExpectContains("Alex shared (Sam)", "Alex moved (Alex → Sam)").
+		ExpectNotContains("Shared by Alex")
+
+	var audited int
+	if err := h.DB.QueryRow(`SELECT COUNT(*) FROM audit_log WHERE event = 'item moved' AND user_id IN ('Alex', 'Sam')`).Scan(&audited); err != nil {
+		t.Fatalf("count audit entries: %v", err)
+	}
+	if audited != 2 {
+		t.Fatalf("expected an audit entry for both profiles, got %d", audited)
+	}
+}
@@ -0,0 +1,106 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"text/template"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+// itemHookTimeout bounds one run of the item hook, since it runs while the app is locked.
+const itemHookTimeout = 10 * time.Second
+
+// itemHook is the command run on item events; see SetItemHook.
+type itemHook struct {
+	path string
+	args []*template.Template
+}
+
+// itemHookPayload is written to the hook's stdin as JSON and is the data of its argument templates.
+type itemHookPayload struct {
+	Event      string  `json:"event"`
+	Profile    string  `json:"profile"`
+	Item       apiItem `json:"item"`
+	DeliveryID int64   `json:"delivery_id,omitempty"`
+}
+
+// SetItemHook runs the command at path whenever an item becomes ready to buy or is decided. args
+// is split on whitespace and each argument is a text/template over the event, e.g.
+// "{{.Event}} {{.Item.ID}}"; the same data is passed as JSON on stdin. An empty path disables the hook.
+func (a *App) SetItemHook(path, args string) error {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		a.itemHook = nil
+		return nil
+	}
+	templates, err := parseItemHookArgs(args)
+	if err != nil {
+		return err
+	}
+	a.itemHook = &itemHook{path: path, args: templates}
+	return nil
+}
+
+// CheckItemHookArgs reports why SetItemHook would reject args, so configuration can be checked before
+// an App exists.
+func CheckItemHookArgs(args string) error {
+	_, err := parseItemHookArgs(args)
+	return err
+}
+
+func parseItemHookArgs(args string) ([]*template.Template, error) {
+	var templates []*template.Template
+	for i, raw := range strings.Fields(args) {
+		tmpl, err := template.New(fmt.Sprintf("arg%d", i)).Option("missingkey=error").Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("parse argument %q: %w", raw, err)
+		}
+		templates = append(templates, tmpl)
+	}
+	return templates, nil
+}
+
+// runItemHookLocked runs the item hook for an item event.
+func (a *App) runItemHookLocked(e domain.Event, deliveryID int64) error {
+	if a.itemHook == nil {
+		return nil
+	}
+	// The hook is configured for the whole instance, so private items stay private from it.
+	payload := itemHookPayload{Event: string(e.Type), Profile: e.Profile, Item: newAPIItem(maskPrivate(e.Item)), DeliveryID: deliveryID}
+	return a.itemHook.run(a.mu.Context(), payload)
+}
+
+func (h *itemHook) run(ctx context.Context, payload itemHookPayload) error {
+	args := make([]string, 0, len(h.args))
+	for _, tmpl := range h.args {
+		var arg strings.Builder
+		if err := tmpl.Execute(&arg, payload); err != nil {
+			return fmt.Errorf("render argument: %w", err)
+		}
+		args = append(args, arg.String())
+	}
+	stdin, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode item: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, itemHookTimeout)
+	defer cancel()
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, h.path, args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
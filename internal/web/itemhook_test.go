@@ -0,0 +1,63 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestItemHookRunsOnPromotedAndDecidedItems(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script")
+	}
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls.log")
+	script := filepath.Join(dir, "hook")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho \"$@\" >> "+calls+"\ncat >> "+calls+"\necho >> "+calls+"\n"), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+
+	app := NewApp()
+	seedProfile(app)
+	if err := app.SetItemHook(script, "{{.Event}} {{.Item.ID}}"); err != nil {
+		t.Fatalf("set hook: %v", err)
+	}
+	app.mu.Lock()
+	app.items = append(app.items, Item{ID: 9, Title: "Headphones", Status: "Waiting", PurchaseAllowedAt: time.Now().Add(-time.Minute)})
+	app.mu.Unlock()
+
+	app.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	req := httptest.NewRequest(http.MethodPost, "/items/status", strings.NewReader(url.Values{"item_id": {"9"}, "status": {"Skipped"}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	app.Handler().ServeHTTP(httptest.NewRecorder(), req)
+
+	raw, err := os.ReadFile(calls)
+	if err != nil {
+		t.Fatalf("read hook log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(raw)), "\n")
+	if len(lines) != 4 || lines[0] != "item.promoted 9" || lines[2] != "item.decided 9" {
+		t.Fatalf("expected a promoted and a decided call, got %q", raw)
+	}
+	var payload itemHookPayload
+	if err := json.Unmarshal([]byte(lines[3]), &payload); err != nil {
+		t.Fatalf("decode stdin: %v", err)
+	}
+	if payload.Event != "item.decided" || payload.Item.Title != "Headphones" || payload.Item.Status != "Skipped" {
+		t.Fatalf("unexpected payload %+v", payload)
+	}
+}
+
+func TestSetItemHookRejectsInvalidArguments(t *testing.T) {
+	app := NewApp()
+	if err := app.SetItemHook("/bin/true", "{{.Item.ID"); err == nil {
+		t.Fatal("expected an unparsable argument template to be rejected")
+	}
+}
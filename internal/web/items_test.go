@@ -0,0 +1,92 @@
+package web_test
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestStatusChangesArePersistedPerProfile(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}, {Name: "Bea"}},
+		Items: []webtest.Item{
+			{Profile: "Alex", Title: "Headphones", Status: "Ready to buy"},
+			{Profile: "Bea", Title: "Bike", Status: "Ready to buy"},
+		},
+	})
+
+	alexItem := h.Item("Alex", "Headphones")
+	h.As("Bea").Get("/").ExpectContains("Bike").ExpectNotContains("Headphones")
+	h.As("Alex").PostForm("/items/status", url.Values{"item_id": {strconv.Itoa(alexItem.ID)}, "status": {"Skipped"}}).
+		ExpectRedirect("/?done=skipped&item=Headphones")
+
+	if got := h.Item("Alex", "Headphones").Status; got != "Skipped" {
+		t.Fatalf("expected Alex's item to be skipped, got %q", got)
+	}
+	if got := h.Item("Bea", "Bike").Status; got != "Ready to buy" {
+		t.Fatalf("expected Bea's item to be unchanged, got %q", got)
+	}
+}
+
+func TestItemActionsAreConfirmedInALiveRegion(t *testing.T) {
+	now := time.Now()
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}},
+		Items: []webtest.Item{
+			{Profile: "Alex", Title: "Headphones", PurchaseAllowedAt: now.Add(-time.Hour)},
+			{Profile: "Alex", Title: "Bike", PurchaseAllowedAt: now.Add(-time.Hour)},
+			{Profile: "Alex", Title: "Lamp", Status: "Researching"},
+			{Profile: "Alex", Title: "Desk & chair", PurchaseAllowedAt: now.Add(time.Hour)},
+		},
+	})
+	alex := h.As("Alex")
+	itemID := func(title string) string { return strconv.Itoa(h.Item("Alex", title).ID) }
+
+	for _, step := range []struct {
+		path     string
+		form     url.Values
+		redirect string
+		message  string
+	}{
+		{"/items/status", url.Values{"item_id": {itemID("Headphones")}, "status": {"Bought"}}, "/?done=bought&item=Headphones", "&#34;Headphones&#34; marked as bought."},
+		{"/items/snooze", url.Values{"item_id": {itemID("Bike")}, "snooze_preset": {"24h"}}, "/?done=snoozed&item=Bike", "&#34;Bike&#34; snoozed for 24 hours."},
+		{"/items/start-wait", url.Values{"item_id": {itemID("Lamp")}}, "/?done=wait-started&item=Lamp", "Wait started for &#34;Lamp&#34;."},
+		{"/items/delete", url.Values{"item_id": {itemID("Desk & chair")}}, "/?done=deleted&item=Desk+%26+chair", "&#34;Desk &amp; chair&#34; deleted."},
+	} {
+		alex.PostForm(step.path, step.form).ExpectRedirect(step.redirect)
+		alex.Get(step.redirect).
+			ExpectStatus(http.StatusOK).
+			ExpectContains(`role="status" aria-live="polite"`, ">"+step.message+"</div>")
+	}
+
+	alex.Get("/?done=unknown&item=Bike").ExpectNotContains("Bike&#34;")
+}
+
+func TestBackfilledItemsKeepTheirHistoricalDates(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{Profiles: []webtest.Profile{{Name: "Alex"}}})
+	alex := h.As("Alex")
+
+	alex.PostForm("/items/new", url.Values{"title": {"Old bike"}, "price": {"300"}, "wait_preset": {"7d"}, "created_at": {"2025-03-01"}, "decision": {"Bought"}, "decided_at": {"2025-02-01"}}).
+		ExpectStatus(http.StatusBadRequest).
+		ExpectContains("The decision date cannot be before the item was added.", "<details")
+	alex.PostForm("/items/new", url.Values{"title": {"Old bike"}, "price": {"300"}, "wait_preset": {"7d"}, "created_at": {"2025-03-01"}, "decision": {"Bought"}, "decided_at": {"2025-03-10"}}).
+		ExpectRedirect("/")
+
+	item := h.Item("Alex", "Old bike")
+	var createdAt, decidedAt string
+	if err := h.DB.QueryRow(`SELECT created_at, decided_at FROM items WHERE id = ?`, item.ID).Scan(&createdAt, &decidedAt); err != nil {
+		t.Fatalf("load dates: %v", err)
+	}
+	if item.Status != "Bought" || !strings.HasPrefix(createdAt, "2025-03-01") || !strings.HasPrefix(decidedAt, "2025-03-10") {
+		t.Fatalf("expected the backfilled dates, got %s created %s decided %s", item.Status, createdAt, decidedAt)
+	}
+
+	alex.PostJSON("/api/v1/items", map[string]any{"title": "Old lamp", "created_at": "2025-01-05", "decided_at": "2025-01-20", "decision": "Skipped"}).
+		ExpectStatus(http.StatusCreated).
+		ExpectContains(`"status":"Skipped"`, `"created_at":"2025-01-05T00:00:00`)
+}
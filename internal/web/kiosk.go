@@ -0,0 +1,146 @@
+package web
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+const (
+	kioskUpcomingWindow  = 72 * time.Hour
+	kioskRefreshInterval = 60 * time.Second
+)
+
+type kioskViewData struct {
+	Title          string
+	ProfileName    string
+	ReadyItems     []Item
+	UpcomingItems  []Item
+	Currency       string
+	RefreshSeconds int
+	GeneratedAt    time.Time
+	Preview        sharePreview
+}
+
+// sharePreview is the OpenGraph card chat apps show for a pasted share link.
+type sharePreview struct {
+	Title       string
+	Description string
+	ImageURL    string
+}
+
+// kiosk shows a profile's board to anyone with its share link. It refreshes every minute, so it changes
+// nothing but the view count and token audit: elapsed items are shown as ready without promoting them.
+func (a *App) kiosk(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimSpace(r.URL.Query().Get("token"))
+
+	a.mu.LockContext(r.Context())
+	profileName, err := a.profileNameByShareTokenLocked(token)
+	if err != nil {
+		a.mu.Unlock()
+		log.Printf("db error while resolving share token: %v", err)
+		http.Error(w, "could not load kiosk", http.StatusInternalServerError)
+		return
+	}
+	if profileName == "" {
+		a.mu.Unlock()
+		http.NotFound(w, r)
+		return
+	}
+	a.recordTokenUseLocked(profileName, "share link", r)
+	if err := a.countShareViewLocked(profileName, time.Now()); err != nil {
+		log.Printf("db error while counting share view: %v", err)
+	}
+	items, err := a.itemsForProfileLocked(profileName)
+	var currency string
+	if err == nil {
+		currency, err = a.currencyForProfileLocked(profileName)
+	}
+	a.mu.Unlock()
+	if err != nil {
+		log.Printf("db error while loading kiosk items: %v", err)
+		http.Error(w, "could not load kiosk", http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	items = maskPrivateItems(items)
+	ready, upcoming := kioskBoard(items, now)
+	preview := sharePreview{
+		Title:       profileName + "'s waitlist",
+		Description: fmt.Sprintf("%d ready to decide, %d unlocking soon", len(ready), len(upcoming)),
+		ImageURL:    a.shareCardURL(token, 0),
+	}
+	if rawID := r.URL.Query().Get("item"); rawID != "" {
+		if item, ok := findSharedItem(items, rawID); ok {
+			preview = sharePreview{
+				Title:       item.Title,
+				Description: itemPreviewDescription(item, currency, now),
+				ImageURL:    a.shareCardURL(token, item.ID),
+			}
+		}
+	}
+
+	setShareHeaders(w)
+	renderTemplate(w, a.templates, "kiosk", kioskViewData{
+		Title:          "Impulse Pause board",
+		ProfileName:    profileName,
+		ReadyItems:     ready,
+		UpcomingItems:  upcoming,
+		Currency:       currency,
+		RefreshSeconds: int(kioskRefreshInterval / time.Second),
+		GeneratedAt:    now,
+		Preview:        preview,
+	})
+}
+
+// itemPreviewDescription is the price and countdown of an item as one sentence for link previews.
+func itemPreviewDescription(item Item, currency string, now time.Time) string {
+	status := string(effectiveStatus(item, now))
+	if status == string(domain.StatusWaiting) {
+		status = cardCountdown(item.PurchaseAllowedAt, now)
+	}
+	if item.Price == "" {
+		return status
+	}
+	return shareCardPrice(item.Price, currency) + " · " + status
+}
+
+// kioskBoard splits items into those ready to decide and those unlocking within kioskUpcomingWindow.
+func kioskBoard(items []Item, now time.Time) (ready []Item, upcoming []Item) {
+	for _, item := range items {
+		item.Status = effectiveStatus(item, now)
+		switch item.Status {
+		case domain.StatusReady:
+			ready = append(ready, item)
+		case domain.StatusWaiting:
+			if item.PurchaseAllowedAt.Sub(now) <= kioskUpcomingWindow {
+				upcoming = append(upcoming, item)
+			}
+		}
+	}
+
+	byUnlock := func(a, b Item) int {
+		if cmp := a.PurchaseAllowedAt.Compare(b.PurchaseAllowedAt); cmp != 0 {
+			return cmp
+		}
+		return a.ID - b.ID
+	}
+	slices.SortFunc(ready, byUnlock)
+	slices.SortFunc(upcoming, byUnlock)
+	return ready, upcoming
+}
+
+// effectiveStatus reports the status an item would have after promotion.
+// Items of inactive profiles are not promoted in the background, so the unlock time decides readiness.
+func effectiveStatus(item Item, now time.Time) domain.Status {
+	if item.Status == domain.StatusWaiting && !item.PurchaseAllowedAt.After(now) {
+		return domain.StatusReady
+	}
+	return item.Status
+}
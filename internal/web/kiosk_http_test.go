@@ -0,0 +1,39 @@
+package web_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestKioskViewsLeaveTheProfileAndItsItemsUnchanged(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}},
+		Items:    []webtest.Item{{Profile: "Alex", Title: "Headphones", PurchaseAllowedAt: time.Now().Add(-time.Hour)}},
+	})
+	if _, err := h.DB.Exec(`UPDATE profiles SET share_token = 'kiosk-token' WHERE user_id = 'Alex'`); err != nil {
+		t.Fatalf("set share token: %v", err)
+	}
+	revision := func() int {
+		t.Helper()
+		var revision int
+		if err := h.DB.QueryRow(`SELECT revision FROM profile_revision`).Scan(&revision); err != nil {
+			t.Fatalf("read profile revision: %v", err)
+		}
+		return revision
+	}
+
+	before := revision()
+	for range 3 {
+		h.Anonymous().Get("/kiosk?token=kiosk-token").ExpectStatus(http.StatusOK).ExpectContains("Headphones")
+	}
+	if after := revision(); after != before {
+		t.Fatalf("expected kiosk views to leave the profile revision at %d, got %d", before, after)
+	}
+	if status := h.Item("Alex", "Headphones").Status; status != "Waiting" {
+		t.Fatalf("expected the kiosk not to promote the item, got %q", status)
+	}
+	h.As("Alex").Get("/settings/profile").ExpectStatus(http.StatusOK).ExpectContains("Viewed 3 time(s)")
+}
@@ -0,0 +1,310 @@
+package web
+
+import (
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKioskRequiresValidShareToken(t *testing.T) {
+	app := NewApp()
+	seedProfile(app)
+
+	for _, target := range []string{"/kiosk", "/kiosk?token=wrong"} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rr := httptest.NewRecorder()
+		app.Handler().ServeHTTP(rr, req)
+		if rr.Code != http.StatusNotFound {
+			t.Fatalf("expected 404 for %s, got %d", target, rr.Code)
+		}
+	}
+}
+
+func TestKioskShowsReadyAndUpcomingItemsWithoutActions(t *testing.T) {
+	app := NewApp()
+	seedProfile(app)
+	now := time.Now()
+
+	app.mu.Lock()
+	app.shareToken = "kiosk-token"
+	app.items = []Item{
+		{ID: 1, Title: "Ready headphones", Price: "199", Status: "Ready to buy", PurchaseAllowedAt: now.Add(-time.Hour)},
+		{ID: 2, Title: "Soon keyboard", Status: "Waiting", PurchaseAllowedAt: now.Add(24 * time.Hour)},
+		{ID: 3, Title: "Far away bike", Status: "Waiting", PurchaseAllowedAt: now.Add(30 * 24 * time.Hour)},
+		{ID: 4, Title: "Decided lamp", Status: "Bought", PurchaseAllowedAt: now.Add(-48 * time.Hour)},
+	}
+	app.mu.Unlock()
+
+	req := httptest.NewRequest(http.MethodGet, "/kiosk?token=kiosk-token", nil)
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	body := rr.Body.String()
+	for _, want := range []string{"Ready headphones", "€ 199", "Soon keyboard", `http-equiv="refresh"`} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected kiosk to contain %q", want)
+		}
+	}
+	for _, unwanted := range []string{"Far away bike", "Decided lamp", "<form", "<button", "primary-nav"} {
+		if strings.Contains(body, unwanted) {
+			t.Fatalf("did not expect kiosk to contain %q", unwanted)
+		}
+	}
+}
+
+func TestKioskBoardTreatsElapsedWaitingItemsAsReady(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	items := []Item{
+		{ID: 1, Title: "later", Status: "Waiting", PurchaseAllowedAt: now.Add(2 * time.Hour)},
+		{ID: 2, Title: "elapsed", Status: "Waiting", PurchaseAllowedAt: now.Add(-time.Minute)},
+		{ID: 3, Title: "sooner", Status: "Waiting", PurchaseAllowedAt: now.Add(time.Hour)},
+	}
+
+	ready, upcoming := kioskBoard(items, now)
+
+	if len(ready) != 1 || ready[0].Title != "elapsed" || ready[0].Status != "Ready to buy" {
+		t.Fatalf("expected elapsed item to be ready, got %+v", ready)
+	}
+	if len(upcoming) != 2 || upcoming[0].Title != "sooner" || upcoming[1].Title != "later" {
+		t.Fatalf("expected upcoming items ordered by unlock time, got %+v", upcoming)
+	}
+}
+
+// storedShareToken returns the share token profile has stored.
+func storedShareToken(t *testing.T, app *App, profile string) string {
+	t.Helper()
+	var token string
+	if err := app.db.QueryRow(`SELECT share_token FROM profiles WHERE user_id = ?`, profile).Scan(&token); err != nil {
+		t.Fatalf("load share token: %v", err)
+	}
+	return token
+}
+
+func TestShareSettingsGenerateAndRevokeKioskLink(t *testing.T) {
+	app, cleanup := newSQLiteTestApp(t)
+	defer cleanup()
+
+	app.mu.Lock()
+	app.activeUserID = "Alex"
+	app.hourlyWage = "25"
+	if err := app.persistProfileLocked(); err != nil {
+		app.mu.Unlock()
+		t.Fatalf("persist profile: %v", err)
+	}
+	item := Item{Title: "Shared monitor", Status: "Ready to buy", WaitPreset: "24h", PurchaseAllowedAt: time.Now().Add(-time.Hour), CreatedAt: time.Now()}
+	if err := app.insertItemLocked(&item); err != nil {
+		app.mu.Unlock()
+		t.Fatalf("insert item: %v", err)
+	}
+	app.items = append(app.items, item)
+	app.mu.Unlock()
+
+	form := url.Values{}
+	form.Set("action", "generate")
+	req := httptest.NewRequest(http.MethodPost, "/settings/share", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(&http.Cookie{Name: "active_profile", Value: "Alex"})
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected redirect, got %d", rr.Code)
+	}
+
+	token := storedShareToken(t, app, "Alex")
+	if token == "" {
+		t.Fatalf("expected share token to be generated")
+	}
+
+	kioskReq := httptest.NewRequest(http.MethodGet, "/kiosk?token="+token, nil)
+	kioskRR := httptest.NewRecorder()
+	app.Handler().ServeHTTP(kioskRR, kioskReq)
+	if kioskRR.Code != http.StatusOK {
+		t.Fatalf("expected kiosk 200, got %d", kioskRR.Code)
+	}
+	if body := kioskRR.Body.String(); !strings.Contains(body, "Shared monitor") || !strings.Contains(body, "Alex's waitlist") {
+		t.Fatalf("expected kiosk to show the sharing profile's items")
+	}
+
+	form.Set("action", "revoke")
+	revokeReq := httptest.NewRequest(http.MethodPost, "/settings/share", strings.NewReader(form.Encode()))
+	revokeReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	revokeReq.AddCookie(&http.Cookie{Name: "active_profile", Value: "Alex"})
+	revokeRR := httptest.NewRecorder()
+	app.Handler().ServeHTTP(revokeRR, revokeReq)
+	if got := revokeRR.Header().Get("Location"); got != "/settings/profile?saved=unshare" {
+		t.Fatalf("expected revoke redirect, got %q", got)
+	}
+
+	revokedReq := httptest.NewRequest(http.MethodGet, "/kiosk?token="+token, nil)
+	revokedRR := httptest.NewRecorder()
+	app.Handler().ServeHTTP(revokedRR, revokedReq)
+	if revokedRR.Code != http.StatusNotFound {
+		t.Fatalf("expected revoked token to be rejected, got %d", revokedRR.Code)
+	}
+}
+
+func TestShareLinkExpiresCountsViewsAndStaysOutOfSearchIndexes(t *testing.T) {
+	app, cleanup := newSQLiteTestApp(t)
+	defer cleanup()
+
+	app.mu.Lock()
+	app.activeUserID = "Alex"
+	app.hourlyWage = "25"
+	if err := app.persistProfileLocked(); err != nil {
+		app.mu.Unlock()
+		t.Fatalf("persist profile: %v", err)
+	}
+	app.mu.Unlock()
+
+	serve := func(method, target string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(&http.Cookie{Name: "active_profile", Value: "Alex"})
+		rr := httptest.NewRecorder()
+		app.Handler().ServeHTTP(rr, req)
+		return rr
+	}
+
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	if rr := serve(http.MethodPost, "/settings/share", url.Values{"action": {"generate"}, "share_expires_on": {tomorrow}}); rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected redirect, got %d", rr.Code)
+	}
+	token := storedShareToken(t, app, "Alex")
+
+	for range 2 {
+		rr := serve(http.MethodGet, "/kiosk?token="+token, nil)
+		if rr.Code != http.StatusOK || !strings.Contains(rr.Header().Get("X-Robots-Tag"), "noindex") || !strings.Contains(rr.Body.String(), `<meta name="robots" content="noindex, nofollow" />`) {
+			t.Fatalf("expected a noindex kiosk page, got %d %q", rr.Code, rr.Header().Get("X-Robots-Tag"))
+		}
+	}
+	if body := serve(http.MethodGet, "/settings/profile", nil).Body.String(); !strings.Contains(body, "Viewed 2 time(s), last on") || !strings.Contains(body, `value="`+tomorrow+`"`) {
+		t.Fatal("expected the view count and expiry on the profile page")
+	}
+
+	yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+	if rr := serve(http.MethodPost, "/settings/share", url.Values{"action": {"expiry"}, "share_expires_on": {yesterday}}); rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "from today on") {
+		t.Fatalf("expected a past expiry to be rejected, got %d", rr.Code)
+	}
+
+	expired := time.Now().Add(-time.Minute)
+	app.mu.Lock()
+	if err := app.activateProfileLocked("Alex"); err != nil {
+		app.mu.Unlock()
+		t.Fatalf("load profile: %v", err)
+	}
+	app.shareExpiresAt = expired
+	if err := app.persistProfileLocked(); err != nil {
+		app.mu.Unlock()
+		t.Fatalf("persist profile: %v", err)
+	}
+	app.mu.Unlock()
+	if rr := serve(http.MethodGet, "/kiosk?token="+token, nil); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected an expired link to be rejected, got %d", rr.Code)
+	}
+	if rr := serve(http.MethodGet, "/api/v1/home-assistant?token="+token, nil); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected an expired link to stop the Home Assistant sensor, got %d", rr.Code)
+	}
+	if body := serve(http.MethodGet, "/settings/profile", nil).Body.String(); !strings.Contains(body, "This link expired after") {
+		t.Fatal("expected the expired link to be flagged")
+	}
+
+	serve(http.MethodPost, "/settings/share", url.Values{"action": {"generate"}})
+	app.mu.Lock()
+	if err := app.activateProfileLocked("Alex"); err != nil {
+		app.mu.Unlock()
+		t.Fatalf("load profile: %v", err)
+	}
+	regenerated, expiresAt := app.shareToken, app.shareExpiresAt
+	app.mu.Unlock()
+	if !expiresAt.IsZero() {
+		t.Fatalf("expected a regenerated link to drop the elapsed expiry, got %v", expiresAt)
+	}
+	if rr := serve(http.MethodGet, "/kiosk?token="+regenerated, nil); rr.Code != http.StatusOK {
+		t.Fatalf("expected the new link to work, got %d", rr.Code)
+	}
+	if body := serve(http.MethodGet, "/settings/profile", nil).Body.String(); !strings.Contains(body, "Viewed 1 time(s)") {
+		t.Fatal("expected the view count to start over for the new link")
+	}
+
+	if rr := serve(http.MethodGet, "/robots.txt", nil); !strings.Contains(rr.Body.String(), "Disallow: /") {
+		t.Fatalf("expected robots.txt to disallow crawling, got %q", rr.Body.String())
+	}
+}
+
+func TestShareLinkHasPreviewCardImage(t *testing.T) {
+	app := NewApp()
+	seedProfile(app)
+	now := time.Now()
+
+	app.mu.Lock()
+	app.shareToken = "kiosk-token"
+	app.items = []Item{
+		{ID: 1, Title: "Ready headphones", Price: "199", Status: "Ready to buy", PurchaseAllowedAt: now.Add(-time.Hour)},
+		{ID: 2, Title: "Soon keyboard", Price: "89", Status: "Waiting", PurchaseAllowedAt: now.Add(50 * time.Hour)},
+		{ID: 3, Title: "Secret gift", Price: "500", Status: "Waiting", Private: true, PurchaseAllowedAt: now.Add(time.Hour)},
+	}
+	app.mu.Unlock()
+
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/kiosk?token=kiosk-token&item=2", nil))
+	body := rr.Body.String()
+	for _, want := range []string{
+		`<meta property="og:title" content="Soon keyboard" />`,
+		`content="EUR 89 · Unlocks in 2d 1h"`,
+		`<meta property="og:image" content="http://localhost:8080/kiosk/card.png?token=kiosk-token&amp;item=2" />`,
+		`<meta name="twitter:card" content="summary_large_image" />`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected kiosk to contain %q, got %s", want, body)
+		}
+	}
+
+	for _, target := range []string{"/kiosk/card.png?token=kiosk-token", "/kiosk/card.png?token=kiosk-token&item=2"} {
+		rr := httptest.NewRecorder()
+		app.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		if rr.Code != http.StatusOK || rr.Header().Get("Content-Type") != "image/png" {
+			t.Fatalf("expected a png for %s, got %d %q", target, rr.Code, rr.Header().Get("Content-Type"))
+		}
+		img, err := png.Decode(rr.Body)
+		if err != nil {
+			t.Fatalf("decode card %s: %v", target, err)
+		}
+		if bounds := img.Bounds(); bounds.Dx() != 1200 || bounds.Dy() != 630 {
+			t.Fatalf("expected a 1200x630 card, got %v", bounds)
+		}
+	}
+
+	for _, target := range []string{"/kiosk/card.png?token=wrong", "/kiosk/card.png?token=kiosk-token&item=99"} {
+		rr := httptest.NewRecorder()
+		app.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		if rr.Code != http.StatusNotFound {
+			t.Fatalf("expected 404 for %s, got %d", target, rr.Code)
+		}
+	}
+
+	if lines := itemCardLines(maskPrivate(app.items[2]), "EUR", now); lines[1].Text != privateItemTitle || len(lines) != 3 {
+		t.Fatalf("expected the private item card without title or price, got %+v", lines)
+	}
+}
+
+func TestCardCountdownShowsTwoUnits(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for left, want := range map[time.Duration]string{
+		-time.Minute:                 "Ready to buy",
+		30 * time.Second:             "Unlocks in under a minute",
+		45 * time.Minute:             "Unlocks in 45m",
+		3*time.Hour + 20*time.Minute: "Unlocks in 3h 20m",
+		50*time.Hour + 5*time.Minute: "Unlocks in 2d 2h",
+	} {
+		if got := cardCountdown(now.Add(left), now); got != want {
+			t.Fatalf("cardCountdown(%v) = %q, want %q", left, got, want)
+		}
+	}
+}
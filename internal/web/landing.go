@@ -0,0 +1,94 @@
+package web
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"mvpapp/internal/domain"
+)
+
+// landingPaths are the pages the "last visited" landing page can return to.
+var landingPaths = []string{"/", "/items/new", "/insights", "/calendar", "/timeline", "/household"}
+
+// homeRequest is what the root handler knows about a request for "/".
+type homeRequest struct {
+	HasActiveProfile bool
+	HasProfile       bool
+	// WithinApp is set for requests from a page of the app or with dashboard filters, such as the
+	// Dashboard link; those always get the dashboard.
+	WithinApp   bool
+	LandingPage string
+	LastVisited string
+}
+
+// homeRoute decides where a request for "/" goes. It returns the path to redirect to, or "" to show the dashboard.
+func homeRoute(req homeRequest) string {
+	switch {
+	case !req.HasActiveProfile:
+		return "/switch-profile"
+	case !req.HasProfile:
+		return "/settings/profile"
+	case req.WithinApp:
+		return ""
+	}
+	switch domain.NormalizeLandingPage(req.LandingPage) {
+	case domain.LandingQuickAdd:
+		return "/items/new"
+	case domain.LandingLastVisited:
+		if req.LastVisited != "/" && slices.Contains(landingPaths, req.LastVisited) {
+			return req.LastVisited
+		}
+	}
+	return ""
+}
+
+// requestWithinApp reports whether r was sent from a page of this app or carries dashboard filters.
+func requestWithinApp(r *http.Request) bool {
+	if r.URL.RawQuery != "" {
+		return true
+	}
+	referer, err := url.Parse(r.Referer())
+	return err == nil && referer.Host != "" && referer.Host == r.Host
+}
+
+// rememberVisitMiddleware records the last page of landingPaths that each profile opened, for the
+// "last visited" landing page.
+func (a *App) rememberVisitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || !slices.Contains(landingPaths, r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		if recorder.status != http.StatusOK {
+			return
+		}
+
+		a := a.requestApp(r)
+		a.mu.LockContext(r.Context())
+		defer a.mu.Unlock()
+		if err := a.rememberVisitLocked(r.URL.Path); err != nil {
+			log.Printf("db error while saving last visited page: %v", err)
+		}
+	})
+}
+
+// rememberVisitLocked stores path as the active profile's last visited page. Only profiles that land on
+// their last visited page are tracked.
+func (a *App) rememberVisitLocked(path string) error {
+	if !a.profileExists || domain.NormalizeLandingPage(a.landingPage) != domain.LandingLastVisited || a.lastVisited == path {
+		return nil
+	}
+	a.lastVisited = path
+	if a.db == nil {
+		return nil
+	}
+	if _, err := a.db.Exec(`UPDATE profiles SET last_visited = ? WHERE user_id = ?`, path, a.currentUserIDLocked()); err != nil {
+		return fmt.Errorf("save last visited page: %w", err)
+	}
+	return nil
+}
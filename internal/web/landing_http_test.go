@@ -0,0 +1,21 @@
+package web_test
+
+import (
+	"net/http"
+	"testing"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestLastVisitedLandingReturnsToTheLastPage(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{Profiles: []webtest.Profile{{Name: "Alex"}}})
+	if _, err := h.DB.Exec(`UPDATE profiles SET landing_page = 'last-visited' WHERE user_id = 'Alex'`); err != nil {
+		t.Fatalf("set landing page: %v", err)
+	}
+	alex := h.As("Alex")
+
+	alex.Get("/calendar").ExpectStatus(http.StatusOK)
+	alex.Get("/").ExpectRedirect("/calendar")
+	alex.WithHeader("Referer", "http://example.com/calendar").Get("/").ExpectStatus(http.StatusOK)
+	alex.Get("/").ExpectStatus(http.StatusOK)
+}
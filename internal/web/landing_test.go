@@ -0,0 +1,30 @@
+package web
+
+import (
+	"testing"
+
+	"mvpapp/internal/domain"
+)
+
+func TestHomeRoutePolicy(t *testing.T) {
+	ready := homeRequest{HasActiveProfile: true, HasProfile: true}
+	cases := []struct {
+		name string
+		req  homeRequest
+		want string
+	}{
+		{"no active profile", homeRequest{}, "/switch-profile"},
+		{"profile not set up", homeRequest{HasActiveProfile: true, LandingPage: domain.LandingQuickAdd}, "/settings/profile"},
+		{"dashboard by default", ready, ""},
+		{"quick add", homeRequest{HasActiveProfile: true, HasProfile: true, LandingPage: domain.LandingQuickAdd}, "/items/new"},
+		{"quick add from within the app", homeRequest{HasActiveProfile: true, HasProfile: true, WithinApp: true, LandingPage: domain.LandingQuickAdd}, ""},
+		{"last visited", homeRequest{HasActiveProfile: true, HasProfile: true, LandingPage: domain.LandingLastVisited, LastVisited: "/insights"}, "/insights"},
+		{"last visited without history", homeRequest{HasActiveProfile: true, HasProfile: true, LandingPage: domain.LandingLastVisited}, ""},
+		{"last visited outside the landing pages", homeRequest{HasActiveProfile: true, HasProfile: true, LandingPage: domain.LandingLastVisited, LastVisited: "https://example.com"}, ""},
+	}
+	for _, tc := range cases {
+		if got := homeRoute(tc.req); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
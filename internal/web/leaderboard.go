@@ -0,0 +1,328 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+// leaderboardMonthLayout is the format of the leaderboard's month parameter.
+const leaderboardMonthLayout = "2006-01"
+
+// leaderboardEntry is one opted-in profile's decisions in a month. Private items count as decisions, but
+// their prices are left out, as on the household page.
+type leaderboardEntry struct {
+	Name     string
+	Currency string
+	Saved    domain.Money
+	Skipped  int
+	Bought   int
+}
+
+// Decided is the number of items bought or skipped in the month.
+func (e leaderboardEntry) Decided() int {
+	return e.Skipped + e.Bought
+}
+
+// SkipRate is the percentage of the month's decisions that were skips.
+func (e leaderboardEntry) SkipRate() int {
+	if e.Decided() == 0 {
+		return 0
+	}
+	return (e.Skipped*100 + e.Decided()/2) / e.Decided()
+}
+
+// leaderboardRanking ranks the profiles that use one currency by what they saved; amounts in different
+// currencies are not compared.
+type leaderboardRanking struct {
+	Currency string
+	Entries  []leaderboardEntry
+}
+
+type leaderboard struct {
+	MostSaved []leaderboardRanking
+	// BestSkipRate ranks the profiles that decided at least one item in the month.
+	BestSkipRate []leaderboardEntry
+}
+
+type leaderboardViewData struct {
+	Title           string
+	CurrentPath     string
+	ContentTemplate string
+	ScriptTemplate  string
+	ActiveProfile   string
+	Month           time.Time
+	PrevMonth       string
+	NextMonth       string
+	Board           leaderboard
+	Joined          bool
+	Digest          bool
+	// Unavailable is set for apps without a database, which have a single profile.
+	Unavailable bool
+	Error       string
+	Feedback    string
+}
+
+// parseLeaderboardMonth returns the first day of the requested month, or of the current month when raw
+// is empty or invalid.
+func parseLeaderboardMonth(raw string, now time.Time) time.Time {
+	current := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	month, err := time.ParseInLocation(leaderboardMonthLayout, strings.TrimSpace(raw), now.Location())
+	if err != nil || month.After(current) {
+		return current
+	}
+	return month
+}
+
+// buildLeaderboard ranks the members by the items they decided in the month starting at monthStart.
+func buildLeaderboard(itemsByMember map[string][]Item, currencies map[string]string, monthStart time.Time) leaderboard {
+	monthEnd := monthStart.AddDate(0, 1, 0)
+	saved := map[string][]leaderboardEntry{}
+	var board leaderboard
+	for name, items := range itemsByMember {
+		entry := leaderboardEntry{Name: name, Currency: profileCurrencyOrDefault(currencies[name])}
+		for _, item := range maskPrivateItems(items) {
+			decidedAt := itemDecisionTime(item)
+			if !item.Status.Decided() || decidedAt.Before(monthStart) || !decidedAt.Before(monthEnd) {
+				continue
+			}
+			switch item.Status {
+			case domain.StatusSkipped:
+				entry.Skipped++
+				if item.HasPriceValue {
+					entry.Saved += item.PriceCents
+				}
+			case domain.StatusBought:
+				entry.Bought++
+			}
+		}
+		saved[entry.Currency] = append(saved[entry.Currency], entry)
+		if entry.Decided() > 0 {
+			board.BestSkipRate = append(board.BestSkipRate, entry)
+		}
+	}
+
+	byName := func(a, b leaderboardEntry) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	}
+	for currency, entries := range saved {
+		slices.SortFunc(entries, func(a, b leaderboardEntry) int {
+			if a.Saved != b.Saved {
+				return int(b.Saved - a.Saved)
+			}
+			return byName(a, b)
+		})
+		board.MostSaved = append(board.MostSaved, leaderboardRanking{Currency: currency, Entries: entries})
+	}
+	// The ranking with the most members comes first.
+	slices.SortFunc(board.MostSaved, func(a, b leaderboardRanking) int {
+		if len(a.Entries) != len(b.Entries) {
+			return len(b.Entries) - len(a.Entries)
+		}
+		return strings.Compare(a.Currency, b.Currency)
+	})
+	slices.SortFunc(board.BestSkipRate, func(a, b leaderboardEntry) int {
+		if a.SkipRate() != b.SkipRate() {
+			return b.SkipRate() - a.SkipRate()
+		}
+		if a.Decided() != b.Decided() {
+			return b.Decided() - a.Decided()
+		}
+		return byName(a, b)
+	})
+	return board
+}
+
+// leaderboardMembersLocked returns the opted-in profiles and whether each wants the monthly digest.
+func (a *App) leaderboardMembersLocked() (map[string]bool, error) {
+	rows, err := a.db.Query(`SELECT user_id, digest FROM leaderboard_members`)
+	if err != nil {
+		return nil, fmt.Errorf("load leaderboard members: %w", err)
+	}
+	defer rows.Close()
+
+	members := map[string]bool{}
+	for rows.Next() {
+		var name string
+		var digest bool
+		if err := rows.Scan(&name, &digest); err != nil {
+			return nil, fmt.Errorf("scan leaderboard member: %w", err)
+		}
+		members[name] = digest
+	}
+	return members, rows.Err()
+}
+
+// leaderboardLocked builds the leaderboard of the month starting at monthStart from the opted-in profiles.
+func (a *App) leaderboardLocked(members map[string]bool, monthStart time.Time) (leaderboard, error) {
+	itemsByMember := make(map[string][]Item, len(members))
+	currencies := make(map[string]string, len(members))
+	for name := range members {
+		items, err := a.itemsForProfileLocked(name)
+		if err != nil {
+			return leaderboard{}, err
+		}
+		itemsByMember[name] = items
+		if currencies[name], err = a.currencyForProfileLocked(name); err != nil {
+			return leaderboard{}, err
+		}
+	}
+	return buildLeaderboard(itemsByMember, currencies, monthStart), nil
+}
+
+func (a *App) leaderboardPage(w http.ResponseWriter, r *http.Request) {
+	data := leaderboardViewData{Month: parseLeaderboardMonth(r.URL.Query().Get("month"), time.Now())}
+	switch r.URL.Query().Get("saved") {
+	case "join":
+		data.Feedback = "You joined the leaderboard."
+	case "leave":
+		data.Feedback = "You left the leaderboard."
+	case "digest":
+		data.Feedback = "Digest setting saved."
+	}
+	a.renderLeaderboard(w, r, data)
+}
+
+func (a *App) saveLeaderboard(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+	action := strings.TrimSpace(r.FormValue("action"))
+	digest := r.FormValue("digest") == "1"
+
+	a.mu.LockContext(r.Context())
+	if a.db == nil {
+		a.mu.Unlock()
+		http.Error(w, "the leaderboard needs the SQLite store", http.StatusBadRequest)
+		return
+	}
+	userID := a.currentUserIDLocked()
+	var err error
+	switch action {
+	case "join", "digest":
+		_, err = a.db.Exec(`INSERT INTO leaderboard_members(user_id, digest, joined_at) VALUES (?, ?, ?) ON CONFLICT(user_id) DO UPDATE SET digest = excluded.digest`,
+			userID, boolToInt(digest), time.Now().Format(time.RFC3339Nano))
+	case "leave":
+		_, err = a.db.Exec(`DELETE FROM leaderboard_members WHERE user_id = ?`, userID)
+	default:
+		a.mu.Unlock()
+		http.Error(w, "invalid action", http.StatusBadRequest)
+		return
+	}
+	a.mu.Unlock()
+	if err != nil {
+		log.Printf("db error while saving leaderboard membership: %v", err)
+		http.Error(w, "could not save leaderboard settings", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/leaderboard?saved="+action, http.StatusSeeOther)
+}
+
+func (a *App) renderLeaderboard(w http.ResponseWriter, r *http.Request, data leaderboardViewData) {
+	a.mu.LockContext(r.Context())
+	data.ActiveProfile = a.currentUserIDLocked()
+	var err error
+	if a.db == nil {
+		data.Unavailable = true
+	} else {
+		var members map[string]bool
+		members, err = a.leaderboardMembersLocked()
+		if err == nil {
+			data.Digest, data.Joined = members[data.ActiveProfile]
+			data.Board, err = a.leaderboardLocked(members, data.Month)
+		}
+	}
+	a.mu.Unlock()
+	if err != nil {
+		log.Printf("db error while loading leaderboard: %v", err)
+		data.Error = "Could not load the leaderboard."
+	}
+
+	data.PrevMonth = data.Month.AddDate(0, -1, 0).Format(leaderboardMonthLayout)
+	if next := data.Month.AddDate(0, 1, 0); !next.After(time.Now()) {
+		data.NextMonth = next.Format(leaderboardMonthLayout)
+	}
+	data.Title = "Leaderboard"
+	data.CurrentPath = "/leaderboard"
+	data.ContentTemplate = "leaderboard_content"
+	renderTemplate(w, a.templates, "layout", data)
+}
+
+// leaderboardDigestMessage summarizes a month's leaderboard for the digest, or returns "" when nobody
+// decided anything that month.
+func leaderboardDigestMessage(board leaderboard, month time.Time, link string) string {
+	if len(board.BestSkipRate) == 0 {
+		return ""
+	}
+	var lines []string
+	for _, ranking := range board.MostSaved {
+		if top := ranking.Entries[0]; top.Saved > 0 {
+			lines = append(lines, fmt.Sprintf("Most saved: %s (%s)", top.Name, formatMoney(top.Saved, top.Currency)))
+		}
+	}
+	top := board.BestSkipRate[0]
+	lines = append(lines, fmt.Sprintf("Best skip ratio: %s (%d%% of %d decisions)", top.Name, top.SkipRate(), top.Decided()))
+	return fmt.Sprintf("Leaderboard for %s\n%s\nFull board: %sleaderboard?month=%s", month.Format("January 2006"), strings.Join(lines, "\n"), link, month.Format(leaderboardMonthLayout))
+}
+
+// StartLeaderboardDigest registers the "leaderboard-digest" job, which sends last month's leaderboard over
+// ntfy to the members that asked for it, on the first of each month.
+func (a *App) StartLeaderboardDigest() {
+	a.registerJob("leaderboard-digest", "0 9 1 * *", 0, false, func(a *App, now time.Time) error {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		return a.sendLeaderboardDigestsLocked(now)
+	})
+}
+
+func (a *App) sendLeaderboardDigestsLocked(now time.Time) error {
+	if a.db == nil {
+		return nil
+	}
+	members, err := a.leaderboardMembersLocked()
+	if err != nil {
+		return err
+	}
+	month := parseLeaderboardMonth("", now).AddDate(0, -1, 0)
+	board, err := a.leaderboardLocked(members, month)
+	if err != nil {
+		return err
+	}
+	message := leaderboardDigestMessage(board, month, a.dashboardLink())
+	if message == "" {
+		return nil
+	}
+
+	var errs []error
+	for name, digest := range members {
+		if !digest {
+			continue
+		}
+		endpoint, topic, err := a.ntfySettingsForProfileLocked(name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if strings.TrimSpace(endpoint) == "" || strings.TrimSpace(topic) == "" {
+			continue
+		}
+		if a.notificationDryRunLocked(effectNtfy, endpoint+"/"+topic, message) {
+			a.recordDeliveryForLocked(name, notificationLeaderboard, effectNtfy, Item{}, 0, nil)
+			continue
+		}
+		code, err := a.postNtfyMessage(a.mu.Context(), endpoint, topic, "Impulse Pause leaderboard", message)
+		if err != nil {
+			log.Printf("ntfy request failed for the leaderboard digest of %q: %v", name, err)
+			errs = append(errs, fmt.Errorf("leaderboard digest for %q: %w", name, err))
+		}
+		a.recordDeliveryForLocked(name, notificationLeaderboard, effectNtfy, Item{}, code, err)
+	}
+	return errors.Join(errs...)
+}
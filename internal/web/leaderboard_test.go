@@ -0,0 +1,64 @@
+package web_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestLeaderboardRanksOnlyProfilesThatJoinedAndSendsTheDigest(t *testing.T) {
+	now := time.Now()
+	lastMonth := time.Date(now.Year(), now.Month()-1, 10, 12, 0, 0, 0, now.Location())
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}, {Name: "Sam"}, {Name: "Kim"}},
+		Items: []webtest.Item{
+			{Profile: "Alex", Title: "Drone", Price: 30000, Status: "Skipped", DecidedAt: lastMonth},
+			{Profile: "Alex", Title: "Socks", Price: 1000, Status: "Bought", DecidedAt: lastMonth},
+			{Profile: "Alex", Title: "Old phone", Price: 90000, Status: "Skipped", DecidedAt: lastMonth.AddDate(0, -2, 0)},
+			{Profile: "Sam", Title: "Watch", Price: 15000, Status: "Skipped", DecidedAt: lastMonth},
+			{Profile: "Kim", Title: "Boat", Price: 500000, Status: "Skipped", DecidedAt: lastMonth},
+		},
+	})
+	var messages []string
+	ntfy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		messages = append(messages, r.URL.Path+" "+string(body))
+	}))
+	defer ntfy.Close()
+	if _, err := h.DB.Exec(`UPDATE profiles SET ntfy_endpoint = ?, ntfy_topic = user_id`, ntfy.URL); err != nil {
+		t.Fatalf("set ntfy settings: %v", err)
+	}
+
+	alex, sam := h.As("Alex"), h.As("Sam")
+	alex.Get("/leaderboard").ExpectStatus(http.StatusOK).ExpectContains("Join the leaderboard", "Nobody joined yet.")
+	alex.PostForm("/leaderboard", url.Values{"action": {"join"}}).ExpectRedirect("/leaderboard?saved=join")
+	sam.PostForm("/leaderboard", url.Values{"action": {"join"}, "digest": {"1"}}).ExpectRedirect("/leaderboard?saved=join")
+
+	month := "/leaderboard?month=" + lastMonth.Format("2006-01")
+	page := alex.Get(month).ExpectStatus(http.StatusOK).
+		ExpectContains("Leave the leaderboard", "€ 300.00 from 1 skipped", "€ 150.00 from 1 skipped", "100% skipped of 1 decided", "50% skipped of 2 decided").
+		ExpectNotContains("Kim", "€ 5000.00", "€ 1200.00")
+	body := page.Body()
+	if strings.Index(body, "Alex</span> · € 300.00") > strings.Index(body, "Sam</span> · € 150.00") {
+		t.Fatalf("expected Alex to lead the savings ranking")
+	}
+	if strings.Index(body, "Sam</span> · 100%") > strings.Index(body, "Alex</span> · 50%") {
+		t.Fatalf("expected Sam to lead the skip ratio ranking")
+	}
+
+	if job, ok := h.App.RunJob("leaderboard-digest"); !ok || job.LastError != "" {
+		t.Fatalf("expected the digest job to run, got %+v", job)
+	}
+	if len(messages) != 1 || !strings.HasPrefix(messages[0], "/Sam ") || !strings.Contains(messages[0], "Most saved: Alex (€ 300.00)") || !strings.Contains(messages[0], "Best skip ratio: Sam (100% of 1 decisions)") {
+		t.Fatalf("expected only Sam to get the digest, got %q", messages)
+	}
+
+	alex.PostForm("/leaderboard", url.Values{"action": {"leave"}}).ExpectRedirect("/leaderboard?saved=leave")
+	alex.Get(month).ExpectContains("Join the leaderboard").ExpectNotContains("€ 300.00")
+}
@@ -0,0 +1,178 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maintenanceRun is the outcome of a database maintenance run, shown on the household page.
+type maintenanceRun struct {
+	StartedAt  time.Time
+	Duration   time.Duration
+	Manual     bool
+	Purged     int64
+	Compacted  int64
+	SizeBefore int64
+	SizeAfter  int64
+	Error      string
+}
+
+// StartBackgroundMaintenance registers the "maintenance" job, which runs the database maintenance every
+// interval, first after one interval so a restart does not vacuum right away. Apps without a database
+// have nothing to maintain.
+func (a *App) StartBackgroundMaintenance(interval time.Duration) {
+	if a.db == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+
+	a.registerJob("maintenance", "@every "+interval.String(), 0, false, func(a *App, now time.Time) error {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if run := a.runMaintenanceLocked(now, false); run.Error != "" {
+			return errors.New(run.Error)
+		}
+		return nil
+	})
+}
+
+// runMaintenanceLocked purges rows that can no longer be used, such as expired API replay keys, push
+// subscriptions, invites and notification log entries, compacts the item change log, then rebuilds the
+// indexes, refreshes the query planner statistics and vacuums the file. Requests go on meanwhile: they
+// keep reading the database, and their writes wait for VACUUM up to the busy timeout.
+func (a *App) runMaintenanceLocked(now time.Time, manual bool) maintenanceRun {
+	run := maintenanceRun{StartedAt: now, Manual: manual}
+	err := a.maintainDatabaseLocked(now, &run)
+	run.Duration = time.Since(now).Round(time.Millisecond)
+	if err != nil {
+		log.Printf("db error during maintenance: %v", err)
+		run.Error = err.Error()
+	}
+	a.lastMaintenance.Store(&run)
+	return run
+}
+
+func (a *App) maintainDatabaseLocked(now time.Time, run *maintenanceRun) error {
+	var err error
+	if run.SizeBefore, err = a.databaseSizeLocked(); err != nil {
+		return err
+	}
+
+	res, err := a.db.Exec(`DELETE FROM api_idempotency_keys WHERE created_at < ?`, now.Add(-idempotencyKeyTTL).Format(time.RFC3339Nano))
+	if err != nil {
+		return fmt.Errorf("purge idempotency keys: %w", err)
+	}
+	purged, _ := res.RowsAffected()
+	run.Purged += purged
+
+	expired, err := a.deleteExpiredPushSubscriptionsLocked(now)
+	if err != nil {
+		return err
+	}
+	run.Purged += expired
+
+	invites, err := a.purgeStaleInvitesLocked(now)
+	if err != nil {
+		return err
+	}
+	run.Purged += invites
+
+	deliveries, err := a.purgeDeliveryLogLocked(now)
+	if err != nil {
+		return err
+	}
+	run.Purged += deliveries
+
+	// Only the latest change per item and profile matters to GET /api/v1/changes.
+	res, err = a.db.Exec(`
+DELETE FROM item_changes
+WHERE seq NOT IN (SELECT MAX(seq) FROM item_changes GROUP BY item_id, user_id)`)
+	if err != nil {
+		return fmt.Errorf("compact item changes: %w", err)
+	}
+	run.Compacted, _ = res.RowsAffected()
+
+	for _, statement := range []string{`REINDEX`, `ANALYZE`, `VACUUM`} {
+		if _, err := a.db.Exec(statement); err != nil {
+			return fmt.Errorf("%s: %w", strings.ToLower(statement), err)
+		}
+	}
+	// In WAL mode the vacuumed pages sit in the log until a checkpoint writes them back.
+	if _, err := a.db.Exec(`PRAGMA wal_checkpoint(TRUNCATE)`); err != nil {
+		return fmt.Errorf("checkpoint: %w", err)
+	}
+
+	run.SizeAfter, err = a.databaseSizeLocked()
+	return err
+}
+
+func (a *App) deleteExpiredPushSubscriptionsLocked(now time.Time) (int64, error) {
+	rows, err := a.db.Query(`SELECT endpoint, expires_at FROM push_subscriptions WHERE expires_at != ''`)
+	if err != nil {
+		return 0, fmt.Errorf("list push subscriptions: %w", err)
+	}
+	var expired []string
+	for rows.Next() {
+		var endpoint, expiresAt string
+		if err := rows.Scan(&endpoint, &expiresAt); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scan push subscription: %w", err)
+		}
+		if at, err := time.Parse(time.RFC3339Nano, expiresAt); err == nil && !at.After(now) {
+			expired = append(expired, endpoint)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("list push subscriptions: %w", err)
+	}
+
+	for _, endpoint := range expired {
+		if _, err := a.db.Exec(`DELETE FROM push_subscriptions WHERE endpoint = ?`, endpoint); err != nil {
+			return 0, fmt.Errorf("delete push subscription: %w", err)
+		}
+	}
+	return int64(len(expired)), nil
+}
+
+func (a *App) databaseSizeLocked() (int64, error) {
+	var pages, pageSize int64
+	if err := a.db.QueryRow(`PRAGMA page_count`).Scan(&pages); err != nil {
+		return 0, fmt.Errorf("read page count: %w", err)
+	}
+	if err := a.db.QueryRow(`PRAGMA page_size`).Scan(&pageSize); err != nil {
+		return 0, fmt.Errorf("read page size: %w", err)
+	}
+	return pages * pageSize, nil
+}
+
+// runMaintenance is the household page's "Run now" button.
+func (a *App) runMaintenance(w http.ResponseWriter, r *http.Request) {
+	if !a.requireAdmin(w, r) {
+		return
+	}
+	if a.db == nil {
+		http.Error(w, "no database to maintain", http.StatusConflict)
+		return
+	}
+
+	a.mu.LockContext(r.Context())
+	a.runMaintenanceLocked(time.Now(), true)
+	a.mu.Unlock()
+
+	householdRedirect(w, r)
+}
+
+// formatBytes renders a file size in KB or MB.
+func formatBytes(n int64) string {
+	if n < 1024*1024 {
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	}
+	return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+}
@@ -0,0 +1,151 @@
+package web
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+var metricLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// metricsOptInsLocked returns the profiles that agreed to appear by name on /metrics.
+func (a *App) metricsOptInsLocked() (map[string]bool, error) {
+	if a.db == nil {
+		return map[string]bool{a.currentUserIDLocked(): a.metricsOptIn}, nil
+	}
+
+	rows, err := a.db.Query(`SELECT user_id FROM profiles WHERE metrics_opt_in = 1`)
+	if err != nil {
+		return nil, fmt.Errorf("list metrics opt-ins: %w", err)
+	}
+	defer rows.Close()
+
+	optIns := map[string]bool{}
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("scan metrics opt-in: %w", err)
+		}
+		optIns[userID] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate metrics opt-ins: %w", err)
+	}
+	return optIns, nil
+}
+
+// writeMetrics renders the household aggregates in the Prometheus text format, labelled with ISO currency
+// codes rather than the display symbols. Totals cover every profile;
+// the per-profile series only list profiles that opted in, so names never leak without consent.
+func writeMetrics(w io.Writer, members []householdMember, optIns map[string]bool) {
+	var open, ready int
+	saved := map[string]domain.Money{}
+	for _, member := range members {
+		open += member.Waiting + member.Ready
+		ready += member.Ready
+		saved[normalizeCurrency(member.Currency)] += member.SavedMonth
+	}
+	currencies := make([]string, 0, len(saved))
+	for currency := range saved {
+		currencies = append(currencies, currency)
+	}
+	slices.Sort(currencies)
+
+	fmt.Fprintln(w, "# HELP impulse_pause_open_items Items that are waiting or ready to buy, across all profiles.")
+	fmt.Fprintln(w, "# TYPE impulse_pause_open_items gauge")
+	fmt.Fprintf(w, "impulse_pause_open_items %d\n", open)
+	fmt.Fprintln(w, "# HELP impulse_pause_ready_items Items whose wait is over, across all profiles.")
+	fmt.Fprintln(w, "# TYPE impulse_pause_ready_items gauge")
+	fmt.Fprintf(w, "impulse_pause_ready_items %d\n", ready)
+	fmt.Fprintln(w, "# HELP impulse_pause_saved_month_to_date Prices of items skipped this month, in major currency units.")
+	fmt.Fprintln(w, "# TYPE impulse_pause_saved_month_to_date gauge")
+	for _, currency := range currencies {
+		fmt.Fprintf(w, "impulse_pause_saved_month_to_date{currency=%q} %s\n", currency, formatMetricMoney(saved[currency]))
+	}
+
+	var named []householdMember
+	for _, member := range members {
+		if optIns[member.Name] {
+			named = append(named, member)
+		}
+	}
+	fmt.Fprintln(w, "# HELP impulse_pause_profile_open_items Items that are waiting or ready to buy, per opted-in profile.")
+	fmt.Fprintln(w, "# TYPE impulse_pause_profile_open_items gauge")
+	for _, member := range named {
+		fmt.Fprintf(w, "impulse_pause_profile_open_items{profile=\"%s\"} %d\n", metricLabelEscaper.Replace(member.Name), member.Waiting+member.Ready)
+	}
+	fmt.Fprintln(w, "# HELP impulse_pause_profile_ready_items Items whose wait is over, per opted-in profile.")
+	fmt.Fprintln(w, "# TYPE impulse_pause_profile_ready_items gauge")
+	for _, member := range named {
+		fmt.Fprintf(w, "impulse_pause_profile_ready_items{profile=\"%s\"} %d\n", metricLabelEscaper.Replace(member.Name), member.Ready)
+	}
+	fmt.Fprintln(w, "# HELP impulse_pause_profile_saved_month_to_date Prices of items skipped this month, in major currency units, per opted-in profile.")
+	fmt.Fprintln(w, "# TYPE impulse_pause_profile_saved_month_to_date gauge")
+	for _, member := range named {
+		fmt.Fprintf(w, "impulse_pause_profile_saved_month_to_date{profile=\"%s\",currency=%q} %s\n", metricLabelEscaper.Replace(member.Name), normalizeCurrency(member.Currency), formatMetricMoney(member.SavedMonth))
+	}
+}
+
+func formatMetricMoney(amount domain.Money) string {
+	return strconv.FormatFloat(amount.Float(), 'f', -1, 64)
+}
+
+// metrics serves the savings gauges for Prometheus. It uses the admin token like the household overview.
+func (a *App) metrics(w http.ResponseWriter, r *http.Request) {
+	if !a.requireAdmin(w, r) {
+		return
+	}
+
+	a.mu.LockContext(r.Context())
+	a.promoteReadyItemsLocked(time.Now())
+	itemsByProfile, err := a.itemsByProfileLocked()
+	currencies := map[string]string{}
+	if err == nil {
+		for name := range itemsByProfile {
+			if currencies[name], err = a.currencyForProfileLocked(name); err != nil {
+				break
+			}
+		}
+	}
+	var optIns map[string]bool
+	if err == nil {
+		optIns, err = a.metricsOptInsLocked()
+	}
+	a.mu.Unlock()
+	if err != nil {
+		log.Printf("db error while collecting metrics: %v", err)
+		http.Error(w, "could not collect metrics", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	writeMetrics(w, buildHouseholdMembers(itemsByProfile, currencies, time.Now()), optIns)
+}
+
+func (a *App) saveMetricsOptIn(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	a.mu.LockContext(r.Context())
+	a.metricsOptIn = r.FormValue("metrics_opt_in") == "1"
+	if err := a.persistProfileLocked(); err != nil {
+		a.mu.Unlock()
+		log.Printf("db error while saving metrics opt-in: %v", err)
+		http.Error(w, "could not save metrics settings", http.StatusInternalServerError)
+		return
+	}
+	a.publishProfileUpdatedLocked("metrics", r)
+	a.mu.Unlock()
+
+	http.Redirect(w, r, "/settings/data?saved=metrics", http.StatusSeeOther)
+}
@@ -0,0 +1,35 @@
+package web_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestMetricsRequireAdminTokenAndProfileOptIn(t *testing.T) {
+	now := time.Now()
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}, {Name: "Sam"}},
+		Items: []webtest.Item{
+			{Profile: "Alex", Title: "Headphones", PurchaseAllowedAt: now.Add(time.Hour)},
+			{Profile: "Sam", Title: "Bike", Status: "Skipped", Price: 12000, PurchaseAllowedAt: now, DecidedAt: now},
+		},
+	})
+	h.Anonymous().Get("/metrics").ExpectStatus(http.StatusNotFound)
+	h.App.SetAdminToken("s3cret")
+	h.Anonymous().Get("/metrics").ExpectStatus(http.StatusUnauthorized)
+
+	scrape := h.Anonymous().WithHeader("Authorization", "Bearer s3cret")
+	scrape.Get("/metrics").
+		ExpectStatus(http.StatusOK).
+		ExpectContains("impulse_pause_open_items 1", `impulse_pause_saved_month_to_date{currency="EUR"} 120`).
+		ExpectNotContains(`profile="Sam"`)
+
+	h.As("Sam").PostForm("/settings/data/metrics", url.Values{"metrics_opt_in": {"1"}}).ExpectRedirect("/settings/data?saved=metrics")
+	scrape.Get("/metrics").
+		ExpectContains(`impulse_pause_profile_saved_month_to_date{profile="Sam",currency="EUR"} 120`).
+		ExpectNotContains(`profile="Alex"`)
+}
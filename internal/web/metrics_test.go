@@ -0,0 +1,35 @@
+package web
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteMetricsOnlyNamesOptedInProfiles(t *testing.T) {
+	members := []householdMember{
+		{Name: "Alex", Waiting: 2, Ready: 1, SavedMonth: 4999, Currency: "€"},
+		{Name: `Sam "S"`, Waiting: 1, Ready: 0, SavedMonth: 1000, Currency: "€"},
+		{Name: "Kim", Waiting: 0, Ready: 3, SavedMonth: 250, Currency: "$"},
+	}
+
+	var out strings.Builder
+	writeMetrics(&out, members, map[string]bool{"Alex": true, `Sam "S"`: true})
+	body := out.String()
+
+	for _, want := range []string{
+		"# TYPE impulse_pause_open_items gauge\nimpulse_pause_open_items 7\n",
+		"impulse_pause_ready_items 4\n",
+		`impulse_pause_saved_month_to_date{currency="EUR"} 59.99`,
+		`impulse_pause_saved_month_to_date{currency="USD"} 2.5`,
+		`impulse_pause_profile_open_items{profile="Alex"} 3`,
+		`impulse_pause_profile_ready_items{profile="Sam \"S\""} 0`,
+		`impulse_pause_profile_saved_month_to_date{profile="Alex",currency="EUR"} 49.99`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected metrics to contain %q, got:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, `profile="Kim"`) {
+		t.Fatalf("expected profile without opt-in to stay anonymous, got:\n%s", body)
+	}
+}
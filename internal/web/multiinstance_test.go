@@ -0,0 +1,198 @@
+package web
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// sendAs serves a request for app with the active_profile cookie set to profile.
+func sendAs(t *testing.T, app *App, profile, method, target string, form url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+	var req *http.Request
+	if form != nil {
+		req = httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		req = httptest.NewRequest(method, target, nil)
+	}
+	req.AddCookie(&http.Cookie{Name: "active_profile", Value: profile})
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+	if rr.Code >= http.StatusBadRequest {
+		t.Errorf("%s %s as %s: status %d: %s", method, target, profile, rr.Code, rr.Body.String())
+	}
+	return rr
+}
+
+// openSharedDB opens the database at path next to the apps using it, to check what they stored.
+func openSharedDB(t *testing.T, path string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestTwoInstancesOverOneDatabaseShowTheSameState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shared.sqlite")
+	first, err := NewAppWithSQLite(path)
+	if err != nil {
+		t.Fatalf("new first app: %v", err)
+	}
+	defer first.Close()
+	second, err := NewAppWithSQLite(path)
+	if err != nil {
+		t.Fatalf("new second app: %v", err)
+	}
+	defer second.Close()
+
+	dashboard := func(app *App, profile string) string {
+		t.Helper()
+		return sendAs(t, app, profile, http.MethodGet, "/", nil).Body.String()
+	}
+
+	for _, name := range []string{"Alex", "Sam"} {
+		sendAs(t, first, name, http.MethodPost, "/switch-profile", url.Values{"profile_name": {name}})
+	}
+	sendAs(t, first, "Alex", http.MethodPost, "/items/new", url.Values{"title": {"Standing desk"}, "wait_preset": {"7d"}})
+
+	// The second instance last served Sam, and still shows Alex's item created on the first one.
+	if body := dashboard(second, "Sam"); strings.Contains(body, "Standing desk") {
+		t.Fatalf("expected Sam's dashboard without Alex's item")
+	}
+	if body := dashboard(second, "Alex"); !strings.Contains(body, "Standing desk") {
+		t.Fatalf("expected the second instance to show the item created on the first")
+	}
+
+	// Changes on the second instance show on the first, which has Alex loaded already.
+	sendAs(t, second, "Alex", http.MethodPost, "/items/new", url.Values{"title": {"Reading lamp"}, "wait_preset": {"24h"}})
+	sendAs(t, second, "Alex", http.MethodPost, "/settings/profile", url.Values{"profile_name": {"Alex"}, "hourly_wage": {"42"}})
+	if body := dashboard(first, "Alex"); !strings.Contains(body, "Standing desk") || !strings.Contains(body, "Reading lamp") {
+		t.Fatalf("expected the first instance to show both items")
+	}
+	if body := sendAs(t, first, "Alex", http.MethodGet, "/settings/profile", nil).Body.String(); !strings.Contains(body, `value="42"`) {
+		t.Fatalf("expected the first instance to show the hourly wage saved on the second")
+	}
+
+	// Items created on either instance get distinct ids, and a delete on one is gone on the other.
+	rows, err := openSharedDB(t, path).Query(`SELECT id, title FROM items WHERE user_id = 'Alex'`)
+	if err != nil {
+		t.Fatalf("query items: %v", err)
+	}
+	ids := map[int]string{}
+	for rows.Next() {
+		var id int
+		var title string
+		if err := rows.Scan(&id, &title); err != nil {
+			t.Fatalf("scan item: %v", err)
+		}
+		ids[id] = title
+	}
+	rows.Close()
+	if len(ids) != 2 {
+		t.Fatalf("expected two items with distinct ids, got %v", ids)
+	}
+	for id, title := range ids {
+		if title == "Standing desk" {
+			sendAs(t, first, "Alex", http.MethodPost, "/items/delete", url.Values{"item_id": {strconv.Itoa(id)}})
+		}
+	}
+	if body := dashboard(second, "Alex"); strings.Contains(body, "Standing desk") || !strings.Contains(body, "Reading lamp") {
+		t.Fatalf("expected the second instance to drop the item deleted on the first")
+	}
+}
+
+func TestConcurrentRequestsForTwoProfilesKeepTheirItemsApart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.sqlite")
+	app, err := NewAppWithSQLite(path)
+	if err != nil {
+		t.Fatalf("new app: %v", err)
+	}
+	defer app.Close()
+	profiles := []string{"Alex", "Sam"}
+	for _, name := range profiles {
+		sendAs(t, app, name, http.MethodPost, "/switch-profile", url.Values{"profile_name": {name}})
+	}
+
+	const perProfile = 60
+	var wg sync.WaitGroup
+	for _, name := range profiles {
+		for worker := range 4 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := worker; i < perProfile; i += 4 {
+					sendAs(t, app, name, http.MethodPost, "/items/new", url.Values{"title": {fmt.Sprintf("%s item %d", name, i)}, "wait_preset": {"24h"}})
+					sendAs(t, app, name, http.MethodGet, "/", nil)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	rows, err := openSharedDB(t, path).Query(`SELECT user_id, title FROM items`)
+	if err != nil {
+		t.Fatalf("query items: %v", err)
+	}
+	defer rows.Close()
+	count := 0
+	for rows.Next() {
+		var owner, title string
+		if err := rows.Scan(&owner, &title); err != nil {
+			t.Fatalf("scan item: %v", err)
+		}
+		count++
+		if !strings.HasPrefix(title, owner+" ") {
+			t.Errorf("%q was saved for %s", title, owner)
+		}
+	}
+	if count != perProfile*len(profiles) {
+		t.Fatalf("expected %d items, got %d", perProfile*len(profiles), count)
+	}
+}
+
+func TestRequestsAreServedWhileAJobHoldsItsApp(t *testing.T) {
+	app, cleanup := newSQLiteTestApp(t)
+	defer cleanup()
+	sendAs(t, app, "Alex", http.MethodPost, "/switch-profile", url.Values{"profile_name": {"Alex"}})
+
+	started, release := make(chan struct{}), make(chan struct{})
+	app.registerJob("slow", "@every 1h", 0, false, func(a *App, now time.Time) error {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		close(started)
+		<-release
+		return nil
+	})
+	ran := make(chan struct{})
+	go func() {
+		app.RunJob("slow")
+		close(ran)
+	}()
+	<-started
+
+	served := make(chan struct{})
+	go func() {
+		sendAs(t, app, "Alex", http.MethodPost, "/items/new", url.Values{"title": {"Standing desk"}, "wait_preset": {"24h"}})
+		close(served)
+	}()
+	select {
+	case <-served:
+	case <-time.After(5 * time.Second):
+		t.Error("expected the request to be served while the job runs")
+	}
+	close(release)
+	<-ran
+	<-served
+}
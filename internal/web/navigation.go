@@ -0,0 +1,99 @@
+package web
+
+// routeMeta describes a page for the primary navigation and breadcrumbs.
+// Pages link to their Parent; pages with InNav appear in the top navigation.
+type routeMeta struct {
+	Path   string
+	Title  string
+	Parent string
+	InNav  bool
+}
+
+type breadcrumb struct {
+	Title   string
+	Path    string
+	Current bool
+}
+
+// pageRoutes lists every layout page. Navigation order follows this list.
+var pageRoutes = []routeMeta{
+	{Path: "/", Title: "Dashboard", InNav: true},
+	{Path: "/items/new", Title: "Add item", Parent: "/", InNav: true},
+	{Path: "/following", Title: "Following", Parent: "/"},
+	{Path: "/items/{id}/edit", Title: "Edit item", Parent: "/"},
+	{Path: "/insights", Title: "Insights", Parent: "/", InNav: true},
+	{Path: "/calendar", Title: "Calendar", Parent: "/", InNav: true},
+	{Path: "/timeline", Title: "Timeline", Parent: "/insights"},
+	{Path: "/leaderboard", Title: "Leaderboard", Parent: "/insights"},
+	{Path: "/settings/profile", Title: "Settings", Parent: "/", InNav: true},
+	{Path: "/settings/tags", Title: "Tags", Parent: "/settings/profile", InNav: true},
+	{Path: "/settings/data", Title: "Data & retention", Parent: "/settings/profile"},
+	{Path: "/settings/exports", Title: "Exports", Parent: "/settings/profile"},
+	{Path: "/settings/reconcile", Title: "Reconcile purchases", Parent: "/settings/profile"},
+	{Path: "/settings/home-assistant", Title: "Home Assistant", Parent: "/settings/profile"},
+	{Path: "/settings/approvals", Title: "Approvals", Parent: "/settings/profile"},
+	{Path: "/settings/blackouts", Title: "Blackout periods", Parent: "/settings/profile"},
+	{Path: "/settings/routing", Title: "Notification routing", Parent: "/settings/profile"},
+	{Path: "/settings/rules", Title: "Rules import/export", Parent: "/settings/profile"},
+	{Path: "/settings/templates", Title: "Item templates", Parent: "/settings/profile"},
+	{Path: "/settings/wait-check", Title: "Wait rule check", Parent: "/settings/profile"},
+	{Path: "/settings/notification-log", Title: "Notification log", Parent: "/settings/profile"},
+	{Path: "/switch-profile", Title: "Choose profile", Parent: "/"},
+	{Path: "/onboarding", Title: "Set up profile", Parent: "/"},
+	{Path: "/household", Title: "Household", Parent: "/"},
+	{Path: "/invite/{token}", Title: "Accept invite", Parent: "/"},
+	{Path: "/about", Title: "About", Parent: "/", InNav: true},
+}
+
+func lookupRoute(path string) (routeMeta, bool) {
+	for _, route := range pageRoutes {
+		if route.Path == path {
+			return route, true
+		}
+	}
+	return routeMeta{}, false
+}
+
+// navLinks returns the pages shown in the primary navigation.
+func navLinks() []routeMeta {
+	links := make([]routeMeta, 0, len(pageRoutes))
+	for _, route := range pageRoutes {
+		if route.InNav {
+			links = append(links, route)
+		}
+	}
+	return links
+}
+
+// navSection returns the navigation entry to highlight for a page: the page itself or its closest ancestor in the navigation.
+func navSection(path string) string {
+	for route, ok := lookupRoute(path); ok; route, ok = lookupRoute(route.Parent) {
+		if route.InNav {
+			return route.Path
+		}
+	}
+	return ""
+}
+
+// breadcrumbs returns the trail from the dashboard to the page. Pages in the primary navigation have no trail.
+func breadcrumbs(path string) []breadcrumb {
+	if page, ok := lookupRoute(path); !ok || page.InNav {
+		return nil
+	}
+	var trail []breadcrumb
+	for route, ok := lookupRoute(path); ok; route, ok = lookupRoute(route.Parent) {
+		trail = append([]breadcrumb{{Title: route.Title, Path: route.Path, Current: route.Path == path}}, trail...)
+	}
+	return trail
+}
+
+// childPages returns the sub-pages of a page that are not in the primary navigation, for in-page link lists.
+func childPages(path string) []routeMeta {
+	var children []routeMeta
+	for _, route := range pageRoutes {
+		if route.Parent == path && !route.InNav {
+			children = append(children, route)
+		}
+	}
+	return children
+}
@@ -0,0 +1,49 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBreadcrumbsFollowRouteParents(t *testing.T) {
+	trail := breadcrumbs("/settings/exports")
+	if len(trail) != 3 {
+		t.Fatalf("expected 3 breadcrumbs, got %+v", trail)
+	}
+	if trail[0].Path != "/" || trail[1].Path != "/settings/profile" || trail[2].Title != "Exports" || !trail[2].Current {
+		t.Fatalf("unexpected breadcrumbs: %+v", trail)
+	}
+	if got := breadcrumbs("/insights"); got != nil {
+		t.Fatalf("expected no breadcrumbs for navigation pages, got %+v", got)
+	}
+	if got := breadcrumbs("/unknown"); got != nil {
+		t.Fatalf("expected no breadcrumbs for unregistered pages, got %+v", got)
+	}
+}
+
+func TestNavSectionHighlightsClosestNavigationAncestor(t *testing.T) {
+	tests := map[string]string{"/items/{id}/edit": "/", "/settings/approvals": "/settings/profile", "/settings/tags": "/settings/tags", "/unknown": ""}
+	for path, want := range tests {
+		if got := navSection(path); got != want {
+			t.Fatalf("navSection(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestLayoutRendersBreadcrumbsForSubPages(t *testing.T) {
+	app := NewApp()
+	seedProfile(app)
+
+	req := httptest.NewRequest(http.MethodGet, "/settings/data", nil)
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+
+	body := rr.Body.String()
+	for _, want := range []string{`aria-label="Breadcrumb"`, `<a href="/settings/profile">Settings</a>`, `<span aria-current="page">Data &amp; retention</span>`, `class="nav-link active" href="/settings/profile" aria-current="page"`} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in settings sub-page", want)
+		}
+	}
+}
@@ -0,0 +1,458 @@
+package web
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/pbkdf2"
+
+	"mvpapp/internal/domain"
+)
+
+// Notes can be encrypted at rest with a passphrase per profile. The passphrase is never stored: the
+// profile keeps a random salt and a sealed check value, and the derived key only lives in memory while
+// the notes are unlocked, until they are locked again or the server restarts.
+const (
+	sealedNotePrefix       = "enc:v1:"
+	noteKeyIterations      = 600000
+	noteKeySaltSize        = 16
+	noteKeyCheckPlaintext  = "impulse-pause notes"
+	minNotePassphraseRunes = 8
+)
+
+// isSealedNote reports whether note is stored encrypted.
+func isSealedNote(note string) bool {
+	return strings.HasPrefix(note, sealedNotePrefix)
+}
+
+func deriveNoteKey(passphrase string, salt []byte) []byte {
+	return pbkdf2.Key([]byte(passphrase), salt, noteKeyIterations, 32, sha256.New)
+}
+
+func sealNote(key []byte, note string) (string, error) {
+	gcm, err := noteCipher(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generate note nonce: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(note), nil)
+	return sealedNotePrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func openNote(key []byte, note string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(note, sealedNotePrefix))
+	if err != nil {
+		return "", fmt.Errorf("decode sealed note: %w", err)
+	}
+	gcm, err := noteCipher(key)
+	if err != nil {
+		return "", err
+	}
+	if len(raw) < gcm.NonceSize() {
+		return "", errors.New("sealed note is too short")
+	}
+	plain, err := gcm.Open(nil, raw[:gcm.NonceSize()], raw[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("open sealed note: %w", err)
+	}
+	return string(plain), nil
+}
+
+func noteCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("note cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// newNoteKey derives a key from passphrase with a fresh salt and returns it with the salt and check
+// value to store on the profile.
+func newNoteKey(passphrase string) (key []byte, salt, check string, err error) {
+	rawSalt := make([]byte, noteKeySaltSize)
+	if _, err := rand.Read(rawSalt); err != nil {
+		return nil, "", "", fmt.Errorf("generate note salt: %w", err)
+	}
+	key = deriveNoteKey(passphrase, rawSalt)
+	check, err = sealNote(key, noteKeyCheckPlaintext)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return key, base64.StdEncoding.EncodeToString(rawSalt), check, nil
+}
+
+func noteFormError(field, message string) error {
+	return &domain.ValidationError{Fields: []domain.FieldError{{Field: field, Message: message}}}
+}
+
+func validateNewNotePassphrase(passphrase, confirmation string) error {
+	if len([]rune(passphrase)) < minNotePassphraseRunes {
+		return noteFormError("new_passphrase", fmt.Sprintf("Please use a passphrase of at least %d characters.", minNotePassphraseRunes))
+	}
+	if passphrase != confirmation {
+		return noteFormError("confirm_passphrase", "The passphrases do not match.")
+	}
+	return nil
+}
+
+func (a *App) noteEncryptionEnabledLocked() bool {
+	return a.noteKeyCheck != ""
+}
+
+// noteKeyring holds the note keys of unlocked profiles. They are never persisted. Every request's app
+// shares it, so it has its own lock.
+type noteKeyring struct {
+	mu     sync.Mutex
+	byUser map[string][]byte
+}
+
+func (k *noteKeyring) get(userID string) []byte {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.byUser[userID]
+}
+
+func (k *noteKeyring) set(userID string, key []byte) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.byUser == nil {
+		k.byUser = map[string][]byte{}
+	}
+	k.byUser[userID] = key
+}
+
+func (k *noteKeyring) forget(userID string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	delete(k.byUser, userID)
+}
+
+// rename moves the key of a renamed profile to its new name.
+func (k *noteKeyring) rename(oldUserID, newUserID string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if key, ok := k.byUser[oldUserID]; ok {
+		delete(k.byUser, oldUserID)
+		k.byUser[newUserID] = key
+	}
+}
+
+func (a *App) notesUnlockedLocked() bool {
+	return a.noteKeys.get(a.currentUserIDLocked()) != nil
+}
+
+// noteKeyFromPassphrase derives a profile's key from its stored salt and checks it against the stored
+// check value.
+func noteKeyFromPassphrase(passphrase, salt, check string) ([]byte, error) {
+	rawSalt, err := base64.StdEncoding.DecodeString(salt)
+	if err != nil {
+		return nil, fmt.Errorf("decode note salt: %w", err)
+	}
+	key := deriveNoteKey(passphrase, rawSalt)
+	if opened, err := openNote(key, check); err != nil || opened != noteKeyCheckPlaintext {
+		return nil, noteFormError("passphrase", "That passphrase is not right.")
+	}
+	return key, nil
+}
+
+func (a *App) setNoteKeyLocked(key []byte) {
+	a.noteKeys.set(a.currentUserIDLocked(), key)
+}
+
+// sealNoteLocked returns the note of item as it is stored. Notes of items owned by the active profile are
+// sealed while encryption is on; notes that are already sealed, such as a locked note sent back
+// unchanged by the edit form, are kept as they are.
+func (a *App) sealNoteLocked(item Item) (string, error) {
+	userID := a.currentUserIDLocked()
+	if !a.noteEncryptionEnabledLocked() || item.Note == "" || isSealedNote(item.Note) || item.OwnerID != userID {
+		return item.Note, nil
+	}
+	key := a.noteKeys.get(userID)
+	if key == nil {
+		return "", noteFormError("note", "Notes are encrypted. Unlock them under Data settings to add or change a note.")
+	}
+	return sealNote(key, item.Note)
+}
+
+// openNotesLocked decrypts the notes userID owns in items if that profile is unlocked. Notes that do not
+// open stay sealed.
+func (a *App) openNotesLocked(userID string, items []Item) {
+	key := a.noteKeys.get(userID)
+	if key == nil {
+		return
+	}
+	for i := range items {
+		if items[i].OwnerID != userID || !isSealedNote(items[i].Note) {
+			continue
+		}
+		if note, err := openNote(key, items[i].Note); err == nil {
+			items[i].Note = note
+		}
+	}
+}
+
+// reloadItemsLocked reads the active profile's items again so their notes match the current key.
+func (a *App) reloadItemsLocked() error {
+	if a.db == nil {
+		return nil
+	}
+	userID := a.currentUserIDLocked()
+	items, err := queryItemsForUser(a.db, userID)
+	if err != nil {
+		return err
+	}
+	a.openNotesLocked(userID, items)
+	a.items = items
+	return nil
+}
+
+// rewriteNotesLocked re-encrypts every note the active profile owns in one transaction: sealed notes are
+// opened with from, and all notes are sealed with to, or stored as plain text if to is nil. The new salt
+// and check value are stored with them, so a failure leaves the old key working. The transaction fails if
+// another request changed the key since this one loaded the profile.
+func (a *App) rewriteNotesLocked(from, to []byte, salt, check string) error {
+	if a.db == nil {
+		a.noteKeySalt, a.noteKeyCheck = salt, check
+		return nil
+	}
+	if !a.profileExists {
+		if err := a.persistProfileLocked(); err != nil {
+			return err
+		}
+	}
+
+	userID := a.currentUserIDLocked()
+	tx, err := a.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin note rewrite tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	rows, err := tx.Query(`SELECT id, note FROM items WHERE user_id = ? AND note != ''`, userID)
+	if err != nil {
+		return fmt.Errorf("load notes: %w", err)
+	}
+	notes := map[int]string{}
+	for rows.Next() {
+		var id int
+		var note string
+		if err := rows.Scan(&id, &note); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan note: %w", err)
+		}
+		notes[id] = note
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate notes: %w", err)
+	}
+
+	for id, note := range notes {
+		if isSealedNote(note) {
+			if from == nil {
+				return fmt.Errorf("note of item %d is sealed with an unknown key", id)
+			}
+			if note, err = openNote(from, note); err != nil {
+				return fmt.Errorf("note of item %d: %w", id, err)
+			}
+		}
+		if to != nil {
+			if note, err = sealNote(to, note); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.Exec(`UPDATE items SET note = ? WHERE id = ?`, note, id); err != nil {
+			return fmt.Errorf("update note: %w", err)
+		}
+	}
+	result, err := tx.Exec(`UPDATE profiles SET note_key_salt = ?, note_key_check = ? WHERE user_id = ? AND note_key_salt = ? AND note_key_check = ?`, salt, check, userID, a.noteKeySalt, a.noteKeyCheck)
+	if err != nil {
+		return fmt.Errorf("update note key: %w", err)
+	}
+	if updated, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("update note key: %w", err)
+	} else if updated == 0 {
+		return errNoteKeyChanged
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit note rewrite tx: %w", err)
+	}
+	a.noteKeySalt, a.noteKeyCheck = salt, check
+	return nil
+}
+
+// noteKeyChange holds the keys a note encryption action needs. Deriving a key is slow on purpose, so
+// they are derived before the state lock is taken, for the salt and check value the profile had then.
+type noteKeyChange struct {
+	salt, check string
+	// key is derived from the current passphrase, for unlock, rotate and disable.
+	key []byte
+	// newKey is derived from the new passphrase with newSalt, for enable and rotate.
+	newKey            []byte
+	newSalt, newCheck string
+}
+
+func deriveNoteKeyChange(action, passphrase, newPassphrase, confirmation, salt, check string) (noteKeyChange, error) {
+	change := noteKeyChange{salt: salt, check: check}
+	switch action {
+	case "enable":
+		if check != "" {
+			return change, noteFormError("action", "Notes are already encrypted.")
+		}
+	case "unlock", "rotate", "disable":
+		if check == "" {
+			return change, noteFormError("action", "Notes are not encrypted.")
+		}
+		key, err := noteKeyFromPassphrase(passphrase, salt, check)
+		if err != nil {
+			return change, err
+		}
+		change.key = key
+	}
+	if action == "enable" || action == "rotate" {
+		if err := validateNewNotePassphrase(newPassphrase, confirmation); err != nil {
+			return change, err
+		}
+		key, newSalt, newCheck, err := newNoteKey(newPassphrase)
+		if err != nil {
+			return change, err
+		}
+		change.newKey, change.newSalt, change.newCheck = key, newSalt, newCheck
+	}
+	return change, nil
+}
+
+// errNoteKeyChanged reports that another request changed the profile's key while this one derived its keys.
+var errNoteKeyChanged = noteFormError("action", "Note encryption was changed in the meantime. Please try again.")
+
+// checkNoteKeyChangeLocked fails if the profile's key changed after the keys of change were derived. The
+// stored key is read again, as requests for the same profile each load it into an app of their own.
+func (a *App) checkNoteKeyChangeLocked(change noteKeyChange) error {
+	salt, check := a.noteKeySalt, a.noteKeyCheck
+	if a.db != nil && a.profileExists {
+		err := a.db.QueryRow(`SELECT note_key_salt, note_key_check FROM profiles WHERE user_id = ?`, a.currentUserIDLocked()).Scan(&salt, &check)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("load note key: %w", err)
+		}
+	}
+	if salt != change.salt || check != change.check {
+		return errNoteKeyChanged
+	}
+	return nil
+}
+
+func (a *App) enableNoteEncryptionLocked(change noteKeyChange) error {
+	if err := a.rewriteNotesLocked(nil, change.newKey, change.newSalt, change.newCheck); err != nil {
+		return err
+	}
+	a.setNoteKeyLocked(change.newKey)
+	return nil
+}
+
+func (a *App) unlockNotesLocked(change noteKeyChange) error {
+	a.setNoteKeyLocked(change.key)
+	a.openNotesLocked(a.currentUserIDLocked(), a.items)
+	return nil
+}
+
+func (a *App) lockNotesLocked() error {
+	a.noteKeys.forget(a.currentUserIDLocked())
+	return a.reloadItemsLocked()
+}
+
+// rotateNoteKeyLocked re-encrypts all notes under a new salt and passphrase, which may be the same
+// passphrase as before.
+func (a *App) rotateNoteKeyLocked(change noteKeyChange) error {
+	if err := a.rewriteNotesLocked(change.key, change.newKey, change.newSalt, change.newCheck); err != nil {
+		return err
+	}
+	a.setNoteKeyLocked(change.newKey)
+	return a.reloadItemsLocked()
+}
+
+func (a *App) disableNoteEncryptionLocked(change noteKeyChange) error {
+	if err := a.rewriteNotesLocked(change.key, nil, "", ""); err != nil {
+		return err
+	}
+	a.noteKeys.forget(a.currentUserIDLocked())
+	return a.reloadItemsLocked()
+}
+
+// applyNoteKeyChange runs action with the keys derived for it.
+func (a *App) applyNoteKeyChange(r *http.Request, action string, change noteKeyChange) error {
+	a.mu.LockContext(r.Context())
+	defer a.mu.Unlock()
+	if action != "lock" {
+		if err := a.checkNoteKeyChangeLocked(change); err != nil {
+			return err
+		}
+	}
+	var err error
+	switch action {
+	case "enable":
+		err = a.enableNoteEncryptionLocked(change)
+	case "unlock":
+		err = a.unlockNotesLocked(change)
+	case "lock":
+		err = a.lockNotesLocked()
+	case "rotate":
+		err = a.rotateNoteKeyLocked(change)
+	case "disable":
+		err = a.disableNoteEncryptionLocked(change)
+	default:
+		err = noteFormError("action", "Please choose a valid action.")
+	}
+	if err == nil && action != "unlock" && action != "lock" {
+		a.publishProfileUpdatedLocked("note encryption", r)
+	}
+	return err
+}
+
+func (a *App) saveNoteEncryption(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	passphrase := r.FormValue("passphrase")
+	newPassphrase := r.FormValue("new_passphrase")
+	confirmation := r.FormValue("confirm_passphrase")
+	action := r.FormValue("action")
+
+	// Keys are derived before the state lock is taken, so an app shared by all requests, as one without a
+	// database is, keeps serving them meanwhile. checkNoteKeyChangeLocked catches a change in between.
+	a.mu.RLock()
+	salt, check := a.noteKeySalt, a.noteKeyCheck
+	a.mu.RUnlock()
+	change, err := deriveNoteKeyChange(action, passphrase, newPassphrase, confirmation, salt, check)
+	if err == nil {
+		err = a.applyNoteKeyChange(r, action, change)
+	}
+
+	var invalid *domain.ValidationError
+	switch {
+	case errors.As(err, &invalid):
+		w.WriteHeader(http.StatusBadRequest)
+		a.renderDataSettings(w, dataSettingsViewData{Error: invalid.Error()})
+	case err != nil:
+		log.Printf("db error while changing note encryption: %v", err)
+		http.Error(w, "could not change note encryption", http.StatusInternalServerError)
+	default:
+		http.Redirect(w, r, "/settings/data?saved=notes-"+action, http.StatusSeeOther)
+	}
+}
@@ -0,0 +1,167 @@
+package web
+
+import (
+	"bytes"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestNoteKeyDerivationIsStable(t *testing.T) {
+	// Keys of notes already sealed must keep opening, whichever PBKDF2 implementation derives them.
+	key := deriveNoteKey("correct horse battery", []byte("0123456789abcdef"))
+	if got := hex.EncodeToString(key); got != "16cb1405f207ab5113b3afbeb6cad2d02ca8caf6d13a76f52fd93e6b43bec1e1" {
+		t.Fatalf("unexpected note key %s", got)
+	}
+}
+
+func TestNoteKeyChangeIsRejectedIfTheKeyChangedMeanwhile(t *testing.T) {
+	app, cleanup := newSQLiteTestApp(t)
+	defer cleanup()
+	seedProfile(app)
+
+	change, err := deriveNoteKeyChange("enable", "", "correct horse", "correct horse", "", "")
+	if err != nil {
+		t.Fatalf("derive keys: %v", err)
+	}
+	if err := app.applyNoteKeyChange(httptest.NewRequest(http.MethodPost, "/settings/data/notes", nil), "enable", change); err != nil {
+		t.Fatalf("enable encryption: %v", err)
+	}
+
+	// A second enable derived before the first one was applied must not replace its key.
+	if err := app.applyNoteKeyChange(httptest.NewRequest(http.MethodPost, "/settings/data/notes", nil), "enable", change); err == nil || !strings.Contains(err.Error(), "changed in the meantime") {
+		t.Fatalf("expected a stale change to be rejected, got %v", err)
+	}
+	if got := app.noteKeys.get(app.currentUserIDLocked()); !bytes.Equal(got, change.newKey) {
+		t.Fatalf("expected the first key to stay in use")
+	}
+}
+
+func TestNoteKeyChangeIsRejectedIfAnotherRequestChangedTheKey(t *testing.T) {
+	app, cleanup := newSQLiteTestApp(t)
+	defer cleanup()
+	seedProfile(app)
+	app.mu.Lock()
+	if err := app.persistProfileLocked(); err != nil {
+		t.Fatalf("persist profile: %v", err)
+	}
+	app.mu.Unlock()
+
+	// Both requests load the profile before either applies its change.
+	req := httptest.NewRequest(http.MethodPost, "/settings/data/notes", nil)
+	first, second := app.profileView(), app.profileView()
+	for _, view := range []*App{first, second} {
+		if err := view.activateProfileFromRequest(req); err != nil {
+			t.Fatalf("activate profile: %v", err)
+		}
+	}
+	firstChange, err := deriveNoteKeyChange("enable", "", "correct horse", "correct horse", "", "")
+	if err != nil {
+		t.Fatalf("derive keys: %v", err)
+	}
+	secondChange, err := deriveNoteKeyChange("enable", "", "battery staple", "battery staple", "", "")
+	if err != nil {
+		t.Fatalf("derive keys: %v", err)
+	}
+
+	if err := first.applyNoteKeyChange(req, "enable", firstChange); err != nil {
+		t.Fatalf("enable encryption: %v", err)
+	}
+	if err := second.applyNoteKeyChange(req, "enable", secondChange); err == nil || !strings.Contains(err.Error(), "changed in the meantime") {
+		t.Fatalf("expected the second request's change to be rejected, got %v", err)
+	}
+	var salt string
+	if err := app.db.QueryRow(`SELECT note_key_salt FROM profiles`).Scan(&salt); err != nil {
+		t.Fatalf("load note key: %v", err)
+	}
+	if salt != firstChange.newSalt {
+		t.Fatalf("expected the first request's key to stay in use")
+	}
+}
+
+func TestEncryptedNotesCanBeLockedRotatedAndDecrypted(t *testing.T) {
+	app, cleanup := newSQLiteTestApp(t)
+	defer cleanup()
+	seedProfile(app)
+
+	serve := func(method, path string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		app.Handler().ServeHTTP(rr, req)
+		return rr
+	}
+	storedNote := func() string {
+		t.Helper()
+		var note string
+		if err := app.db.QueryRow(`SELECT note FROM items WHERE title = 'Drone'`).Scan(&note); err != nil {
+			t.Fatalf("load note: %v", err)
+		}
+		return note
+	}
+	notes := func(form url.Values) *httptest.ResponseRecorder {
+		return serve(http.MethodPost, "/settings/data/notes", form)
+	}
+
+	serve(http.MethodPost, "/items/new", url.Values{"title": {"Drone"}, "price": {"500"}, "note": {"Only if the old one breaks"}, "wait_preset": {"24h"}})
+
+	if rr := notes(url.Values{"action": {"enable"}, "new_passphrase": {"short"}, "confirm_passphrase": {"short"}}); rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "at least 8 characters") {
+		t.Fatalf("expected a short passphrase to be rejected, got %d", rr.Code)
+	}
+	if rr := notes(url.Values{"action": {"enable"}, "new_passphrase": {"correct horse"}, "confirm_passphrase": {"correct horse"}}); rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/settings/data?saved=notes-enable" {
+		t.Fatalf("expected redirect after enabling, got %d %q", rr.Code, rr.Header().Get("Location"))
+	}
+	sealed := storedNote()
+	if !isSealedNote(sealed) || strings.Contains(sealed, "old one") {
+		t.Fatalf("expected the stored note to be encrypted, got %q", sealed)
+	}
+	if body := serve(http.MethodGet, "/", nil).Body.String(); !strings.Contains(body, "Only if the old one breaks") {
+		t.Fatal("expected the unlocked note on the dashboard")
+	}
+
+	notes(url.Values{"action": {"lock"}})
+	if body := serve(http.MethodGet, "/", nil).Body.String(); strings.Contains(body, "old one") || !strings.Contains(body, "Encrypted note") {
+		t.Fatal("expected the locked note to be hidden on the dashboard")
+	}
+	if rr := serve(http.MethodPost, "/items/new", url.Values{"title": {"Lamp"}, "note": {"For the desk"}, "wait_preset": {"24h"}}); rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "Notes are encrypted") {
+		t.Fatalf("expected a new note to be refused while locked, got %d", rr.Code)
+	}
+	var droneID string
+	if err := app.db.QueryRow(`SELECT id FROM items WHERE title = 'Drone'`).Scan(&droneID); err != nil {
+		t.Fatalf("load item: %v", err)
+	}
+	if rr := serve(http.MethodPost, "/items/"+droneID+"/edit", url.Values{"title": {"Drone"}, "price": {"450"}, "note": {sealed}, "wait_preset": {"24h"}}); rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected a locked note to pass through an edit, got %d", rr.Code)
+	}
+	if got := storedNote(); got != sealed {
+		t.Fatalf("expected the sealed note to be kept, got %q", got)
+	}
+
+	if rr := notes(url.Values{"action": {"unlock"}, "passphrase": {"wrong horse"}}); rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "passphrase is not right") {
+		t.Fatalf("expected a wrong passphrase to be rejected, got %d", rr.Code)
+	}
+	if rr := notes(url.Values{"action": {"rotate"}, "passphrase": {"correct horse"}, "new_passphrase": {"battery staple"}, "confirm_passphrase": {"battery staple"}}); rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected rotation to succeed, got %d", rr.Code)
+	}
+	rotated := storedNote()
+	if !isSealedNote(rotated) || rotated == sealed {
+		t.Fatalf("expected the note to be sealed with the new key, got %q", rotated)
+	}
+	notes(url.Values{"action": {"lock"}})
+	if rr := notes(url.Values{"action": {"unlock"}, "passphrase": {"correct horse"}}); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected the old passphrase to stop working, got %d", rr.Code)
+	}
+
+	if rr := notes(url.Values{"action": {"disable"}, "passphrase": {"battery staple"}}); rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected decryption to succeed, got %d", rr.Code)
+	}
+	if got := storedNote(); got != "Only if the old one breaks" {
+		t.Fatalf("expected the plain note back, got %q", got)
+	}
+	if body := serve(http.MethodGet, "/", nil).Body.String(); !strings.Contains(body, "Only if the old one breaks") {
+		t.Fatal("expected the decrypted note on the dashboard")
+	}
+}
@@ -0,0 +1,181 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// pluginNotifyTimeout bounds one notifier call, since notifiers run while the app is locked.
+const pluginNotifyTimeout = 5 * time.Second
+
+// Notification is what notifier plugins receive when an item becomes ready to buy. Exec
+// plugins read it as JSON on stdin.
+type Notification struct {
+	Event        string  `json:"event"`
+	Profile      string  `json:"profile"`
+	ItemID       int     `json:"item_id"`
+	Title        string  `json:"title"`
+	Price        float64 `json:"price,omitempty"`
+	Currency     string  `json:"currency"`
+	Message      string  `json:"message"`
+	DashboardURL string  `json:"dashboard_url"`
+	// DeliveryID stays the same when a failed delivery is retried, so a notifier can skip a repeat.
+	DeliveryID int64 `json:"delivery_id,omitempty"`
+}
+
+// Notifier is a notification channel added without changing this package, such as chat or e-mail.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// NotifierFunc adapts a function to a Notifier.
+type NotifierFunc func(ctx context.Context, n Notification) error
+
+func (f NotifierFunc) Notify(ctx context.Context, n Notification) error {
+	return f(ctx, n)
+}
+
+var (
+	notifiersMu sync.RWMutex
+	notifiers   = map[string]Notifier{}
+)
+
+// RegisterNotifier adds a notification channel for every profile. Plugins call it from an init
+// function, so a blank import in a build-tagged file of cmd/server is enough to enable them. It
+// panics if name is empty or already registered.
+func RegisterNotifier(name string, notifier Notifier) {
+	notifiersMu.Lock()
+	defer notifiersMu.Unlock()
+	if strings.TrimSpace(name) == "" || notifier == nil {
+		panic("web: RegisterNotifier needs a name and a notifier")
+	}
+	if _, dup := notifiers[name]; dup {
+		panic("web: RegisterNotifier called twice for " + name)
+	}
+	notifiers[name] = notifier
+}
+
+// ExecNotifier is an out-of-process notifier: the executable at path gets the notification as JSON
+// on stdin and fails the notification by exiting non-zero.
+func ExecNotifier(path string) Notifier {
+	return NotifierFunc(func(ctx context.Context, n Notification) error {
+		payload, err := json.Marshal(n)
+		if err != nil {
+			return fmt.Errorf("encode notification: %w", err)
+		}
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, path)
+		cmd.Stdin = bytes.NewReader(payload)
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return fmt.Errorf("%w: %s", err, msg)
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+// registeredNotifierNames returns the registered notifier names in a stable order.
+func registeredNotifierNames() []string {
+	notifiersMu.RLock()
+	defer notifiersMu.RUnlock()
+	names := make([]string, 0, len(notifiers))
+	for name := range notifiers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// sendPluginNotificationLocked hands a newly ready item of the active profile to the named notifier.
+// Notifiers that are no longer registered are skipped.
+func (a *App) sendPluginNotificationLocked(name string, item Item, deliveryID int64) error {
+	notifiersMu.RLock()
+	notifier, ok := notifiers[name]
+	notifiersMu.RUnlock()
+	if !ok {
+		log.Printf("notifier %s skipped for item %d: not registered", name, item.ID)
+		return errChannelNotConfigured
+	}
+	// Plugins are third-party code; they get what the shared screens get.
+	item = maskPrivate(item)
+
+	n := Notification{
+		Event:        "item_ready",
+		Profile:      a.currentUserIDLocked(),
+		ItemID:       item.ID,
+		Title:        item.Title,
+		Currency:     normalizeCurrency(a.currency),
+		Message:      fmt.Sprintf("%s is ready to buy.", item.Title),
+		DashboardURL: a.dashboardLink(),
+		DeliveryID:   deliveryID,
+	}
+	if item.HasPriceValue {
+		n.Price = item.PriceCents.Float()
+	}
+	if payload, err := json.Marshal(n); err == nil && a.notificationDryRunLocked(effectNotifierPrefix+name, name, string(payload)) {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(a.mu.Context(), pluginNotifyTimeout)
+	defer cancel()
+	return notifier.Notify(ctx, n)
+}
+
+// SetNotificationsDryRun makes every profile log its notifications instead of sending them, as if each
+// had turned on the dry run in its settings.
+func (a *App) SetNotificationsDryRun(enabled bool) {
+	a.mu.Lock()
+	a.notificationsDryRun = enabled
+	a.mu.Unlock()
+}
+
+// notificationDryRunLocked logs a notification the active profile is about to send on channel to target
+// and reports true when it must not be sent, because the profile or the whole instance is in dry-run mode.
+func (a *App) notificationDryRunLocked(channel, target, message string) bool {
+	if !a.notifyDryRun && !a.notificationsDryRun {
+		return false
+	}
+	log.Printf("notification dry run: %s to %s for profile %q, not sent: %q", channel, target, a.currentUserIDLocked(), message)
+	return true
+}
+
+// NotificationEndpoints lists the distinct ntfy servers and Home Assistant webhooks that active profiles
+// send notifications to, so startup can check that they are reachable.
+func (a *App) NotificationEndpoints() ([]string, error) {
+	if a.db == nil {
+		a.mu.RLock()
+		defer a.mu.RUnlock()
+		if a.ntfyURL == "" {
+			return nil, nil
+		}
+		return []string{a.ntfyURL}, nil
+	}
+	rows, err := a.db.Query(`
+SELECT ntfy_endpoint FROM profiles WHERE ntfy_endpoint != '' AND ntfy_topic != '' AND archived_at = ''
+UNION
+SELECT ha_webhook_url FROM profiles WHERE ha_webhook_url != '' AND archived_at = ''
+ORDER BY 1`)
+	if err != nil {
+		return nil, fmt.Errorf("list notification endpoints: %w", err)
+	}
+	defer rows.Close()
+	var endpoints []string
+	for rows.Next() {
+		var endpoint string
+		if err := rows.Scan(&endpoint); err != nil {
+			return nil, fmt.Errorf("scan notification endpoint: %w", err)
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints, rows.Err()
+}
@@ -0,0 +1,30 @@
+package web_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestRenotifyPolicyIsSavedWithTheProfile(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{Profiles: []webtest.Profile{{Name: "Alex", HourlyWage: "25"}}})
+	alex := h.As("Alex")
+
+	alex.PostForm("/settings/profile", url.Values{"profile_name": {"Alex"}, "hourly_wage": {"25"}, "renotify_policy": {"days"}}).
+		ExpectStatus(http.StatusBadRequest).
+		ExpectContains(`id="renotify_days-error"`)
+	alex.PostForm("/settings/profile", url.Values{"profile_name": {"Alex"}, "hourly_wage": {"25"}, "renotify_policy": {"days"}, "renotify_days": {"5"}}).
+		ExpectRedirect("/settings/profile?saved=1")
+
+	var policy string
+	var days int
+	if err := h.DB.QueryRow(`SELECT renotify_policy, renotify_days FROM profiles WHERE user_id = 'Alex'`).Scan(&policy, &days); err != nil {
+		t.Fatalf("load renotify policy: %v", err)
+	}
+	if policy != "days" || days != 5 {
+		t.Fatalf("expected the policy to be persisted, got %q %d", policy, days)
+	}
+	alex.Get("/settings/profile").ExpectContains(`<option value="days" selected>`, `value="5"`)
+}
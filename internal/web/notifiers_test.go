@@ -0,0 +1,189 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// registerTestNotifier registers notifier for the duration of the test.
+func registerTestNotifier(t *testing.T, name string, notifier Notifier) {
+	t.Helper()
+	RegisterNotifier(name, notifier)
+	t.Cleanup(func() {
+		notifiersMu.Lock()
+		delete(notifiers, name)
+		notifiersMu.Unlock()
+	})
+}
+
+func TestRegisteredNotifiersReceiveReadyItems(t *testing.T) {
+	app := NewApp()
+	seedProfile(app)
+	var received []Notification
+	registerTestNotifier(t, "a-failing", NotifierFunc(func(context.Context, Notification) error {
+		return errors.New("channel down")
+	}))
+	registerTestNotifier(t, "b-recorder", NotifierFunc(func(_ context.Context, n Notification) error {
+		received = append(received, n)
+		return nil
+	}))
+
+	app.mu.Lock()
+	app.items = append(app.items,
+		Item{ID: 9, Title: "headphones", Status: "Waiting", PriceCents: 12999, HasPriceValue: true, PurchaseAllowedAt: time.Now().Add(-time.Minute)},
+		Item{ID: 10, Title: "Ring", Status: "Waiting", Private: true, PurchaseAllowedAt: time.Now().Add(-time.Minute)},
+	)
+	app.mu.Unlock()
+
+	for range 2 {
+		app.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	if len(received) != 2 {
+		t.Fatalf("expected one notification per ready item despite the failing notifier, got %+v", received)
+	}
+	if got := received[0]; got.Event != "item_ready" || got.ItemID != 9 || got.Price != 129.99 || got.Message != "headphones is ready to buy." {
+		t.Fatalf("unexpected notification %+v", got)
+	}
+	if got := received[1]; got.Title != privateItemTitle {
+		t.Fatalf("expected the private item to be masked, got %+v", got)
+	}
+}
+
+func TestRegisterNotifierRejectsDuplicateNames(t *testing.T) {
+	registerTestNotifier(t, "dup", NotifierFunc(func(context.Context, Notification) error { return nil }))
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic for a duplicate notifier name")
+		}
+	}()
+	RegisterNotifier("dup", NotifierFunc(func(context.Context, Notification) error { return nil }))
+}
+
+func TestExecNotifierPassesTheNotificationOnStdin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script")
+	}
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out.json")
+	script := filepath.Join(dir, "notify")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ncat > "+out+"\n"), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	failing := filepath.Join(dir, "fail")
+	if err := os.WriteFile(failing, []byte("#!/bin/sh\necho 'no token' >&2\nexit 3\n"), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+
+	if err := ExecNotifier(script).Notify(context.Background(), Notification{Event: "item_ready", ItemID: 4, Title: "Kettle"}); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+	raw, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	var got Notification
+	if err := json.Unmarshal(raw, &got); err != nil || got.ItemID != 4 || got.Title != "Kettle" {
+		t.Fatalf("unexpected payload %s (%v)", raw, err)
+	}
+
+	if err := ExecNotifier(failing).Notify(context.Background(), Notification{}); err == nil || !strings.Contains(err.Error(), "exit status 3") || !strings.Contains(err.Error(), "no token") {
+		t.Fatalf("expected the exit status and stderr in the error, got %v", err)
+	}
+}
+
+func TestNotificationDryRunLogsInsteadOfSending(t *testing.T) {
+	var requests int
+	ntfyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer ntfyServer.Close()
+	var received []Notification
+	registerTestNotifier(t, "dry-run-recorder", NotifierFunc(func(_ context.Context, n Notification) error {
+		received = append(received, n)
+		return nil
+	}))
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	app := NewApp()
+	seedProfile(app)
+	app.mu.Lock()
+	app.ntfyURL, app.ntfyTopic = ntfyServer.URL, "alex"
+	app.notifyDryRun = true
+	app.items = append(app.items, Item{ID: 4, Title: "Kayak", Status: "Waiting", PurchaseAllowedAt: time.Now().Add(-time.Minute)})
+	app.mu.Unlock()
+	app.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if requests != 0 || len(received) != 0 {
+		t.Fatalf("expected nothing to be sent in dry-run mode, got %d ntfy requests and %d notifications", requests, len(received))
+	}
+	for _, want := range []string{`ntfy to ` + ntfyServer.URL + `/alex`, `"Kayak is now ready to buy.\nDashboard: http://localhost:8080/"`, `notifier:dry-run-recorder to dry-run-recorder`} {
+		if !strings.Contains(logs.String(), want) {
+			t.Fatalf("expected the dry run to log %q, got %s", want, logs.String())
+		}
+	}
+}
+
+func TestProfileSettingsSaveNotificationDryRun(t *testing.T) {
+	app := NewApp()
+	seedProfile(app)
+	app.SetNotificationsDryRun(true)
+
+	form := url.Values{"hourly_wage": {"25"}, "currency": {"EUR"}, "notify_dry_run": {"1"}}
+	req := httptest.NewRequest(http.MethodPost, "/settings/profile", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusSeeOther || !app.notifyDryRun {
+		t.Fatalf("expected the dry run to be saved, got %d %v", rr.Code, app.notifyDryRun)
+	}
+
+	rr = httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/settings/profile", nil))
+	body := rr.Body.String()
+	if !regexp.MustCompile(`id="notify_dry_run"[^>]*checked`).MatchString(body) || !strings.Contains(body, "NOTIFICATIONS_DRY_RUN") {
+		t.Fatalf("expected the checked dry run and the instance-wide note, got %s", body)
+	}
+}
+
+func TestNotificationEndpointsListsEndpointsOfActiveProfiles(t *testing.T) {
+	app, err := NewAppWithSQLite(filepath.Join(t.TempDir(), "test.sqlite"))
+	if err != nil {
+		t.Fatalf("new sqlite app: %v", err)
+	}
+	defer app.Close()
+	for _, stmt := range []string{
+		`INSERT INTO profiles(user_id, hourly_wage, ntfy_endpoint, ntfy_topic, ha_webhook_url, updated_at) VALUES ('Alex', '25', 'https://ntfy.sh', 'alex', 'http://ha.local/api/webhook/x', '')`,
+		`INSERT INTO profiles(user_id, hourly_wage, ntfy_endpoint, ntfy_topic, updated_at) VALUES ('Sam', '25', 'https://ntfy.sh', 'sam', '')`,
+		// Without a topic nothing is sent, and archived profiles send nothing either.
+		`INSERT INTO profiles(user_id, hourly_wage, ntfy_endpoint, updated_at) VALUES ('Kim', '25', 'https://ntfy.kim', '')`,
+		`INSERT INTO profiles(user_id, hourly_wage, ntfy_endpoint, ntfy_topic, archived_at, updated_at) VALUES ('Old', '25', 'https://ntfy.old', 'old', '2026-01-01T00:00:00Z', '')`,
+	} {
+		if _, err := app.db.Exec(stmt); err != nil {
+			t.Fatalf("seed profiles: %v", err)
+		}
+	}
+
+	endpoints, err := app.NotificationEndpoints()
+	if err != nil {
+		t.Fatalf("notification endpoints: %v", err)
+	}
+	if strings.Join(endpoints, " ") != "http://ha.local/api/webhook/x https://ntfy.sh" {
+		t.Fatalf("unexpected endpoints %v", endpoints)
+	}
+}
@@ -0,0 +1,127 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// After ntfyBreakerThreshold failed requests in a row, an ntfy endpoint is left alone for
+// ntfyBreakerCooldown, so a server that is down is not hit by every queued retry.
+const (
+	ntfyBreakerThreshold = 3
+	ntfyBreakerCooldown  = 5 * time.Minute
+)
+
+// ntfyBreakers tracks the ntfy endpoints that recently failed. It has its own lock because ntfy is
+// called with a.mu held for reading as well as for writing.
+type ntfyBreakers struct {
+	mu        sync.Mutex
+	endpoints map[string]*ntfyBreaker
+}
+
+// ntfyBreaker is the circuit breaker state of one endpoint. The circuit is open until OpenUntil; after
+// that one request goes through, and its outcome closes the circuit or opens it for another cooldown.
+type ntfyBreaker struct {
+	Endpoint  string
+	Failures  int
+	OpenUntil time.Time
+	LastError string
+	LastFail  time.Time
+	// State is set by statuses; see ntfyBreaker.state.
+	State string
+}
+
+// ntfyCircuitOpenError is returned instead of sending while an endpoint's circuit is open.
+type ntfyCircuitOpenError struct {
+	Endpoint string
+	Until    time.Time
+}
+
+func (e *ntfyCircuitOpenError) Error() string {
+	return fmt.Sprintf("ntfy endpoint %s is paused after repeated failures until %s", e.Endpoint, e.Until.Format(time.RFC3339))
+}
+
+// state is "open" during the cooldown, "trial" once a request may test the endpoint again and "closed"
+// while requests go through.
+func (b ntfyBreaker) state(now time.Time) string {
+	switch {
+	case b.OpenUntil.After(now):
+		return "open"
+	case !b.OpenUntil.IsZero():
+		return "trial"
+	}
+	return "closed"
+}
+
+// ntfyEndpointKey identifies an endpoint regardless of a trailing slash. Credentials in the URL are
+// replaced, since the key is shown on the household page.
+func ntfyEndpointKey(endpoint string) string {
+	endpoint = strings.TrimRight(strings.TrimSpace(endpoint), "/")
+	if u, err := url.Parse(endpoint); err == nil {
+		return u.Redacted()
+	}
+	return endpoint
+}
+
+// allow returns an error while the endpoint's circuit is open.
+func (bs *ntfyBreakers) allow(endpoint string, now time.Time) error {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if b, ok := bs.endpoints[endpoint]; ok && b.OpenUntil.After(now) {
+		return &ntfyCircuitOpenError{Endpoint: endpoint, Until: b.OpenUntil}
+	}
+	return nil
+}
+
+// record notes the outcome of a request. Endpoints that work again are forgotten.
+func (bs *ntfyBreakers) record(endpoint string, err error, now time.Time) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if err == nil {
+		delete(bs.endpoints, endpoint)
+		return
+	}
+	if bs.endpoints == nil {
+		bs.endpoints = map[string]*ntfyBreaker{}
+	}
+	b, ok := bs.endpoints[endpoint]
+	if !ok {
+		b = &ntfyBreaker{Endpoint: endpoint}
+		bs.endpoints[endpoint] = b
+	}
+	b.Failures++
+	b.LastError, b.LastFail = err.Error(), now
+	if b.Failures >= ntfyBreakerThreshold {
+		b.OpenUntil = now.Add(ntfyBreakerCooldown)
+	}
+}
+
+// statuses returns the endpoints that failed since they last worked, by endpoint.
+func (bs *ntfyBreakers) statuses(now time.Time) []ntfyBreaker {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	out := make([]ntfyBreaker, 0, len(bs.endpoints))
+	for _, b := range bs.endpoints {
+		status := *b
+		status.State = b.state(now)
+		out = append(out, status)
+	}
+	slices.SortFunc(out, func(a, b ntfyBreaker) int { return strings.Compare(a.Endpoint, b.Endpoint) })
+	return out
+}
+
+// postNtfyMessage publishes a message on an ntfy topic through the endpoint's circuit breaker.
+func (a *App) postNtfyMessage(ctx context.Context, endpoint, topic, title, message string) (int, error) {
+	key := ntfyEndpointKey(endpoint)
+	if err := a.ntfyBreakers.allow(key, time.Now()); err != nil {
+		return 0, err
+	}
+	code, err := postNtfyMessage(ctx, endpoint, topic, title, message)
+	a.ntfyBreakers.record(key, err, time.Now())
+	return code, err
+}
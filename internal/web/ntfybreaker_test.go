@@ -0,0 +1,86 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNtfyEndpointIsPausedAfterRepeatedFailures(t *testing.T) {
+	status := http.StatusBadGateway
+	requests := 0
+	ntfyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(status)
+	}))
+	defer ntfyServer.Close()
+
+	app, err := NewAppWithSQLite(filepath.Join(t.TempDir(), "test.sqlite"))
+	if err != nil {
+		t.Fatalf("new sqlite app: %v", err)
+	}
+	defer app.Close()
+	app.SetAdminToken("s3cret")
+
+	for i := 0; i < ntfyBreakerThreshold+2; i++ {
+		_, _ = app.postNtfyMessage(context.Background(), ntfyServer.URL+"/", "alex", "Test", "message")
+	}
+	if requests != ntfyBreakerThreshold {
+		t.Fatalf("expected requests to stop after %d failures, got %d", ntfyBreakerThreshold, requests)
+	}
+	_, err = app.postNtfyMessage(context.Background(), ntfyServer.URL, "bea", "Test", "message")
+	var paused *ntfyCircuitOpenError
+	if !errors.As(err, &paused) || requests != ntfyBreakerThreshold {
+		t.Fatalf("expected other topics on the endpoint to be paused too, got %v after %d requests", err, requests)
+	}
+
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/household?token=s3cret", nil))
+	if body := rr.Body.String(); !strings.Contains(body, ntfyServer.URL) || !strings.Contains(body, "Paused until") {
+		t.Fatalf("expected the household page to show the paused endpoint")
+	}
+
+	app.ntfyBreakers.endpoints[ntfyEndpointKey(ntfyServer.URL)].OpenUntil = time.Now().Add(-time.Second)
+	status = http.StatusOK
+	if _, err := app.postNtfyMessage(context.Background(), ntfyServer.URL, "alex", "Test", "message"); err != nil || requests != ntfyBreakerThreshold+1 {
+		t.Fatalf("expected a trial request after the cooldown, got %v after %d requests", err, requests)
+	}
+	if got := app.ntfyBreakers.statuses(time.Now()); len(got) != 0 {
+		t.Fatalf("expected the endpoint to be forgotten once it works again, got %+v", got)
+	}
+}
+
+func TestPausedNtfyEndpointDoesNotUseUpOutboxAttempts(t *testing.T) {
+	app, err := NewAppWithSQLite(filepath.Join(t.TempDir(), "test.sqlite"))
+	if err != nil {
+		t.Fatalf("new sqlite app: %v", err)
+	}
+	defer app.Close()
+
+	now := time.Now()
+	if _, err := app.db.Exec(`INSERT INTO outbox(user_id, effect, event, next_attempt_at, created_at) VALUES ('Alex', ?, '{}', ?, ?)`, effectNtfy, now.UTC().Format(outboxTimeFormat), now.UTC().Format(outboxTimeFormat)); err != nil {
+		t.Fatalf("insert outbox entry: %v", err)
+	}
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	entries, err := app.dueOutboxEntriesLocked(now)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected one due entry, got %d (%v)", len(entries), err)
+	}
+	until := now.Add(ntfyBreakerCooldown)
+	if err := app.finishOutboxEntryLocked(entries[0], &ntfyCircuitOpenError{Endpoint: "https://ntfy.example", Until: until}, now); err != nil {
+		t.Fatalf("finish outbox entry: %v", err)
+	}
+	if due, err := app.dueOutboxEntriesLocked(until.Add(-time.Second)); err != nil || len(due) != 0 {
+		t.Fatalf("expected the entry to wait for the cooldown, got %d (%v)", len(due), err)
+	}
+	due, err := app.dueOutboxEntriesLocked(until.Add(time.Second))
+	if err != nil || len(due) != 1 || due[0].Attempts != 0 {
+		t.Fatalf("expected the entry after the cooldown without a used attempt, got %+v (%v)", due, err)
+	}
+}
@@ -0,0 +1,323 @@
+package web
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"mvpapp/internal/domain"
+)
+
+// onboardingStep is one page of the setup wizard for new profiles. Fields are the form inputs it owns.
+type onboardingStep struct {
+	Key    string
+	Title  string
+	Intro  string
+	Fields []string
+}
+
+// onboardingSteps run in order. A profile's progress is the key of its next unfinished step.
+var onboardingSteps = []onboardingStep{
+	{Key: "name", Title: "Name", Intro: "Each profile keeps its own items and settings. Pick the name you will switch to.", Fields: []string{"profile_name"}},
+	{Key: "wage", Title: "Hourly wage", Intro: "Prices are shown as hours of work, based on what you earn per hour after taxes.", Fields: []string{"hourly_wage"}},
+	{Key: "currency", Title: "Currency", Intro: "Used for prices and saved amounts.", Fields: []string{"currency"}},
+	{Key: "wait", Title: "Default wait", Intro: "How long new items wait before you may buy them. Tags can override this later.", Fields: []string{"default_wait_preset", "default_wait_custom_hours"}},
+	{Key: "notifications", Title: "Notifications", Intro: "Optional: get an ntfy push when an item is ready to buy.", Fields: []string{"ntfy_endpoint", "ntfy_topic"}},
+	{Key: "item", Title: "First item", Intro: "Optional: capture the first thing you are tempted to buy. Its wait starts right away.", Fields: []string{"title", "price"}},
+}
+
+// onboardingProgressItem is one entry of the wizard's step list. Reachable steps link back to themselves.
+type onboardingProgressItem struct {
+	Number    int
+	Key       string
+	Title     string
+	Current   bool
+	Reachable bool
+}
+
+type onboardingViewData struct {
+	Title                  string
+	CurrentPath            string
+	ContentTemplate        string
+	ScriptTemplate         string
+	Steps                  []onboardingStep
+	Step                   onboardingStep
+	StepNumber             int
+	Progress               []onboardingProgressItem
+	ProgressPercent        int
+	ProfileName            string
+	HourlyWage             string
+	Currency               string
+	CurrencyOptions        []currencyInfo
+	DefaultWaitPreset      string
+	DefaultWaitCustomHours string
+	NtfyEndpoint           string
+	NtfyTopic              string
+	ItemTitle              string
+	ItemPrice              string
+	Error                  string
+	FieldErrors            map[string]string
+	ActiveProfile          string
+}
+
+func onboardingStepIndex(key string) int {
+	return slices.IndexFunc(onboardingSteps, func(step onboardingStep) bool { return step.Key == key })
+}
+
+// onboardingProgressLocked returns the index of the profile's next unfinished step, or -1 once setup is done.
+func (a *App) onboardingProgressLocked() int {
+	return onboardingStepIndex(a.onboardingStep)
+}
+
+func (a *App) onboarding(w http.ResponseWriter, r *http.Request) {
+	if !a.hasActiveProfile() {
+		http.Redirect(w, r, "/switch-profile", http.StatusSeeOther)
+		return
+	}
+
+	a.mu.RLock()
+	reached := a.onboardingProgressLocked()
+	a.mu.RUnlock()
+	if reached < 0 {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+
+	// Earlier steps can be revisited; later ones only open once the steps before them are done.
+	index := reached
+	if requested := onboardingStepIndex(r.URL.Query().Get("step")); requested >= 0 && requested < reached {
+		index = requested
+	}
+	a.renderOnboarding(w, onboardingViewData{Step: onboardingSteps[index]})
+}
+
+func (a *App) renderOnboarding(w http.ResponseWriter, data onboardingViewData) {
+	a.mu.RLock()
+	if data.ProfileName == "" {
+		data.ProfileName = a.currentUserIDLocked()
+	}
+	if data.HourlyWage == "" {
+		data.HourlyWage = a.hourlyWage
+	}
+	if data.Currency == "" {
+		data.Currency = normalizeCurrency(a.currency)
+	}
+	if data.DefaultWaitPreset == "" {
+		data.DefaultWaitPreset = domain.NormalizeWaitPreset(a.defaultWaitPreset)
+	}
+	if data.DefaultWaitCustomHours == "" {
+		data.DefaultWaitCustomHours = a.defaultWaitCustomHours
+	}
+	if data.NtfyEndpoint == "" {
+		data.NtfyEndpoint = a.ntfyURL
+	}
+	if data.NtfyTopic == "" {
+		data.NtfyTopic = a.ntfyTopic
+	}
+	reached := a.onboardingProgressLocked()
+	data.ActiveProfile = a.currentUserIDLocked()
+	a.mu.RUnlock()
+
+	data.Title = "Set up profile"
+	data.CurrentPath = "/onboarding"
+	data.ContentTemplate = "onboarding_content"
+	data.ScriptTemplate = "onboarding_script"
+	data.Steps = onboardingSteps
+	data.StepNumber = onboardingStepIndex(data.Step.Key) + 1
+	data.ProgressPercent = data.StepNumber * 100 / len(onboardingSteps)
+	data.Progress = make([]onboardingProgressItem, 0, len(onboardingSteps))
+	for i, step := range onboardingSteps {
+		data.Progress = append(data.Progress, onboardingProgressItem{
+			Number:    i + 1,
+			Key:       step.Key,
+			Title:     step.Title,
+			Current:   step.Key == data.Step.Key,
+			Reachable: i < reached,
+		})
+	}
+	data.CurrencyOptions = supportedCurrencies
+	renderTemplate(w, a.templates, "layout", data)
+}
+
+func (a *App) saveOnboardingStep(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+	if !a.hasActiveProfile() {
+		http.Redirect(w, r, "/switch-profile", http.StatusSeeOther)
+		return
+	}
+	index := onboardingStepIndex(r.FormValue("step"))
+	if index < 0 {
+		http.Error(w, "invalid onboarding step", http.StatusBadRequest)
+		return
+	}
+	step := onboardingSteps[index]
+
+	switch r.FormValue("action") {
+	case "skip":
+		a.mu.LockContext(r.Context())
+		a.onboardingStep = ""
+		err := a.persistProfileLocked()
+		a.mu.Unlock()
+		if err != nil {
+			log.Printf("db error while skipping onboarding: %v", err)
+			http.Error(w, "could not save profile", http.StatusInternalServerError)
+			return
+		}
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	case "back":
+		http.Redirect(w, r, "/onboarding?step="+onboardingSteps[max(index-1, 0)].Key, http.StatusSeeOther)
+		return
+	}
+
+	data := onboardingViewData{
+		Step:                   step,
+		ProfileName:            strings.TrimSpace(r.FormValue("profile_name")),
+		HourlyWage:             strings.TrimSpace(r.FormValue("hourly_wage")),
+		Currency:               strings.TrimSpace(r.FormValue("currency")),
+		DefaultWaitPreset:      strings.TrimSpace(r.FormValue("default_wait_preset")),
+		DefaultWaitCustomHours: strings.TrimSpace(r.FormValue("default_wait_custom_hours")),
+		NtfyEndpoint:           strings.TrimSpace(r.FormValue("ntfy_endpoint")),
+		NtfyTopic:              strings.TrimSpace(r.FormValue("ntfy_topic")),
+		ItemTitle:              strings.TrimSpace(r.FormValue("title")),
+		ItemPrice:              strings.TrimSpace(r.FormValue("price")),
+	}
+
+	a.mu.LockContext(r.Context())
+	reached := a.onboardingProgressLocked()
+	if reached < 0 || index > reached {
+		a.mu.Unlock()
+		http.Redirect(w, r, "/onboarding", http.StatusSeeOther)
+		return
+	}
+	previousProfileName := a.currentUserIDLocked()
+	err := a.applyOnboardingStepLocked(step, data)
+	if err == nil {
+		// Revisiting an earlier step does not undo progress made past it.
+		if next := index + 1; next > reached {
+			a.onboardingStep = ""
+			if next < len(onboardingSteps) {
+				a.onboardingStep = onboardingSteps[next].Key
+			}
+		}
+		err = a.persistProfileLocked()
+	}
+	profileName := a.currentUserIDLocked()
+	if err == nil && profileName != previousProfileName {
+		a.recordAuditLocked(profileName, auditProfileRenamed, previousProfileName+" → "+profileName, r)
+	}
+	done := a.onboardingStep == ""
+	a.mu.Unlock()
+
+	var invalid *domain.ValidationError
+	if errors.As(err, &invalid) {
+		w.WriteHeader(http.StatusBadRequest)
+		data.Error = fieldErrorSummary
+		data.FieldErrors = invalid.FieldMessages()
+		a.renderOnboarding(w, data)
+		return
+	}
+	if err != nil {
+		log.Printf("db error while saving onboarding step %s: %v", step.Key, err)
+		http.Error(w, "could not save profile", http.StatusInternalServerError)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{Name: "active_profile", Value: profileName, Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode})
+	if done {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, "/onboarding?step="+onboardingSteps[index+1].Key, http.StatusSeeOther)
+}
+
+// applyOnboardingStepLocked validates the inputs the step owns and saves them to the active profile.
+// The profile settings are checked as a whole, like on the settings page, but only the step's own
+// fields can reject it.
+func (a *App) applyOnboardingStepLocked(step onboardingStep, data onboardingViewData) error {
+	if step.Key == "currency" {
+		currency, err := parseCurrency(data.Currency)
+		if err != nil {
+			return &domain.ValidationError{Fields: []domain.FieldError{{Field: "currency", Message: err.Error()}}}
+		}
+		a.currency = currency
+		return nil
+	}
+	if step.Key == "item" {
+		return a.createOnboardingItemLocked(data)
+	}
+
+	in := domain.ProfileSettings{
+		Name:                   a.currentUserIDLocked(),
+		HourlyWage:             a.hourlyWage,
+		DefaultWaitPreset:      a.defaultWaitPreset,
+		DefaultWaitCustomHours: a.defaultWaitCustomHours,
+		NtfyEndpoint:           a.ntfyURL,
+		NtfyTopic:              a.ntfyTopic,
+	}
+	switch step.Key {
+	case "name":
+		in.Name = data.ProfileName
+	case "wage":
+		in.HourlyWage = data.HourlyWage
+	case "wait":
+		in.DefaultWaitPreset = data.DefaultWaitPreset
+		in.DefaultWaitCustomHours = data.DefaultWaitCustomHours
+	case "notifications":
+		in.NtfyEndpoint = data.NtfyEndpoint
+		in.NtfyTopic = data.NtfyTopic
+	}
+	settings, err := a.profileService().ValidateSettings(in)
+	var invalid *domain.ValidationError
+	if errors.As(err, &invalid) {
+		var own domain.Validation
+		for _, field := range invalid.Fields {
+			if slices.Contains(step.Fields, field.Field) {
+				own.Add(field.Field, field.Message)
+			}
+		}
+		if err := own.Err(); err != nil {
+			return err
+		}
+	}
+
+	switch step.Key {
+	case "name":
+		if settings.Name != a.currentUserIDLocked() {
+			if err := a.renameProfileLocked(a.currentUserIDLocked(), settings.Name); err != nil {
+				return err
+			}
+			a.activeUserID = settings.Name
+		}
+	case "wage":
+		a.hourlyWage = settings.HourlyWage
+		a.monthlyIncome = ""
+	case "wait":
+		a.defaultWaitPreset = domain.NormalizeWaitPreset(settings.DefaultWaitPreset)
+		a.defaultWaitCustomHours = ""
+		if a.defaultWaitPreset == "custom" {
+			a.defaultWaitCustomHours = settings.DefaultWaitCustomHours
+		}
+	case "notifications":
+		a.ntfyURL = settings.NtfyEndpoint
+		a.ntfyTopic = settings.NtfyTopic
+	}
+	return nil
+}
+
+// createOnboardingItemLocked adds the optional first item with the profile's default wait.
+func (a *App) createOnboardingItemLocked(data onboardingViewData) error {
+	if data.ItemTitle == "" && data.ItemPrice == "" {
+		return nil
+	}
+	item := Item{Title: data.ItemTitle, Price: data.ItemPrice}
+	item.PriceCents, item.HasPriceValue = a.parsePriceLocked(item.Price)
+	a.applyWaitDefaultsLocked(&item, false)
+	_, err := a.itemServiceLocked().Create(domain.Draft{Item: item})
+	return err
+}
@@ -0,0 +1,53 @@
+package web_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestOnboardingGuidesNewProfilesThroughSetup(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{})
+
+	alex := h.Anonymous()
+	alex.PostForm("/switch-profile", url.Values{"profile_name": {"Alex"}}).ExpectRedirect("/onboarding")
+	alex.Get("/").ExpectContains("Your profile setup is not finished yet.")
+	alex.Get("/onboarding?step=item").ExpectStatus(http.StatusOK).ExpectContains("Step 1 of 6", `value="Alex"`)
+
+	alex.PostForm("/onboarding", url.Values{"step": {"name"}, "profile_name": {"Alexa"}}).ExpectRedirect("/onboarding?step=wage")
+	alex.PostForm("/onboarding", url.Values{"step": {"wage"}, "hourly_wage": {"abc"}}).
+		ExpectStatus(http.StatusBadRequest).
+		ExpectContains(`id="hourly_wage-error"`, "Please correct the highlighted fields.")
+	alex.PostForm("/onboarding", url.Values{"step": {"wage"}, "hourly_wage": {"40"}}).ExpectRedirect("/onboarding?step=currency")
+
+	var step string
+	if err := h.DB.QueryRow(`SELECT onboarding_step FROM profiles WHERE user_id = 'Alexa'`).Scan(&step); err != nil {
+		t.Fatalf("load onboarding progress: %v", err)
+	}
+	if step != "currency" {
+		t.Fatalf("expected progress to be persisted at the currency step, got %q", step)
+	}
+
+	alex.PostForm("/onboarding", url.Values{"step": {"currency"}, "currency": {"CHF"}}).ExpectRedirect("/onboarding?step=wait")
+	alex.PostForm("/onboarding", url.Values{"step": {"wait"}, "default_wait_preset": {"7d"}}).ExpectRedirect("/onboarding?step=notifications")
+	alex.PostForm("/onboarding", url.Values{"step": {"notifications"}}).ExpectRedirect("/onboarding?step=item")
+	alex.PostForm("/onboarding", url.Values{"step": {"item"}, "title": {"Headphones"}, "price": {"120"}}).ExpectRedirect("/")
+
+	alex.Get("/").ExpectStatus(http.StatusOK).ExpectContains("Headphones").ExpectNotContains("Your profile setup is not finished yet.")
+	alex.Get("/onboarding").ExpectRedirect("/")
+	alex.Get("/settings/profile").ExpectContains(`value="40"`, `<option value="CHF" selected>`)
+	if got := h.Item("Alexa", "Headphones").Status; got != "Waiting" {
+		t.Fatalf("expected the first item to wait, got %q", got)
+	}
+}
+
+func TestOnboardingCanBeSkipped(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{})
+
+	alex := h.Anonymous()
+	alex.PostForm("/switch-profile", url.Values{"profile_name": {"Alex"}}).ExpectRedirect("/onboarding")
+	alex.PostForm("/onboarding", url.Values{"step": {"name"}, "action": {"skip"}}).ExpectRedirect("/")
+	alex.Get("/").ExpectStatus(http.StatusOK).ExpectNotContains("Continue setup")
+}
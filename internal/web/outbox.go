@@ -0,0 +1,310 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+// The external side effects of item events. Each is one outbox row, so one failing channel is
+// retried without repeating the others.
+const (
+	effectNtfy          = "ntfy"
+	effectWebPush       = "web-push"
+	effectHomeAssistant = "home-assistant"
+	effectItemHook      = "item-hook"
+	// effectNotifierPrefix is followed by the name of a registered notifier.
+	effectNotifierPrefix = "notifier:"
+	// effectNtfyTopicPrefix is followed by an ntfy topic a routing rule sends the item to.
+	effectNtfyTopicPrefix = "ntfy-topic:"
+	// effectReadyDigest collects the item for the weekly ready digest.
+	effectReadyDigest = "ready-digest"
+	// effectApprovalNtfy asks the approver of an approval request over their ntfy topic.
+	effectApprovalNtfy = "approval-ntfy"
+	// effectFirefly pushes a bought item to Firefly III as a withdrawal.
+	effectFirefly = "firefly"
+)
+
+// maxOutboxAttempts is how often an effect is tried before it is dropped.
+const maxOutboxAttempts = 8
+
+// outboxBatchSize bounds the entries delivered per run, so a run is short and the rest follow in the next.
+const outboxBatchSize = 50
+
+// outboxTimeFormat keeps every digit of outbox times so they compare as strings in SQL.
+const outboxTimeFormat = "2006-01-02T15:04:05.000000000Z"
+
+// outboxEntry is one pending side effect of an item event.
+type outboxEntry struct {
+	ID       int64
+	UserID   string
+	Effect   string
+	Event    domain.Event
+	Attempts int
+}
+
+// outboxRetryDelay is the wait before the next attempt after attempts failed ones: a minute, doubling up to an hour.
+func outboxRetryDelay(attempts int) time.Duration {
+	if attempts > 6 {
+		return time.Hour
+	}
+	return time.Minute << (attempts - 1)
+}
+
+// itemEffectsLocked lists the side effects of an item event of the active profile. For a promoted
+// item it applies the re-notification policy and marks item as notified, which the caller stores
+// with the item.
+func (a *App) itemEffectsLocked(eventType domain.EventType, item *Item, now time.Time) []string {
+	if eventType == domain.EventApprovalRequested {
+		return []string{effectApprovalNtfy}
+	}
+	var effects []string
+	if eventType == domain.EventItemPromoted {
+		if a.renotifyPolicyLocked().Allows(item.NotifiedAt, now) {
+			item.NotifiedAt = now
+			effects = append(effects, a.routedEffectsLocked(item)...)
+			for _, name := range registeredNotifierNames() {
+				effects = append(effects, effectNotifierPrefix+name)
+			}
+		} else {
+			log.Printf("notification skipped for item %d: announced %s, held back by the re-notification policy", item.ID, item.NotifiedAt.Format(time.RFC3339))
+		}
+		effects = append(effects, effectHomeAssistant)
+	}
+	if a.itemHook != nil {
+		effects = append(effects, effectItemHook)
+	}
+	return effects
+}
+
+// deliverEffectLocked performs one side effect of an event for the active profile. Notifications are
+// recorded in the delivery log unless their channel is not set up. deliveryID is the id of the outbox
+// entry, which receivers of structured events get to recognize a retried delivery; 0 without an outbox.
+func (a *App) deliverEffectLocked(effect string, e domain.Event, deliveryID int64) error {
+	var code int
+	var err error
+	switch {
+	case effect == effectNtfy:
+		code, err = a.sendReadyNtfyLocked(e.Item)
+	case effect == effectWebPush:
+		code, err = a.sendWebPushLocked(e.Item)
+	case effect == effectHomeAssistant:
+		code, err = a.sendHomeAssistantEventLocked(e.Item, deliveryID)
+	case effect == effectItemHook:
+		return a.runItemHookLocked(e, deliveryID)
+	case strings.HasPrefix(effect, effectNotifierPrefix):
+		err = a.sendPluginNotificationLocked(strings.TrimPrefix(effect, effectNotifierPrefix), e.Item, deliveryID)
+	case strings.HasPrefix(effect, effectNtfyTopicPrefix):
+		code, err = a.sendTopicNtfyLocked(strings.TrimPrefix(effect, effectNtfyTopicPrefix), e.Item)
+	case effect == effectReadyDigest:
+		return a.collectForDigestLocked(e.Item)
+	case effect == effectApprovalNtfy:
+		return a.notifyApproverLocked(e.Item)
+	case effect == effectFirefly:
+		return a.pushItemToFireflyLocked(e.Item.ID)
+	default:
+		log.Printf("outbox effect %q is unknown, dropping it", effect)
+		return nil
+	}
+	if errors.Is(err, errChannelNotConfigured) {
+		return nil
+	}
+	a.recordDeliveryLocked(notificationItemReady, effect, e.Item, code, err)
+	return err
+}
+
+// saveItemWithEventLocked stores an item change with save and, when it causes an event, the event's side
+// effects in the outbox, all in one transaction. The effects are then delivered even if the app stops
+// right after the change, and never for a change that was rolled back. Delivery is at least once: an
+// effect sent just before the app stops, or before its entry could be deleted, is sent again.
+func (a *App) saveItemWithEventLocked(item Item, eventType domain.EventType, save func(db sqlExecer, item Item) error) error {
+	now := time.Now()
+	var effects []string
+	if eventType != "" {
+		effects = a.itemEffectsLocked(eventType, &item, now)
+	}
+
+	tx, err := a.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin item tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	if err := save(tx, item); err != nil {
+		return err
+	}
+	event := domain.Event{Type: eventType, Profile: a.currentUserIDLocked(), Item: item}
+	if err := a.enqueueEffectsLocked(tx, event, effects, now); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit item tx: %w", err)
+	}
+	a.replaceItemLocked(item)
+	return nil
+}
+
+// enqueueEffectsLocked adds an outbox entry for each effect of event, due now or, for coalescible
+// notifications, after the coalesce window.
+func (a *App) enqueueEffectsLocked(db sqlExecer, event domain.Event, effects []string, now time.Time) error {
+	if len(effects) == 0 {
+		return nil
+	}
+	var err error
+	// Notes are only encrypted on disk; the outbox is no exception.
+	if event.Item.Note, err = a.sealNoteLocked(event.Item); err != nil {
+		event.Item.Note = ""
+	}
+	raw, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encode outbox event: %w", err)
+	}
+	for _, effect := range effects {
+		due := now
+		if coalescible(effect, event) {
+			due = now.Add(a.coalesceWindow)
+		}
+		if _, err := db.Exec(`INSERT INTO outbox(user_id, effect, event, next_attempt_at, created_at) VALUES (?, ?, ?, ?, ?)`, event.Profile, effect, string(raw), due.UTC().Format(outboxTimeFormat), now.UTC().Format(outboxTimeFormat)); err != nil {
+			return fmt.Errorf("enqueue %s: %w", effect, err)
+		}
+	}
+	return nil
+}
+
+// deliverItemEffectsLocked handles a promoted or decided item, or an approval request. With a database its effects are already in
+// the outbox and the outbox job is triggered to deliver them; without one they are delivered directly, once.
+func (a *App) deliverItemEffectsLocked(e domain.Event) {
+	now := time.Now()
+	if a.db != nil {
+		// A promotion run triggers the job once at its end, with all its items in the outbox.
+		if !a.promoting {
+			a.triggerJob("outbox")
+		}
+		return
+	}
+
+	effects := a.itemEffectsLocked(e.Type, &e.Item, now)
+	if i := a.itemIndexLocked(e.Item.ID); i >= 0 {
+		a.items[i].NotifiedAt = e.Item.NotifiedAt
+		a.items[i].NtfyAttempted = e.Item.NtfyAttempted
+	}
+	for _, effect := range effects {
+		if err := a.deliverEffectLocked(effect, e, 0); err != nil {
+			log.Printf("%s failed for item %d: %v", effect, e.Item.ID, err)
+		}
+	}
+}
+
+// StartBackgroundOutbox registers the "outbox" job, which delivers pending side effects every interval,
+// starting right away with those a previous run left behind, and as soon as an item change queues new
+// ones. Apps without a database deliver effects directly and have no outbox.
+func (a *App) StartBackgroundOutbox(interval time.Duration) {
+	if a.db == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	a.registerJob("outbox", "@every "+interval.String(), 0, true, func(a *App, now time.Time) error {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		a.dispatchOutboxLocked(now)
+		return nil
+	})
+}
+
+// dispatchOutboxLocked delivers the due outbox entries. Each entry is delivered as its profile, which is
+// made active for it; the previously active profile is restored. Ready notifications of one profile and
+// channel go out as one message. Delivered entries are deleted, failed ones retried later with a growing
+// delay.
+func (a *App) dispatchOutboxLocked(now time.Time) {
+	entries, err := a.dueOutboxEntriesLocked(now)
+	if err != nil {
+		log.Printf("db error while loading the outbox: %v", err)
+		return
+	}
+	active := a.activeUserID
+	for _, batch := range batchOutboxEntries(entries) {
+		userID := batch[0].UserID
+		if err := a.activateProfileLocked(userID); err != nil {
+			log.Printf("db error while loading profile %q for the outbox: %v", userID, err)
+			continue
+		}
+		deliveryErr := a.deliverBatchLocked(batch)
+		for _, entry := range batch {
+			if deliveryErr != nil {
+				log.Printf("%s failed for item %d (attempt %d): %v", entry.Effect, entry.Event.Item.ID, entry.Attempts+1, deliveryErr)
+			}
+			if err := a.finishOutboxEntryLocked(entry, deliveryErr, now); err != nil {
+				log.Printf("db error while updating the outbox: %v", err)
+			}
+		}
+	}
+	if active != "" && a.activeUserID != active {
+		if err := a.activateProfileLocked(active); err != nil {
+			log.Printf("db error while restoring profile %q after the outbox: %v", active, err)
+		}
+	}
+}
+
+func (a *App) dueOutboxEntriesLocked(now time.Time) ([]outboxEntry, error) {
+	rows, err := a.db.Query(`
+SELECT id, user_id, effect, event, attempts FROM outbox
+WHERE next_attempt_at <= ?
+ORDER BY id
+LIMIT ?`, now.UTC().Format(outboxTimeFormat), outboxBatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("list outbox: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []outboxEntry
+	for rows.Next() {
+		var entry outboxEntry
+		var raw string
+		if err := rows.Scan(&entry.ID, &entry.UserID, &entry.Effect, &raw, &entry.Attempts); err != nil {
+			return nil, fmt.Errorf("scan outbox: %w", err)
+		}
+		if err := json.Unmarshal([]byte(raw), &entry.Event); err != nil {
+			return nil, fmt.Errorf("decode outbox entry %d: %w", entry.ID, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// finishOutboxEntryLocked deletes a delivered entry, or records a failed attempt and schedules the next.
+// Entries that failed maxOutboxAttempts times are dropped.
+func (a *App) finishOutboxEntryLocked(entry outboxEntry, deliveryErr error, now time.Time) error {
+	// Nothing was sent while the endpoint's circuit is open, so the entry waits for the cooldown
+	// without using up an attempt.
+	var paused *ntfyCircuitOpenError
+	if errors.As(deliveryErr, &paused) {
+		if _, err := a.db.Exec(`UPDATE outbox SET last_error = ?, next_attempt_at = ? WHERE id = ?`, deliveryErr.Error(), paused.Until.UTC().Format(outboxTimeFormat), entry.ID); err != nil {
+			return fmt.Errorf("reschedule outbox entry: %w", err)
+		}
+		return nil
+	}
+	attempts := entry.Attempts + 1
+	if deliveryErr == nil || attempts >= maxOutboxAttempts {
+		if deliveryErr != nil {
+			log.Printf("%s for item %d dropped after %d attempts", entry.Effect, entry.Event.Item.ID, attempts)
+		}
+		if _, err := a.db.Exec(`DELETE FROM outbox WHERE id = ?`, entry.ID); err != nil {
+			return fmt.Errorf("delete outbox entry: %w", err)
+		}
+		return nil
+	}
+	next := now.Add(outboxRetryDelay(attempts))
+	if _, err := a.db.Exec(`UPDATE outbox SET attempts = ?, last_error = ?, next_attempt_at = ? WHERE id = ?`, attempts, deliveryErr.Error(), next.UTC().Format(outboxTimeFormat), entry.ID); err != nil {
+		return fmt.Errorf("reschedule outbox entry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package web_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestFireflyPushIsDeliveredByTheOutbox(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}},
+		Items: []webtest.Item{
+			{Profile: "Alex", Title: "Headphones", Price: 19990, Tags: "Audio", Status: "Bought", DecidedAt: time.Date(2026, 2, 3, 10, 0, 0, 0, time.UTC)},
+			{Profile: "Alex", Title: "Tent", Price: 8000},
+		},
+	})
+	var payees []string
+	firefly := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Transactions []struct {
+				Description string `json:"description"`
+			} `json:"transactions"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || len(payload.Transactions) != 1 {
+			t.Errorf("unexpected firefly payload: %v", err)
+			return
+		}
+		payees = append(payees, payload.Transactions[0].Description)
+	}))
+	defer firefly.Close()
+
+	alex := h.As("Alex")
+	alex.PostForm("/settings/exports", url.Values{"firefly_url": {firefly.URL}, "firefly_token": {"pat-123"}, "firefly_account": {"Checking"}}).
+		ExpectRedirect("/settings/exports?saved=1")
+	alex.PostForm("/exports/firefly/push", nil).ExpectRedirect("/settings/exports?queued=1")
+	alex.PostForm("/exports/firefly/push", nil).ExpectRedirect("/settings/exports?queued=1")
+	if len(payees) != 0 {
+		t.Fatalf("expected the request to leave the push to the outbox job, got %q", payees)
+	}
+
+	h.App.StartBackgroundOutbox(time.Hour)
+	h.App.RunJob("outbox")
+	if len(payees) != 1 || payees[0] != "Headphones" {
+		t.Fatalf("expected the bought item to be pushed once, got %q", payees)
+	}
+	alex.PostForm("/exports/firefly/push", nil).ExpectRedirect("/settings/exports?queued=0")
+}
+
+func TestApprovalRequestNotifiesTheApproverThroughTheOutbox(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}, {Name: "Bea"}},
+		Items:    []webtest.Item{{Profile: "Alex", Title: "Road bike", Price: 90000, Status: "Ready to buy"}},
+	})
+	var messages []string
+	ntfy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		messages = append(messages, r.URL.Path+" "+string(body))
+	}))
+	defer ntfy.Close()
+	if _, err := h.DB.Exec(`UPDATE profiles SET ntfy_endpoint = ?, ntfy_topic = 'bea' WHERE user_id = 'Bea'`, ntfy.URL); err != nil {
+		t.Fatalf("set ntfy settings: %v", err)
+	}
+
+	alex := h.As("Alex")
+	alex.PostForm("/settings/approvals", url.Values{"approval_threshold": {"500"}, "approver": {"Bea"}}).ExpectRedirect("/settings/approvals?saved=1")
+	alex.PostForm("/items/approval", url.Values{"item_id": {strconv.Itoa(h.Item("Alex", "Road bike").ID)}, "action": {"request"}}).ExpectStatus(http.StatusSeeOther)
+	if len(messages) != 0 {
+		t.Fatalf("expected the request to leave the notification to the outbox job, got %q", messages)
+	}
+
+	h.App.StartBackgroundOutbox(time.Hour)
+	h.App.RunJob("outbox")
+	if len(messages) != 1 || !strings.HasPrefix(messages[0], "/bea ") || !strings.Contains(messages[0], "Alex asks for approval to buy Road bike") {
+		t.Fatalf("expected one approval request to Bea, got %q", messages)
+	}
+}
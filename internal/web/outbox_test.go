@@ -0,0 +1,275 @@
+package web
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOutboxRetriesFailedNotificationsAfterARestart(t *testing.T) {
+	ntfyStatus := http.StatusInternalServerError
+	var topics []string
+	ntfyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		topics = append(topics, r.URL.Path)
+		w.WriteHeader(ntfyStatus)
+	}))
+	defer ntfyServer.Close()
+
+	dbPath := filepath.Join(t.TempDir(), "test.sqlite")
+	app, err := NewAppWithSQLite(dbPath)
+	if err != nil {
+		t.Fatalf("new sqlite app: %v", err)
+	}
+	app.mu.Lock()
+	for _, name := range []string{"Bea", "Alex"} {
+		app.activeUserID = name
+		app.hourlyWage = "25"
+		app.ntfyURL, app.ntfyTopic = "", ""
+		if name == "Alex" {
+			app.ntfyURL, app.ntfyTopic = ntfyServer.URL, "alex"
+		}
+		if err := app.persistProfileLocked(); err != nil {
+			app.mu.Unlock()
+			t.Fatalf("persist profile: %v", err)
+		}
+	}
+	item := Item{Title: "Headphones", Status: "Waiting", WaitPreset: "24h", PurchaseAllowedAt: time.Now().Add(-time.Minute), CreatedAt: time.Now().Add(-24 * time.Hour)}
+	if err := app.insertItemLocked(&item); err != nil {
+		app.mu.Unlock()
+		t.Fatalf("insert item: %v", err)
+	}
+	app.items = append(app.items, item)
+	app.mu.Unlock()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "active_profile", Value: "Alex"})
+	app.Handler().ServeHTTP(httptest.NewRecorder(), req)
+	if len(topics) != 0 {
+		t.Fatalf("expected the request to leave delivery to the outbox job, got %d attempts", len(topics))
+	}
+	app.StartBackgroundOutbox(time.Hour)
+	app.RunJob("outbox")
+	if len(topics) != 1 {
+		t.Fatalf("expected one ntfy attempt on promotion, got %d", len(topics))
+	}
+	var attempts int
+	var lastError string
+	if err := app.db.QueryRow(`SELECT attempts, last_error FROM outbox WHERE effect = ?`, effectNtfy).Scan(&attempts, &lastError); err != nil || attempts != 1 || lastError == "" {
+		t.Fatalf("expected the failed notification to stay in the outbox, got %d %q (%v)", attempts, lastError, err)
+	}
+	_ = app.Close()
+
+	restarted, err := NewAppWithSQLite(dbPath)
+	if err != nil {
+		t.Fatalf("restart sqlite app: %v", err)
+	}
+	defer restarted.Close()
+	ntfyStatus = http.StatusOK
+
+	restarted.mu.Lock()
+	defer restarted.mu.Unlock()
+	if err := restarted.activateProfileLocked("Bea"); err != nil {
+		t.Fatalf("activate profile: %v", err)
+	}
+	restarted.dispatchOutboxLocked(time.Now())
+	if len(topics) != 1 {
+		t.Fatalf("expected no retry before the backoff, got %d attempts", len(topics))
+	}
+	restarted.dispatchOutboxLocked(time.Now().Add(2 * time.Minute))
+	restarted.dispatchOutboxLocked(time.Now().Add(time.Hour))
+	if len(topics) != 2 || topics[1] != "/alex" {
+		t.Fatalf("expected exactly one retry to Alex's topic, got %v", topics)
+	}
+	if restarted.activeUserID != "Bea" {
+		t.Fatalf("expected the active profile to be restored, got %q", restarted.activeUserID)
+	}
+	var pending int
+	if err := restarted.db.QueryRow(`SELECT COUNT(*) FROM outbox`).Scan(&pending); err != nil || pending != 0 {
+		t.Fatalf("expected an empty outbox, got %d (%v)", pending, err)
+	}
+}
+
+// runOutbox delivers the due outbox entries, as the outbox job does.
+func runOutbox(app *App) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	app.dispatchOutboxLocked(time.Now())
+}
+
+func TestItemChangesTriggerTheOutboxJob(t *testing.T) {
+	topics := make(chan string, 1)
+	ntfyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		topics <- r.URL.Path
+	}))
+	defer ntfyServer.Close()
+
+	app, cleanup := newSQLiteTestApp(t)
+	defer cleanup()
+	app.mu.Lock()
+	app.activeUserID = "Alex"
+	app.hourlyWage = "25"
+	app.ntfyURL, app.ntfyTopic = ntfyServer.URL, "alex"
+	if err := app.persistProfileLocked(); err != nil {
+		app.mu.Unlock()
+		t.Fatalf("persist profile: %v", err)
+	}
+	app.mu.Unlock()
+
+	// After its run at start the job's next run is an hour away, so only a trigger delivers in time.
+	app.StartBackgroundOutbox(time.Hour)
+	app.StartJobs()
+	outboxRuns := func() int {
+		for _, job := range app.jobStatuses() {
+			if job.Name == "outbox" {
+				return job.Runs
+			}
+		}
+		return 0
+	}
+	for deadline := time.Now().Add(5 * time.Second); outboxRuns() == 0 && time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+	}
+
+	app.mu.Lock()
+	item := Item{Title: "Headphones", Status: "Waiting", WaitPreset: "24h", PurchaseAllowedAt: time.Now().Add(-time.Minute), CreatedAt: time.Now().Add(-24 * time.Hour)}
+	if err := app.insertItemLocked(&item); err != nil {
+		app.mu.Unlock()
+		t.Fatalf("insert item: %v", err)
+	}
+	app.items = append(app.items, item)
+	app.mu.Unlock()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "active_profile", Value: "Alex"})
+	app.Handler().ServeHTTP(httptest.NewRecorder(), req)
+
+	select {
+	case topic := <-topics:
+		if topic != "/alex" {
+			t.Fatalf("expected the notification on Alex's topic, got %s", topic)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected the outbox job to deliver the promotion")
+	}
+}
+
+func TestOutboxRetryDelayDoublesUpToAnHour(t *testing.T) {
+	for attempts, want := range map[int]time.Duration{1: time.Minute, 2: 2 * time.Minute, 6: 32 * time.Minute, 7: time.Hour, 20: time.Hour} {
+		if got := outboxRetryDelay(attempts); got != want {
+			t.Errorf("outboxRetryDelay(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
+
+func TestItemsReadyTogetherAreAnnouncedInOneMessage(t *testing.T) {
+	var messages []string
+	ntfyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		messages = append(messages, string(body))
+	}))
+	defer ntfyServer.Close()
+
+	app, err := NewAppWithSQLite(filepath.Join(t.TempDir(), "test.sqlite"))
+	if err != nil {
+		t.Fatalf("new sqlite app: %v", err)
+	}
+	defer app.Close()
+	app.SetNotificationCoalesceWindow(10 * time.Minute)
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	app.activeUserID = "Alex"
+	app.hourlyWage = "25"
+	app.ntfyURL, app.ntfyTopic = ntfyServer.URL, "alex"
+	if err := app.persistProfileLocked(); err != nil {
+		t.Fatalf("persist profile: %v", err)
+	}
+	insert := func(title string, unlock time.Time) {
+		item := Item{Title: title, Status: "Waiting", WaitPreset: "24h", PurchaseAllowedAt: unlock, CreatedAt: unlock.Add(-24 * time.Hour)}
+		if err := app.insertItemLocked(&item); err != nil {
+			t.Fatalf("insert item: %v", err)
+		}
+		app.items = append(app.items, item)
+	}
+	now := time.Now()
+	insert("Headphones", now.Add(-time.Minute))
+	insert("Keyboard", now.Add(-time.Minute))
+	insert("Lamp", now.Add(2*time.Minute))
+
+	app.promoteReadyItemsLocked(now)
+	if len(messages) != 0 {
+		t.Fatalf("expected notifications to wait for the coalescing window, got %v", messages)
+	}
+	app.promoteReadyItemsLocked(now.Add(3 * time.Minute))
+	app.dispatchOutboxLocked(now.Add(20 * time.Minute))
+	if len(messages) != 1 || !strings.HasPrefix(messages[0], "3 items are ready: Headphones, Keyboard, Lamp.") {
+		t.Fatalf("expected one combined message for three items, got %q", messages)
+	}
+	attempts, err := app.deliveryLogLocked("Alex", 10)
+	if err != nil || len(attempts) != 3 {
+		t.Fatalf("expected a delivery log entry per item, got %d (%v)", len(attempts), err)
+	}
+}
+
+func TestReadyMessageListsAFewTitles(t *testing.T) {
+	items := []Item{{Title: "A"}, {Title: "B"}, {Title: "C"}, {Title: "D"}, {Title: "E"}, {Title: "F"}, {Title: "G"}}
+	if got := readyMessage(items[:1]); got != "A is now ready to buy." {
+		t.Fatalf("unexpected single message %q", got)
+	}
+	if got := readyMessage(items); got != "7 items are ready: A, B, C, D, E, and 2 more." {
+		t.Fatalf("unexpected combined message %q", got)
+	}
+}
+
+func TestRetriedDeliveriesKeepTheirDeliveryID(t *testing.T) {
+	webhookStatus := http.StatusInternalServerError
+	var bodies [][]byte
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, body)
+		w.WriteHeader(webhookStatus)
+	}))
+	defer webhook.Close()
+
+	app, cleanup := newSQLiteTestApp(t)
+	defer cleanup()
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	app.activeUserID = "Alex"
+	app.hourlyWage = "25"
+	app.haWebhookURL = webhook.URL
+	if err := app.persistProfileLocked(); err != nil {
+		t.Fatalf("persist profile: %v", err)
+	}
+	now := time.Now()
+	for _, title := range []string{"Headphones", "Lamp"} {
+		item := Item{Title: title, Status: "Waiting", WaitPreset: "24h", PurchaseAllowedAt: now.Add(-time.Minute), CreatedAt: now.Add(-24 * time.Hour)}
+		if err := app.insertItemLocked(&item); err != nil {
+			t.Fatalf("insert item: %v", err)
+		}
+		app.items = append(app.items, item)
+	}
+	app.promoteReadyItemsLocked(now)
+	app.dispatchOutboxLocked(time.Now())
+	webhookStatus = http.StatusOK
+	app.dispatchOutboxLocked(time.Now().Add(2 * time.Minute))
+
+	if len(bodies) != 4 {
+		t.Fatalf("expected two failed and two retried events, got %d", len(bodies))
+	}
+	ids := make([]int64, len(bodies))
+	for i, body := range bodies {
+		assertMatchesSchema(t, "home-assistant-event.json", body)
+		var event homeAssistantEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			t.Fatalf("decode event: %v", err)
+		}
+		ids[i] = event.DeliveryID
+	}
+	if ids[0] == 0 || ids[0] == ids[1] || ids[2] != ids[0] || ids[3] != ids[1] {
+		t.Fatalf("expected each item's retry to repeat its own delivery id, got %v", ids)
+	}
+}
@@ -0,0 +1,30 @@
+package web
+
+// privateItemTitle stands in for the title of a private item.
+const privateItemTitle = "Private item"
+
+// maskPrivate hides what a private item is and what it costs, keeping its status and dates so boards
+// and counts still add up. Prices of private items therefore drop out of totals shown to others.
+func maskPrivate(item Item) Item {
+	if !item.Private {
+		return item
+	}
+	item.Title = privateItemTitle
+	item.Price = ""
+	item.PriceCents = 0
+	item.HasPriceValue = false
+	item.Link = ""
+	item.Note = ""
+	item.Tags = ""
+	item.ReceiptName = ""
+	return item
+}
+
+// maskPrivateItems returns a copy of items fit for the kiosk link, Home Assistant and the household page.
+func maskPrivateItems(items []Item) []Item {
+	masked := make([]Item, len(items))
+	for i, item := range items {
+		masked[i] = maskPrivate(item)
+	}
+	return masked
+}
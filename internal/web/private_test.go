@@ -0,0 +1,41 @@
+package web_test
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestPrivateItemsAreHiddenFromSharedViews(t *testing.T) {
+	now := time.Now()
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex", HourlyWage: "25"}},
+		Items:    []webtest.Item{{Profile: "Alex", Title: "Headphones", Price: 12000, PurchaseAllowedAt: now.Add(time.Hour)}},
+	})
+	h.App.SetAdminToken("s3cret")
+	alex := h.As("Alex")
+
+	alex.PostForm("/items/new", url.Values{"title": {"Engagement ring"}, "price": {"2500"}, "note": {"For June"}, "wait_preset": {"24h"}, "private": {"1"}}).
+		ExpectStatus(http.StatusSeeOther)
+	ring := strconv.Itoa(h.Item("Alex", "Engagement ring").ID)
+	alex.Get("/").ExpectContains("Engagement ring", "€ 2500.00", ">Private</span>")
+	alex.Get("/items/" + ring + "/edit").ExpectContains(`value="1" aria-describedby="private-help" checked`)
+
+	alex.PostForm("/settings/share", url.Values{"action": {"generate"}})
+	var token string
+	if err := h.DB.QueryRow(`SELECT share_token FROM profiles WHERE user_id = 'Alex'`).Scan(&token); err != nil || token == "" {
+		t.Fatalf("expected a share token, got %q: %v", token, err)
+	}
+	h.Anonymous().Get("/kiosk?token="+token).
+		ExpectContains("Headphones", "Private item").
+		ExpectNotContains("Engagement ring", "2500")
+	alex.Get("/household?token=s3cret").ExpectStatus(http.StatusOK).ExpectNotContains("Engagement ring")
+
+	alex.PostForm("/items/"+ring+"/edit", url.Values{"title": {"Engagement ring"}, "price": {"2500"}, "wait_preset": {"24h"}}).
+		ExpectStatus(http.StatusSeeOther)
+	h.Anonymous().Get("/kiosk?token=" + token).ExpectContains("Engagement ring")
+}
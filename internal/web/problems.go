@@ -0,0 +1,68 @@
+package web
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"mvpapp/internal/domain"
+)
+
+// problemContentType is the media type of RFC 7807 problem details.
+const problemContentType = "application/problem+json"
+
+// statusForError maps the kind of a domain error to an HTTP status: 404 for ErrNotFound, 409 for
+// ErrConflict, 422 for ErrValidation and 500 for anything else.
+func statusForError(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrValidation):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// errorPageData is the error page shown for failed form posts and page requests.
+type errorPageData struct {
+	Title           string
+	CurrentPath     string
+	ContentTemplate string
+	ScriptTemplate  string
+	ActiveProfile   string
+	Status          int
+	Message         string
+}
+
+// renderErrorPage shows message on an error page with the app's layout and navigation, instead of a
+// plain-text response.
+func (a *App) renderErrorPage(w http.ResponseWriter, r *http.Request, status int, message string) {
+	data := errorPageData{
+		Title:           http.StatusText(status),
+		CurrentPath:     r.URL.Path,
+		ContentTemplate: "error_content",
+		ActiveProfile:   a.activeProfileName(),
+		Status:          status,
+		Message:         message,
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := a.templates.ExecuteTemplate(w, "layout", data); err != nil {
+		log.Printf("template error: %v", err)
+	}
+}
+
+// renderDomainError shows the error page for err with the status of its kind. Unexpected errors are
+// logged and shown as fallback, so internal details stay out of the page.
+func (a *App) renderDomainError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	status := statusForError(err)
+	message := err.Error()
+	if status == http.StatusInternalServerError {
+		log.Printf("%s: %v", fallback, err)
+		message = fallback
+	}
+	a.renderErrorPage(w, r, status, message)
+}
@@ -0,0 +1,31 @@
+package web_test
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestFormErrorsRenderAnErrorPageWithTheStatusOfTheirKind(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}},
+		Items:    []webtest.Item{{Profile: "Alex", Title: "Desk", Status: "Waiting", PurchaseAllowedAt: time.Now().Add(24 * time.Hour)}},
+	})
+	alex := h.As("Alex")
+	desk := strconv.Itoa(h.Item("Alex", "Desk").ID)
+
+	res := alex.PostForm("/items/status", url.Values{"item_id": {desk}, "status": {"Bought"}}).
+		ExpectStatus(http.StatusConflict).
+		ExpectContains("<title>Conflict</title>", "status transition not allowed", `href="/">Back to the dashboard`)
+	if got := res.Header().Get("Content-Type"); got != "text/html; charset=utf-8" {
+		t.Fatalf("expected an HTML error page, got %q", got)
+	}
+
+	alex.PostForm("/items/snooze", url.Values{"item_id": {"999"}, "snooze_preset": {"24h"}}).
+		ExpectStatus(http.StatusNotFound).
+		ExpectContains("<title>Not Found</title>", "item not found")
+}
@@ -0,0 +1,142 @@
+package web
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"mvpapp/internal/domain"
+)
+
+const (
+	defaultProjectionRate  = 5.0
+	defaultProjectionYears = 10
+	maxProjectionRate      = 20.0
+	maxProjectionYears     = 50
+)
+
+type projectionSettingsForm struct {
+	Rate  string
+	Years string
+}
+
+// savingsProjection shows what the skipped amounts could grow to if they had been invested.
+type savingsProjection struct {
+	RatePercent float64
+	Years       int
+	// Saved is the total of the saved-amount trend and Grown what it becomes after Years at RatePercent.
+	Saved domain.Money
+	Grown domain.Money
+	// Average is the mean amount saved per trend period that had savings; Continued adds investing that
+	// amount every period of the horizon on top of Grown.
+	Average   domain.Money
+	Continued domain.Money
+	Period    string
+}
+
+// buildSavingsProjection compounds the saved-amount trend at an annual rate. It returns nil when nothing was saved.
+func buildSavingsProjection(trend []savedAmountPeriod, granularity string, ratePercent float64, years int) *savingsProjection {
+	var saved domain.Money
+	for _, period := range trend {
+		saved += period.Amount
+	}
+	if saved <= 0 {
+		return nil
+	}
+
+	periodsPerYear, period := 12.0, "month"
+	if granularity == trendGranularityWeek {
+		periodsPerYear, period = 52.0, "week"
+	}
+	rate := ratePercent / 100
+	average := saved / domain.Money(len(trend))
+	growth := math.Pow(1+rate, float64(years))
+	periods := float64(years) * periodsPerYear
+	contributions := average.Float() * periods
+	if periodRate := math.Pow(1+rate, 1/periodsPerYear) - 1; periodRate > 0 {
+		contributions = average.Float() * (math.Pow(1+periodRate, periods) - 1) / periodRate
+	}
+
+	grown := domain.MoneyFromFloat(saved.Float() * growth)
+	return &savingsProjection{
+		RatePercent: ratePercent,
+		Years:       years,
+		Saved:       saved,
+		Grown:       grown,
+		Average:     average,
+		Continued:   grown + domain.MoneyFromFloat(contributions),
+		Period:      period,
+	}
+}
+
+func parseProjectionSettings(form projectionSettingsForm) (string, int, error) {
+	rate := strings.TrimSpace(form.Rate)
+	if rate != "" {
+		parsed, err := strconv.ParseFloat(rate, 64)
+		if err != nil || parsed < 0 || parsed > maxProjectionRate {
+			return "", 0, errors.New("Please enter an annual rate between 0 and 20 percent.")
+		}
+	}
+	years := 0
+	if raw := strings.TrimSpace(form.Years); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxProjectionYears {
+			return "", 0, errors.New("Please enter a horizon between 1 and 50 years.")
+		}
+		years = parsed
+	}
+	return rate, years, nil
+}
+
+// projectionSettingsLocked returns the profile's rate and horizon, falling back to the defaults.
+func (a *App) projectionSettingsLocked() (float64, int) {
+	rate, err := strconv.ParseFloat(a.projectionRate, 64)
+	if err != nil || rate < 0 || rate > maxProjectionRate {
+		rate = defaultProjectionRate
+	}
+	years := a.projectionYears
+	if years < 1 || years > maxProjectionYears {
+		years = defaultProjectionYears
+	}
+	return rate, years
+}
+
+func (a *App) projectionSettingsFormLocked() projectionSettingsForm {
+	rate, years := a.projectionSettingsLocked()
+	return projectionSettingsForm{Rate: strconv.FormatFloat(rate, 'f', -1, 64), Years: strconv.Itoa(years)}
+}
+
+func (a *App) saveProjectionSettings(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	form := projectionSettingsForm{
+		Rate:  strings.TrimSpace(r.FormValue("projection_rate")),
+		Years: strings.TrimSpace(r.FormValue("projection_years")),
+	}
+	rate, years, err := parseProjectionSettings(form)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		a.renderInsights(w, insightsViewData{Title: "Insights", CurrentPath: "/insights", ProjectionSettings: form, Error: err.Error()})
+		return
+	}
+
+	a.mu.LockContext(r.Context())
+	a.projectionRate = rate
+	a.projectionYears = years
+	if err := a.persistProfileLocked(); err != nil {
+		a.mu.Unlock()
+		log.Printf("db error while saving projection settings: %v", err)
+		http.Error(w, "could not save projection settings", http.StatusInternalServerError)
+		return
+	}
+	a.publishProfileUpdatedLocked("savings projection", r)
+	a.mu.Unlock()
+
+	http.Redirect(w, r, "/insights?saved=projection", http.StatusSeeOther)
+}
@@ -0,0 +1,34 @@
+package web_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestSaveProjectionSettingsPersistsAndValidates(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}},
+		Items:    []webtest.Item{{Profile: "Alex", Title: "Drone", Price: 50000, Status: "Skipped", DecidedAt: time.Now()}},
+	})
+	alex := h.As("Alex")
+
+	alex.PostForm("/insights/projection", url.Values{"projection_rate": {"25"}, "projection_years": {"10"}}).
+		ExpectStatus(http.StatusBadRequest).ExpectContains("between 0 and 20 percent")
+	alex.PostForm("/insights/projection", url.Values{"projection_rate": {"5"}, "projection_years": {"0"}}).ExpectStatus(http.StatusBadRequest)
+	alex.PostForm("/insights/projection", url.Values{"projection_rate": {"7"}, "projection_years": {"20"}}).ExpectRedirect("/insights?saved=projection")
+
+	var rate string
+	var years int
+	if err := h.DB.QueryRow(`SELECT projection_rate, projection_years FROM profiles WHERE user_id = 'Alex'`).Scan(&rate, &years); err != nil {
+		t.Fatalf("load projection settings: %v", err)
+	}
+	if rate != "7" || years != 20 {
+		t.Fatalf("unexpected persisted projection settings: %q %d", rate, years)
+	}
+
+	alex.Get("/insights").ExpectStatus(http.StatusOK).ExpectContains("€ 500.00 saved grows to", "€ 1934.84", "After 20 years at 7% a year")
+}
@@ -0,0 +1,23 @@
+package web
+
+import (
+	"testing"
+)
+
+func TestBuildSavingsProjectionCompoundsTheSavedTrend(t *testing.T) {
+	trend := []savedAmountPeriod{{Period: "2026-01", Amount: 10000}, {Period: "2026-02", Amount: 30000}}
+
+	got := buildSavingsProjection(trend, trendGranularityMonth, 10, 1)
+	if got.Saved != 40000 || got.Grown != 44000 || got.Average != 20000 || got.Continued != 294811 || got.Period != "month" {
+		t.Fatalf("unexpected projection %+v", got)
+	}
+
+	got = buildSavingsProjection(trend, trendGranularityWeek, 0, 2)
+	if got.Grown != 40000 || got.Continued != 40000+20000*104 {
+		t.Fatalf("expected a zero rate to add contributions without growth, got %+v", got)
+	}
+
+	if got := buildSavingsProjection(nil, trendGranularityMonth, 5, 10); got != nil {
+		t.Fatalf("expected no projection without savings, got %+v", got)
+	}
+}
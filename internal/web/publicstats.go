@@ -0,0 +1,155 @@
+package web
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+// publicStats are the instance-wide totals on the opt-in public stats page. They are sums over all
+// profiles and carry nothing that identifies a profile or an item.
+type publicStats struct {
+	Profiles int
+	Skipped  int
+	Bought   int
+	// Saved holds the prices of skipped items per currency, as amounts in different currencies do not add up.
+	// Private items count as skipped, but their prices are left out.
+	Saved []publicStatsAmount
+}
+
+type publicStatsAmount struct {
+	Currency string
+	Amount   domain.Money
+}
+
+// SkipRate is the percentage of decided items that were skipped, 0 before any decision.
+func (s publicStats) SkipRate() int {
+	decided := s.Skipped + s.Bought
+	if decided == 0 {
+		return 0
+	}
+	return (s.Skipped*100 + decided/2) / decided
+}
+
+type publicStatsViewData struct {
+	Title       string
+	Stats       publicStats
+	GeneratedAt time.Time
+}
+
+// SetPublicStats turns the anonymous /stats page on. It is off unless the instance opts in.
+func (a *App) SetPublicStats(enabled bool) {
+	a.mu.Lock()
+	a.publicStats = enabled
+	a.mu.Unlock()
+}
+
+// addPublicStats counts count decided items of a profile with the given currency, priced price in total,
+// into stats.
+func addPublicStats(stats *publicStats, saved map[string]domain.Money, currency string, status domain.Status, count int, price domain.Money, private bool) {
+	switch status {
+	case domain.StatusSkipped:
+		stats.Skipped += count
+		if !private {
+			saved[profileCurrencyOrDefault(currency)] += price
+		}
+	case domain.StatusBought:
+		stats.Bought += count
+	}
+}
+
+func finishPublicStats(stats *publicStats, saved map[string]domain.Money) {
+	if len(saved) == 0 {
+		saved[profileCurrencyOrDefault("")] = 0
+	}
+	for currency, amount := range saved {
+		stats.Saved = append(stats.Saved, publicStatsAmount{Currency: currency, Amount: amount})
+	}
+	slices.SortFunc(stats.Saved, func(a, b publicStatsAmount) int {
+		if a.Amount != b.Amount {
+			return int(b.Amount - a.Amount)
+		}
+		return strings.Compare(a.Currency, b.Currency)
+	})
+}
+
+// publicStatsLocked aggregates decisions and savings across all profiles in the database. The demo
+// profile is left out while demo mode resets it, as its sample data is not anyone's savings.
+func (a *App) publicStatsLocked() (publicStats, error) {
+	var stats publicStats
+	saved := map[string]domain.Money{}
+	if a.db == nil {
+		stats.Profiles = 1
+		for _, item := range a.items {
+			price := domain.Money(0)
+			if item.HasPriceValue {
+				price = item.PriceCents
+			}
+			addPublicStats(&stats, saved, a.currency, item.Status, 1, price, item.Private)
+		}
+		finishPublicStats(&stats, saved)
+		return stats, nil
+	}
+
+	excluded := ""
+	if a.demoModeLocked() {
+		excluded = demoProfileName
+	}
+	if err := a.db.QueryRow(`SELECT COUNT(*) FROM profiles WHERE user_id != ?`, excluded).Scan(&stats.Profiles); err != nil {
+		return publicStats{}, fmt.Errorf("count profiles: %w", err)
+	}
+	rows, err := a.db.Query(`
+SELECT COALESCE(p.currency, ''), i.status, i.private, COUNT(*), COALESCE(SUM(CASE WHEN i.has_price_value = 1 THEN i.price_cents ELSE 0 END), 0)
+FROM items i
+LEFT JOIN profiles p ON p.user_id = i.user_id
+WHERE i.status IN (?, ?) AND i.user_id != ?
+GROUP BY p.currency, i.status, i.private
+`, domain.StatusSkipped, domain.StatusBought, excluded)
+	if err != nil {
+		return publicStats{}, fmt.Errorf("aggregate public stats: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var currency string
+		var status domain.Status
+		var private bool
+		var count int
+		var price domain.Money
+		if err := rows.Scan(&currency, &status, &private, &count, &price); err != nil {
+			return publicStats{}, fmt.Errorf("scan public stats: %w", err)
+		}
+		addPublicStats(&stats, saved, currency, status, count, price, private)
+	}
+	if err := rows.Err(); err != nil {
+		return publicStats{}, fmt.Errorf("iterate public stats: %w", err)
+	}
+	finishPublicStats(&stats, saved)
+	return stats, nil
+}
+
+func (a *App) publicStatsPage(w http.ResponseWriter, r *http.Request) {
+	a.mu.LockContext(r.Context())
+	if !a.publicStats {
+		a.mu.Unlock()
+		http.NotFound(w, r)
+		return
+	}
+	stats, err := a.publicStatsLocked()
+	a.mu.Unlock()
+	if err != nil {
+		log.Printf("db error while loading public stats: %v", err)
+		http.Error(w, "could not load stats", http.StatusInternalServerError)
+		return
+	}
+
+	renderTemplate(w, a.templates, "public_stats", publicStatsViewData{
+		Title:       "Impulse Pause stats",
+		Stats:       stats,
+		GeneratedAt: time.Now(),
+	})
+}
@@ -0,0 +1,34 @@
+package web_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestPublicStatsAreOptInAndAggregateWithoutNames(t *testing.T) {
+	decided := time.Now().Add(-time.Hour)
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}, {Name: "Sam", Currency: "USD"}},
+		Items: []webtest.Item{
+			{Profile: "Alex", Title: "Drone", Price: 30000, Status: "Skipped", DecidedAt: decided},
+			{Profile: "Alex", Title: "Secret gift", Price: 8000, Status: "Skipped", DecidedAt: decided},
+			{Profile: "Alex", Title: "Socks", Price: 1000, Status: "Bought", DecidedAt: decided},
+			{Profile: "Sam", Title: "Watch", Price: 15000, Status: "Skipped", DecidedAt: decided},
+			{Profile: "Sam", Title: "Lamp", Price: 4000},
+		},
+	})
+	if _, err := h.DB.Exec(`UPDATE items SET private = 1 WHERE title = 'Secret gift'`); err != nil {
+		t.Fatalf("mark item private: %v", err)
+	}
+
+	h.Anonymous().Get("/stats").ExpectStatus(http.StatusNotFound)
+
+	h.App.SetPublicStats(true)
+	h.Anonymous().Get("/stats").
+		ExpectStatus(http.StatusOK).
+		ExpectContains("Totals across 2 profiles", "€ 300.00", "$ 150.00", "<strong>3</strong> impulse purchases skipped", "<strong>75%</strong> of decisions").
+		ExpectNotContains("Alex", "Sam", "Drone", "Watch", "€ 380.00")
+}
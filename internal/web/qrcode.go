@@ -0,0 +1,72 @@
+package web
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// qrCodeSize is the edge length of settings QR codes in pixels, large enough for phone cameras at arm's length.
+const qrCodeSize = 320
+
+// quickAddURLLocked returns the link that opens the add form on another device as the active profile.
+func (a *App) quickAddURLLocked() string {
+	return a.dashboardLink() + "quick-add?profile=" + url.QueryEscape(a.currentUserIDLocked())
+}
+
+// settingsQRCode renders the share link (for=share) or the quick-add link (for=quick-add) of the active
+// profile as a PNG QR code, so a phone can pick them up from the settings page.
+func (a *App) settingsQRCode(w http.ResponseWriter, r *http.Request) {
+	a.mu.RLock()
+	var target string
+	switch r.URL.Query().Get("for") {
+	case "share":
+		target = a.shareURLLocked()
+	case "quick-add":
+		if strings.TrimSpace(a.activeUserID) != "" {
+			target = a.quickAddURLLocked()
+		}
+	}
+	a.mu.RUnlock()
+	if target == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	png, err := qrcode.Encode(target, qrcode.Medium, qrCodeSize)
+	if err != nil {
+		log.Printf("could not encode qr code: %v", err)
+		http.Error(w, "could not render qr code", http.StatusInternalServerError)
+		return
+	}
+	// The share code carries the share token, so it is kept out of caches like the link itself.
+	setShareHeaders(w)
+	w.Header().Set("Content-Type", "image/png")
+	_, _ = w.Write(png)
+}
+
+// quickAdd is where the quick-add QR code leads: it selects the named profile on this device and opens the
+// add form. Profiles are chosen freely on the switch page as well, so the link grants nothing new; unknown
+// and archived profiles go to the switch page instead of being created.
+func (a *App) quickAdd(w http.ResponseWriter, r *http.Request) {
+	names, err := a.listProfileNames()
+	if err == nil {
+		names, err = a.withoutArchivedProfiles(names)
+	}
+	if err != nil {
+		log.Printf("db error while loading profiles for quick add: %v", err)
+		http.Error(w, "could not load profiles", http.StatusInternalServerError)
+		return
+	}
+	name := r.URL.Query().Get("profile")
+	if !slices.Contains(names, name) {
+		http.Redirect(w, r, "/switch-profile", http.StatusSeeOther)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: "active_profile", Value: name, Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode})
+	http.Redirect(w, r, "/items/new", http.StatusSeeOther)
+}
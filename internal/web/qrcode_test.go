@@ -0,0 +1,46 @@
+package web_test
+
+import (
+	"bytes"
+	"image/png"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestSettingsShowQRCodesForShareAndQuickAdd(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{Profiles: []webtest.Profile{{Name: "Alex", HourlyWage: "25"}}})
+	alex := h.As("Alex")
+
+	alex.Get("/settings/profile").
+		ExpectContains(`src="/settings/qr.png?for=quick-add"`, `value="http://localhost:8080/quick-add?profile=Alex"`).
+		ExpectNotContains(`src="/settings/qr.png?for=share"`)
+	alex.Get("/settings/qr.png?for=share").ExpectStatus(http.StatusNotFound)
+
+	alex.PostForm("/settings/share", url.Values{"action": {"generate"}})
+	alex.Get("/settings/profile").ExpectContains(`src="/settings/qr.png?for=share"`)
+	for _, target := range []string{"share", "quick-add"} {
+		res := alex.Get("/settings/qr.png?for=" + target).ExpectStatus(http.StatusOK)
+		if got := res.Header().Get("Content-Type"); got != "image/png" {
+			t.Fatalf("expected a png for %s, got %q", target, got)
+		}
+		if res.Header().Get("Cache-Control") != "no-store" {
+			t.Fatalf("expected the %s code to stay out of caches", target)
+		}
+		if _, err := png.Decode(bytes.NewReader(res.ResponseRecorder.Body.Bytes())); err != nil {
+			t.Fatalf("decode %s code: %v", target, err)
+		}
+	}
+}
+
+func TestQuickAddLinkSelectsExistingProfileOnly(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{Profiles: []webtest.Profile{{Name: "Alex", HourlyWage: "25"}}})
+
+	res := h.Anonymous().Get("/quick-add?profile=Alex").ExpectRedirect("/items/new")
+	if cookie := res.Result().Cookies(); len(cookie) != 1 || cookie[0].Value != "Alex" {
+		t.Fatalf("expected the quick-add link to select Alex, got %v", cookie)
+	}
+	h.Anonymous().Get("/quick-add?profile=Mallory").ExpectRedirect("/switch-profile")
+}
@@ -0,0 +1,171 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+// badRow is a row that would stop the state from loading, or that points at an item that no longer exists.
+type badRow struct {
+	Table  string
+	RowID  int64
+	Reason string
+}
+
+// itemTimestampColumns are the item columns loadStateFromDB parses, and whether an empty value is allowed.
+var itemTimestampColumns = []struct {
+	Name     string
+	Optional bool
+}{
+	{"purchase_allowed_at", false},
+	{"created_at", false},
+	{"decided_at", true},
+	{"notified_at", true},
+}
+
+// itemChildTables hold rows that belong to an item and are orphaned once it is gone.
+var itemChildTables = []string{"item_shares", "item_history", "item_receipts"}
+
+// quarantineBadRows moves rows that would fail the state load, such as items with unparsable
+// timestamps, and rows orphaned by a missing item into quarantined_rows, so the app starts with the
+// rest of the data. Each quarantined row is kept there as JSON and logged, for repair by hand.
+func (a *App) quarantineBadRows() error {
+	if a.db == nil {
+		return nil
+	}
+	bad, err := findBadItemRows(a.db)
+	if err != nil {
+		return err
+	}
+	tx, err := a.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin quarantine: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	quarantined := 0
+	for _, row := range bad {
+		if err := quarantineRowTx(tx, row, now); err != nil {
+			return err
+		}
+		quarantined++
+	}
+	// Orphans are looked for after the bad items are gone, so their shares and history go along.
+	for _, table := range itemChildTables {
+		orphans, err := findOrphanedRows(tx, table)
+		if err != nil {
+			return err
+		}
+		for _, row := range orphans {
+			if err := quarantineRowTx(tx, row, now); err != nil {
+				return err
+			}
+			quarantined++
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit quarantine: %w", err)
+	}
+	if quarantined > 0 {
+		log.Printf("moved %d unreadable or orphaned rows to quarantined_rows; the app starts without them", quarantined)
+	}
+	return nil
+}
+
+// findBadItemRows returns the items whose timestamps cannot be parsed.
+func findBadItemRows(db *scopedDB) ([]badRow, error) {
+	rows, err := db.Query(`SELECT id, purchase_allowed_at, created_at, decided_at, notified_at FROM items`)
+	if err != nil {
+		return nil, fmt.Errorf("check items: %w", err)
+	}
+	defer rows.Close()
+
+	var bad []badRow
+	for rows.Next() {
+		var id int64
+		values := make([]string, len(itemTimestampColumns))
+		if err := rows.Scan(&id, &values[0], &values[1], &values[2], &values[3]); err != nil {
+			return nil, fmt.Errorf("scan item timestamps: %w", err)
+		}
+		for i, column := range itemTimestampColumns {
+			if values[i] == "" && column.Optional {
+				continue
+			}
+			if _, err := time.Parse(time.RFC3339Nano, values[i]); err != nil {
+				bad = append(bad, badRow{Table: "items", RowID: id, Reason: fmt.Sprintf("unparsable %s %q", column.Name, values[i])})
+				break
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate items: %w", err)
+	}
+	return bad, nil
+}
+
+// findOrphanedRows returns the rows of table whose item_id matches no item.
+func findOrphanedRows(tx *scopedTx, table string) ([]badRow, error) {
+	rows, err := tx.Query(`SELECT rowid, item_id FROM ` + table + ` WHERE item_id NOT IN (SELECT id FROM items)`)
+	if err != nil {
+		return nil, fmt.Errorf("check %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var orphans []badRow
+	for rows.Next() {
+		var rowID, itemID int64
+		if err := rows.Scan(&rowID, &itemID); err != nil {
+			return nil, fmt.Errorf("scan %s: %w", table, err)
+		}
+		orphans = append(orphans, badRow{Table: table, RowID: rowID, Reason: fmt.Sprintf("item %d does not exist", itemID)})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate %s: %w", table, err)
+	}
+	return orphans, nil
+}
+
+// quarantineRowTx copies a row as JSON into quarantined_rows and deletes it from its table.
+func quarantineRowTx(tx *scopedTx, row badRow, now string) error {
+	rows, err := tx.Query(`SELECT * FROM `+row.Table+` WHERE rowid = ?`, row.RowID)
+	if err != nil {
+		return fmt.Errorf("read %s row %d: %w", row.Table, row.RowID, err)
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		rows.Close()
+		return fmt.Errorf("read %s columns: %w", row.Table, err)
+	}
+	record := map[string]any{}
+	if rows.Next() {
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan %s row %d: %w", row.Table, row.RowID, err)
+		}
+		for i, column := range columns {
+			record[column] = values[i]
+		}
+	}
+	rows.Close()
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("encode %s row %d: %w", row.Table, row.RowID, err)
+	}
+	if _, err := tx.Exec(`INSERT INTO quarantined_rows(source_table, row_id, reason, data, quarantined_at) VALUES (?, ?, ?, ?, ?)`, row.Table, row.RowID, row.Reason, string(data), now); err != nil {
+		return fmt.Errorf("quarantine %s row %d: %w", row.Table, row.RowID, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM `+row.Table+` WHERE rowid = ?`, row.RowID); err != nil {
+		return fmt.Errorf("remove %s row %d: %w", row.Table, row.RowID, err)
+	}
+	log.Printf("quarantined %s row %d: %s", row.Table, row.RowID, row.Reason)
+	return nil
+}
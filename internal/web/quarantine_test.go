@@ -0,0 +1,64 @@
+package web
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStartupQuarantinesUnreadableAndOrphanedRows(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.sqlite")
+	app, err := NewAppWithSQLite(dbPath)
+	if err != nil {
+		t.Fatalf("new sqlite app: %v", err)
+	}
+	for _, stmt := range []string{
+		`INSERT INTO profiles(user_id, hourly_wage, updated_at) VALUES ('Alex', '25', '')`,
+		`INSERT INTO items(id, user_id, title, status, wait_preset, purchase_allowed_at, created_at) VALUES (1, 'Alex', 'Lamp', 'Waiting', '24h', '2026-01-02T00:00:00Z', '2026-01-01T00:00:00Z')`,
+		`INSERT INTO items(id, user_id, title, status, wait_preset, purchase_allowed_at, created_at) VALUES (2, 'Alex', 'Broken', 'Waiting', '24h', '2026-01-02T00:00:00Z', 'yesterday')`,
+		`INSERT INTO item_history(item_id, user_id, action, created_at) VALUES (2, 'Alex', 'created', '')`,
+		`INSERT INTO item_shares(item_id, user_id, created_at) VALUES (99, 'Sam', '')`,
+	} {
+		if _, err := app.db.Exec(stmt); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+	app.Close()
+
+	app, err = NewAppWithSQLite(dbPath)
+	if err != nil {
+		t.Fatalf("expected the app to start despite the bad rows, got %v", err)
+	}
+	defer app.Close()
+	if err := app.loadStateFromDB("Alex"); err != nil {
+		t.Fatalf("load state: %v", err)
+	}
+	if len(app.items) != 1 || app.items[0].Title != "Lamp" {
+		t.Fatalf("expected only the readable item, got %+v", app.items)
+	}
+
+	rows, err := app.db.Query(`SELECT source_table, row_id, reason, data FROM quarantined_rows ORDER BY id`)
+	if err != nil {
+		t.Fatalf("query quarantined rows: %v", err)
+	}
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		var table, reason, data string
+		var rowID int
+		if err := rows.Scan(&table, &rowID, &reason, &data); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		got = append(got, table+": "+reason)
+		if table == "items" && !strings.Contains(data, `"title":"Broken"`) {
+			t.Fatalf("expected the quarantined item to keep its data, got %s", data)
+		}
+	}
+	want := []string{`items: unparsable created_at "yesterday"`, "item_shares: item 99 does not exist", "item_history: item 2 does not exist"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected quarantined rows:\n%s", strings.Join(got, "\n"))
+	}
+	if settings := app.databaseSettings(); settings.Quarantined != 3 {
+		t.Fatalf("expected the household page to count 3 quarantined rows, got %d", settings.Quarantined)
+	}
+}
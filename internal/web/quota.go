@@ -0,0 +1,46 @@
+package web
+
+import "fmt"
+
+// SetItemQuota caps the items each profile may own, so one profile cannot grow a shared instance without
+// bound. Items shared with a profile count for their owner only. Zero or less is unlimited.
+func (a *App) SetItemQuota(maxItems int) {
+	if maxItems < 0 {
+		maxItems = 0
+	}
+	a.mu.Lock()
+	a.itemQuota = maxItems
+	a.mu.Unlock()
+}
+
+// SetAttachmentQuota caps the bytes of receipts each profile may store. Receipts count for the owner of
+// their item, and replacing a receipt only counts the difference. Zero or less is unlimited.
+func (a *App) SetAttachmentQuota(maxBytes int64) {
+	if maxBytes < 0 {
+		maxBytes = 0
+	}
+	a.mu.Lock()
+	a.attachmentQuota = maxBytes
+	a.mu.Unlock()
+}
+
+func itemQuotaMessage(maxItems int) string {
+	return fmt.Sprintf("This profile has reached its limit of %d items. Delete items you no longer need to add new ones.", maxItems)
+}
+
+func attachmentQuotaMessage(maxBytes int64) string {
+	return fmt.Sprintf("This receipt would exceed the profile's limit of %s for receipts. Delete items with receipts you no longer need to add new ones.", formatBytes(maxBytes))
+}
+
+// attachmentBytesLocked sums the receipts stored for items owned by profile, leaving out the receipt of
+// exceptItemID so a replacement can be checked against what remains.
+func (a *App) attachmentBytesLocked(profile string, exceptItemID int) (int64, error) {
+	var used int64
+	if err := a.db.QueryRow(`
+SELECT COALESCE(SUM(LENGTH(r.data)), 0) FROM item_receipts r JOIN items i ON i.id = r.item_id
+WHERE i.user_id = ? AND r.item_id <> ?
+`, profile, exceptItemID).Scan(&used); err != nil {
+		return 0, fmt.Errorf("sum receipt sizes: %w", err)
+	}
+	return used, nil
+}
@@ -0,0 +1,64 @@
+package web_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestItemQuotaStopsNewItemsAndShowsUsage(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}, {Name: "Sam"}},
+		Items: []webtest.Item{
+			{Profile: "Alex", Title: "Desk lamp"},
+			{Profile: "Alex", Title: "Tent"},
+		},
+	})
+	h.App.SetItemQuota(2)
+	alex := h.As("Alex")
+
+	alex.PostForm("/items/new", url.Values{"title": {"Headphones"}, "wait_preset": {"24h"}}).
+		ExpectStatus(http.StatusConflict).
+		ExpectContains("This profile has reached its limit of 2 items.", `value="Headphones"`)
+	alex.PostJSON("/api/v1/items", map[string]any{"title": "Headphones"}).
+		ExpectStatus(http.StatusConflict).
+		ExpectContains(`"error":"This profile has reached its limit of 2 items.`)
+	if items := h.Items("Alex"); len(items) != 2 {
+		t.Fatalf("expected no item beyond the quota, got %d", len(items))
+	}
+	alex.Get("/settings/data").ExpectStatus(http.StatusOK).ExpectContains("Storage: 2 of 2 items used.", "The limit is reached")
+
+	h.As("Sam").PostJSON("/api/v1/items", map[string]any{"title": "Headphones"}).ExpectStatus(http.StatusCreated)
+}
+
+func TestAttachmentQuotaStopsReceiptsAndShowsUsage(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}, {Name: "Sam"}},
+		Items: []webtest.Item{
+			{Profile: "Alex", Title: "Headphones", Status: "Bought"},
+			{Profile: "Alex", Title: "Tent", Status: "Bought"},
+			{Profile: "Sam", Title: "Kettle", Status: "Bought"},
+		},
+	})
+	h.App.SetAttachmentQuota(1024)
+	alex := h.As("Alex")
+	receipt := func(size int) string {
+		return testReceiptPDF + strings.Repeat("%", size-len(testReceiptPDF))
+	}
+
+	postReceipt(t, alex, h.Item("Alex", "Headphones").ID, "", "headphones.pdf", receipt(600)).ExpectStatus(http.StatusSeeOther)
+	postReceipt(t, alex, h.Item("Alex", "Tent").ID, "", "tent.pdf", receipt(600)).
+		ExpectStatus(http.StatusConflict).
+		ExpectContains("would exceed the profile's limit of 1.0 KB for receipts")
+	var receipts int
+	if err := h.DB.QueryRow(`SELECT COUNT(*) FROM item_receipts WHERE item_id = ?`, h.Item("Alex", "Tent").ID).Scan(&receipts); err != nil || receipts != 0 {
+		t.Fatalf("expected no receipt beyond the quota, got %d (%v)", receipts, err)
+	}
+	postReceipt(t, alex, h.Item("Alex", "Headphones").ID, "", "headphones.pdf", receipt(1024)).ExpectStatus(http.StatusSeeOther)
+	alex.Get("/settings/data").ExpectStatus(http.StatusOK).ExpectContains("Receipts: 1.0 KB of 1.0 KB used.", "delete items with receipts you no longer need")
+
+	postReceipt(t, h.As("Sam"), h.Item("Sam", "Kettle").ID, "", "kettle.pdf", receipt(600)).ExpectStatus(http.StatusSeeOther)
+}
@@ -0,0 +1,216 @@
+package web
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+const (
+	// maxReceiptSize caps an uploaded receipt; phone photos of a receipt stay well below it.
+	maxReceiptSize = 5 << 20
+	// maxReceiptNameRunes keeps long camera or shop file names readable on the edit page.
+	maxReceiptNameRunes = 80
+)
+
+// receiptContentTypes are the sniffed types accepted as receipts. The browser's own claim is ignored.
+var receiptContentTypes = []string{"application/pdf", "image/jpeg", "image/png", "image/webp"}
+
+// receiptFileName keeps the base name of an uploaded file, without directories from older browsers.
+func receiptFileName(raw string) string {
+	name := strings.TrimSpace(filepath.Base(strings.ReplaceAll(raw, `\`, "/")))
+	if name == "" || name == "." || name == "/" {
+		return "receipt"
+	}
+	if runes := []rune(name); len(runes) > maxReceiptNameRunes {
+		name = string(runes[len(runes)-maxReceiptNameRunes:])
+	}
+	return name
+}
+
+func (a *App) saveReceiptLocked(itemID int, name, contentType string, data []byte) error {
+	tx, err := a.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin receipt tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.Exec(`
+INSERT INTO item_receipts(item_id, content_type, data, uploaded_at) VALUES (?, ?, ?, ?)
+ON CONFLICT(item_id) DO UPDATE SET content_type = excluded.content_type, data = excluded.data, uploaded_at = excluded.uploaded_at
+`, itemID, contentType, data, time.Now().Format(time.RFC3339Nano)); err != nil {
+		return fmt.Errorf("store receipt: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE items SET receipt_name = ? WHERE id = ?`, name, itemID); err != nil {
+		return fmt.Errorf("record receipt name: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit receipt tx: %w", err)
+	}
+	return nil
+}
+
+// saveReceipt records what a bought item finally cost and stores its receipt. Both are optional, so
+// the price can be corrected without a file and a receipt added without repeating the price.
+func (a *App) saveReceipt(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxReceiptSize+64<<10)
+	if err := r.ParseMultipartForm(maxReceiptSize); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("receipts can be at most %d MB", maxReceiptSize>>20), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	id, err := strconv.Atoi(strings.TrimSpace(r.FormValue("item_id")))
+	if err != nil || id <= 0 {
+		http.Error(w, "invalid item id", http.StatusBadRequest)
+		return
+	}
+	var paid domain.Money
+	if raw := strings.TrimSpace(r.FormValue("paid_price")); raw != "" {
+		if paid, err = domain.ParseMoney(raw); err != nil {
+			http.Error(w, "invalid paid price", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var name, contentType string
+	var data []byte
+	if file, header, err := r.FormFile("receipt"); err == nil {
+		data, err = io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			http.Error(w, "could not read upload", http.StatusBadRequest)
+			return
+		}
+		if len(data) > maxReceiptSize {
+			http.Error(w, fmt.Sprintf("receipts can be at most %d MB", maxReceiptSize>>20), http.StatusRequestEntityTooLarge)
+			return
+		}
+		contentType, _, _ = mime.ParseMediaType(http.DetectContentType(data))
+		if !slices.Contains(receiptContentTypes, contentType) {
+			http.Error(w, "receipts must be a PDF, JPEG, PNG or WebP file", http.StatusUnsupportedMediaType)
+			return
+		}
+		name = receiptFileName(header.Filename)
+	}
+	if paid == 0 && data == nil {
+		http.Error(w, "add a paid price or a receipt file", http.StatusBadRequest)
+		return
+	}
+
+	a.mu.LockContext(r.Context())
+	defer a.mu.Unlock()
+
+	if a.db == nil {
+		http.Error(w, "receipts require persistent storage", http.StatusConflict)
+		return
+	}
+	i := a.itemIndexLocked(id)
+	if i < 0 {
+		http.NotFound(w, r)
+		return
+	}
+	if a.items[i].Status != domain.StatusBought {
+		http.Error(w, "receipts can only be added to bought items", http.StatusConflict)
+		return
+	}
+
+	if data != nil && a.attachmentQuota > 0 {
+		owner := a.items[i].OwnerID
+		if owner == "" {
+			owner = a.currentUserIDLocked()
+		}
+		used, err := a.attachmentBytesLocked(owner, id)
+		if err != nil {
+			log.Printf("db error while checking receipt quota: %v", err)
+			http.Error(w, "could not save receipt", http.StatusInternalServerError)
+			return
+		}
+		if used+int64(len(data)) > a.attachmentQuota {
+			http.Error(w, attachmentQuotaMessage(a.attachmentQuota), http.StatusConflict)
+			return
+		}
+	}
+
+	if paid > 0 {
+		previous := a.items[i]
+		a.items[i].Price = paid.String()
+		a.items[i].PriceCents = paid
+		a.items[i].HasPriceValue = true
+		if err := a.updateItemLocked(a.items[i]); err != nil {
+			a.items[i] = previous
+			log.Printf("db error while saving paid price: %v", err)
+			http.Error(w, "could not save receipt", http.StatusInternalServerError)
+			return
+		}
+		a.recordHistoryLocked(id, "paid", formatMoney(paid, a.currency))
+	}
+	if data != nil {
+		if err := a.saveReceiptLocked(id, name, contentType, data); err != nil {
+			log.Printf("db error while saving receipt: %v", err)
+			http.Error(w, "could not save receipt", http.StatusInternalServerError)
+			return
+		}
+		a.items[i].ReceiptName = name
+		a.recordHistoryLocked(id, "added a receipt", name)
+	}
+
+	itemActionRedirect(w, r, "receipt", a.items[i].Title)
+}
+
+// serveReceipt shows a receipt to the profiles that can see its item.
+func (a *App) serveReceipt(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	a.mu.RLock()
+	db := a.db
+	name := ""
+	if i := a.itemIndexLocked(id); i >= 0 {
+		name = a.items[i].ReceiptName
+	}
+	a.mu.RUnlock()
+	if db == nil || name == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	var contentType string
+	var data []byte
+	err = db.QueryRowContext(r.Context(), `SELECT content_type, data FROM item_receipts WHERE item_id = ?`, id).Scan(&contentType, &data)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Printf("db error while loading receipt: %v", err)
+		http.Error(w, "could not load receipt", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": name}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, no-store")
+	_, _ = w.Write(data)
+}
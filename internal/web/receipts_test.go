@@ -0,0 +1,72 @@
+package web_test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"mvpapp/internal/web/webtest"
+)
+
+const testReceiptPDF = "%PDF-1.4\n1 0 obj << /Type /Catalog >> endobj\n%%EOF\n"
+
+func postReceipt(t *testing.T, client *webtest.Client, itemID int, paid, fileName, content string) *webtest.Response {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	_ = form.WriteField("item_id", strconv.Itoa(itemID))
+	_ = form.WriteField("paid_price", paid)
+	if fileName != "" {
+		part, err := form.CreateFormFile("receipt", fileName)
+		if err != nil {
+			t.Fatalf("create form file: %v", err)
+		}
+		_, _ = part.Write([]byte(content))
+	}
+	if err := form.Close(); err != nil {
+		t.Fatalf("close multipart form: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/items/receipt", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	return client.Do(req)
+}
+
+func TestReceiptRecordsPaidPriceAndIsExported(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}, {Name: "Sam"}},
+		Items: []webtest.Item{
+			{Profile: "Alex", Title: "Headphones", Price: 10000, Tags: "Audio", Status: "Bought"},
+			{Profile: "Alex", Title: "Tent", Price: 8000},
+		},
+	})
+	alex := h.As("Alex")
+	headphones := h.Item("Alex", "Headphones").ID
+
+	alex.Get("/?status=Bought").ExpectContains(`href="/items/` + strconv.Itoa(headphones) + `/edit#receipt">Add receipt</a>`)
+	alex.Get("/items/"+strconv.Itoa(headphones)+"/edit").ExpectContains(`action="/items/receipt"`, `enctype="multipart/form-data"`)
+
+	postReceipt(t, alex, h.Item("Alex", "Tent").ID, "", "tent.pdf", testReceiptPDF).ExpectStatus(http.StatusConflict)
+	postReceipt(t, alex, headphones, "", "notes.txt", "just text").ExpectStatus(http.StatusUnsupportedMediaType)
+	postReceipt(t, alex, headphones, "", "", "").ExpectStatus(http.StatusBadRequest)
+
+	postReceipt(t, alex, headphones, "89.90", `C:\Users\alex\Downloads\headphones.pdf`, testReceiptPDF).
+		ExpectRedirect("/?done=receipt&item=Headphones")
+	if got := h.Item("Alex", "Headphones").Price; got != "89.90" {
+		t.Fatalf("expected the paid price to replace the price, got %q", got)
+	}
+
+	receipt := alex.Get("/items/" + strconv.Itoa(headphones) + "/receipt").ExpectStatus(http.StatusOK)
+	if got := receipt.Header().Get("Content-Type"); got != "application/pdf" {
+		t.Fatalf("expected a PDF receipt, got %q", got)
+	}
+	if receipt.Body() != testReceiptPDF {
+		t.Fatalf("expected the uploaded receipt back, got %q", receipt.Body())
+	}
+	alex.Get("/items/"+strconv.Itoa(headphones)+"/edit").ExpectContains("headphones.pdf", "Alex paid (€ 89.90)", "Alex added a receipt (headphones.pdf)")
+	alex.Get("/exports/ynab.csv").ExpectContains("Headphones,Audio,(receipt: headphones.pdf),89.90,")
+
+	h.As("Sam").Get("/items/" + strconv.Itoa(headphones) + "/receipt").ExpectStatus(http.StatusNotFound)
+}
@@ -0,0 +1,333 @@
+package web
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+const (
+	// maxReconcileUpload caps pasted and uploaded transaction files.
+	maxReconcileUpload = 1 << 20
+	// maxReconcileRows keeps the matching table usable.
+	maxReconcileRows = 500
+)
+
+// transactionDateLayouts are the date formats accepted in transaction exports, tried in order.
+var transactionDateLayouts = []string{"2006-01-02", "2006/01/02", "02.01.2006", "02.01.06", "01/02/2006"}
+
+// transactionColumns maps lower-case header names used by common bank exports to the column they hold.
+var transactionColumns = map[string]string{
+	"date": "date", "booking date": "date", "transaction date": "date", "datum": "date", "buchungstag": "date", "valuta": "date",
+	"description": "description", "payee": "description", "merchant": "description", "memo": "description", "text": "description",
+	"name": "description", "details": "description", "verwendungszweck": "description", "empfänger": "description", "auftraggeber/empfänger": "description",
+	"amount": "amount", "value": "amount", "betrag": "amount", "umsatz": "amount",
+}
+
+// transaction is one card or bank transaction from a pasted or uploaded CSV file.
+type transaction struct {
+	Date        time.Time
+	Description string
+	Amount      domain.Money
+	// SuggestedID is the open item that most likely matches, or 0.
+	SuggestedID int
+}
+
+type reconcileViewData struct {
+	Title           string
+	CurrentPath     string
+	ContentTemplate string
+	ScriptTemplate  string
+	ActiveProfile   string
+	Currency        string
+	Input           string
+	Transactions    []transaction
+	OpenItems       []Item
+	Skipped         []string
+	Feedback        string
+	Error           string
+}
+
+// parseTransactionAmount parses amounts as banks export them, such as "-1.234,56", "1,234.56 €" or "(12.50)".
+// Debits and credits both yield the absolute amount.
+func parseTransactionAmount(raw string) (domain.Money, error) {
+	cleaned := strings.Map(func(r rune) rune {
+		if (r >= '0' && r <= '9') || r == '.' || r == ',' {
+			return r
+		}
+		return -1
+	}, raw)
+	lastComma, lastDot := strings.LastIndex(cleaned, ","), strings.LastIndex(cleaned, ".")
+	switch {
+	case lastComma > lastDot && len(cleaned)-lastComma-1 <= 2:
+		// A comma followed by at most two digits is the decimal separator.
+		cleaned = strings.ReplaceAll(cleaned[:lastComma], ".", "") + "." + cleaned[lastComma+1:]
+	default:
+		cleaned = strings.ReplaceAll(cleaned, ",", "")
+	}
+	amount, err := domain.ParseMoney(cleaned)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", strings.TrimSpace(raw))
+	}
+	return amount, nil
+}
+
+func parseTransactionDate(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	for _, layout := range transactionDateLayouts {
+		if parsed, err := time.ParseInLocation(layout, raw, time.Local); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", raw)
+}
+
+// parseTransactionsCSV reads transactions with a date, description and amount column. Columns are found
+// by their header; files without a known header are read as date, description, amount. Semicolon-separated
+// files are detected from the first line. Rows that cannot be read are returned as skipped with a reason.
+func parseTransactionsCSV(input string) ([]transaction, []string, error) {
+	input = strings.TrimPrefix(strings.TrimSpace(input), "\ufeff")
+	if input == "" {
+		return nil, nil, errors.New("Please paste or upload a CSV file with your transactions.")
+	}
+
+	reader := csv.NewReader(strings.NewReader(input))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+	firstLine, _, _ := strings.Cut(input, "\n")
+	if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		reader.Comma = ';'
+	}
+
+	columns := map[string]int{"date": 0, "description": 1, "amount": 2}
+	var transactions []transaction
+	var skipped []string
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("Could not read the CSV file: %v", err)
+		}
+		if line == 1 {
+			if header, ok := transactionHeader(record); ok {
+				columns = header
+				continue
+			}
+		}
+		if len(transactions) == maxReconcileRows {
+			skipped = append(skipped, fmt.Sprintf("Only the first %d transactions are shown.", maxReconcileRows))
+			break
+		}
+
+		field := func(name string) string {
+			if i := columns[name]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		date, err := parseTransactionDate(field("date"))
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("Line %d: %v", line, err))
+			continue
+		}
+		amount, err := parseTransactionAmount(field("amount"))
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("Line %d: %v", line, err))
+			continue
+		}
+		transactions = append(transactions, transaction{Date: date, Description: field("description"), Amount: amount})
+	}
+	return transactions, skipped, nil
+}
+
+// transactionHeader returns the column positions if the record is a header naming a date and an amount column.
+func transactionHeader(record []string) (map[string]int, bool) {
+	columns := map[string]int{}
+	for i, name := range record {
+		column, ok := transactionColumns[strings.ToLower(strings.TrimSpace(name))]
+		if _, seen := columns[column]; ok && !seen {
+			columns[column] = i
+		}
+	}
+	_, hasDate := columns["date"]
+	_, hasAmount := columns["amount"]
+	if !hasDate || !hasAmount {
+		return nil, false
+	}
+	if _, ok := columns["description"]; !ok {
+		columns["description"] = len(record)
+	}
+	return columns, true
+}
+
+// matchScore rates how likely the transaction is the purchase of the item: a title word in the
+// description counts two points, an exact price two and a price within 10% one.
+func matchScore(tx transaction, item Item) int {
+	score := 0
+	description := strings.ToLower(tx.Description)
+	for _, word := range strings.Fields(strings.ToLower(item.Title)) {
+		if len([]rune(word)) >= 3 && strings.Contains(description, word) {
+			score += 2
+			break
+		}
+	}
+	if price, ok := itemPrice(item); ok {
+		diff := price - tx.Amount
+		if diff < 0 {
+			diff = -diff
+		}
+		switch {
+		case diff == 0:
+			score += 2
+		case diff*10 <= price:
+			score++
+		}
+	}
+	return score
+}
+
+// suggestMatches pre-selects the most likely open item for each transaction. Each item is suggested at
+// most once and only with a title match or an exact price.
+func suggestMatches(transactions []transaction, open []Item) {
+	used := map[int]bool{}
+	for i := range transactions {
+		best, bestScore := 0, 1
+		for _, item := range open {
+			if used[item.ID] {
+				continue
+			}
+			if score := matchScore(transactions[i], item); score > bestScore {
+				best, bestScore = item.ID, score
+			}
+		}
+		if best != 0 {
+			transactions[i].SuggestedID = best
+			used[best] = true
+		}
+	}
+}
+
+func openItems(items []Item) []Item {
+	var open []Item
+	for _, item := range items {
+		if slices.Contains(domain.OpenStatuses, item.Status) {
+			open = append(open, item)
+		}
+	}
+	return open
+}
+
+func (a *App) reconcileSettings(w http.ResponseWriter, r *http.Request) {
+	feedback := ""
+	if saved := r.URL.Query().Get("saved"); saved != "" {
+		count, _ := strconv.Atoi(saved)
+		feedback = fmt.Sprintf("Marked %d item(s) as bought.", count)
+	}
+	a.renderReconcile(w, reconcileViewData{Feedback: feedback})
+}
+
+// saveReconcile either previews pasted or uploaded transactions next to the open items, or, with
+// action=apply, records the matched transactions as purchases.
+func (a *App) saveReconcile(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxReconcileUpload)
+	if err := r.ParseMultipartForm(maxReconcileUpload); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	if r.FormValue("action") == "apply" {
+		a.applyReconcile(w, r)
+		return
+	}
+
+	data := reconcileViewData{Input: r.FormValue("transactions")}
+	if file, _, err := r.FormFile("file"); err == nil {
+		uploaded, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			http.Error(w, "could not read upload", http.StatusBadRequest)
+			return
+		}
+		data.Input = string(uploaded)
+	}
+
+	transactions, skipped, err := parseTransactionsCSV(data.Input)
+	if err != nil {
+		data.Error = err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		a.renderReconcile(w, data)
+		return
+	}
+	data.Transactions, data.Skipped = transactions, skipped
+	a.renderReconcile(w, data)
+}
+
+func (a *App) applyReconcile(w http.ResponseWriter, r *http.Request) {
+	rows, err := strconv.Atoi(r.FormValue("rows"))
+	if err != nil || rows < 0 || rows > maxReconcileRows {
+		http.Error(w, "invalid number of rows", http.StatusBadRequest)
+		return
+	}
+
+	a.mu.LockContext(r.Context())
+	defer a.mu.Unlock()
+
+	service := a.itemServiceLocked()
+	recorded := 0
+	for i := 0; i < rows; i++ {
+		id, err := strconv.Atoi(r.FormValue(fmt.Sprintf("match_%d", i)))
+		if err != nil || id <= 0 {
+			continue
+		}
+		boughtAt, err := time.ParseInLocation("2006-01-02", r.FormValue(fmt.Sprintf("date_%d", i)), time.Local)
+		if err != nil {
+			http.Error(w, "invalid transaction date", http.StatusBadRequest)
+			return
+		}
+		paid, err := domain.ParseMoney(r.FormValue(fmt.Sprintf("amount_%d", i)))
+		if err != nil {
+			http.Error(w, "invalid transaction amount", http.StatusBadRequest)
+			return
+		}
+
+		_, err = service.RecordPurchase(id, paid, boughtAt)
+		switch {
+		case errors.Is(err, domain.ErrItemNotFound), errors.Is(err, domain.ErrTransitionNotAllowed):
+			// The item was decided meanwhile or picked for two transactions; keep the first.
+			continue
+		case err != nil:
+			log.Printf("db error while recording purchase: %v", err)
+			http.Error(w, "could not record purchases", http.StatusInternalServerError)
+			return
+		}
+		recorded++
+	}
+
+	http.Redirect(w, r, "/settings/reconcile?saved="+strconv.Itoa(recorded), http.StatusSeeOther)
+}
+
+func (a *App) renderReconcile(w http.ResponseWriter, data reconcileViewData) {
+	a.mu.RLock()
+	data.ActiveProfile = a.currentUserIDLocked()
+	data.Currency = a.currency
+	data.OpenItems = openItems(a.items)
+	a.mu.RUnlock()
+
+	suggestMatches(data.Transactions, data.OpenItems)
+	data.Title = "Reconcile purchases"
+	data.CurrentPath = "/settings/reconcile"
+	data.ContentTemplate = "reconcile_content"
+	renderTemplate(w, a.templates, "layout", data)
+}
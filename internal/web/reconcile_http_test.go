@@ -0,0 +1,45 @@
+package web_test
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestReconcileMarksMatchedTransactionsAsBought(t *testing.T) {
+	now := time.Now()
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}},
+		Items: []webtest.Item{
+			{Profile: "Alex", Title: "Noise cancelling headphones", Price: 9900, PurchaseAllowedAt: now.Add(72 * time.Hour)},
+			{Profile: "Alex", Title: "Desk lamp", Price: 4000, PurchaseAllowedAt: now.Add(time.Hour)},
+		},
+	})
+	alex := h.As("Alex")
+	headphones := strconv.Itoa(h.Item("Alex", "Noise cancelling headphones").ID)
+
+	csv := "Buchungstag;Verwendungszweck;Betrag\n02.05.2026;MEDIAMARKT HEADPHONES;-89,99\nnot a date;Bakery;-3,20\n"
+	alex.PostForm("/settings/reconcile", url.Values{"transactions": {csv}}).
+		ExpectStatus(http.StatusOK).
+		ExpectContains("MEDIAMARKT HEADPHONES", `<option value="`+headphones+`" selected>`, "Line 3: invalid date")
+
+	alex.PostForm("/settings/reconcile", url.Values{"action": {"apply"}, "rows": {"1"}, "match_0": {headphones}, "date_0": {"2026-05-02"}, "amount_0": {"89.99"}}).
+		ExpectRedirect("/settings/reconcile?saved=1")
+
+	item := h.Item("Alex", "Noise cancelling headphones")
+	if item.Status != "Bought" || item.Price != "89.99" {
+		t.Fatalf("expected the item to be bought at the paid price, got %+v", item)
+	}
+	var decidedAt string
+	if err := h.DB.QueryRow(`SELECT decided_at FROM items WHERE id = ?`, item.ID).Scan(&decidedAt); err != nil || !strings.HasPrefix(decidedAt, "2026-05-02") {
+		t.Fatalf("expected the transaction date as purchase date, got %q: %v", decidedAt, err)
+	}
+	if got := h.Item("Alex", "Desk lamp").Status; got != "Waiting" {
+		t.Fatalf("expected unmatched items to stay open, got %q", got)
+	}
+}
@@ -0,0 +1,59 @@
+package web
+
+import (
+	"testing"
+
+	"mvpapp/internal/domain"
+)
+
+func TestParseTransactionAmountHandlesBankFormats(t *testing.T) {
+	tests := map[string]domain.Money{
+		"-1.234,56":  123456,
+		"1,234.56 €": 123456,
+		"12,5":       1250,
+		"$ 89.99":    8999,
+		"(12.50)":    1250,
+	}
+	for raw, want := range tests {
+		if got, err := parseTransactionAmount(raw); err != nil || got != want {
+			t.Errorf("parseTransactionAmount(%q) = %d, %v; want %d", raw, got, err, want)
+		}
+	}
+	if _, err := parseTransactionAmount("n/a"); err == nil {
+		t.Fatalf("expected text without digits to be rejected")
+	}
+}
+
+func TestParseTransactionsCSVFindsColumnsByHeader(t *testing.T) {
+	transactions, skipped, err := parseTransactionsCSV("Amount,Date,Payee\n-20.00,2026-05-01,Bookshop\n-5,2026-13-01,Cafe\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(transactions) != 1 || transactions[0].Description != "Bookshop" || transactions[0].Amount != 2000 || transactions[0].Date.Format("2006-01-02") != "2026-05-01" {
+		t.Fatalf("unexpected transactions %+v", transactions)
+	}
+	if len(skipped) != 1 {
+		t.Fatalf("expected the invalid date to be skipped, got %q", skipped)
+	}
+
+	headerless, _, err := parseTransactionsCSV("2026-05-01,Bookshop,20")
+	if err != nil || len(headerless) != 1 || headerless[0].Description != "Bookshop" {
+		t.Fatalf("expected headerless rows as date, description, amount, got %+v (%v)", headerless, err)
+	}
+}
+
+func TestSuggestMatchesPrefersTitleAndPrice(t *testing.T) {
+	open := []Item{
+		{ID: 1, Title: "Desk lamp", Price: "40"},
+		{ID: 2, Title: "Headphones", Price: "99"},
+	}
+	transactions := []transaction{
+		{Description: "AMAZON HEADPHONES", Amount: 8999},
+		{Description: "IKEA", Amount: 4000},
+		{Description: "Bakery", Amount: 320},
+	}
+	suggestMatches(transactions, open)
+	if transactions[0].SuggestedID != 2 || transactions[1].SuggestedID != 1 || transactions[2].SuggestedID != 0 {
+		t.Fatalf("unexpected suggestions %+v", transactions)
+	}
+}
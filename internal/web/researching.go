@@ -0,0 +1,91 @@
+package web
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+// startedPurchaseAllowedAt resolves the unlock time of a researching item whose wait starts now.
+// Payday waits end on the payday after now; payday is the profile's day of the month.
+func startedPurchaseAllowedAt(item Item, payday int, now time.Time) (time.Time, error) {
+	if item.WaitPreset == "date" && !item.PurchaseAllowedAt.IsZero() {
+		return item.PurchaseAllowedAt, nil
+	}
+	if item.WaitPreset == domain.WaitPresetPayday {
+		return domain.ResolvePurchaseAllowedAt(item.WaitPreset, "", "", "", payday, now)
+	}
+	duration, err := domain.ParseWaitDuration(item.WaitPreset, item.WaitCustomHours)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return now.Add(duration), nil
+}
+
+func countItemsWithStatus(items []Item, status domain.Status) int {
+	count := 0
+	for _, item := range items {
+		if item.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+func (a *App) startWait(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	id, err := strconv.Atoi(strings.TrimSpace(r.FormValue("item_id")))
+	if err != nil || id <= 0 {
+		http.Error(w, "invalid item id", http.StatusBadRequest)
+		return
+	}
+
+	a.mu.LockContext(r.Context())
+	defer a.mu.Unlock()
+
+	i := a.itemIndexLocked(id)
+	if i < 0 {
+		http.NotFound(w, r)
+		return
+	}
+	if !a.items[i].Status.CanPerform(domain.ActionStartWait) {
+		http.Error(w, "wait can only be started for researching items", http.StatusConflict)
+		return
+	}
+
+	now := time.Now()
+	purchaseAllowedAt, err := startedPurchaseAllowedAt(a.items[i], a.payday, now)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	purchaseAllowedAt, _ = domain.DeferForBlackouts(purchaseAllowedAt, a.blackouts)
+
+	next := domain.ActiveStatus(purchaseAllowedAt, now)
+	if err := domain.CheckTransition(a.items[i].Status, domain.ActionStartWait, next); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	previous := a.items[i]
+	a.items[i].PurchaseAllowedAt = purchaseAllowedAt
+	a.items[i].Status = next
+	a.items[i].NtfyAttempted = false
+	if err := a.updateItemLocked(a.items[i]); err != nil {
+		a.items[i] = previous
+		log.Printf("db error while starting wait: %v", err)
+		http.Error(w, "could not start wait", http.StatusInternalServerError)
+		return
+	}
+	a.recordHistoryLocked(id, "started the wait", "")
+
+	itemActionRedirect(w, r, "wait-started", a.items[i].Title)
+}
@@ -0,0 +1,88 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+func TestResearchingItemStartsWaitOnlyWhenRequested(t *testing.T) {
+	app, cleanup := newSQLiteTestApp(t)
+	defer cleanup()
+
+	app.mu.Lock()
+	app.activeUserID = "Alex"
+	app.hourlyWage = "25"
+	if err := app.persistProfileLocked(); err != nil {
+		app.mu.Unlock()
+		t.Fatalf("persist profile: %v", err)
+	}
+	app.mu.Unlock()
+
+	form := url.Values{"title": {"Standing desk"}, "wait_preset": {"7d"}, "researching": {"1"}}
+	req := httptest.NewRequest(http.MethodPost, "/items/new", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected redirect, got %d", rr.Code)
+	}
+
+	app.mu.Lock()
+	if err := app.loadStateFromDB("Alex"); err != nil {
+		app.mu.Unlock()
+		t.Fatalf("reload profile: %v", err)
+	}
+	item := app.items[0]
+	app.promoteReadyItemsLocked(time.Now())
+	promoted := app.items[0].Status
+	app.mu.Unlock()
+	if item.Status != "Researching" || !item.PurchaseAllowedAt.IsZero() {
+		t.Fatalf("expected researching item without countdown, got %+v", item)
+	}
+	if promoted != "Researching" {
+		t.Fatalf("expected researching item not to be promoted, got %q", promoted)
+	}
+
+	before := time.Now()
+	startReq := httptest.NewRequest(http.MethodPost, "/items/start-wait", strings.NewReader(url.Values{"item_id": {strconv.Itoa(item.ID)}}.Encode()))
+	startReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	startRR := httptest.NewRecorder()
+	app.Handler().ServeHTTP(startRR, startReq)
+	if startRR.Code != http.StatusSeeOther {
+		t.Fatalf("expected redirect, got %d", startRR.Code)
+	}
+
+	app.mu.Lock()
+	if err := app.activateProfileLocked("Alex"); err != nil {
+		app.mu.Unlock()
+		t.Fatalf("reload profile: %v", err)
+	}
+	started := app.items[0]
+	app.mu.Unlock()
+	if started.Status != "Waiting" || started.PurchaseAllowedAt.Before(before.Add(7*24*time.Hour)) {
+		t.Fatalf("expected 7d wait counted from start, got %+v", started)
+	}
+
+	againRR := httptest.NewRecorder()
+	againReq := httptest.NewRequest(http.MethodPost, "/items/start-wait", strings.NewReader(url.Values{"item_id": {strconv.Itoa(item.ID)}}.Encode()))
+	againReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	app.Handler().ServeHTTP(againRR, againReq)
+	if againRR.Code != http.StatusConflict {
+		t.Fatalf("expected conflict for non-researching item, got %d", againRR.Code)
+	}
+}
+
+func TestDefaultStatusFilterIncludesResearching(t *testing.T) {
+	selected, explicit := parseStatusFilter(nil)
+	if explicit || !slices.Equal(selected, []domain.Status{"Researching", "Waiting", "Ready to buy"}) {
+		t.Fatalf("unexpected default status filter %v (%v)", selected, explicit)
+	}
+}
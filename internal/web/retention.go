@@ -0,0 +1,226 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// retentionReminderWindow is how far ahead the data settings warn about decided items that are about to be purged.
+const retentionReminderWindow = 14 * 24 * time.Hour
+
+var retentionMonthOptions = []int{0, 3, 6, 12, 24, 36}
+
+type dataSettingsViewData struct {
+	Title            string
+	CurrentPath      string
+	ContentTemplate  string
+	ScriptTemplate   string
+	RetentionMonths  int
+	RetentionOptions []int
+	ExpiredCount     int
+	UpcomingCount    int
+	ItemCount        int
+	OwnedItems       int
+	ItemQuota        int
+	AttachmentBytes  int64
+	AttachmentQuota  int64
+	MetricsOptIn     bool
+	NotesEncrypted   bool
+	NotesUnlocked    bool
+	Error            string
+	Feedback         string
+	ActiveProfile    string
+}
+
+// StartBackgroundPurge registers the "purge" job, which deletes decided items past their retention
+// period every interval, starting right away.
+func (a *App) StartBackgroundPurge(interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	a.registerJob("purge", "@every "+interval.String(), 0, true, func(a *App, now time.Time) error {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if _, err := a.purgeExpiredItemsLocked(now); err != nil {
+			return fmt.Errorf("purge expired items: %w", err)
+		}
+		return nil
+	})
+}
+
+// purgeExpiredItemsLocked deletes decided items older than each profile's retention period and returns how many were removed.
+func (a *App) purgeExpiredItemsLocked(now time.Time) (int, error) {
+	settings, err := a.retentionSettingsLocked()
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	activeUserID := a.currentUserIDLocked()
+	for userID, months := range settings {
+		items, err := a.itemsForProfileLocked(userID)
+		if err != nil {
+			return purged, err
+		}
+
+		expired, _ := retentionPurgeCandidates(items, months, now)
+		if len(expired) == 0 {
+			continue
+		}
+
+		ids := make([]int, 0, len(expired))
+		for _, item := range expired {
+			ids = append(ids, item.ID)
+		}
+		if err := a.deleteItemsLocked(userID, ids); err != nil {
+			return purged, err
+		}
+		if userID == activeUserID {
+			a.items = slices.DeleteFunc(a.items, func(item Item) bool {
+				return slices.Contains(ids, item.ID)
+			})
+		}
+		purged += len(ids)
+	}
+	return purged, nil
+}
+
+// retentionPurgeCandidates returns decided items past the retention period and those expiring within retentionReminderWindow.
+func retentionPurgeCandidates(items []Item, months int, now time.Time) (expired []Item, upcoming []Item) {
+	if months <= 0 {
+		return nil, nil
+	}
+
+	cutoff := now.AddDate(0, -months, 0)
+	for _, item := range items {
+		if !item.Status.Decided() {
+			continue
+		}
+		decidedAt := itemDecisionTime(item)
+		switch {
+		case !decidedAt.After(cutoff):
+			expired = append(expired, item)
+		case !decidedAt.After(cutoff.Add(retentionReminderWindow)):
+			upcoming = append(upcoming, item)
+		}
+	}
+	return expired, upcoming
+}
+
+// itemDecisionTime falls back to the creation time for items decided before decision timestamps were recorded.
+func itemDecisionTime(item Item) time.Time {
+	if item.DecidedAt.IsZero() {
+		return item.CreatedAt
+	}
+	return item.DecidedAt
+}
+
+func parseRetentionMonths(raw string) (int, error) {
+	months, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil || !slices.Contains(retentionMonthOptions, months) {
+		return 0, errors.New("Please select a valid retention period.")
+	}
+	return months, nil
+}
+
+func (a *App) dataSettings(w http.ResponseWriter, r *http.Request) {
+	feedback := ""
+	switch r.URL.Query().Get("saved") {
+	case "1":
+		feedback = "Retention settings saved."
+	case "metrics":
+		feedback = "Metrics settings saved."
+	case "notes-enable":
+		feedback = "Notes are now encrypted."
+	case "notes-unlock":
+		feedback = "Notes unlocked."
+	case "notes-lock":
+		feedback = "Notes locked."
+	case "notes-rotate":
+		feedback = "Notes re-encrypted with the new passphrase."
+	case "notes-disable":
+		feedback = "Note encryption turned off."
+	}
+	a.renderDataSettings(w, dataSettingsViewData{Feedback: feedback})
+}
+
+func (a *App) saveDataSettings(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	months, err := parseRetentionMonths(r.FormValue("retention_months"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		a.renderDataSettings(w, dataSettingsViewData{Error: err.Error()})
+		return
+	}
+
+	a.mu.LockContext(r.Context())
+	a.retentionMonths = months
+	if err := a.persistProfileLocked(); err != nil {
+		a.mu.Unlock()
+		log.Printf("db error while saving retention settings: %v", err)
+		http.Error(w, "could not save retention settings", http.StatusInternalServerError)
+		return
+	}
+	a.publishProfileUpdatedLocked("data retention", r)
+	a.mu.Unlock()
+
+	http.Redirect(w, r, "/settings/data?saved=1", http.StatusSeeOther)
+}
+
+func (a *App) wipeProfileData(w http.ResponseWriter, r *http.Request) {
+	a.mu.LockContext(r.Context())
+	profileName := a.currentUserIDLocked()
+	if err := a.deleteProfileLocked(profileName); err != nil {
+		a.mu.Unlock()
+		log.Printf("db error while wiping profile data: %v", err)
+		http.Error(w, "could not delete data", http.StatusInternalServerError)
+		return
+	}
+	a.recordAuditLocked(profileName, auditDataWiped, "", r)
+	a.resetActiveProfileLocked()
+	a.mu.Unlock()
+
+	http.SetCookie(w, &http.Cookie{Name: "active_profile", Value: "", Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode, MaxAge: -1})
+	http.Redirect(w, r, "/switch-profile", http.StatusSeeOther)
+}
+
+func (a *App) renderDataSettings(w http.ResponseWriter, data dataSettingsViewData) {
+	a.mu.RLock()
+	data.RetentionMonths = a.retentionMonths
+	expired, upcoming := retentionPurgeCandidates(a.items, a.retentionMonths, time.Now())
+	data.ExpiredCount = len(expired)
+	data.UpcomingCount = len(upcoming)
+	data.ItemCount = len(a.items)
+	data.OwnedItems = a.itemServiceLocked().OwnedItems()
+	data.ItemQuota = a.itemQuota
+	data.AttachmentQuota = a.attachmentQuota
+	if a.db != nil {
+		used, err := a.attachmentBytesLocked(a.currentUserIDLocked(), 0)
+		if err != nil {
+			log.Printf("db error while summing receipt sizes: %v", err)
+		}
+		data.AttachmentBytes = used
+	}
+	data.MetricsOptIn = a.metricsOptIn
+	data.NotesEncrypted = a.noteEncryptionEnabledLocked()
+	data.NotesUnlocked = a.notesUnlockedLocked()
+	data.ActiveProfile = a.currentUserIDLocked()
+	a.mu.RUnlock()
+
+	data.Title = "Data settings"
+	data.CurrentPath = "/settings/data"
+	data.RetentionOptions = retentionMonthOptions
+	data.ContentTemplate = "data_settings_content"
+	renderTemplate(w, a.templates, "layout", data)
+}
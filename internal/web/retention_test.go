@@ -0,0 +1,182 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetentionPurgeCandidates(t *testing.T) {
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	items := []Item{
+		{ID: 1, Status: "Bought", DecidedAt: now.AddDate(0, -7, 0)},
+		{ID: 2, Status: "Skipped", DecidedAt: now.AddDate(0, -6, 7)},
+		{ID: 3, Status: "Skipped", DecidedAt: now.AddDate(0, -1, 0)},
+		{ID: 4, Status: "Waiting", CreatedAt: now.AddDate(-2, 0, 0)},
+		{ID: 5, Status: "Bought", CreatedAt: now.AddDate(-1, 0, 0)},
+	}
+
+	expired, upcoming := retentionPurgeCandidates(items, 6, now)
+
+	if got := itemIDs(expired); !slices.Equal(got, []int{1, 5}) {
+		t.Fatalf("expected items 1 and 5 to be expired, got %v", got)
+	}
+	if got := itemIDs(upcoming); !slices.Equal(got, []int{2}) {
+		t.Fatalf("expected item 2 to be purged soon, got %v", got)
+	}
+
+	if expired, upcoming := retentionPurgeCandidates(items, 0, now); expired != nil || upcoming != nil {
+		t.Fatalf("expected retention 0 to keep everything")
+	}
+}
+
+func TestPurgeExpiredItemsRemovesOnlyOldDecisionsAcrossProfiles(t *testing.T) {
+	app, cleanup := newSQLiteTestApp(t)
+	defer cleanup()
+	now := time.Now()
+
+	app.mu.Lock()
+	app.activeUserID = "Archive"
+	app.retentionMonths = 3
+	if err := app.persistProfileLocked(); err != nil {
+		app.mu.Unlock()
+		t.Fatalf("persist profile: %v", err)
+	}
+	for _, item := range []Item{
+		{Title: "old decision", Status: "Skipped", WaitPreset: "24h", PurchaseAllowedAt: now.AddDate(0, -5, 0), CreatedAt: now.AddDate(0, -5, 0), DecidedAt: now.AddDate(0, -4, 0)},
+		{Title: "recent decision", Status: "Bought", WaitPreset: "24h", PurchaseAllowedAt: now.AddDate(0, -5, 0), CreatedAt: now.AddDate(0, -5, 0), DecidedAt: now.AddDate(0, -1, 0)},
+		{Title: "old open item", Status: "Waiting", WaitPreset: "24h", PurchaseAllowedAt: now.Add(time.Hour), CreatedAt: now.AddDate(-1, 0, 0)},
+	} {
+		if err := app.insertItemLocked(&item); err != nil {
+			app.mu.Unlock()
+			t.Fatalf("insert item: %v", err)
+		}
+	}
+
+	// Switch away so the purge has to work on a profile that is not loaded in memory.
+	app.activeUserID = "Other"
+	if err := app.loadStateFromDB("Other"); err != nil {
+		app.mu.Unlock()
+		t.Fatalf("load other profile: %v", err)
+	}
+
+	purged, err := app.purgeExpiredItemsLocked(now)
+	app.mu.Unlock()
+	if err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if purged != 1 {
+		t.Fatalf("expected exactly one purged item, got %d", purged)
+	}
+
+	remaining, err := queryItemsForUser(app.db, "Archive")
+	if err != nil {
+		t.Fatalf("query remaining items: %v", err)
+	}
+	var titles []string
+	for _, item := range remaining {
+		titles = append(titles, item.Title)
+	}
+	if slices.Contains(titles, "old decision") || len(titles) != 2 {
+		t.Fatalf("expected only the old decision to be purged, got %v", titles)
+	}
+}
+
+func TestDataSettingsSaveRetentionAndShowPurgeReminder(t *testing.T) {
+	app := NewApp()
+	seedProfile(app)
+
+	app.mu.Lock()
+	app.items = []Item{{ID: 1, Title: "Old lamp", Status: "Skipped", DecidedAt: time.Now().AddDate(0, -6, 3)}}
+	app.mu.Unlock()
+
+	form := url.Values{}
+	form.Set("retention_months", "6")
+	req := httptest.NewRequest(http.MethodPost, "/settings/data", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected redirect, got %d", rr.Code)
+	}
+
+	getReq := httptest.NewRequest(http.MethodGet, "/settings/data?saved=1", nil)
+	getRR := httptest.NewRecorder()
+	app.Handler().ServeHTTP(getRR, getReq)
+	body := getRR.Body.String()
+	if !strings.Contains(body, `<option value="6" selected>`) {
+		t.Fatalf("expected saved retention period to be selected")
+	}
+	if !strings.Contains(body, "will be purged within the next two weeks") {
+		t.Fatalf("expected export reminder for items about to be purged")
+	}
+}
+
+func TestDataSettingsRejectsUnknownRetention(t *testing.T) {
+	app := NewApp()
+	seedProfile(app)
+
+	form := url.Values{}
+	form.Set("retention_months", "5")
+	req := httptest.NewRequest(http.MethodPost, "/settings/data", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), "valid retention period") {
+		t.Fatalf("expected validation message")
+	}
+}
+
+func TestWipeProfileDataDeletesEvenTheLastProfile(t *testing.T) {
+	app, cleanup := newSQLiteTestApp(t)
+	defer cleanup()
+
+	app.mu.Lock()
+	app.activeUserID = "OnlyOne"
+	app.shareToken = "secret"
+	if err := app.persistProfileLocked(); err != nil {
+		app.mu.Unlock()
+		t.Fatalf("persist profile: %v", err)
+	}
+	item := Item{Title: "to be wiped", Status: "Waiting", WaitPreset: "24h", PurchaseAllowedAt: time.Now().Add(time.Hour), CreatedAt: time.Now()}
+	if err := app.insertItemLocked(&item); err != nil {
+		app.mu.Unlock()
+		t.Fatalf("insert item: %v", err)
+	}
+	app.mu.Unlock()
+
+	req := httptest.NewRequest(http.MethodPost, "/settings/data/wipe", nil)
+	req.AddCookie(&http.Cookie{Name: "active_profile", Value: "OnlyOne"})
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+
+	if got := rr.Header().Get("Location"); rr.Code != http.StatusSeeOther || got != "/switch-profile" {
+		t.Fatalf("expected redirect to /switch-profile, got %d %q", rr.Code, got)
+	}
+	names, err := app.listProfileNames()
+	if err != nil {
+		t.Fatalf("list profiles: %v", err)
+	}
+	if len(names) != 0 {
+		t.Fatalf("expected no profiles after wipe, got %v", names)
+	}
+	if got := rr.Header().Get("Set-Cookie"); !strings.Contains(got, "active_profile=;") || !strings.Contains(got, "Max-Age=0") {
+		t.Fatalf("expected the active profile cookie to be cleared after wipe, got %q", got)
+	}
+}
+
+func itemIDs(items []Item) []int {
+	ids := make([]int, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
@@ -0,0 +1,269 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+type routingSettingsViewData struct {
+	Title           string
+	CurrentPath     string
+	ContentTemplate string
+	ScriptTemplate  string
+	ActiveProfile   string
+	Rules           []domain.RoutingRule
+	NtfyConfigured  bool
+	WhenInput       string
+	ValueInput      string
+	ChannelInput    string
+	TopicInput      string
+	Error           string
+	FieldErrors     map[string]string
+	Feedback        string
+}
+
+// routedEffectsLocked returns the announcement effects of a ready item as the active profile's routing
+// rules choose them. Without rules, the item goes over ntfy, once, and web push.
+func (a *App) routedEffectsLocked(item *Item) []string {
+	var effects []string
+	switch rule := domain.Route(a.routingRules, *item); rule.Channel {
+	case domain.RouteToPush:
+		effects = append(effects, effectWebPush)
+	case domain.RouteToNtfy:
+		if !item.NtfyAttempted {
+			item.NtfyAttempted = true
+			effects = append(effects, effectNtfy)
+		}
+	case domain.RouteToTopic:
+		effects = append(effects, effectNtfyTopicPrefix+rule.Topic)
+	case domain.RouteToDigest:
+		effects = append(effects, effectReadyDigest)
+	default:
+		if !item.NtfyAttempted {
+			item.NtfyAttempted = true
+			effects = append(effects, effectNtfy)
+		}
+		effects = append(effects, effectWebPush)
+	}
+	return effects
+}
+
+// sendTopicNtfyLocked announces ready items on another topic of the active profile's ntfy endpoint.
+func (a *App) sendTopicNtfyLocked(topic string, items ...Item) (int, error) {
+	if strings.TrimSpace(a.ntfyURL) == "" {
+		log.Printf("ntfy topic %q skipped for item %d: endpoint not configured", topic, items[0].ID)
+		return 0, errChannelNotConfigured
+	}
+
+	message := fmt.Sprintf("%s\nDashboard: %s", readyMessage(items), a.dashboardLink())
+	if a.notificationDryRunLocked(effectNtfyTopicPrefix+topic, a.ntfyURL+"/"+topic, message) {
+		return 0, nil
+	}
+	return a.postNtfyMessage(a.mu.Context(), a.ntfyURL, topic, "Impulse Pause reminder", message)
+}
+
+// collectForDigestLocked keeps a ready item for the active profile's next weekly digest.
+func (a *App) collectForDigestLocked(item Item) error {
+	if a.db == nil {
+		return nil
+	}
+	item = maskPrivate(item)
+	if _, err := a.db.Exec(`INSERT INTO ready_digest(user_id, item_id, title, ready_at) VALUES (?, ?, ?, ?)`, a.currentUserIDLocked(), item.ID, item.Title, time.Now().Format(time.RFC3339Nano)); err != nil {
+		return fmt.Errorf("collect item for the ready digest: %w", err)
+	}
+	return nil
+}
+
+// readyDigestMessage lists the items that became ready since the last digest.
+func readyDigestMessage(titles []string, link string) string {
+	lines := make([]string, 0, len(titles))
+	for _, title := range titles {
+		lines = append(lines, "- "+title)
+	}
+	return fmt.Sprintf("Ready to buy this week:\n%s\nDashboard: %s", strings.Join(lines, "\n"), link)
+}
+
+// StartReadyDigest registers the "ready-digest" job, which sends each profile the items its routing rules
+// collected for the digest over ntfy, on Monday mornings.
+func (a *App) StartReadyDigest() {
+	a.registerJob("ready-digest", "0 9 * * 1", 0, false, func(a *App, now time.Time) error {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		return a.sendReadyDigestsLocked()
+	})
+}
+
+// sendReadyDigestsLocked sends and clears the collected items of every profile. Items of profiles without
+// ntfy stay collected until it is set up, and so do items whose digest failed.
+func (a *App) sendReadyDigestsLocked() error {
+	if a.db == nil {
+		return nil
+	}
+	rows, err := a.db.Query(`SELECT id, user_id, item_id, title FROM ready_digest ORDER BY id`)
+	if err != nil {
+		return fmt.Errorf("list ready digest: %w", err)
+	}
+	type collected struct {
+		ids    []int64
+		items  []Item
+		titles []string
+	}
+	var order []string
+	byProfile := map[string]*collected{}
+	for rows.Next() {
+		var id int64
+		var name string
+		var item Item
+		if err := rows.Scan(&id, &name, &item.ID, &item.Title); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan ready digest: %w", err)
+		}
+		c, ok := byProfile[name]
+		if !ok {
+			c = &collected{}
+			byProfile[name] = c
+			order = append(order, name)
+		}
+		c.ids = append(c.ids, id)
+		c.items = append(c.items, item)
+		c.titles = append(c.titles, item.Title)
+	}
+	if err := rows.Close(); err != nil {
+		return fmt.Errorf("list ready digest: %w", err)
+	}
+
+	var errs []error
+	for _, name := range order {
+		c := byProfile[name]
+		endpoint, topic, err := a.ntfySettingsForProfileLocked(name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if strings.TrimSpace(endpoint) == "" || strings.TrimSpace(topic) == "" {
+			continue
+		}
+		message := readyDigestMessage(c.titles, a.dashboardLink())
+		var code int
+		if !a.notificationDryRunLocked(effectNtfy, endpoint+"/"+topic, message) {
+			code, err = a.postNtfyMessage(a.mu.Context(), endpoint, topic, "Impulse Pause weekly digest", message)
+		}
+		for _, item := range c.items {
+			a.recordDeliveryForLocked(name, notificationReadyDigest, effectNtfy, item, code, err)
+		}
+		if err != nil {
+			log.Printf("ntfy request failed for the ready digest of %q: %v", name, err)
+			errs = append(errs, fmt.Errorf("ready digest for %q: %w", name, err))
+			continue
+		}
+		for _, id := range c.ids {
+			if _, err := a.db.Exec(`DELETE FROM ready_digest WHERE id = ?`, id); err != nil {
+				errs = append(errs, fmt.Errorf("clear ready digest: %w", err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (a *App) routingSettings(w http.ResponseWriter, r *http.Request) {
+	feedback := ""
+	switch r.URL.Query().Get("saved") {
+	case "added":
+		feedback = "Rule added. It applies to items that become ready from now on."
+	case "deleted":
+		feedback = "Rule removed."
+	case "moved":
+		feedback = "Rule moved."
+	}
+	a.renderRoutingSettings(w, routingSettingsViewData{Feedback: feedback})
+}
+
+// saveRoutingRules adds a rule at the end of the list, or with action=delete or action=up removes the rule
+// at the given index or moves it one place up, since the first matching rule wins.
+func (a *App) saveRoutingRules(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	a.mu.RLock()
+	rules := slices.Clone(a.routingRules)
+	format := domain.NormalizeNumberFormat(a.numberFormat)
+	a.mu.RUnlock()
+
+	saved := "added"
+	switch action := r.FormValue("action"); action {
+	case "delete", "up":
+		index, err := strconv.Atoi(r.FormValue("index"))
+		if err != nil || index < 0 || index >= len(rules) || (action == "up" && index == 0) {
+			http.Error(w, "invalid rule", http.StatusBadRequest)
+			return
+		}
+		if action == "up" {
+			rules[index-1], rules[index] = rules[index], rules[index-1]
+			saved = "moved"
+		} else {
+			rules = slices.Delete(rules, index, index+1)
+			saved = "deleted"
+		}
+	default:
+		data := routingSettingsViewData{
+			WhenInput:    strings.TrimSpace(r.FormValue("when")),
+			ValueInput:   strings.TrimSpace(r.FormValue("value")),
+			ChannelInput: strings.TrimSpace(r.FormValue("channel")),
+			TopicInput:   strings.TrimSpace(r.FormValue("topic")),
+		}
+		rule, err := domain.ParseRoutingRule(data.WhenInput, data.ValueInput, data.ChannelInput, data.TopicInput, format)
+		var invalid *domain.ValidationError
+		if errors.As(err, &invalid) {
+			data.Error = fieldErrorSummary
+			data.FieldErrors = invalid.FieldMessages()
+			w.WriteHeader(http.StatusBadRequest)
+			a.renderRoutingSettings(w, data)
+			return
+		}
+		rules = append(rules, rule)
+	}
+
+	a.mu.LockContext(r.Context())
+	previous := a.routingRules
+	a.routingRules = rules
+	if err := a.persistProfileLocked(); err != nil {
+		a.routingRules = previous
+		a.mu.Unlock()
+		log.Printf("db error while saving routing rules: %v", err)
+		http.Error(w, "could not save routing rules", http.StatusInternalServerError)
+		return
+	}
+	a.publishProfileUpdatedLocked("routing", r)
+	a.mu.Unlock()
+
+	http.Redirect(w, r, "/settings/routing?saved="+saved, http.StatusSeeOther)
+}
+
+func (a *App) renderRoutingSettings(w http.ResponseWriter, data routingSettingsViewData) {
+	a.mu.RLock()
+	data.ActiveProfile = a.currentUserIDLocked()
+	data.Rules = a.routingRules
+	data.NtfyConfigured = strings.TrimSpace(a.ntfyURL) != ""
+	a.mu.RUnlock()
+
+	if data.WhenInput == "" {
+		data.WhenInput = domain.RouteWhenPriceAtLeast
+	}
+	if data.ChannelInput == "" {
+		data.ChannelInput = domain.RouteToPush
+	}
+	data.Title = "Notification routing"
+	data.CurrentPath = "/settings/routing"
+	data.ContentTemplate = "routing_content"
+	renderTemplate(w, a.templates, "layout", data)
+}
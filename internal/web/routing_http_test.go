@@ -0,0 +1,36 @@
+package web_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestRoutingSettingsAddMoveAndRemoveRules(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{Profiles: []webtest.Profile{{Name: "Alex", HourlyWage: "25"}}})
+	alex := h.As("Alex")
+
+	alex.PostForm("/settings/routing", url.Values{"when": {"price_at_least"}, "value": {"lots"}, "channel": {"push"}}).
+		ExpectStatus(http.StatusBadRequest).ExpectContains("Please enter the minimum price")
+	alex.PostForm("/settings/routing", url.Values{"when": {"shared"}, "channel": {"topic"}, "topic": {"our house"}}).
+		ExpectStatus(http.StatusBadRequest).ExpectContains("without spaces or slashes")
+
+	alex.PostForm("/settings/routing", url.Values{"when": {"any"}, "channel": {"digest"}}).
+		ExpectRedirect("/settings/routing?saved=added")
+	alex.PostForm("/settings/routing", url.Values{"when": {"price_at_least"}, "value": {"200"}, "channel": {"push"}}).
+		ExpectRedirect("/settings/routing?saved=added")
+	alex.PostForm("/settings/routing", url.Values{"action": {"up"}, "index": {"1"}}).
+		ExpectRedirect("/settings/routing?saved=moved")
+	body := alex.Get("/settings/routing").ExpectStatus(http.StatusOK).Body()
+	if first, second := strings.Index(body, "Items costing at least 200.00 go to web push only"), strings.Index(body, "All other items go to the weekly digest"); first < 0 || second < first {
+		t.Fatalf("expected the price rule above the catch-all rule")
+	}
+
+	alex.PostForm("/settings/routing", url.Values{"action": {"up"}, "index": {"0"}}).ExpectStatus(http.StatusBadRequest)
+	alex.PostForm("/settings/routing", url.Values{"action": {"delete"}, "index": {"0"}}).
+		ExpectRedirect("/settings/routing?saved=deleted")
+	alex.Get("/settings/routing").ExpectContains("All other items go to the weekly digest").ExpectNotContains("Items costing at least 200.00")
+}
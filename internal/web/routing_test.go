@@ -0,0 +1,74 @@
+package web
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+func TestRoutingRulesSendReadyItemsToTopicsAndTheWeeklyDigest(t *testing.T) {
+	messages := map[string][]string{}
+	ntfyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		messages[r.URL.Path] = append(messages[r.URL.Path], string(body))
+	}))
+	defer ntfyServer.Close()
+
+	app, err := NewAppWithSQLite(filepath.Join(t.TempDir(), "test.sqlite"))
+	if err != nil {
+		t.Fatalf("new sqlite app: %v", err)
+	}
+	defer app.Close()
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	app.activeUserID = "Alex"
+	app.hourlyWage = "25"
+	app.ntfyURL, app.ntfyTopic = ntfyServer.URL, "alex"
+	app.routingRules = []domain.RoutingRule{
+		{When: domain.RouteWhenTag, Value: "gift", Channel: domain.RouteToTopic, Topic: "household"},
+		{When: domain.RouteWhenPriceAtLeast, Value: "200.00", Channel: domain.RouteToNtfy},
+		{When: domain.RouteWhenAny, Channel: domain.RouteToDigest},
+	}
+	if err := app.persistProfileLocked(); err != nil {
+		t.Fatalf("persist profile: %v", err)
+	}
+	now := time.Now()
+	for _, item := range []Item{
+		{Title: "Board game", Tags: "gift"},
+		{Title: "Camera", Price: "450", PriceCents: 45000, HasPriceValue: true},
+		{Title: "Socks", Price: "12", PriceCents: 1200, HasPriceValue: true},
+	} {
+		item.Status, item.WaitPreset, item.PurchaseAllowedAt, item.CreatedAt = "Waiting", "24h", now.Add(-time.Minute), now.Add(-24*time.Hour)
+		if err := app.insertItemLocked(&item); err != nil {
+			t.Fatalf("insert item: %v", err)
+		}
+		app.items = append(app.items, item)
+	}
+
+	app.promoteReadyItemsLocked(now)
+	app.dispatchOutboxLocked(time.Now())
+	if got := messages["/household"]; len(got) != 1 || !strings.HasPrefix(got[0], "Board game is now ready to buy.") {
+		t.Fatalf("expected the gift on the household topic, got %q", got)
+	}
+	if got := messages["/alex"]; len(got) != 1 || !strings.HasPrefix(got[0], "Camera is now ready to buy.") {
+		t.Fatalf("expected only the camera on the profile's topic, got %q", got)
+	}
+
+	if err := app.sendReadyDigestsLocked(); err != nil {
+		t.Fatalf("send ready digests: %v", err)
+	}
+	if got := messages["/alex"]; len(got) != 2 || !strings.Contains(got[1], "- Socks\n") {
+		t.Fatalf("expected the socks in the weekly digest, got %q", got)
+	}
+	var collected int
+	if err := app.db.QueryRow(`SELECT COUNT(*) FROM ready_digest`).Scan(&collected); err != nil || collected != 0 {
+		t.Fatalf("expected the digest to be cleared after sending, got %d (%v)", collected, err)
+	}
+}
@@ -0,0 +1,321 @@
+package web
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"mvpapp/internal/domain"
+)
+
+// maxRulesUpload limits an imported rules file.
+const maxRulesUpload = 1 << 20
+
+// rulesDocumentVersion is the version of the rules file format written by the export.
+const rulesDocumentVersion = 1
+
+// rulesDocument is a profile's rules as a YAML file: tag wait defaults, the approval rule, notification
+// routing and upcoming blackouts. Amounts use a point as decimal separator whatever the profile's number
+// format, so files can be shared. On import, each section that is present replaces the profile's rules of
+// that kind and sections that are left out stay as they are.
+type rulesDocument struct {
+	Version   int               `yaml:"version"`
+	TagWaits  map[string]string `yaml:"tag_waits"`
+	Approval  *rulesApproval    `yaml:"approval"`
+	Routing   []rulesRoute      `yaml:"routing"`
+	Blackouts []rulesBlackout   `yaml:"blackouts"`
+}
+
+type rulesApproval struct {
+	Threshold string `yaml:"threshold,omitempty"`
+	Approver  string `yaml:"approver,omitempty"`
+}
+
+type rulesRoute struct {
+	When    string `yaml:"when"`
+	Value   string `yaml:"value,omitempty"`
+	Channel string `yaml:"channel"`
+	Topic   string `yaml:"topic,omitempty"`
+}
+
+type rulesBlackout struct {
+	FirstDay string `yaml:"first_day"`
+	LastDay  string `yaml:"last_day"`
+	Label    string `yaml:"label,omitempty"`
+}
+
+// importedRules are the rules of a document once they are checked. Nil fields were not in the document.
+type importedRules struct {
+	TagWaits        map[string]string
+	Approval        *rulesApproval
+	Threshold       domain.Money
+	Routing         []domain.RoutingRule
+	Blackouts       []domain.Blackout
+	SkippedOver     int
+	ReplaceRouting  bool
+	ReplaceBlackout bool
+}
+
+type rulesSettingsViewData struct {
+	Title           string
+	CurrentPath     string
+	ContentTemplate string
+	ScriptTemplate  string
+	ActiveProfile   string
+	TagWaitCount    int
+	HasApproval     bool
+	RoutingCount    int
+	BlackoutCount   int
+	Input           string
+	Error           string
+	Problems        []string
+	Feedback        string
+}
+
+// exportRulesLocked returns the active profile's rules as a document.
+func (a *App) exportRulesLocked(now time.Time) rulesDocument {
+	doc := rulesDocument{
+		Version:   rulesDocumentVersion,
+		TagWaits:  maps.Clone(a.tagWaitDefaults),
+		Approval:  &rulesApproval{Approver: a.approver},
+		Routing:   []rulesRoute{},
+		Blackouts: []rulesBlackout{},
+	}
+	if doc.TagWaits == nil {
+		doc.TagWaits = map[string]string{}
+	}
+	if a.approvalThreshold > 0 {
+		doc.Approval.Threshold = a.approvalThreshold.String()
+	}
+	for _, rule := range a.routingRules {
+		doc.Routing = append(doc.Routing, rulesRoute{When: rule.When, Value: rule.Value, Channel: rule.Channel, Topic: rule.Topic})
+	}
+	for _, b := range upcomingBlackouts(a.blackouts, now) {
+		doc.Blackouts = append(doc.Blackouts, rulesBlackout{FirstDay: b.Start.Format("2006-01-02"), LastDay: b.LastDay().Format("2006-01-02"), Label: b.Label})
+	}
+	return doc
+}
+
+// parseRulesDocument reads and checks a rules file. The problems name the rule they are about. Approvers
+// are checked against profiles; blackouts that are already over are left out and counted.
+func parseRulesDocument(raw []byte, profiles []string, active string, now time.Time) (importedRules, []string) {
+	var doc rulesDocument
+	decoder := yaml.NewDecoder(bytes.NewReader(raw))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return importedRules{}, []string{"The rules file is empty."}
+		}
+		return importedRules{}, []string{"The rules file is not valid YAML: " + strings.TrimPrefix(err.Error(), "yaml: ")}
+	}
+	if doc.Version != rulesDocumentVersion {
+		return importedRules{}, []string{fmt.Sprintf("The rules file needs \"version: %d\".", rulesDocumentVersion)}
+	}
+
+	var out importedRules
+	var problems []string
+	if doc.TagWaits != nil {
+		out.TagWaits = map[string]string{}
+		tags := mapKeys(doc.TagWaits)
+		slices.Sort(tags)
+		for _, tag := range tags {
+			preset := strings.TrimSpace(doc.TagWaits[tag])
+			switch {
+			case strings.TrimSpace(tag) == "":
+				problems = append(problems, "Tag waits: please name the tag.")
+			case !slices.Contains(tagWaitPresetOptions, preset):
+				problems = append(problems, fmt.Sprintf("Tag wait for %s: please use one of %s.", tag, strings.Join(tagWaitPresetOptions, ", ")))
+			default:
+				out.TagWaits = setTagWaitDefault(out.TagWaits, strings.TrimSpace(tag), preset)
+			}
+		}
+	}
+
+	if doc.Approval != nil {
+		approval := rulesApproval{Threshold: strings.TrimSpace(doc.Approval.Threshold), Approver: strings.TrimSpace(doc.Approval.Approver)}
+		threshold, err := parseApprovalThreshold(approval.Threshold, domain.NumberFormatPoint)
+		if err != nil {
+			problems = append(problems, "Approval: "+err.Error())
+		}
+		if approval.Approver != "" && (approval.Approver == active || !slices.Contains(profiles, approval.Approver)) {
+			problems = append(problems, fmt.Sprintf("Approval: %s is not another profile on this instance.", approval.Approver))
+		}
+		out.Approval, out.Threshold = &approval, threshold
+	}
+
+	if doc.Routing != nil {
+		out.ReplaceRouting = true
+		out.Routing = []domain.RoutingRule{}
+		for i, route := range doc.Routing {
+			rule, err := domain.ParseRoutingRule(route.When, route.Value, route.Channel, route.Topic, domain.NumberFormatPoint)
+			var invalid *domain.ValidationError
+			if errors.As(err, &invalid) {
+				for _, field := range invalid.Fields {
+					problems = append(problems, fmt.Sprintf("Routing rule %d: %s", i+1, field.Message))
+				}
+				continue
+			}
+			out.Routing = append(out.Routing, rule)
+		}
+	}
+
+	if doc.Blackouts != nil {
+		out.ReplaceBlackout = true
+		out.Blackouts = []domain.Blackout{}
+		for i, entry := range doc.Blackouts {
+			if last, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(entry.LastDay), time.Local); err == nil && !last.AddDate(0, 0, 1).After(now) {
+				out.SkippedOver++
+				continue
+			}
+			blackout, err := domain.ParseBlackout(entry.FirstDay, entry.LastDay, entry.Label, now)
+			var invalid *domain.ValidationError
+			if errors.As(err, &invalid) {
+				for _, field := range invalid.Fields {
+					problems = append(problems, fmt.Sprintf("Blackout %d: %s", i+1, field.Message))
+				}
+				continue
+			}
+			out.Blackouts = append(out.Blackouts, blackout)
+		}
+		domain.SortBlackouts(out.Blackouts)
+	}
+	return out, problems
+}
+
+func (a *App) rulesSettings(w http.ResponseWriter, r *http.Request) {
+	feedback := ""
+	if r.URL.Query().Get("saved") == "imported" {
+		feedback = "Rules imported."
+		if skipped, _ := strconv.Atoi(r.URL.Query().Get("skipped")); skipped > 0 {
+			feedback += fmt.Sprintf(" %d blackout(s) that are already over were left out.", skipped)
+		}
+	}
+	a.renderRulesSettings(w, rulesSettingsViewData{Feedback: feedback})
+}
+
+// exportRules downloads the active profile's rules as YAML.
+func (a *App) exportRules(w http.ResponseWriter, r *http.Request) {
+	a.mu.RLock()
+	doc := a.exportRulesLocked(time.Now())
+	a.mu.RUnlock()
+
+	var buf bytes.Buffer
+	buf.WriteString("# Impulse Pause rules. Import them under Settings > Rules import/export.\n")
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		log.Printf("encode rules export: %v", err)
+		http.Error(w, "could not export rules", http.StatusInternalServerError)
+		return
+	}
+	encoder.Close()
+
+	w.Header().Set("Content-Type", "application/yaml; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "impulse-pause-rules.yaml"))
+	_, _ = w.Write(buf.Bytes())
+}
+
+// importRules replaces the active profile's rules with those of a pasted or uploaded rules file. Nothing
+// is changed when any rule in it is invalid.
+func (a *App) importRules(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxRulesUpload)
+	if err := r.ParseMultipartForm(maxRulesUpload); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	data := rulesSettingsViewData{Input: r.FormValue("rules")}
+	if file, _, err := r.FormFile("file"); err == nil {
+		uploaded, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			http.Error(w, "could not read upload", http.StatusBadRequest)
+			return
+		}
+		data.Input = string(uploaded)
+	}
+
+	profiles, err := a.listProfileNames()
+	if err != nil {
+		log.Printf("db error while listing profiles for a rules import: %v", err)
+		http.Error(w, "could not import rules", http.StatusInternalServerError)
+		return
+	}
+	now := time.Now()
+	rules, problems := parseRulesDocument([]byte(data.Input), profiles, a.activeProfileName(), now)
+	if len(problems) > 0 {
+		data.Error = "The rules were not imported. Please fix the file and try again:"
+		data.Problems = problems
+		w.WriteHeader(http.StatusBadRequest)
+		a.renderRulesSettings(w, data)
+		return
+	}
+
+	a.mu.LockContext(r.Context())
+	previousCatalog, previousTagWaits := a.tagCatalog, a.tagWaitDefaults
+	previousThreshold, previousApprover := a.approvalThreshold, a.approver
+	previousRouting, previousBlackouts := a.routingRules, a.blackouts
+	if rules.TagWaits != nil {
+		a.tagWaitDefaults = rules.TagWaits
+		for tag := range rules.TagWaits {
+			a.tagCatalog = appendTagOption(a.tagCatalog, tag)
+		}
+	}
+	if rules.Approval != nil {
+		a.approvalThreshold, a.approver = rules.Threshold, rules.Approval.Approver
+	}
+	if rules.ReplaceRouting {
+		a.routingRules = rules.Routing
+	}
+	if rules.ReplaceBlackout {
+		a.blackouts = rules.Blackouts
+	}
+	if err := a.persistProfileLocked(); err != nil {
+		a.tagCatalog, a.tagWaitDefaults = previousCatalog, previousTagWaits
+		a.approvalThreshold, a.approver = previousThreshold, previousApprover
+		a.routingRules, a.blackouts = previousRouting, previousBlackouts
+		a.mu.Unlock()
+		log.Printf("db error while importing rules: %v", err)
+		http.Error(w, "could not import rules", http.StatusInternalServerError)
+		return
+	}
+	if rules.ReplaceBlackout {
+		if _, err := a.itemServiceLocked().HoldBackForBlackouts(); err != nil {
+			log.Printf("db error while holding items back for blackouts: %v", err)
+		}
+	}
+	a.publishProfileUpdatedLocked("rules", r)
+	a.mu.Unlock()
+
+	target := "/settings/rules?saved=imported"
+	if rules.SkippedOver > 0 {
+		target += "&skipped=" + strconv.Itoa(rules.SkippedOver)
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
+
+func (a *App) renderRulesSettings(w http.ResponseWriter, data rulesSettingsViewData) {
+	now := time.Now()
+	a.mu.RLock()
+	data.ActiveProfile = a.currentUserIDLocked()
+	data.TagWaitCount = len(a.tagWaitDefaults)
+	data.HasApproval = a.approvalThreshold > 0 && a.approver != ""
+	data.RoutingCount = len(a.routingRules)
+	data.BlackoutCount = len(upcomingBlackouts(a.blackouts, now))
+	a.mu.RUnlock()
+
+	data.Title = "Rules import/export"
+	data.CurrentPath = "/settings/rules"
+	data.ContentTemplate = "rules_content"
+	renderTemplate(w, a.templates, "layout", data)
+}
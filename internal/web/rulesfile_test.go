@@ -0,0 +1,71 @@
+package web_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestRulesExportImportsIntoAnotherProfile(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{Profiles: []webtest.Profile{{Name: "Alex", HourlyWage: "25"}, {Name: "Sam", HourlyWage: "25"}, {Name: "Jo", HourlyWage: "25"}}})
+	alex := h.As("Alex")
+	now := time.Now()
+	day := func(days int) string { return now.AddDate(0, 0, days).Format("2006-01-02") }
+
+	rules := "version: 1\n" +
+		"tag_waits:\n  Tech: 30d\n  Hobby: 7d\n" +
+		"approval:\n  threshold: \"300\"\n  approver: Sam\n" +
+		"routing:\n  - when: price_at_least\n    value: \"200\"\n    channel: push\n  - when: any\n    channel: digest\n" +
+		"blackouts:\n  - first_day: " + day(10) + "\n    last_day: " + day(12) + "\n    label: Trip\n" +
+		"  - first_day: " + day(-20) + "\n    last_day: " + day(-18) + "\n"
+	alex.PostForm("/settings/rules", url.Values{"rules": {rules}}).ExpectRedirect("/settings/rules?saved=imported&skipped=1")
+	alex.Get("/settings/rules?saved=imported&skipped=1").
+		ExpectContains("Rules imported. 1 blackout(s) that are already over were left out.", "2 tag wait default(s)", "An approval rule", "2 routing rule(s)", "1 upcoming blackout(s)")
+	alex.Get("/settings/routing").ExpectContains("Items costing at least 200.00 go to web push only")
+
+	export := alex.Get("/settings/rules/export").ExpectStatus(http.StatusOK)
+	if got := export.Header().Get("Content-Disposition"); got != `attachment; filename="impulse-pause-rules.yaml"` {
+		t.Fatalf("unexpected content disposition %q", got)
+	}
+	exported := export.ExpectContains("Tech: 30d", "approver: Sam", "threshold: \"300.00\"", "label: Trip").ExpectNotContains(day(-20)).Body()
+
+	jo := h.As("Jo")
+	jo.PostForm("/settings/rules", url.Values{"rules": {exported}}).ExpectRedirect("/settings/rules?saved=imported")
+	if again := jo.Get("/settings/rules/export").Body(); again != exported {
+		t.Fatalf("expected the imported rules to export the same:\n%s\nwant:\n%s", again, exported)
+	}
+	jo.Get("/settings/routing").ExpectContains("All other items go to the weekly digest")
+}
+
+func TestRulesImportRejectsInvalidFilesAndKeepsLeftOutSections(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{Profiles: []webtest.Profile{{Name: "Alex", HourlyWage: "25"}}})
+	alex := h.As("Alex")
+
+	alex.PostForm("/settings/rules", url.Values{"rules": {"version: 1\nrouting:\n  - when: any\n    channel: digest\n"}}).
+		ExpectRedirect("/settings/rules?saved=imported")
+
+	alex.PostForm("/settings/rules", url.Values{"rules": {"tag_waits:\n  Tech: 30d\n"}}).
+		ExpectStatus(http.StatusBadRequest).ExpectContains("The rules file needs &#34;version: 1&#34;.")
+	alex.PostForm("/settings/rules", url.Values{"rules": {"version: 1\nrouting: [\n"}}).
+		ExpectStatus(http.StatusBadRequest).ExpectContains("The rules file is not valid YAML")
+	alex.PostForm("/settings/rules", url.Values{"rules": {"version: 1\npricebands: {}\n"}}).
+		ExpectStatus(http.StatusBadRequest).ExpectContains("field pricebands not found")
+	alex.PostForm("/settings/rules", url.Values{"rules": {"version: 1\n" +
+		"tag_waits:\n  Tech: forever\n" +
+		"approval:\n  threshold: lots\n  approver: Alex\n" +
+		"routing:\n  - when: tag\n    channel: push\n"}}).
+		ExpectStatus(http.StatusBadRequest).
+		ExpectContains("Tag wait for Tech: please use one of", "Approval: Please enter a valid approval threshold.",
+			"Approval: Alex is not another profile on this instance.", "Routing rule 1: Please enter the tag the rule applies to.", "tag_waits:")
+
+	// Nothing was changed by the invalid files, and a file with only tag waits keeps the routing rules.
+	alex.PostForm("/settings/rules", url.Values{"rules": {"version: 1\ntag_waits:\n  Tech: 30d\n"}}).
+		ExpectRedirect("/settings/rules?saved=imported")
+	alex.Get("/settings/rules/export").ExpectContains("Tech: 30d", "channel: digest")
+	alex.PostForm("/settings/rules", url.Values{"rules": {"version: 1\nrouting: []\n"}}).
+		ExpectRedirect("/settings/rules?saved=imported")
+	alex.Get("/settings/rules").ExpectContains("1 tag wait default(s)", "0 routing rule(s)")
+}
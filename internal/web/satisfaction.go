@@ -0,0 +1,148 @@
+package web
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"mvpapp/internal/domain"
+)
+
+const (
+	satisfactionWorthIt = "worth_it"
+	satisfactionRegret  = "regret"
+
+	maxUrgeScore = 5
+)
+
+// categoryRegretRate ranks categories by how often bought items were regretted afterwards.
+type categoryRegretRate struct {
+	Name          string
+	RegretCount   int
+	ResponseCount int
+	Ratio         float64
+	AverageUrge   float64
+	HasUrge       bool
+}
+
+// parseUrgeScore accepts an empty value (not rated) or a score from 1 to maxUrgeScore.
+func parseUrgeScore(raw string) (int, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, nil
+	}
+	score, err := strconv.Atoi(raw)
+	if err != nil || score < 1 || score > maxUrgeScore {
+		return 0, errors.New("invalid urge score")
+	}
+	return score, nil
+}
+
+func buildCategoryRegretRates(items []Item) []categoryRegretRate {
+	responses := map[string]int{}
+	regrets := map[string]int{}
+	urgeTotals := map[string]int{}
+	urgeCounts := map[string]int{}
+
+	for _, item := range items {
+		if item.Status != domain.StatusBought || (item.Satisfaction != satisfactionWorthIt && item.Satisfaction != satisfactionRegret) {
+			continue
+		}
+
+		for _, category := range categoriesFromTags(item.Tags) {
+			responses[category]++
+			if item.Satisfaction == satisfactionRegret {
+				regrets[category]++
+			}
+			if item.UrgeScore > 0 {
+				urgeTotals[category] += item.UrgeScore
+				urgeCounts[category]++
+			}
+		}
+	}
+
+	if len(responses) == 0 {
+		return nil
+	}
+
+	result := make([]categoryRegretRate, 0, len(responses))
+	for category, responseCount := range responses {
+		rate := categoryRegretRate{
+			Name:          category,
+			RegretCount:   regrets[category],
+			ResponseCount: responseCount,
+			Ratio:         float64(regrets[category]) / float64(responseCount),
+		}
+		if urgeCounts[category] > 0 {
+			rate.AverageUrge = float64(urgeTotals[category]) / float64(urgeCounts[category])
+			rate.HasUrge = true
+		}
+		result = append(result, rate)
+	}
+
+	slices.SortFunc(result, func(a, b categoryRegretRate) int {
+		if a.Ratio != b.Ratio {
+			if a.Ratio > b.Ratio {
+				return -1
+			}
+			return 1
+		}
+		if a.ResponseCount != b.ResponseCount {
+			return b.ResponseCount - a.ResponseCount
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	if len(result) > 5 {
+		result = result[:5]
+	}
+
+	return result
+}
+
+func (a *App) rateSatisfaction(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	id, err := strconv.Atoi(strings.TrimSpace(r.FormValue("item_id")))
+	if err != nil || id <= 0 {
+		http.Error(w, "invalid item id", http.StatusBadRequest)
+		return
+	}
+
+	satisfaction := strings.TrimSpace(r.FormValue("satisfaction"))
+	if satisfaction != satisfactionWorthIt && satisfaction != satisfactionRegret {
+		http.Error(w, "invalid satisfaction", http.StatusBadRequest)
+		return
+	}
+
+	a.mu.LockContext(r.Context())
+	defer a.mu.Unlock()
+
+	i := a.itemIndexLocked(id)
+	if i < 0 {
+		http.NotFound(w, r)
+		return
+	}
+	if a.items[i].Status != domain.StatusBought {
+		http.Error(w, "only bought items can be rated", http.StatusConflict)
+		return
+	}
+
+	previous := a.items[i].Satisfaction
+	a.items[i].Satisfaction = satisfaction
+	if err := a.updateItemLocked(a.items[i]); err != nil {
+		a.items[i].Satisfaction = previous
+		log.Printf("db error while saving satisfaction: %v", err)
+		http.Error(w, "could not save rating", http.StatusInternalServerError)
+		return
+	}
+	a.recordHistoryLocked(id, "rated", strings.ReplaceAll(satisfaction, "_", " "))
+
+	itemActionRedirect(w, r, "rated", a.items[i].Title)
+}
@@ -0,0 +1,88 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildCategoryRegretRatesRanksByRegret(t *testing.T) {
+	items := []Item{
+		{Status: "Bought", Tags: "Gaming", Satisfaction: satisfactionRegret, UrgeScore: 5},
+		{Status: "Bought", Tags: "Gaming", Satisfaction: satisfactionRegret, UrgeScore: 4},
+		{Status: "Bought", Tags: "Gaming, Office", Satisfaction: satisfactionWorthIt},
+		{Status: "Bought", Tags: "Office", Satisfaction: satisfactionWorthIt, UrgeScore: 2},
+		{Status: "Bought", Tags: "Travel"},
+		{Status: "Skipped", Tags: "Travel", Satisfaction: satisfactionRegret},
+	}
+
+	rates := buildCategoryRegretRates(items)
+	if len(rates) != 2 {
+		t.Fatalf("expected only rated bought categories, got %+v", rates)
+	}
+	if rates[0].Name != "gaming" || rates[0].RegretCount != 2 || rates[0].ResponseCount != 3 {
+		t.Fatalf("expected Gaming first with 2/3 regrets, got %+v", rates[0])
+	}
+	if !rates[0].HasUrge || rates[0].AverageUrge != 4.5 {
+		t.Fatalf("expected average urge 4.5 for Gaming, got %+v", rates[0])
+	}
+	if rates[1].Name != "office" || rates[1].Ratio != 0 {
+		t.Fatalf("expected Office last without regrets, got %+v", rates[1])
+	}
+}
+
+func TestParseUrgeScore(t *testing.T) {
+	if score, err := parseUrgeScore(""); err != nil || score != 0 {
+		t.Fatalf("expected empty urge score to be unrated, got %d (%v)", score, err)
+	}
+	if score, err := parseUrgeScore("4"); err != nil || score != 4 {
+		t.Fatalf("expected 4, got %d (%v)", score, err)
+	}
+	for _, raw := range []string{"0", "6", "abc"} {
+		if _, err := parseUrgeScore(raw); err == nil {
+			t.Fatalf("expected %q to be rejected", raw)
+		}
+	}
+}
+
+func TestRateSatisfactionOnlyForBoughtItems(t *testing.T) {
+	app := NewApp()
+	seedProfile(app)
+
+	app.mu.Lock()
+	bought := Item{Title: "Keyboard", Tags: "Office", Status: "Bought", UrgeScore: 3, CreatedAt: time.Now(), PurchaseAllowedAt: time.Now()}
+	waiting := Item{Title: "Mouse", Status: "Waiting", CreatedAt: time.Now(), PurchaseAllowedAt: time.Now().Add(time.Hour)}
+	_ = app.insertItemLocked(&bought)
+	_ = app.insertItemLocked(&waiting)
+	app.items = []Item{bought, waiting}
+	app.mu.Unlock()
+
+	rate := func(id int, satisfaction string) int {
+		form := url.Values{"item_id": {strconv.Itoa(id)}, "satisfaction": {satisfaction}}
+		req := httptest.NewRequest(http.MethodPost, "/items/satisfaction", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		app.Handler().ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	if code := rate(waiting.ID, satisfactionRegret); code != http.StatusConflict {
+		t.Fatalf("expected conflict for waiting item, got %d", code)
+	}
+	if code := rate(bought.ID, "meh"); code != http.StatusBadRequest {
+		t.Fatalf("expected bad request for unknown answer, got %d", code)
+	}
+	if code := rate(bought.ID, satisfactionRegret); code != http.StatusSeeOther {
+		t.Fatalf("expected redirect, got %d", code)
+	}
+
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/insights", nil))
+	if body := rr.Body.String(); !strings.Contains(body, "What should I stop buying?") || !strings.Contains(body, "1 / 1") {
+		t.Fatalf("expected regret ranking on insights page")
+	}
+}
@@ -0,0 +1,525 @@
+package web
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// jobSchedule tells when a background job runs next. A zero time means never.
+type jobSchedule interface {
+	Next(after time.Time) time.Time
+}
+
+// everySchedule runs a job at a fixed interval, as in "@every 5s".
+type everySchedule time.Duration
+
+func (e everySchedule) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(e))
+}
+
+// cronSchedule runs a job at the minutes matching a five-field cron expression, in the server's time zone.
+// Each field is a bit set of the values it allows.
+type cronSchedule struct {
+	minutes, hours, days, months, weekdays uint64
+	// anyDay and anyWeekday are set for "*"; when both day fields are restricted, either may match.
+	anyDay, anyWeekday bool
+}
+
+// cronAliases are the named schedules accepted besides "@every <duration>".
+var cronAliases = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+}
+
+// parseJobSchedule reads "@every <duration>", an alias such as "@daily", or a five-field cron expression
+// ("minute hour day-of-month month day-of-week") with "*", lists, ranges and steps.
+func parseJobSchedule(expr string) (jobSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	if rest, ok := strings.CutPrefix(expr, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("invalid interval %q", rest)
+		}
+		return everySchedule(interval), nil
+	}
+	if alias, ok := cronAliases[expr]; ok {
+		expr = alias
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("schedule %q needs five fields or @every", expr)
+	}
+	var c cronSchedule
+	var err error
+	if c.minutes, _, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("minute: %w", err)
+	}
+	if c.hours, _, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("hour: %w", err)
+	}
+	if c.days, c.anyDay, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("day of month: %w", err)
+	}
+	if c.months, _, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("month: %w", err)
+	}
+	if c.weekdays, c.anyWeekday, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("day of week: %w", err)
+	}
+	if c.weekdays&(1<<7) != 0 {
+		c.weekdays |= 1
+	}
+	return c, nil
+}
+
+// parseCronField returns the values a cron field allows as a bit set and whether it is "*".
+func parseCronField(field string, lowest, highest int) (uint64, bool, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if rangePart, stepPart, ok := strings.Cut(part, "/"); ok {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, false, fmt.Errorf("invalid step %q", stepPart)
+			}
+			part, step = rangePart, n
+		}
+		lo, hi := lowest, highest
+		if part != "*" {
+			first, last, isRange := strings.Cut(part, "-")
+			var err error
+			if lo, err = strconv.Atoi(first); err != nil {
+				return 0, false, fmt.Errorf("invalid value %q", first)
+			}
+			switch {
+			case isRange:
+				if hi, err = strconv.Atoi(last); err != nil {
+					return 0, false, fmt.Errorf("invalid value %q", last)
+				}
+			case step == 1:
+				hi = lo
+			}
+		}
+		if lo < lowest || hi > highest || lo > hi {
+			return 0, false, fmt.Errorf("%q is outside %d-%d", part, lowest, highest)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, field == "*", nil
+}
+
+func (c cronSchedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	// Impossible dates such as February 30th never match; give up after a few years.
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case c.months&(1<<int(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case c.hours&(1<<t.Hour()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case c.minutes&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (c cronSchedule) dayMatches(t time.Time) bool {
+	day := c.days&(1<<t.Day()) != 0
+	weekday := c.weekdays&(1<<int(t.Weekday())) != 0
+	if !c.anyDay && !c.anyWeekday {
+		return day || weekday
+	}
+	return day && weekday
+}
+
+// backgroundJob is a recurring task registered with the scheduler. The exported fields are its
+// status, shown on /household and kept in job_runs across restarts.
+type backgroundJob struct {
+	Name         string
+	Schedule     string
+	Jitter       time.Duration
+	Enabled      bool
+	LastStarted  time.Time
+	LastFinished time.Time
+	LastError    string
+	// LastManual is set when the last run was triggered from /household rather than the schedule.
+	LastManual bool
+	Runs       int
+
+	schedule   jobSchedule
+	runAtStart bool
+	run        func(a *App, now time.Time) error
+	// running is held during a run, so a run from /household waits for a scheduled one to finish.
+	running *sync.Mutex
+	// wake makes the job's loop pick up a changed schedule, or run the job when triggered is set.
+	wake      chan struct{}
+	triggered bool
+}
+
+// jobConfig overrides a job's defaults; see SetJobSchedule and SetJobEnabled.
+type jobConfig struct {
+	schedule jobSchedule
+	expr     string
+	jitter   time.Duration
+	disabled bool
+}
+
+// jobScheduler runs the background jobs, each in its own goroutine once StartJobs was called. It has its
+// own lock so jobs can take a.mu while they run.
+type jobScheduler struct {
+	mu      sync.Mutex
+	jobs    []*backgroundJob
+	configs map[string]jobConfig
+	started bool
+}
+
+// registerJob adds a job that runs run on schedule expr, a programmer-supplied schedule that must parse.
+// Each run is delayed by up to jitter, so jobs of several instances do not hit shared services at once.
+// With runAtStart the job also runs as soon as it starts. Registering a name again replaces that job's
+// schedule and work but keeps its status. Like a request, each run gets its own app to load the profiles
+// it works on into.
+func (a *App) registerJob(name, expr string, jitter time.Duration, runAtStart bool, run func(a *App, now time.Time) error) {
+	schedule, err := parseJobSchedule(expr)
+	if err != nil {
+		panic(fmt.Sprintf("web: job %s: %v", name, err))
+	}
+
+	a.jobs.mu.Lock()
+	for _, job := range a.jobs.jobs {
+		if job.Name == name {
+			job.Schedule, job.Jitter, job.schedule, job.runAtStart, job.run = expr, jitter, schedule, runAtStart, run
+			if cfg, ok := a.jobs.configs[name]; ok {
+				applyJobConfig(job, cfg)
+			}
+			a.jobs.mu.Unlock()
+			wakeJob(job)
+			return
+		}
+	}
+	a.jobs.mu.Unlock()
+
+	job := &backgroundJob{Name: name, Schedule: expr, Jitter: jitter, Enabled: true, schedule: schedule, runAtStart: runAtStart, run: run, running: &sync.Mutex{}, wake: make(chan struct{}, 1)}
+	if err := a.loadJobStatus(job); err != nil {
+		log.Printf("db error while loading job %s: %v", name, err)
+	}
+
+	a.jobs.mu.Lock()
+	defer a.jobs.mu.Unlock()
+	if cfg, ok := a.jobs.configs[name]; ok {
+		applyJobConfig(job, cfg)
+	}
+	a.jobs.jobs = append(a.jobs.jobs, job)
+	if a.jobs.started {
+		go a.jobLoop(job)
+	}
+}
+
+// StartJobs starts the registered background jobs, such as promotion and purge; jobs registered later
+// start right away. Configure them with SetJobSchedule and SetJobEnabled first, so a disabled job does
+// not run at start.
+func (a *App) StartJobs() {
+	a.jobs.mu.Lock()
+	defer a.jobs.mu.Unlock()
+	if a.jobs.started {
+		return
+	}
+	a.jobs.started = true
+	for _, job := range a.jobs.jobs {
+		go a.jobLoop(job)
+	}
+}
+
+func (a *App) jobLoop(job *backgroundJob) {
+	a.jobs.mu.Lock()
+	runAtStart := job.runAtStart
+	a.jobs.mu.Unlock()
+	if runAtStart {
+		a.runJob(job, false)
+	}
+	for {
+		a.jobs.mu.Lock()
+		next := job.schedule.Next(time.Now())
+		if !next.IsZero() && job.Jitter > 0 {
+			next = next.Add(rand.N(job.Jitter))
+		}
+		a.jobs.mu.Unlock()
+
+		if next.IsZero() {
+			<-job.wake
+			a.runTriggeredJob(job)
+			continue
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-timer.C:
+			a.runJob(job, false)
+		case <-job.wake:
+			timer.Stop()
+			a.runTriggeredJob(job)
+		}
+	}
+}
+
+// runTriggeredJob runs job if triggerJob asked for a run since its last one.
+func (a *App) runTriggeredJob(job *backgroundJob) {
+	a.jobs.mu.Lock()
+	triggered := job.triggered
+	job.triggered = false
+	a.jobs.mu.Unlock()
+	if triggered {
+		a.runJob(job, false)
+	}
+}
+
+func applyJobConfig(job *backgroundJob, cfg jobConfig) {
+	if cfg.schedule != nil {
+		job.schedule, job.Schedule, job.Jitter = cfg.schedule, cfg.expr, cfg.jitter
+	}
+	job.Enabled = !cfg.disabled
+}
+
+// configureJob records an override for the named job, which may not be registered yet, and applies it.
+func (a *App) configureJob(name string, update func(*jobConfig)) {
+	a.jobs.mu.Lock()
+	defer a.jobs.mu.Unlock()
+	if a.jobs.configs == nil {
+		a.jobs.configs = map[string]jobConfig{}
+	}
+	cfg := a.jobs.configs[name]
+	update(&cfg)
+	a.jobs.configs[name] = cfg
+	for _, job := range a.jobs.jobs {
+		if job.Name == name {
+			applyJobConfig(job, cfg)
+			wakeJob(job)
+		}
+	}
+}
+
+// wakeJob makes the job's loop pick up a changed schedule without waiting for its current one.
+func wakeJob(job *backgroundJob) {
+	select {
+	case job.wake <- struct{}{}:
+	default:
+	}
+}
+
+// triggerJob makes the named job run soon instead of at its next scheduled time, unless it is disabled.
+// The run happens in the job's own goroutine, so a request may trigger it while holding a.mu. Triggers
+// before the run starts are combined into one run.
+func (a *App) triggerJob(name string) {
+	a.jobs.mu.Lock()
+	defer a.jobs.mu.Unlock()
+	for _, job := range a.jobs.jobs {
+		if job.Name == name {
+			job.triggered = true
+			wakeJob(job)
+		}
+	}
+}
+
+// SetJobSchedule replaces the schedule of the named background job, such as "purge" or "maintenance",
+// with "@every <duration>", "@daily" and the like, or a five-field cron expression. Each run is delayed
+// by up to jitter.
+func (a *App) SetJobSchedule(name, expr string, jitter time.Duration) error {
+	if err := CheckJobSchedule(expr, jitter); err != nil {
+		return err
+	}
+	schedule, _ := parseJobSchedule(expr)
+	a.configureJob(name, func(cfg *jobConfig) {
+		cfg.schedule, cfg.expr, cfg.jitter = schedule, strings.TrimSpace(expr), jitter
+	})
+	return nil
+}
+
+// CheckJobSchedule reports why SetJobSchedule would reject expr and jitter, so configuration can be
+// checked before an App exists.
+func CheckJobSchedule(expr string, jitter time.Duration) error {
+	if _, err := parseJobSchedule(expr); err != nil {
+		return err
+	}
+	if jitter < 0 {
+		return errors.New("jitter must not be negative")
+	}
+	return nil
+}
+
+// KnownJobNames lists every background job an App may register, including those that only run in
+// some setups, such as "demo-reset" in demo mode.
+func KnownJobNames() []string {
+	return []string{"promotion", "purge", "outbox", "maintenance", "backup", "leaderboard-digest", "ready-digest", "demo-reset"}
+}
+
+// SetJobEnabled turns the named background job on or off. A disabled job keeps its schedule but skips its runs.
+func (a *App) SetJobEnabled(name string, enabled bool) {
+	a.configureJob(name, func(cfg *jobConfig) { cfg.disabled = !enabled })
+}
+
+// JobNames lists the registered background jobs.
+func (a *App) JobNames() []string {
+	a.jobs.mu.Lock()
+	defer a.jobs.mu.Unlock()
+	names := make([]string, 0, len(a.jobs.jobs))
+	for _, job := range a.jobs.jobs {
+		names = append(names, job.Name)
+	}
+	return names
+}
+
+// jobStatuses returns a copy of every job's status, in registration order.
+func (a *App) jobStatuses() []backgroundJob {
+	a.jobs.mu.Lock()
+	defer a.jobs.mu.Unlock()
+	statuses := make([]backgroundJob, 0, len(a.jobs.jobs))
+	for _, job := range a.jobs.jobs {
+		statuses = append(statuses, backgroundJob{Name: job.Name, Schedule: job.Schedule, Jitter: job.Jitter, Enabled: job.Enabled, LastStarted: job.LastStarted, LastFinished: job.LastFinished, LastError: job.LastError, LastManual: job.LastManual, Runs: job.Runs})
+	}
+	return statuses
+}
+
+// runJob runs job once and records the outcome. Scheduled runs of a disabled job are skipped, manual
+// ones are not. It returns the job's status after the run and whether it ran.
+func (a *App) runJob(job *backgroundJob, manual bool) (backgroundJob, bool) {
+	a.jobs.mu.Lock()
+	enabled, run := job.Enabled, job.run
+	a.jobs.mu.Unlock()
+	if !enabled && !manual {
+		return backgroundJob{}, false
+	}
+
+	job.running.Lock()
+	started := time.Now()
+	err := run(a.profileView(), started)
+	job.running.Unlock()
+	if err != nil {
+		log.Printf("job %s failed: %v", job.Name, err)
+	}
+
+	a.jobs.mu.Lock()
+	job.LastStarted, job.LastFinished, job.LastManual, job.Runs = started, time.Now(), manual, job.Runs+1
+	job.LastError = ""
+	if err != nil {
+		job.LastError = err.Error()
+	}
+	status := *job
+	a.jobs.mu.Unlock()
+
+	if err := a.saveJobStatus(status); err != nil {
+		log.Printf("db error while saving job %s: %v", job.Name, err)
+	}
+	return status, true
+}
+
+// RunJob runs the named background job right away, even when it is disabled, and returns its status
+// afterwards. It reports false for an unknown job.
+func (a *App) RunJob(name string) (backgroundJob, bool) {
+	a.jobs.mu.Lock()
+	var job *backgroundJob
+	for _, registered := range a.jobs.jobs {
+		if registered.Name == name {
+			job = registered
+		}
+	}
+	a.jobs.mu.Unlock()
+	if job == nil {
+		return backgroundJob{}, false
+	}
+	return a.runJob(job, true)
+}
+
+// Duration is how long the last run took.
+func (job backgroundJob) Duration() time.Duration {
+	if job.LastFinished.Before(job.LastStarted) {
+		return 0
+	}
+	return job.LastFinished.Sub(job.LastStarted).Round(time.Millisecond)
+}
+
+// jobRunResponse is the JSON answer to POST /household/jobs/{name}/run.
+type jobRunResponse struct {
+	Job        string    `json:"job"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMS int64     `json:"duration_ms"`
+	OK         bool      `json:"ok"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// runJobNow is the household page's "Run now" button of each job. Scripts that ask for JSON get the
+// outcome directly instead of the redirect, as a 500 when the job failed.
+func (a *App) runJobNow(w http.ResponseWriter, r *http.Request) {
+	if !a.requireAdmin(w, r) {
+		return
+	}
+	name := r.PathValue("name")
+	status, ok := a.RunJob(name)
+	if !ok {
+		http.Error(w, "unknown job", http.StatusNotFound)
+		return
+	}
+
+	if !strings.Contains(r.Header.Get("Accept"), "application/json") {
+		householdRedirect(w, r)
+		return
+	}
+	code := http.StatusOK
+	if status.LastError != "" {
+		code = http.StatusInternalServerError
+	}
+	writeJSON(w, code, jobRunResponse{Job: name, StartedAt: status.LastStarted, DurationMS: status.Duration().Milliseconds(), OK: status.LastError == "", Error: status.LastError})
+}
+
+func (a *App) loadJobStatus(job *backgroundJob) error {
+	if a.db == nil {
+		return nil
+	}
+	var started, finished string
+	err := a.db.QueryRow(`SELECT last_started_at, last_finished_at, last_error, last_manual, runs FROM job_runs WHERE name = ?`, job.Name).Scan(&started, &finished, &job.LastError, &job.LastManual, &job.Runs)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("load job status: %w", err)
+	}
+	job.LastStarted, _ = time.Parse(time.RFC3339Nano, started)
+	job.LastFinished, _ = time.Parse(time.RFC3339Nano, finished)
+	return nil
+}
+
+func (a *App) saveJobStatus(job backgroundJob) error {
+	if a.db == nil {
+		return nil
+	}
+	_, err := a.db.Exec(`
+INSERT INTO job_runs(name, last_started_at, last_finished_at, last_error, last_manual, runs) VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT(name) DO UPDATE SET
+	last_started_at = excluded.last_started_at,
+	last_finished_at = excluded.last_finished_at,
+	last_error = excluded.last_error,
+	last_manual = excluded.last_manual,
+	runs = excluded.runs
+`, job.Name, job.LastStarted.Format(time.RFC3339Nano), job.LastFinished.Format(time.RFC3339Nano), job.LastError, job.LastManual, job.Runs)
+	if err != nil {
+		return fmt.Errorf("save job status: %w", err)
+	}
+	return nil
+}
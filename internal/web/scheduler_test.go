@@ -0,0 +1,197 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseJobScheduleFindsTheNextMatchingMinute(t *testing.T) {
+	from := time.Date(2026, time.March, 13, 10, 17, 42, 0, time.UTC) // a Friday
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"@every 90s", from.Add(90 * time.Second)},
+		{"*/15 * * * *", time.Date(2026, time.March, 13, 10, 30, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, time.March, 14, 0, 0, 0, 0, time.UTC)},
+		{"30 3 * * 0", time.Date(2026, time.March, 15, 3, 30, 0, 0, time.UTC)},
+		{"0 9 1,15 * *", time.Date(2026, time.March, 15, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 6-8 *", time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC)},
+		// With both day fields restricted, either one matches, as in cron.
+		{"0 12 1 * 1", time.Date(2026, time.March, 16, 12, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		schedule, err := parseJobSchedule(tt.expr)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tt.expr, err)
+		}
+		if got := schedule.Next(from); !got.Equal(tt.want) {
+			t.Fatalf("%q: expected next run at %s, got %s", tt.expr, tt.want, got)
+		}
+	}
+
+	for _, expr := range []string{"", "@every soon", "@every -1m", "* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *"} {
+		if _, err := parseJobSchedule(expr); err == nil {
+			t.Fatalf("expected %q to be rejected", expr)
+		}
+	}
+}
+
+func TestJobStatusIsShownAndKeptAcrossRestarts(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.sqlite")
+	app, err := NewAppWithSQLite(dbPath)
+	if err != nil {
+		t.Fatalf("new sqlite app: %v", err)
+	}
+	ran := make(chan struct{}, 1)
+	app.registerJob("price-refresh", "@every 1h", 0, true, func(*App, time.Time) error {
+		ran <- struct{}{}
+		return errors.New("shop unreachable")
+	})
+	app.SetAdminToken("s3cret")
+	app.StartJobs()
+	<-ran
+
+	// The status is saved after the run, so wait for it to reach the database.
+	var runs int
+	for deadline := time.Now().Add(5 * time.Second); runs == 0 && time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		_ = app.db.QueryRow(`SELECT runs FROM job_runs WHERE name = ?`, "price-refresh").Scan(&runs)
+	}
+	var status backgroundJob
+	for _, job := range app.jobStatuses() {
+		if job.Name == "price-refresh" {
+			status = job
+		}
+	}
+	if runs != 1 || status.Runs != 1 || status.LastError != "shop unreachable" || status.LastStarted.IsZero() {
+		t.Fatalf("expected one failed run, got %d saved and %+v", runs, status)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/household?token=s3cret", nil)
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "price-refresh") || !strings.Contains(rec.Body.String(), "failed: shop unreachable") {
+		t.Fatalf("expected /household to list the failed job, got %d %s", rec.Code, rec.Body.String())
+	}
+	_ = app.Close()
+
+	restarted, err := NewAppWithSQLite(dbPath)
+	if err != nil {
+		t.Fatalf("reopen sqlite app: %v", err)
+	}
+	defer restarted.Close()
+	restarted.registerJob("price-refresh", "@every 1h", 0, false, func(*App, time.Time) error { return nil })
+	for _, job := range restarted.jobStatuses() {
+		if job.Name == "price-refresh" && (job.Runs != 1 || job.LastError != "shop unreachable") {
+			t.Fatalf("expected the last run to survive a restart, got %+v", job)
+		}
+	}
+}
+
+func TestDisabledJobSkipsItsRuns(t *testing.T) {
+	app := NewApp()
+	runs := 0
+	app.registerJob("digest", "@every 1h", 0, false, func(*App, time.Time) error {
+		runs++
+		return nil
+	})
+	app.SetJobEnabled("digest", false)
+
+	var job *backgroundJob
+	for _, registered := range app.jobs.jobs {
+		if registered.Name == "digest" {
+			job = registered
+		}
+	}
+	app.runJob(job, false)
+	if runs != 0 || job.Runs != 0 {
+		t.Fatalf("expected a disabled job not to run, got %d runs", runs)
+	}
+
+	if err := app.SetJobSchedule("digest", "0 7 * * *", time.Minute); err != nil {
+		t.Fatalf("set schedule: %v", err)
+	}
+	if err := app.SetJobSchedule("digest", "sometimes", 0); err == nil {
+		t.Fatal("expected an invalid schedule to be rejected")
+	}
+	app.SetJobEnabled("digest", true)
+	app.runJob(job, false)
+	if runs != 1 || job.Schedule != "0 7 * * *" || job.Jitter != time.Minute {
+		t.Fatalf("expected the enabled job to run on its new schedule, got %d runs, %+v", runs, job)
+	}
+}
+
+func TestRunJobNowRunsADisabledJobAndReportsTheOutcome(t *testing.T) {
+	app := NewApp()
+	app.SetAdminToken("s3cret")
+	runs := 0
+	app.registerJob("backup", "@daily", 0, false, func(*App, time.Time) error {
+		runs++
+		if runs > 1 {
+			return errors.New("disk full")
+		}
+		return nil
+	})
+	app.SetJobEnabled("backup", false)
+
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/household/jobs/backup/run?token=s3cret", nil))
+	if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/household?token=s3cret" || runs != 1 {
+		t.Fatalf("expected the form to run the job and return to /household, got %d %q after %d runs", rr.Code, rr.Header().Get("Location"), runs)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/household/jobs/backup/run", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	req.Header.Set("Accept", "application/json")
+	rr = httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+	var body jobRunResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if rr.Code != http.StatusInternalServerError || body.Job != "backup" || body.OK || body.Error != "disk full" || body.StartedAt.IsZero() {
+		t.Fatalf("expected the failed run to be reported, got %d %+v", rr.Code, body)
+	}
+
+	rr = httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/household?token=s3cret", nil))
+	if !strings.Contains(rr.Body.String(), "(run manually)") || !strings.Contains(rr.Body.String(), `action="/household/jobs/backup/run?token=s3cret"`) {
+		t.Fatalf("expected /household to show the manual run and its button, got %s", rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/household/jobs/nope/run?token=s3cret", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown job, got %d", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/household/jobs/backup/run?token=wrong", nil))
+	if rr.Code != http.StatusUnauthorized || runs != 2 {
+		t.Fatalf("expected a wrong token to be rejected without running the job, got %d after %d runs", rr.Code, runs)
+	}
+}
+
+func TestKnownJobNamesCoverEveryRegisteredJob(t *testing.T) {
+	app, cleanup := newSQLiteTestApp(t)
+	defer cleanup()
+	app.StartBackgroundBackups(t.TempDir(), time.Hour)
+	if err := app.EnableDemoMode(time.Hour); err != nil {
+		t.Fatalf("enable demo mode: %v", err)
+	}
+
+	names := app.JobNames()
+	slices.Sort(names)
+	known := KnownJobNames()
+	slices.Sort(known)
+	if !slices.Equal(names, known) {
+		t.Fatalf("expected the known jobs %v to match the registered ones %v", known, names)
+	}
+}
@@ -0,0 +1,76 @@
+package web
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// schemaFiles are the JSON Schemas of the payloads the app sends to other systems. Their fields only
+// grow: removing or renaming one breaks consumers, so that needs a new schema file instead.
+//
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// payloadSchema is an entry of the GET /api/schemas/ index.
+type payloadSchema struct {
+	Name        string `json:"name"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+}
+
+// payloadSchemas lists the embedded schemas by file name.
+func payloadSchemas() ([]payloadSchema, error) {
+	names, err := fs.Glob(schemaFiles, "schemas/*.json")
+	if err != nil {
+		return nil, err
+	}
+	schemas := make([]payloadSchema, 0, len(names))
+	for _, name := range names {
+		raw, err := schemaFiles.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		var schema payloadSchema
+		if err := json.Unmarshal(raw, &schema); err != nil {
+			return nil, err
+		}
+		schema.Name = path.Base(name)
+		schema.URL = "/api/schemas/" + schema.Name
+		schemas = append(schemas, schema)
+	}
+	return schemas, nil
+}
+
+// apiSchemas lists the payload schemas. They are the same for every profile and need no token.
+func (a *App) apiSchemas(w http.ResponseWriter, r *http.Request) {
+	schemas, err := payloadSchemas()
+	if err != nil {
+		log.Printf("list payload schemas: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "could not list schemas")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"schemas": schemas})
+}
+
+// apiSchema serves one payload schema as application/schema+json.
+func (a *App) apiSchema(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if strings.Contains(name, "/") || path.Ext(name) != ".json" {
+		writeAPIError(w, http.StatusNotFound, "unknown schema")
+		return
+	}
+	raw, err := schemaFiles.ReadFile("schemas/" + name)
+	if err != nil {
+		writeAPIError(w, http.StatusNotFound, "unknown schema")
+		return
+	}
+	w.Header().Set("Content-Type", "application/schema+json")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	_, _ = w.Write(raw)
+}
@@ -0,0 +1,266 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// validateSchema checks value against the parts of JSON Schema the payload schemas use: type, const,
+// enum, required, properties, additionalProperties, items, minimum and the date-time and uri formats.
+func validateSchema(schema map[string]any, value any, at string) []string {
+	var problems []string
+	fail := func(format string, args ...any) {
+		problems = append(problems, at+": "+fmt.Sprintf(format, args...))
+	}
+	if want, ok := schema["const"]; ok && !reflect.DeepEqual(want, value) {
+		fail("want %v, got %v", want, value)
+	}
+	if enum, ok := schema["enum"].([]any); ok && !slices.ContainsFunc(enum, func(v any) bool { return reflect.DeepEqual(v, value) }) {
+		fail("%v is not one of %v", value, enum)
+	}
+	switch schema["type"] {
+	case nil:
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			fail("want an object, got %T", value)
+			break
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		for _, name := range schema["required"].([]any) {
+			if _, ok := object[name.(string)]; !ok {
+				fail("missing required %s", name)
+			}
+		}
+		for name, field := range object {
+			property, ok := properties[name].(map[string]any)
+			if !ok {
+				if schema["additionalProperties"] == false {
+					fail("unexpected property %s", name)
+				}
+				continue
+			}
+			problems = append(problems, validateSchema(property, field, at+"."+name)...)
+		}
+	case "array":
+		array, ok := value.([]any)
+		if !ok {
+			fail("want an array, got %T", value)
+			break
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, element := range array {
+				problems = append(problems, validateSchema(items, element, fmt.Sprintf("%s[%d]", at, i))...)
+			}
+		}
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			fail("want a string, got %T", value)
+			break
+		}
+		switch schema["format"] {
+		case "date-time":
+			if _, err := time.Parse(time.RFC3339Nano, text); err != nil {
+				fail("%q is not a date-time", text)
+			}
+		case "uri":
+			if parsed, err := url.Parse(text); err != nil || !parsed.IsAbs() {
+				fail("%q is not an absolute URI", text)
+			}
+		}
+	case "integer", "number":
+		number, ok := value.(float64)
+		if !ok || (schema["type"] == "integer" && number != float64(int64(number))) {
+			fail("want an %s, got %v", schema["type"], value)
+			break
+		}
+		if minimum, ok := schema["minimum"].(float64); ok && number < minimum {
+			fail("%v is below %v", number, minimum)
+		}
+	default:
+		fail("unsupported schema type %v", schema["type"])
+	}
+	return problems
+}
+
+func loadPayloadSchema(t *testing.T, name string) map[string]any {
+	t.Helper()
+	raw, err := schemaFiles.ReadFile("schemas/" + name)
+	if err != nil {
+		t.Fatalf("read schema %s: %v", name, err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		t.Fatalf("decode schema %s: %v", name, err)
+	}
+	return schema
+}
+
+func assertMatchesSchema(t *testing.T, name string, payload []byte) {
+	t.Helper()
+	var value any
+	if err := json.Unmarshal(payload, &value); err != nil {
+		t.Fatalf("decode payload for %s: %v", name, err)
+	}
+	if problems := validateSchema(loadPayloadSchema(t, name), value, "$"); len(problems) > 0 {
+		t.Errorf("payload does not match %s:\n  %s\npayload: %s", name, strings.Join(problems, "\n  "), payload)
+	}
+}
+
+// assertSchemaCoversStruct fails when a JSON field of the payload type is missing from the schema, so a
+// new field cannot be sent without documenting it.
+func assertSchemaCoversStruct(t *testing.T, schema map[string]any, typ reflect.Type, at string) {
+	t.Helper()
+	properties, _ := schema["properties"].(map[string]any)
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		property, ok := properties[name].(map[string]any)
+		if !ok {
+			t.Errorf("%s: %s.%s is sent as %q but missing from the schema", at, typ.Name(), field.Name, name)
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct && property["type"] == "object" {
+			assertSchemaCoversStruct(t, property, field.Type, at+"."+name)
+		}
+	}
+}
+
+func TestPayloadSchemasCoverEveryField(t *testing.T) {
+	for name, typ := range map[string]reflect.Type{
+		"home-assistant-event.json": reflect.TypeFor[homeAssistantEvent](),
+		"notification.json":         reflect.TypeFor[Notification](),
+		"item-hook.json":            reflect.TypeFor[itemHookPayload](),
+	} {
+		assertSchemaCoversStruct(t, loadPayloadSchema(t, name), typ, name)
+	}
+}
+
+func TestOutgoingPayloadsMatchTheirSchemas(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script")
+	}
+	dir := t.TempDir()
+	hookLog := filepath.Join(dir, "hook.log")
+	script := filepath.Join(dir, "hook")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ncat >> "+hookLog+"\necho >> "+hookLog+"\n"), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	var webhookBodies [][]byte
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		webhookBodies = append(webhookBodies, body)
+	}))
+	defer webhook.Close()
+	var notifications [][]byte
+	registerTestNotifier(t, "schema-recorder", NotifierFunc(func(_ context.Context, n Notification) error {
+		payload, err := json.Marshal(n)
+		notifications = append(notifications, payload)
+		return err
+	}))
+
+	app := NewApp()
+	seedProfile(app)
+	if err := app.SetItemHook(script, ""); err != nil {
+		t.Fatalf("set hook: %v", err)
+	}
+	ready := time.Now().Add(-time.Minute)
+	app.mu.Lock()
+	app.haWebhookURL = webhook.URL
+	app.items = append(app.items,
+		Item{ID: 9, Title: "Headphones", Status: "Waiting", Price: "129.99", PriceCents: 12999, HasPriceValue: true, Tags: "Tech, Audio", Link: "https://shop.example.com/hp", PurchaseAllowedAt: ready, CreatedAt: ready.Add(-time.Hour)},
+		Item{ID: 10, Title: "Ring", Status: "Waiting", Private: true, Price: "900", PriceCents: 90000, HasPriceValue: true, PurchaseAllowedAt: ready, CreatedAt: ready.Add(-time.Hour)},
+	)
+	app.mu.Unlock()
+
+	app.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	req := httptest.NewRequest(http.MethodPost, "/items/status", strings.NewReader(url.Values{"item_id": {"9"}, "status": {"Bought"}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	app.Handler().ServeHTTP(httptest.NewRecorder(), req)
+
+	raw, err := os.ReadFile(hookLog)
+	if err != nil {
+		t.Fatalf("read hook log: %v", err)
+	}
+	hookPayloads := strings.Split(strings.TrimSpace(string(raw)), "\n")
+	if len(webhookBodies) != 2 || len(notifications) != 2 || len(hookPayloads) != 3 {
+		t.Fatalf("expected two ready events per channel and a decided hook call, got %d webhook, %d notifier and %d hook payloads", len(webhookBodies), len(notifications), len(hookPayloads))
+	}
+	for _, body := range webhookBodies {
+		assertMatchesSchema(t, "home-assistant-event.json", body)
+	}
+	for _, payload := range notifications {
+		assertMatchesSchema(t, "notification.json", payload)
+	}
+	for _, payload := range hookPayloads {
+		assertMatchesSchema(t, "item-hook.json", []byte(payload))
+	}
+
+	// The validator itself rejects payloads that break the contract.
+	var broken map[string]any
+	if err := json.Unmarshal(webhookBodies[0], &broken); err != nil {
+		t.Fatalf("decode webhook body: %v", err)
+	}
+	delete(broken, "message")
+	broken["event"], broken["item_id"], broken["extra"] = "item_gone", 1.5, true
+	if problems := validateSchema(loadPayloadSchema(t, "home-assistant-event.json"), broken, "$"); len(problems) != 4 {
+		t.Fatalf("expected four problems with a broken payload, got %q", problems)
+	}
+}
+
+func TestSchemasAreServedWithoutAProfile(t *testing.T) {
+	app := NewApp()
+
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/schemas/", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 for the index, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var index struct {
+		Schemas []payloadSchema `json:"schemas"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &index); err != nil {
+		t.Fatalf("decode index: %v", err)
+	}
+	var names []string
+	for _, schema := range index.Schemas {
+		names = append(names, schema.Name)
+		if schema.Title == "" || schema.URL != "/api/schemas/"+schema.Name {
+			t.Fatalf("unexpected index entry %+v", schema)
+		}
+	}
+	if want := []string{"home-assistant-event.json", "item-hook.json", "notification.json"}; !slices.Equal(names, want) {
+		t.Fatalf("expected schemas %v, got %v", want, names)
+	}
+
+	rr = httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/schemas/item-hook.json", nil))
+	if rr.Code != http.StatusOK || rr.Header().Get("Content-Type") != "application/schema+json" || !strings.Contains(rr.Body.String(), `"item.promoted"`) {
+		t.Fatalf("unexpected schema response %d %q: %s", rr.Code, rr.Header().Get("Content-Type"), rr.Body.String())
+	}
+
+	for _, target := range []string{"/api/schemas/unknown.json", "/api/schemas/item-hook"} {
+		rr = httptest.NewRecorder()
+		app.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		if rr.Code != http.StatusNotFound || rr.Header().Get("Content-Type") != problemContentType {
+			t.Fatalf("expected a 404 problem for %s, got %d %q", target, rr.Code, rr.Header().Get("Content-Type"))
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package web
+
+import (
+	"mvpapp/internal/domain"
+)
+
+// lockedItemStore exposes the active profile's items to the domain services. The caller holds a.mu for writing.
+type lockedItemStore struct {
+	a *App
+}
+
+func (s lockedItemStore) Items() []Item {
+	return s.a.items
+}
+
+func (s lockedItemStore) InsertItem(item *Item) error {
+	if err := s.a.insertItemLocked(item); err != nil {
+		return err
+	}
+	s.a.items = append([]Item{*item}, s.a.items...)
+	return nil
+}
+
+func (s lockedItemStore) UpdateItem(item Item) error {
+	if s.a.db == nil {
+		s.a.replaceItemLocked(item)
+		return s.a.updateItemLocked(item)
+	}
+	// Recording a purchase decides an item through a plain update.
+	var event domain.EventType
+	if i := s.a.itemIndexLocked(item.ID); i >= 0 && !s.a.items[i].Status.Decided() && item.Status.Decided() {
+		event = domain.EventItemDecided
+	}
+	return s.a.saveItemWithEventLocked(item, event, s.a.updateItemWithLocked)
+}
+
+func (s lockedItemStore) SaveStatus(item Item) error {
+	if s.a.db == nil {
+		s.a.replaceItemLocked(item)
+		return s.a.updateItemStatusLocked(item.ID, item.Status, item.DecidedAt)
+	}
+	var event domain.EventType
+	if item.Status.Decided() {
+		event = domain.EventItemDecided
+	}
+	return s.a.saveItemWithEventLocked(item, event, func(db sqlExecer, item Item) error {
+		return s.a.updateItemStatusWithLocked(db, item.ID, item.Status, item.DecidedAt)
+	})
+}
+
+func (s lockedItemStore) SavePromoted(item Item) error {
+	if s.a.db == nil {
+		s.a.replaceItemLocked(item)
+		return s.a.updatePromotedItemLocked(item)
+	}
+	return s.a.saveItemWithEventLocked(item, domain.EventItemPromoted, s.a.updatePromotedItemWithLocked)
+}
+
+func (s lockedItemStore) RecordHistory(itemID int, action, detail string) {
+	s.a.recordHistoryLocked(itemID, action, detail)
+}
+
+func (a *App) replaceItemLocked(item Item) {
+	for i := range a.items {
+		if a.items[i].ID == item.ID {
+			a.items[i] = item
+			return
+		}
+	}
+}
+
+// itemServiceLocked returns the item rules bound to the active profile. The caller holds a.mu for writing.
+func (a *App) itemServiceLocked() domain.ItemService {
+	return domain.ItemService{Store: lockedItemStore{a: a}, PurchaseBlocked: a.purchaseBlockedByApprovalLocked, Events: &a.events, Payday: a.payday, Blackouts: a.blackouts, MaxItems: a.itemQuota, Owner: a.currentUserIDLocked()}
+}
+
+// profileStore locks a.mu itself, so profile services must be used without holding it.
+type profileStore struct {
+	a *App
+}
+
+func (s profileStore) ProfileNames() ([]string, error) {
+	return s.a.listProfileNames()
+}
+
+func (s profileStore) DeleteProfile(name string) error {
+	s.a.mu.Lock()
+	defer s.a.mu.Unlock()
+	return s.a.deleteProfileLocked(name)
+}
+
+func (a *App) profileService() domain.ProfileService {
+	return domain.ProfileService{Store: profileStore{a: a}}
+}
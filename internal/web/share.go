@@ -0,0 +1,186 @@
+package web
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// shareExpiryLayout is the date format of the share link expiry input.
+const shareExpiryLayout = "2006-01-02"
+
+func newShareToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate share token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func (a *App) shareSettings(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	action := strings.TrimSpace(r.FormValue("action"))
+	expiresAt, err := parseShareExpiry(r.FormValue("share_expires_on"), time.Now())
+	if err != nil && action != "revoke" {
+		w.WriteHeader(http.StatusBadRequest)
+		a.renderProfile(w, profileViewData{Title: "Profile settings", CurrentPath: "/settings/profile", ProfileError: err.Error()})
+		return
+	}
+
+	a.mu.LockContext(r.Context())
+	token, resetViews := a.shareToken, true
+	var feedback, event string
+	switch action {
+	case "generate":
+		generated, err := newShareToken()
+		if err != nil {
+			a.mu.Unlock()
+			log.Printf("share token error: %v", err)
+			http.Error(w, "could not create share link", http.StatusInternalServerError)
+			return
+		}
+		token = generated
+		// Regenerating keeps a running expiry unless the form sets a new one.
+		if _, set := r.Form["share_expires_on"]; !set && !shareLinkExpired(a.shareExpiresAt, time.Now()) {
+			expiresAt = a.shareExpiresAt
+		}
+		feedback = "share"
+		event = auditShareLinkCreated
+	case "revoke":
+		token, expiresAt = "", time.Time{}
+		feedback = "unshare"
+		event = auditShareLinkRevoked
+	case "expiry":
+		if token == "" {
+			a.mu.Unlock()
+			http.Error(w, "no share link", http.StatusBadRequest)
+			return
+		}
+		resetViews = false
+		feedback = "share-expiry"
+		event = auditShareLinkExpiry
+	default:
+		a.mu.Unlock()
+		http.Error(w, "invalid action", http.StatusBadRequest)
+		return
+	}
+
+	a.shareToken = token
+	a.shareExpiresAt = expiresAt
+	err = a.persistProfileLocked()
+	if err == nil && resetViews {
+		err = a.resetShareViewsLocked()
+	}
+	if err != nil {
+		a.mu.Unlock()
+		log.Printf("db error while saving share token: %v", err)
+		http.Error(w, "could not save share settings", http.StatusInternalServerError)
+		return
+	}
+	a.recordAuditLocked(a.currentUserIDLocked(), event, formatShareExpiry(expiresAt), r)
+	a.mu.Unlock()
+
+	http.Redirect(w, r, "/settings/profile?saved="+feedback, http.StatusSeeOther)
+}
+
+// parseShareExpiry reads the optional last day of a share link. The link stops working when that day
+// ends in the server's timezone.
+func parseShareExpiry(raw string, now time.Time) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	day, err := time.ParseInLocation(shareExpiryLayout, raw, now.Location())
+	if err != nil {
+		return time.Time{}, errors.New("Please enter the expiry date as YYYY-MM-DD.")
+	}
+	expiresAt := day.AddDate(0, 0, 1)
+	if !expiresAt.After(now) {
+		return time.Time{}, errors.New("Please choose an expiry date from today on.")
+	}
+	return expiresAt, nil
+}
+
+// formatShareExpiry returns the last day a share link works, or "" if it never expires.
+func formatShareExpiry(expiresAt time.Time) string {
+	if expiresAt.IsZero() {
+		return ""
+	}
+	return expiresAt.AddDate(0, 0, -1).Format(shareExpiryLayout)
+}
+
+func shareLinkExpired(expiresAt time.Time, now time.Time) bool {
+	return !expiresAt.IsZero() && !now.Before(expiresAt)
+}
+
+// countShareViewLocked records a kiosk view of userID's share link. Counts are kept in their own table,
+// so views do not count as profile changes.
+func (a *App) countShareViewLocked(userID string, now time.Time) error {
+	if a.db == nil {
+		return nil
+	}
+	if _, err := a.db.Exec(`INSERT INTO share_views(user_id, views, viewed_at) VALUES (?, 1, ?)
+ON CONFLICT(user_id) DO UPDATE SET views = views + 1, viewed_at = excluded.viewed_at`, userID, now.Format(time.RFC3339Nano)); err != nil {
+		return fmt.Errorf("count share view: %w", err)
+	}
+	return nil
+}
+
+// resetShareViewsLocked starts the view count of the active profile's share link over, for a new or revoked link.
+func (a *App) resetShareViewsLocked() error {
+	if a.db == nil {
+		return nil
+	}
+	if _, err := a.db.Exec(`DELETE FROM share_views WHERE user_id = ?`, a.currentUserIDLocked()); err != nil {
+		return fmt.Errorf("reset share views: %w", err)
+	}
+	return nil
+}
+
+// shareViewsLocked returns how often the active profile's share link was viewed and when it was last viewed.
+func (a *App) shareViewsLocked() (int, time.Time) {
+	if a.db == nil {
+		return 0, time.Time{}
+	}
+	var views int
+	var viewedAtRaw string
+	if err := a.db.QueryRow(`SELECT views, viewed_at FROM share_views WHERE user_id = ?`, a.currentUserIDLocked()).Scan(&views, &viewedAtRaw); err != nil {
+		return 0, time.Time{}
+	}
+	viewedAt, _ := time.Parse(time.RFC3339Nano, viewedAtRaw)
+	return views, viewedAt
+}
+
+// robots asks crawlers to stay away from the whole app, so share links that end up somewhere public are
+// not followed. Share pages also send noindex headers for crawlers that ignore robots.txt.
+func robots(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = io.WriteString(w, "User-agent: *\nDisallow: /\n")
+}
+
+// setShareHeaders keeps pages opened with a share link out of search indexes and caches, and keeps the
+// token out of the referrer of links on them.
+func setShareHeaders(w http.ResponseWriter) {
+	w.Header().Set("X-Robots-Tag", "noindex, nofollow, noarchive")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("Cache-Control", "no-store")
+}
+
+// shareURLLocked returns the kiosk link for the active profile, or "" when sharing is disabled.
+func (a *App) shareURLLocked() string {
+	if a.shareToken == "" {
+		return ""
+	}
+	return a.dashboardLink() + "kiosk?token=" + url.QueryEscape(a.shareToken)
+}
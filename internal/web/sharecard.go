@@ -0,0 +1,272 @@
+package web
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"mvpapp/internal/domain"
+)
+
+// Share cards use the OpenGraph size that chat apps show as a large preview.
+const (
+	shareCardWidth  = 1200
+	shareCardHeight = 630
+	shareCardMargin = 80
+)
+
+var (
+	shareCardBackground = color.RGBA{0xf8, 0xf9, 0xfa, 0xff}
+	shareCardAccent     = color.RGBA{0x0d, 0x6e, 0xfd, 0xff}
+	shareCardText       = color.RGBA{0x21, 0x25, 0x29, 0xff}
+	shareCardMuted      = color.RGBA{0x6c, 0x75, 0x7d, 0xff}
+	shareCardReady      = color.RGBA{0x19, 0x87, 0x54, 0xff}
+)
+
+// shareCardLine is one line of text on a share card, scaled from the 5×7 card font. Text wraps onto up
+// to Rows rows, one when unset.
+type shareCardLine struct {
+	Text  string
+	Scale int
+	Color color.RGBA
+	Rows  int
+}
+
+// kioskCard serves the preview image of a share link: the profile's list, or a single item with item=ID.
+// Fetching it does not count as a view of the share link, since chat apps fetch it on their own.
+func (a *App) kioskCard(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimSpace(r.URL.Query().Get("token"))
+
+	a.mu.LockContext(r.Context())
+	profileName, err := a.profileNameByShareTokenLocked(token)
+	var items []Item
+	var currency string
+	if err == nil && profileName != "" {
+		items, err = a.itemsForProfileLocked(profileName)
+		if err == nil {
+			currency, err = a.currencyForProfileLocked(profileName)
+		}
+	}
+	a.mu.Unlock()
+	if err != nil {
+		log.Printf("db error while loading share card: %v", err)
+		http.Error(w, "could not load share card", http.StatusInternalServerError)
+		return
+	}
+	if profileName == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	now := time.Now()
+	items = maskPrivateItems(items)
+	lines := listCardLines(profileName, items, now)
+	if rawID := r.URL.Query().Get("item"); rawID != "" {
+		item, ok := findSharedItem(items, rawID)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		lines = itemCardLines(item, currency, now)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, renderShareCard(lines)); err != nil {
+		log.Printf("could not encode share card: %v", err)
+		http.Error(w, "could not render share card", http.StatusInternalServerError)
+		return
+	}
+	setShareHeaders(w)
+	w.Header().Set("Content-Type", "image/png")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// findSharedItem returns the item with the given ID from a share link's items.
+func findSharedItem(items []Item, rawID string) (Item, bool) {
+	id, err := strconv.Atoi(rawID)
+	if err != nil {
+		return Item{}, false
+	}
+	for _, item := range items {
+		if item.ID == id {
+			return item, true
+		}
+	}
+	return Item{}, false
+}
+
+// shareCardURL is the absolute image URL for og:image; itemID 0 is the list card.
+func (a *App) shareCardURL(token string, itemID int) string {
+	target := a.dashboardLink() + "kiosk/card.png?token=" + url.QueryEscape(token)
+	if itemID != 0 {
+		target += "&item=" + strconv.Itoa(itemID)
+	}
+	return target
+}
+
+// listCardLines describes a profile's list: how much is ready, how much waits and what unlocks next.
+func listCardLines(profileName string, items []Item, now time.Time) []shareCardLine {
+	ready, waiting := 0, 0
+	var next *Item
+	for i := range items {
+		switch effectiveStatus(items[i], now) {
+		case domain.StatusReady:
+			ready++
+		case domain.StatusWaiting:
+			waiting++
+			if next == nil || items[i].PurchaseAllowedAt.Before(next.PurchaseAllowedAt) {
+				next = &items[i]
+			}
+		}
+	}
+
+	lines := []shareCardLine{
+		{Text: "Impulse Pause", Scale: 5, Color: shareCardAccent},
+		{Text: profileName + "'s waitlist", Scale: 10, Color: shareCardText, Rows: 2},
+		{Text: fmt.Sprintf("%d ready, %d waiting", ready, waiting), Scale: 7, Color: shareCardReady},
+	}
+	if next != nil {
+		lines = append(lines, shareCardLine{Text: "Next: " + next.Title, Scale: 6, Color: shareCardText})
+		lines = append(lines, shareCardLine{Text: cardCountdown(next.PurchaseAllowedAt, now), Scale: 6, Color: shareCardMuted})
+	}
+	return lines
+}
+
+// itemCardLines describes one item: its title, its price and how long it still waits.
+func itemCardLines(item Item, currency string, now time.Time) []shareCardLine {
+	lines := []shareCardLine{
+		{Text: "Impulse Pause", Scale: 5, Color: shareCardAccent},
+		{Text: item.Title, Scale: 10, Color: shareCardText, Rows: 2},
+	}
+	if item.Price != "" {
+		lines = append(lines, shareCardLine{Text: shareCardPrice(item.Price, currency), Scale: 9, Color: shareCardText})
+	}
+	switch status := effectiveStatus(item, now); status {
+	case domain.StatusReady:
+		lines = append(lines, shareCardLine{Text: "Ready to buy", Scale: 7, Color: shareCardReady})
+	case domain.StatusWaiting:
+		lines = append(lines, shareCardLine{Text: cardCountdown(item.PurchaseAllowedAt, now), Scale: 7, Color: shareCardMuted})
+	default:
+		lines = append(lines, shareCardLine{Text: string(status), Scale: 7, Color: shareCardMuted})
+	}
+	return lines
+}
+
+// shareCardPrice writes the amount with the currency code, since the card font has no currency symbols.
+func shareCardPrice(price, currency string) string {
+	if code := currencyCode(currency); code != "" {
+		return code + " " + price
+	}
+	return price
+}
+
+// cardCountdown is the time left until unlock in days, hours and minutes, two units at most.
+func cardCountdown(unlock, now time.Time) string {
+	left := unlock.Sub(now)
+	if left <= 0 {
+		return "Ready to buy"
+	}
+	days := int(left / (24 * time.Hour))
+	hours := int(left/time.Hour) % 24
+	minutes := int(left/time.Minute) % 60
+	switch {
+	case days > 0:
+		return fmt.Sprintf("Unlocks in %dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("Unlocks in %dh %dm", hours, minutes)
+	case minutes > 0:
+		return fmt.Sprintf("Unlocks in %dm", minutes)
+	}
+	return "Unlocks in under a minute"
+}
+
+// renderShareCard draws lines top to bottom below an accent bar. Text that does not fit its rows is cut
+// with "...", and lines below the bottom margin are left out.
+func renderShareCard(lines []shareCardLine) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, shareCardWidth, shareCardHeight))
+	fillRect(img, img.Bounds(), shareCardBackground)
+	fillRect(img, image.Rect(0, 0, shareCardWidth, 16), shareCardAccent)
+
+	y := shareCardMargin
+	for _, line := range lines {
+		perRow := (shareCardWidth - 2*shareCardMargin) / (6 * line.Scale)
+		for _, row := range wrapCardText(line.Text, perRow, max(line.Rows, 1)) {
+			if y+7*line.Scale > shareCardHeight-shareCardMargin {
+				return img
+			}
+			drawCardText(img, shareCardMargin, y, line.Scale, line.Color, row)
+			y += 10 * line.Scale
+		}
+	}
+	return img
+}
+
+// wrapCardText breaks text at spaces into at most rows rows of perRow characters. Words longer than a row
+// are split, and text left over after the last row is cut with "...".
+func wrapCardText(text string, perRow, rows int) []string {
+	var wrapped []string
+	words := strings.Fields(text)
+	for len(words) > 0 && len(wrapped) < rows {
+		row := []rune(words[0])
+		if len(row) > perRow {
+			words[0] = string(row[perRow:])
+			row = row[:perRow]
+		} else {
+			words = words[1:]
+			for len(words) > 0 && len(row)+1+len([]rune(words[0])) <= perRow {
+				row = append(append(row, ' '), []rune(words[0])...)
+				words = words[1:]
+			}
+		}
+		wrapped = append(wrapped, string(row))
+	}
+	if len(words) > 0 && len(wrapped) > 0 {
+		last := len(wrapped) - 1
+		wrapped[last] = truncateRunes(wrapped[last]+" "+strings.Join(words, " "), perRow)
+	}
+	return wrapped
+}
+
+func fillRect(img *image.RGBA, rect image.Rectangle, c color.RGBA) {
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+// drawCardText draws text with the card font, each font pixel as a scale×scale square.
+func drawCardText(img *image.RGBA, x, y, scale int, c color.RGBA, text string) {
+	for _, r := range text {
+		glyph, ok := cardGlyphs[unicode.ToUpper(r)]
+		if !ok {
+			glyph = cardGlyphs['?']
+		}
+		for row, bits := range glyph {
+			for col, bit := range bits {
+				if bit == '#' {
+					px, py := x+col*scale, y+row*scale
+					fillRect(img, image.Rect(px, py, px+scale, py+scale), c)
+				}
+			}
+		}
+		x += 6 * scale
+	}
+}
+
+func truncateRunes(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:max(limit-3, 0)]) + "..."
+}
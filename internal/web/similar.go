@@ -0,0 +1,45 @@
+package web
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+// maxSimilarItems bounds the past decisions listed next to a ready item.
+const maxSimilarItems = 3
+
+// similarItemsNote sums up how similar items in history were decided, such as
+// "Similar before: Speaker (€ 140.00, bought) · Old earbuds (€ 80.00, skipped)", or returns "" when
+// there were none. It helps deciding a ready item; see domain.SimilarDecisions.
+func similarItemsNote(item Item, history []Item, currency string) string {
+	similar := domain.SimilarDecisions(item, history, maxSimilarItems)
+	if len(similar) == 0 {
+		return ""
+	}
+	parts := make([]string, len(similar))
+	for i, past := range similar {
+		decision := strings.ToLower(string(past.Status))
+		if past.HasPriceValue {
+			decision = formatMoney(past.PriceCents, currency) + ", " + decision
+		}
+		parts[i] = fmt.Sprintf("%s (%s)", past.Title, decision)
+	}
+	return "Similar before: " + strings.Join(parts, " · ")
+}
+
+// similarNotesLocked returns the similar-items note of each ready item among items, by item ID.
+func (a *App) similarNotesLocked(items []Item, now time.Time) map[int]string {
+	notes := map[int]string{}
+	for _, item := range items {
+		if effectiveStatus(item, now) != domain.StatusReady {
+			continue
+		}
+		if note := similarItemsNote(item, a.items, profileCurrencyOrDefault(a.currency)); note != "" {
+			notes[item.ID] = note
+		}
+	}
+	return notes
+}
@@ -0,0 +1,25 @@
+package web_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestReadyItemsShowHowSimilarItemsWereDecided(t *testing.T) {
+	now := time.Now()
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex", HourlyWage: "25"}},
+		Items: []webtest.Item{
+			{Profile: "Alex", Title: "Headphones", Price: 10000, Tags: "audio", PurchaseAllowedAt: now.Add(-time.Hour)},
+			{Profile: "Alex", Title: "Old earbuds", Price: 8000, Tags: "audio", Status: "Skipped", DecidedAt: now.Add(-24 * time.Hour)},
+			{Profile: "Alex", Title: "Amplifier", Price: 40000, Tags: "audio", Status: "Bought", DecidedAt: now.Add(-24 * time.Hour)},
+		},
+	})
+
+	h.As("Alex").Get("/").ExpectStatus(http.StatusOK).
+		ExpectContains("Similar before: Old earbuds (€ 80.00, skipped)").
+		ExpectNotContains("Amplifier (")
+}
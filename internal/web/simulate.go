@@ -0,0 +1,218 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+// waitSimulation explains which wait and rules would apply to a new item, without creating it.
+type waitSimulation struct {
+	WaitPreset      string
+	WaitCustomHours string
+	WaitLabel       string
+	// Source names the rule that chose the wait: a tag default or the profile default.
+	Source            string
+	PurchaseAllowedAt time.Time
+	TagRules          []tagWaitRule
+	NeedsApproval     bool
+	Approver          string
+	ApprovalThreshold domain.Money
+	WorkEffort        string
+}
+
+// tagWaitRule is a tag of the simulated item that has a default wait. Applied marks the longest one, which wins.
+type tagWaitRule struct {
+	Tag     string
+	Preset  string
+	Applied bool
+}
+
+type waitSimulationViewData struct {
+	Title           string
+	CurrentPath     string
+	ContentTemplate string
+	ScriptTemplate  string
+	ActiveProfile   string
+	Currency        string
+	Price           string
+	TagOptions      []string
+	SelectedTags    map[string]bool
+	Result          *waitSimulation
+	Error           string
+}
+
+type apiWaitSimulation struct {
+	WaitPreset        string       `json:"wait_preset"`
+	WaitCustomHours   string       `json:"wait_custom_hours,omitempty"`
+	Source            string       `json:"source"`
+	PurchaseAllowedAt time.Time    `json:"purchase_allowed_at"`
+	TagRules          []apiTagRule `json:"tag_rules"`
+	NeedsApproval     bool         `json:"needs_approval"`
+	Approver          string       `json:"approver,omitempty"`
+	WorkEffort        string       `json:"work_effort,omitempty"`
+}
+
+type apiTagRule struct {
+	Tag     string `json:"tag"`
+	Preset  string `json:"wait_preset"`
+	Applied bool   `json:"applied"`
+}
+
+// waitPresetLabel describes a wait preset in words, for example "7 days".
+func waitPresetLabel(preset, customHours string) string {
+	switch preset {
+	case "24h":
+		return "24 hours"
+	case "7d":
+		return "7 days"
+	case "30d":
+		return "30 days"
+	case domain.WaitPresetPayday:
+		return "until payday"
+	default:
+		return strings.TrimSpace(customHours) + " hours"
+	}
+}
+
+// simulateWaitLocked applies the same wait defaults and approval rule as creating an item would.
+// The price is optional; an unparsable one is reported as a validation error.
+func (a *App) simulateWaitLocked(price, tags string, now time.Time) (*waitSimulation, error) {
+	item := Item{Price: strings.TrimSpace(price), Tags: tags}
+	if item.Price != "" {
+		parsed, ok := a.parsePriceLocked(item.Price)
+		if !ok {
+			return nil, errors.New("invalid price")
+		}
+		item.PriceCents = parsed
+		item.HasPriceValue = true
+	}
+
+	a.applyWaitDefaultsLocked(&item, false)
+	result := &waitSimulation{
+		WaitPreset:      item.WaitPreset,
+		WaitCustomHours: item.WaitCustomHours,
+		WaitLabel:       waitPresetLabel(item.WaitPreset, item.WaitCustomHours),
+		Source:          "profile default",
+	}
+	for _, tag := range strings.Split(tags, ",") {
+		tag = strings.TrimSpace(tag)
+		if preset, ok := lookupTagWaitDefault(a.tagWaitDefaults, tag); ok && tag != "" {
+			rule := tagWaitRule{Tag: tag, Preset: preset}
+			if preset == item.WaitPreset && result.Source == "profile default" {
+				rule.Applied = true
+				result.Source = "tag default for " + tag
+			}
+			result.TagRules = append(result.TagRules, rule)
+		}
+	}
+
+	purchaseAllowedAt, err := domain.ResolvePurchaseAllowedAt(item.WaitPreset, item.WaitCustomHours, "", "", a.payday, now)
+	if err != nil {
+		return nil, err
+	}
+	result.PurchaseAllowedAt = purchaseAllowedAt
+	result.NeedsApproval = requiresApproval(item, a.approvalThreshold, a.approver)
+	if result.NeedsApproval {
+		result.Approver = a.approver
+		result.ApprovalThreshold = a.approvalThreshold
+	}
+	if wage, err := domain.ParseHourlyWage(a.hourlyWage); err == nil && item.HasPriceValue {
+		result.WorkEffort = formatWorkEffort(item, wage, a.workEffortFramingLocked())
+	}
+	return result, nil
+}
+
+func (a *App) waitSimulationPage(w http.ResponseWriter, r *http.Request) {
+	data := waitSimulationViewData{
+		Price:        strings.TrimSpace(r.URL.Query().Get("price")),
+		SelectedTags: selectedTagsMap(parseTagsFromForm(r.URL.Query()["tags"])),
+	}
+
+	a.mu.RLock()
+	data.ActiveProfile = a.currentUserIDLocked()
+	data.Currency = profileCurrencyOrDefault(a.currency)
+	data.TagOptions = availableTagOptions(a.items, a.tagCatalog)
+	if r.URL.Query().Has("price") {
+		result, err := a.simulateWaitLocked(data.Price, parseTagsFromForm(r.URL.Query()["tags"]), time.Now())
+		if err != nil {
+			data.Error = "Please enter a valid price, for example 49.90."
+		}
+		data.Result = result
+	}
+	a.mu.RUnlock()
+
+	if data.Error != "" {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	data.Title = "Wait rule check"
+	data.CurrentPath = "/settings/wait-check"
+	data.ContentTemplate = "wait_check_content"
+	renderTemplate(w, a.templates, "layout", data)
+}
+
+// apiSimulateWait answers GET /api/v1/wait-simulation?price=…&tags=A,B.
+func (a *App) apiSimulateWait(w http.ResponseWriter, r *http.Request) {
+	if !a.requireAPIProfile(w, r) {
+		return
+	}
+
+	a.mu.RLock()
+	result, err := a.simulateWaitLocked(r.URL.Query().Get("price"), parseTagsFromForm(strings.Split(r.URL.Query().Get("tags"), ",")), time.Now())
+	a.mu.RUnlock()
+	if err != nil {
+		writeAPIError(w, http.StatusUnprocessableEntity, "invalid price")
+		return
+	}
+
+	out := apiWaitSimulation{
+		WaitPreset:        result.WaitPreset,
+		WaitCustomHours:   result.WaitCustomHours,
+		Source:            result.Source,
+		PurchaseAllowedAt: result.PurchaseAllowedAt.UTC(),
+		TagRules:          []apiTagRule{},
+		NeedsApproval:     result.NeedsApproval,
+		Approver:          result.Approver,
+		WorkEffort:        result.WorkEffort,
+	}
+	for _, rule := range result.TagRules {
+		out.TagRules = append(out.TagRules, apiTagRule{Tag: rule.Tag, Preset: rule.Preset, Applied: rule.Applied})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+type apiWaitPreview struct {
+	PurchaseAllowedAt time.Time `json:"purchase_allowed_at"`
+	Label             string    `json:"label"`
+}
+
+// apiPreviewWait answers GET /api/v1/wait-preview?text=…&timezone_offset_minutes=…, resolving a free-text
+// wait such as "next Friday 18:00" the way saving the item would. The add form uses it to show the date.
+func (a *App) apiPreviewWait(w http.ResponseWriter, r *http.Request) {
+	if !a.requireAPIProfile(w, r) {
+		return
+	}
+
+	a.mu.RLock()
+	payday := a.payday
+	a.mu.RUnlock()
+
+	query := r.URL.Query()
+	resolved, err := domain.ResolvePurchaseAllowedAt(domain.WaitPresetText, "", query.Get("text"), query.Get("timezone_offset_minutes"), payday, time.Now())
+	if err != nil {
+		var invalid *domain.ValidationError
+		if errors.As(err, &invalid) {
+			writeJSON(w, http.StatusUnprocessableEntity, validationErrorBody(invalid))
+			return
+		}
+		writeAPIError(w, http.StatusUnprocessableEntity, "invalid wait")
+		return
+	}
+	writeJSON(w, http.StatusOK, apiWaitPreview{
+		PurchaseAllowedAt: resolved.UTC(),
+		Label:             "Buy after " + resolved.Format("Mon, 2 Jan 2006 15:04"),
+	})
+}
@@ -0,0 +1,56 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestWaitRuleCheckShowsTagDefaultAndApproval(t *testing.T) {
+	app, cleanup := newSQLiteTestApp(t)
+	defer cleanup()
+
+	seedSharingProfiles(t, app)
+	if rr := postSharingForm(app, "Alex", "/settings/tags", url.Values{"action": {"wait"}, "tag": {"Gaming"}, "wait_preset": {"30d"}}); rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected tag default redirect, got %d", rr.Code)
+	}
+	if rr := postSharingForm(app, "Alex", "/settings/approvals", url.Values{"approval_threshold": {"500"}, "approver": {"Bea"}}); rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected approval rule redirect, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.AddCookie(&http.Cookie{Name: "active_profile", Value: "Alex"})
+		rr := httptest.NewRecorder()
+		app.Handler().ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get("/settings/wait-check?price=900&tags=Gaming&tags=Office")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected wait check page, got %d", rr.Code)
+	}
+	for _, want := range []string{"30 days", "tag default for Gaming", "Needs approval by Bea"} {
+		if !strings.Contains(rr.Body.String(), want) {
+			t.Fatalf("expected %q in wait check result", want)
+		}
+	}
+	if rr := get("/settings/wait-check?price=abc"); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected invalid price to be rejected, got %d", rr.Code)
+	}
+
+	rr = get("/api/v1/wait-simulation?price=20&tags=Office")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected simulation response, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var simulation apiWaitSimulation
+	if err := json.Unmarshal(rr.Body.Bytes(), &simulation); err != nil {
+		t.Fatalf("decode simulation: %v", err)
+	}
+	if simulation.Source != "profile default" || simulation.NeedsApproval || len(simulation.TagRules) != 0 {
+		t.Fatalf("expected the profile default without approval, got %+v", simulation)
+	}
+}
@@ -0,0 +1,117 @@
+package web
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// maxLoggedStatement caps the statement text in the slow query log.
+const maxLoggedStatement = 500
+
+// SetSlowQueryThreshold logs every database statement that takes longer than threshold, with the route
+// that issued it. Zero or less turns the log off. Apps without a database have nothing to log.
+func (a *App) SetSlowQueryThreshold(threshold time.Duration) {
+	if threshold < 0 {
+		threshold = 0
+	}
+	a.mu.scope.setSlowQueryThreshold(threshold)
+}
+
+// SetRequestSLO logs every request that takes longer than slo, with its route, status and the time it
+// spent in the database. Zero or less turns the log off.
+func (a *App) SetRequestSLO(slo time.Duration) {
+	if slo < 0 {
+		slo = 0
+	}
+	a.mu.Lock()
+	a.requestSLO = slo
+	a.mu.Unlock()
+}
+
+// requestStats follows one request through the database driver, so slow queries can name their route
+// and slow requests can tell database time from the rest.
+type requestStats struct {
+	route      string
+	queries    atomic.Int64
+	queryNanos atomic.Int64
+}
+
+type requestStatsKey struct{}
+
+func withRequestStats(ctx context.Context, stats *requestStats) context.Context {
+	return context.WithValue(ctx, requestStatsKey{}, stats)
+}
+
+func requestStatsFrom(ctx context.Context) *requestStats {
+	stats, _ := ctx.Value(requestStatsKey{}).(*requestStats)
+	return stats
+}
+
+// statusRecorder remembers the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// loggingMiddleware logs every request, and requests slower than the request SLO once more with their
+// route, status and database time.
+func (a *App) loggingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log.Printf("%s %s", r.Method, r.URL.Path)
+
+		_, route := a.mux.Handler(r)
+		if route == "" {
+			route = r.Method + " (unmatched)"
+		}
+		stats := &requestStats{route: route}
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		next.ServeHTTP(recorder, r.WithContext(withRequestStats(r.Context(), stats)))
+		elapsed := time.Since(start)
+
+		a.mu.RLock()
+		slo := a.requestSLO
+		a.mu.RUnlock()
+		if slo > 0 && elapsed > slo {
+			log.Printf("slow request: %s %s took %s (slo %s) route=%q status=%d queries=%d query_time=%s",
+				r.Method, r.URL.Path, elapsed.Round(time.Millisecond), slo, route, recorder.status,
+				stats.queries.Load(), time.Duration(stats.queryNanos.Load()).Round(time.Millisecond))
+		}
+	})
+}
+
+// observeStatement adds a finished statement to the request that ran it and logs it when it exceeded
+// the slow query threshold. For queries, the time covers execution up to the first row.
+func observeStatement(ctx context.Context, threshold time.Duration, operation, query string, args int, elapsed time.Duration) {
+	stats := requestStatsFrom(ctx)
+	if stats != nil {
+		stats.queries.Add(1)
+		stats.queryNanos.Add(int64(elapsed))
+	}
+	if threshold <= 0 || elapsed <= threshold {
+		return
+	}
+	route := "(no request)"
+	if stats != nil {
+		route = stats.route
+	}
+	text := strings.Join(strings.Fields(query), " ")
+	if len(text) > maxLoggedStatement {
+		text = text[:maxLoggedStatement] + "…"
+	}
+	log.Printf("slow query: %s took %s (threshold %s) route=%q args=%d statement=%q",
+		operation, elapsed.Round(time.Millisecond), threshold, route, args, text)
+}
@@ -0,0 +1,67 @@
+package web_test
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"mvpapp/internal/web/webtest"
+)
+
+// lockedBuffer collects log output written by handlers and background jobs alike.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func captureLog(t *testing.T) *lockedBuffer {
+	t.Helper()
+	out := &lockedBuffer{}
+	log.SetOutput(out)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	return out
+}
+
+func TestSlowQueriesAndRequestsAreLoggedWithTheirRoute(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{Profiles: []webtest.Profile{{Name: "Alex"}}})
+	alex := h.As("Alex")
+	alex.Get("/").ExpectStatus(http.StatusOK)
+	out := captureLog(t)
+
+	h.App.SetSlowQueryThreshold(time.Nanosecond)
+	h.App.SetRequestSLO(time.Nanosecond)
+	alex.PostJSON("/api/v1/items", map[string]any{"title": "Desk lamp"}).ExpectStatus(http.StatusCreated)
+
+	logged := out.String()
+	if !strings.Contains(logged, `slow query: exec took`) || !strings.Contains(logged, `route="POST /api/v1/items" args=`) || !strings.Contains(logged, `statement="INSERT INTO items`) {
+		t.Fatalf("expected the item insert in the slow query log, got:\n%s", logged)
+	}
+	if !strings.Contains(logged, `slow request: POST /api/v1/items took`) || !strings.Contains(logged, `status=201 queries=`) {
+		t.Fatalf("expected the request in the slow request log, got:\n%s", logged)
+	}
+
+	h.App.SetSlowQueryThreshold(0)
+	h.App.SetRequestSLO(0)
+	before := len(out.String())
+	alex.PostJSON("/api/v1/items", map[string]any{"title": "Headphones"}).ExpectStatus(http.StatusCreated)
+	if strings.Contains(out.String()[before:], "slow ") {
+		t.Fatalf("expected no slow log once disabled, got:\n%s", out.String()[before:])
+	}
+}
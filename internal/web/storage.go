@@ -4,35 +4,87 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	_ "modernc.org/sqlite"
+
+	"mvpapp/internal/domain"
 )
 
 const defaultUserID = "local-default"
 
-func openSQLite(dbPath string) (*sql.DB, error) {
+// itemAccessCondition matches items owned by or shared with a profile. It takes the profile name twice.
+const itemAccessCondition = `(user_id = ? OR id IN (SELECT item_id FROM item_shares WHERE user_id = ?))`
+
+// SQLiteOptions tunes the database connections. In WAL mode readers no longer wait for a write in
+// progress, and the busy timeout lets a connection wait for the write lock instead of failing with
+// "database is locked".
+type SQLiteOptions struct {
+	// JournalMode is wal, delete, truncate or persist.
+	JournalMode string
+	BusyTimeout time.Duration
+	// MaxOpenConns and MaxIdleConns size the connection pool. SQLite allows a single writer, so more
+	// connections only help concurrent reads.
+	MaxOpenConns int
+	MaxIdleConns int
+}
+
+var sqliteJournalModes = []string{"wal", "delete", "truncate", "persist"}
+
+// DefaultSQLiteOptions returns the settings NewAppWithSQLite uses.
+func DefaultSQLiteOptions() SQLiteOptions {
+	return SQLiteOptions{JournalMode: "wal", BusyTimeout: 5 * time.Second, MaxOpenConns: 4, MaxIdleConns: 4}
+}
+
+func (o SQLiteOptions) validate() error {
+	if !slices.Contains(sqliteJournalModes, o.JournalMode) {
+		return fmt.Errorf("journal mode must be one of %s", strings.Join(sqliteJournalModes, ", "))
+	}
+	if o.BusyTimeout < 0 {
+		return errors.New("busy timeout must not be negative")
+	}
+	if o.MaxOpenConns < 1 {
+		return errors.New("max open connections must be at least 1")
+	}
+	if o.MaxIdleConns < 0 || o.MaxIdleConns > o.MaxOpenConns {
+		return errors.New("max idle connections must be between 0 and max open connections")
+	}
+	return nil
+}
+
+// dsn applies the pragmas to every connection of the pool. Transactions take the write lock when they
+// begin, so two of them cannot deadlock upgrading from a read lock.
+func (o SQLiteOptions) dsn(dbPath string) string {
+	query := url.Values{"_pragma": {
+		"foreign_keys(1)",
+		"busy_timeout(" + strconv.FormatInt(o.BusyTimeout.Milliseconds(), 10) + ")",
+		"journal_mode(" + o.JournalMode + ")",
+	}, "_txlock": {"immediate"}}
+	return dbPath + "?" + query.Encode()
+}
+
+func openSQLite(dbPath string, opts SQLiteOptions, scope *traceScope) (*sql.DB, error) {
 	if dbPath == "" {
 		return nil, errors.New("db path is required")
 	}
+	if err := opts.validate(); err != nil {
+		return nil, fmt.Errorf("invalid sqlite options: %w", err)
+	}
 
 	dir := filepath.Dir(dbPath)
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return nil, fmt.Errorf("create db dir: %w", err)
 	}
 
-	db, err := sql.Open("sqlite", dbPath)
-	if err != nil {
-		return nil, fmt.Errorf("open sqlite: %w", err)
-	}
-
-	if _, err := db.Exec(`PRAGMA foreign_keys = ON;`); err != nil {
-		_ = db.Close()
-		return nil, fmt.Errorf("enable foreign keys: %w", err)
-	}
+	db := openTracedSQLite(opts.dsn(dbPath), scope)
+	db.SetMaxOpenConns(opts.MaxOpenConns)
+	db.SetMaxIdleConns(opts.MaxIdleConns)
 
 	if err := initSchema(db); err != nil {
 		_ = db.Close()
@@ -52,12 +104,62 @@ func initSchema(db *sql.DB) error {
 CREATE TABLE IF NOT EXISTS profiles (
 	user_id TEXT PRIMARY KEY,
 	hourly_wage TEXT NOT NULL,
-	currency TEXT NOT NULL DEFAULT '€',
+	currency TEXT NOT NULL DEFAULT 'EUR',
 	default_wait_preset TEXT NOT NULL DEFAULT '24h',
 	default_wait_custom_hours TEXT NOT NULL DEFAULT '',
 	ntfy_endpoint TEXT NOT NULL DEFAULT '',
 	ntfy_topic TEXT NOT NULL DEFAULT '',
 	tag_catalog TEXT NOT NULL DEFAULT '',
+	share_token TEXT NOT NULL DEFAULT '',
+	retention_months INTEGER NOT NULL DEFAULT 0,
+	firefly_url TEXT NOT NULL DEFAULT '',
+	firefly_token TEXT NOT NULL DEFAULT '',
+	firefly_account TEXT NOT NULL DEFAULT '',
+	-- approval_threshold holds pre-cents rules until migratePriceCents moves them to approval_threshold_cents.
+	approval_threshold REAL NOT NULL DEFAULT 0,
+	approval_threshold_cents INTEGER NOT NULL DEFAULT 0,
+	approver TEXT NOT NULL DEFAULT '',
+	tag_wait_defaults TEXT NOT NULL DEFAULT '',
+	trend_timezone TEXT NOT NULL DEFAULT '',
+	week_start TEXT NOT NULL DEFAULT 'monday',
+	month_start_day INTEGER NOT NULL DEFAULT 1,
+	-- onboarding_step is the next unfinished setup step of a new profile; empty once setup is done.
+	onboarding_step TEXT NOT NULL DEFAULT '',
+	metrics_opt_in INTEGER NOT NULL DEFAULT 0,
+	ha_webhook_url TEXT NOT NULL DEFAULT '',
+	work_hours_mode TEXT NOT NULL DEFAULT 'hours',
+	shift_hours TEXT NOT NULL DEFAULT '',
+	monthly_income TEXT NOT NULL DEFAULT '',
+	weekly_hours TEXT NOT NULL DEFAULT '',
+	projection_rate TEXT NOT NULL DEFAULT '',
+	projection_years INTEGER NOT NULL DEFAULT 0,
+	renotify_policy TEXT NOT NULL DEFAULT 'always',
+	renotify_days INTEGER NOT NULL DEFAULT 0,
+	number_format TEXT NOT NULL DEFAULT 'point',
+	payday INTEGER NOT NULL DEFAULT 0,
+	blackouts TEXT NOT NULL DEFAULT '',
+	avatar_emoji TEXT NOT NULL DEFAULT '',
+	avatar_color TEXT NOT NULL DEFAULT '',
+	hours_goal INTEGER NOT NULL DEFAULT 0,
+	hours_goal_celebrated TEXT NOT NULL DEFAULT '',
+	work_hours_precision TEXT NOT NULL DEFAULT '',
+	work_hours_rounding TEXT NOT NULL DEFAULT 'nearest',
+	note_key_salt TEXT NOT NULL DEFAULT '',
+	note_key_check TEXT NOT NULL DEFAULT '',
+	-- share_views and share_viewed_at are no longer written; view counts live in the share_views table.
+	share_expires_at TEXT NOT NULL DEFAULT '',
+	share_views INTEGER NOT NULL DEFAULT 0,
+	share_viewed_at TEXT NOT NULL DEFAULT '',
+	-- last_visited is the page the landing_page 'last-visited' returns to.
+	landing_page TEXT NOT NULL DEFAULT 'dashboard',
+	last_visited TEXT NOT NULL DEFAULT '',
+	-- dashboard_filters is the query string of the last dashboard filters, applied when / is opened bare.
+	dashboard_filters TEXT NOT NULL DEFAULT '',
+	archived_at TEXT NOT NULL DEFAULT '',
+	-- notify_dry_run logs the profile's notifications instead of sending them.
+	notify_dry_run INTEGER NOT NULL DEFAULT 0,
+	-- routing_rules holds one notification routing rule per line; see domain.FormatRoutingRules.
+	routing_rules TEXT NOT NULL DEFAULT '',
 	updated_at TEXT NOT NULL
 );
 
@@ -66,7 +168,9 @@ CREATE TABLE IF NOT EXISTS items (
 	user_id TEXT NOT NULL,
 	title TEXT NOT NULL,
 	price TEXT NOT NULL DEFAULT '',
+	-- price_value holds pre-cents prices until migratePriceCents moves them to price_cents.
 	price_value REAL,
+	price_cents INTEGER NOT NULL DEFAULT 0,
 	has_price_value INTEGER NOT NULL DEFAULT 0,
 	link TEXT NOT NULL DEFAULT '',
 	note TEXT NOT NULL DEFAULT '',
@@ -76,10 +180,244 @@ CREATE TABLE IF NOT EXISTS items (
 	wait_custom_hours TEXT NOT NULL DEFAULT '',
 	purchase_allowed_at TEXT NOT NULL,
 	created_at TEXT NOT NULL,
-	ntfy_attempted INTEGER NOT NULL DEFAULT 0
+	decided_at TEXT NOT NULL DEFAULT '',
+	ntfy_attempted INTEGER NOT NULL DEFAULT 0,
+	firefly_pushed INTEGER NOT NULL DEFAULT 0,
+	approval_state TEXT NOT NULL DEFAULT '',
+	urge_score INTEGER NOT NULL DEFAULT 0,
+	satisfaction TEXT NOT NULL DEFAULT '',
+	-- notified_at is when the item was last announced as ready, for the re-notification policy.
+	notified_at TEXT NOT NULL DEFAULT '',
+	-- receipt_name is the file name of the receipt in item_receipts, empty without one.
+	receipt_name TEXT NOT NULL DEFAULT '',
+	private INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS item_shares (
+	item_id INTEGER NOT NULL,
+	user_id TEXT NOT NULL,
+	-- split_percent is the share of the price this profile carries; the owner carries the rest.
+	split_percent INTEGER NOT NULL DEFAULT 0,
+	created_at TEXT NOT NULL,
+	PRIMARY KEY (item_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS item_history (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	item_id INTEGER NOT NULL,
+	user_id TEXT NOT NULL,
+	action TEXT NOT NULL,
+	detail TEXT NOT NULL DEFAULT '',
+	created_at TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS audit_log (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id TEXT NOT NULL,
+	event TEXT NOT NULL,
+	detail TEXT NOT NULL DEFAULT '',
+	remote_addr TEXT NOT NULL DEFAULT '',
+	created_at TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS item_templates (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id TEXT NOT NULL,
+	name TEXT NOT NULL,
+	title TEXT NOT NULL DEFAULT '',
+	price TEXT NOT NULL DEFAULT '',
+	tags TEXT NOT NULL DEFAULT '',
+	wait_preset TEXT NOT NULL DEFAULT '24h',
+	wait_custom_hours TEXT NOT NULL DEFAULT '',
+	created_at TEXT NOT NULL
+);
+
+-- api_idempotency_keys keeps the response of an API request so a retry with the same Idempotency-Key replays it.
+CREATE TABLE IF NOT EXISTS api_idempotency_keys (
+	user_id TEXT NOT NULL,
+	idempotency_key TEXT NOT NULL,
+	request_hash TEXT NOT NULL,
+	status INTEGER NOT NULL,
+	body TEXT NOT NULL,
+	created_at TEXT NOT NULL,
+	PRIMARY KEY (user_id, idempotency_key)
+);
+
+-- push_subscriptions are the web push devices of a profile. expires_at is empty when the browser set no expiry.
+CREATE TABLE IF NOT EXISTS push_subscriptions (
+	endpoint TEXT PRIMARY KEY,
+	user_id TEXT NOT NULL,
+	p256dh TEXT NOT NULL,
+	auth TEXT NOT NULL,
+	expires_at TEXT NOT NULL DEFAULT '',
+	created_at TEXT NOT NULL
+);
+
+-- outbox holds the external side effects of item events, such as notifications, until they are
+-- delivered. Rows are written in the transaction of the item change and deleted once delivered.
+CREATE TABLE IF NOT EXISTS outbox (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id TEXT NOT NULL,
+	effect TEXT NOT NULL,
+	event TEXT NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT NOT NULL DEFAULT '',
+	next_attempt_at TEXT NOT NULL,
+	created_at TEXT NOT NULL
+);
+
+-- notification_log records every attempt to send a notification, for the log in settings. item_title is
+-- kept so the log still reads after the item is gone.
+CREATE TABLE IF NOT EXISTS notification_log (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id TEXT NOT NULL,
+	notification TEXT NOT NULL,
+	channel TEXT NOT NULL,
+	item_id INTEGER NOT NULL DEFAULT 0,
+	item_title TEXT NOT NULL DEFAULT '',
+	attempted_at TEXT NOT NULL,
+	status_code INTEGER NOT NULL DEFAULT 0,
+	error TEXT NOT NULL DEFAULT '',
+	dry_run INTEGER NOT NULL DEFAULT 0
+);
+
+-- followed_shares are the share links of other profiles a profile follows on its Following tab. Only
+-- the token is kept; it is resolved on every view, so revoking the link ends the follow.
+CREATE TABLE IF NOT EXISTS followed_shares (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id TEXT NOT NULL,
+	token TEXT NOT NULL,
+	added_at TEXT NOT NULL,
+	UNIQUE(user_id, token)
+);
+
+-- leaderboard_members are the profiles that opted in to the monthly household leaderboard. digest asks
+-- for last month's leaderboard over ntfy on the first of each month.
+CREATE TABLE IF NOT EXISTS leaderboard_members (
+	user_id TEXT PRIMARY KEY,
+	digest INTEGER NOT NULL DEFAULT 0,
+	joined_at TEXT NOT NULL
+);
+
+-- share_views counts kiosk views of each profile's current share link. It is kept out of profiles, so a
+-- kiosk refreshing every minute does not bump profile_revision and make every instance reload the profile.
+CREATE TABLE IF NOT EXISTS share_views (
+	user_id TEXT PRIMARY KEY,
+	views INTEGER NOT NULL DEFAULT 0,
+	viewed_at TEXT NOT NULL DEFAULT ''
+);
+
+-- job_runs keeps the last run of each background job, so /household shows it across restarts.
+CREATE TABLE IF NOT EXISTS job_runs (
+	name TEXT PRIMARY KEY,
+	last_started_at TEXT NOT NULL DEFAULT '',
+	last_finished_at TEXT NOT NULL DEFAULT '',
+	last_error TEXT NOT NULL DEFAULT '',
+	runs INTEGER NOT NULL DEFAULT 0
+);
+
+-- invites let a new profile be created when /switch-profile only accepts existing names. profile_name is
+-- empty when the invitee picks the name; used_by is empty until the invite is accepted.
+CREATE TABLE IF NOT EXISTS invites (
+	token TEXT PRIMARY KEY,
+	profile_name TEXT NOT NULL DEFAULT '',
+	created_at TEXT NOT NULL,
+	expires_at TEXT NOT NULL,
+	used_by TEXT NOT NULL DEFAULT '',
+	used_at TEXT NOT NULL DEFAULT ''
+);
+
+-- item_receipts holds the uploaded receipt of a bought item, at most one per item. The file lives in
+-- the database so backups of the SQLite file include it.
+CREATE TABLE IF NOT EXISTS item_receipts (
+	item_id INTEGER PRIMARY KEY,
+	content_type TEXT NOT NULL,
+	data BLOB NOT NULL,
+	uploaded_at TEXT NOT NULL
+);
+
+-- item_changes is the change log behind GET /api/v1/changes. Triggers record every write to items and
+-- item_shares, so no code path can forget to. user_id is the owner, or the profile a share was added
+-- for or removed from.
+CREATE TABLE IF NOT EXISTS item_changes (
+	seq INTEGER PRIMARY KEY AUTOINCREMENT,
+	item_id INTEGER NOT NULL,
+	user_id TEXT NOT NULL
+);
+
+-- quarantined_rows keeps rows that were taken out at startup because they could not be loaded, such as
+-- items with unparsable timestamps or shares of items that no longer exist. data is the row as JSON.
+CREATE TABLE IF NOT EXISTS quarantined_rows (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	source_table TEXT NOT NULL,
+	row_id INTEGER NOT NULL,
+	reason TEXT NOT NULL,
+	data TEXT NOT NULL,
+	quarantined_at TEXT NOT NULL
+);
+
+-- ready_digest collects ready items a routing rule sent to the weekly digest, until it is sent.
+CREATE TABLE IF NOT EXISTS ready_digest (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id TEXT NOT NULL,
+	item_id INTEGER NOT NULL,
+	title TEXT NOT NULL,
+	ready_at TEXT NOT NULL
+);
+
+-- profile_revision counts writes to profiles and item_templates. Together with the item change log it
+-- tells an instance whether the profile it has loaded may have been changed through another instance.
+CREATE TABLE IF NOT EXISTS profile_revision (
+	id INTEGER PRIMARY KEY CHECK (id = 1),
+	revision INTEGER NOT NULL
 );
+INSERT OR IGNORE INTO profile_revision(id, revision) VALUES (1, 0);
+
+CREATE TRIGGER IF NOT EXISTS profile_revision_insert AFTER INSERT ON profiles BEGIN
+	UPDATE profile_revision SET revision = revision + 1 WHERE id = 1;
+END;
+CREATE TRIGGER IF NOT EXISTS profile_revision_update AFTER UPDATE ON profiles BEGIN
+	UPDATE profile_revision SET revision = revision + 1 WHERE id = 1;
+END;
+CREATE TRIGGER IF NOT EXISTS profile_revision_delete AFTER DELETE ON profiles BEGIN
+	UPDATE profile_revision SET revision = revision + 1 WHERE id = 1;
+END;
+CREATE TRIGGER IF NOT EXISTS profile_revision_template_insert AFTER INSERT ON item_templates BEGIN
+	UPDATE profile_revision SET revision = revision + 1 WHERE id = 1;
+END;
+CREATE TRIGGER IF NOT EXISTS profile_revision_template_delete AFTER DELETE ON item_templates BEGIN
+	UPDATE profile_revision SET revision = revision + 1 WHERE id = 1;
+END;
+CREATE TRIGGER IF NOT EXISTS profile_revision_template_update AFTER UPDATE ON item_templates BEGIN
+	UPDATE profile_revision SET revision = revision + 1 WHERE id = 1;
+END;
+
+CREATE TRIGGER IF NOT EXISTS item_changes_insert AFTER INSERT ON items BEGIN
+	INSERT INTO item_changes(item_id, user_id) VALUES (NEW.id, NEW.user_id);
+END;
+CREATE TRIGGER IF NOT EXISTS item_changes_update AFTER UPDATE ON items BEGIN
+	INSERT INTO item_changes(item_id, user_id) VALUES (NEW.id, NEW.user_id);
+END;
+CREATE TRIGGER IF NOT EXISTS item_changes_delete AFTER DELETE ON items BEGIN
+	INSERT INTO item_changes(item_id, user_id) VALUES (OLD.id, OLD.user_id);
+END;
+CREATE TRIGGER IF NOT EXISTS item_changes_share AFTER INSERT ON item_shares BEGIN
+	INSERT INTO item_changes(item_id, user_id) VALUES (NEW.item_id, NEW.user_id);
+END;
+CREATE TRIGGER IF NOT EXISTS item_changes_unshare AFTER DELETE ON item_shares BEGIN
+	INSERT INTO item_changes(item_id, user_id) VALUES (OLD.item_id, OLD.user_id);
+END;
+CREATE TRIGGER IF NOT EXISTS item_changes_split AFTER UPDATE ON item_shares BEGIN
+	INSERT INTO item_changes(item_id, user_id) SELECT id, user_id FROM items WHERE id = NEW.item_id;
+END;
 
 CREATE INDEX IF NOT EXISTS idx_items_user_id ON items(user_id);
+CREATE INDEX IF NOT EXISTS idx_item_templates_user_id ON item_templates(user_id);
+CREATE INDEX IF NOT EXISTS idx_outbox_next_attempt ON outbox(next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_item_shares_user_id ON item_shares(user_id);
+CREATE INDEX IF NOT EXISTS idx_item_history_item_id ON item_history(item_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_user_id ON audit_log(user_id);
+CREATE INDEX IF NOT EXISTS idx_notification_log_user_id ON notification_log(user_id);
 CREATE INDEX IF NOT EXISTS idx_items_status_allowed ON items(status, purchase_allowed_at);
 `)
 	if err != nil {
@@ -89,7 +427,7 @@ CREATE INDEX IF NOT EXISTS idx_items_status_allowed ON items(status, purchase_al
 	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN default_wait_preset TEXT NOT NULL DEFAULT '24h'`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
 		return fmt.Errorf("migrate profiles.default_wait_preset: %w", err)
 	}
-	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN currency TEXT NOT NULL DEFAULT '€'`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN currency TEXT NOT NULL DEFAULT 'EUR'`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
 		return fmt.Errorf("migrate profiles.currency: %w", err)
 	}
 	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN default_wait_custom_hours TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
@@ -98,12 +436,237 @@ CREATE INDEX IF NOT EXISTS idx_items_status_allowed ON items(status, purchase_al
 	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN tag_catalog TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
 		return fmt.Errorf("migrate profiles.tag_catalog: %w", err)
 	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN share_token TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.share_token: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN retention_months INTEGER NOT NULL DEFAULT 0`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.retention_months: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE items ADD COLUMN decided_at TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate items.decided_at: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN firefly_url TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.firefly_url: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN firefly_token TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.firefly_token: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN firefly_account TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.firefly_account: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE items ADD COLUMN firefly_pushed INTEGER NOT NULL DEFAULT 0`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate items.firefly_pushed: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN approval_threshold REAL NOT NULL DEFAULT 0`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.approval_threshold: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN approver TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.approver: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE items ADD COLUMN approval_state TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate items.approval_state: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN tag_wait_defaults TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.tag_wait_defaults: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE items ADD COLUMN urge_score INTEGER NOT NULL DEFAULT 0`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate items.urge_score: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE items ADD COLUMN satisfaction TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate items.satisfaction: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE items ADD COLUMN notified_at TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate items.notified_at: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE items ADD COLUMN receipt_name TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate items.receipt_name: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE items ADD COLUMN private INTEGER NOT NULL DEFAULT 0`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate items.private: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN trend_timezone TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.trend_timezone: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN week_start TEXT NOT NULL DEFAULT 'monday'`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.week_start: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN month_start_day INTEGER NOT NULL DEFAULT 1`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.month_start_day: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN onboarding_step TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.onboarding_step: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN metrics_opt_in INTEGER NOT NULL DEFAULT 0`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.metrics_opt_in: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN ha_webhook_url TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.ha_webhook_url: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN work_hours_mode TEXT NOT NULL DEFAULT 'hours'`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.work_hours_mode: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN shift_hours TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.shift_hours: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN monthly_income TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.monthly_income: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN weekly_hours TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.weekly_hours: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN projection_rate TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.projection_rate: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN projection_years INTEGER NOT NULL DEFAULT 0`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.projection_years: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN renotify_policy TEXT NOT NULL DEFAULT 'always'`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.renotify_policy: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN renotify_days INTEGER NOT NULL DEFAULT 0`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.renotify_days: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN number_format TEXT NOT NULL DEFAULT 'point'`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.number_format: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN payday INTEGER NOT NULL DEFAULT 0`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.payday: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN blackouts TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.blackouts: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN avatar_emoji TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.avatar_emoji: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN avatar_color TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.avatar_color: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN hours_goal INTEGER NOT NULL DEFAULT 0`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.hours_goal: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN hours_goal_celebrated TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.hours_goal_celebrated: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN work_hours_precision TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.work_hours_precision: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN work_hours_rounding TEXT NOT NULL DEFAULT 'nearest'`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.work_hours_rounding: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN note_key_salt TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.note_key_salt: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN note_key_check TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.note_key_check: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN share_expires_at TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.share_expires_at: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN share_views INTEGER NOT NULL DEFAULT 0`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.share_views: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN share_viewed_at TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.share_viewed_at: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN landing_page TEXT NOT NULL DEFAULT 'dashboard'`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.landing_page: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN last_visited TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.last_visited: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN dashboard_filters TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.dashboard_filters: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN archived_at TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.archived_at: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN notify_dry_run INTEGER NOT NULL DEFAULT 0`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.notify_dry_run: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN routing_rules TEXT NOT NULL DEFAULT ''`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.routing_rules: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE items ADD COLUMN price_cents INTEGER NOT NULL DEFAULT 0`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate items.price_cents: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE item_shares ADD COLUMN split_percent INTEGER NOT NULL DEFAULT 0`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate item_shares.split_percent: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE profiles ADD COLUMN approval_threshold_cents INTEGER NOT NULL DEFAULT 0`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate profiles.approval_threshold_cents: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE job_runs ADD COLUMN last_manual INTEGER NOT NULL DEFAULT 0`); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return fmt.Errorf("migrate job_runs.last_manual: %w", err)
+	}
+	if err := migrateCurrencyCodes(db); err != nil {
+		return fmt.Errorf("migrate profiles.currency codes: %w", err)
+	}
+	if err := migratePriceCents(db); err != nil {
+		return fmt.Errorf("migrate prices to cents: %w", err)
+	}
+	if err := migrateShareViews(db); err != nil {
+		return fmt.Errorf("migrate share views: %w", err)
+	}
+	return nil
+}
+
+// migrateShareViews moves the view counts kept on profiles before the share_views table existed. The
+// profile columns are cleared afterwards, so only counts from before the switch are moved.
+func migrateShareViews(db *sql.DB) error {
+	if _, err := db.Exec(`INSERT OR IGNORE INTO share_views(user_id, views, viewed_at) SELECT user_id, share_views, share_viewed_at FROM profiles WHERE share_views > 0`); err != nil {
+		return err
+	}
+	_, err := db.Exec(`UPDATE profiles SET share_views = 0, share_viewed_at = '' WHERE share_views > 0`)
+	return err
+}
+
+// migratePriceCents converts decimal prices and approval thresholds to integer cents. The decimal
+// columns are cleared afterwards, so the migration only touches rows written before the switch.
+func migratePriceCents(db *sql.DB) error {
+	if _, err := db.Exec(`UPDATE items SET price_cents = CAST(ROUND(price_value * 100) AS INTEGER), price_value = NULL WHERE price_value IS NOT NULL`); err != nil {
+		return err
+	}
+	if _, err := db.Exec(`UPDATE profiles SET approval_threshold_cents = CAST(ROUND(approval_threshold * 100) AS INTEGER), approval_threshold = 0 WHERE approval_threshold <> 0`); err != nil {
+		return err
+	}
+	return nil
+}
+
+// migrateCurrencyCodes rewrites free-text profile currencies ("€", "eur", "Fr.") to ISO codes.
+// Values that match no supported currency fall back to the default.
+func migrateCurrencyCodes(db *sql.DB) error {
+	rows, err := db.Query(`SELECT user_id, currency FROM profiles`)
+	if err != nil {
+		return err
+	}
+	updates := map[string]string{}
+	for rows.Next() {
+		var userID, currency string
+		if err := rows.Scan(&userID, &currency); err != nil {
+			rows.Close()
+			return err
+		}
+		if code := normalizeCurrency(currency); code != currency {
+			updates[userID] = code
+		}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return err
+	}
+	rows.Close()
+
+	for userID, code := range updates {
+		if _, err := db.Exec(`UPDATE profiles SET currency = ? WHERE user_id = ?`, code, userID); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 func (a *App) loadStateFromDB(userID string) error {
 	if a.db == nil {
-		a.tagCatalog = append([]string(nil), defaultTagOptions...)
+		a.tagCatalog = a.starterTagsLocked()
 		return nil
 	}
 
@@ -111,18 +674,61 @@ func (a *App) loadStateFromDB(userID string) error {
 	a.nextID = 1
 	a.hourlyWage = ""
 	a.currency = ""
-	a.defaultWaitPreset = defaultWaitPreset("")
+	a.defaultWaitPreset = domain.NormalizeWaitPreset("")
 	a.defaultWaitCustomHours = ""
 	a.ntfyURL = ""
 	a.ntfyTopic = ""
 	a.tagCatalog = nil
+	a.shareToken = ""
+	a.shareExpiresAt = time.Time{}
+	a.retentionMonths = 0
+	a.fireflyURL = ""
+	a.fireflyToken = ""
+	a.fireflyAccount = ""
+	a.approvalThreshold = 0
+	a.approver = ""
+	a.itemTemplates = nil
+	a.tagWaitDefaults = nil
+	a.trendTimezone = ""
+	a.weekStart = ""
+	a.monthStartDay = 0
+	a.onboardingStep = ""
+	a.metricsOptIn = false
+	a.notifyDryRun = false
+	a.haWebhookURL = ""
+	a.workHoursMode = ""
+	a.shiftHours = ""
+	a.monthlyIncome = ""
+	a.weeklyHours = ""
+	a.projectionRate = ""
+	a.projectionYears = 0
+	a.renotifyPolicy = ""
+	a.renotifyDays = 0
+	a.numberFormat = ""
+	a.payday = 0
+	a.blackouts = nil
+	a.routingRules = nil
+	a.avatarEmoji = ""
+	a.avatarColor = ""
+	a.hoursGoal = 0
+	a.hoursGoalCelebrated = ""
+	a.workHoursPrecision = ""
+	a.workHoursRounding = ""
+	a.noteKeySalt = ""
+	a.noteKeyCheck = ""
+	a.landingPage = ""
+	a.lastVisited = ""
+	a.dashboardFilters = ""
+	a.archived = false
 	a.profileExists = false
 
-	row := a.db.QueryRow(`SELECT hourly_wage, currency, default_wait_preset, default_wait_custom_hours, ntfy_endpoint, ntfy_topic, tag_catalog FROM profiles WHERE user_id = ?`, userID)
-	var hourlyWage, currency, defaultPreset, defaultCustomHours, ntfyEndpoint, ntfyTopic, tagCatalogRaw string
-	switch err := row.Scan(&hourlyWage, &currency, &defaultPreset, &defaultCustomHours, &ntfyEndpoint, &ntfyTopic, &tagCatalogRaw); {
+	row := a.db.QueryRow(`SELECT hourly_wage, currency, default_wait_preset, default_wait_custom_hours, ntfy_endpoint, ntfy_topic, tag_catalog, share_token, retention_months, firefly_url, firefly_token, firefly_account, approval_threshold_cents, approver, tag_wait_defaults, trend_timezone, week_start, month_start_day, onboarding_step, metrics_opt_in, ha_webhook_url, work_hours_mode, shift_hours, monthly_income, weekly_hours, projection_rate, projection_years, renotify_policy, renotify_days, number_format, payday, blackouts, avatar_emoji, avatar_color, hours_goal, hours_goal_celebrated, work_hours_precision, work_hours_rounding, note_key_salt, note_key_check, share_expires_at, landing_page, last_visited, dashboard_filters, archived_at, notify_dry_run, routing_rules FROM profiles WHERE user_id = ?`, userID)
+	var hourlyWage, currency, defaultPreset, defaultCustomHours, ntfyEndpoint, ntfyTopic, tagCatalogRaw, shareToken, fireflyURL, fireflyToken, fireflyAccount, approver, tagWaitDefaultsRaw, trendTimezone, weekStart, onboardingStep, haWebhookURL, workHoursMode, shiftHours, monthlyIncome, weeklyHours, projectionRate, renotifyPolicy, numberFormat, blackoutsRaw, avatarEmoji, avatarColor, hoursGoalCelebrated, workHoursPrecision, workHoursRounding, noteKeySalt, noteKeyCheck, shareExpiresAt, landingPage, lastVisited, dashboardFilters, archivedAt, routingRulesRaw string
+	var retentionMonths, monthStartDay, metricsOptIn, projectionYears, renotifyDays, payday, hoursGoal, notifyDryRun int
+	var approvalThreshold domain.Money
+	switch err := row.Scan(&hourlyWage, &currency, &defaultPreset, &defaultCustomHours, &ntfyEndpoint, &ntfyTopic, &tagCatalogRaw, &shareToken, &retentionMonths, &fireflyURL, &fireflyToken, &fireflyAccount, &approvalThreshold, &approver, &tagWaitDefaultsRaw, &trendTimezone, &weekStart, &monthStartDay, &onboardingStep, &metricsOptIn, &haWebhookURL, &workHoursMode, &shiftHours, &monthlyIncome, &weeklyHours, &projectionRate, &projectionYears, &renotifyPolicy, &renotifyDays, &numberFormat, &payday, &blackoutsRaw, &avatarEmoji, &avatarColor, &hoursGoal, &hoursGoalCelebrated, &workHoursPrecision, &workHoursRounding, &noteKeySalt, &noteKeyCheck, &shareExpiresAt, &landingPage, &lastVisited, &dashboardFilters, &archivedAt, &notifyDryRun, &routingRulesRaw); {
 	case errors.Is(err, sql.ErrNoRows):
-		a.tagCatalog = append([]string(nil), defaultTagOptions...)
+		a.tagCatalog = a.starterTagsLocked()
 	case err != nil:
 		return fmt.Errorf("load profile: %w", err)
 	default:
@@ -132,7 +738,10 @@ func (a *App) loadStateFromDB(userID string) error {
 			a.hourlyWage = defaultProfileHourlyWage
 		}
 		a.currency = normalizeCurrency(currency)
-		a.defaultWaitPreset = defaultWaitPreset(defaultPreset)
+		a.trendTimezone = trendTimezone
+		a.weekStart = weekStart
+		a.monthStartDay = monthStartDay
+		a.defaultWaitPreset = domain.NormalizeWaitPreset(defaultPreset)
 		if a.defaultWaitPreset == "custom" {
 			a.defaultWaitCustomHours = defaultCustomHours
 		}
@@ -140,31 +749,96 @@ func (a *App) loadStateFromDB(userID string) error {
 		a.ntfyTopic = ntfyTopic
 		a.tagCatalog = parseTagCatalog(tagCatalogRaw)
 		if len(a.tagCatalog) == 0 {
-			a.tagCatalog = append([]string(nil), defaultTagOptions...)
+			a.tagCatalog = a.starterTagsLocked()
 		}
+		a.shareToken = shareToken
+		if expiresAt, err := time.Parse(time.RFC3339Nano, shareExpiresAt); err == nil {
+			a.shareExpiresAt = expiresAt
+		}
+		a.retentionMonths = retentionMonths
+		a.fireflyURL = fireflyURL
+		a.fireflyToken = fireflyToken
+		a.fireflyAccount = fireflyAccount
+		a.approvalThreshold = approvalThreshold
+		a.approver = approver
+		a.tagWaitDefaults = parseTagWaitDefaults(tagWaitDefaultsRaw)
+		a.onboardingStep = onboardingStep
+		a.metricsOptIn = metricsOptIn == 1
+		a.notifyDryRun = notifyDryRun == 1
+		a.haWebhookURL = haWebhookURL
+		a.workHoursMode = domain.NormalizeWorkHoursMode(workHoursMode)
+		a.shiftHours = shiftHours
+		a.monthlyIncome = monthlyIncome
+		a.weeklyHours = weeklyHours
+		a.projectionRate = projectionRate
+		a.projectionYears = projectionYears
+		a.renotifyPolicy = domain.NormalizeRenotifyMode(renotifyPolicy)
+		a.renotifyDays = renotifyDays
+		a.numberFormat = string(domain.NormalizeNumberFormat(numberFormat))
+		a.payday = payday
+		a.blackouts = domain.ParseBlackouts(blackoutsRaw)
+		a.routingRules = domain.ParseRoutingRules(routingRulesRaw)
+		a.avatarEmoji = avatarEmoji
+		a.avatarColor = avatarColor
+		a.hoursGoal = hoursGoal
+		a.hoursGoalCelebrated = hoursGoalCelebrated
+		a.workHoursPrecision = workHoursPrecision
+		a.workHoursRounding = domain.NormalizeWorkHoursRounding(workHoursRounding)
+		a.noteKeySalt = noteKeySalt
+		a.noteKeyCheck = noteKeyCheck
+		a.landingPage = domain.NormalizeLandingPage(landingPage)
+		a.lastVisited = lastVisited
+		a.dashboardFilters = dashboardFilters
+		a.archived = archivedAt != ""
 	}
+	a.cacheActiveAvatarLocked()
 
-	rows, err := a.db.Query(`
-SELECT id, title, price, COALESCE(price_value, 0), has_price_value, link, note, tags, status, wait_preset, wait_custom_hours, purchase_allowed_at, created_at, ntfy_attempted
+	items, err := queryItemsForUser(a.db, userID)
+	if err != nil {
+		return err
+	}
+
+	maxID := 0
+	for _, item := range items {
+		if item.ID > maxID {
+			maxID = item.ID
+		}
+	}
+	a.openNotesLocked(userID, items)
+	a.items = items
+	a.nextID = maxID + 1
+
+	templates, err := queryItemTemplatesForUser(a.db, userID)
+	if err != nil {
+		return err
+	}
+	a.itemTemplates = templates
+	return nil
+}
+
+func queryItemsForUser(db *scopedDB, userID string) ([]Item, error) {
+	rows, err := db.Query(`
+SELECT id, user_id, title, price, price_cents, has_price_value, link, note, tags, status, wait_preset, wait_custom_hours, purchase_allowed_at, created_at, decided_at, ntfy_attempted, firefly_pushed, approval_state, urge_score, satisfaction, notified_at, receipt_name, private
 FROM items
-WHERE user_id = ?
+WHERE `+itemAccessCondition+`
 ORDER BY id DESC
-`, userID)
+`, userID, userID)
 	if err != nil {
-		return fmt.Errorf("load items: %w", err)
+		return nil, fmt.Errorf("load items: %w", err)
 	}
 	defer rows.Close()
 
-	maxID := 0
+	var items []Item
 	for rows.Next() {
 		var item Item
-		var purchaseAllowedAtRaw, createdAtRaw string
-		var hasPriceValueInt, ntfyAttemptedInt int
+		var purchaseAllowedAtRaw, createdAtRaw, decidedAtRaw, notifiedAtRaw string
+		var hasPriceValueInt, ntfyAttemptedInt, fireflyPushedInt, privateInt int
 		if err := rows.Scan(
 			&item.ID,
+			&item.OwnerID,
 			&item.Title,
 			&item.Price,
-			&item.PriceValue,
+			&item.PriceCents,
 			&hasPriceValueInt,
 			&item.Link,
 			&item.Note,
@@ -174,36 +848,98 @@ ORDER BY id DESC
 			&item.WaitCustomHours,
 			&purchaseAllowedAtRaw,
 			&createdAtRaw,
+			&decidedAtRaw,
 			&ntfyAttemptedInt,
+			&fireflyPushedInt,
+			&item.ApprovalState,
+			&item.UrgeScore,
+			&item.Satisfaction,
+			&notifiedAtRaw,
+			&item.ReceiptName,
+			&privateInt,
 		); err != nil {
-			return fmt.Errorf("scan item: %w", err)
+			return nil, fmt.Errorf("scan item: %w", err)
 		}
 
 		purchaseAllowedAt, err := time.Parse(time.RFC3339Nano, purchaseAllowedAtRaw)
 		if err != nil {
-			return fmt.Errorf("parse purchase_allowed_at: %w", err)
+			return nil, fmt.Errorf("parse purchase_allowed_at: %w", err)
 		}
 		createdAt, err := time.Parse(time.RFC3339Nano, createdAtRaw)
 		if err != nil {
-			return fmt.Errorf("parse created_at: %w", err)
+			return nil, fmt.Errorf("parse created_at: %w", err)
+		}
+		if decidedAtRaw != "" {
+			decidedAt, err := time.Parse(time.RFC3339Nano, decidedAtRaw)
+			if err != nil {
+				return nil, fmt.Errorf("parse decided_at: %w", err)
+			}
+			item.DecidedAt = decidedAt
+		}
+		if notifiedAtRaw != "" {
+			notifiedAt, err := time.Parse(time.RFC3339Nano, notifiedAtRaw)
+			if err != nil {
+				return nil, fmt.Errorf("parse notified_at: %w", err)
+			}
+			item.NotifiedAt = notifiedAt
 		}
 
 		item.HasPriceValue = hasPriceValueInt == 1
 		item.NtfyAttempted = ntfyAttemptedInt == 1
+		item.FireflyPushed = fireflyPushedInt == 1
+		item.Private = privateInt == 1
 		item.PurchaseAllowedAt = purchaseAllowedAt
 		item.CreatedAt = createdAt
 
-		a.items = append(a.items, item)
-		if item.ID > maxID {
-			maxID = item.ID
-		}
+		items = append(items, item)
 	}
 	if err := rows.Err(); err != nil {
-		return fmt.Errorf("iterate items: %w", err)
+		return nil, fmt.Errorf("iterate items: %w", err)
 	}
 
-	a.nextID = maxID + 1
-	return nil
+	shares, splits, err := queryItemSharesForUser(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i].SharedWith = shares[items[i].ID]
+		items[i].SplitPercents = splits[items[i].ID]
+	}
+	return items, nil
+}
+
+func queryItemSharesForUser(db *scopedDB, userID string) (map[int][]string, map[int]map[string]int, error) {
+	rows, err := db.Query(`
+SELECT item_id, user_id, split_percent
+FROM item_shares
+WHERE item_id IN (SELECT id FROM items WHERE `+itemAccessCondition+`)
+ORDER BY user_id COLLATE NOCASE
+`, userID, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load item shares: %w", err)
+	}
+	defer rows.Close()
+
+	shares := map[int][]string{}
+	splits := map[int]map[string]int{}
+	for rows.Next() {
+		var itemID, splitPercent int
+		var sharedWith string
+		if err := rows.Scan(&itemID, &sharedWith, &splitPercent); err != nil {
+			return nil, nil, fmt.Errorf("scan item share: %w", err)
+		}
+		shares[itemID] = append(shares[itemID], sharedWith)
+		if splitPercent > 0 {
+			if splits[itemID] == nil {
+				splits[itemID] = map[string]int{}
+			}
+			splits[itemID][sharedWith] = splitPercent
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("iterate item shares: %w", err)
+	}
+	return shares, splits, nil
 }
 
 func (a *App) persistProfileLocked() error {
@@ -213,8 +949,8 @@ func (a *App) persistProfileLocked() error {
 		return nil
 	}
 	_, err := a.db.Exec(`
-INSERT INTO profiles(user_id, hourly_wage, currency, default_wait_preset, default_wait_custom_hours, ntfy_endpoint, ntfy_topic, tag_catalog, updated_at)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+INSERT INTO profiles(user_id, hourly_wage, currency, default_wait_preset, default_wait_custom_hours, ntfy_endpoint, ntfy_topic, tag_catalog, share_token, retention_months, firefly_url, firefly_token, firefly_account, approval_threshold_cents, approver, tag_wait_defaults, trend_timezone, week_start, month_start_day, onboarding_step, metrics_opt_in, ha_webhook_url, work_hours_mode, shift_hours, monthly_income, weekly_hours, projection_rate, projection_years, renotify_policy, renotify_days, number_format, payday, blackouts, avatar_emoji, avatar_color, hours_goal, hours_goal_celebrated, work_hours_precision, work_hours_rounding, note_key_salt, note_key_check, share_expires_at, landing_page, last_visited, dashboard_filters, notify_dry_run, routing_rules, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 ON CONFLICT(user_id) DO UPDATE SET
 	hourly_wage = excluded.hourly_wage,
 	currency = excluded.currency,
@@ -223,8 +959,47 @@ ON CONFLICT(user_id) DO UPDATE SET
 	ntfy_endpoint = excluded.ntfy_endpoint,
 	ntfy_topic = excluded.ntfy_topic,
 	tag_catalog = excluded.tag_catalog,
+	share_token = excluded.share_token,
+	retention_months = excluded.retention_months,
+	firefly_url = excluded.firefly_url,
+	firefly_token = excluded.firefly_token,
+	firefly_account = excluded.firefly_account,
+	approval_threshold_cents = excluded.approval_threshold_cents,
+	approver = excluded.approver,
+	tag_wait_defaults = excluded.tag_wait_defaults,
+	trend_timezone = excluded.trend_timezone,
+	week_start = excluded.week_start,
+	month_start_day = excluded.month_start_day,
+	onboarding_step = excluded.onboarding_step,
+	metrics_opt_in = excluded.metrics_opt_in,
+	ha_webhook_url = excluded.ha_webhook_url,
+	work_hours_mode = excluded.work_hours_mode,
+	shift_hours = excluded.shift_hours,
+	monthly_income = excluded.monthly_income,
+	weekly_hours = excluded.weekly_hours,
+	projection_rate = excluded.projection_rate,
+	projection_years = excluded.projection_years,
+	renotify_policy = excluded.renotify_policy,
+	renotify_days = excluded.renotify_days,
+	number_format = excluded.number_format,
+	payday = excluded.payday,
+	blackouts = excluded.blackouts,
+	avatar_emoji = excluded.avatar_emoji,
+	avatar_color = excluded.avatar_color,
+	hours_goal = excluded.hours_goal,
+	hours_goal_celebrated = excluded.hours_goal_celebrated,
+	work_hours_precision = excluded.work_hours_precision,
+	work_hours_rounding = excluded.work_hours_rounding,
+	note_key_salt = excluded.note_key_salt,
+	note_key_check = excluded.note_key_check,
+	share_expires_at = excluded.share_expires_at,
+	landing_page = excluded.landing_page,
+	last_visited = excluded.last_visited,
+	dashboard_filters = excluded.dashboard_filters,
+	notify_dry_run = excluded.notify_dry_run,
+	routing_rules = excluded.routing_rules,
 	updated_at = excluded.updated_at
-`, userID, defaultHourlyWageValue(a.hourlyWage), normalizeCurrency(a.currency), defaultWaitPreset(a.defaultWaitPreset), a.defaultWaitCustomHours, a.ntfyURL, a.ntfyTopic, strings.Join(a.tagCatalog, ", "), time.Now().Format(time.RFC3339Nano))
+`, userID, defaultHourlyWageValue(a.hourlyWage), normalizeCurrency(a.currency), domain.NormalizeWaitPreset(a.defaultWaitPreset), a.defaultWaitCustomHours, a.ntfyURL, a.ntfyTopic, strings.Join(a.tagCatalog, ", "), a.shareToken, a.retentionMonths, a.fireflyURL, a.fireflyToken, a.fireflyAccount, a.approvalThreshold, a.approver, formatTagWaitDefaults(a.tagWaitDefaults), a.trendTimezone, normalizeWeekStart(a.weekStart), normalizeMonthStartDay(a.monthStartDay), a.onboardingStep, boolToInt(a.metricsOptIn), a.haWebhookURL, domain.NormalizeWorkHoursMode(a.workHoursMode), a.shiftHours, a.monthlyIncome, a.weeklyHours, a.projectionRate, a.projectionYears, domain.NormalizeRenotifyMode(a.renotifyPolicy), a.renotifyDays, string(domain.NormalizeNumberFormat(a.numberFormat)), a.payday, domain.FormatBlackouts(a.blackouts), a.avatarEmoji, a.avatarColor, a.hoursGoal, a.hoursGoalCelebrated, a.workHoursPrecision, domain.NormalizeWorkHoursRounding(a.workHoursRounding), a.noteKeySalt, a.noteKeyCheck, formatOptionalTime(a.shareExpiresAt), domain.NormalizeLandingPage(a.landingPage), a.lastVisited, a.dashboardFilters, boolToInt(a.notifyDryRun), domain.FormatRoutingRules(a.routingRules), time.Now().Format(time.RFC3339Nano))
 	if err != nil {
 		return fmt.Errorf("persist profile: %w", err)
 	}
@@ -236,30 +1011,40 @@ ON CONFLICT(user_id) DO UPDATE SET
 
 func (a *App) insertItemLocked(item *Item) error {
 	userID := a.currentUserIDLocked()
+	item.OwnerID = userID
 	if a.db == nil {
 		item.ID = a.nextID
 		a.nextID++
 		return nil
 	}
+	note, err := a.sealNoteLocked(*item)
+	if err != nil {
+		return err
+	}
 
 	res, err := a.db.Exec(`
-INSERT INTO items(user_id, title, price, price_value, has_price_value, link, note, tags, status, wait_preset, wait_custom_hours, purchase_allowed_at, created_at, ntfy_attempted)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+INSERT INTO items(user_id, title, price, price_cents, has_price_value, link, note, tags, status, wait_preset, wait_custom_hours, purchase_allowed_at, created_at, decided_at, ntfy_attempted, approval_state, urge_score, satisfaction, private)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 `,
 		userID,
 		item.Title,
 		item.Price,
-		item.PriceValue,
+		item.PriceCents,
 		boolToInt(item.HasPriceValue),
 		item.Link,
-		item.Note,
+		note,
 		item.Tags,
 		item.Status,
 		item.WaitPreset,
 		item.WaitCustomHours,
 		item.PurchaseAllowedAt.Format(time.RFC3339Nano),
 		item.CreatedAt.Format(time.RFC3339Nano),
+		formatOptionalTime(item.DecidedAt),
 		boolToInt(item.NtfyAttempted),
+		item.ApprovalState,
+		item.UrgeScore,
+		item.Satisfaction,
+		boolToInt(item.Private),
 	)
 	if err != nil {
 		return fmt.Errorf("insert item: %w", err)
@@ -277,31 +1062,51 @@ VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 }
 
 func (a *App) updateItemLocked(item Item) error {
-	userID := a.currentUserIDLocked()
 	if a.db == nil {
-		a.tagCatalog = append([]string(nil), defaultTagOptions...)
+		a.tagCatalog = a.starterTagsLocked()
 		return nil
 	}
+	return a.updateItemWithLocked(a.db, item)
+}
 
-	_, err := a.db.Exec(`
+// sqlExecer is the app database or one of its transactions, so item updates can join an outbox transaction.
+type sqlExecer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+func (a *App) updateItemWithLocked(db sqlExecer, item Item) error {
+	userID := a.currentUserIDLocked()
+	note, err := a.sealNoteLocked(item)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
 UPDATE items
-SET title = ?, price = ?, price_value = ?, has_price_value = ?, link = ?, note = ?, tags = ?, status = ?, wait_preset = ?, wait_custom_hours = ?, purchase_allowed_at = ?, ntfy_attempted = ?
-WHERE id = ? AND user_id = ?
+SET title = ?, price = ?, price_cents = ?, has_price_value = ?, link = ?, note = ?, tags = ?, status = ?, wait_preset = ?, wait_custom_hours = ?, purchase_allowed_at = ?, created_at = ?, decided_at = ?, ntfy_attempted = ?, approval_state = ?, urge_score = ?, satisfaction = ?, private = ?
+WHERE id = ? AND `+itemAccessCondition+`
 `,
 		item.Title,
 		item.Price,
-		item.PriceValue,
+		item.PriceCents,
 		boolToInt(item.HasPriceValue),
 		item.Link,
-		item.Note,
+		note,
 		item.Tags,
 		item.Status,
 		item.WaitPreset,
 		item.WaitCustomHours,
 		item.PurchaseAllowedAt.Format(time.RFC3339Nano),
+		item.CreatedAt.Format(time.RFC3339Nano),
+		formatOptionalTime(item.DecidedAt),
 		boolToInt(item.NtfyAttempted),
+		item.ApprovalState,
+		item.UrgeScore,
+		item.Satisfaction,
+		boolToInt(item.Private),
 		item.ID,
 		userID,
+		userID,
 	)
 	if err != nil {
 		return fmt.Errorf("update item: %w", err)
@@ -312,53 +1117,54 @@ WHERE id = ? AND user_id = ?
 func (a *App) deleteItemLocked(itemID int) error {
 	userID := a.currentUserIDLocked()
 	if a.db == nil {
-		a.tagCatalog = append([]string(nil), defaultTagOptions...)
+		a.tagCatalog = a.starterTagsLocked()
 		return nil
 	}
 
-	_, err := a.db.Exec(`DELETE FROM items WHERE id = ? AND user_id = ?`, itemID, userID)
-	if err != nil {
-		return fmt.Errorf("delete item: %w", err)
-	}
-	return nil
+	return a.deleteItemsLocked(userID, []int{itemID})
 }
 
-func (a *App) updateItemStatusLocked(itemID int, status string) error {
-	userID := a.currentUserIDLocked()
+func (a *App) updateItemStatusLocked(itemID int, status domain.Status, decidedAt time.Time) error {
 	if a.db == nil {
-		a.tagCatalog = append([]string(nil), defaultTagOptions...)
+		a.tagCatalog = a.starterTagsLocked()
 		return nil
 	}
+	return a.updateItemStatusWithLocked(a.db, itemID, status, decidedAt)
+}
 
-	_, err := a.db.Exec(`UPDATE items SET status = ? WHERE id = ? AND user_id = ?`, status, itemID, userID)
+func (a *App) updateItemStatusWithLocked(db sqlExecer, itemID int, status domain.Status, decidedAt time.Time) error {
+	userID := a.currentUserIDLocked()
+	_, err := db.Exec(`UPDATE items SET status = ?, decided_at = ? WHERE id = ? AND `+itemAccessCondition, status, formatOptionalTime(decidedAt), itemID, userID, userID)
 	if err != nil {
 		return fmt.Errorf("update item status: %w", err)
 	}
 	return nil
 }
 
-func (a *App) markNtfyAttemptedLocked(itemID int) error {
+func (a *App) markFireflyPushedLocked(itemID int) error {
 	userID := a.currentUserIDLocked()
 	if a.db == nil {
-		a.tagCatalog = append([]string(nil), defaultTagOptions...)
 		return nil
 	}
 
-	_, err := a.db.Exec(`UPDATE items SET ntfy_attempted = 1 WHERE id = ? AND user_id = ?`, itemID, userID)
+	_, err := a.db.Exec(`UPDATE items SET firefly_pushed = 1 WHERE id = ? AND `+itemAccessCondition, itemID, userID, userID)
 	if err != nil {
-		return fmt.Errorf("mark ntfy attempted: %w", err)
+		return fmt.Errorf("mark firefly pushed: %w", err)
 	}
 	return nil
 }
 
 func (a *App) updatePromotedItemLocked(item Item) error {
-	userID := a.currentUserIDLocked()
 	if a.db == nil {
-		a.tagCatalog = append([]string(nil), defaultTagOptions...)
+		a.tagCatalog = a.starterTagsLocked()
 		return nil
 	}
+	return a.updatePromotedItemWithLocked(a.db, item)
+}
 
-	_, err := a.db.Exec(`UPDATE items SET status = ?, ntfy_attempted = ? WHERE id = ? AND user_id = ?`, item.Status, boolToInt(item.NtfyAttempted), item.ID, userID)
+func (a *App) updatePromotedItemWithLocked(db sqlExecer, item Item) error {
+	userID := a.currentUserIDLocked()
+	_, err := db.Exec(`UPDATE items SET status = ?, ntfy_attempted = ?, notified_at = ? WHERE id = ? AND `+itemAccessCondition, item.Status, boolToInt(item.NtfyAttempted), formatOptionalTime(item.NotifiedAt), item.ID, userID, userID)
 	if err != nil {
 		return fmt.Errorf("update promoted item: %w", err)
 	}
@@ -367,7 +1173,7 @@ func (a *App) updatePromotedItemLocked(item Item) error {
 
 func (a *App) deleteProfileLocked(userID string) error {
 	if a.db == nil {
-		a.tagCatalog = append([]string(nil), defaultTagOptions...)
+		a.tagCatalog = a.starterTagsLocked()
 		return nil
 	}
 
@@ -379,22 +1185,65 @@ func (a *App) deleteProfileLocked(userID string) error {
 		_ = tx.Rollback()
 	}()
 
+	if _, err := tx.Exec(`DELETE FROM item_history WHERE item_id IN (SELECT id FROM items WHERE user_id = ?)`, userID); err != nil {
+		return fmt.Errorf("delete profile item history: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM item_receipts WHERE item_id IN (SELECT id FROM items WHERE user_id = ?)`, userID); err != nil {
+		return fmt.Errorf("delete profile item receipts: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM item_shares WHERE user_id = ? OR item_id IN (SELECT id FROM items WHERE user_id = ?)`, userID, userID); err != nil {
+		return fmt.Errorf("delete profile item shares: %w", err)
+	}
 	if _, err := tx.Exec(`DELETE FROM items WHERE user_id = ?`, userID); err != nil {
 		return fmt.Errorf("delete profile items: %w", err)
 	}
+	if _, err := tx.Exec(`DELETE FROM item_templates WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("delete profile item templates: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM api_idempotency_keys WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("delete profile idempotency keys: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM item_changes WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("delete profile item changes: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM push_subscriptions WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("delete profile push subscriptions: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM outbox WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("delete profile outbox: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM ready_digest WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("delete profile ready digest: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM notification_log WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("delete profile notification log: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM followed_shares WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("delete profile followed shares: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM leaderboard_members WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("delete profile leaderboard membership: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM share_views WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("delete profile share views: %w", err)
+	}
 	if _, err := tx.Exec(`DELETE FROM profiles WHERE user_id = ?`, userID); err != nil {
 		return fmt.Errorf("delete profile row: %w", err)
 	}
+	if _, err := tx.Exec(`UPDATE profiles SET approver = '' WHERE approver = ?`, userID); err != nil {
+		return fmt.Errorf("clear deleted approver: %w", err)
+	}
 
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("commit delete profile tx: %w", err)
 	}
+	a.noteKeys.forget(userID)
 	return nil
 }
 
 func (a *App) renameProfileLocked(oldUserID, newUserID string) error {
 	if a.db == nil {
-		a.tagCatalog = append([]string(nil), defaultTagOptions...)
+		a.tagCatalog = a.starterTagsLocked()
 		return nil
 	}
 
@@ -413,19 +1262,62 @@ WHERE user_id = ?
 `, newUserID, oldUserID); err != nil {
 		return fmt.Errorf("move items to renamed profile: %w", err)
 	}
-
-	if _, err := tx.Exec(`
-UPDATE profiles
-SET user_id = ?
-WHERE user_id = ?
-`, newUserID, oldUserID); err != nil {
-		return fmt.Errorf("rename profile row: %w", err)
+	if _, err := tx.Exec(`UPDATE item_shares SET user_id = ? WHERE user_id = ?`, newUserID, oldUserID); err != nil {
+		return fmt.Errorf("move item shares to renamed profile: %w", err)
 	}
-
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit rename profile tx: %w", err)
+	if _, err := tx.Exec(`UPDATE item_history SET user_id = ? WHERE user_id = ?`, newUserID, oldUserID); err != nil {
+		return fmt.Errorf("move item history to renamed profile: %w", err)
 	}
-	return nil
+	if _, err := tx.Exec(`UPDATE item_templates SET user_id = ? WHERE user_id = ?`, newUserID, oldUserID); err != nil {
+		return fmt.Errorf("move item templates to renamed profile: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE audit_log SET user_id = ? WHERE user_id = ?`, newUserID, oldUserID); err != nil {
+		return fmt.Errorf("move audit log to renamed profile: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE api_idempotency_keys SET user_id = ? WHERE user_id = ?`, newUserID, oldUserID); err != nil {
+		return fmt.Errorf("move idempotency keys to renamed profile: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE item_changes SET user_id = ? WHERE user_id = ?`, newUserID, oldUserID); err != nil {
+		return fmt.Errorf("move item changes to renamed profile: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE push_subscriptions SET user_id = ? WHERE user_id = ?`, newUserID, oldUserID); err != nil {
+		return fmt.Errorf("move push subscriptions to renamed profile: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE outbox SET user_id = ? WHERE user_id = ?`, newUserID, oldUserID); err != nil {
+		return fmt.Errorf("move outbox to renamed profile: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE ready_digest SET user_id = ? WHERE user_id = ?`, newUserID, oldUserID); err != nil {
+		return fmt.Errorf("move ready digest to renamed profile: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE notification_log SET user_id = ? WHERE user_id = ?`, newUserID, oldUserID); err != nil {
+		return fmt.Errorf("move notification log to renamed profile: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE followed_shares SET user_id = ? WHERE user_id = ?`, newUserID, oldUserID); err != nil {
+		return fmt.Errorf("move followed shares to renamed profile: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE leaderboard_members SET user_id = ? WHERE user_id = ?`, newUserID, oldUserID); err != nil {
+		return fmt.Errorf("move leaderboard membership to renamed profile: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE share_views SET user_id = ? WHERE user_id = ?`, newUserID, oldUserID); err != nil {
+		return fmt.Errorf("move share views to renamed profile: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+UPDATE profiles
+SET user_id = ?
+WHERE user_id = ?
+`, newUserID, oldUserID); err != nil {
+		return fmt.Errorf("rename profile row: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE profiles SET approver = ? WHERE approver = ?`, newUserID, oldUserID); err != nil {
+		return fmt.Errorf("move approver to renamed profile: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit rename profile tx: %w", err)
+	}
+	a.noteKeys.rename(oldUserID, newUserID)
+	return nil
 }
 
 func defaultHourlyWageValue(raw string) string {
@@ -435,9 +1327,637 @@ func defaultHourlyWageValue(raw string) string {
 	return strings.TrimSpace(raw)
 }
 
+func (a *App) deleteItemsLocked(userID string, itemIDs []int) error {
+	if a.db == nil || len(itemIDs) == 0 {
+		return nil
+	}
+
+	tx, err := a.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin delete items tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	for _, itemID := range itemIDs {
+		res, err := tx.Exec(`DELETE FROM items WHERE id = ? AND user_id = ?`, itemID, userID)
+		if err != nil {
+			return fmt.Errorf("delete item %d: %w", itemID, err)
+		}
+		if deleted, err := res.RowsAffected(); err != nil || deleted == 0 {
+			continue
+		}
+		if _, err := tx.Exec(`DELETE FROM item_shares WHERE item_id = ?`, itemID); err != nil {
+			return fmt.Errorf("delete shares of item %d: %w", itemID, err)
+		}
+		if _, err := tx.Exec(`DELETE FROM item_history WHERE item_id = ?`, itemID); err != nil {
+			return fmt.Errorf("delete history of item %d: %w", itemID, err)
+		}
+		if _, err := tx.Exec(`DELETE FROM item_receipts WHERE item_id = ?`, itemID); err != nil {
+			return fmt.Errorf("delete receipt of item %d: %w", itemID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit delete items tx: %w", err)
+	}
+	return nil
+}
+
+func (a *App) retentionSettingsLocked() (map[string]int, error) {
+	if a.db == nil {
+		if a.retentionMonths <= 0 {
+			return nil, nil
+		}
+		return map[string]int{a.currentUserIDLocked(): a.retentionMonths}, nil
+	}
+
+	rows, err := a.db.Query(`SELECT user_id, retention_months FROM profiles WHERE retention_months > 0 AND archived_at = ''`)
+	if err != nil {
+		return nil, fmt.Errorf("list retention settings: %w", err)
+	}
+	defer rows.Close()
+
+	settings := map[string]int{}
+	for rows.Next() {
+		var userID string
+		var months int
+		if err := rows.Scan(&userID, &months); err != nil {
+			return nil, fmt.Errorf("scan retention setting: %w", err)
+		}
+		settings[userID] = months
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate retention settings: %w", err)
+	}
+	return settings, nil
+}
+
+func formatOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
 func boolToInt(v bool) int {
 	if v {
 		return 1
 	}
 	return 0
 }
+
+func (a *App) profileNameByShareTokenLocked(token string) (string, error) {
+	if strings.TrimSpace(token) == "" {
+		return "", nil
+	}
+	if a.db == nil {
+		if a.shareToken == token && !shareLinkExpired(a.shareExpiresAt, time.Now()) {
+			return a.currentUserIDLocked(), nil
+		}
+		return "", nil
+	}
+
+	var name, expiresAt string
+	err := a.db.QueryRow(`SELECT user_id, share_expires_at FROM profiles WHERE share_token = ?`, token).Scan(&name, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("find profile by share token: %w", err)
+	}
+	if expiry, err := time.Parse(time.RFC3339Nano, expiresAt); err == nil && !time.Now().Before(expiry) {
+		return "", nil
+	}
+	return name, nil
+}
+
+func (a *App) itemsForProfileLocked(userID string) ([]Item, error) {
+	if a.db == nil || userID == a.currentUserIDLocked() {
+		return append([]Item(nil), a.items...), nil
+	}
+	return queryItemsForUser(a.db, userID)
+}
+
+func (a *App) currencyForProfileLocked(userID string) (string, error) {
+	if a.db == nil || userID == a.currentUserIDLocked() {
+		return profileCurrencyOrDefault(a.currency), nil
+	}
+
+	var currency string
+	err := a.db.QueryRow(`SELECT currency FROM profiles WHERE user_id = ?`, userID).Scan(&currency)
+	if errors.Is(err, sql.ErrNoRows) {
+		return profileCurrencyOrDefault(""), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("load profile currency: %w", err)
+	}
+	return profileCurrencyOrDefault(currency), nil
+}
+
+func (a *App) itemsByProfileLocked() (map[string][]Item, error) {
+	if a.db == nil {
+		return map[string][]Item{a.currentUserIDLocked(): append([]Item(nil), a.items...)}, nil
+	}
+
+	rows, err := a.db.Query(`SELECT user_id FROM profiles UNION SELECT user_id FROM items`)
+	if err != nil {
+		return nil, fmt.Errorf("list profiles with items: %w", err)
+	}
+	var userIDs []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("scan profile name: %w", err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return nil, fmt.Errorf("iterate profile names: %w", err)
+	}
+	_ = rows.Close()
+
+	result := make(map[string][]Item, len(userIDs))
+	for _, userID := range userIDs {
+		items, err := queryItemsForUser(a.db, userID)
+		if err != nil {
+			return nil, err
+		}
+		result[userID] = items
+	}
+	return result, nil
+}
+
+func (a *App) addItemShareLocked(itemID int, sharedWith string) error {
+	if a.db == nil {
+		return nil
+	}
+
+	_, err := a.db.Exec(`INSERT OR IGNORE INTO item_shares(item_id, user_id, created_at) VALUES (?, ?, ?)`, itemID, sharedWith, time.Now().Format(time.RFC3339Nano))
+	if err != nil {
+		return fmt.Errorf("add item share: %w", err)
+	}
+	return nil
+}
+
+// hourlyWagesLocked returns the valid hourly wages of the given profiles, keyed by profile name.
+func (a *App) hourlyWagesLocked(profiles []string) (map[string]float64, error) {
+	wages := make(map[string]float64, len(profiles))
+	if a.db == nil {
+		return wages, nil
+	}
+	for _, profile := range profiles {
+		var raw string
+		err := a.db.QueryRow(`SELECT hourly_wage FROM profiles WHERE user_id = ?`, profile).Scan(&raw)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("load hourly wage: %w", err)
+		}
+		if wage, err := domain.ParseHourlyWage(raw); err == nil {
+			wages[profile] = wage
+		}
+	}
+	return wages, nil
+}
+
+// setItemSplitLocked stores the price share of each profile the item is shared with.
+func (a *App) setItemSplitLocked(itemID int, splits map[string]int) error {
+	if a.db == nil {
+		return nil
+	}
+
+	tx, err := a.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin item split tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	for profile, percent := range splits {
+		if _, err := tx.Exec(`UPDATE item_shares SET split_percent = ? WHERE item_id = ? AND user_id = ?`, percent, itemID, profile); err != nil {
+			return fmt.Errorf("update item split for %s: %w", profile, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit item split tx: %w", err)
+	}
+	return nil
+}
+
+func (a *App) removeItemShareLocked(itemID int, sharedWith string) error {
+	if a.db == nil {
+		return nil
+	}
+
+	_, err := a.db.Exec(`DELETE FROM item_shares WHERE item_id = ? AND user_id = ?`, itemID, sharedWith)
+	if err != nil {
+		return fmt.Errorf("remove item share: %w", err)
+	}
+	return nil
+}
+
+func (a *App) insertHistoryLocked(itemID int, action, detail string, at time.Time) error {
+	if a.db == nil {
+		return nil
+	}
+
+	_, err := a.db.Exec(`INSERT INTO item_history(item_id, user_id, action, detail, created_at) VALUES (?, ?, ?, ?, ?)`, itemID, a.currentUserIDLocked(), action, detail, at.Format(time.RFC3339Nano))
+	if err != nil {
+		return fmt.Errorf("insert item history: %w", err)
+	}
+	return nil
+}
+
+func (a *App) itemHistoryLocked(itemID int) ([]historyEntry, error) {
+	if a.db == nil {
+		return nil, nil
+	}
+
+	rows, err := a.db.Query(`SELECT user_id, action, detail, created_at FROM item_history WHERE item_id = ? ORDER BY id DESC`, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("load item history: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []historyEntry
+	for rows.Next() {
+		var entry historyEntry
+		var createdAtRaw string
+		if err := rows.Scan(&entry.Actor, &entry.Action, &entry.Detail, &createdAtRaw); err != nil {
+			return nil, fmt.Errorf("scan item history: %w", err)
+		}
+		createdAt, err := time.Parse(time.RFC3339Nano, createdAtRaw)
+		if err != nil {
+			return nil, fmt.Errorf("parse item history created_at: %w", err)
+		}
+		entry.ItemID = itemID
+		entry.CreatedAt = createdAt
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate item history: %w", err)
+	}
+	return entries, nil
+}
+
+func (a *App) approvalRuleForProfileLocked(userID string) (domain.Money, string, error) {
+	if a.db == nil || userID == "" || userID == a.currentUserIDLocked() {
+		return a.approvalThreshold, a.approver, nil
+	}
+
+	var threshold domain.Money
+	var approver string
+	err := a.db.QueryRow(`SELECT approval_threshold_cents, approver FROM profiles WHERE user_id = ?`, userID).Scan(&threshold, &approver)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, "", nil
+	}
+	if err != nil {
+		return 0, "", fmt.Errorf("load approval rule: %w", err)
+	}
+	return threshold, approver, nil
+}
+
+func (a *App) ntfySettingsForProfileLocked(userID string) (string, string, error) {
+	if a.db == nil || userID == a.currentUserIDLocked() {
+		return a.ntfyURL, a.ntfyTopic, nil
+	}
+
+	var endpoint, topic string
+	err := a.db.QueryRow(`SELECT ntfy_endpoint, ntfy_topic FROM profiles WHERE user_id = ?`, userID).Scan(&endpoint, &topic)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("load ntfy settings: %w", err)
+	}
+	return endpoint, topic, nil
+}
+
+// pendingApprovalsLocked returns items waiting for a decision by approver, across all profiles that name them.
+func (a *App) pendingApprovalsLocked(approver string) ([]Item, error) {
+	if a.db == nil {
+		return nil, nil
+	}
+
+	rows, err := a.db.Query(`
+SELECT id, user_id, title, price, price_cents, has_price_value, created_at
+FROM items
+WHERE approval_state = 'requested'
+	AND user_id IN (SELECT user_id FROM profiles WHERE approver = ?)
+ORDER BY id
+`, approver)
+	if err != nil {
+		return nil, fmt.Errorf("load pending approvals: %w", err)
+	}
+	defer rows.Close()
+
+	var items []Item
+	for rows.Next() {
+		var item Item
+		var hasPriceValueInt int
+		var createdAtRaw string
+		if err := rows.Scan(&item.ID, &item.OwnerID, &item.Title, &item.Price, &item.PriceCents, &hasPriceValueInt, &createdAtRaw); err != nil {
+			return nil, fmt.Errorf("scan pending approval: %w", err)
+		}
+		createdAt, err := time.Parse(time.RFC3339Nano, createdAtRaw)
+		if err != nil {
+			return nil, fmt.Errorf("parse created_at: %w", err)
+		}
+		item.HasPriceValue = hasPriceValueInt == 1
+		item.CreatedAt = createdAt
+		item.ApprovalState = approvalRequested
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate pending approvals: %w", err)
+	}
+	return items, nil
+}
+
+// resolveApprovalLocked records the approver's decision and reports whether a pending request was found.
+func (a *App) resolveApprovalLocked(itemID int, approver, state string) (bool, error) {
+	if a.db == nil {
+		return false, nil
+	}
+
+	res, err := a.db.Exec(`
+UPDATE items
+SET approval_state = ?
+WHERE id = ?
+	AND approval_state = 'requested'
+	AND user_id IN (SELECT user_id FROM profiles WHERE approver = ?)
+`, state, itemID, approver)
+	if err != nil {
+		return false, fmt.Errorf("resolve approval: %w", err)
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("resolve approval rows: %w", err)
+	}
+	return updated > 0, nil
+}
+
+func queryItemTemplatesForUser(db *scopedDB, userID string) ([]itemTemplate, error) {
+	rows, err := db.Query(`
+SELECT id, name, title, price, tags, wait_preset, wait_custom_hours
+FROM item_templates
+WHERE user_id = ?
+ORDER BY name COLLATE NOCASE, id
+`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("load item templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []itemTemplate
+	for rows.Next() {
+		var tpl itemTemplate
+		if err := rows.Scan(&tpl.ID, &tpl.Name, &tpl.Title, &tpl.Price, &tpl.Tags, &tpl.WaitPreset, &tpl.WaitCustomHours); err != nil {
+			return nil, fmt.Errorf("scan item template: %w", err)
+		}
+		templates = append(templates, tpl)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate item templates: %w", err)
+	}
+	return templates, nil
+}
+
+func (a *App) insertItemTemplateLocked(tpl *itemTemplate) error {
+	if a.db == nil {
+		for _, existing := range a.itemTemplates {
+			if existing.ID >= tpl.ID {
+				tpl.ID = existing.ID + 1
+			}
+		}
+		if tpl.ID == 0 {
+			tpl.ID = 1
+		}
+		return nil
+	}
+
+	res, err := a.db.Exec(`
+INSERT INTO item_templates(user_id, name, title, price, tags, wait_preset, wait_custom_hours, created_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+`, a.currentUserIDLocked(), tpl.Name, tpl.Title, tpl.Price, tpl.Tags, tpl.WaitPreset, tpl.WaitCustomHours, time.Now().Format(time.RFC3339Nano))
+	if err != nil {
+		return fmt.Errorf("insert item template: %w", err)
+	}
+	insertedID, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("read inserted template id: %w", err)
+	}
+	tpl.ID = int(insertedID)
+	return nil
+}
+
+func (a *App) deleteItemTemplateLocked(templateID int) error {
+	if a.db == nil {
+		return nil
+	}
+
+	_, err := a.db.Exec(`DELETE FROM item_templates WHERE id = ? AND user_id = ?`, templateID, a.currentUserIDLocked())
+	if err != nil {
+		return fmt.Errorf("delete item template: %w", err)
+	}
+	return nil
+}
+
+func (a *App) insertAuditLocked(userID string, entry auditEntry) error {
+	if a.db == nil {
+		return nil
+	}
+
+	_, err := a.db.Exec(`INSERT INTO audit_log(user_id, event, detail, remote_addr, created_at) VALUES (?, ?, ?, ?, ?)`, userID, entry.Event, entry.Detail, entry.RemoteAddr, entry.CreatedAt.Format(time.RFC3339Nano))
+	if err != nil {
+		return fmt.Errorf("insert audit entry: %w", err)
+	}
+	return nil
+}
+
+// auditLogLocked returns the most recent audit entries of a profile, newest first.
+func (a *App) auditLogLocked(userID string, limit int) ([]auditEntry, error) {
+	if a.db == nil {
+		return nil, nil
+	}
+
+	rows, err := a.db.Query(`SELECT event, detail, remote_addr, created_at FROM audit_log WHERE user_id = ? ORDER BY id DESC LIMIT ?`, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("load audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []auditEntry
+	for rows.Next() {
+		var entry auditEntry
+		var createdAtRaw string
+		if err := rows.Scan(&entry.Event, &entry.Detail, &entry.RemoteAddr, &createdAtRaw); err != nil {
+			return nil, fmt.Errorf("scan audit entry: %w", err)
+		}
+		createdAt, err := time.Parse(time.RFC3339Nano, createdAtRaw)
+		if err != nil {
+			return nil, fmt.Errorf("parse audit created_at: %w", err)
+		}
+		entry.CreatedAt = createdAt
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// idempotentResponseLocked returns the stored response for the active profile's idempotency key, if any.
+func (a *App) idempotentResponseLocked(key string) (idempotentResponse, bool, error) {
+	userID := a.currentUserIDLocked()
+	if a.db == nil {
+		stored, ok := a.idempotencyKeys[userID+"\x00"+key]
+		return stored, ok, nil
+	}
+
+	var stored idempotentResponse
+	var createdAt string
+	err := a.db.QueryRow(`SELECT request_hash, status, body, created_at FROM api_idempotency_keys WHERE user_id = ? AND idempotency_key = ?`, userID, key).
+		Scan(&stored.RequestHash, &stored.Status, &stored.Body, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return idempotentResponse{}, false, nil
+	}
+	if err != nil {
+		return idempotentResponse{}, false, fmt.Errorf("load idempotency key: %w", err)
+	}
+	stored.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+	return stored, true, nil
+}
+
+// saveIdempotentResponseLocked stores the response for the active profile's idempotency key and
+// drops the profile's keys that are too old to be replayed.
+func (a *App) saveIdempotentResponseLocked(key string, stored idempotentResponse) error {
+	userID := a.currentUserIDLocked()
+	if a.db == nil {
+		if a.idempotencyKeys == nil {
+			a.idempotencyKeys = map[string]idempotentResponse{}
+		}
+		a.idempotencyKeys[userID+"\x00"+key] = stored
+		return nil
+	}
+
+	cutoff := stored.CreatedAt.Add(-idempotencyKeyTTL).Format(time.RFC3339Nano)
+	if _, err := a.db.Exec(`DELETE FROM api_idempotency_keys WHERE user_id = ? AND created_at < ?`, userID, cutoff); err != nil {
+		return fmt.Errorf("expire idempotency keys: %w", err)
+	}
+	_, err := a.db.Exec(`INSERT OR REPLACE INTO api_idempotency_keys(user_id, idempotency_key, request_hash, status, body, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		userID, key, stored.RequestHash, stored.Status, stored.Body, stored.CreatedAt.Format(time.RFC3339Nano))
+	if err != nil {
+		return fmt.Errorf("save idempotency key: %w", err)
+	}
+	return nil
+}
+
+// itemChangeCursorLocked returns the sequence number of the latest item change of any profile.
+func (a *App) itemChangeCursorLocked() (int64, error) {
+	var cursor int64
+	if err := a.db.QueryRow(`SELECT COALESCE(MAX(seq), 0) FROM item_changes`).Scan(&cursor); err != nil {
+		return 0, fmt.Errorf("load item change cursor: %w", err)
+	}
+	return cursor, nil
+}
+
+// storedRevisionLocked identifies the stored state of all profiles: it moves with every write to items,
+// their shares, profiles and item templates.
+func (a *App) storedRevisionLocked() (storedRevision, error) {
+	var rev storedRevision
+	err := a.db.QueryRow(`SELECT (SELECT COALESCE(MAX(seq), 0) FROM item_changes), (SELECT COALESCE(MAX(revision), 0) FROM profile_revision)`).Scan(&rev.Items, &rev.Profiles)
+	if err != nil {
+		return storedRevision{}, fmt.Errorf("load stored revision: %w", err)
+	}
+	return rev, nil
+}
+
+// changedItemIDsLocked returns the items of the active profile, owned or shared, that changed after since
+// and up to until. Items that are gone or no longer shared are included, so clients can drop them.
+func (a *App) changedItemIDsLocked(since, until int64) ([]int, error) {
+	userID := a.currentUserIDLocked()
+	rows, err := a.db.Query(`
+SELECT item_id FROM item_changes
+WHERE seq > ? AND seq <= ? AND (user_id = ? OR item_id IN (SELECT item_id FROM item_shares WHERE user_id = ?))
+GROUP BY item_id
+ORDER BY MAX(seq)
+`, since, until, userID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list item changes: %w", err)
+	}
+	defer rows.Close()
+
+	var itemIDs []int
+	for rows.Next() {
+		var itemID int
+		if err := rows.Scan(&itemID); err != nil {
+			return nil, fmt.Errorf("scan item change: %w", err)
+		}
+		itemIDs = append(itemIDs, itemID)
+	}
+	return itemIDs, rows.Err()
+}
+
+// savePushSubscriptionLocked registers the device for the active profile.
+func (a *App) savePushSubscriptionLocked(sub storedPushSubscription) error {
+	_, err := a.db.Exec(`
+INSERT INTO push_subscriptions(endpoint, user_id, p256dh, auth, expires_at, created_at)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT(endpoint) DO UPDATE SET
+	user_id = excluded.user_id,
+	p256dh = excluded.p256dh,
+	auth = excluded.auth,
+	expires_at = excluded.expires_at
+`, sub.Endpoint, a.currentUserIDLocked(), encodeBase64URL(sub.P256dh), encodeBase64URL(sub.Auth), formatOptionalTime(sub.ExpiresAt), time.Now().Format(time.RFC3339Nano))
+	if err != nil {
+		return fmt.Errorf("save push subscription: %w", err)
+	}
+	return nil
+}
+
+func (a *App) deletePushSubscriptionLocked(userID, endpoint string) (bool, error) {
+	if a.db == nil {
+		return false, nil
+	}
+	res, err := a.db.Exec(`DELETE FROM push_subscriptions WHERE user_id = ? AND endpoint = ?`, userID, endpoint)
+	if err != nil {
+		return false, fmt.Errorf("delete push subscription: %w", err)
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("delete push subscription: %w", err)
+	}
+	return deleted > 0, nil
+}
+
+func (a *App) pushSubscriptionsLocked(userID string) ([]storedPushSubscription, error) {
+	rows, err := a.db.Query(`SELECT endpoint, p256dh, auth, expires_at FROM push_subscriptions WHERE user_id = ? ORDER BY created_at`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list push subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []storedPushSubscription
+	for rows.Next() {
+		var sub storedPushSubscription
+		var p256dh, auth, expiresAt string
+		if err := rows.Scan(&sub.Endpoint, &p256dh, &auth, &expiresAt); err != nil {
+			return nil, fmt.Errorf("scan push subscription: %w", err)
+		}
+		if sub.P256dh, err = decodeBase64URL(p256dh); err != nil {
+			return nil, fmt.Errorf("decode push subscription key: %w", err)
+		}
+		if sub.Auth, err = decodeBase64URL(auth); err != nil {
+			return nil, fmt.Errorf("decode push subscription secret: %w", err)
+		}
+		sub.ExpiresAt, _ = time.Parse(time.RFC3339Nano, expiresAt)
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
@@ -1,12 +1,15 @@
 package web
 
 import (
+	"database/sql"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewAppWithSQLiteCreatesSchemaAndPersistsData(t *testing.T) {
@@ -31,6 +34,7 @@ func TestNewAppWithSQLiteCreatesSchemaAndPersistsData(t *testing.T) {
 
 	itemForm := url.Values{}
 	itemForm.Set("title", "Bike light")
+	itemForm.Set("price", "19.99")
 	itemReq := httptest.NewRequest(http.MethodPost, "/items/new", strings.NewReader(itemForm.Encode()))
 	itemReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	itemRR := httptest.NewRecorder()
@@ -50,8 +54,16 @@ func TestNewAppWithSQLiteCreatesSchemaAndPersistsData(t *testing.T) {
 	if homeRR.Code != http.StatusOK {
 		t.Fatalf("expected home 200 after reload, got %d", homeRR.Code)
 	}
-	if body := homeRR.Body.String(); !strings.Contains(body, "Bike light") {
-		t.Fatalf("expected persisted item after reload")
+	if body := homeRR.Body.String(); !strings.Contains(body, "Bike light") || !strings.Contains(body, "19.99") {
+		t.Fatalf("expected persisted item and price after reload")
+	}
+	var priceCents int64
+	var priceValue sql.NullFloat64
+	if err := reloadedApp.db.QueryRow(`SELECT price_cents, price_value FROM items WHERE title = 'Bike light'`).Scan(&priceCents, &priceValue); err != nil {
+		t.Fatalf("load stored price: %v", err)
+	}
+	if priceCents != 1999 || priceValue.Valid {
+		t.Fatalf("expected the price stored as 1999 cents only, got %d and %v", priceCents, priceValue)
 	}
 
 	settingsReq := httptest.NewRequest(http.MethodGet, "/settings/profile", nil)
@@ -63,7 +75,7 @@ func TestNewAppWithSQLiteCreatesSchemaAndPersistsData(t *testing.T) {
 	if body := settingsRR.Body.String(); !strings.Contains(body, "value=\"35\"") {
 		t.Fatalf("expected persisted profile hourly wage after reload")
 	}
-	if body := settingsRR.Body.String(); !strings.Contains(body, "value=\"EUR\"") {
+	if body := settingsRR.Body.String(); !strings.Contains(body, "<option value=\"EUR\" selected>") {
 		t.Fatalf("expected persisted profile currency after reload")
 	}
 }
@@ -203,8 +215,8 @@ func TestSwitchProfileCreatesDefaultProfileRowWithoutSettingsSave(t *testing.T)
 	if switchRR.Code != http.StatusSeeOther {
 		t.Fatalf("expected switch redirect, got %d", switchRR.Code)
 	}
-	if got := switchRR.Header().Get("Location"); got != "/settings/profile" {
-		t.Fatalf("expected redirect to settings for new profile setup, got %q", got)
+	if got := switchRR.Header().Get("Location"); got != "/onboarding" {
+		t.Fatalf("expected redirect to onboarding for new profile setup, got %q", got)
 	}
 
 	reloadedApp, err := NewAppWithSQLite(dbPath)
@@ -241,7 +253,122 @@ func TestSwitchProfileCreatesDefaultProfileRowWithoutSettingsSave(t *testing.T)
 	if body := profileRR.Body.String(); !strings.Contains(body, "value=\"25\"") {
 		t.Fatalf("expected default hourly wage in auto-created profile settings")
 	}
-	if body := profileRR.Body.String(); !strings.Contains(body, "value=\"€\"") {
+	if body := profileRR.Body.String(); !strings.Contains(body, "<option value=\"EUR\" selected>") {
 		t.Fatalf("expected default currency in auto-created profile settings")
 	}
 }
+
+func TestStatusDecisionTimestampPersistsInSQLite(t *testing.T) {
+	app, cleanup := newSQLiteTestApp(t)
+	defer cleanup()
+
+	app.mu.Lock()
+	app.activeUserID = "Decider"
+	if err := app.persistProfileLocked(); err != nil {
+		app.mu.Unlock()
+		t.Fatalf("persist profile: %v", err)
+	}
+	item := Item{Title: "Desk lamp", Status: "Ready to buy", WaitPreset: "24h", PurchaseAllowedAt: time.Now().Add(-time.Hour), CreatedAt: time.Now().Add(-25 * time.Hour)}
+	if err := app.insertItemLocked(&item); err != nil {
+		app.mu.Unlock()
+		t.Fatalf("insert item: %v", err)
+	}
+	app.items = append(app.items, item)
+	app.mu.Unlock()
+
+	form := url.Values{}
+	form.Set("item_id", strconv.Itoa(item.ID))
+	form.Set("status", "Skipped")
+	req := httptest.NewRequest(http.MethodPost, "/items/status", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected redirect, got %d", rr.Code)
+	}
+
+	items, err := queryItemsForUser(app.db, "Decider")
+	if err != nil {
+		t.Fatalf("query items: %v", err)
+	}
+	if len(items) != 1 || items[0].DecidedAt.IsZero() {
+		t.Fatalf("expected decision timestamp to be persisted, got %+v", items)
+	}
+}
+
+func TestMigratePriceCentsConvertsPreCentsRows(t *testing.T) {
+	app, cleanup := newSQLiteTestApp(t)
+	defer cleanup()
+
+	if _, err := app.db.Exec(`INSERT INTO profiles(user_id, hourly_wage, currency, approval_threshold, updated_at) VALUES ('Alex', '25', 'EUR', 49.5, '')`); err != nil {
+		t.Fatalf("insert profile: %v", err)
+	}
+	if _, err := app.db.Exec(`INSERT INTO items(user_id, title, price, price_value, has_price_value, status, wait_preset, purchase_allowed_at, created_at) VALUES
+		('Alex', 'Lamp', '0.29', 0.29, 1, 'Waiting', '24h', '', ''),
+		('Alex', 'Desk', '', NULL, 0, 'Waiting', '24h', '', '')`); err != nil {
+		t.Fatalf("insert items: %v", err)
+	}
+	if _, err := app.db.Exec(`INSERT INTO items(user_id, title, price, price_cents, has_price_value, status, wait_preset, purchase_allowed_at, created_at) VALUES ('Alex', 'Chair', '80.00', 8000, 1, 'Waiting', '24h', '', '')`); err != nil {
+		t.Fatalf("insert migrated item: %v", err)
+	}
+
+	// A second run finds nothing left to convert and keeps the converted values.
+	for i := 0; i < 2; i++ {
+		if err := migratePriceCents(app.db.DB); err != nil {
+			t.Fatalf("migrate prices: %v", err)
+		}
+	}
+
+	want := map[string]int64{"Lamp": 29, "Desk": 0, "Chair": 8000}
+	rows, err := app.db.Query(`SELECT title, price_cents, price_value FROM items WHERE user_id = 'Alex'`)
+	if err != nil {
+		t.Fatalf("query items: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var title string
+		var cents int64
+		var value sql.NullFloat64
+		if err := rows.Scan(&title, &cents, &value); err != nil {
+			t.Fatalf("scan item: %v", err)
+		}
+		if cents != want[title] || value.Valid {
+			t.Fatalf("expected %s at %d cents with price_value cleared, got %d and %v", title, want[title], cents, value)
+		}
+	}
+
+	var threshold float64
+	var thresholdCents int64
+	if err := app.db.QueryRow(`SELECT approval_threshold, approval_threshold_cents FROM profiles WHERE user_id = 'Alex'`).Scan(&threshold, &thresholdCents); err != nil {
+		t.Fatalf("load threshold: %v", err)
+	}
+	if thresholdCents != 4950 || threshold != 0 {
+		t.Fatalf("expected the threshold moved to 4950 cents, got %d cents and %v left behind", thresholdCents, threshold)
+	}
+}
+
+func TestMigrateShareViewsMovesCountsOffProfiles(t *testing.T) {
+	app, cleanup := newSQLiteTestApp(t)
+	defer cleanup()
+
+	if _, err := app.db.Exec(`INSERT INTO profiles(user_id, hourly_wage, currency, share_views, share_viewed_at, updated_at) VALUES ('Alex', '25', 'EUR', 4, '2026-03-01T10:00:00Z', '')`); err != nil {
+		t.Fatalf("insert profile: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := migrateShareViews(app.db.DB); err != nil {
+			t.Fatalf("migrate share views: %v", err)
+		}
+	}
+
+	var views, legacyViews int
+	var viewedAt string
+	if err := app.db.QueryRow(`SELECT views, viewed_at FROM share_views WHERE user_id = 'Alex'`).Scan(&views, &viewedAt); err != nil {
+		t.Fatalf("load share views: %v", err)
+	}
+	if err := app.db.QueryRow(`SELECT share_views FROM profiles WHERE user_id = 'Alex'`).Scan(&legacyViews); err != nil {
+		t.Fatalf("load profile: %v", err)
+	}
+	if views != 4 || viewedAt != "2026-03-01T10:00:00Z" || legacyViews != 0 {
+		t.Fatalf("expected 4 views moved off the profile, got %d at %q and %d left", views, viewedAt, legacyViews)
+	}
+}
@@ -0,0 +1,70 @@
+package web
+
+import (
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+// homeSummary is the strip of counts at the top of the dashboard. Each count links to the filtered
+// list it was counted from.
+type homeSummary struct {
+	ReadyCount     int
+	UnlockingCount int
+	// SavedThisMonth is the share the active profile carries of the items skipped this month.
+	SavedThisMonth domain.Money
+}
+
+// normalizeWithin accepts the dashboard's "within" filter: the current week or month by the profile's
+// trend settings, or empty for no period filter.
+func normalizeWithin(raw string) string {
+	switch raw {
+	case trendGranularityWeek, trendGranularityMonth:
+		return raw
+	default:
+		return ""
+	}
+}
+
+// itemInPeriod reports whether the item's milestone falls in the period containing now: the decision
+// for bought and skipped items, and for open items an unlock that is still ahead.
+func itemInPeriod(item Item, periods trendPeriods, now time.Time) bool {
+	at := item.PurchaseAllowedAt
+	if item.Status.Decided() {
+		at = itemDecisionTime(item)
+	} else if !at.After(now) {
+		return false
+	}
+	return periods.start(at).Equal(periods.start(now))
+}
+
+func filterWithinPeriod(items []Item, periods trendPeriods, now time.Time) []Item {
+	filtered := make([]Item, 0, len(items))
+	for _, item := range items {
+		if itemInPeriod(item, periods, now) {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+// buildHomeSummary counts the items the summary strip links to. Prices are the active profile's share,
+// as on the insights page.
+func buildHomeSummary(carried []Item, week, month trendPeriods, now time.Time) homeSummary {
+	var summary homeSummary
+	for _, item := range carried {
+		switch item.Status {
+		case domain.StatusReady:
+			summary.ReadyCount++
+		case domain.StatusWaiting:
+			if itemInPeriod(item, week, now) {
+				summary.UnlockingCount++
+			}
+		case domain.StatusSkipped:
+			if item.HasPriceValue && itemInPeriod(item, month, now) {
+				summary.SavedThisMonth += item.PriceCents
+			}
+		}
+	}
+	return summary
+}
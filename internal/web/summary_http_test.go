@@ -0,0 +1,30 @@
+package web_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestHomeShowsSummaryLinkingToFilteredViews(t *testing.T) {
+	now := time.Now()
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex"}},
+		Items: []webtest.Item{
+			{Profile: "Alex", Title: "Lamp", Status: "Ready to buy", PurchaseAllowedAt: now.Add(-time.Hour)},
+			{Profile: "Alex", Title: "Kettle", PurchaseAllowedAt: now.Add(time.Minute)},
+			{Profile: "Alex", Title: "Sofa", PurchaseAllowedAt: now.AddDate(0, 2, 0)},
+			{Profile: "Alex", Title: "Drone", Price: 12000, Status: "Skipped", PurchaseAllowedAt: now, DecidedAt: now},
+		},
+	})
+	alex := h.As("Alex")
+
+	alex.Get("/").ExpectStatus(http.StatusOK).ExpectContains(
+		`<a href="/?status=Ready+to+buy"><strong>1</strong> ready to decide</a>`,
+		`<a href="/?status=Waiting&amp;within=week"><strong>1</strong> unlocking this week</a>`,
+		`<strong>€ 120.00</strong> saved this month`,
+	)
+	alex.Get("/?status=Waiting&within=week").ExpectStatus(http.StatusOK).ExpectContains("Kettle").ExpectNotContains("Sofa")
+}
@@ -0,0 +1,32 @@
+package web
+
+import (
+	"testing"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+func TestBuildHomeSummaryCountsTheCurrentWeekAndMonth(t *testing.T) {
+	now := time.Date(2026, time.March, 11, 12, 0, 0, 0, time.UTC) // a Wednesday
+	week := trendPeriods{Granularity: trendGranularityWeek, Location: time.UTC, WeekStart: time.Monday}
+	month := trendPeriods{Granularity: trendGranularityMonth, Location: time.UTC, MonthStartDay: 1}
+	items := []Item{
+		{Status: domain.StatusReady, PurchaseAllowedAt: now.Add(-time.Hour)},
+		{Status: domain.StatusWaiting, PurchaseAllowedAt: now.Add(3 * 24 * time.Hour)},
+		{Status: domain.StatusWaiting, PurchaseAllowedAt: now.Add(5 * 24 * time.Hour)},
+		{Status: domain.StatusSkipped, PriceCents: 4000, HasPriceValue: true, DecidedAt: now.AddDate(0, 0, -10)},
+		{Status: domain.StatusSkipped, PriceCents: 9900, HasPriceValue: true, DecidedAt: now.AddDate(0, -1, 0)},
+		{Status: domain.StatusBought, PriceCents: 2500, HasPriceValue: true, DecidedAt: now},
+	}
+
+	got := buildHomeSummary(items, week, month, now)
+	want := homeSummary{ReadyCount: 1, UnlockingCount: 1, SavedThisMonth: 4000}
+	if got != want {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+
+	if filtered := filterWithinPeriod(items, month, now); len(filtered) != 4 {
+		t.Fatalf("expected both waits and this month's decisions within the month, got %d items", len(filtered))
+	}
+}
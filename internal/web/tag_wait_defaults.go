@@ -0,0 +1,86 @@
+package web
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+// tagWaitPresetOptions are the wait presets a tag can default to; custom hours and dates stay per item.
+var tagWaitPresetOptions = []string{"24h", "7d", "30d"}
+
+// parseTagWaitDefaults reads the stored "Tag=preset, Tag=preset" list and drops unknown presets.
+func parseTagWaitDefaults(raw string) map[string]string {
+	defaults := map[string]string{}
+	for _, part := range strings.Split(raw, ",") {
+		tag, preset, ok := strings.Cut(part, "=")
+		tag = strings.TrimSpace(tag)
+		preset = strings.TrimSpace(preset)
+		if !ok || tag == "" || !slices.Contains(tagWaitPresetOptions, preset) {
+			continue
+		}
+		defaults[tag] = preset
+	}
+	return defaults
+}
+
+func formatTagWaitDefaults(defaults map[string]string) string {
+	tags := mapKeys(defaults)
+	slices.Sort(tags)
+	parts := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		parts = append(parts, tag+"="+defaults[tag])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// tagWaitDefault returns the longest default wait among the item's tags.
+func tagWaitDefault(rawTags string, defaults map[string]string) (string, bool) {
+	best := ""
+	var bestDuration time.Duration
+	for _, tag := range strings.Split(rawTags, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		preset, ok := lookupTagWaitDefault(defaults, tag)
+		if !ok {
+			continue
+		}
+		duration, err := domain.ParseWaitDuration(preset, "")
+		if err != nil {
+			continue
+		}
+		if duration > bestDuration {
+			best = preset
+			bestDuration = duration
+		}
+	}
+	return best, best != ""
+}
+
+func lookupTagWaitDefault(defaults map[string]string, tag string) (string, bool) {
+	for existing, preset := range defaults {
+		if strings.EqualFold(existing, tag) {
+			return preset, true
+		}
+	}
+	return "", false
+}
+
+// setTagWaitDefault stores preset for tag, or removes the default when preset is empty.
+func setTagWaitDefault(defaults map[string]string, tag, preset string) map[string]string {
+	next := make(map[string]string, len(defaults)+1)
+	for existing, existingPreset := range defaults {
+		if strings.EqualFold(existing, tag) {
+			continue
+		}
+		next[existing] = existingPreset
+	}
+	if preset != "" {
+		next[tag] = preset
+	}
+	return next
+}
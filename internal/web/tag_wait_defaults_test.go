@@ -0,0 +1,68 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTagWaitDefaultPicksLongestMatchingTag(t *testing.T) {
+	defaults := parseTagWaitDefaults("Gaming=30d, Office=24h, Broken=1y")
+	if _, ok := defaults["Broken"]; ok {
+		t.Fatalf("expected unknown preset to be dropped")
+	}
+
+	if preset, ok := tagWaitDefault("Office, gaming", defaults); !ok || preset != "30d" {
+		t.Fatalf("expected longest default 30d, got %q (%v)", preset, ok)
+	}
+	if _, ok := tagWaitDefault("Travel", defaults); ok {
+		t.Fatalf("expected no default for unconfigured tag")
+	}
+	if got := formatTagWaitDefaults(defaults); got != "Gaming=30d, Office=24h" {
+		t.Fatalf("unexpected serialized defaults %q", got)
+	}
+}
+
+func TestCreateItemUsesTagDefaultUnlessPresetChosen(t *testing.T) {
+	app := NewApp()
+	seedProfile(app)
+
+	post := func(path string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		app.Handler().ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := post("/settings/tags", url.Values{"action": {"wait"}, "tag": {"Gaming"}, "wait_preset": {"30d"}}); rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected tag default redirect, got %d", rr.Code)
+	}
+	if rr := post("/settings/tags", url.Values{"action": {"wait"}, "tag": {"Office"}, "wait_preset": {"forever"}}); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected invalid preset to be rejected, got %d", rr.Code)
+	}
+
+	before := time.Now()
+	if rr := post("/items/new", url.Values{"title": {"Console"}, "tags": {"Gaming", "Office"}, "wait_preset": {"24h"}, "wait_preset_auto": {"1"}}); rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected create redirect, got %d", rr.Code)
+	}
+	if rr := post("/items/new", url.Values{"title": {"Headset"}, "tags": {"Gaming"}, "wait_preset": {"24h"}}); rr.Code != http.StatusSeeOther {
+		t.Fatalf("expected create redirect, got %d", rr.Code)
+	}
+
+	app.mu.RLock()
+	defer app.mu.RUnlock()
+	byTitle := map[string]Item{}
+	for _, item := range app.items {
+		byTitle[item.Title] = item
+	}
+	if got := byTitle["Console"]; got.WaitPreset != "30d" || got.PurchaseAllowedAt.Before(before.Add(29*24*time.Hour)) {
+		t.Fatalf("expected tag default 30d for automatic preset, got %+v", got)
+	}
+	if got := byTitle["Headset"]; got.WaitPreset != "24h" {
+		t.Fatalf("expected explicit preset to win, got %q", got.WaitPreset)
+	}
+}
@@ -0,0 +1,42 @@
+package web_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestNewProfilesStartWithConfiguredStarterTags(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{})
+	h.App.SetStarterTags("Groceries, Kids, groceries")
+
+	alex := h.Anonymous()
+	alex.PostForm("/switch-profile", url.Values{"profile_name": {"Alex"}}).ExpectStatus(http.StatusSeeOther)
+	alex.PostForm("/settings/tags", url.Values{"action": {"add"}, "tag": {"Garden"}}).ExpectRedirect("/settings/tags?saved=1")
+
+	alex.Get("/items/new").
+		ExpectStatus(http.StatusOK).
+		ExpectContains(`value="Groceries"`, `value="Kids"`, `value="Garden"`).
+		ExpectNotContains(`value="Tech"`)
+
+	var catalog string
+	if err := h.DB.QueryRow(`SELECT tag_catalog FROM profiles WHERE user_id = 'Alex'`).Scan(&catalog); err != nil {
+		t.Fatalf("load tag catalog: %v", err)
+	}
+	if catalog != "Groceries, Kids, Garden" {
+		t.Fatalf("expected seeded and edited catalog to be persisted, got %q", catalog)
+	}
+}
+
+func TestTagSettingsResetRestoresStarterTags(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{Profiles: []webtest.Profile{{Name: "Alex"}}})
+	alex := h.As("Alex")
+	alex.PostForm("/settings/tags", url.Values{"action": {"delete"}, "tag": {"Tech"}}).ExpectRedirect("/settings/tags?saved=deleted")
+	alex.Get("/settings/tags").ExpectNotContains(`value="Tech"`)
+
+	alex.PostForm("/settings/tags", url.Values{"action": {"reset"}}).ExpectRedirect("/settings/tags?saved=reset")
+	alex.Get("/settings/tags?saved=reset").
+		ExpectContains("Starter tags restored.", `<input type="hidden" name="tag" value="Tech" />`)
+}
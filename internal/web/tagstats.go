@@ -0,0 +1,123 @@
+package web
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+// apiTagList is the body of GET /api/v1/tags. Amounts are in the profile's currency.
+type apiTagList struct {
+	Currency string        `json:"currency"`
+	Tags     []apiTagStats `json:"tags"`
+}
+
+// apiTagStats describes one tag: how many of its items are in each status, what was spent on the bought
+// ones and saved by skipping the others, and the share of decisions that were skips. SkipRatio is null
+// until an item with the tag was decided. Split items count with the profile's share of their price.
+type apiTagStats struct {
+	Tag        string          `json:"tag"`
+	ItemCount  int             `json:"item_count"`
+	Counts     apiStatusCounts `json:"counts"`
+	SpentCents int64           `json:"spent_cents"`
+	SavedCents int64           `json:"saved_cents"`
+	SkipRatio  *float64        `json:"skip_ratio"`
+}
+
+type apiStatusCounts struct {
+	Researching int `json:"researching"`
+	Waiting     int `json:"waiting"`
+	Ready       int `json:"ready"`
+	Bought      int `json:"bought"`
+	Skipped     int `json:"skipped"`
+}
+
+// apiListTags answers GET /api/v1/tags with statistics for every tag of the active profile: the tags of
+// its catalog, including unused ones, and any other tag its items carry.
+func (a *App) apiListTags(w http.ResponseWriter, r *http.Request) {
+	if !a.requireAPIProfile(w, r) {
+		return
+	}
+
+	a.mu.LockContext(r.Context())
+	now := time.Now()
+	a.promoteReadyItemsLocked(now)
+	profile := a.currentUserIDLocked()
+	out := apiTagList{
+		Currency: profileCurrencyOrDefault(a.currency),
+		Tags:     buildTagStats(itemsCarriedBy(a.items, profile), a.tagCatalog, now),
+	}
+	a.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, out)
+}
+
+// buildTagStats sums up items per tag. Tags match regardless of case and are named as in the catalog,
+// or as first written on an item. The most used tags come first.
+func buildTagStats(items []Item, catalog []string, now time.Time) []apiTagStats {
+	byKey := map[string]*apiTagStats{}
+	var keys []string
+	stats := func(tag string) *apiTagStats {
+		key := strings.ToLower(tag)
+		if s, ok := byKey[key]; ok {
+			return s
+		}
+		s := &apiTagStats{Tag: tag}
+		byKey[key] = s
+		keys = append(keys, key)
+		return s
+	}
+	for _, tag := range catalog {
+		stats(tag)
+	}
+
+	for _, item := range items {
+		seen := map[string]bool{}
+		for _, tag := range splitTags(item.Tags) {
+			s := stats(tag)
+			if seen[s.Tag] {
+				continue
+			}
+			seen[s.Tag] = true
+			s.ItemCount++
+			switch effectiveStatus(item, now) {
+			case domain.StatusResearching:
+				s.Counts.Researching++
+			case domain.StatusWaiting:
+				s.Counts.Waiting++
+			case domain.StatusReady:
+				s.Counts.Ready++
+			case domain.StatusBought:
+				s.Counts.Bought++
+				if item.HasPriceValue {
+					s.SpentCents += int64(item.PriceCents)
+				}
+			case domain.StatusSkipped:
+				s.Counts.Skipped++
+				if item.HasPriceValue {
+					s.SavedCents += int64(item.PriceCents)
+				}
+			}
+		}
+	}
+
+	out := make([]apiTagStats, 0, len(keys))
+	for _, key := range keys {
+		s := *byKey[key]
+		if decided := s.Counts.Bought + s.Counts.Skipped; decided > 0 {
+			ratio := float64(s.Counts.Skipped) / float64(decided)
+			s.SkipRatio = &ratio
+		}
+		out = append(out, s)
+	}
+	slices.SortStableFunc(out, func(a, b apiTagStats) int {
+		if a.ItemCount != b.ItemCount {
+			return b.ItemCount - a.ItemCount
+		}
+		return strings.Compare(strings.ToLower(a.Tag), strings.ToLower(b.Tag))
+	})
+	return out
+}
@@ -0,0 +1,137 @@
+package web
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"mvpapp/internal/domain"
+)
+
+const (
+	timelineAdded    = "added"
+	timelineUnlocked = "unlocked"
+	timelineBought   = "bought"
+	timelineSkipped  = "skipped"
+)
+
+// timelineEvent is one step in an item's life: added, its wait ending, or the decision.
+type timelineEvent struct {
+	At     time.Time
+	ItemID int
+	Title  string
+	Kind   string
+	Price  domain.Money
+	// HasPrice is set on decisions of priced items, so the page can show what was spent or saved.
+	HasPrice bool
+}
+
+type timelineDay struct {
+	Date   time.Time
+	Events []timelineEvent
+}
+
+type timelineViewData struct {
+	Title           string
+	CurrentPath     string
+	ContentTemplate string
+	ScriptTemplate  string
+	ActiveProfile   string
+	Currency        string
+	TagOptions      []string
+	TagFilter       string
+	Month           string
+	Days            []timelineDay
+	EventCount      int
+	Error           string
+}
+
+// buildTimeline lists the events of the items, newest first. A wait only counts as ended once it has,
+// and not after the item was already decided.
+func buildTimeline(items []Item, now time.Time) []timelineEvent {
+	events := make([]timelineEvent, 0, len(items)*2)
+	for _, item := range items {
+		events = append(events, timelineEvent{At: item.CreatedAt, ItemID: item.ID, Title: item.Title, Kind: timelineAdded})
+
+		unlocked := item.Status != domain.StatusResearching && !item.PurchaseAllowedAt.IsZero() && !item.PurchaseAllowedAt.After(now)
+		if item.Status.Decided() {
+			decided := itemDecisionTime(item)
+			unlocked = unlocked && !item.PurchaseAllowedAt.After(decided)
+			kind := timelineSkipped
+			if item.Status == domain.StatusBought {
+				kind = timelineBought
+			}
+			events = append(events, timelineEvent{At: decided, ItemID: item.ID, Title: item.Title, Kind: kind, Price: item.PriceCents, HasPrice: item.HasPriceValue})
+		}
+		if unlocked {
+			events = append(events, timelineEvent{At: item.PurchaseAllowedAt, ItemID: item.ID, Title: item.Title, Kind: timelineUnlocked})
+		}
+	}
+	slices.SortStableFunc(events, func(a, b timelineEvent) int {
+		if cmp := b.At.Compare(a.At); cmp != 0 {
+			return cmp
+		}
+		return b.ItemID - a.ItemID
+	})
+	return events
+}
+
+// groupTimelineByDay splits newest-first events into local days, with times shown in loc.
+func groupTimelineByDay(events []timelineEvent, loc *time.Location) []timelineDay {
+	var days []timelineDay
+	for _, event := range events {
+		event.At = event.At.In(loc)
+		date := time.Date(event.At.Year(), event.At.Month(), event.At.Day(), 0, 0, 0, 0, loc)
+		if len(days) == 0 || !days[len(days)-1].Date.Equal(date) {
+			days = append(days, timelineDay{Date: date})
+		}
+		days[len(days)-1].Events = append(days[len(days)-1].Events, event)
+	}
+	return days
+}
+
+// timeline tells the story of the profile's items in order. ?tag= narrows it to a tag and ?month=2006-01
+// to a month, counted in the profile's insights timezone.
+func (a *App) timeline(w http.ResponseWriter, r *http.Request) {
+	data := timelineViewData{
+		TagFilter: strings.TrimSpace(r.URL.Query().Get("tag")),
+		Month:     strings.TrimSpace(r.URL.Query().Get("month")),
+	}
+
+	a.mu.LockContext(r.Context())
+	now := time.Now()
+	a.promoteReadyItemsLocked(now)
+	loc := a.trendPeriodsLocked(trendGranularityMonth).location()
+	items := a.items
+	if data.TagFilter != "" {
+		tag := strings.ToLower(data.TagFilter)
+		items = slices.DeleteFunc(slices.Clone(items), func(item Item) bool { return !itemHasTag(item.Tags, tag) })
+	}
+	events := buildTimeline(items, now)
+	data.ActiveProfile = a.currentUserIDLocked()
+	data.Currency = profileCurrencyOrDefault(a.currency)
+	data.TagOptions = availableTagOptions(a.items, a.tagCatalog)
+	a.mu.Unlock()
+
+	if data.Month != "" {
+		month, err := time.ParseInLocation(calendarMonthLayout, data.Month, loc)
+		if err != nil {
+			data.Error = "Please pick a month like " + now.In(loc).Format(calendarMonthLayout) + "."
+			data.Month = ""
+			w.WriteHeader(http.StatusBadRequest)
+		} else {
+			next := month.AddDate(0, 1, 0)
+			events = slices.DeleteFunc(events, func(event timelineEvent) bool {
+				return event.At.Before(month) || !event.At.Before(next)
+			})
+		}
+	}
+
+	data.EventCount = len(events)
+	data.Days = groupTimelineByDay(events, loc)
+	data.Title = "Timeline"
+	data.CurrentPath = "/timeline"
+	data.ContentTemplate = "timeline_content"
+	renderTemplate(w, a.templates, "layout", data)
+}
@@ -0,0 +1,69 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildTimelineInterleavesItemEventsNewestFirst(t *testing.T) {
+	now := time.Date(2026, time.March, 20, 12, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2026, time.March, d, 9, 0, 0, 0, time.UTC) }
+	items := []Item{
+		{ID: 1, Title: "Tent", Status: "Bought", CreatedAt: day(1), PurchaseAllowedAt: day(8), DecidedAt: day(10), PriceCents: 19900, HasPriceValue: true},
+		{ID: 2, Title: "Drone", Status: "Skipped", CreatedAt: day(2), PurchaseAllowedAt: day(30), DecidedAt: day(5)},
+		{ID: 3, Title: "Kettle", Status: "Waiting", CreatedAt: day(15), PurchaseAllowedAt: day(22)},
+		{ID: 4, Title: "Idea", Status: "Researching", CreatedAt: day(3)},
+	}
+
+	var got []string
+	for _, event := range buildTimeline(items, now) {
+		got = append(got, event.At.Format("02")+" "+event.Title+" "+event.Kind)
+	}
+	want := "15 Kettle added, 10 Tent bought, 08 Tent unlocked, 05 Drone skipped, 03 Idea added, 02 Drone added, 01 Tent added"
+	if strings.Join(got, ", ") != want {
+		t.Fatalf("expected %q, got %q", want, strings.Join(got, ", "))
+	}
+
+	days := groupTimelineByDay(buildTimeline(items[:1], now), time.UTC)
+	if len(days) != 3 || len(days[0].Events) != 1 || days[0].Date.Day() != 10 {
+		t.Fatalf("expected one day per event, got %+v", days)
+	}
+}
+
+func TestTimelinePageFiltersByTagAndMonth(t *testing.T) {
+	app := NewApp()
+	seedProfile(app)
+	app.mu.Lock()
+	app.items = []Item{
+		{ID: 1, Title: "Headphones", Tags: "Audio", Status: "Skipped", CreatedAt: time.Date(2026, time.February, 3, 10, 0, 0, 0, time.Local), DecidedAt: time.Date(2026, time.February, 9, 10, 0, 0, 0, time.Local), PriceCents: 12000, HasPriceValue: true},
+		{ID: 2, Title: "Sneakers", Tags: "Fashion", Status: "Skipped", CreatedAt: time.Date(2026, time.February, 4, 10, 0, 0, 0, time.Local), DecidedAt: time.Date(2026, time.February, 5, 10, 0, 0, 0, time.Local)},
+		{ID: 3, Title: "Speaker", Tags: "Audio", Status: "Bought", CreatedAt: time.Date(2026, time.January, 4, 10, 0, 0, 0, time.Local), DecidedAt: time.Date(2026, time.January, 20, 10, 0, 0, 0, time.Local)},
+	}
+	app.mu.Unlock()
+
+	req := httptest.NewRequest(http.MethodGet, "/timeline?tag=audio&month=2026-02", nil)
+	rr := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+	body := rr.Body.String()
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	for _, want := range []string{"2 event(s)", `<a href="/items/1/edit">Headphones</a>`, "€ 120.00 saved", `value="2026-02"`} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in the timeline", want)
+		}
+	}
+	if strings.Contains(body, "Sneakers") || strings.Contains(body, "Speaker</a>") {
+		t.Fatal("expected other tags and months to be filtered out")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/timeline?month=soon", nil)
+	rr = httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected an invalid month to be rejected, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,321 @@
+package web
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"modernc.org/sqlite"
+)
+
+// tracerName identifies the spans this package creates.
+const tracerName = "mvpapp/internal/web"
+
+// outboundTransport traces the requests sent to ntfy, webhooks, push services and Firefly III.
+var outboundTransport = otelhttp.NewTransport(http.DefaultTransport)
+
+// OutboundTransport returns the transport the app sends outgoing requests with, for callers outside
+// the package that talk to the same endpoints.
+func OutboundTransport() http.RoundTripper {
+	return outboundTransport
+}
+
+// StartTracing exports spans via OTLP over HTTP. The endpoint and headers come from the standard
+// OTEL_EXPORTER_OTLP_* environment variables. Without a call, all spans are dropped at no cost.
+// The returned function flushes pending spans and should be called on shutdown.
+func StartTracing(ctx context.Context, serviceName string) (func(context.Context) error, error) {
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("create otlp exporter: %w", err)
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, fmt.Errorf("build trace resource: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	// Continue traces started by a proxy or caller, and pass them on to outbound requests.
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider.Shutdown, nil
+}
+
+// tracingMiddleware starts a server span per request, named after the matched route pattern such as
+// "GET /items/{id}/edit" so that requests for different items are grouped together.
+func tracingMiddleware(mux *http.ServeMux, next http.Handler) http.Handler {
+	return otelhttp.NewHandler(next, "http.request", otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+		if _, pattern := mux.Handler(r); pattern != "" {
+			return pattern
+		}
+		return r.Method
+	}))
+}
+
+// traceScope holds the context of the request that holds an app's state lock. The storage functions
+// predate contexts, so scopedDB passes it on with their statements instead. The scope shared with the
+// traced database driver also carries the slow query threshold, as the driver cannot take the state lock
+// its callers hold.
+type traceScope struct {
+	ctx       atomic.Pointer[context.Context]
+	slowQuery atomic.Int64
+}
+
+func (s *traceScope) setSlowQueryThreshold(threshold time.Duration) {
+	if s != nil {
+		s.slowQuery.Store(int64(threshold))
+	}
+}
+
+func (s *traceScope) slowQueryThreshold() time.Duration {
+	if s == nil {
+		return 0
+	}
+	return time.Duration(s.slowQuery.Load())
+}
+
+func (s *traceScope) set(ctx context.Context) {
+	if s == nil {
+		return
+	}
+	if ctx == nil {
+		s.ctx.Store(nil)
+		return
+	}
+	s.ctx.Store(&ctx)
+}
+
+func (s *traceScope) get() context.Context {
+	if s != nil {
+		if ctx := s.ctx.Load(); ctx != nil {
+			return *ctx
+		}
+	}
+	return context.Background()
+}
+
+// stateLock is the app state mutex. Handlers lock it with LockContext(r.Context()), which makes the
+// storage calls and outbound requests made while holding it part of the request's trace.
+type stateLock struct {
+	sync.RWMutex
+	scope *traceScope
+}
+
+// LockContext locks for writing on behalf of the request or job traced in ctx.
+func (l *stateLock) LockContext(ctx context.Context) {
+	l.Lock()
+	l.scope.set(ctx)
+}
+
+func (l *stateLock) Unlock() {
+	l.scope.set(nil)
+	l.RWMutex.Unlock()
+}
+
+// Context returns the context passed to LockContext. The caller holds the lock.
+func (l *stateLock) Context() context.Context {
+	return l.scope.get()
+}
+
+// scopedDB is the app's database. Its statements run with the context of whoever holds lock, so they
+// belong to that request's trace even while requests for other profiles hold the locks of their own
+// apps. The context is never cancelled, so a request that goes away does not abort its writes.
+type scopedDB struct {
+	*sql.DB
+	lock *stateLock
+}
+
+func (db *scopedDB) context() context.Context {
+	return context.WithoutCancel(db.lock.Context())
+}
+
+func (db *scopedDB) Exec(query string, args ...any) (sql.Result, error) {
+	return db.DB.ExecContext(db.context(), query, args...)
+}
+
+func (db *scopedDB) Query(query string, args ...any) (*sql.Rows, error) {
+	return db.DB.QueryContext(db.context(), query, args...)
+}
+
+func (db *scopedDB) QueryRow(query string, args ...any) *sql.Row {
+	return db.DB.QueryRowContext(db.context(), query, args...)
+}
+
+func (db *scopedDB) Begin() (*scopedTx, error) {
+	ctx := db.context()
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &scopedTx{Tx: tx, ctx: ctx}, nil
+}
+
+// scopedTx is a transaction of a scopedDB, running its statements with the context it began with.
+type scopedTx struct {
+	*sql.Tx
+	ctx context.Context
+}
+
+func (tx *scopedTx) Exec(query string, args ...any) (sql.Result, error) {
+	return tx.Tx.ExecContext(tx.ctx, query, args...)
+}
+
+func (tx *scopedTx) Query(query string, args ...any) (*sql.Rows, error) {
+	return tx.Tx.QueryContext(tx.ctx, query, args...)
+}
+
+func (tx *scopedTx) QueryRow(query string, args ...any) *sql.Row {
+	return tx.Tx.QueryRowContext(tx.ctx, query, args...)
+}
+
+// openTracedSQLite opens the SQLite database through a driver that records statements as child spans
+// of the request holding the state lock. Statements outside a traced request are not recorded.
+func openTracedSQLite(dsn string, scope *traceScope) *sql.DB {
+	return sql.OpenDB(tracedConnector{dsn: dsn, driver: &sqlite.Driver{}, scope: scope})
+}
+
+type tracedConnector struct {
+	dsn    string
+	driver driver.Driver
+	scope  *traceScope
+}
+
+func (c tracedConnector) Connect(context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedConn{Conn: conn, scope: c.scope}, nil
+}
+
+func (c tracedConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// tracedConn forwards to the sqlite connection, wrapping queries and statements in spans and timing
+// them for the slow query log.
+type tracedConn struct {
+	driver.Conn
+	scope *traceScope
+}
+
+// statement is a database call in flight, attributed to the caller's request or else the lock holder's.
+type statement struct {
+	parent    context.Context
+	span      trace.Span
+	operation string
+	query     string
+	args      int
+	threshold time.Duration
+	start     time.Time
+}
+
+// startStatement starts a span for the statement if its request is traced. The statement itself keeps
+// the caller's context, so a cancelled request does not abort its writes.
+func (c *tracedConn) startStatement(ctx context.Context, operation, query string, args int) *statement {
+	if !trace.SpanContextFromContext(ctx).IsValid() && requestStatsFrom(ctx) == nil {
+		ctx = c.scope.get()
+	}
+	st := &statement{parent: ctx, operation: operation, query: query, args: args, threshold: c.scope.slowQueryThreshold(), start: time.Now()}
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		_, st.span = otel.Tracer(tracerName).Start(ctx, "sqlite "+operation+" "+statementVerb(query),
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemSqlite, attribute.String("db.statement", strings.TrimSpace(query))))
+	}
+	return st
+}
+
+func (st *statement) end(err error) {
+	if err == driver.ErrSkip {
+		return
+	}
+	observeStatement(st.parent, st.threshold, st.operation, st.query, st.args, time.Since(st.start))
+	if st.span == nil {
+		return
+	}
+	if err != nil {
+		st.span.RecordError(err)
+		st.span.SetStatus(codes.Error, err.Error())
+	}
+	st.span.End()
+}
+
+// statementVerb returns the first keyword of the statement, such as SELECT or UPDATE.
+func statementVerb(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToUpper(fields[0])
+}
+
+func (c *tracedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	st := c.startStatement(ctx, "exec", query, len(args))
+	result, err := execer.ExecContext(ctx, query, args)
+	st.end(err)
+	return result, err
+}
+
+func (c *tracedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	st := c.startStatement(ctx, "query", query, len(args))
+	rows, err := queryer.QueryContext(ctx, query, args)
+	st.end(err)
+	return rows, err
+}
+
+func (c *tracedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+func (c *tracedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *tracedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *tracedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *tracedConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
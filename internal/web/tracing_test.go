@@ -0,0 +1,55 @@
+package web_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestRequestSpansContainTheirStorageCalls(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	h := webtest.New(t, webtest.Fixtures{Profiles: []webtest.Profile{{Name: "Alex"}}})
+	h.As("Alex").PostJSON("/api/v1/items", map[string]any{"title": "Desk lamp", "wait_preset": "7d"}).ExpectStatus(http.StatusCreated)
+
+	var request sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "POST /api/v1/items" {
+			request = span
+		}
+	}
+	if request == nil {
+		t.Fatalf("expected a span named after the route, got %d spans", len(recorder.Ended()))
+	}
+
+	inserts := 0
+	for _, span := range recorder.Ended() {
+		if span.Parent().SpanID() != request.SpanContext().SpanID() {
+			continue
+		}
+		if span.SpanContext().TraceID() != request.SpanContext().TraceID() {
+			t.Fatalf("expected child spans to share the request's trace")
+		}
+		if span.Name() == "sqlite exec INSERT" {
+			inserts++
+			for _, attr := range span.Attributes() {
+				if attr.Key == "db.statement" && !strings.Contains(attr.Value.AsString(), "INSERT") {
+					t.Fatalf("unexpected statement %q", attr.Value.AsString())
+				}
+			}
+		}
+	}
+	if inserts == 0 {
+		t.Fatalf("expected the item insert as a child span of the request")
+	}
+}
@@ -0,0 +1,252 @@
+package web
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	// Embedded so profile timezones resolve on minimal images without zoneinfo.
+	_ "time/tzdata"
+
+	"mvpapp/internal/domain"
+)
+
+const (
+	trendGranularityMonth = "month"
+	trendGranularityWeek  = "week"
+
+	maxMonthStartDay = 28
+)
+
+var weekStartOptions = []string{"monday", "sunday", "saturday"}
+
+// trendPeriods controls how insights trends are bucketed for a profile.
+// The zero value buckets by calendar month in server-local time.
+type trendPeriods struct {
+	Granularity   string
+	Location      *time.Location
+	WeekStart     time.Weekday
+	MonthStartDay int
+}
+
+type trendSettingsForm struct {
+	Timezone      string
+	WeekStart     string
+	MonthStartDay string
+}
+
+func normalizeTrendGranularity(raw string) string {
+	if strings.TrimSpace(raw) == trendGranularityWeek {
+		return trendGranularityWeek
+	}
+	return trendGranularityMonth
+}
+
+func normalizeWeekStart(raw string) string {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	if slices.Contains(weekStartOptions, raw) {
+		return raw
+	}
+	return weekStartOptions[0]
+}
+
+func normalizeMonthStartDay(day int) int {
+	if day < 1 || day > maxMonthStartDay {
+		return 1
+	}
+	return day
+}
+
+func weekdayFromName(name string) time.Weekday {
+	switch normalizeWeekStart(name) {
+	case "sunday":
+		return time.Sunday
+	case "saturday":
+		return time.Saturday
+	default:
+		return time.Monday
+	}
+}
+
+// parseTrendSettings validates the submitted settings and returns the values to store.
+func parseTrendSettings(form trendSettingsForm) (string, string, int, error) {
+	timezone := strings.TrimSpace(form.Timezone)
+	if timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return "", "", 0, errors.New("Please enter a valid timezone such as Europe/Berlin, or leave it empty.")
+		}
+	}
+	weekStart := strings.ToLower(strings.TrimSpace(form.WeekStart))
+	if weekStart != "" && !slices.Contains(weekStartOptions, weekStart) {
+		return "", "", 0, errors.New("Please choose a valid first day of the week.")
+	}
+	monthStartDay := 1
+	if raw := strings.TrimSpace(form.MonthStartDay); raw != "" {
+		day, err := strconv.Atoi(raw)
+		if err != nil || day < 1 || day > maxMonthStartDay {
+			return "", "", 0, errors.New("Please enter a month start day between 1 and 28.")
+		}
+		monthStartDay = day
+	}
+	return timezone, normalizeWeekStart(weekStart), monthStartDay, nil
+}
+
+func (a *App) trendPeriodsLocked(granularity string) trendPeriods {
+	periods := trendPeriods{
+		Granularity:   granularity,
+		WeekStart:     weekdayFromName(a.weekStart),
+		MonthStartDay: normalizeMonthStartDay(a.monthStartDay),
+	}
+	if a.trendTimezone != "" {
+		loc, err := time.LoadLocation(a.trendTimezone)
+		if err != nil {
+			log.Printf("invalid trend timezone %q, using server time: %v", a.trendTimezone, err)
+		} else {
+			periods.Location = loc
+		}
+	}
+	return periods
+}
+
+func (a *App) trendSettingsFormLocked() trendSettingsForm {
+	return trendSettingsForm{
+		Timezone:      a.trendTimezone,
+		WeekStart:     normalizeWeekStart(a.weekStart),
+		MonthStartDay: strconv.Itoa(normalizeMonthStartDay(a.monthStartDay)),
+	}
+}
+
+// location is the timezone days are counted in: the profile's, or server time without one.
+func (p trendPeriods) location() *time.Location {
+	if p.Location == nil {
+		return time.Local
+	}
+	return p.Location
+}
+
+// start returns the beginning of the period containing t.
+func (p trendPeriods) start(t time.Time) time.Time {
+	loc := p.location()
+	t = t.In(loc)
+
+	if p.Granularity == trendGranularityWeek {
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		offset := (int(day.Weekday()) - int(p.WeekStart) + 7) % 7
+		return day.AddDate(0, 0, -offset)
+	}
+
+	start := time.Date(t.Year(), t.Month(), normalizeMonthStartDay(p.MonthStartDay), 0, 0, 0, 0, loc)
+	if t.Before(start) {
+		start = start.AddDate(0, -1, 0)
+	}
+	return start
+}
+
+func (p trendPeriods) label(start time.Time) string {
+	switch {
+	case p.Granularity == trendGranularityWeek:
+		return "Week of " + start.Format("2006-01-02")
+	case normalizeMonthStartDay(p.MonthStartDay) > 1:
+		return start.Format("2006-01-02") + " – " + start.AddDate(0, 1, -1).Format("2006-01-02")
+	default:
+		return start.Format("2006-01")
+	}
+}
+
+// sortedPeriodStarts returns the period starts present in buckets in chronological order.
+func sortedPeriodStarts[V any](buckets map[time.Time]V) []time.Time {
+	starts := make([]time.Time, 0, len(buckets))
+	for start := range buckets {
+		starts = append(starts, start)
+	}
+	slices.SortFunc(starts, func(x, y time.Time) int { return x.Compare(y) })
+	return starts
+}
+
+func buildDecisionTrend(items []Item, periods trendPeriods) []decisionTrendPeriod {
+	buckets := map[time.Time]*decisionTrendPeriod{}
+	for _, item := range items {
+		if !item.Status.Decided() {
+			continue
+		}
+		start := periods.start(item.CreatedAt)
+		bucket, exists := buckets[start]
+		if !exists {
+			bucket = &decisionTrendPeriod{Period: periods.label(start)}
+			buckets[start] = bucket
+		}
+		if item.Status == domain.StatusBought {
+			bucket.BoughtCount++
+		} else {
+			bucket.SkippedCount++
+		}
+	}
+
+	if len(buckets) == 0 {
+		return nil
+	}
+
+	trends := make([]decisionTrendPeriod, 0, len(buckets))
+	for _, start := range sortedPeriodStarts(buckets) {
+		trends = append(trends, *buckets[start])
+	}
+	return trends
+}
+
+func buildSavedTrend(items []Item, periods trendPeriods) []savedAmountPeriod {
+	buckets := map[time.Time]domain.Money{}
+	for _, item := range items {
+		if item.Status != domain.StatusSkipped || !item.HasPriceValue {
+			continue
+		}
+		buckets[periods.start(item.CreatedAt)] += item.PriceCents
+	}
+
+	if len(buckets) == 0 {
+		return nil
+	}
+
+	trend := make([]savedAmountPeriod, 0, len(buckets))
+	for _, start := range sortedPeriodStarts(buckets) {
+		trend = append(trend, savedAmountPeriod{Period: periods.label(start), Amount: buckets[start]})
+	}
+	return trend
+}
+
+func (a *App) saveTrendSettings(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	form := trendSettingsForm{
+		Timezone:      strings.TrimSpace(r.FormValue("trend_timezone")),
+		WeekStart:     strings.TrimSpace(r.FormValue("week_start")),
+		MonthStartDay: strings.TrimSpace(r.FormValue("month_start_day")),
+	}
+	timezone, weekStart, monthStartDay, err := parseTrendSettings(form)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		a.renderInsights(w, insightsViewData{Title: "Insights", CurrentPath: "/insights", TrendSettings: form, Error: err.Error()})
+		return
+	}
+
+	a.mu.LockContext(r.Context())
+	a.trendTimezone = timezone
+	a.weekStart = weekStart
+	a.monthStartDay = monthStartDay
+	if err := a.persistProfileLocked(); err != nil {
+		a.mu.Unlock()
+		log.Printf("db error while saving trend settings: %v", err)
+		http.Error(w, "could not save trend settings", http.StatusInternalServerError)
+		return
+	}
+	a.publishProfileUpdatedLocked("trend periods", r)
+	a.mu.Unlock()
+
+	http.Redirect(w, r, "/insights?saved=trends", http.StatusSeeOther)
+}
@@ -0,0 +1,109 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildDecisionTrendUsesProfileTimezoneForMonthBoundaries(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+	// 23:30 UTC on Jan 31 is already February in Berlin.
+	items := []Item{{Status: "Skipped", CreatedAt: time.Date(2026, 1, 31, 23, 30, 0, 0, time.UTC)}}
+
+	utcTrend := buildDecisionTrend(items, trendPeriods{Location: time.UTC})
+	berlinTrend := buildDecisionTrend(items, trendPeriods{Location: berlin})
+	if utcTrend[0].Period != "2026-01" || berlinTrend[0].Period != "2026-02" {
+		t.Fatalf("unexpected periods: utc=%+v berlin=%+v", utcTrend, berlinTrend)
+	}
+}
+
+func TestBuildDecisionTrendMonthStartDay(t *testing.T) {
+	items := []Item{
+		{Status: "Bought", CreatedAt: time.Date(2026, 2, 10, 12, 0, 0, 0, time.UTC)},
+		{Status: "Skipped", CreatedAt: time.Date(2026, 2, 25, 12, 0, 0, 0, time.UTC)},
+	}
+
+	trend := buildDecisionTrend(items, trendPeriods{Location: time.UTC, MonthStartDay: 25})
+	if len(trend) != 2 {
+		t.Fatalf("expected 2 periods, got %+v", trend)
+	}
+	if trend[0].Period != "2026-01-25 – 2026-02-24" || trend[0].BoughtCount != 1 {
+		t.Fatalf("unexpected first period: %+v", trend[0])
+	}
+	if trend[1].Period != "2026-02-25 – 2026-03-24" || trend[1].SkippedCount != 1 {
+		t.Fatalf("unexpected second period: %+v", trend[1])
+	}
+}
+
+func TestBuildSavedTrendWeeklyRespectsFirstDayOfWeek(t *testing.T) {
+	// 2026-03-01 is a Sunday.
+	items := []Item{
+		{Status: "Skipped", HasPriceValue: true, PriceCents: 1000, CreatedAt: time.Date(2026, 2, 28, 12, 0, 0, 0, time.UTC)},
+		{Status: "Skipped", HasPriceValue: true, PriceCents: 2000, CreatedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)},
+	}
+
+	mondayTrend := buildSavedTrend(items, trendPeriods{Granularity: trendGranularityWeek, Location: time.UTC, WeekStart: time.Monday})
+	if len(mondayTrend) != 1 || mondayTrend[0].Period != "Week of 2026-02-23" || mondayTrend[0].Amount != 3000 {
+		t.Fatalf("unexpected monday-based weeks: %+v", mondayTrend)
+	}
+
+	sundayTrend := buildSavedTrend(items, trendPeriods{Granularity: trendGranularityWeek, Location: time.UTC, WeekStart: time.Sunday})
+	if len(sundayTrend) != 2 || sundayTrend[1].Period != "Week of 2026-03-01" || sundayTrend[1].Amount != 2000 {
+		t.Fatalf("unexpected sunday-based weeks: %+v", sundayTrend)
+	}
+}
+
+func TestSaveTrendSettingsPersistsAndValidates(t *testing.T) {
+	app, cleanup := newSQLiteTestApp(t)
+	defer cleanup()
+	seedProfile(app)
+
+	post := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/insights", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		app.Handler().ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := post(url.Values{"trend_timezone": {"Mars/Olympus"}, "week_start": {"monday"}, "month_start_day": {"1"}})
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "valid timezone") {
+		t.Fatalf("expected timezone validation error, got %d", rr.Code)
+	}
+	rr = post(url.Values{"week_start": {"monday"}, "month_start_day": {"31"}})
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for month start day 31, got %d", rr.Code)
+	}
+
+	rr = post(url.Values{"trend_timezone": {"America/New_York"}, "week_start": {"sunday"}, "month_start_day": {"25"}})
+	if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/insights?saved=trends" {
+		t.Fatalf("expected redirect after save, got %d %q", rr.Code, rr.Header().Get("Location"))
+	}
+
+	app.mu.Lock()
+	userID := app.currentUserIDLocked()
+	app.trendTimezone, app.weekStart, app.monthStartDay = "", "", 0
+	if err := app.loadStateFromDB(userID); err != nil {
+		app.mu.Unlock()
+		t.Fatalf("reload profile: %v", err)
+	}
+	got := app.trendSettingsFormLocked()
+	app.mu.Unlock()
+	if got.Timezone != "America/New_York" || got.WeekStart != "sunday" || got.MonthStartDay != "25" {
+		t.Fatalf("unexpected persisted trend settings: %+v", got)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/insights?period=week", nil)
+	rr = httptest.NewRecorder()
+	app.Handler().ServeHTTP(rr, req)
+	if body := rr.Body.String(); !strings.Contains(body, "Weekly decision trend") || !strings.Contains(body, `<option value="sunday" selected>`) {
+		t.Fatalf("expected weekly trend view with saved settings")
+	}
+}
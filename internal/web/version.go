@@ -0,0 +1,60 @@
+package web
+
+import (
+	"net/http"
+	"runtime"
+	"runtime/debug"
+)
+
+// Build information, stamped in at link time:
+//
+//	go build -ldflags "-X mvpapp/internal/web.Version=1.4.0 -X mvpapp/internal/web.Commit=$(git rev-parse --short HEAD) -X mvpapp/internal/web.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/server
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildDate = ""
+)
+
+// BuildInfo identifies the running build, for bug reports. It is the body of GET /version.
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+}
+
+// CurrentBuild returns the stamped build information. Builds without ldflags fall back to the VCS
+// revision and commit time Go records itself, which go build includes when run inside a checkout.
+func CurrentBuild() BuildInfo {
+	info := BuildInfo{Version: Version, Commit: Commit, BuildDate: BuildDate, GoVersion: runtime.Version()}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range bi.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = setting.Value
+				if len(info.Commit) > 12 {
+					info.Commit = info.Commit[:12]
+				}
+			case setting.Key == "vcs.time" && info.BuildDate == "":
+				info.BuildDate = setting.Value
+			}
+		}
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	return info
+}
+
+// String is the one-line form used in the page footer and the startup log, e.g. "1.4.0 (3f2a9c1, 2026-10-16T08:00:00Z)".
+func (b BuildInfo) String() string {
+	s := b.Version + " (" + b.Commit
+	if b.BuildDate != "" {
+		s += ", " + b.BuildDate
+	}
+	return s + ")"
+}
+
+func (a *App) version(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, CurrentBuild())
+}
@@ -0,0 +1,76 @@
+package web_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"mvpapp/internal/domain"
+	"mvpapp/internal/web/webtest"
+)
+
+func TestFreeTextWaitsAreResolvedWhenSaved(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{Profiles: []webtest.Profile{{Name: "Alex"}}})
+	alex := h.As("Alex")
+
+	alex.Get("/items/new").ExpectContains(`<option value="text"`, `id="wait_text"`, `id="wait_text-preview" class="form-text" aria-live="polite"`)
+	alex.PostForm("/items/new", url.Values{"title": {"Kayak"}, "wait_preset": {"text"}, "wait_text": {"3 weeks"}}).ExpectRedirect("/")
+
+	var preset, allowedRaw string
+	if err := h.DB.QueryRow(`SELECT wait_preset, purchase_allowed_at FROM items WHERE title = 'Kayak'`).Scan(&preset, &allowedRaw); err != nil {
+		t.Fatalf("load item: %v", err)
+	}
+	allowed, err := time.Parse(time.RFC3339Nano, allowedRaw)
+	if err != nil {
+		t.Fatalf("parse purchase_allowed_at %q: %v", allowedRaw, err)
+	}
+	if wait := time.Until(allowed); preset != "date" || wait < 20*24*time.Hour || wait > 21*24*time.Hour {
+		t.Fatalf("expected a date wait three weeks ahead, got %q until %v", preset, allowed)
+	}
+
+	alex.PostForm("/items/new", url.Values{"title": {"Tent"}, "wait_preset": {"text"}, "wait_text": {"someday"}}).
+		ExpectStatus(http.StatusBadRequest).
+		ExpectContains(`id="wait_text-error"`, "is not a wait we understand", `value="someday"`)
+
+	alex.Get("/api/v1/wait-preview?text=2+days&timezone_offset_minutes=0").ExpectStatus(http.StatusOK).ExpectContains(`"label":"Buy after `)
+	alex.Get("/api/v1/wait-preview?text=someday").ExpectStatus(http.StatusUnprocessableEntity).ExpectContains(`"field":"wait_text"`)
+
+	alex.PostJSON("/api/v1/items", map[string]any{"title": "Canoe", "wait_text": "in 2 days"}).ExpectStatus(http.StatusCreated)
+	alex.PostJSON("/api/v1/items", map[string]any{"title": "Raft", "wait_text": "someday"}).
+		ExpectStatus(http.StatusUnprocessableEntity).ExpectContains(`"field":"wait_text"`)
+}
+
+func TestPaydayWaitEndsOnTheNextPayday(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{Profiles: []webtest.Profile{{Name: "Alex", HourlyWage: "25"}}})
+	alex := h.As("Alex")
+
+	alex.Get("/items/new").ExpectNotContains(`<option value="payday"`)
+	alex.PostForm("/items/new", url.Values{"title": {"Drone"}, "wait_preset": {"payday"}}).
+		ExpectStatus(http.StatusBadRequest).ExpectContains("set your payday in the settings")
+
+	alex.PostForm("/settings/profile", url.Values{"profile_name": {"Alex"}, "hourly_wage": {"25"}, "payday": {"32"}}).
+		ExpectStatus(http.StatusBadRequest).ExpectContains(`id="payday-error"`, "between 1 and 31")
+	alex.PostForm("/settings/profile", url.Values{"profile_name": {"Alex"}, "hourly_wage": {"25"}, "payday": {"25"}}).
+		ExpectRedirect("/settings/profile?saved=1")
+	alex.Get("/settings/profile").ExpectContains(`id="payday"`, `value="25"`)
+	alex.Get("/items/new").ExpectContains(`<option value="payday" >Until after payday (day 25)</option>`)
+
+	alex.PostForm("/items/new", url.Values{"title": {"Drone"}, "wait_preset": {"payday"}}).ExpectRedirect("/")
+	alex.PostForm("/items/new", url.Values{"title": {"Gimbal"}, "wait_preset": {"text"}, "wait_text": {"until payday"}}).ExpectRedirect("/")
+
+	want := domain.NextPayday(25, time.Now())
+	for title, wantPreset := range map[string]string{"Drone": "payday", "Gimbal": "date"} {
+		var preset, allowedRaw string
+		if err := h.DB.QueryRow(`SELECT wait_preset, purchase_allowed_at FROM items WHERE title = ?`, title).Scan(&preset, &allowedRaw); err != nil {
+			t.Fatalf("load %s: %v", title, err)
+		}
+		allowed, err := time.Parse(time.RFC3339Nano, allowedRaw)
+		if err != nil {
+			t.Fatalf("parse purchase_allowed_at %q: %v", allowedRaw, err)
+		}
+		if preset != wantPreset || !allowed.Equal(want) {
+			t.Errorf("%s: expected %q until %v, got %q until %v", title, wantPreset, want, preset, allowed)
+		}
+	}
+}
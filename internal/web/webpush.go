@@ -0,0 +1,430 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// webPushTTL is how long a push service keeps a notification for an offline device.
+const webPushTTL = 24 * time.Hour
+
+// webPushKeys are the VAPID keys that identify this server to push services.
+type webPushKeys struct {
+	PublicKey  string
+	privateKey *ecdsa.PrivateKey
+	Subject    string
+}
+
+// pushSubscription is what a browser's PushSubscription.toJSON() returns, and the body of
+// POST /api/v1/push/subscriptions. ExpirationTime is in milliseconds since the epoch.
+type pushSubscription struct {
+	Endpoint       string `json:"endpoint"`
+	ExpirationTime *int64 `json:"expirationTime"`
+	Keys           struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+// storedPushSubscription is a registered device of a profile.
+type storedPushSubscription struct {
+	Endpoint  string
+	P256dh    []byte
+	Auth      []byte
+	ExpiresAt time.Time
+}
+
+// webPushMessage is the JSON payload a service worker or app receives. ItemID is 0 when the message
+// announces several items.
+type webPushMessage struct {
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	ItemID int    `json:"item_id"`
+	URL    string `json:"url"`
+}
+
+// errPushSubscriptionGone means the push service no longer knows the subscription.
+var errPushSubscriptionGone = errors.New("push subscription is gone")
+
+// SetWebPushKeys enables web push with a VAPID key pair, both base64url encoded: the uncompressed P-256
+// public key and the private key's 32-byte scalar. The subject is a mailto: or https: contact for push
+// services. Web push stays disabled when both keys are empty.
+func (a *App) SetWebPushKeys(publicKey, privateKey, subject string) error {
+	publicKey = strings.TrimSpace(publicKey)
+	privateKey = strings.TrimSpace(privateKey)
+	if publicKey == "" && privateKey == "" {
+		a.mu.Lock()
+		a.webPush = nil
+		a.mu.Unlock()
+		return nil
+	}
+
+	keys, err := parseWebPushKeys(publicKey, privateKey, strings.TrimSpace(subject))
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	a.webPush = keys
+	a.mu.Unlock()
+	return nil
+}
+
+// CheckWebPushKeys reports why SetWebPushKeys would reject the keys, so configuration can be checked
+// before an App exists.
+func CheckWebPushKeys(publicKey, privateKey, subject string) error {
+	publicKey = strings.TrimSpace(publicKey)
+	privateKey = strings.TrimSpace(privateKey)
+	if publicKey == "" && privateKey == "" {
+		return nil
+	}
+	_, err := parseWebPushKeys(publicKey, privateKey, strings.TrimSpace(subject))
+	return err
+}
+
+func parseWebPushKeys(publicKey, privateKey, subject string) (*webPushKeys, error) {
+	if !strings.HasPrefix(subject, "mailto:") && !strings.HasPrefix(subject, "https://") {
+		return nil, errors.New("VAPID subject must be a mailto: or https: URL")
+	}
+	scalar, err := decodeBase64URL(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode VAPID private key: %w", err)
+	}
+	key, err := ecdh.P256().NewPrivateKey(scalar)
+	if err != nil {
+		return nil, fmt.Errorf("parse VAPID private key: %w", err)
+	}
+	public := key.PublicKey().Bytes()
+	if encodeBase64URL(public) != strings.TrimRight(publicKey, "=") {
+		return nil, errors.New("VAPID public key does not belong to the private key")
+	}
+
+	return &webPushKeys{
+		PublicKey: encodeBase64URL(public),
+		privateKey: &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(public[1:33]), Y: new(big.Int).SetBytes(public[33:])},
+			D:         new(big.Int).SetBytes(scalar),
+		},
+		Subject: subject,
+	}, nil
+}
+
+func decodeBase64URL(raw string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(raw, "="))
+}
+
+func encodeBase64URL(raw []byte) string {
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// parsePushSubscription validates a subscription sent by a client.
+func parsePushSubscription(sub pushSubscription) (storedPushSubscription, error) {
+	endpoint := strings.TrimSpace(sub.Endpoint)
+	parsed, err := url.Parse(endpoint)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return storedPushSubscription{}, errors.New("endpoint must be an http or https URL")
+	}
+	p256dh, err := decodeBase64URL(sub.Keys.P256dh)
+	if err != nil || len(p256dh) != 65 || p256dh[0] != 4 {
+		return storedPushSubscription{}, errors.New("keys.p256dh must be an uncompressed P-256 public key")
+	}
+	if _, err := ecdh.P256().NewPublicKey(p256dh); err != nil {
+		return storedPushSubscription{}, errors.New("keys.p256dh must be an uncompressed P-256 public key")
+	}
+	auth, err := decodeBase64URL(sub.Keys.Auth)
+	if err != nil || len(auth) != 16 {
+		return storedPushSubscription{}, errors.New("keys.auth must be a 16-byte secret")
+	}
+
+	stored := storedPushSubscription{Endpoint: endpoint, P256dh: p256dh, Auth: auth}
+	if sub.ExpirationTime != nil {
+		stored.ExpiresAt = time.UnixMilli(*sub.ExpirationTime)
+	}
+	return stored, nil
+}
+
+// apiPushPublicKey returns the VAPID public key clients pass to PushManager.subscribe as applicationServerKey.
+func (a *App) apiPushPublicKey(w http.ResponseWriter, r *http.Request) {
+	a.mu.RLock()
+	keys := a.webPush
+	a.mu.RUnlock()
+	if keys == nil {
+		writeAPIError(w, http.StatusNotFound, "web push is not configured")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"public_key": keys.PublicKey})
+}
+
+// apiRegisterPushSubscription stores a device for the active profile. Registering a known endpoint again
+// updates its keys and moves it to the active profile.
+func (a *App) apiRegisterPushSubscription(w http.ResponseWriter, r *http.Request) {
+	if !a.requireAPIProfile(w, r) {
+		return
+	}
+
+	var input pushSubscription
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxAPIBodyBytes)).Decode(&input); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	sub, err := parsePushSubscription(input)
+	if err != nil {
+		writeAPIError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	a.mu.LockContext(r.Context())
+	defer a.mu.Unlock()
+	if a.webPush == nil {
+		writeAPIError(w, http.StatusNotFound, "web push is not configured")
+		return
+	}
+	if a.db == nil {
+		writeAPIError(w, http.StatusConflict, "web push requires persistent storage")
+		return
+	}
+	if err := a.savePushSubscriptionLocked(sub); err != nil {
+		log.Printf("db error while saving push subscription: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "could not save subscription")
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]string{"endpoint": sub.Endpoint})
+}
+
+// apiUnregisterPushSubscription removes one of the active profile's devices. The body only needs the endpoint.
+func (a *App) apiUnregisterPushSubscription(w http.ResponseWriter, r *http.Request) {
+	if !a.requireAPIProfile(w, r) {
+		return
+	}
+
+	var input pushSubscription
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxAPIBodyBytes)).Decode(&input); err != nil || strings.TrimSpace(input.Endpoint) == "" {
+		writeAPIError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+
+	a.mu.LockContext(r.Context())
+	removed, err := a.deletePushSubscriptionLocked(a.currentUserIDLocked(), strings.TrimSpace(input.Endpoint))
+	a.mu.Unlock()
+	if err != nil {
+		log.Printf("db error while deleting push subscription: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "could not delete subscription")
+		return
+	}
+	if !removed {
+		writeAPIError(w, http.StatusNotFound, "unknown subscription")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// sendWebPushLocked notifies every device of the active profile that items are ready to buy. Expired
+// subscriptions and those the push service reports as gone are removed. It fails only when no device
+// got the message, so a retry never reaches a device twice. The status code is that of a device that got
+// it, or else of the last push service that answered.
+func (a *App) sendWebPushLocked(items ...Item) (int, error) {
+	if a.webPush == nil || a.db == nil {
+		return 0, errChannelNotConfigured
+	}
+
+	userID := a.currentUserIDLocked()
+	subs, err := a.pushSubscriptionsLocked(userID)
+	if err != nil {
+		return 0, fmt.Errorf("load push subscriptions: %w", err)
+	}
+	if len(subs) == 0 {
+		return 0, errChannelNotConfigured
+	}
+
+	message := webPushMessage{
+		Title: "Impulse Pause reminder",
+		Body:  readyMessage(items),
+		URL:   a.dashboardLink(),
+	}
+	if len(items) == 1 {
+		message.ItemID = items[0].ID
+	}
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return 0, fmt.Errorf("encode web push message: %w", err)
+	}
+	if a.notificationDryRunLocked(effectWebPush, fmt.Sprintf("%d devices", len(subs)), string(payload)) {
+		return 0, nil
+	}
+
+	now := time.Now()
+	delivered := false
+	status := 0
+	var errs []error
+	for _, sub := range subs {
+		code, err := 0, errPushSubscriptionGone
+		if sub.ExpiresAt.IsZero() || sub.ExpiresAt.After(now) {
+			code, err = sendWebPush(a.mu.Context(), a.webPush, sub, payload, now)
+		}
+		if code != 0 && !delivered {
+			status = code
+		}
+		if errors.Is(err, errPushSubscriptionGone) {
+			if _, err := a.deletePushSubscriptionLocked(userID, sub.Endpoint); err != nil {
+				log.Printf("db error while removing dead push subscription: %v", err)
+			}
+			continue
+		}
+		if err != nil {
+			log.Printf("web push failed for item %d: %v", items[0].ID, err)
+			errs = append(errs, err)
+			continue
+		}
+		delivered = true
+	}
+	if delivered {
+		return status, nil
+	}
+	if len(errs) == 0 {
+		// Every device was gone.
+		return status, errChannelNotConfigured
+	}
+	return status, errors.Join(errs...)
+}
+
+// sendWebPush delivers an encrypted message to one device and returns the push service's status code.
+func sendWebPush(ctx context.Context, keys *webPushKeys, sub storedPushSubscription, payload []byte, now time.Time) (int, error) {
+	body, err := encryptWebPushPayload(sub, payload)
+	if err != nil {
+		return 0, fmt.Errorf("encrypt payload: %w", err)
+	}
+	authorization, err := vapidAuthorization(keys, sub.Endpoint, now)
+	if err != nil {
+		return 0, fmt.Errorf("sign vapid token: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", fmt.Sprint(int(webPushTTL.Seconds())))
+
+	client := &http.Client{Timeout: 2 * time.Second, Transport: outboundTransport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return resp.StatusCode, errPushSubscriptionGone
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return resp.StatusCode, fmt.Errorf("push service returned %d: %s", resp.StatusCode, strings.TrimSpace(string(text)))
+	}
+	return resp.StatusCode, nil
+}
+
+// vapidAuthorization builds the Authorization header of RFC 8292: an ES256 JWT for the push service's origin.
+func vapidAuthorization(keys *webPushKeys, endpoint string, now time.Time) (string, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	header := encodeBase64URL([]byte(`{"typ":"JWT","alg":"ES256"}`))
+	claims, err := json.Marshal(map[string]any{
+		"aud": parsed.Scheme + "://" + parsed.Host,
+		"exp": now.Add(12 * time.Hour).Unix(),
+		"sub": keys.Subject,
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := header + "." + encodeBase64URL(claims)
+
+	digest := sha256.Sum256([]byte(unsigned))
+	r, s, err := ecdsa.Sign(rand.Reader, keys.privateKey, digest[:])
+	if err != nil {
+		return "", err
+	}
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+	return fmt.Sprintf("vapid t=%s.%s, k=%s", unsigned, encodeBase64URL(signature), keys.PublicKey), nil
+}
+
+// encryptWebPushPayload encrypts the payload for the subscription as a single aes128gcm record (RFC 8291),
+// with a new server key and salt for every message.
+func encryptWebPushPayload(sub storedPushSubscription, payload []byte) ([]byte, error) {
+	serverPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return sealWebPushPayload(sub, payload, serverPrivate, salt)
+}
+
+// sealWebPushPayload does the work of encryptWebPushPayload with the given server key and salt.
+func sealWebPushPayload(sub storedPushSubscription, payload []byte, serverPrivate *ecdh.PrivateKey, salt []byte) ([]byte, error) {
+	devicePublic, err := ecdh.P256().NewPublicKey(sub.P256dh)
+	if err != nil {
+		return nil, err
+	}
+	sharedSecret, err := serverPrivate.ECDH(devicePublic)
+	if err != nil {
+		return nil, err
+	}
+	serverPublic := serverPrivate.PublicKey().Bytes()
+
+	keyInfo := append(append([]byte("WebPush: info\x00"), sub.P256dh...), serverPublic...)
+	ikm := hkdfSHA256(sub.Auth, sharedSecret, keyInfo, 32)
+	contentKey := hkdfSHA256(salt, ikm, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	nonce := hkdfSHA256(salt, ikm, []byte("Content-Encoding: nonce\x00"), 12)
+
+	block, err := aes.NewCipher(contentKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// 0x02 marks the last (and only) record.
+	ciphertext := gcm.Seal(nil, nonce, append(append([]byte(nil), payload...), 2), nil)
+
+	header := make([]byte, 0, 16+4+1+len(serverPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, 4096)
+	header = append(header, byte(len(serverPublic)))
+	header = append(header, serverPublic...)
+	return append(header, ciphertext...), nil
+}
+
+// hkdfSHA256 derives length bytes with HKDF-SHA256. Reading fails only beyond 255 hash lengths, far more
+// than the keys and nonces derived here.
+func hkdfSHA256(salt, secret, info []byte, length int) []byte {
+	out := make([]byte, length)
+	_, _ = io.ReadFull(hkdf.New(sha256.New, secret, salt, info), out)
+	return out
+}
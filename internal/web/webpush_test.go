@@ -0,0 +1,285 @@
+package web
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testPushDevice struct {
+	key  *ecdh.PrivateKey
+	auth []byte
+}
+
+func newTestPushDevice(t *testing.T) testPushDevice {
+	t.Helper()
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate device key: %v", err)
+	}
+	auth := make([]byte, 16)
+	if _, err := rand.Read(auth); err != nil {
+		t.Fatalf("generate auth secret: %v", err)
+	}
+	return testPushDevice{key: key, auth: auth}
+}
+
+func (d testPushDevice) subscription(endpoint string) string {
+	body, _ := json.Marshal(map[string]any{
+		"endpoint":       endpoint,
+		"expirationTime": nil,
+		"keys":           map[string]string{"p256dh": encodeBase64URL(d.key.PublicKey().Bytes()), "auth": encodeBase64URL(d.auth)},
+	})
+	return string(body)
+}
+
+// decrypt reverses encryptWebPushPayload the way a browser does.
+func (d testPushDevice) decrypt(t *testing.T, body []byte) []byte {
+	t.Helper()
+	salt, idLength := body[:16], int(body[20])
+	serverPublic, err := ecdh.P256().NewPublicKey(body[21 : 21+idLength])
+	if err != nil {
+		t.Fatalf("parse server key: %v", err)
+	}
+	shared, err := d.key.ECDH(serverPublic)
+	if err != nil {
+		t.Fatalf("derive shared secret: %v", err)
+	}
+	keyInfo := append(append([]byte("WebPush: info\x00"), d.key.PublicKey().Bytes()...), serverPublic.Bytes()...)
+	ikm := hkdfSHA256(d.auth, shared, keyInfo, 32)
+	block, _ := aes.NewCipher(hkdfSHA256(salt, ikm, []byte("Content-Encoding: aes128gcm\x00"), 16))
+	gcm, _ := cipher.NewGCM(block)
+	plaintext, err := gcm.Open(nil, hkdfSHA256(salt, ikm, []byte("Content-Encoding: nonce\x00"), 12), body[21+idLength:], nil)
+	if err != nil {
+		t.Fatalf("decrypt payload: %v", err)
+	}
+	return bytes.TrimSuffix(plaintext, []byte{2})
+}
+
+func newTestVAPIDKeys(t *testing.T) (publicKey, privateKey string) {
+	t.Helper()
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate vapid key: %v", err)
+	}
+	return encodeBase64URL(key.PublicKey().Bytes()), encodeBase64URL(key.Bytes())
+}
+
+func verifyVAPIDAuthorization(t *testing.T, header, publicKey string) {
+	t.Helper()
+	token, key, ok := strings.Cut(strings.TrimPrefix(header, "vapid t="), ", k=")
+	if !ok || key != publicKey {
+		t.Fatalf("unexpected authorization header %q", header)
+	}
+	lastDot := strings.LastIndex(token, ".")
+	signature, err := decodeBase64URL(token[lastDot+1:])
+	if err != nil || len(signature) != 64 {
+		t.Fatalf("unexpected signature in %q", header)
+	}
+	public, _ := decodeBase64URL(publicKey)
+	ecdsaKey := ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(public[1:33]), Y: new(big.Int).SetBytes(public[33:])}
+	digest := sha256.Sum256([]byte(token[:lastDot]))
+	if !ecdsa.Verify(&ecdsaKey, digest[:], new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])) {
+		t.Fatalf("vapid signature does not verify")
+	}
+}
+
+// TestWebPushEncryptionMatchesRFC8291Example encrypts the message of RFC 8291 Appendix A with its keys
+// and salt and expects the exact bytes given there, which also pins the HKDF steps the decrypt helper repeats.
+func TestWebPushEncryptionMatchesRFC8291Example(t *testing.T) {
+	decode := func(s string) []byte {
+		t.Helper()
+		b, err := decodeBase64URL(s)
+		if err != nil {
+			t.Fatalf("decode %q: %v", s, err)
+		}
+		return b
+	}
+	serverKey, err := ecdh.P256().NewPrivateKey(decode("yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"))
+	if err != nil {
+		t.Fatalf("parse server key: %v", err)
+	}
+	deviceKey, err := ecdh.P256().NewPrivateKey(decode("q1dXpw3UpT5VOmu_cf_v6ih07Aems3njxI-JWgLcM94"))
+	if err != nil {
+		t.Fatalf("parse device key: %v", err)
+	}
+	device := testPushDevice{key: deviceKey, auth: decode("BTBZMqHH6r4Tts7J_aSIgg")}
+	salt := decode("DGv6ra1nlYgDCS1FRnbzlw")
+	plaintext := []byte("When I grow up, I want to be a watermelon")
+
+	shared, err := serverKey.ECDH(deviceKey.PublicKey())
+	if err != nil {
+		t.Fatalf("derive shared secret: %v", err)
+	}
+	keyInfo := append(append([]byte("WebPush: info\x00"), deviceKey.PublicKey().Bytes()...), serverKey.PublicKey().Bytes()...)
+	ikm := hkdfSHA256(device.auth, shared, keyInfo, 32)
+	for _, step := range []struct{ name, got, want string }{
+		{"IKM", encodeBase64URL(ikm), "S4lYMb_L0FxCeq0WhDx813KgSYqU26kOyzWUdsXYyrg"},
+		{"CEK", encodeBase64URL(hkdfSHA256(salt, ikm, []byte("Content-Encoding: aes128gcm\x00"), 16)), "oIhVW04MRdy2XN9CiKLxTg"},
+		{"NONCE", encodeBase64URL(hkdfSHA256(salt, ikm, []byte("Content-Encoding: nonce\x00"), 12)), "4h_95klXJ5E_qnoN"},
+	} {
+		if step.got != step.want {
+			t.Errorf("%s: got %s, want %s", step.name, step.got, step.want)
+		}
+	}
+
+	sub := storedPushSubscription{P256dh: deviceKey.PublicKey().Bytes(), Auth: device.auth}
+	body, err := sealWebPushPayload(sub, plaintext, serverKey, salt)
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	want := "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN"
+	if got := encodeBase64URL(body); got != want {
+		t.Fatalf("unexpected message\n got %s\nwant %s", got, want)
+	}
+	if got := device.decrypt(t, body); !bytes.Equal(got, plaintext) {
+		t.Fatalf("expected the device to read %q, got %q", plaintext, got)
+	}
+}
+
+func TestWebPushNotifiesRegisteredDevicesAndDropsDeadOnes(t *testing.T) {
+	app, cleanup := newSQLiteTestApp(t)
+	defer cleanup()
+
+	publicKey, privateKey := newTestVAPIDKeys(t)
+	if err := app.SetWebPushKeys(publicKey, privateKey, "mailto:ops@example.com"); err != nil {
+		t.Fatalf("set web push keys: %v", err)
+	}
+
+	device := newTestPushDevice(t)
+	pushStatus := http.StatusCreated
+	var messages []webPushMessage
+	pushService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "aes128gcm" || r.Header.Get("TTL") == "" {
+			t.Errorf("unexpected push headers %v", r.Header)
+		}
+		verifyVAPIDAuthorization(t, r.Header.Get("Authorization"), publicKey)
+		body, _ := io.ReadAll(r.Body)
+		var message webPushMessage
+		if err := json.Unmarshal(device.decrypt(t, body), &message); err != nil {
+			t.Errorf("decode push message: %v", err)
+		}
+		messages = append(messages, message)
+		w.WriteHeader(pushStatus)
+	}))
+	defer pushService.Close()
+
+	app.mu.Lock()
+	app.activeUserID = "Alex"
+	if err := app.persistProfileLocked(); err != nil {
+		app.mu.Unlock()
+		t.Fatalf("persist profile: %v", err)
+	}
+	app.mu.Unlock()
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.AddCookie(&http.Cookie{Name: "active_profile", Value: "Alex"})
+		rr := httptest.NewRecorder()
+		app.Handler().ServeHTTP(rr, req)
+		return rr
+	}
+	if rr := serve(http.MethodGet, "/api/v1/push/public-key", ""); rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), publicKey) {
+		t.Fatalf("expected the public key, got %d %s", rr.Code, rr.Body.String())
+	}
+	if rr := serve(http.MethodPost, "/api/v1/push/subscriptions", device.subscription(pushService.URL+"/push/1")); rr.Code != http.StatusCreated {
+		t.Fatalf("expected subscription to be registered, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	readyItem := func(title string) {
+		app.mu.Lock()
+		defer app.mu.Unlock()
+		item := Item{Title: title, Status: "Waiting", WaitPreset: "24h", PurchaseAllowedAt: time.Now().Add(-time.Minute), CreatedAt: time.Now().Add(-24 * time.Hour)}
+		if err := app.insertItemLocked(&item); err != nil {
+			t.Fatalf("insert item: %v", err)
+		}
+		app.items = append(app.items, item)
+	}
+
+	readyItem("Headphones")
+	serve(http.MethodGet, "/", "")
+	runOutbox(app)
+	if len(messages) != 1 || messages[0].Body != "Headphones is now ready to buy." {
+		t.Fatalf("expected one push message, got %+v", messages)
+	}
+
+	pushStatus = http.StatusGone
+	readyItem("Bike")
+	serve(http.MethodGet, "/", "")
+	runOutbox(app)
+	readyItem("Lamp")
+	serve(http.MethodGet, "/", "")
+	runOutbox(app)
+	if len(messages) != 2 {
+		t.Fatalf("expected the gone subscription to be dropped after one attempt, got %d messages", len(messages))
+	}
+}
+
+func TestWebPushSubscriptionsCanBeRemoved(t *testing.T) {
+	app, cleanup := newSQLiteTestApp(t)
+	defer cleanup()
+
+	serve := func(method, path, body string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.AddCookie(&http.Cookie{Name: "active_profile", Value: "Alex"})
+		rr := httptest.NewRecorder()
+		app.Handler().ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	app.mu.Lock()
+	app.activeUserID = "Alex"
+	if err := app.persistProfileLocked(); err != nil {
+		app.mu.Unlock()
+		t.Fatalf("persist profile: %v", err)
+	}
+	app.mu.Unlock()
+
+	device := newTestPushDevice(t)
+	if code := serve(http.MethodGet, "/api/v1/push/public-key", ""); code != http.StatusNotFound {
+		t.Fatalf("expected web push to be disabled without keys, got %d", code)
+	}
+
+	publicKey, privateKey := newTestVAPIDKeys(t)
+	if err := app.SetWebPushKeys(publicKey, privateKey, "mailto:ops@example.com"); err != nil {
+		t.Fatalf("set web push keys: %v", err)
+	}
+	if code := serve(http.MethodPost, "/api/v1/push/subscriptions", `{"endpoint":"https://push.example.com/1","keys":{"p256dh":"abc","auth":"def"}}`); code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected malformed keys to be rejected, got %d", code)
+	}
+	if code := serve(http.MethodPost, "/api/v1/push/subscriptions", device.subscription("https://push.example.com/1")); code != http.StatusCreated {
+		t.Fatalf("expected subscription to be registered, got %d", code)
+	}
+	if code := serve(http.MethodDelete, "/api/v1/push/subscriptions", `{"endpoint":"https://push.example.com/1"}`); code != http.StatusNoContent {
+		t.Fatalf("expected subscription to be removed, got %d", code)
+	}
+	if code := serve(http.MethodDelete, "/api/v1/push/subscriptions", `{"endpoint":"https://push.example.com/1"}`); code != http.StatusNotFound {
+		t.Fatalf("expected unknown subscription to be reported, got %d", code)
+	}
+}
+
+func TestSetWebPushKeysRejectsMismatchedKeys(t *testing.T) {
+	app := NewApp()
+	publicKey, _ := newTestVAPIDKeys(t)
+	_, otherPrivateKey := newTestVAPIDKeys(t)
+
+	if err := app.SetWebPushKeys(publicKey, otherPrivateKey, "mailto:ops@example.com"); err == nil {
+		t.Fatalf("expected mismatched keys to be rejected")
+	}
+	if err := app.SetWebPushKeys("", "", ""); err != nil {
+		t.Fatalf("expected empty keys to disable web push, got %v", err)
+	}
+}
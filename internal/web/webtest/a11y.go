@@ -0,0 +1,178 @@
+package webtest
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	a11yIgnoredBlocks = regexp.MustCompile(`(?is)<!--.*?-->|<script\b.*?</script>|<style\b.*?</style>|<template\b.*?</template>`)
+	a11yTag           = regexp.MustCompile(`<(/?)([a-zA-Z][a-zA-Z0-9-]*)((?:[^>"']|"[^"]*"|'[^']*')*)>`)
+	a11yAttribute     = regexp.MustCompile(`([a-zA-Z_:][-a-zA-Z0-9_:.]*)(?:\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+)))?`)
+	a11yLabelable     = map[string]bool{"input": true, "select": true, "textarea": true}
+	// a11yNamed elements collect their text content, which names them.
+	a11yNamed = map[string]bool{"label": true, "button": true, "a": true, "summary": true}
+	// a11yUnlabeledInputs are input types that are named by their value or need no name.
+	a11yUnlabeledInputs = map[string]bool{"hidden": true, "submit": true, "button": true, "reset": true, "image": true}
+)
+
+type a11yElement struct {
+	tag   string
+	attrs map[string]string
+	text  strings.Builder
+	// wrapped is set on form controls inside a <label>, which names them.
+	wrapped bool
+	// labelsControl is set on labels that wrap a form control.
+	labelsControl bool
+}
+
+// AuditAccessibility checks rendered HTML for problems that make a page hard to use with a screen reader
+// or keyboard: form controls without a label, labels pointing nowhere, duplicate ids, images without alt
+// text, buttons and links without a name, and a missing language, main landmark or top-level heading.
+// It returns one message per problem.
+func AuditAccessibility(page string) []string {
+	var problems []string
+	report := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	page = a11yIgnoredBlocks.ReplaceAllString(page, "")
+	ids := map[string]int{}
+	labelFor := map[string]bool{}
+	var controls []*a11yElement
+	var stack []*a11yElement
+	mains, headings := 0, 0
+	isDocument := false
+
+	offset := 0
+	for _, match := range a11yTag.FindAllStringSubmatchIndex(page, -1) {
+		text := html.UnescapeString(page[offset:match[0]])
+		offset = match[1]
+		for _, open := range stack {
+			open.text.WriteString(text)
+		}
+
+		closing := page[match[2]:match[3]] == "/"
+		tag := strings.ToLower(page[match[4]:match[5]])
+		if closing {
+			for i := len(stack) - 1; i >= 0; i-- {
+				if stack[i].tag != tag {
+					continue
+				}
+				checkNamedElement(stack[i], report)
+				if tag == "label" {
+					if target := stack[i].attrs["for"]; target != "" {
+						labelFor[target] = true
+					} else if !stack[i].labelsControl {
+						report("<label> %q labels no form control; group controls with <fieldset> and <legend> instead", strings.TrimSpace(stack[i].text.String()))
+					}
+				}
+				stack = stack[:i]
+				break
+			}
+			continue
+		}
+
+		el := &a11yElement{tag: tag, attrs: parseA11yAttributes(page[match[6]:match[7]])}
+		if id := el.attrs["id"]; id != "" {
+			ids[id]++
+		}
+
+		switch tag {
+		case "html":
+			isDocument = true
+			if strings.TrimSpace(el.attrs["lang"]) == "" {
+				report("<html> has no lang attribute")
+			}
+		case "main":
+			mains++
+		case "h1":
+			headings++
+		case "img":
+			if _, ok := el.attrs["alt"]; !ok {
+				report("<img src=%q> has no alt text", el.attrs["src"])
+			}
+		}
+
+		if a11yLabelable[tag] && !a11yUnlabeledInputs[strings.ToLower(el.attrs["type"])] {
+			controls = append(controls, el)
+			for _, open := range stack {
+				if open.tag == "label" {
+					open.labelsControl = true
+					el.wrapped = true
+				}
+			}
+		}
+		if a11yNamed[tag] {
+			stack = append(stack, el)
+		}
+	}
+
+	for target := range labelFor {
+		if ids[target] == 0 {
+			report("<label for=%q> points to no element", target)
+		}
+	}
+	for _, control := range controls {
+		named := control.wrapped || labelFor[control.attrs["id"]] ||
+			control.attrs["aria-label"] != "" || control.attrs["aria-labelledby"] != "" || control.attrs["title"] != ""
+		if !named {
+			report("<%s %s> has no label", control.tag, controlKey(control))
+		}
+	}
+	for id, count := range ids {
+		if count > 1 {
+			report("id %q is used %d times", id, count)
+		}
+	}
+	if isDocument {
+		if mains != 1 {
+			report("page has %d <main> landmarks, expected 1", mains)
+		}
+		if headings == 0 {
+			report("page has no <h1>")
+		}
+	}
+	return problems
+}
+
+// checkNamedElement reports buttons, links and disclosure summaries that have no accessible name.
+func checkNamedElement(el *a11yElement, report func(string, ...any)) {
+	if el.tag != "button" && el.tag != "a" && el.tag != "summary" {
+		return
+	}
+	if strings.TrimSpace(el.text.String()) != "" || el.attrs["aria-label"] != "" || el.attrs["aria-labelledby"] != "" || el.attrs["title"] != "" {
+		return
+	}
+	report("<%s %s> has no accessible name", el.tag, controlKey(el))
+}
+
+func controlKey(el *a11yElement) string {
+	if id := el.attrs["id"]; id != "" {
+		return fmt.Sprintf("id=%q", id)
+	}
+	if name := el.attrs["name"]; name != "" {
+		return fmt.Sprintf("name=%q", name)
+	}
+	if href := el.attrs["href"]; href != "" {
+		return fmt.Sprintf("href=%q", href)
+	}
+	return ""
+}
+
+func parseA11yAttributes(raw string) map[string]string {
+	attrs := map[string]string{}
+	for _, m := range a11yAttribute.FindAllStringSubmatch(raw, -1) {
+		value := m[2]
+		if value == "" {
+			value = m[3]
+		}
+		if value == "" {
+			value = m[4]
+		}
+		attrs[strings.ToLower(m[1])] = html.UnescapeString(value)
+	}
+	return attrs
+}
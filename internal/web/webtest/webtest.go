@@ -0,0 +1,316 @@
+// Package webtest runs handler-level tests against a web.App backed by a temporary SQLite database.
+//
+// Fixtures are written straight to the database before the first request and assertions read the
+// database back, so feature tests only touch the app through HTTP and stay independent of its
+// in-memory state.
+package webtest
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"mvpapp/internal/domain"
+	"mvpapp/internal/web"
+)
+
+const profileCookie = "active_profile"
+
+// Profile is a profile fixture. Empty fields fall back to the app defaults.
+type Profile struct {
+	Name       string
+	HourlyWage string
+	Currency   string
+}
+
+// Item is an item fixture owned by the profile named in Profile.
+// Empty fields default to a waiting item created now with a 24h wait.
+type Item struct {
+	Profile string
+	Title   string
+	// Price is in cents, e.g. 1250 for 12.50; 0 leaves the item without a price.
+	Price             domain.Money
+	Link              string
+	Note              string
+	Tags              string
+	Status            string
+	WaitPreset        string
+	PurchaseAllowedAt time.Time
+	CreatedAt         time.Time
+	DecidedAt         time.Time
+}
+
+// Fixtures is the initial database content of a harness.
+type Fixtures struct {
+	Profiles []Profile
+	Items    []Item
+}
+
+// StoredItem is an item as persisted in the database.
+type StoredItem struct {
+	ID     int
+	Title  string
+	Price  string
+	Tags   string
+	Status string
+}
+
+// Harness serves requests against an App and inspects its database.
+type Harness struct {
+	T   testing.TB
+	App *web.App
+	DB  *sql.DB
+
+	handler http.Handler
+}
+
+// New creates an app with a temporary database seeded from fixtures. Both are closed when the test ends.
+func New(t testing.TB, fixtures Fixtures) *Harness {
+	t.Helper()
+	dbPath := filepath.Join(t.TempDir(), "test.sqlite")
+	app, err := web.NewAppWithSQLite(dbPath)
+	if err != nil {
+		t.Fatalf("new sqlite app: %v", err)
+	}
+	t.Cleanup(func() { _ = app.Close() })
+
+	// The app may still be writing from a background job; wait for its lock instead of failing the seed.
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		t.Fatalf("open fixture db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	h := &Harness{T: t, App: app, DB: db, handler: app.Handler()}
+	for _, profile := range fixtures.Profiles {
+		h.seedProfile(profile)
+	}
+	for _, item := range fixtures.Items {
+		h.seedItem(item)
+	}
+	return h
+}
+
+func (h *Harness) seedProfile(profile Profile) {
+	h.T.Helper()
+	wage := profile.HourlyWage
+	if wage == "" {
+		wage = "25"
+	}
+	currency := profile.Currency
+	if currency == "" {
+		currency = "EUR"
+	}
+	_, err := h.DB.Exec(`INSERT INTO profiles(user_id, hourly_wage, currency, updated_at) VALUES (?, ?, ?, ?)`,
+		profile.Name, wage, currency, time.Now().Format(time.RFC3339Nano))
+	if err != nil {
+		h.T.Fatalf("seed profile %q: %v", profile.Name, err)
+	}
+}
+
+func (h *Harness) seedItem(item Item) {
+	h.T.Helper()
+	if item.Status == "" {
+		item.Status = "Waiting"
+	}
+	if item.WaitPreset == "" {
+		item.WaitPreset = "24h"
+	}
+	if item.CreatedAt.IsZero() {
+		item.CreatedAt = time.Now()
+	}
+	if item.PurchaseAllowedAt.IsZero() {
+		item.PurchaseAllowedAt = item.CreatedAt.Add(24 * time.Hour)
+	}
+	price, hasPrice := "", 0
+	if item.Price > 0 {
+		price, hasPrice = item.Price.String(), 1
+	}
+	decidedAt := ""
+	if !item.DecidedAt.IsZero() {
+		decidedAt = item.DecidedAt.Format(time.RFC3339Nano)
+	}
+	_, err := h.DB.Exec(`
+INSERT INTO items(user_id, title, price, price_cents, has_price_value, link, note, tags, status, wait_preset, purchase_allowed_at, created_at, decided_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`, item.Profile, item.Title, price, int64(item.Price), hasPrice, item.Link, item.Note, item.Tags, item.Status, item.WaitPreset,
+		item.PurchaseAllowedAt.Format(time.RFC3339Nano), item.CreatedAt.Format(time.RFC3339Nano), decidedAt)
+	if err != nil {
+		h.T.Fatalf("seed item %q: %v", item.Title, err)
+	}
+}
+
+// As returns a client that sends requests with the profile cookie of the named profile.
+func (h *Harness) As(profile string) *Client {
+	return &Client{h: h, profile: profile}
+}
+
+// Anonymous returns a client without a profile cookie, such as a kiosk or household viewer.
+func (h *Harness) Anonymous() *Client {
+	return &Client{h: h}
+}
+
+// Items returns the profile's stored items ordered by ID.
+func (h *Harness) Items(profile string) []StoredItem {
+	h.T.Helper()
+	rows, err := h.DB.Query(`SELECT id, title, price, tags, status FROM items WHERE user_id = ? ORDER BY id`, profile)
+	if err != nil {
+		h.T.Fatalf("query items: %v", err)
+	}
+	defer rows.Close()
+
+	var items []StoredItem
+	for rows.Next() {
+		var item StoredItem
+		if err := rows.Scan(&item.ID, &item.Title, &item.Price, &item.Tags, &item.Status); err != nil {
+			h.T.Fatalf("scan item: %v", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		h.T.Fatalf("iterate items: %v", err)
+	}
+	return items
+}
+
+// Item returns the profile's stored item with the given title and fails the test if there is none.
+func (h *Harness) Item(profile, title string) StoredItem {
+	h.T.Helper()
+	for _, item := range h.Items(profile) {
+		if item.Title == title {
+			return item
+		}
+	}
+	h.T.Fatalf("no item %q for profile %q", title, profile)
+	return StoredItem{}
+}
+
+// Client sends requests as one profile.
+type Client struct {
+	h       *Harness
+	profile string
+	header  http.Header
+}
+
+// WithHeader returns a copy of the client that adds the header to every request.
+func (c *Client) WithHeader(key, value string) *Client {
+	next := *c
+	next.header = c.header.Clone()
+	if next.header == nil {
+		next.header = http.Header{}
+	}
+	next.header.Set(key, value)
+	return &next
+}
+
+// Get requests the path.
+func (c *Client) Get(path string) *Response {
+	c.h.T.Helper()
+	return c.Do(httptest.NewRequest(http.MethodGet, path, nil))
+}
+
+// PostForm submits the form to the path.
+func (c *Client) PostForm(path string, form url.Values) *Response {
+	c.h.T.Helper()
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return c.Do(req)
+}
+
+// PostJSON sends body, encoded as JSON, to the path.
+func (c *Client) PostJSON(path string, body any) *Response {
+	c.h.T.Helper()
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		c.h.T.Fatalf("encode json body: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(encoded))
+	req.Header.Set("Content-Type", "application/json")
+	return c.Do(req)
+}
+
+// Do sends the request as the client's profile.
+func (c *Client) Do(req *http.Request) *Response {
+	c.h.T.Helper()
+	for key, values := range c.header {
+		req.Header[key] = values
+	}
+	if c.profile != "" {
+		req.AddCookie(&http.Cookie{Name: profileCookie, Value: c.profile})
+	}
+
+	rr := httptest.NewRecorder()
+	c.h.handler.ServeHTTP(rr, req)
+	for _, cookie := range rr.Result().Cookies() {
+		if cookie.Name == profileCookie {
+			c.profile = cookie.Value
+		}
+	}
+	return &Response{ResponseRecorder: rr, t: c.h.T}
+}
+
+// Response is a recorded response with assertion helpers.
+type Response struct {
+	*httptest.ResponseRecorder
+	t testing.TB
+}
+
+// Body returns the response body.
+func (r *Response) Body() string {
+	return r.ResponseRecorder.Body.String()
+}
+
+// Location returns the redirect target.
+func (r *Response) Location() string {
+	return r.Header().Get("Location")
+}
+
+// ExpectStatus fails the test unless the response has the status code.
+func (r *Response) ExpectStatus(code int) *Response {
+	r.t.Helper()
+	if r.Code != code {
+		r.t.Fatalf("expected status %d, got %d: %s", code, r.Code, strings.TrimSpace(r.Body()))
+	}
+	return r
+}
+
+// ExpectRedirect fails the test unless the response is a 303 redirect to the location.
+func (r *Response) ExpectRedirect(location string) *Response {
+	r.t.Helper()
+	r.ExpectStatus(http.StatusSeeOther)
+	if got := r.Location(); got != location {
+		r.t.Fatalf("expected redirect to %q, got %q", location, got)
+	}
+	return r
+}
+
+// ExpectContains fails the test unless the body contains every snippet.
+func (r *Response) ExpectContains(snippets ...string) *Response {
+	r.t.Helper()
+	body := r.Body()
+	for _, snippet := range snippets {
+		if !strings.Contains(body, snippet) {
+			r.t.Fatalf("expected response to contain %q", snippet)
+		}
+	}
+	return r
+}
+
+// ExpectNotContains fails the test if the body contains any of the snippets.
+func (r *Response) ExpectNotContains(snippets ...string) *Response {
+	r.t.Helper()
+	body := r.Body()
+	for _, snippet := range snippets {
+		if strings.Contains(body, snippet) {
+			r.t.Fatalf("expected response not to contain %q", snippet)
+		}
+	}
+	return r
+}
@@ -0,0 +1,59 @@
+package webtest
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestFixturesAreVisibleToTheirProfileOnly(t *testing.T) {
+	h := New(t, Fixtures{
+		Profiles: []Profile{{Name: "Alex", Currency: "CHF"}, {Name: "Bea"}},
+		Items:    []Item{{Profile: "Alex", Title: "Desk lamp", Price: 4000}},
+	})
+
+	if items := h.Items("Alex"); len(items) != 1 || items[0].Price != "40.00" || items[0].Status != "Waiting" {
+		t.Fatalf("unexpected seeded items %+v", items)
+	}
+	h.As("Alex").Get("/").ExpectStatus(http.StatusOK).ExpectContains("Desk lamp", "CHF 40")
+	h.As("Bea").Get("/").ExpectStatus(http.StatusOK).ExpectNotContains("Desk lamp")
+}
+
+func TestClientActivatesItsProfileBeforeOtherRequests(t *testing.T) {
+	h := New(t, Fixtures{Profiles: []Profile{{Name: "Alex", HourlyWage: "30"}, {Name: "Bea", HourlyWage: "12"}}})
+
+	h.As("Alex").Get("/")
+	h.As("Bea").Get("/settings/profile").ExpectStatus(http.StatusOK).ExpectContains(`value="Bea"`, `value="12"`)
+}
+
+func TestAuditAccessibilityReportsCommonProblems(t *testing.T) {
+	page := `<html><body><main>
+<label>Status</label>
+<input id="q" name="q" />
+<label for="missing">Sort</label>
+<label>Tags <input type="checkbox" name="tags" /></label>
+<input type="hidden" name="item_id" />
+<button id="q"></button>
+<img src="/logo.png">
+<script>if (a < b) {}</script>
+</main></body></html>`
+
+	got := strings.Join(AuditAccessibility(page), "\n")
+	for _, want := range []string{
+		`<html> has no lang attribute`,
+		`<label> "Status" labels no form control`,
+		`<input id="q"> has no label`,
+		`<label for="missing"> points to no element`,
+		`<button id="q"> has no accessible name`,
+		`id "q" is used 2 times`,
+		`<img src="/logo.png"> has no alt text`,
+		`page has no <h1>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected problem %q, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, `name="tags"`) || strings.Contains(got, `item_id`) {
+		t.Fatalf("expected wrapped and hidden inputs to pass, got:\n%s", got)
+	}
+}
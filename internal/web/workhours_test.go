@@ -0,0 +1,91 @@
+package web_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"mvpapp/internal/web/webtest"
+)
+
+func TestWorkCostFollowsTheChosenDisplayMode(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex", HourlyWage: "25"}},
+		Items:    []webtest.Item{{Profile: "Alex", Title: "Headphones", Price: 10000}},
+	})
+	alex := h.As("Alex")
+	alex.Get("/").ExpectContains("Work hours: 4.0 h")
+
+	for _, mode := range []struct {
+		form url.Values
+		want string
+	}{
+		{url.Values{"work_hours_mode": {"days"}}, "Work days: 0.5"},
+		{url.Values{"work_hours_mode": {"shifts"}, "shift_hours": {"10"}}, "Shifts: 0.4 × 10 h"},
+		{url.Values{"work_hours_mode": {"income"}}, "Monthly income: 2.3%"},
+	} {
+		mode.form.Set("profile_name", "Alex")
+		mode.form.Set("hourly_wage", "25")
+		alex.PostForm("/settings/profile", mode.form).ExpectRedirect("/settings/profile?saved=1")
+		alex.Get("/").ExpectContains(mode.want).ExpectNotContains("Work hours: 4.0 h")
+	}
+	alex.Get("/settings/profile").ExpectContains(`<option value="income" selected>`)
+
+	alex.PostForm("/settings/profile", url.Values{"profile_name": {"Alex"}, "hourly_wage": {"25"}, "work_hours_mode": {"shifts"}, "shift_hours": {"0"}}).
+		ExpectStatus(http.StatusBadRequest).
+		ExpectContains(`id="shift_hours-error"`)
+}
+
+func TestWorkCostFollowsTheChosenPrecisionAndRounding(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex", HourlyWage: "30"}},
+		Items:    []webtest.Item{{Profile: "Alex", Title: "Headphones", Price: 10000}},
+	})
+	alex := h.As("Alex")
+	alex.Get("/").ExpectContains("Work hours: 3.3 h")
+
+	for _, setting := range []struct {
+		form url.Values
+		want string
+	}{
+		{url.Values{"work_hours_precision": {"2"}}, "Work hours: 3.33 h"},
+		{url.Values{"work_hours_precision": {"0"}, "work_hours_rounding": {"up"}}, "Work hours: 4 h"},
+		{url.Values{"work_hours_precision": {"1"}, "work_hours_rounding": {"up"}, "work_hours_mode": {"days"}}, "Work days: 0.5"},
+	} {
+		setting.form.Set("profile_name", "Alex")
+		setting.form.Set("hourly_wage", "30")
+		alex.PostForm("/settings/profile", setting.form).ExpectRedirect("/settings/profile?saved=1")
+		alex.Get("/").ExpectContains(setting.want)
+	}
+	alex.Get("/settings/profile").ExpectContains(`<option value="up" selected>`, `<option value="1" selected>`)
+
+	alex.PostForm("/settings/profile", url.Values{"profile_name": {"Alex"}, "hourly_wage": {"30"}, "work_hours_precision": {"5"}}).
+		ExpectStatus(http.StatusBadRequest).
+		ExpectContains(`id="work_hours_precision-error"`)
+}
+
+func TestMonthlyIncomeDerivesTheHourlyWage(t *testing.T) {
+	h := webtest.New(t, webtest.Fixtures{
+		Profiles: []webtest.Profile{{Name: "Alex", HourlyWage: "25"}},
+		Items:    []webtest.Item{{Profile: "Alex", Title: "Headphones", Price: 10000}},
+	})
+	alex := h.As("Alex")
+	alex.Get("/settings/profile").ExpectContains("About € 4333.33 per month at 40 h per week.")
+
+	alex.PostForm("/settings/profile", url.Values{"profile_name": {"Alex"}, "monthly_income": {"2600"}, "weekly_hours": {"30"}}).
+		ExpectRedirect("/settings/profile?saved=1")
+	alex.Get("/settings/profile").ExpectContains(`value="2600"`, `value="30"`, `value="20.00"`, "Effective hourly wage: € 20.00 at 30 h per week.")
+	alex.Get("/").ExpectContains("Work hours: 5.0 h")
+
+	var hourlyWage, monthlyIncome, weeklyHours string
+	if err := h.DB.QueryRow(`SELECT hourly_wage, monthly_income, weekly_hours FROM profiles WHERE user_id = 'Alex'`).Scan(&hourlyWage, &monthlyIncome, &weeklyHours); err != nil {
+		t.Fatalf("load income settings: %v", err)
+	}
+	if hourlyWage != "20.00" || monthlyIncome != "2600" || weeklyHours != "30" {
+		t.Fatalf("expected both representations to be persisted, got %q %q %q", hourlyWage, monthlyIncome, weeklyHours)
+	}
+
+	alex.PostForm("/settings/profile", url.Values{"profile_name": {"Alex"}, "monthly_income": {"-1"}}).
+		ExpectStatus(http.StatusBadRequest).
+		ExpectContains(`id="monthly_income-error"`)
+}
@@ -657,14 +657,14 @@ test('R1-008 currency from profile is used in forms, list and insights with euro
 });
 
 
-test('R1-006: new profile redirects to settings with reset defaults', async ({ page }) => {
+test('R1-006: new profile redirects to onboarding with reset defaults', async ({ page }) => {
   const profileA = uniqueName('Alice');
   const profileB = uniqueName('BrandNew');
 
   await page.goto('/switch-profile');
   await page.getByLabel('Profile name').fill(profileA);
   await page.getByRole('button', { name: 'Create' }).click();
-  await expect(page).toHaveURL(/\/settings\/profile/);
+  await expect(page).toHaveURL(/\/onboarding/);
 
   await saveProfile(page, '45', 'CHF');
 
@@ -674,7 +674,8 @@ test('R1-006: new profile redirects to settings with reset defaults', async ({ p
 
   await page.getByLabel('Profile name').fill(profileB);
   await page.getByRole('button', { name: 'Create' }).click();
-  await expect(page).toHaveURL(/\/settings\/profile/);
+  await expect(page).toHaveURL(/\/onboarding/);
+  await page.goto('/settings/profile');
 
   await expect(page.getByLabel('Net hourly wage')).toHaveValue('25');
   await expect(page.getByLabel('Currency')).toHaveValue('€');
@@ -715,7 +716,8 @@ test('R1-006: profile name can be renamed in settings', async ({ page }) => {
   await page.goto('/switch-profile');
   await page.getByLabel('Profile name').fill(profileA);
   await page.getByRole('button', { name: 'Create' }).click();
-  await expect(page).toHaveURL(/\/settings\/profile/);
+  await expect(page).toHaveURL(/\/onboarding/);
+  await page.goto('/settings/profile');
 
   await page.getByLabel('Profile name').fill(profileB);
   await page.getByLabel('Net hourly wage').fill('31');